@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/version"
+	stashr "github.com/harshalranjhani/stashr/pkg/stashr/v1"
+)
+
+var (
+	apiVersionShort    bool
+	apiVersionRequires string
+)
+
+// apiVersionCmd represents the api-version command
+var apiVersionCmd = &cobra.Command{
+	Use:   "api-version",
+	Short: "Show the version of the library API and plugin protocol",
+	Long: `Show the versions third-party tools depend on: the library API of
+github.com/harshalranjhani/stashr/pkg/stashr/v1, which follows semantic
+versioning, and the storage plugin protocol. Deprecated APIs are listed with
+their replacements; they keep working until the next major version.
+
+Tools can check compatibility with --requires, which exits with an error if the
+installed stashr doesn't provide the API version they were built against.`,
+	Example: `  stashr api-version
+  stashr api-version --short
+  stashr api-version --requires 1.0`,
+	Run: runAPIVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(apiVersionCmd)
+
+	apiVersionCmd.Flags().BoolVar(&apiVersionShort, "short", false, "Print only the API version")
+	apiVersionCmd.Flags().StringVar(&apiVersionRequires, "requires", "", "Exit with an error unless this API version (MAJOR.MINOR) is supported")
+}
+
+func runAPIVersion(cmd *cobra.Command, args []string) {
+	if apiVersionRequires != "" {
+		supported, err := stashr.Supports(apiVersionRequires)
+		if err != nil {
+			logger.PrintError(err)
+			os.Exit(1)
+		}
+		if !supported {
+			logger.Failure("API %s is not supported by this stashr (API %s)", apiVersionRequires, stashr.APIVersion)
+			os.Exit(1)
+		}
+	}
+
+	if apiVersionShort {
+		fmt.Println(stashr.APIVersion)
+		return
+	}
+
+	logger.Header("🧩 API Version")
+	logger.Info("stashr: %s", version.GetFullVersion())
+	logger.Info("Library API: %s (github.com/harshalranjhani/stashr/pkg/stashr/v1)", stashr.APIVersion)
+	logger.Info("Plugin protocol: %d", stashr.PluginProtocolVersion)
+
+	if len(stashr.Deprecations) == 0 {
+		logger.Info("Deprecated APIs: none")
+		return
+	}
+	logger.Info("Deprecated APIs:")
+	for _, d := range stashr.Deprecations {
+		logger.Info("  %s (since %s): use %s", d.Name, d.Since, d.Replacement)
+	}
+}
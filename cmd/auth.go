@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// authBundleVersion is the format version of exported auth bundles
+const authBundleVersion = 1
+
+var (
+	authOutput string
+	authForce  bool
+)
+
+// authBundle carries a destination's OAuth credentials and token between machines.
+// Service account keys need no token.
+type authBundle struct {
+	Version     int             `json:"version"`
+	Storage     string          `json:"storage"`
+	Credentials json.RawMessage `json:"credentials"`
+	Token       json.RawMessage `json:"token,omitempty"`
+	FolderID    string          `json:"folder_id"`
+	DriveID     string          `json:"drive_id,omitempty"`
+	ExportedAt  time.Time       `json:"exported_at"`
+	ExportedBy  string          `json:"exported_by"`
+}
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Transfer storage authentication between machines",
+	Long: `Move a storage destination's authentication to a second trusted machine
+without repeating the OAuth sign-in.
+
+'auth export' wraps the OAuth client credentials, the saved token and the
+folder ID in a bundle encrypted with a password you choose. Copy the bundle to
+the other machine and run 'auth import' there.
+
+Supported destinations:
+  gdrive  Google Drive`,
+}
+
+// authExportCmd represents the auth export command
+var authExportCmd = &cobra.Command{
+	Use:     "export gdrive",
+	Short:   "Export storage authentication to an encrypted bundle",
+	Example: `  stashr auth export gdrive --output gdrive-auth.stashr`,
+	Args:    cobra.ExactArgs(1),
+	Run:     runAuthExport,
+}
+
+// authImportCmd represents the auth import command
+var authImportCmd = &cobra.Command{
+	Use:     "import gdrive <bundle>",
+	Short:   "Import storage authentication from an encrypted bundle",
+	Example: `  stashr auth import gdrive gdrive-auth.stashr`,
+	Args:    cobra.ExactArgs(2),
+	Run:     runAuthImport,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authExportCmd)
+	authCmd.AddCommand(authImportCmd)
+
+	authExportCmd.Flags().StringVarP(&authOutput, "output", "o", "", "Output path for the bundle (default: gdrive-auth.stashr)")
+	authImportCmd.Flags().BoolVarP(&authForce, "force", "f", false, "Overwrite existing credentials and token")
+}
+
+// checkAuthStorage rejects destinations that have no transferable authentication
+func checkAuthStorage(name string) error {
+	if name != "gdrive" {
+		return fmt.Errorf("unsupported storage: %s (use: gdrive)", name)
+	}
+	return nil
+}
+
+func runAuthExport(cmd *cobra.Command, args []string) {
+	logger.Header("🔑 Export Storage Authentication")
+
+	if err := checkAuthStorage(args[0]); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, cfg.Storage.GoogleDrive.FolderID, cfg.Storage.GoogleDrive.DriveID)
+	credentials, err := os.ReadFile(gdrive.CredentialsPath)
+	if err != nil {
+		logger.Failure("Failed to read Google Drive credentials: %v", err)
+		return
+	}
+	var token []byte
+	if !gdrive.IsServiceAccount() {
+		token, err = os.ReadFile(gdrive.TokenPath())
+		if err != nil {
+			logger.Failure("Failed to read Google Drive token: %v", err)
+			logger.Info("Sign in on this machine first, e.g. with 'stashr config validate'")
+			return
+		}
+	}
+	if !json.Valid(credentials) || (token != nil && !json.Valid(token)) {
+		logger.Failure("Google Drive credentials or token file is not valid JSON")
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	bundle, err := json.Marshal(authBundle{
+		Version:     authBundleVersion,
+		Storage:     args[0],
+		Credentials: credentials,
+		Token:       token,
+		FolderID:    gdrive.FolderID,
+		DriveID:     gdrive.DriveID,
+		ExportedAt:  time.Now(),
+		ExportedBy:  hostname,
+	})
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Warning("⚠️  The bundle grants access to your Google Drive. Only import it on machines you trust.")
+	password, err := utils.PromptForPassword("Enter a password for the bundle: ")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if password == "" {
+		logger.Failure("A bundle password is required")
+		return
+	}
+	confirmPassword, err := utils.PromptForPassword("Confirm bundle password: ")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if password != confirmPassword {
+		logger.Failure("Passwords do not match!")
+		return
+	}
+
+	encrypted, err := crypto.Encrypt(bundle, password)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	outputPath := authOutput
+	if outputPath == "" {
+		outputPath = args[0] + "-auth.stashr"
+	}
+	if err := os.WriteFile(outputPath, encrypted, 0600); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Success("✓ Authentication bundle written to: %s", outputPath)
+	logger.Info("On the other machine, run: stashr auth import %s %s", args[0], filepath.Base(outputPath))
+	logger.Info("Delete the bundle once it has been imported.")
+}
+
+func runAuthImport(cmd *cobra.Command, args []string) {
+	logger.Header("🔑 Import Storage Authentication")
+
+	if err := checkAuthStorage(args[0]); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	encrypted, err := os.ReadFile(args[1])
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	password, err := utils.PromptForPassword("Enter the bundle password: ")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	data, err := crypto.Decrypt(encrypted, password)
+	if err != nil {
+		logger.Failure("Failed to decrypt bundle: %v", err)
+		logger.Info("Make sure you're using the password chosen during 'auth export'")
+		return
+	}
+
+	var bundle authBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		logger.Failure("Invalid authentication bundle: %v", err)
+		return
+	}
+	if bundle.Version > authBundleVersion {
+		logger.Failure("Bundle version %d is newer than supported (%d); upgrade stashr", bundle.Version, authBundleVersion)
+		return
+	}
+	if bundle.Storage != args[0] {
+		logger.Failure("Bundle is for %s, not %s", bundle.Storage, args[0])
+		return
+	}
+	logger.Success("✓ Decrypted bundle exported from %s on %s", bundle.ExportedBy, bundle.ExportedAt.Format("2006-01-02 15:04"))
+
+	gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, bundle.FolderID, bundle.DriveID)
+	if !authForce && (utils.FileExists(gdrive.CredentialsPath) || utils.FileExists(gdrive.TokenPath())) {
+		if !utils.ConfirmPrompt("Google Drive credentials already exist on this machine. Overwrite them?") {
+			logger.Info("Import cancelled")
+			return
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(gdrive.CredentialsPath), 0700); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := os.WriteFile(gdrive.CredentialsPath, bundle.Credentials, 0600); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Credentials written to: %s", gdrive.CredentialsPath)
+	if len(bundle.Token) > 0 {
+		if err := os.WriteFile(gdrive.TokenPath(), bundle.Token, 0600); err != nil {
+			logger.PrintError(err)
+			return
+		}
+		logger.Success("✓ Token written to: %s", gdrive.TokenPath())
+	}
+
+	cfg.Storage.GoogleDrive.Enabled = true
+	cfg.Storage.GoogleDrive.FolderID = bundle.FolderID
+	cfg.Storage.GoogleDrive.DriveID = bundle.DriveID
+	if err := config.Save(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Google Drive enabled in configuration")
+
+	logger.Separator()
+	logger.Info("Run 'stashr config validate' to test the connection, then delete the bundle:")
+	logger.Info("  rm \"%s\"", args[1])
+}
@@ -1,22 +1,43 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/user"
+	"path"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
+	"github.com/harshalranjhani/stashr/internal/backupname"
 	"github.com/harshalranjhani/stashr/internal/config"
 	"github.com/harshalranjhani/stashr/internal/crypto"
 	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/identity"
 	"github.com/harshalranjhani/stashr/internal/logger"
 	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/secret"
 	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/internal/strength"
+	"github.com/harshalranjhani/stashr/internal/version"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
+// backupKey is the key read from the key file this run encrypts with, if any
+var backupKey *secret.Buffer
+
+// backupSigner is the identity this run signs backups with, if signing is enabled
+var backupSigner *identity.Identity
+
 var (
 	managerFlag      string
 	destinationFlag  string
@@ -25,24 +46,36 @@ var (
 	promptEachBackup bool
 	fullExport       bool
 	interactiveMode  bool
+	nonInteractive   bool
 	dryRun           bool
 	backupTags       []string
 	backupNotes      string
+	skipValidation   bool
+	parallelFlag     int
+	parallelUploads  int
+	tempDirFlag      string
+	strictPreflight  bool
+	noVerify         bool
+	ignoreConditions bool
+	forceLock        bool
 )
 
 // backupCmd represents the backup command
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Backup password manager vaults",
-	
+
 	Long: `Backup password manager vaults to configured storage destinations.
 
 This command will:
-1. Check password manager authentication
+1. Check every password manager and storage backend up front (pre-flight)
 2. Export vault data
 3. Compress and encrypt the data
 4. Upload to configured storage backends
-5. Apply retention policy to remove old backups`,
+5. Apply retention policy to remove old backups
+
+Managers or destinations that fail the pre-flight checks are skipped; use
+--strict to abort the whole run instead.`,
 	Run: runBackup,
 }
 
@@ -50,15 +83,25 @@ func init() {
 	rootCmd.AddCommand(backupCmd)
 
 	backupCmd.Flags().StringVarP(&managerFlag, "manager", "m", "all", "Password manager to backup (bitwarden, 1password, all)")
-	backupCmd.Flags().StringVarP(&destinationFlag, "destination", "d", "all", "Destination to backup to (gdrive, usb, local, all)")
-	backupCmd.Flags().StringVarP(&encryptionKey, "encryption-key", "k", "", "Path to encryption key (will prompt if not provided)")
+	backupCmd.Flags().StringVarP(&destinationFlag, "destination", "d", "all", "Destination to backup to (gdrive, onedrive, webdav, gcs, azure, s3, rclone, icloud, usb, local, git-annex, a profile or plugin name, all)")
+	backupCmd.Flags().StringVarP(&encryptionKey, "encryption-key", "k", "", "Path to a key file to encrypt with instead of a password (default: backup.encryption.key_file)")
 	backupCmd.Flags().BoolVar(&noEncrypt, "no-encrypt", false, "Skip encryption (not recommended)")
+	backupCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Don't check stored copies against the upload (default: backup.verify_uploads)")
 	backupCmd.Flags().BoolVar(&promptEachBackup, "prompt-each", false, "Prompt for password for each manager (more secure)")
 	backupCmd.Flags().BoolVar(&fullExport, "full-export", false, "Export full item details including passwords (slower, 1Password only)")
 	backupCmd.Flags().BoolVarP(&interactiveMode, "interactive", "i", false, "Interactive mode with guided prompts")
+	backupCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; exit with a machine-readable error if input is required (default when stdin isn't a terminal)")
 	backupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview backup operation without executing")
 	backupCmd.Flags().StringSliceVarP(&backupTags, "tag", "t", []string{}, "Tags to add to this backup (can be specified multiple times)")
 	backupCmd.Flags().StringVarP(&backupNotes, "note", "n", "", "Notes to add to this backup")
+	backupCmd.Flags().IntVar(&parallelFlag, "parallel", 0, "Number of managers to back up at once (default: backup.max_parallel)")
+	backupCmd.Flags().IntVar(&parallelUploads, "parallel-uploads", 0, "Number of destinations to upload each backup to at once (default: backup.max_parallel_uploads)")
+	backupCmd.Flags().StringVar(&tempDirFlag, "temp-dir", "", "Directory for unencrypted vault exports, e.g. an encrypted volume or ramdisk (default: backup.temp_dir)")
+	backupCmd.Flags().BoolVar(&strictPreflight, "strict", false, "Abort if any manager or destination fails the pre-flight checks")
+	backupCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Upload the export even if it fails sanity checks (not recommended)")
+	backupCmd.Flags().BoolVar(&forceLock, "force", false, "Break the lock of another backup that is no longer running")
+	backupCmd.Flags().BoolVar(&ignoreConditions, "ignore-conditions", false, "Run a non-interactive backup even when backup.conditions would postpone it")
+	backupCmd.MarkFlagsMutuallyExclusive("interactive", "non-interactive")
 }
 
 func runBackup(cmd *cobra.Command, args []string) {
@@ -71,9 +114,49 @@ func runBackup(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// A backup that stops early is reported as failed through the notification channels
+	report := newBackupReport(cfg)
+	defer report.send()
+
+	// Nobody can answer prompts without a terminal, e.g. under cron or a scheduler
+	if !nonInteractive && !utils.IsTerminal() {
+		nonInteractive = true
+		logger.Info("stdin is not a terminal: running non-interactively")
+	}
+	// Only one backup or upload retry runs at a time
+	if !dryRun {
+		if err := acquireRunLock("backup", forceLock); err != nil {
+			report.discard()
+			logger.PrintError(err)
+			return
+		}
+		defer releaseRunLock()
+	}
+	// Unattended runs wait for backup.conditions, e.g. AC power, and run next time when not met
+	if nonInteractive && !dryRun && !ignoreConditions && cfg.Backup.Conditions.Enabled() {
+		if reason := unmetCondition(cfg); reason != "" {
+			report.discard()
+			logger.Warning("⏸ Postponing the backup: %s", reason)
+			recordPostponement(managerFlag, reason)
+			logger.Info("It runs at the next scheduled time the conditions are met, or now with --ignore-conditions")
+			return
+		}
+	}
+	// Unattended runs report to the dead-man switch, which alerts when they stop running
+	if nonInteractive && !dryRun {
+		report.startPing()
+	}
+	if nonInteractive && interactiveMode {
+		failInputRequired(inputInteractive, "--interactive asks questions and needs a terminal to answer them")
+	}
+	if nonInteractive && promptEachBackup {
+		failInputRequired(inputEncryptionPassword, "--prompt-each prompts for a password for each manager and can't run non-interactively")
+	}
+
 	// Interactive mode - ask user questions before proceeding
 	if interactiveMode {
 		if !handleInteractiveMode(cfg) {
+			report.discard()
 			logger.Info("Backup cancelled")
 			return
 		}
@@ -92,86 +175,334 @@ func runBackup(cmd *cobra.Command, args []string) {
 		logger.Failure("No storage backends enabled or selected")
 		return
 	}
+	// Flaky destinations are tried after reliable ones
+	storageBackends = orderByHealth(storageBackends)
+
+	// A misconfigured policy must block backups rather than be ignored
+	if err := validatePolicies(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := cfg.ValidateEncryptionOverrides(); err != nil {
+		logger.PrintError(err)
+		return
+	}
 
 	// Dry-run mode - preview what will happen
 	if dryRun {
+		report.discard()
 		handleDryRun(managersToBackup, storageBackends, cfg)
 		return
 	}
 
+	// Check every manager and destination before asking for passwords or exporting anything
+	preflight := runPreflight(managersToBackup, storageBackends)
+	report.addPreflight(preflight, managersToBackup)
+	if !preflightDecision(preflight, strictPreflight) {
+		return
+	}
+	managersToBackup = preflight.managers
+	storageBackends = preflight.backends
+	unavailableBackends = preflight.unavailable
+	logger.Separator()
+
+	// Refuse algorithms backup.encryption.fips doesn't allow before asking for a password
+	for _, backend := range storageBackends {
+		if err := checkFIPSMode(cfg, effectiveEncryptionMode(cfg, backend)); err != nil {
+			logger.PrintError(fmt.Errorf("%s: %w", backend.Name(), err))
+			return
+		}
+	}
+
+	// A key file stands in for the shared encryption password
+	backupKey = nil
+	if keyFile := resolveKeyFile(cfg, encryptionKey); keyFile != "" && requiresSharedPassword(cfg, storageBackends) {
+		if nonInteractive {
+			requireUnprotectedKeyFile(keyFile)
+		}
+		key, err := loadKeyFile(keyFile)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		backupKey = secret.FromBytes(key)
+		defer backupKey.Destroy()
+		logger.Success("✓ Encrypting with key file %s (key %s)", keyFile, crypto.KeyFileID(backupKey.Bytes()))
+	}
+
+	// Backups are signed with the identity key
+	backupSigner = nil
+	if cfg.Backup.Signing.Enabled {
+		backupSigner, err = signingIdentity()
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		logger.Success("✓ Signing backups as %s", backupSigner.ID)
+	}
+
 	// Get encryption password if needed (once for all backups)
-	var password string
-	if !noEncrypt && cfg.Backup.Encryption.Enabled && !promptEachBackup {
+	var password *secret.Buffer
+	defer func() { password.Destroy() }()
+	needsPassword := requiresSharedPassword(cfg, storageBackends) && backupKey == nil
+	if needsPassword && !promptEachBackup && cfg.Backup.Encryption.Keychain {
+		password, err = keychainPassword(cfg)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		logger.Success("✓ Using the encryption password from the OS keychain")
+	}
+	if needsPassword && !promptEachBackup && password == nil {
+		if password = environmentPassword(); password != nil {
+			if err := checkPasswordStrength(cfg, password); err != nil {
+				logger.PrintError(err)
+				return
+			}
+			logger.Success("✓ Using the encryption password from %s", passwordEnvVar)
+		}
+	}
+	if needsPassword && !promptEachBackup && password == nil && nonInteractive {
+		failInputRequired(inputEncryptionPassword, fmt.Sprintf("The encryption password can't be prompted for: store it in the OS keychain (stashr keychain set, backup.encryption.keychain), set %s, or use a key file (--encryption-key, backup.encryption.key_file)", passwordEnvVar))
+	}
+	if needsPassword && !promptEachBackup && password == nil {
 		logger.Warning("⚠️  CRITICAL: If you forget this password, your backups are LOST FOREVER!")
 		logger.Info("💡 Store this password in your password manager or write it down securely")
 		logger.Separator()
-		password, err = utils.PromptForPassword("Enter encryption password: ")
+		password, err = utils.PromptForSecret("Enter encryption password: ")
 		if err != nil {
 			logger.PrintError(err)
 			return
 		}
-		if password == "" {
+		if password.Len() == 0 {
 			logger.Failure("Encryption password is required")
 			return
 		}
 
 		// Confirm password
-		confirmPassword, err := utils.PromptForPassword("Confirm encryption password: ")
+		confirmPassword, err := utils.PromptForSecret("Confirm encryption password: ")
 		if err != nil {
 			logger.PrintError(err)
 			return
 		}
-		if password != confirmPassword {
+		matches := password.Equal(confirmPassword)
+		confirmPassword.Destroy()
+		if !matches {
 			logger.Failure("Passwords do not match!")
 			return
 		}
+		if err := checkPasswordStrength(cfg, password); err != nil {
+			logger.PrintError(err)
+			return
+		}
 	}
 
-	// Backup each manager
-	for _, mgr := range managersToBackup {
-		logger.Separator()
+	// Get dedicated passwords for destinations that require them
+	destinationPasswords, err := promptDestinationPasswords(cfg, storageBackends)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer func() {
+		for _, pw := range destinationPasswords {
+			pw.Destroy()
+		}
+	}()
 
+	// Ask every question before the pipelines start so prompts never interleave with their output
+	var jobs []backupJob
+	defer func() {
+		for _, job := range jobs {
+			job.password.Destroy()
+		}
+	}()
+	for _, mgr := range managersToBackup {
 		// Get password for this specific backup if prompt-each is enabled
 		currentPassword := password
-		if !noEncrypt && cfg.Backup.Encryption.Enabled && promptEachBackup {
-			currentPassword, err = utils.PromptForPassword(fmt.Sprintf("Enter encryption password for %s: ", mgr.Name()))
+		if needsPassword && promptEachBackup {
+			currentPassword, err = utils.PromptForSecret(fmt.Sprintf("Enter encryption password for %s: ", mgr.Name()))
 			if err != nil {
 				logger.PrintError(err)
 				continue
 			}
-			if currentPassword == "" {
+			if currentPassword.Len() == 0 {
+				currentPassword.Destroy()
 				logger.Failure("Encryption password is required")
 				continue
 			}
+			if err := checkPasswordStrength(cfg, currentPassword); err != nil {
+				currentPassword.Destroy()
+				logger.PrintError(err)
+				continue
+			}
 		}
 
-		if err := backupManager(mgr, storageBackends, cfg, currentPassword); err != nil {
-			logger.PrintError(err)
-			// Continue with next manager
+		if !confirmExportMode(mgr) {
+			cancelErr := fmt.Errorf("backup cancelled by user")
+			logger.PrintError(cancelErr)
+			recordEvent(database.EventRecord{Kind: database.EventBackup, Manager: mgr.Name()}, cancelErr)
+			continue
 		}
 
-		// Clear password from memory if prompting each time
-		if promptEachBackup && currentPassword != "" {
-			// Overwrite the password in memory
-			for i := range currentPassword {
-				_ = i // Use the variable to avoid compiler warning
-			}
-			currentPassword = ""
+		jobs = append(jobs, backupJob{mgr: mgr, password: currentPassword})
+	}
+
+	// Cancel running CLI commands on Ctrl+C so temp files are cleaned up
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Back up managers concurrently, bounded by backup.max_parallel
+	parallel := backupParallelism(cfg, len(jobs))
+	if parallel > 1 {
+		logger.Separator()
+		logger.Info("Backing up %d managers in parallel", len(jobs))
+	}
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			break
 		}
+		job.mgr.SetContext(ctx)
+
+		// Sequential runs keep the original unprefixed output
+		out := logger.WithPrefix("")
+		if parallel > 1 {
+			out = logger.WithPrefix(job.mgr.Name())
+		} else {
+			logger.Separator()
+		}
+
+		wg.Add(1)
+		go func(job backupJob) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			backupErr := backupManager(out, job.mgr, storageBackends, cfg, job.password, destinationPasswords)
+			if promptEachBackup {
+				// This manager's own password isn't needed anymore
+				job.password.Destroy()
+			}
+			if backupErr != nil {
+				out.PrintError(backupErr)
+			}
+			recordEvent(database.EventRecord{Kind: database.EventBackup, Manager: job.mgr.Name()}, backupErr)
+			report.add(job.mgr.Name(), backupErr)
+		}(job)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		report.discard()
+		logger.Separator()
+		logger.Warning("⚠️  Backup interrupted")
+		return
 	}
 
+	report.complete()
 	logger.Separator()
 	logger.Success("✅ Backup completed!")
+	nudgeChecklist(cfg)
 }
 
-func backupManager(mgr managers.Manager, storageBackends []storage.Storage, cfg *config.Config, password string) error {
-	logger.Progress("Backing up %s...", mgr.Name())
+// backupJob is one manager's backup with the encryption password it uses
+type backupJob struct {
+	mgr      managers.Manager
+	password *secret.Buffer
+}
+
+// backupParallelism returns how many manager backups run at once
+func backupParallelism(cfg *config.Config, jobs int) int {
+	parallel := cfg.Backup.MaxParallel
+	if parallelFlag > 0 {
+		parallel = parallelFlag
+	}
+	return max(1, min(parallel, jobs))
+}
+
+// backupTempDir returns the directory vault exports are written to before encryption
+func backupTempDir(cfg *config.Config) string {
+	if tempDirFlag != "" {
+		return tempDirFlag
+	}
+	return cfg.Backup.TempDir
+}
+
+// confirmExportMode warns about 1Password's metadata-only export and asks to continue
+func confirmExportMode(mgr managers.Manager) bool {
+	if _, ok := mgr.(*managers.OnePassword); !ok || fullExport {
+		return true
+	}
+
+	logger.Separator()
+	logger.Warning("⚠️  1PASSWORD BACKUP MODE: Metadata Only (Fast)")
+	logger.Info("")
+	logger.Info("This backup will include:")
+	logger.Info("  ✓ Item titles, usernames, URLs")
+	logger.Info("  ✓ Categories and tags")
+	logger.Info("  ✗ Actual passwords (NOT included)")
+	logger.Info("")
+	logger.Info("For a complete backup with passwords, use: --full-export")
+	logger.Info("Note: Full export is slower but includes all sensitive data")
+	logger.Separator()
+
+	if nonInteractive {
+		logger.Info("Continuing with a metadata-only backup (non-interactive)")
+		return true
+	}
+	return utils.ConfirmPrompt("Continue with metadata-only backup?")
+}
+
+// backendLocks serializes access to each storage backend across concurrent manager backups
+var backendLocks sync.Map
+
+// lockBackend locks a backend by name and returns the unlock function
+func lockBackend(backend storage.Storage) func() {
+	lock, _ := backendLocks.LoadOrStore(backend.Name(), &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// leaseHolder names this host in destination leases: its identity, or its hostname
+func leaseHolder() string {
+	if current, err := loadIdentity(); err == nil && current != nil {
+		return current.ID
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "unknown host"
+}
+
+func backupManager(out *logger.Scope, mgr managers.Manager, storageBackends []storage.Storage, cfg *config.Config, password *secret.Buffer, destinationPasswords map[string]*secret.Buffer) error {
+	out.Progress("Backing up %s...", mgr.Name())
+	labels := cfg.ManagerLabels(mgr.Name())
+	if len(labels) > 0 {
+		out.Info("  Classification: %s", formatTags(labels))
+	}
+
+	// Drop destinations a policy forbids before anything is exported
+	var permitted []storage.Storage
+	for _, backend := range storageBackends {
+		if err := checkPolicies(cfg, mgr.Name(), backend); err != nil {
+			out.Failure("✗ Upload to %s blocked: %v", backend.Name(), err)
+			recordEvent(database.EventRecord{Kind: database.EventUpload, Manager: mgr.Name(), StorageType: backend.Name()}, err)
+			continue
+		}
+		permitted = append(permitted, backend)
+	}
+	if len(permitted) == 0 {
+		return fmt.Errorf("no destination permitted by policy for %s", mgr.Name())
+	}
+	storageBackends = permitted
 
 	// Check if installed
 	if !mgr.IsInstalled() {
 		return fmt.Errorf("%s CLI is not installed", mgr.Name())
 	}
-	logger.Success("✓ %s CLI found", mgr.Name())
+	out.Success("✓ %s CLI found", mgr.Name())
 
 	// Check authentication
 	authenticated, err := mgr.IsAuthenticated()
@@ -181,16 +512,16 @@ func backupManager(mgr managers.Manager, storageBackends []storage.Storage, cfg
 	if !authenticated {
 		return fmt.Errorf("%s is not authenticated. Please login first", mgr.Name())
 	}
-	logger.Success("✓ Authenticated")
+	out.Success("✓ Authenticated")
 
 	// Get item count (if available)
 	itemCount, _ := mgr.GetItemCount()
 	if itemCount > 0 {
-		logger.Info("  Found %d items", itemCount)
+		out.Info("  Found %d items", itemCount)
 	}
 
 	// Create temporary file for export
-	tmpFile, err := utils.GetTempFile(fmt.Sprintf("stashr-%s-*.json", mgr.Name()))
+	tmpFile, err := utils.GetTempFile(backupTempDir(cfg), fmt.Sprintf("stashr-%s-*.json", mgr.Name()))
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -201,50 +532,30 @@ func backupManager(mgr managers.Manager, storageBackends []storage.Storage, cfg
 	if fullExport {
 		// Check if manager supports full export (1Password only)
 		if op, ok := mgr.(*managers.OnePassword); ok {
-			logger.Progress("Exporting vault data with full details (including passwords)...")
-			logger.Warning("⚠️  This may take several minutes for large vaults...")
+			out.Progress("Exporting vault data with full details (including passwords)...")
+			out.Warning("⚠️  This may take several minutes for large vaults...")
 
 			// Progress callback
 			currentItem := 0
 			progressCallback := func(current, total int, itemTitle string) {
 				currentItem = current
 				if current%10 == 0 || current == total {
-					logger.Info("  Processing item %d/%d: %s", current, total, itemTitle)
+					out.Info("  Processing item %d/%d: %s", current, total, itemTitle)
 				}
 			}
 
 			if err := op.ExportFull(tmpFile.Name(), progressCallback); err != nil {
 				return fmt.Errorf("full export failed: %w", err)
 			}
-			logger.Success("✓ Exported %d items with full details", currentItem)
+			out.Success("✓ Exported %d items with full details", currentItem)
 		} else {
-			logger.Warning("⚠️  Full export is only supported for 1Password. Using standard export for %s.", mgr.Name())
+			out.Warning("⚠️  Full export is only supported for 1Password. Using standard export for %s.", mgr.Name())
 			if err := mgr.Export(tmpFile.Name()); err != nil {
 				return fmt.Errorf("export failed: %w", err)
 			}
 		}
 	} else {
-		logger.Progress("Exporting vault data...")
-
-		// Warning for 1Password users about metadata-only export
-		if _, ok := mgr.(*managers.OnePassword); ok {
-			logger.Separator()
-			logger.Warning("⚠️  1PASSWORD BACKUP MODE: Metadata Only (Fast)")
-			logger.Info("")
-			logger.Info("This backup will include:")
-			logger.Info("  ✓ Item titles, usernames, URLs")
-			logger.Info("  ✓ Categories and tags")
-			logger.Info("  ✗ Actual passwords (NOT included)")
-			logger.Info("")
-			logger.Info("For a complete backup with passwords, use: --full-export")
-			logger.Info("Note: Full export is slower but includes all sensitive data")
-			logger.Separator()
-
-			if !utils.ConfirmPrompt("Continue with metadata-only backup?") {
-				return fmt.Errorf("backup cancelled by user")
-			}
-		}
-
+		out.Progress("Exporting vault data...")
 		if err := mgr.Export(tmpFile.Name()); err != nil {
 			return fmt.Errorf("export failed: %w", err)
 		}
@@ -255,16 +566,35 @@ func backupManager(mgr managers.Manager, storageBackends []storage.Storage, cfg
 	if err != nil {
 		return fmt.Errorf("failed to read exported data: %w", err)
 	}
+	// The export is the vault in plaintext: wipe it and its compressed copies once uploaded
+	defer secret.Wipe(exportedData)
 	originalSize := len(exportedData)
-	logger.Success("✓ Exported vault data (%s)", utils.FormatBytes(int64(originalSize)))
+	out.Success("✓ Exported vault data (%s)", utils.FormatBytes(int64(originalSize)))
+
+	// Validate the export so an empty or truncated vault is never uploaded
+	if skipValidation {
+		out.Warning("⚠️  Skipping export validation")
+	} else {
+		validated, err := managers.ValidateExport(mgr.Name(), exportedData, itemCount, cfg.Backup.Validation.TolerancePercent, cfg.Backup.Validation.AllowEmpty)
+		if err != nil {
+			return err
+		}
+		out.Success("✓ Validated export (%d items)", validated)
+	}
+
+	// Collect item statistics so the backup contents can be inspected without decrypting
+	stats, err := mgr.GetStats(tmpFile.Name())
+	if err != nil {
+		out.Warning("Failed to collect vault statistics: %v", err)
+	}
 
 	// Compress data if enabled
 	var processedData []byte
-	if cfg.Backup.Compression {
-		logger.Progress("Compressing data...")
+	if algorithm := cfg.Backup.CompressionAlgorithm(); algorithm != utils.CompressionNone {
+		out.Progress("Compressing data with %s...", algorithm)
 
 		// Show progress bar for large data (> 5MB)
-		if originalSize > 5*1024*1024 {
+		if originalSize > 5*1024*1024 && !out.Prefixed() {
 			bar := progressbar.NewOptions(originalSize,
 				progressbar.OptionSetDescription("Compressing"),
 				progressbar.OptionSetWidth(40),
@@ -274,98 +604,808 @@ func backupManager(mgr managers.Manager, storageBackends []storage.Storage, cfg
 			bar.Add(originalSize) // Compression is too fast to show real progress, so just complete it
 		}
 
-		compressedData, err := utils.CompressData(exportedData)
+		compressedData, err := utils.Compress(exportedData, algorithm, cfg.Backup.CompressionLevel)
 		if err != nil {
 			return fmt.Errorf("compression failed: %w", err)
 		}
 		processedData = compressedData
+		defer secret.Wipe(compressedData)
 		compressedSize := len(compressedData)
-		logger.Success("✓ Compressed (%s → %s)", utils.FormatBytes(int64(originalSize)), utils.FormatBytes(int64(compressedSize)))
+		out.Success("✓ Compressed (%s → %s)", utils.FormatBytes(int64(originalSize)), utils.FormatBytes(int64(compressedSize)))
 	} else {
 		processedData = exportedData
 	}
 
-	// Encrypt data if enabled
-	if !noEncrypt && cfg.Backup.Encryption.Enabled {
-		logger.Progress("Encrypting backup...")
+	// Encrypted copies use the manager's trained dictionary when it beats backup.compression
+	var dictionaryData []byte
+	var dictionaryID uint32
+	if dictionaryEnabled(cfg) {
+		dictionaryData, dictionaryID = compressWithDictionary(out, cfg, mgr.Name(), exportedData, len(processedData))
+		defer secret.Wipe(dictionaryData)
+	}
 
-		// Show progress bar for large data (> 5MB)
-		if len(processedData) > 5*1024*1024 {
-			bar := progressbar.NewOptions(len(processedData),
-				progressbar.OptionSetDescription("Encrypting"),
-				progressbar.OptionSetWidth(40),
-				progressbar.OptionShowBytes(true),
-				progressbar.OptionClearOnFinish(),
-			)
-			bar.Add(len(processedData)) // Encryption is too fast to show real progress, so just complete it
+	// Build one artifact per encryption requirement, then upload it to the matching destinations
+	timestamp := time.Now()
+	// Encrypted backups record what they contain, for restore --preview
+	metadata := &crypto.Metadata{
+		Manager:          mgr.Name(),
+		CreatedAt:        timestamp.UTC(),
+		ItemCount:        itemCount,
+		AppVersion:       version.Version,
+		UncompressedSize: int64(originalSize),
+		Compression:      cfg.Backup.CompressionAlgorithm(),
+	}
+	if stats != nil {
+		metadata.ItemCount = stats.TotalItems
+	}
+	artifacts := make(map[string]*backupArtifact)
+	var artifactOrder []*backupArtifact
+	var uploads []backupUpload
+	// Destinations the pre-flight checks skipped get the backup once they are back
+	var deferredUploads []backupUpload
+	targets := append(append([]storage.Storage(nil), storageBackends...), deferredBackends(cfg, mgr.Name())...)
+	for _, backend := range targets {
+		deferred := !slices.Contains(storageBackends, backend)
+		mode := effectiveEncryptionMode(cfg, backend)
+		extension := destinationArtifact(cfg, backend).Extension
+		key := mode + extension
+		artifactPassword := password
+		recipients := encryptionRecipients(cfg, backend, mode)
+		if mode == config.EncryptionModeAge || mode == config.EncryptionModeGPG {
+			// Destinations with the same recipients share a file
+			key += ":" + strings.Join(recipients, ",")
+		}
+		if pw, ok := destinationPasswords[backend.Name()]; ok {
+			key = mode + ":" + backend.Name()
+			artifactPassword = pw
+		} else if deferred && mode == config.EncryptionModePassword && destinationEncryption(cfg, backend).SeparatePassword {
+			out.Warning("⚠ Not queuing the upload to %s: it uses a separate password, which wasn't asked for", backend.Name())
+			continue
+		}
+		// Each deduplicated destination encrypts with its own salt
+		dedup := storage.IsDeduplicated(backend)
+		if dedup {
+			key = "dedup:" + backend.Name()
 		}
 
-		encryptedData, err := crypto.Encrypt(processedData, password)
-		if err != nil {
-			return fmt.Errorf("encryption failed: %w", err)
+		artifact, ok := artifacts[key]
+		if !ok {
+			// Unencrypted copies skip the dictionary, so standard tools can read them
+			artifactData, artifactMetadata := processedData, metadata
+			var artifactDictionary uint32
+			if mode == config.EncryptionModePassword && dictionaryData != nil {
+				artifactData, artifactDictionary = dictionaryData, dictionaryID
+				withDictionary := *metadata
+				withDictionary.Compression = "dictionary"
+				artifactMetadata = &withDictionary
+			}
+			if dedup {
+				artifact, err = buildDedupArtifact(out, exportedData, mode, extension, artifactPassword, mgr.Name(), timestamp, cfg, backend)
+			} else {
+				artifact, err = buildArtifact(out, artifactData, mode, extension, artifactPassword, recipients, mgr.Name(), timestamp, cfg, artifactMetadata)
+			}
+			if err != nil {
+				out.Warning("⚠ %s: %v", backend.Name(), err)
+				continue
+			}
+			if cfg.Backup.ObfuscateNames {
+				if err := obfuscateArtifact(artifact, extension); err != nil {
+					out.Warning("⚠ %s: %v", backend.Name(), err)
+					continue
+				}
+			}
+			artifact.encrypted = mode != config.EncryptionModeNone
+			if !dedup {
+				artifact.dictionary = artifactDictionary
+			}
+			artifacts[key] = artifact
+			artifactOrder = append(artifactOrder, artifact)
 		}
-		processedData = encryptedData
-		logger.Success("✓ Encrypted")
+		if deferred {
+			deferredUploads = append(deferredUploads, backupUpload{backend: backend, artifact: artifact, err: errDeferredUpload, retryable: true})
+			continue
+		}
+		uploads = append(uploads, backupUpload{backend: backend, artifact: artifact})
 	}
 
-	// Generate backup filename
-	filenameFormat := cfg.Backup.FilenameFormat
-	// If encryption is disabled, remove .enc extension
-	if noEncrypt || !cfg.Backup.Encryption.Enabled {
-		// Replace .enc extension with appropriate extension based on compression
-		if cfg.Backup.Compression {
-			filenameFormat = "backup_%s_%s.json.gz"
-		} else {
-			filenameFormat = "backup_%s_%s.json"
+	resolveCollisions(out, cfg, uploads)
+	runUploads(out, uploads, cfg)
+	for _, upload := range uploads {
+		if upload.err != nil {
+			recordEvent(database.EventRecord{Kind: database.EventUpload, Manager: mgr.Name(), StorageType: upload.backend.Name(), Filename: upload.artifact.filename}, upload.err)
+			continue
+		}
+		if upload.artifact.successfulStorage == "" {
+			// The database records the first destination, in health order, that has the backup
+			upload.artifact.successfulStorage = upload.backend.Name()
+		}
+		// Artifacts of the same name differ between destinations, so each copy's checksum is kept
+		if err := database.RecordBackupCopy(upload.artifact.filename, upload.backend.Name(), utils.SHA256Hex(upload.artifact.data), int64(len(upload.artifact.data))); err != nil {
+			out.Warning("Failed to record backup checksum: %v", err)
+		}
+		if upload.artifact.dictionary != 0 {
+			storeDictionaryCopy(out, cfg, upload.backend, upload.artifact.dictionary)
 		}
 	}
-	filename := utils.GenerateBackupFilename(filenameFormat, mgr.Name())
-	finalSize := len(processedData)
+	// Destinations that failed, like an unplugged USB drive, get the backup later
+	queueFailedUploads(out, mgr.Name(), append(uploads, deferredUploads...))
 
-	// Upload to each storage backend
 	successCount := 0
-	var successfulStorage string
-	for _, backend := range storageBackends {
-		if err := uploadToBackend(backend, filename, processedData, cfg); err != nil {
-			logger.Warning("⚠ %s: %v", backend.Name(), err)
+	finalSize := 0
+	for _, artifact := range artifactOrder {
+		if artifact.successfulStorage == "" {
+			continue
+		}
+		successCount++
+		if len(artifact.data) > finalSize {
+			finalSize = len(artifact.data)
+		}
+
+		// Record backup in database
+		if err := database.RecordBackup(artifact.filename, mgr.Name(), artifact.successfulStorage, int64(len(artifact.data)), backupTags, backupNotes); err != nil {
+			out.Warning("Failed to record backup in database: %v", err)
+			// Don't fail the backup if database recording fails
 		} else {
-			successCount++
-			if successfulStorage == "" {
-				successfulStorage = backend.Name()
+			if err := database.UpdateBackupChecksum(artifact.filename, utils.SHA256Hex(artifact.data)); err != nil {
+				out.Warning("Failed to record backup checksum: %v", err)
+			}
+			// Copies that failed verification were not counted as uploaded
+			if verifyUploads(cfg) {
+				if err := database.UpdateBackupVerified(artifact.filename, time.Now()); err != nil {
+					out.Warning("Failed to record backup verification: %v", err)
+				}
+			}
+			if err := database.UpdateBackupLabels(artifact.filename, labels); err != nil {
+				out.Warning("Failed to record backup labels: %v", err)
+			}
+			// Names stay parseable after backup.filename_format changes
+			if err := database.RecordFilenameFormat(artifact.format); err != nil {
+				out.Warning("Failed to record filename format: %v", err)
+			}
+			if stats != nil {
+				statsJSON, _ := json.Marshal(stats)
+				if err := database.UpdateBackupStats(artifact.filename, stats.TotalItems, string(statsJSON)); err != nil {
+					out.Warning("Failed to record vault statistics: %v", err)
+				}
 			}
 		}
+
+		publishProof(out, cfg, mgr.Name(), artifact)
 	}
 
 	if successCount == 0 {
 		return fmt.Errorf("failed to upload to any storage backend")
 	}
 
-	// Record backup in database
-	if err := database.RecordBackup(filename, mgr.Name(), successfulStorage, int64(finalSize), backupTags, backupNotes); err != nil {
-		logger.Warning("Failed to record backup in database: %v", err)
-		// Don't fail the backup if database recording fails
+	if dictionaryEnabled(cfg) {
+		trainDictionary(out, cfg, mgr.Name(), exportedData)
+	}
+
+	out.Success("✅ Backup completed for %s (%s)", mgr.Name(), utils.FormatBytes(int64(finalSize)))
+	return nil
+}
+
+// backupUpload is the upload of an artifact to one destination, and its outcome
+type backupUpload struct {
+	backend  storage.Storage
+	artifact *backupArtifact
+	err      error
+	duration time.Duration
+
+	// retryable uploads were attempted rather than refused, so they can be retried later
+	retryable bool
+}
+
+// uploadParallelism returns how many destinations a backup is uploaded to at once
+func uploadParallelism(cfg *config.Config, uploads int) int {
+	parallel := cfg.Backup.MaxParallelUploads
+	if parallelUploads > 0 {
+		parallel = parallelUploads
+	}
+	return max(1, min(parallel, uploads))
+}
+
+// runUploads uploads each artifact to its destination, up to
+// backup.max_parallel_uploads at once, and records the outcomes in uploads.
+// Parallel uploads prefix their output with the destination and end with a summary.
+func runUploads(out *logger.Scope, uploads []backupUpload, cfg *config.Config) {
+	parallel := uploadParallelism(cfg, len(uploads))
+	if parallel == 1 {
+		for i := range uploads {
+			uploads[i].run(out, cfg)
+		}
+		return
+	}
+
+	out.Progress("Uploading to %d destinations in parallel...", len(uploads))
+	startTime := time.Now()
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range uploads {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(upload *backupUpload) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			upload.run(out.WithPrefix(upload.backend.Name()), cfg)
+		}(&uploads[i])
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, upload := range uploads {
+		if upload.err != nil {
+			out.Failure("✗ %s: failed after %.1fs", upload.backend.Name(), upload.duration.Seconds())
+			continue
+		}
+		succeeded++
+		out.Success("✓ %s: done in %.1fs", upload.backend.Name(), upload.duration.Seconds())
+	}
+	out.Info("  Uploaded to %d of %d destinations in %.1fs", succeeded, len(uploads), time.Since(startTime).Seconds())
+}
+
+// run uploads the artifact and records the outcome
+func (u *backupUpload) run(out *logger.Scope, cfg *config.Config) {
+	// Refused before it started
+	if u.err != nil {
+		out.Warning("⚠ %s: %v", u.backend.Name(), u.err)
+		return
+	}
+	startTime := time.Now()
+	u.err = uploadToBackend(out, u.backend, u.artifact.filename, u.artifact.data, cfg)
+	u.duration = time.Since(startTime)
+	u.retryable = u.err != nil
+	if u.err != nil {
+		out.Warning("⚠ %s: %v", u.backend.Name(), u.err)
+	}
+}
+
+// resolveCollisions applies backup.on_collision to the uploads whose filename
+// their destination already has. "refuse" fails those uploads, "version"
+// renames the artifact to its first -N version none of its destinations has,
+// and "overwrite" lets the uploads replace the existing files.
+func resolveCollisions(out *logger.Scope, cfg *config.Config, uploads []backupUpload) {
+	policy := cfg.Backup.OnCollision
+	if policy == config.CollisionOverwrite {
+		return
+	}
+
+	// A destination that can't be listed fails its upload with a better error
+	listings := make(map[storage.Storage]map[string]bool)
+	has := func(backend storage.Storage, filename string) bool {
+		names, ok := listings[backend]
+		if !ok {
+			names = make(map[string]bool)
+			if backups, err := backend.List(); err == nil {
+				for _, backup := range backups {
+					names[backup.Name] = true
+				}
+			}
+			listings[backend] = names
+		}
+		return names[filename]
+	}
+
+	var versioned []*backupArtifact
+	for i := range uploads {
+		upload := &uploads[i]
+		if !has(upload.backend, upload.artifact.filename) {
+			continue
+		}
+		if policy == config.CollisionRefuse {
+			upload.err = fmt.Errorf("%s already exists (backup.on_collision: refuse)", upload.artifact.filename)
+			continue
+		}
+		if !slices.Contains(versioned, upload.artifact) {
+			versioned = append(versioned, upload.artifact)
+		}
+	}
+
+	for _, artifact := range versioned {
+		for n := 1; ; n++ {
+			filename := backupname.Versioned(artifact.filename, n)
+			taken := false
+			for _, upload := range uploads {
+				if upload.artifact == artifact && has(upload.backend, filename) {
+					taken = true
+					break
+				}
+			}
+			if !taken {
+				out.Info("  %s already exists, saving as %s", artifact.filename, filename)
+				artifact.filename = filename
+				break
+			}
+		}
+	}
+}
+
+// recordEvent records the outcome of an operation in the event log.
+// Failing to record is not fatal; the event log only feeds reports.
+func recordEvent(event database.EventRecord, err error) {
+	event.Success = err == nil
+	if err != nil {
+		event.Message = err.Error()
+	}
+	if recordErr := database.RecordEvent(event); recordErr != nil {
+		logger.Debug("Failed to record %s event: %v", event.Kind, recordErr)
+	}
+}
+
+// backupArtifact is a processed backup uploaded to one or more destinations
+type backupArtifact struct {
+	filename string
+	// format is the filename template the file was named with
+	format            string
+	data              []byte
+	successfulStorage string
+
+	// encrypted artifacts can be kept on disk until a failed upload is retried
+	encrypted bool
+	// dictionary is the ID of the dictionary the artifact is compressed with, 0 if none
+	dictionary uint32
+}
+
+// buildArtifact encrypts the processed data as required and names the resulting
+// file. Password and key file backups record metadata in their header.
+func buildArtifact(out *logger.Scope, data []byte, mode, extension string, password *secret.Buffer, recipients []string, manager string, timestamp time.Time, cfg *config.Config, metadata *crypto.Metadata) (*backupArtifact, error) {
+	format := artifactFormat(cfg, mode, extension)
+	if cfg.Backup.ObfuscateNames && metadata != nil {
+		// The header keeps the name the file is no longer uploaded under
+		named := *metadata
+		named.Filename = path.Base(artifactFilename(cfg, format, manager, timestamp))
+		metadata = &named
+	}
+	switch mode {
+	case config.EncryptionModeAge:
+		return buildAgeArtifact(out, data, recipients, manager, timestamp, cfg, format)
+	case config.EncryptionModeGPG:
+		return buildGPGArtifact(out, data, recipients, manager, timestamp, cfg, format)
+	}
+	if mode != config.EncryptionModePassword {
+		return &backupArtifact{
+			filename: artifactFilename(cfg, format, manager, timestamp),
+			format:   format,
+			data:     data,
+		}, nil
+	}
+
+	if password == nil && backupKey != nil {
+		out.Progress("Encrypting backup with key file...")
+		encryptedData, err := crypto.EncryptWithKey(data, backupKey.Bytes(), metadata)
+		if err != nil {
+			return nil, fmt.Errorf("encryption failed: %w", err)
+		}
+		out.Success("✓ Encrypted")
+		return &backupArtifact{
+			filename: artifactFilename(cfg, format, manager, timestamp),
+			format:   format,
+			data:     encryptedData,
+		}, nil
+	}
+	if password.Len() == 0 {
+		return nil, fmt.Errorf("encryption password is required")
+	}
+
+	out.Progress("Encrypting backup...")
+
+	// Show progress bar for large data (> 5MB)
+	if len(data) > 5*1024*1024 && !out.Prefixed() {
+		bar := progressbar.NewOptions(len(data),
+			progressbar.OptionSetDescription("Encrypting"),
+			progressbar.OptionSetWidth(40),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionClearOnFinish(),
+		)
+		bar.Add(len(data)) // Encryption is too fast to show real progress, so just complete it
+	}
+
+	encryptedData, err := crypto.EncryptWithPassword(data, password, cfg.Backup.Encryption.KeyIterations(), metadata)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+	out.Success("✓ Encrypted")
+
+	return &backupArtifact{
+		filename: artifactFilename(cfg, format, manager, timestamp),
+		format:   format,
+		data:     encryptedData,
+	}, nil
+}
+
+// buildAgeArtifact encrypts the processed data to age recipients, so that no
+// password is needed to make the backup
+func buildAgeArtifact(out *logger.Scope, data []byte, recipients []string, manager string, timestamp time.Time, cfg *config.Config, format string) (*backupArtifact, error) {
+	var parsed []crypto.AgeRecipient
+	for _, recipient := range recipients {
+		r, err := crypto.ParseAgeRecipient(recipient)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+
+	out.Progress("Encrypting backup to %d age recipient(s)...", len(parsed))
+	encryptedData, err := crypto.EncryptAge(data, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+	out.Success("✓ Encrypted")
+
+	return &backupArtifact{
+		filename: artifactFilename(cfg, format, manager, timestamp),
+		format:   format,
+		data:     encryptedData,
+	}, nil
+}
+
+// buildGPGArtifact encrypts the processed data to GPG recipients with the gpg tool
+func buildGPGArtifact(out *logger.Scope, data []byte, recipients []string, manager string, timestamp time.Time, cfg *config.Config, format string) (*backupArtifact, error) {
+	out.Progress("Encrypting backup to %d GPG recipient(s)...", len(recipients))
+	encryptedData, err := crypto.GPGEncrypt(cfg.Backup.Encryption.GPG.Executable(), data, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
 	}
+	out.Success("✓ Encrypted")
 
-	logger.Success("✅ Backup completed for %s (%s)", mgr.Name(), utils.FormatBytes(int64(finalSize)))
+	return &backupArtifact{
+		filename: artifactFilename(cfg, format, manager, timestamp),
+		format:   format,
+		data:     encryptedData,
+	}, nil
+}
+
+// buildDedupArtifact builds the file for a deduplicated destination: the export
+// is compressed and encrypted in content-defined pieces, so that consecutive
+// backups share most of their bytes and the destination stores them once
+func buildDedupArtifact(out *logger.Scope, data []byte, mode, extension string, password *secret.Buffer, manager string, timestamp time.Time, cfg *config.Config, backend storage.Storage) (*backupArtifact, error) {
+	pieces := storage.DedupPieces(data)
+	if algorithm := cfg.Backup.CompressionAlgorithm(); algorithm != utils.CompressionNone {
+		// Concatenated gzip, zstd and xz streams decompress like a single one
+		for i, piece := range pieces {
+			compressed, err := utils.Compress(piece, algorithm, cfg.Backup.CompressionLevel)
+			if err != nil {
+				return nil, fmt.Errorf("compression failed: %w", err)
+			}
+			pieces[i] = compressed
+		}
+		defer func() {
+			for _, piece := range pieces {
+				secret.Wipe(piece)
+			}
+		}()
+	}
+
+	if mode == config.EncryptionModeAge || mode == config.EncryptionModeGPG {
+		return nil, fmt.Errorf("deduplicated destinations cannot use %s encryption", mode)
+	}
+
+	format := artifactFormat(cfg, mode, extension)
+	artifact := &backupArtifact{
+		filename: artifactFilename(cfg, format, manager, timestamp),
+		format:   format,
+	}
+	if mode != config.EncryptionModePassword {
+		artifact.data = bytes.Join(pieces, nil)
+		return artifact, nil
+	}
+
+	if password == nil && backupKey != nil {
+		return nil, fmt.Errorf("deduplicated destinations need a password; they can't use a key file")
+	}
+	if password.Len() == 0 {
+		return nil, fmt.Errorf("encryption password is required")
+	}
+	salt, err := storage.DedupSalt(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	out.Progress("Encrypting backup for deduplication...")
+	artifact.data, err = crypto.EncryptChunks(pieces, password, salt, cfg.Backup.Encryption.KeyIterations())
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+	out.Success("✓ Encrypted (%d pieces)", len(pieces))
+	return artifact, nil
+}
+
+// artifactFormat returns the filename template for an encryption mode and a
+// destination's extension override
+func artifactFormat(cfg *config.Config, mode, extension string) string {
+	filenameFormat := cfg.Backup.FilenameFormat
+	compression := utils.CompressionExtension(cfg.Backup.CompressionAlgorithm())
+	switch mode {
+	case config.EncryptionModePassword:
+	case config.EncryptionModeAge:
+		// Files encrypted to public keys keep the extension their tools expect
+		filenameFormat = "backup_%s_%s.json" + compression + ".age"
+	case config.EncryptionModeGPG:
+		filenameFormat = "backup_%s_%s.json" + compression + ".gpg"
+	default:
+		// Unencrypted backups use an extension that reflects their content
+		filenameFormat = "backup_%s_%s.json" + compression
+	}
+	if extension != "" {
+		filenameFormat = trimBackupExtension(filenameFormat) + extension
+	}
+	return filenameFormat
+}
+
+// artifactFilename names the backup file after a filename template, inside the
+// subfolder the folder layout calls for
+func artifactFilename(cfg *config.Config, format, manager string, timestamp time.Time) string {
+	return backupFilePath(cfg, manager, timestamp, utils.GenerateBackupFilenameAt(format, manager, timestamp))
+}
+
+// obfuscateArtifact renames an artifact to a random name that says nothing
+// about the backup, and records the name it stands for so listing, restore
+// and retention still see its manager and date
+func obfuscateArtifact(artifact *backupArtifact, extension string) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate backup name: %w", err)
+	}
+	// A destination's extension override is kept; it is the same for every backup
+	if extension == "" {
+		extension = obfuscatedExtension
+	}
+	name := hex.EncodeToString(id) + extension
+	key, err := obfuscatedNamesKey(true)
+	if err != nil {
+		return fmt.Errorf("failed to get the key for obfuscated names: %w", err)
+	}
+	if err := database.RecordObfuscatedName(name, path.Base(artifact.filename), key); err != nil {
+		return fmt.Errorf("failed to record obfuscated name: %w", err)
+	}
+	artifact.filename = name
 	return nil
 }
 
-func uploadToBackend(backend storage.Storage, filename string, data []byte, cfg *config.Config) error {
+// obfuscatedExtension is the extension of backups uploaded under random names
+const obfuscatedExtension = ".bin"
+
+// backupFilePath places a backup file in the subfolder the folder layout calls for.
+// Destinations create the subfolders on upload.
+func backupFilePath(cfg *config.Config, manager string, timestamp time.Time, filename string) string {
+	switch cfg.Backup.FolderLayout {
+	case config.FolderLayoutManager:
+		return path.Join(manager, filename)
+	case config.FolderLayoutManagerMonth:
+		return path.Join(manager, timestamp.Format("2006-01"), filename)
+	default:
+		return filename
+	}
+}
+
+// destinationEncryption returns the encryption override configured for a backend
+func destinationEncryption(cfg *config.Config, backend storage.Storage) config.DestinationEncryptionConfig {
+	dest, ok := destinationForBackend(cfg, backend)
+	if !ok {
+		return config.DestinationEncryptionConfig{}
+	}
+	return dest.encryption
+}
+
+// destinationArtifact returns the file extension and MIME type configured for a backend
+func destinationArtifact(cfg *config.Config, backend storage.Storage) config.ArtifactConfig {
+	dest, ok := destinationForBackend(cfg, backend)
+	if !ok {
+		return config.ArtifactConfig{}
+	}
+	return dest.artifact
+}
+
+// effectiveEncryptionMode resolves the encryption mode used for a backend.
+// A destination override wins over the global setting and the --no-encrypt flag.
+func effectiveEncryptionMode(cfg *config.Config, backend storage.Storage) string {
+	if mode := destinationEncryption(cfg, backend).Mode; mode != config.EncryptionModeInherit {
+		return mode
+	}
+	if !noEncrypt && cfg.Backup.Encryption.Enabled {
+		switch {
+		case len(cfg.Backup.Encryption.Recipients) > 0:
+			return config.EncryptionModeAge
+		case len(cfg.Backup.Encryption.GPG.Recipients) > 0:
+			return config.EncryptionModeGPG
+		}
+		return config.EncryptionModePassword
+	}
+	return config.EncryptionModeNone
+}
+
+// encryptionRecipients returns the age or GPG recipients backups for a backend
+// are encrypted to: the destination's own, or the global ones for the mode
+func encryptionRecipients(cfg *config.Config, backend storage.Storage, mode string) []string {
+	if recipients := destinationEncryption(cfg, backend).Recipients; len(recipients) > 0 {
+		return recipients
+	}
+	if mode == config.EncryptionModeGPG {
+		return cfg.Backup.Encryption.GPG.Recipients
+	}
+	return cfg.Backup.Encryption.Recipients
+}
+
+// requiresSharedPassword checks if any backend is encrypted with the shared password
+func requiresSharedPassword(cfg *config.Config, backends []storage.Storage) bool {
+	for _, backend := range backends {
+		if effectiveEncryptionMode(cfg, backend) == config.EncryptionModePassword && !destinationEncryption(cfg, backend).SeparatePassword {
+			return true
+		}
+	}
+	return false
+}
+
+// promptDestinationPasswords prompts for the dedicated password of each destination that requires one
+func promptDestinationPasswords(cfg *config.Config, backends []storage.Storage) (map[string]*secret.Buffer, error) {
+	passwords := make(map[string]*secret.Buffer)
+	for _, backend := range backends {
+		if effectiveEncryptionMode(cfg, backend) != config.EncryptionModePassword || !destinationEncryption(cfg, backend).SeparatePassword {
+			continue
+		}
+
+		if nonInteractive {
+			failInputRequired(inputDestinationPassword, fmt.Sprintf("%s uses a separate encryption password, which can't be prompted for non-interactively", backend.Name()))
+		}
+		password, err := promptDestinationPassword(cfg, backend)
+		if err != nil {
+			for _, pw := range passwords {
+				pw.Destroy()
+			}
+			return nil, err
+		}
+		passwords[backend.Name()] = password
+	}
+	return passwords, nil
+}
+
+// promptDestinationPassword prompts for and confirms the dedicated password of a destination
+func promptDestinationPassword(cfg *config.Config, backend storage.Storage) (*secret.Buffer, error) {
+	password, err := utils.PromptForSecret(fmt.Sprintf("Enter encryption password for %s: ", backend.Name()))
+	if err != nil {
+		return nil, err
+	}
+	if password.Len() == 0 {
+		password.Destroy()
+		return nil, fmt.Errorf("encryption password for %s is required", backend.Name())
+	}
+	confirmPassword, err := utils.PromptForSecret(fmt.Sprintf("Confirm encryption password for %s: ", backend.Name()))
+	if err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	matches := password.Equal(confirmPassword)
+	confirmPassword.Destroy()
+	if !matches {
+		password.Destroy()
+		return nil, fmt.Errorf("passwords for %s do not match", backend.Name())
+	}
+	if err := checkPasswordStrength(cfg, password); err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	return password, nil
+}
+
+// checkPasswordStrength warns about a weak new encryption password, and rejects
+// one estimated below backup.encryption.min_entropy. The estimator works on
+// strings, so it sees an unprotected copy of the password.
+func checkPasswordStrength(cfg *config.Config, password *secret.Buffer) error {
+	// Words an attacker targeting this backup would try first
+	inputs := []string{"stashr", "bitwarden", "1password", "onepassword"}
+	if hostname, err := os.Hostname(); err == nil {
+		inputs = append(inputs, hostname)
+	}
+	if current, err := user.Current(); err == nil {
+		inputs = append(inputs, current.Username, current.Name)
+	}
+
+	result := strength.Estimate(string(password.Bytes()), inputs...)
+	minEntropy := cfg.Backup.Encryption.MinEntropy
+	if result.Score >= 3 && result.Entropy >= float64(minEntropy) {
+		return nil
+	}
+
+	if result.Warning != "" {
+		logger.Warning("⚠️  %s", result.Warning)
+	}
+	for _, suggestion := range result.Suggestions {
+		logger.Info("💡 %s", suggestion)
+	}
+	if minEntropy > 0 && result.Entropy < float64(minEntropy) {
+		return fmt.Errorf("encryption password is too weak: about %.0f bits, backup.encryption.min_entropy requires %d", result.Entropy, minEntropy)
+	}
+	logger.Warning("⚠️  Weak encryption password (about %.0f bits): backups can be attacked offline, so a stronger one is recommended", result.Entropy)
+	return nil
+}
+
+// verifyUploads reports whether stored copies are checked after uploading
+func verifyUploads(cfg *config.Config) bool {
+	return cfg.Backup.VerifyUploads && !noVerify
+}
+
+// checkFreeSpace makes sure a backend has room for a backup plus the configured
+// headroom. Backends that can't report their free space are not checked.
+func checkFreeSpace(out *logger.Scope, backend storage.Storage, size int64, cfg *config.Config) error {
+	action := cfg.Backup.FreeSpace.Action
+	if action == config.FreeSpaceOff {
+		return nil
+	}
+
+	free, err := storage.FreeSpace(backend)
+	if errors.Is(err, storage.ErrFreeSpaceUnknown) {
+		return nil
+	}
+	if err != nil {
+		out.Warning("⚠ Could not check free space on %s: %v", backend.Name(), err)
+		return nil
+	}
+
+	needed := size + int64(cfg.Backup.FreeSpace.HeadroomMB)*1024*1024
+	if free >= needed {
+		return nil
+	}
+	shortage := fmt.Sprintf("%s free, %s needed (%s backup + %d MB headroom)",
+		utils.FormatBytes(free), utils.FormatBytes(needed), utils.FormatBytes(size), cfg.Backup.FreeSpace.HeadroomMB)
+	if action == config.FreeSpaceWarn {
+		out.Warning("⚠ Low free space on %s: %s", backend.Name(), shortage)
+		return nil
+	}
+	return fmt.Errorf("not enough free space: %s", shortage)
+}
+
+// waitForSync warns when a destination that syncs in the background, like
+// iCloud Drive, hasn't got a backup off this machine within its sync timeout
+func waitForSync(out *logger.Scope, backend storage.Storage, filename string) {
+	if !storage.SupportsSyncStatus(backend) {
+		return
+	}
+	out.Progress("Waiting for %s to upload the backup...", backend.Name())
+	state, err := storage.WaitForSync(backend, filename)
+	switch {
+	case err != nil:
+		out.Warning("⚠ %s: couldn't confirm the backup left this machine: %v", backend.Name(), err)
+	case state != storage.SyncUploaded:
+		out.Warning("⚠ %s: backup hasn't reached the cloud yet (%s); until it does it's only on this machine", backend.Name(), state)
+	default:
+		out.Success("✓ %s has the backup", backend.Name())
+	}
+}
+
+func uploadToBackend(out *logger.Scope, backend storage.Storage, filename string, data []byte, cfg *config.Config) error {
+	// Managers backed up in parallel take turns on each backend
+	defer lockBackend(backend)()
+
 	// Check availability
 	available, err := backend.IsAvailable()
 	if err != nil {
+		recordDestinationOp(backend, database.OperationUpload, time.Now(), err)
 		return err
 	}
 	if !available {
-		return fmt.Errorf("storage not available")
+		err := fmt.Errorf("storage not available")
+		recordDestinationOp(backend, database.OperationUpload, time.Now(), err)
+		return err
+	}
+
+	if err := checkFreeSpace(out, backend, int64(len(data)), cfg); err != nil {
+		return err
 	}
 
 	// Upload with progress bar
-	logger.Progress("Uploading to %s...", backend.Name())
+	out.Progress("Uploading to %s...", backend.Name())
 	startTime := time.Now()
 
-	// Show progress bar for large uploads (> 1MB)
-	if len(data) > 1024*1024 {
+	// Show progress for large uploads (> 1MB), as a bar unless other managers share the output
+	var progress storage.ProgressFunc
+	if len(data) > 1024*1024 && out.Prefixed() {
+		progress = func(sent, total int64) {
+			// Completion is reported by the upload result
+			if sent < total {
+				out.Info("  Uploaded %s of %s (%d%%)", utils.FormatBytes(sent), utils.FormatBytes(total), sent*100/total)
+			}
+		}
+	} else if len(data) > 1024*1024 {
 		bar := progressbar.NewOptions(len(data),
 			progressbar.OptionSetDescription(fmt.Sprintf("Uploading to %s", backend.Name())),
 			progressbar.OptionSetWidth(40),
@@ -379,31 +1419,79 @@ func uploadToBackend(backend storage.Storage, filename string, data []byte, cfg
 			}),
 			progressbar.OptionClearOnFinish(),
 		)
-		bar.Add(len(data))
+		progress = func(sent, total int64) {
+			bar.Set64(sent)
+		}
 	}
 
-	if err := backend.Upload(filename, data); err != nil {
+	if err := storage.UploadWithProgress(backend, filename, data, progress); err != nil {
+		recordDestinationOp(backend, database.OperationUpload, startTime, err)
 		return err
 	}
 
 	duration := time.Since(startTime)
-	logger.Success("✓ Uploaded to %s (%.1fs)", backend.Name(), duration.Seconds())
+	out.Success("✓ Uploaded to %s (%.1fs)", backend.Name(), duration.Seconds())
+
+	// A copy that doesn't match counts as a failed upload, so retention keeps older backups
+	if verifyUploads(cfg) {
+		out.Progress("Verifying stored copy...")
+		method, err := storage.VerifyUpload(backend, filename, data)
+		if err != nil {
+			err = fmt.Errorf("verification failed: %w", err)
+			recordDestinationOp(backend, database.OperationUpload, startTime, err)
+			return err
+		}
+		out.Success("✓ Verified stored copy (%s)", method)
+	}
+	recordDestinationOp(backend, database.OperationUpload, startTime, nil)
+	if backupSigner != nil {
+		// An unsigned backup is still a backup, so a failure only warns
+		if err := uploadSignature(backend, backupSigner, filename, data); err != nil {
+			out.Warning("⚠ Failed to upload signature: %v", err)
+		} else {
+			out.Success("✓ Signed as %s", backupSigner.ID)
+		}
+	}
+	waitForSync(out, backend, filename)
 
 	// Apply retention policy
-	logger.Progress("Applying retention policy...")
+	out.Progress("Applying retention policy...")
 	backups, err := backend.List()
 	if err != nil {
-		logger.Warning("Failed to list backups for retention: %v", err)
+		out.Warning("Failed to list backups for retention: %v", err)
+		return nil
+	}
+	if len(storage.RetentionCandidates(backups, destinationRetentionPolicy(cfg, backend), backupManagerOf(cfg), time.Now())) == 0 {
 		return nil
 	}
 
-	if err := storage.ApplyRetentionPolicy(backups, cfg.Backup.Retention.KeepLast, backend.Delete); err != nil {
-		logger.Warning("Failed to apply retention policy: %v", err)
-	} else {
-		deleted := len(backups) - cfg.Backup.Retention.KeepLast
-		if deleted > 0 {
-			logger.Info("  Deleted %d old backup(s)", deleted)
+	// Other hosts backing up to this destination must not prune at the same time
+	lease, err := storage.AcquireLease(backend, leaseHolder(), storage.DefaultLeaseTTL)
+	if err != nil {
+		out.Warning("Skipping retention policy: %v", err)
+		return nil
+	}
+	defer func() {
+		if err := lease.Release(); err != nil {
+			out.Warning("Failed to release lock on %s: %v", backend.Name(), err)
 		}
+	}()
+
+	// List again now that no other host is pruning
+	backups, err = backend.List()
+	if err != nil {
+		out.Warning("Failed to list backups for retention: %v", err)
+		return nil
+	}
+
+	deleted, err := storage.ApplyRetentionPolicy(backups, destinationRetentionPolicy(cfg, backend), backupManagerOf(cfg), retentionRemover(cfg, backend))
+	if err != nil {
+		out.Warning("Failed to apply retention policy: %v", err)
+	}
+	if deleted > 0 && destinationTrashDays(cfg, backend) > 0 {
+		out.Info("  Moved %d old backup(s) to the trash", deleted)
+	} else if deleted > 0 {
+		out.Info("  Deleted %d old backup(s)", deleted)
 	}
 
 	return nil
@@ -415,56 +1503,49 @@ func getManagersToBackup(cfg *config.Config) []managers.Manager {
 	// Check which managers to backup based on flag
 	if managerFlag == "all" || managerFlag == "bitwarden" {
 		if cfg.PasswordManagers.Bitwarden.Enabled {
-			mgrs = append(mgrs, managers.NewBitwarden(
-				cfg.PasswordManagers.Bitwarden.CLIPath,
-				cfg.PasswordManagers.Bitwarden.Email,
-			))
+			mgrs = append(mgrs, newBitwarden(cfg))
 		}
 	}
 
 	if managerFlag == "all" || managerFlag == "1password" {
 		if cfg.PasswordManagers.OnePassword.Enabled {
-			mgrs = append(mgrs, managers.NewOnePassword(
-				cfg.PasswordManagers.OnePassword.CLIPath,
-				cfg.PasswordManagers.OnePassword.Account,
-			))
+			mgrs = append(mgrs, newOnePassword(cfg))
 		}
 	}
 
 	return mgrs
 }
 
-func getStorageBackends(cfg *config.Config) []storage.Storage {
-	var backends []storage.Storage
-
-	// Check which storage backends to use based on flag
-	if destinationFlag == "all" || destinationFlag == "gdrive" {
-		if cfg.Storage.GoogleDrive.Enabled {
-			backends = append(backends, storage.NewGoogleDrive(
-				cfg.Storage.GoogleDrive.CredentialsPath,
-				cfg.Storage.GoogleDrive.FolderID,
-			))
-		}
+// newBitwarden creates a Bitwarden manager with the configured command timeout and session
+func newBitwarden(cfg *config.Config) *managers.Bitwarden {
+	bw := managers.NewBitwarden(cfg.PasswordManagers.Bitwarden.CLIPath, cfg.PasswordManagers.Bitwarden.Email)
+	if timeout, err := config.ParseTimeout(cfg.PasswordManagers.Bitwarden.Timeout); err == nil && timeout >= 0 {
+		bw.SetTimeout(timeout)
 	}
-
-	if destinationFlag == "all" || destinationFlag == "usb" {
-		if cfg.Storage.USB.Enabled {
-			backends = append(backends, storage.NewUSB(
-				cfg.Storage.USB.MountPath,
-				cfg.Storage.USB.BackupDir,
-			))
-		}
+	if session := flagOrEnv(bwSession, "STASHR_BW_SESSION"); session != "" {
+		bw.SetSession(session)
 	}
+	return bw
+}
 
-	if destinationFlag == "all" || destinationFlag == "local" {
-		if cfg.Storage.Local.Enabled {
-			backends = append(backends, storage.NewLocal(
-				cfg.Storage.Local.BackupPath,
-			))
-		}
+// newOnePassword creates a 1Password manager with the configured command timeout and credentials
+func newOnePassword(cfg *config.Config) *managers.OnePassword {
+	op := managers.NewOnePassword(cfg.PasswordManagers.OnePassword.CLIPath, cfg.PasswordManagers.OnePassword.Account)
+	if timeout, err := config.ParseTimeout(cfg.PasswordManagers.OnePassword.Timeout); err == nil && timeout >= 0 {
+		op.SetTimeout(timeout)
+	}
+	if session := flagOrEnv(opSession, "STASHR_OP_SESSION"); session != "" {
+		op.SetSession(session)
 	}
+	if token := flagOrEnv(opToken, "STASHR_OP_TOKEN"); token != "" {
+		op.SetServiceAccountToken(token)
+	}
+	return op
+}
 
-	return backends
+func getStorageBackends(cfg *config.Config) []storage.Storage {
+	// Check which storage backends to use based on flag
+	return selectStorageBackends(cfg, destinationFlag)
 }
 
 // handleInteractiveMode guides the user through backup options
@@ -522,16 +1603,7 @@ func handleInteractiveMode(cfg *config.Config) bool {
 	}
 
 	// Ask which storage to use
-	enabledBackends := []string{}
-	if cfg.Storage.Local.Enabled {
-		enabledBackends = append(enabledBackends, "local")
-	}
-	if cfg.Storage.USB.Enabled {
-		enabledBackends = append(enabledBackends, "usb")
-	}
-	if cfg.Storage.GoogleDrive.Enabled {
-		enabledBackends = append(enabledBackends, "gdrive")
-	}
+	enabledBackends := enabledDestinationFlags(cfg)
 
 	if len(enabledBackends) > 1 {
 		logger.Info("Which storage destinations would you like to use?")
@@ -567,7 +1639,7 @@ func handleInteractiveMode(cfg *config.Config) bool {
 	logger.Info("  Managers: %s", managerFlag)
 	logger.Info("  Storage: %s", destinationFlag)
 	logger.Info("  Encryption: %v", cfg.Backup.Encryption.Enabled && !noEncrypt)
-	logger.Info("  Compression: %v", cfg.Backup.Compression)
+	logger.Info("  Compression: %s", cfg.Backup.CompressionAlgorithm())
 	if managerFlag == "1password" || managerFlag == "all" {
 		logger.Info("  1Password mode: %s", map[bool]string{true: "Full export (with passwords)", false: "Metadata only"}[fullExport])
 	}
@@ -578,8 +1650,9 @@ func handleInteractiveMode(cfg *config.Config) bool {
 
 // handleDryRun shows what would be backed up without executing
 func handleDryRun(managersToBackup []managers.Manager, storageBackends []storage.Storage, cfg *config.Config) {
-	logger.Info("🔍 DRY RUN MODE - Preview Only (no backup will be created)")
-	logger.Separator()
+	printDryRunHeader()
+	var plan dryRunPlan
+	timestamp := time.Now()
 
 	// Check managers
 	logger.Info("Password Managers to Backup:")
@@ -608,10 +1681,13 @@ func handleDryRun(managersToBackup []managers.Manager, storageBackends []storage
 		if itemCount > 0 {
 			logger.Info("  📊 Items: %d", itemCount)
 		}
+		if labels := cfg.ManagerLabels(mgr.Name()); len(labels) > 0 {
+			logger.Info("  🏷️  Classification: %s", formatTags(labels))
+		}
 
 		// Estimate size (rough estimate: 1KB per item)
 		estimatedSize := int64(itemCount * 1024)
-		if cfg.Backup.Compression {
+		if cfg.Backup.Compressed() {
 			estimatedSize = estimatedSize * 3 / 10 // Assume 70% compression
 		}
 		logger.Info("  📦 Estimated size: %s", utils.FormatBytes(estimatedSize))
@@ -646,16 +1722,45 @@ func handleDryRun(managersToBackup []managers.Manager, storageBackends []storage
 		}
 		logger.Success("  ✓ Available")
 
+		mode := effectiveEncryptionMode(cfg, backend)
+		if destinationEncryption(cfg, backend).SeparatePassword {
+			mode += " (separate password)"
+		}
+		logger.Info("  🔐 Encryption: %s", mode)
+
+		if free, err := storage.FreeSpace(backend); err == nil {
+			logger.Info("  💾 Free space: %s", utils.FormatBytes(free))
+		}
+
+		var uploads []string
+		for _, mgr := range managersToBackup {
+			if err := checkPolicies(cfg, mgr.Name(), backend); err != nil {
+				logger.Failure("  ⛔ %s: blocked (%v)", mgr.Name(), err)
+				continue
+			}
+			format := artifactFormat(cfg, effectiveEncryptionMode(cfg, backend), destinationArtifact(cfg, backend).Extension)
+			filename := artifactFilename(cfg, format, mgr.Name(), timestamp)
+			uploads = append(uploads, filename)
+			plan.Add("Upload %s to %s", filename, backend.Name())
+			if verifyUploads(cfg) {
+				plan.Add("Verify the stored copy of %s on %s", filename, backend.Name())
+			}
+		}
+
 		// List existing backups
 		backups, err := backend.List()
 		if err != nil {
 			logger.Warning("  ⚠ Could not list existing backups: %v", err)
 		} else {
 			logger.Info("  📁 Existing backups: %d", len(backups))
+
+			// The new uploads count towards the backups retention keeps
+			candidates := plannedRetentionDeletions(cfg, backend, backups, uploads, time.Now())
 			if len(backups) > 0 {
-				logger.Info("  🗑️  Old backups to delete: %d (keeping last %d)",
-					max(0, len(backups)+len(managersToBackup)-cfg.Backup.Retention.KeepLast),
-					cfg.Backup.Retention.KeepLast)
+				logger.Info("  🗑️  Old backups to delete: %d (keeping %s per manager)", len(candidates), destinationRetentionPolicy(cfg, backend))
+			}
+			for _, backup := range candidates {
+				plan.Add("%s (retention)", retentionRemoval(cfg, backend, backup.Name))
 			}
 		}
 	}
@@ -665,15 +1770,31 @@ func handleDryRun(managersToBackup []managers.Manager, storageBackends []storage
 	// Show encryption info
 	if !noEncrypt && cfg.Backup.Encryption.Enabled {
 		logger.Info("Encryption Settings:")
-		logger.Info("  Algorithm: %s", cfg.Backup.Encryption.Algorithm)
-		logger.Info("  Password prompt: %s", map[bool]string{true: "Once per manager", false: "Once for all"}[promptEachBackup])
+		if recipients := cfg.Backup.Encryption.Recipients; len(recipients) > 0 {
+			logger.Info("  Algorithm: age (ChaCha20-Poly1305)")
+			logger.Info("  Recipients: %d (no password prompt)", len(recipients))
+		} else if recipients := cfg.Backup.Encryption.GPG.Recipients; len(recipients) > 0 {
+			logger.Info("  Algorithm: OpenPGP (gpg)")
+			logger.Info("  Recipients: %s (no password prompt)", strings.Join(recipients, ", "))
+			if err := crypto.CheckGPGRecipients(cfg.Backup.Encryption.GPG.Executable(), recipients); err != nil {
+				logger.Warning("  ⚠ %v", err)
+			}
+		} else if keyFile := resolveKeyFile(cfg, encryptionKey); keyFile != "" {
+			logger.Info("  Algorithm: %s", cfg.Backup.Encryption.Algorithm)
+			logger.Info("  Key file: %s", keyFile)
+			if !utils.FileExists(keyFile) {
+				logger.Warning("  ⚠ %s does not exist (create it with: stashr keyfile generate)", keyFile)
+			}
+		} else {
+			logger.Info("  Algorithm: %s", cfg.Backup.Encryption.Algorithm)
+			logger.Info("  Key derivation: PBKDF2-SHA256, %d iterations", cfg.Backup.Encryption.KeyIterations())
+			logger.Info("  Password prompt: %s", map[bool]string{true: "Once per manager", false: "Once for all"}[promptEachBackup])
+		}
 		logger.Separator()
 	}
 
 	// Show summary
-	logger.Success("✅ Dry run complete!")
-	logger.Info("")
-	logger.Info("To perform the actual backup, run the same command without --dry-run")
+	plan.Print()
 }
 
 // parseChoice converts user input to an integer choice
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/internal/backupname"
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/keychain"
+)
+
+// namesKeyFileName holds the key encrypting obfuscated names when the OS keychain isn't available
+const namesKeyFileName = "names.key"
+
+// newBackupNameParser returns a parser for backup filenames that also knows
+// the configured filename_format, every format recorded in the database and
+// the names behind obfuscated filenames
+func newBackupNameParser(cfg *config.Config) *backupname.Parser {
+	formats, _ := database.ListFilenameFormats()
+	if cfg != nil {
+		formats = append([]string{cfg.Backup.FilenameFormat}, formats...)
+	}
+	parser := backupname.NewParser(formats...)
+	if key, err := obfuscatedNamesKey(false); err == nil && key != nil {
+		if aliases, err := database.ListObfuscatedNames(key); err == nil {
+			parser.SetAliases(aliases)
+		}
+	}
+	return parser
+}
+
+// obfuscatedNamesKey returns the key the names behind obfuscated filenames are
+// encrypted with in the database, creating it when create is set. It is kept in
+// the OS keychain, or in a file next to the database when there's no keychain.
+// Without create, a missing key returns nil.
+func obfuscatedNamesKey(create bool) ([]byte, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(dir, namesKeyFileName)
+	if data, err := os.ReadFile(keyPath); err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read names key: %w", err)
+	}
+
+	useKeychain := keychain.Available() == nil
+	if useKeychain {
+		stored, err := keychain.Get(keychain.NamesAccount)
+		if err == nil {
+			defer stored.Destroy()
+			return hex.DecodeString(string(stored.Bytes()))
+		}
+		if !errors.Is(err, keychain.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if !create {
+		return nil, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate names key: %w", err)
+	}
+	encoded := hex.EncodeToString(key)
+	if useKeychain {
+		if err := keychain.Set(keychain.NamesAccount, encoded); err != nil {
+			return nil, err
+		}
+		// Some keyrings report success even when nothing was stored
+		stored, err := keychain.Get(keychain.NamesAccount)
+		matches := err == nil && string(stored.Bytes()) == encoded
+		stored.Destroy()
+		if !matches {
+			return nil, fmt.Errorf("failed to store names key in keychain: it could not be read back")
+		}
+		return key, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyPath, []byte(encoded), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write names key: %w", err)
+	}
+	return key, nil
+}
+
+// managerDisplayName returns the product name of a manager parsed from a filename
+func managerDisplayName(manager string) string {
+	switch manager {
+	case "bitwarden":
+		return "Bitwarden"
+	case "1password":
+		return "1Password"
+	case "":
+		return "Unknown"
+	default:
+		return manager
+	}
+}
+
+// trimBackupExtension removes the .stashr, .json.enc, .json.gz (or .zst, .xz)
+// or .json extension of a backup filename
+func trimBackupExtension(filename string) string {
+	for _, ext := range []string{backupname.CanonicalExtension, ".enc", ".age", ".gpg", ".gz", ".zst", ".xz", ".json"} {
+		filename = strings.TrimSuffix(filename, ext)
+	}
+	return filename
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/notify"
+)
+
+// backupReport collects the results of a backup run and sends them through the
+// notification channels, as selected by notifications.backups, and to the
+// dead-man switch in notifications.ping
+type backupReport struct {
+	cfg     *config.Config
+	started time.Time
+	// ping is set once the run's start was pinged, and is pinged again at its end
+	ping *notify.Ping
+
+	mu      sync.Mutex
+	results []backupResult
+	// skipped names destinations that failed the pre-flight checks
+	skipped []string
+	// finished is set once the run reached its end, rather than stopping early
+	finished bool
+	// discarded runs (dry runs, cancellations, interrupts) aren't reported
+	discarded bool
+	sent      bool
+}
+
+// backupResult is the outcome of one manager's backup
+type backupResult struct {
+	manager string
+	err     error
+}
+
+// currentBackupReport is the report of the running backup, for exits that skip
+// its deferred send
+var currentBackupReport *backupReport
+
+// newBackupReport starts the report of a backup run
+func newBackupReport(cfg *config.Config) *backupReport {
+	currentBackupReport = &backupReport{cfg: cfg, started: time.Now()}
+	return currentBackupReport
+}
+
+// add records the outcome of a manager's backup
+func (r *backupReport) add(manager string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, backupResult{manager: manager, err: err})
+}
+
+// addPreflight records the managers and destinations that failed the pre-flight checks
+func (r *backupReport) addPreflight(result preflightResult, mgrs []managers.Manager) {
+	for _, name := range result.failed {
+		isManager := slices.ContainsFunc(mgrs, func(mgr managers.Manager) bool { return mgr.Name() == name })
+		if isManager {
+			r.add(name, errors.New("failed the pre-flight checks"))
+			continue
+		}
+		r.mu.Lock()
+		r.skipped = append(r.skipped, name)
+		r.mu.Unlock()
+	}
+}
+
+// startPing pings the dead-man switch that the run started. Only unattended runs
+// ping it, so manual backups can't hide a schedule that stopped running.
+func (r *backupReport) startPing() {
+	settings := r.cfg.Notifications.Ping
+	if !settings.Enabled {
+		return
+	}
+	ping := notify.NewPing(settings.Service, settings.PingURL())
+	if err := ping.Send(notify.PingStart, "stashr backup started"); err != nil {
+		logger.Warning("⚠ %v", err)
+	}
+	r.mu.Lock()
+	r.ping = ping
+	r.mu.Unlock()
+}
+
+// complete marks the run as having reached its end
+func (r *backupReport) complete() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = true
+}
+
+// discard drops the report of a run there is nothing to notify about
+func (r *backupReport) discard() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.discarded = true
+}
+
+// send notifies the run's outcome, once. A run that stopped early is a failure,
+// reported with the last error it printed.
+func (r *backupReport) send() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sent {
+		return
+	}
+	r.sent = true
+
+	failed := r.failedManagers()
+	// An interrupted run was still pinged as started, so the ping reports it failed
+	r.finishPing(failed)
+	if r.discarded {
+		return
+	}
+
+	mode := r.cfg.Notifications.Backups
+	if mode == config.BackupNotificationsNone || !r.cfg.Notifications.ChannelEnabled() {
+		return
+	}
+	host, _ := os.Hostname()
+
+	message := notify.Message{Severity: notify.SeverityInfo, Subject: "stashr: backup completed"}
+	switch {
+	case !r.finished:
+		message.Severity = notify.SeverityError
+		message.Subject = "stashr: backup failed"
+	case len(failed) > 0:
+		message.Severity = notify.SeverityError
+		message.Subject = fmt.Sprintf("stashr: backup failed for %s", strings.Join(failed, ", "))
+	case len(r.skipped) > 0:
+		message.Severity = notify.SeverityWarning
+		message.Subject = "stashr: backup completed with warnings"
+	}
+	if host != "" {
+		message.Subject += " on " + host
+	}
+	if message.Severity == notify.SeverityInfo && mode != config.BackupNotificationsAll {
+		return
+	}
+	channels := notifiers(r.cfg, message.Severity)
+	if len(channels) == 0 {
+		return
+	}
+	message.Body = r.summary()
+
+	if err := deliverNotification(r.cfg, channels, message); err != nil {
+		logger.Warning("⚠ Backup notification not sent: %v", err)
+		return
+	}
+	logger.Success("✓ Sent backup notification")
+}
+
+// finishPing pings the dead-man switch with the run's outcome, if its start was pinged
+func (r *backupReport) finishPing(failed []string) {
+	if r.ping == nil {
+		return
+	}
+	event := notify.PingSuccess
+	if !r.finished || len(failed) > 0 {
+		event = notify.PingFailure
+	}
+	// The summary is stored by the service, so mask it like log files
+	redactor, err := newRedactor(r.cfg.Logging.Redaction)
+	if err != nil {
+		logger.Warning("⚠ %s not pinged: %v", r.ping.Name(), err)
+		return
+	}
+	if err := r.ping.Send(event, redactor.Redact(r.summary())); err != nil {
+		logger.Warning("⚠ %v", err)
+		return
+	}
+	logger.Success("✓ Pinged %s", r.ping.Name())
+}
+
+// failedManagers returns the display names of the managers whose backup failed
+func (r *backupReport) failedManagers() []string {
+	var failed []string
+	for _, result := range r.results {
+		if result.err != nil {
+			failed = append(failed, managerDisplayName(result.manager))
+		}
+	}
+	return failed
+}
+
+// summary describes the run: when it started, each manager's result and the
+// destinations it skipped
+func (r *backupReport) summary() string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Started: %s (%s)\n", r.started.Format("2006-01-02 15:04"), time.Since(r.started).Round(time.Second))
+	if len(r.results) > 0 {
+		body.WriteString("\n")
+	}
+	for _, result := range r.results {
+		if result.err != nil {
+			fmt.Fprintf(&body, "✗ %s: %v\n", managerDisplayName(result.manager), result.err)
+		} else {
+			fmt.Fprintf(&body, "✓ %s\n", managerDisplayName(result.manager))
+		}
+	}
+	for _, destination := range r.skipped {
+		fmt.Fprintf(&body, "⚠ Skipped %s: failed the pre-flight checks\n", destination)
+	}
+	if !r.finished {
+		reason := logger.LastFailure()
+		if reason == "" {
+			reason = "see the output of stashr backup"
+		}
+		fmt.Fprintf(&body, "\nThe backup stopped before it finished: %s\n", reason)
+	}
+	return strings.TrimSuffix(body.String(), "\n")
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the download cache",
+	Long: `Manage the local cache of downloaded backups.
+
+Encrypted backups downloaded from remote destinations (Google Drive, OneDrive,
+WebDAV, Google Cloud Storage, Azure Blob, S3, rclone, plugins) are kept in a size-bounded
+cache, so restoring, converting or verifying the same backup again doesn't
+download it again. The least recently used backups are evicted first. Use
+--no-cache on any command to bypass it.
+
+Subcommands:
+  status - Show cached backups and cache usage
+  clear  - Delete every cached backup`,
+}
+
+// cacheStatusCmd represents the cache status command
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show cached backups and cache usage",
+	Run:   runCacheStatus,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete every cached backup",
+	Run:   runCacheClear,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cacheClearCmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the cached backups that would be deleted without deleting them")
+}
+
+func runCacheStatus(cmd *cobra.Command, args []string) {
+	logger.Header("🗄️  Download Cache")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cache := backupCache(cfg)
+	entries, err := cache.Entries()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	if cfg.Cache.Enabled {
+		logger.Info("Status: enabled")
+	} else {
+		logger.Info("Status: disabled (cache.enabled: false)")
+	}
+	logger.Info("Directory: %s", cache.Dir)
+	logger.Info("Usage: %s of %s (%d backups)", utils.FormatBytes(total), utils.FormatBytes(cache.MaxBytes), len(entries))
+
+	if len(entries) == 0 {
+		return
+	}
+
+	logger.Separator()
+	fmt.Printf("%-15s %-50s %-12s %s\n", i18n.T("STORAGE"), i18n.T("FILENAME"), i18n.T("SIZE"), i18n.T("LAST USED"))
+	for _, entry := range entries {
+		fmt.Printf("%-15s %-50s %-12s %s\n",
+			entry.Storage,
+			entry.Name,
+			utils.FormatBytes(entry.Size),
+			entry.LastUsed.Format("2006-01-02 15:04"),
+		)
+	}
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) {
+	logger.Header("🗄️  Clear Download Cache")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cache := backupCache(cfg)
+	size, err := cache.Size()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		entries, err := cache.Entries()
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		printDryRunHeader()
+		var plan dryRunPlan
+		for _, entry := range entries {
+			plan.Add("Delete cached %s from %s (%s)", entry.Name, entry.Storage, utils.FormatBytes(entry.Size))
+		}
+		plan.Print()
+		return
+	}
+
+	if err := cache.Clear(); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Cleared %s from %s", utils.FormatBytes(size), cache.Dir)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// Checklist item identifiers, stored in the database as "checklist.<id>"
+const (
+	checklistEncryption   = "encryption"
+	checklistPassword     = "password_stored"
+	checklistDestinations = "destinations"
+	checklistEmergencyKit = "emergency_kit"
+	checklistTestRestore  = "test_restore"
+)
+
+// checklistItem is one critical safety item
+type checklistItem struct {
+	id    string
+	title string
+	hint  string
+	// check evaluates the item from the configuration; items without a check
+	// are completed by the user confirming them or by running another command
+	check func(cfg *config.Config) bool
+	// confirm is asked interactively to complete an item without a check
+	confirm string
+}
+
+// checklistItems returns the safety checklist in the order it is walked through
+func checklistItems() []checklistItem {
+	return []checklistItem{
+		{
+			id:    checklistEncryption,
+			title: "Encryption is enabled for every destination",
+			hint:  "Set backup.encryption.enabled: true",
+			check: func(cfg *config.Config) bool {
+				// Destinations can't override encryption to none
+				return cfg.Backup.Encryption.Enabled
+			},
+		},
+		{
+			id:      checklistPassword,
+			title:   "Encryption password is stored somewhere safe",
+			hint:    "Keep it outside this computer (e.g. written down in a safe, or in a separate password manager)",
+			confirm: "Is your encryption password stored somewhere safe, outside this computer?",
+		},
+		{
+			id:    checklistDestinations,
+			title: "Backups go to at least 2 destinations",
+			hint:  "Enable a second destination with 'stashr init' or in ~/.stashr/config.yaml",
+			check: func(cfg *config.Config) bool {
+				return len(enabledDestinationFlags(cfg)) >= 2
+			},
+		},
+		{
+			id:    checklistEmergencyKit,
+			title: "Emergency kit generated",
+			hint:  "Run 'stashr emergency-kit' and keep the PDF with your password",
+		},
+		{
+			id:    checklistTestRestore,
+			title: "Test restore performed",
+			hint:  "Run 'stashr rehearse' or 'stashr restore --latest' to prove a backup can be restored",
+		},
+	}
+}
+
+// checklistCmd represents the checklist command
+var checklistCmd = &cobra.Command{
+	Use:   "checklist",
+	Short: "Verify the critical backup safety items",
+	Long: `Walk through the items that decide whether your backups can actually be restored:
+
+1. Encryption is enabled
+2. The encryption password is stored somewhere safe
+3. Backups go to at least 2 destinations
+4. An emergency kit has been generated
+5. A test restore has been performed
+
+Completed items are recorded, and other commands remind you until every
+item is done.`,
+	Run: runChecklist,
+}
+
+func init() {
+	rootCmd.AddCommand(checklistCmd)
+}
+
+func runChecklist(cmd *cobra.Command, args []string) {
+	logger.Header("✅ Safety Checklist")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	items := checklistItems()
+	done := 0
+	for i, item := range items {
+		completed, completedAt := checklistStatus(cfg, item)
+
+		// Ask the user to attest items that cannot be checked automatically
+		if !completed && item.confirm != "" {
+			if utils.ConfirmPrompt(item.confirm) {
+				markChecklistItem(item.id)
+				completed, completedAt = true, time.Now()
+			}
+		}
+
+		prefix := fmt.Sprintf("%d/%d %s", i+1, len(items), i18n.T(item.title))
+		if completed {
+			done++
+			if completedAt.IsZero() {
+				logger.Success("✓ %s", prefix)
+			} else {
+				logger.Success("✓ %s (%s)", prefix, completedAt.Format("2006-01-02"))
+			}
+			continue
+		}
+		logger.Failure("✗ %s", prefix)
+		logger.Info("    → %s", i18n.T(item.hint))
+	}
+
+	logger.Separator()
+	if done == len(items) {
+		logger.Success("🎉 All %d safety items are complete", len(items))
+		return
+	}
+	logger.Warning("⚠ %d of %d safety items are incomplete. Run 'stashr checklist' again once fixed", len(items)-done, len(items))
+}
+
+// checklistStatus reports whether an item is complete and when it was recorded.
+// Configuration checks are evaluated live so a later config change is noticed.
+func checklistStatus(cfg *config.Config, item checklistItem) (bool, time.Time) {
+	if item.check != nil {
+		if !item.check(cfg) {
+			return false, time.Time{}
+		}
+		markChecklistItem(item.id)
+		return true, time.Time{}
+	}
+
+	value, err := database.GetState("checklist." + item.id)
+	if err != nil || value == "" {
+		return false, time.Time{}
+	}
+	completedAt, _ := time.Parse(time.RFC3339, value)
+	return true, completedAt
+}
+
+// markChecklistItem records a checklist item as complete, keeping the first completion time
+func markChecklistItem(id string) {
+	key := "checklist." + id
+	if value, err := database.GetState(key); err == nil && value != "" {
+		return
+	}
+	if err := database.SetState(key, time.Now().Format(time.RFC3339)); err != nil {
+		logger.Debug("Failed to record checklist item %s: %v", id, err)
+	}
+}
+
+// nudgeChecklist reminds the user of incomplete safety items without prompting
+func nudgeChecklist(cfg *config.Config) {
+	remaining := 0
+	for _, item := range checklistItems() {
+		if completed, _ := checklistStatus(cfg, item); !completed {
+			remaining++
+		}
+	}
+	if remaining > 0 {
+		logger.Info("💡 %d safety checklist item(s) incomplete. Run 'stashr checklist'", remaining)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/harshalranjhani/stashr/internal/conditions"
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// bytesPerMB converts backup.conditions.min_free_disk_mb to bytes
+const bytesPerMB = 1024 * 1024
+
+// unmetCondition returns why backup.conditions postpone an unattended backup,
+// or "" if they are met. A condition that can't be checked on this machine is
+// treated as met, so backups don't stop for good.
+func unmetCondition(cfg *config.Config) string {
+	guards := cfg.Backup.Conditions
+
+	if guards.OnlyOnACPower {
+		onAC, err := conditions.OnACPower()
+		switch {
+		case err != nil:
+			logger.Warning("⚠ Can't tell whether the machine runs on AC power (%v): not postponing", err)
+		case !onAC:
+			return "running on battery power"
+		}
+	}
+
+	if guards.OnlyOnUnmeteredNetwork {
+		metered, err := conditions.OnMeteredNetwork()
+		switch {
+		case err != nil:
+			logger.Warning("⚠ Can't tell whether the network connection is metered (%v): not postponing", err)
+		case metered:
+			return "the network connection is metered"
+		}
+	}
+
+	if guards.MinFreeDiskMB > 0 {
+		// Exports are written to the temporary directory before they are encrypted
+		dir := backupTempDir(cfg)
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		free, err := storage.PathFreeSpace(dir)
+		switch {
+		case err != nil:
+			logger.Warning("⚠ Can't read the free space of %s (%v): not postponing", dir, err)
+		case free < int64(guards.MinFreeDiskMB)*bytesPerMB:
+			return fmt.Sprintf("only %s free in %s, below the %d MB of backup.conditions.min_free_disk_mb", utils.FormatBytes(free), dir, guards.MinFreeDiskMB)
+		}
+	}
+
+	return ""
+}
+
+// recordPostponement records a backup postponed by backup.conditions in the
+// event log, for status and the digest
+func recordPostponement(manager, reason string) {
+	if manager == "all" {
+		manager = ""
+	}
+	recordEvent(database.EventRecord{Kind: database.EventPostponed, Manager: manager}, errors.New(reason))
+}
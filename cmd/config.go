@@ -8,7 +8,6 @@ import (
 
 	"github.com/harshalranjhani/stashr/internal/config"
 	"github.com/harshalranjhani/stashr/internal/logger"
-	"github.com/harshalranjhani/stashr/internal/managers"
 	"github.com/harshalranjhani/stashr/internal/storage"
 )
 
@@ -20,7 +19,8 @@ var configCmd = &cobra.Command{
 
 Subcommands:
   show     - Display current configuration
-  validate - Validate configuration and test connections`,
+  validate - Validate configuration and test connections
+  diff     - Show how the configuration differs from the defaults or another file`,
 }
 
 var configShowCmd = &cobra.Command{
@@ -38,10 +38,31 @@ and storage backends.`,
 	Run: runConfigValidate,
 }
 
+var configDiffCmd = &cobra.Command{
+	Use:   "diff [file] [file]",
+	Short: "Show configuration differences",
+	Long: `Show the settings that differ between the current configuration and the
+built-in defaults, or between configuration files. Changes that weaken the
+backups, such as disabling encryption or keeping a single backup, are flagged.
+
+Secrets are redacted, so the output can be shared when asking for support.`,
+	Example: `  # Compare the current configuration with the defaults
+  stashr config diff
+
+  # Compare the current configuration with another file
+  stashr config diff ~/work-config.yaml
+
+  # Compare two files
+  stashr config diff old.yaml new.yaml`,
+	Args: cobra.MaximumNArgs(2),
+	Run:  runConfigDiff,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configDiffCmd)
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) {
@@ -59,8 +80,11 @@ func runConfigShow(cmd *cobra.Command, args []string) {
 	logger.Info("Configuration file: %s", configPath)
 	logger.Separator()
 
+	// Redact secrets before display
+	display := cfg.Redacted()
+
 	// Marshal to YAML for display
-	data, err := yaml.Marshal(cfg)
+	data, err := yaml.Marshal(&display)
 	if err != nil {
 		logger.PrintError(err)
 		return
@@ -87,6 +111,10 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 		logger.Failure("Configuration validation failed: %v", err)
 		return
 	}
+	if err := validatePolicies(cfg); err != nil {
+		logger.Failure("Configuration validation failed: %v", err)
+		return
+	}
 	logger.Success("✓ Configuration is valid")
 
 	// Test password managers
@@ -99,7 +127,7 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 
 	if cfg.PasswordManagers.Bitwarden.Enabled {
 		managersTotal++
-		bw := managers.NewBitwarden(cfg.PasswordManagers.Bitwarden.CLIPath, cfg.PasswordManagers.Bitwarden.Email)
+		bw := newBitwarden(cfg)
 
 		if !bw.IsInstalled() {
 			logger.Failure("✗ Bitwarden: CLI not found at %s", cfg.PasswordManagers.Bitwarden.CLIPath)
@@ -120,7 +148,7 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 
 	if cfg.PasswordManagers.OnePassword.Enabled {
 		managersTotal++
-		op := managers.NewOnePassword(cfg.PasswordManagers.OnePassword.CLIPath, cfg.PasswordManagers.OnePassword.Account)
+		op := newOnePassword(cfg)
 
 		if !op.IsInstalled() {
 			logger.Failure("✗ 1Password: CLI not found at %s", cfg.PasswordManagers.OnePassword.CLIPath)
@@ -149,7 +177,7 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 
 	if cfg.Storage.GoogleDrive.Enabled {
 		storageTotal++
-		gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, cfg.Storage.GoogleDrive.FolderID)
+		gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, cfg.Storage.GoogleDrive.FolderID, cfg.Storage.GoogleDrive.DriveID)
 
 		available, err := gdrive.IsAvailable()
 		if err != nil {
@@ -164,7 +192,7 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 
 	if cfg.Storage.USB.Enabled {
 		storageTotal++
-		usb := storage.NewUSB(cfg.Storage.USB.MountPath, cfg.Storage.USB.BackupDir)
+		usb := newUSB(cfg.Storage.USB)
 
 		available, err := usb.IsAvailable()
 		if err != nil {
@@ -174,7 +202,7 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 			logger.Warning("⚠ USB: Not available")
 			logger.Info("  (USB drives may not always be connected)")
 		} else {
-			logger.Success("✓ USB: Available at %s", cfg.Storage.USB.MountPath)
+			logger.Success("✓ USB: Available at %s", usb.MountPath)
 			storageOK++
 		}
 	}
@@ -194,6 +222,163 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if cfg.Storage.GitAnnex.Enabled {
+		storageTotal++
+		annex := storage.NewGitAnnex(cfg.Storage.GitAnnex.RepoPath, cfg.Storage.GitAnnex.BackupDir, cfg.Storage.GitAnnex.Remotes)
+
+		available, err := annex.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ git-annex: %v", err)
+		} else if !available {
+			logger.Failure("✗ git-annex: Not available")
+		} else {
+			logger.Success("✓ git-annex: Available at %s", cfg.Storage.GitAnnex.RepoPath)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.OneDrive.Enabled {
+		storageTotal++
+		onedrive := storage.NewOneDrive(cfg.Storage.OneDrive.ClientID, cfg.Storage.OneDrive.Tenant, cfg.Storage.OneDrive.Folder, cfg.Storage.OneDrive.TokenPath)
+
+		available, err := onedrive.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ OneDrive: %v", err)
+		} else if !available {
+			logger.Failure("✗ OneDrive: Not available")
+		} else {
+			logger.Success("✓ OneDrive: Available")
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.WebDAV.Enabled {
+		storageTotal++
+		webdav := newWebDAV(cfg.Storage.WebDAV)
+
+		available, err := webdav.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ WebDAV: %v", err)
+		} else if !available {
+			logger.Failure("✗ WebDAV: Not available")
+		} else {
+			logger.Success("✓ WebDAV: Available at %s", cfg.Storage.WebDAV.URL)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.GCS.Enabled {
+		storageTotal++
+		gcs := storage.NewGCS(cfg.Storage.GCS.Bucket, cfg.Storage.GCS.Prefix, cfg.Storage.GCS.CredentialsPath)
+
+		available, err := gcs.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ Google Cloud Storage: %v", err)
+		} else if !available {
+			logger.Failure("✗ Google Cloud Storage: Not available")
+		} else {
+			logger.Success("✓ Google Cloud Storage: Available at gs://%s", cfg.Storage.GCS.Bucket)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.AzureBlob.Enabled {
+		storageTotal++
+		azure := newAzureBlob(cfg.Storage.AzureBlob)
+
+		available, err := azure.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ Azure Blob: %v", err)
+		} else if !available {
+			logger.Failure("✗ Azure Blob: Not available")
+		} else {
+			logger.Success("✓ Azure Blob: Available in container %s", cfg.Storage.AzureBlob.Container)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.S3.Enabled {
+		storageTotal++
+		s3 := newS3(cfg.Storage.S3)
+
+		available, err := s3.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ S3: %v", err)
+		} else if !available {
+			logger.Failure("✗ S3: Not available")
+		} else {
+			logger.Success("✓ S3: Available at s3://%s", cfg.Storage.S3.Bucket)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.Rclone.Enabled {
+		storageTotal++
+		rclone := storage.NewRclone(cfg.Storage.Rclone.Remote, cfg.Storage.Rclone.CLIPath, cfg.Storage.Rclone.ConfigPath)
+
+		available, err := rclone.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ rclone: %v", err)
+		} else if !available {
+			logger.Failure("✗ rclone: Not available")
+		} else {
+			logger.Success("✓ rclone: Available at %s", cfg.Storage.Rclone.Remote)
+			storageOK++
+		}
+	}
+
+	if cfg.Storage.ICloud.Enabled {
+		storageTotal++
+		timeout, _ := cfg.Storage.ICloud.Timeout()
+		icloud := storage.NewICloudDrive(cfg.Storage.ICloud.DrivePath, cfg.Storage.ICloud.BackupDir, timeout)
+
+		available, err := icloud.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ iCloud Drive: %v", err)
+		} else if !available {
+			logger.Failure("✗ iCloud Drive: Not available")
+		} else {
+			logger.Success("✓ iCloud Drive: Available at %s", icloud.DrivePath)
+			storageOK++
+		}
+	}
+
+	for _, profile := range cfg.Storage.Destinations {
+		dest, ok := profileDestination(profile)
+		if !ok || !dest.enabled {
+			continue
+		}
+		storageTotal++
+
+		available, err := dest.create().IsAvailable()
+		if err != nil {
+			logger.Failure("✗ %s (%s): %v", profile.Name, profile.Kind(), err)
+		} else if !available {
+			logger.Failure("✗ %s (%s): Not available", profile.Name, profile.Kind())
+		} else {
+			logger.Success("✓ %s (%s): Available", profile.Name, profile.Kind())
+			storageOK++
+		}
+	}
+
+	for _, plugin := range cfg.Storage.Plugins {
+		if !plugin.Enabled {
+			continue
+		}
+		storageTotal++
+		backend := storage.NewPlugin(plugin.Name, plugin.Path, plugin.Options)
+
+		available, err := backend.IsAvailable()
+		if err != nil {
+			logger.Failure("✗ Plugin %s: %v", plugin.Name, err)
+		} else if !available {
+			logger.Failure("✗ Plugin %s: Not available", plugin.Name)
+		} else {
+			logger.Success("✓ Plugin %s: Available (%s)", plugin.Name, backend.Path)
+			storageOK++
+		}
+	}
+
 	// Summary
 	logger.Separator()
 	logger.Info("Summary:")
@@ -214,3 +399,62 @@ func runConfigValidate(cmd *cobra.Command, args []string) {
 		logger.Info("Review the messages above for details")
 	}
 }
+
+func runConfigDiff(cmd *cobra.Command, args []string) {
+	logger.Header("⚙️  Configuration Differences")
+
+	var from, to *config.Config
+	var fromName, toName string
+	var err error
+	switch len(args) {
+	case 0:
+		fromName, toName = "defaults", "current configuration"
+		if from, err = config.GetDefaultExpanded(); err == nil {
+			to, err = config.Load()
+		}
+	case 1:
+		fromName, toName = "current configuration", args[0]
+		if from, err = config.Load(); err == nil {
+			to, err = config.LoadFile(args[0])
+		}
+	default:
+		fromName, toName = args[0], args[1]
+		if from, err = config.LoadFile(args[0]); err == nil {
+			to, err = config.LoadFile(args[1])
+		}
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	changes, err := config.Diff(from, to)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Info("Comparing %s with %s", fromName, toName)
+	logger.Separator()
+
+	if len(changes) == 0 {
+		logger.Success("✓ No differences")
+		return
+	}
+
+	risky := 0
+	for _, change := range changes {
+		if change.Risk != "" {
+			risky++
+			logger.Warning("⚠ %s: %s → %s (%s)", change.Key, change.From, change.To, change.Risk)
+			continue
+		}
+		logger.Info("  %s: %s → %s", change.Key, change.From, change.To)
+	}
+
+	logger.Separator()
+	logger.Info("%d setting(s) differ", len(changes))
+	if risky > 0 {
+		logger.Warning("⚠ %d risky change(s) in %s", risky, toName)
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	convertInput  string
+	convertTo     string
+	convertOutput string
+	// inputKeyFile decrypts inputs encrypted with a key file, for every
+	// command that reads backups with readBackupInput
+	inputKeyFile string
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert a backup into another manager's import format",
+	Long: `Convert a backup from one password manager's export format into another's
+import format, e.g. move a 1Password backup into Bitwarden.
+
+The input can be a path to a backup file or the name of a backup in any
+configured storage location. Encrypted and compressed backups are decrypted
+and decompressed first.
+
+Supported formats:
+  bitwarden-json  Bitwarden (json) import
+  bitwarden-csv   Bitwarden (csv) import
+  1password-csv   1Password CSV import
+  chrome-csv      Chrome/Edge password import (logins with a website only)
+  csv             Normalized CSV (title, category, folder, username, ...)
+  kdbx            KeePass database (KeePassXC, KeePass, KeeWeb, ...)`,
+	Example: `  stashr convert --input 1password_backup_2025-01-15_10-30-00.json.gz.enc --to bitwarden-json
+  stashr convert --input ./export.json --to csv --output vault.csv`,
+	Run: runConvert,
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVarP(&convertInput, "input", "i", "", "Backup file path or backup name to convert")
+	convertCmd.Flags().StringVarP(&convertTo, "to", "t", "", "Target format ("+strings.Join(convert.Formats, ", ")+", kdbx)")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "Output path (default: current directory)")
+	convertCmd.Flags().StringVarP(&inputKeyFile, "encryption-key", "k", "", "Key file the backup was encrypted with (default: backup.encryption.key_file)")
+	convertCmd.MarkFlagRequired("input")
+	convertCmd.MarkFlagRequired("to")
+}
+
+func runConvert(cmd *cobra.Command, args []string) {
+	logger.Header("🔁 Convert Backup")
+
+	// Validate the target format before touching any backup
+	if convertTo != "kdbx" && !isConvertFormat(convertTo) {
+		logger.Failure("Unknown format: %s (use: %s, kdbx)", convertTo, strings.Join(convert.Formats, ", "))
+		return
+	}
+
+	data, password, err := readBackupInput(convertInput)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer password.Destroy()
+	defer secret.Wipe(data)
+
+	var output []byte
+	if convertTo == "kdbx" {
+		output, err = convertToKDBX(data, password)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+	} else {
+		output, err = convertExport(data, convertTo)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+	}
+
+	outputPath := convertOutput
+	if outputPath == "" {
+		baseName := trimBackupExtension(filepath.Base(convertInput))
+		// Keep the source file intact when converting in place
+		outputPath = filepath.Join(".", baseName+"_"+convertTo+convert.Extension(convertTo))
+	}
+
+	if err := os.WriteFile(outputPath, output, 0600); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Output written to: %s", outputPath)
+
+	logger.Separator()
+	if convertTo != "kdbx" {
+		logger.Warning("⚠️  SECURITY WARNING: The converted file contains your passwords in plain text!")
+		logger.Info("Delete it after importing:")
+		logger.Info("  rm \"%s\"", outputPath)
+	}
+}
+
+// convertExport converts a decrypted export into one of the convert.Formats
+func convertExport(data []byte, format string) ([]byte, error) {
+	logger.Progress("Converting to %s...", format)
+	entries, err := convert.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vault data: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := convert.Write(&buf, entries, format); err != nil {
+		return nil, err
+	}
+
+	skipped := convert.Skipped(entries, format)
+	logger.Success("✓ Converted %d items", len(entries)-skipped)
+	if skipped > 0 {
+		logger.Warning("⚠ %d items skipped (%s only supports logins with a website and password)", skipped, format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// isConvertFormat reports whether format is a supported convert target
+func isConvertFormat(format string) bool {
+	for _, f := range convert.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// readBackupInput reads a backup from a path, falling back to a backup of that
+// name in the configured storage locations, and decrypts and decompresses it.
+// It returns the password it was decrypted with, if any, for the caller to destroy.
+func readBackupInput(input string) ([]byte, *secret.Buffer, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logger.Progress("Searching for backup file: %s", input)
+		var sourceName string
+		data, sourceName, err = findBackupInAllSources(cfg, filepath.Base(input))
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Success("✓ Found backup in %s", sourceName)
+	}
+
+	// Without a configuration there is no FIPS policy to apply
+	if cfg, err := config.Load(); err == nil {
+		if err := checkFIPSFile(cfg, input, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var password *secret.Buffer
+	if crypto.UsesKeyFile(data) {
+		cfg := &config.Config{}
+		if inputKeyFile == "" {
+			if cfg, err = config.Load(); err != nil {
+				return nil, nil, err
+			}
+		}
+		data, err = decryptKeyFileBackup(cfg, inputKeyFile, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+	} else if crypto.IsEncrypted(data) {
+		password, err = utils.PromptForSecret("Enter encryption password: ")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logger.Progress("Decrypting backup...")
+		data, err = crypto.DecryptWithPassword(data, password)
+		if err != nil {
+			password.Destroy()
+			logger.Info("Make sure you're using the correct encryption password")
+			return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+	} else if publicKeyEncrypted(data) {
+		data, err = decryptPublicKeyBackup(nil, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+	}
+
+	if isCompressedBackup(data) {
+		decompressed, err := decompressBackup(nil, data)
+		secret.Wipe(data)
+		if err != nil {
+			password.Destroy()
+			return nil, nil, err
+		}
+		data = decompressed
+	}
+
+	return data, password, nil
+}
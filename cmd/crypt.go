@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// cryptExtension is the extension crypt encrypt adds to a file
+const cryptExtension = ".enc"
+
+var (
+	cryptOutput  string
+	cryptKeyFile string
+	cryptForce   bool
+)
+
+// cryptCmd represents the crypt command
+var cryptCmd = &cobra.Command{
+	Use:   "crypt",
+	Short: "Encrypt and decrypt any file like a backup",
+	Long: `Encrypt and decrypt any file with the format and password backups use, to
+protect other sensitive files such as recovery codes or 2FA exports.
+
+Files are encrypted with AES-256-GCM under the encryption password, from the
+OS keychain when backup.encryption.keychain is set, or with the key file of
+--encryption-key or backup.encryption.key_file. Decrypting also accepts
+backups, which stay compressed; restore them with "stashr restore" instead.
+
+Subcommands:
+  encrypt - Encrypt a file
+  decrypt - Decrypt a file`,
+}
+
+// cryptEncryptCmd represents the crypt encrypt command
+var cryptEncryptCmd = &cobra.Command{
+	Use:   "encrypt <file>",
+	Short: "Encrypt a file",
+	Example: `  # Encrypt recovery codes to recovery-codes.txt.enc
+  stashr crypt encrypt recovery-codes.txt
+
+  # Encrypt with a key file
+  stashr crypt encrypt authenticator-export.json --encryption-key ~/.stashr/backup.key`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCryptEncrypt,
+}
+
+// cryptDecryptCmd represents the crypt decrypt command
+var cryptDecryptCmd = &cobra.Command{
+	Use:   "decrypt <file>",
+	Short: "Decrypt a file",
+	Example: `  # Decrypt recovery-codes.txt.enc to recovery-codes.txt
+  stashr crypt decrypt recovery-codes.txt.enc
+
+  # Decrypt to another path
+  stashr crypt decrypt codes.enc --output /tmp/codes.txt`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCryptDecrypt,
+}
+
+func init() {
+	rootCmd.AddCommand(cryptCmd)
+	cryptCmd.AddCommand(cryptEncryptCmd)
+	cryptCmd.AddCommand(cryptDecryptCmd)
+
+	for _, cmd := range []*cobra.Command{cryptEncryptCmd, cryptDecryptCmd} {
+		cmd.Flags().StringVarP(&cryptOutput, "output", "o", "", "Output file path")
+		cmd.Flags().StringVarP(&cryptKeyFile, "encryption-key", "k", "", "Key file to use instead of a password (default: backup.encryption.key_file)")
+		cmd.Flags().BoolVar(&cryptForce, "force", false, "Overwrite the output file if it exists")
+	}
+}
+
+func runCryptEncrypt(cmd *cobra.Command, args []string) {
+	logger.Header("🔒 Encrypt File")
+
+	outputPath := cryptOutput
+	if outputPath == "" {
+		outputPath = args[0] + cryptExtension
+	}
+	if err := checkCryptOutput(outputPath); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer secret.Wipe(data)
+	if crypto.IsEncrypted(data) || publicKeyEncrypted(data) {
+		logger.Warning("⚠ %s is already encrypted; encrypting it again", args[0])
+	}
+
+	if err := checkFIPSMode(cfg, config.EncryptionModePassword); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	var encrypted []byte
+	if keyFile := resolveKeyFile(cfg, cryptKeyFile); keyFile != "" {
+		key, err := loadKeyFile(keyFile)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		defer secret.Wipe(key)
+		logger.Progress("Encrypting with key file %s (key %s)...", keyFile, crypto.KeyFileID(key))
+		encrypted, err = crypto.EncryptWithKey(data, key, nil)
+		if err != nil {
+			logger.Failure("Encryption failed: %v", err)
+			return
+		}
+	} else {
+		password, err := cryptNewPassword(cfg)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		defer password.Destroy()
+		logger.Progress("Encrypting...")
+		encrypted, err = crypto.EncryptWithPassword(data, password, cfg.Backup.Encryption.KeyIterations(), nil)
+		if err != nil {
+			logger.Failure("Encryption failed: %v", err)
+			return
+		}
+	}
+
+	if err := writeRestoredFile(outputPath, encrypted, outputPermissions{mode: 0600, uid: -1, gid: -1}); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Encrypted to %s (%s)", outputPath, utils.FormatBytes(int64(len(encrypted))))
+	logger.Info("💡 The original is unchanged; delete it once you've checked the encrypted copy decrypts")
+}
+
+func runCryptDecrypt(cmd *cobra.Command, args []string) {
+	logger.Header("🔓 Decrypt File")
+
+	outputPath := cryptOutput
+	if outputPath == "" {
+		if !strings.HasSuffix(args[0], cryptExtension) {
+			logger.Failure("%s has no %s extension; choose where to write it with --output", args[0], cryptExtension)
+			return
+		}
+		outputPath = strings.TrimSuffix(args[0], cryptExtension)
+	}
+	if err := checkCryptOutput(outputPath); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if err := checkFIPSFile(cfg, args[0], data); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	var decrypted []byte
+	switch {
+	case crypto.UsesKeyFile(data):
+		decrypted, err = decryptKeyFileBackup(cfg, cryptKeyFile, data)
+	case crypto.IsEncrypted(data):
+		decrypted, err = cryptDecryptWithPassword(cfg, data)
+	case publicKeyEncrypted(data):
+		decrypted, err = decryptPublicKeyBackup(cfg, data)
+	default:
+		logger.Failure("%s is not encrypted", args[0])
+		return
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer secret.Wipe(decrypted)
+
+	if err := writeRestoredFile(outputPath, decrypted, outputPermissions{mode: 0600, uid: -1, gid: -1}); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Decrypted to %s", outputPath)
+}
+
+// checkCryptOutput refuses to replace an existing file without --force
+func checkCryptOutput(path string) error {
+	if utils.FileExists(path) && !cryptForce {
+		return fmt.Errorf("%s already exists; pass --force to overwrite it", path)
+	}
+	return nil
+}
+
+// cryptNewPassword returns the password to encrypt a file with: the keychain
+// password if backup.encryption.keychain is set, else a confirmed prompt
+func cryptNewPassword(cfg *config.Config) (*secret.Buffer, error) {
+	if cfg.Backup.Encryption.Keychain {
+		password, err := keychainPassword(cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.Success("✓ Using the encryption password from the OS keychain")
+		return password, nil
+	}
+
+	logger.Warning("⚠️  If you forget this password, the file can't be decrypted")
+	password, err := utils.PromptForSecret("Enter encryption password: ")
+	if err != nil {
+		return nil, err
+	}
+	if password.Len() == 0 {
+		password.Destroy()
+		return nil, fmt.Errorf("encryption password is required")
+	}
+	confirmPassword, err := utils.PromptForSecret("Confirm encryption password: ")
+	if err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	matches := password.Equal(confirmPassword)
+	confirmPassword.Destroy()
+	if !matches {
+		password.Destroy()
+		return nil, fmt.Errorf("passwords do not match")
+	}
+	if err := checkPasswordStrength(cfg, password); err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	return password, nil
+}
+
+// cryptDecryptWithPassword decrypts a password-encrypted file, trying the
+// keychain password before prompting
+func cryptDecryptWithPassword(cfg *config.Config, data []byte) ([]byte, error) {
+	if stored, err := keychainPassword(cfg); err != nil {
+		logger.Warning("⚠ %v", err)
+	} else if stored != nil {
+		decrypted, err := crypto.DecryptWithPassword(data, stored)
+		stored.Destroy()
+		if err == nil {
+			return decrypted, nil
+		}
+		logger.Warning("⚠ The password from the OS keychain didn't decrypt this file")
+	}
+
+	password, err := utils.PromptForSecret("Enter encryption password: ")
+	if err != nil {
+		return nil, err
+	}
+	defer password.Destroy()
+	logger.Progress("Decrypting...")
+	return crypto.DecryptWithPassword(data, password)
+}
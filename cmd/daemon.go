@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+)
+
+const (
+	// digestStateKey records when the weekly digest was last sent
+	digestStateKey = "digest.last_sent"
+	// daemonInterval is how often the daemon checks whether a job is due
+	daemonInterval = time.Minute
+	// digestRetryDelay is how long to wait before retrying a digest that failed to send
+	digestRetryDelay = time.Hour
+)
+
+// nextDigestAttempt delays retries after a digest failed to send
+var nextDigestAttempt time.Time
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled background jobs",
+	Long: `Run stashr in the foreground and perform scheduled background jobs until
+interrupted (Ctrl+C or SIGTERM).
+
+Jobs:
+  • Weekly digest - summarizes backups, sizes, verifications, failures and
+    upcoming retention deletions, sent through the configured notification
+    channels (notifications.digest)
+  • Health checks - checks that the password manager CLIs respond and their
+    sessions are unlocked, notifying when a session has been locked for more
+    than notifications.health.locked_days, since scheduled backups would fail
+  • Change backups - checks the vaults for changes (item revisions, without
+    exporting them) and backs up only the managers that changed
+    (backup.on_change)
+  • Restore drills - restores the latest backup of each manager on each
+    destination in memory and records the results (backup.drills)
+  • Upload retries - retries the uploads that failed during a backup, as
+    "stashr retry" does, every 15 minutes
+
+Run it under a service manager (systemd, launchd) or in a terminal
+multiplexer to keep it running.`,
+	Run: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(cmd *cobra.Command, args []string) {
+	logger.Header("🕑 stashr daemon")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if !cfg.Notifications.Digest.Enabled && !cfg.Notifications.Health.Enabled && !cfg.Backup.OnChange.Enabled && !cfg.Backup.Drills.Enabled {
+		logger.Warning("⚠ No jobs are enabled. Enable notifications.digest to send a weekly digest, notifications.health to check password manager sessions, backup.on_change to back up changed vaults or backup.drills to test-restore backups")
+	}
+	if cfg.Notifications.Digest.Enabled {
+		weekday, hour, minute, _ := cfg.Notifications.Digest.Schedule()
+		logger.Info("Weekly digest: every %s at %02d:%02d", weekday, hour, minute)
+	}
+	if cfg.Notifications.Health.Enabled {
+		logger.Info("Health checks: every %s, notifying after %d days locked", cfg.Notifications.Health.Interval, cfg.Notifications.Health.LockedDays)
+	}
+	if cfg.Backup.OnChange.Enabled {
+		logger.Info("Change backups: checking for changes every %s", cfg.Backup.OnChange.Interval)
+	}
+	if cfg.Backup.Drills.Enabled {
+		logger.Info("Restore drills: every %s", cfg.Backup.Drills.Interval)
+	}
+	logger.Info("Upload retries: every %d minutes while uploads are queued", int(retryQueueInterval.Minutes()))
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	ticker := time.NewTicker(daemonInterval)
+	defer ticker.Stop()
+
+	for {
+		if cfg.Notifications.Digest.Enabled {
+			runDigestJob(cfg, time.Now())
+		}
+		if cfg.Notifications.Health.Enabled {
+			runHealthJob(ctx, cfg, time.Now())
+		}
+		if cfg.Backup.OnChange.Enabled {
+			runChangeJob(ctx, cfg, time.Now())
+		}
+		if cfg.Backup.Drills.Enabled {
+			runDrillJob(cfg, time.Now())
+		}
+		runRetryJob(cfg, time.Now())
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Daemon stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDigestJob sends the weekly digest if its scheduled time has passed since it was last sent
+func runDigestJob(cfg *config.Config, now time.Time) {
+	weekday, hour, minute, err := cfg.Notifications.Digest.Schedule()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// Most recent scheduled time at or before now
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	scheduled = scheduled.AddDate(0, 0, -((int(now.Weekday()) - int(weekday) + 7) % 7))
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -7)
+	}
+
+	if now.Before(nextDigestAttempt) {
+		return
+	}
+
+	lastSent, err := database.GetState(digestStateKey)
+	if err != nil {
+		logger.Warning("Failed to read digest state: %v", err)
+		return
+	}
+
+	// The first digest is sent at the next scheduled time after the daemon first starts
+	if lastSent == "" {
+		if err := database.SetState(digestStateKey, now.Format(time.RFC3339)); err != nil {
+			logger.Warning("Failed to record digest state: %v", err)
+		}
+		return
+	}
+
+	since, err := time.Parse(time.RFC3339, lastSent)
+	if err != nil {
+		since = now.AddDate(0, 0, -7)
+	} else if !since.Before(scheduled) {
+		return
+	}
+
+	logger.Progress("Sending weekly digest...")
+	message, err := buildDigest(cfg, since, now)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := sendNotification(cfg, message); err != nil {
+		logger.PrintError(err)
+		nextDigestAttempt = now.Add(digestRetryDelay)
+		logger.Info("Retrying at %s", nextDigestAttempt.Format("15:04"))
+		return
+	}
+
+	if err := database.SetState(digestStateKey, now.Format(time.RFC3339)); err != nil {
+		logger.Warning("Failed to record digest state: %v", err)
+	}
+	logger.Success("✓ Weekly digest sent")
+}
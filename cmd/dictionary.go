@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/anonymize"
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/dictionary"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// dictionaryStateKey records the ID of the dictionary a manager's next backup is compressed with
+func dictionaryStateKey(manager string) string {
+	return "dictionary." + manager
+}
+
+// dictionaryEnabled reports whether backups are compressed with trained dictionaries
+func dictionaryEnabled(cfg *config.Config) bool {
+	return cfg.Backup.Compressed() && cfg.Backup.Dictionary.Enabled
+}
+
+// dictionaryCopyStateKey records that a destination has a copy of a dictionary
+func dictionaryCopyStateKey(destination string, id uint32) string {
+	return "dictionary.copy." + destination + "." + dictionary.FormatID(id)
+}
+
+// compressWithDictionary compresses an export with the manager's current
+// dictionary and returns the dictionary's ID. It returns nil if the manager has
+// no dictionary yet or the dictionary doesn't beat compressedSize, the size
+// with backup.compression.
+func compressWithDictionary(out *logger.Scope, cfg *config.Config, manager string, data []byte, compressedSize int) ([]byte, uint32) {
+	value, err := database.GetState(dictionaryStateKey(manager))
+	if err != nil {
+		out.Warning("Failed to read compression dictionary state: %v", err)
+		return nil, 0
+	}
+	if value == "" {
+		out.Info("  No compression dictionary for %s yet; one is trained after this backup", managerDisplayName(manager))
+		return nil, 0
+	}
+	id, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		out.Warning("Invalid compression dictionary ID %q", value)
+		return nil, 0
+	}
+
+	dict, err := dictionary.NewStore(cfg.Backup.Dictionary.Dir).Load(uint32(id))
+	if err != nil {
+		out.Warning("⚠ %v", err)
+		return nil, 0
+	}
+	compressed, err := dictionary.Compress(data, dict)
+	if err != nil {
+		out.Warning("⚠ %v", err)
+		return nil, 0
+	}
+	if len(compressed) >= compressedSize {
+		algorithm := cfg.Backup.CompressionAlgorithm()
+		out.Info("  Dictionary %s doesn't beat %s for this export; using %s", value, algorithm, algorithm)
+		return nil, 0
+	}
+
+	out.Success("✓ Compressed with dictionary %s (%s → %s)", value, utils.FormatBytes(int64(len(data))), utils.FormatBytes(int64(len(compressed))))
+	return compressed, uint32(id)
+}
+
+// storeDictionaryCopy uploads a copy of a dictionary to a destination that has
+// a backup compressed with it, so the backup can be restored on a machine whose
+// dictionary directory doesn't have it. Each destination gets each dictionary once.
+func storeDictionaryCopy(out *logger.Scope, cfg *config.Config, backend storage.Storage, id uint32) {
+	key := dictionaryCopyStateKey(backend.Name(), id)
+	if value, err := database.GetState(key); err != nil || value != "" {
+		return
+	}
+
+	dict, err := dictionary.NewStore(cfg.Backup.Dictionary.Dir).Load(id)
+	if err != nil {
+		out.Warning("⚠ %s: %v", backend.Name(), err)
+		return
+	}
+	if err := backend.Upload(dictionary.DestinationPath(id), dict); err != nil {
+		out.Warning("⚠ Failed to copy compression dictionary %s to %s: %v", dictionary.FormatID(id), backend.Name(), err)
+		return
+	}
+	if err := database.SetState(key, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		out.Warning("Failed to record compression dictionary copy: %v", err)
+	}
+}
+
+// trainDictionary adds an anonymized copy of an export to the manager's samples
+// and trains the dictionary its next backup is compressed with. Failing to train
+// is not fatal; the current dictionary stays in use.
+func trainDictionary(out *logger.Scope, cfg *config.Config, manager string, data []byte) {
+	sample, err := anonymize.Export(data)
+	if err != nil {
+		out.Warning("Skipping compression dictionary training: %v", err)
+		return
+	}
+
+	store := dictionary.NewStore(cfg.Backup.Dictionary.Dir)
+	if err := store.AddSample(manager, sample.Data, cfg.Backup.Dictionary.Samples); err != nil {
+		out.Warning("Skipping compression dictionary training: %v", err)
+		return
+	}
+	samples, err := store.Samples(manager)
+	if err != nil {
+		out.Warning("Skipping compression dictionary training: %v", err)
+		return
+	}
+
+	dict, err := dictionary.Train(samples, dictionary.MaxSize)
+	if err != nil {
+		out.Warning("Skipping compression dictionary training: %v", err)
+		return
+	}
+	if len(dict) == 0 {
+		return
+	}
+	id, err := store.Save(dict)
+	if err != nil {
+		out.Warning("Failed to save compression dictionary: %v", err)
+		return
+	}
+	if err := database.SetState(dictionaryStateKey(manager), dictionary.FormatID(id)); err != nil {
+		out.Warning("Failed to record compression dictionary: %v", err)
+		return
+	}
+	out.Info("  Trained compression dictionary %s on %d past exports", dictionary.FormatID(id), len(samples))
+}
+
+// isCompressedBackup reports whether decrypted backup data is compressed with
+// one of the compression algorithms or with a dictionary
+func isCompressedBackup(data []byte) bool {
+	if utils.IsCompressed(data) {
+		return true
+	}
+	_, ok := dictionary.HeaderID(data)
+	return ok
+}
+
+// decompressBackup decompresses decrypted backup data, loading the dictionary
+// recorded in its header if it has one. A dictionary missing from the
+// dictionary directory is fetched from the destinations' copies. cfg may be
+// nil, in which case the configuration is loaded when a dictionary is needed.
+func decompressBackup(cfg *config.Config, data []byte) ([]byte, error) {
+	id, ok := dictionary.HeaderID(data)
+	if !ok {
+		return utils.DecompressData(data)
+	}
+
+	if cfg == nil {
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			return nil, err
+		}
+	}
+	dict, err := loadDictionary(cfg, id)
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := dictionary.Decompress(data, dict)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary %s: %w", dictionary.FormatID(id), err)
+	}
+	return decompressed, nil
+}
+
+// loadDictionary returns a dictionary from the dictionary directory or, if it
+// isn't there, from the first destination with a copy. A fetched copy is saved
+// to the dictionary directory.
+func loadDictionary(cfg *config.Config, id uint32) ([]byte, error) {
+	store := dictionary.NewStore(cfg.Backup.Dictionary.Dir)
+	dict, err := store.Load(id)
+	if !errors.Is(err, dictionary.ErrNotFound) {
+		return dict, err
+	}
+
+	logger.Progress("Compression dictionary %s is not in %s, searching the destinations...", dictionary.FormatID(id), cfg.Backup.Dictionary.Dir)
+	dict, source, findErr := findBackupInAllSources(cfg, dictionary.DestinationPath(id))
+	if findErr != nil {
+		return nil, err
+	}
+	// The copy is only trusted if it is the dictionary the backup names
+	if copyID, idErr := dictionary.ID(dict); idErr != nil || copyID != id {
+		return nil, fmt.Errorf("the copy of compression dictionary %s on %s is damaged", dictionary.FormatID(id), source)
+	}
+	logger.Success("✓ Loaded compression dictionary %s from %s", dictionary.FormatID(id), source)
+	if _, err := store.Save(dict); err != nil {
+		logger.Warning("Failed to save compression dictionary: %v", err)
+	}
+	return dict, nil
+}
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/notify"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	digestDays int
+	digestSend bool
+)
+
+// digestCmd represents the digest command
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize recent backup activity",
+	Long: `Summarize backup activity over the last week: backups taken and their sizes,
+verification results, failures, and the backups the retention policy will
+delete on the next run.
+
+The digest is printed by default. Use --send to deliver it through the
+configured notification channels; in daemon mode it is sent automatically
+on the day and time configured under notifications.digest.`,
+	Run: runDigest,
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	digestCmd.Flags().IntVar(&digestDays, "days", 7, "Number of days to summarize")
+	digestCmd.Flags().BoolVar(&digestSend, "send", false, "Send the digest through the configured notification channels")
+}
+
+func runDigest(cmd *cobra.Command, args []string) {
+	logger.Header("📬 Backup Digest")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	until := time.Now()
+	message, err := buildDigest(cfg, until.AddDate(0, 0, -digestDays), until)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if !digestSend {
+		fmt.Println(message.Subject)
+		fmt.Println()
+		fmt.Println(message.Body)
+		return
+	}
+
+	if err := sendNotification(cfg, message); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Digest sent")
+}
+
+// buildDigest summarizes backups, verifications, failures and upcoming
+// retention deletions between since and until
+func buildDigest(cfg *config.Config, since, until time.Time) (notify.Message, error) {
+	records, err := database.ListBackups("", "", nil)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	events, err := database.ListEvents(since)
+	if err != nil {
+		return notify.Message{}, err
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Period: %s to %s\n", since.Format("2006-01-02"), until.Format("2006-01-02"))
+
+	// Backups taken, newest first as listed by the database
+	var taken []database.BackupRecord
+	var totalSize int64
+	latest := make(map[string]time.Time)
+	for _, record := range records {
+		if record.CreatedAt.After(latest[record.Manager]) {
+			latest[record.Manager] = record.CreatedAt
+		}
+		if record.CreatedAt.Before(since) || record.CreatedAt.After(until) {
+			continue
+		}
+		taken = append(taken, record)
+		totalSize += record.Size
+	}
+
+	fmt.Fprintf(&body, "\nBackups taken: %d (%s)\n", len(taken), utils.FormatBytes(totalSize))
+	for _, record := range taken {
+		line := fmt.Sprintf("  • %s  %s  %s  %s", record.CreatedAt.Format("2006-01-02 15:04"), record.Manager, record.StorageType, utils.FormatBytes(record.Size))
+		if record.ItemCount != nil {
+			line += fmt.Sprintf("  %d items", *record.ItemCount)
+		}
+		if len(record.Labels) > 0 {
+			line += fmt.Sprintf("  [%s]", formatTags(record.Labels))
+		}
+		body.WriteString(line + "\n")
+	}
+
+	// Managers without a backup in the period are the most important thing to notice
+	var stale []string
+	for _, manager := range enabledManagerNames(cfg) {
+		last, ok := latest[manager]
+		switch {
+		case !ok:
+			stale = append(stale, fmt.Sprintf("  • %s: never backed up", manager))
+		case last.Before(since):
+			stale = append(stale, fmt.Sprintf("  • %s: last backup %s ago", manager, formatAge(until.Sub(last))))
+		}
+	}
+	if len(stale) > 0 {
+		body.WriteString("\nNo backup this period:\n")
+		body.WriteString(strings.Join(stale, "\n") + "\n")
+	}
+
+	// Verifications and failures come from the event log
+	var verified, verifyFailed int
+	var failures, postponed []string
+	for _, event := range events {
+		if event.CreatedAt.After(until) {
+			continue
+		}
+		// Postponed backups didn't fail, they wait for backup.conditions
+		if event.Kind == database.EventPostponed {
+			postponed = append(postponed, fmt.Sprintf("  • %s  %s: %s", event.CreatedAt.Format("2006-01-02 15:04"), valueOr(event.Manager, "all managers"), event.Message))
+			continue
+		}
+		if event.Kind == database.EventVerification {
+			if event.Success {
+				verified++
+			} else {
+				verifyFailed++
+			}
+		}
+		if event.Success {
+			continue
+		}
+
+		subject := event.Manager
+		if labels := cfg.ManagerLabels(event.Manager); len(labels) > 0 {
+			subject += fmt.Sprintf(" [%s]", formatTags(labels))
+		}
+		if event.StorageType != "" {
+			subject += " → " + event.StorageType
+		}
+		failures = append(failures, fmt.Sprintf("  • %s  %s %s: %s", event.CreatedAt.Format("2006-01-02 15:04"), event.Kind, subject, event.Message))
+	}
+
+	fmt.Fprintf(&body, "\nVerifications: %d passed, %d failed\n", verified, verifyFailed)
+	if verified+verifyFailed == 0 {
+		body.WriteString("  No backups were test-restored. Run 'stashr rehearse' to verify them.\n")
+	}
+
+	fmt.Fprintf(&body, "\nFailures: %d\n", len(failures))
+	for _, failure := range failures {
+		body.WriteString(failure + "\n")
+	}
+
+	if len(postponed) > 0 {
+		fmt.Fprintf(&body, "\nPostponed by backup.conditions: %d\n", len(postponed))
+		body.WriteString(strings.Join(postponed, "\n") + "\n")
+	}
+
+	// Retention deletions the next backup run will perform
+	deletions := upcomingRetentionDeletions(cfg)
+	fmt.Fprintf(&body, "\nRetention deletions on next backup (keep %s per manager): %d\n", retentionPolicy(cfg), len(deletions))
+	for _, deletion := range deletions {
+		body.WriteString(deletion + "\n")
+	}
+
+	status := "OK"
+	if len(failures) > 0 || len(stale) > 0 {
+		status = "attention needed"
+	}
+	hostname, _ := os.Hostname()
+	subject := fmt.Sprintf("stashr weekly digest (%s): %d backups, %d failures - %s", hostname, len(taken), len(failures), status)
+
+	return notify.Message{Subject: subject, Body: body.String(), SentAt: until}, nil
+}
+
+// upcomingRetentionDeletions lists the backups each destination will delete when
+// the next backup of every enabled manager is uploaded
+func upcomingRetentionDeletions(cfg *config.Config) []string {
+	now := time.Now()
+
+	var deletions []string
+	for _, backend := range getStorageBackendsForRestore(cfg) {
+		if available, _ := backend.IsAvailable(); !available {
+			continue
+		}
+		backups, err := backend.List()
+		if err != nil {
+			continue
+		}
+
+		format := artifactFormat(cfg, effectiveEncryptionMode(cfg, backend), destinationArtifact(cfg, backend).Extension)
+		var planned []string
+		for _, manager := range enabledManagerNames(cfg) {
+			planned = append(planned, artifactFilename(cfg, format, manager, now))
+		}
+
+		candidates := plannedRetentionDeletions(cfg, backend, backups, planned, now)
+		// Oldest backups are listed first
+		for i := len(candidates) - 1; i >= 0; i-- {
+			backup := candidates[i]
+			deletions = append(deletions, fmt.Sprintf("  • %s: %s (%s)", backend.Name(), backup.Name, backup.ModifiedTime.Format("2006-01-02")))
+		}
+	}
+
+	return deletions
+}
+
+// notifiers returns the enabled notification channels whose min_severity
+// admits messages of the given severity
+func notifiers(cfg *config.Config, severity notify.Severity) []notify.Notifier {
+	var channels []notify.Notifier
+	accepts := func(minSeverity string) bool {
+		// Validation rejects unknown severities; the zero value sends everything
+		threshold, _ := notify.ParseSeverity(minSeverity)
+		return severity >= threshold
+	}
+	notifications := cfg.Notifications
+
+	if notifications.Webhook.Enabled && accepts(notifications.Webhook.MinSeverity) {
+		// Sign webhook payloads once this installation has an identity
+		current, err := loadIdentity()
+		if err != nil {
+			logger.Warning("⚠ Sending unsigned webhook: %v", err)
+		}
+		if current != nil {
+			channels = append(channels, notify.NewSignedWebhook(notifications.Webhook.URL, current.ID, current.WebhookSecret()))
+		} else {
+			channels = append(channels, notify.NewWebhook(notifications.Webhook.URL))
+		}
+	}
+
+	if email := notifications.Email; email.Enabled && accepts(email.MinSeverity) {
+		password := email.Password
+		if password == "" {
+			password = os.Getenv("STASHR_SMTP_PASSWORD")
+		}
+		channels = append(channels, notify.NewEmail(email.SMTPHost, email.SMTPPort, email.Username, password, email.From, email.To))
+	}
+
+	if notifications.Slack.Enabled && accepts(notifications.Slack.MinSeverity) {
+		channels = append(channels, notify.NewSlack(notifications.Slack.URL()))
+	}
+	if notifications.Discord.Enabled && accepts(notifications.Discord.MinSeverity) {
+		channels = append(channels, notify.NewDiscord(notifications.Discord.URL()))
+	}
+	if telegram := notifications.Telegram; telegram.Enabled && accepts(telegram.MinSeverity) {
+		bot := notify.NewTelegram(telegram.Token(), telegram.ChatID)
+		if telegram.APIURL != "" {
+			bot.APIURL = strings.TrimSuffix(telegram.APIURL, "/")
+		}
+		channels = append(channels, bot)
+	}
+
+	return channels
+}
+
+// sendNotification delivers a message to every enabled channel that accepts its
+// severity, succeeding if at least one accepted it
+func sendNotification(cfg *config.Config, message notify.Message) error {
+	if !cfg.Notifications.ChannelEnabled() {
+		return fmt.Errorf("no notification channels enabled (configure notifications.webhook, email, slack, discord or telegram)")
+	}
+	channels := notifiers(cfg, message.Severity)
+	if len(channels) == 0 {
+		return fmt.Errorf("no notification channel accepts %s messages (check the channels' min_severity)", message.Severity)
+	}
+	return deliverNotification(cfg, channels, message)
+}
+
+// deliverNotification redacts a message and sends it to the given channels,
+// succeeding if at least one accepted it
+func deliverNotification(cfg *config.Config, channels []notify.Notifier, message notify.Message) error {
+	// Notifications leave the machine, so mask them like log files
+	redactor, err := newRedactor(cfg.Logging.Redaction)
+	if err != nil {
+		return err
+	}
+	message.Subject = redactor.Redact(message.Subject)
+	message.Body = redactor.Redact(message.Body)
+
+	errs := notify.SendAll(channels, message)
+	for _, err := range errs {
+		logger.Warning("⚠ %v", err)
+	}
+	if len(errs) == len(channels) {
+		return fmt.Errorf("failed to send notification to any channel")
+	}
+
+	return nil
+}
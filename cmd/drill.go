@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/notify"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+const (
+	// drillStateKey records when the daemon last ran the restore drills
+	drillStateKey = "drills.last_run"
+	// drillRetryDelay is how long to wait before retrying drills that couldn't run
+	drillRetryDelay = time.Hour
+)
+
+// nextDrillAttempt delays retries after the restore drills couldn't run
+var nextDrillAttempt time.Time
+
+// drillTarget is the latest backup of a manager on a destination
+type drillTarget struct {
+	manager string
+	backend storage.Storage
+	backup  storage.BackupFile
+}
+
+// runDrills test-restores the latest backup of each enabled manager on each
+// destination: it is downloaded, checked, decrypted, decompressed and parsed in
+// memory, never written to disk. Each result is recorded in the database. With
+// interactive unset, passwords the environment and keychain don't have aren't
+// asked for. It returns the recorded drills.
+func runDrills(cfg *config.Config, interactive bool) ([]database.DrillRecord, error) {
+	// A cached copy proves nothing about the stored one
+	noCache = true
+
+	verifier := &integrityVerifier{cfg: cfg, names: newBackupNameParser(cfg), decrypt: true, keyFile: verifyKeyFile, interactive: interactive, passwords: make(map[string]*secret.Buffer)}
+	defer func() {
+		for _, password := range verifier.passwords {
+			password.Destroy()
+		}
+	}()
+
+	enabled := enabledManagerNames(cfg)
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("no password managers enabled")
+	}
+
+	var targets []drillTarget
+	for _, backend := range getStorageBackendsForRestore(cfg) {
+		// Nobody is there to type a destination's own password
+		if !interactive && effectiveEncryptionMode(cfg, backend) == config.EncryptionModePassword && destinationEncryption(cfg, backend).SeparatePassword {
+			logger.Info("Skipping %s: it uses a separate password, which unattended drills can't ask for", backend.Name())
+			continue
+		}
+		if available, err := backend.IsAvailable(); err != nil || !available {
+			logger.Warning("⚠ Skipping %s: not available", backend.Name())
+			continue
+		}
+		backups, err := backend.List()
+		if err != nil {
+			logger.Warning("⚠ Skipping %s: %v", backend.Name(), err)
+			continue
+		}
+
+		latest := make(map[string]storage.BackupFile)
+		for _, backup := range backups {
+			manager := detectManager(verifier.names, backup.Name)
+			if current, ok := latest[manager]; !ok || backup.ModifiedTime.After(current.ModifiedTime) {
+				latest[manager] = backup
+			}
+		}
+		for _, manager := range enabled {
+			if backup, ok := latest[manager]; ok {
+				targets = append(targets, drillTarget{manager: manager, backend: backend, backup: backup})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no backups of the enabled managers were found on a reachable destination")
+	}
+	sort.SliceStable(targets, func(i, j int) bool { return targets[i].manager < targets[j].manager })
+
+	var drills []database.DrillRecord
+	for _, target := range targets {
+		display := managerDisplayName(target.manager)
+		logger.Progress("Restore drill of %s from %s (%s)...", display, target.backend.Name(), target.backup.Name)
+
+		start := time.Now()
+		result := verifier.verifyCopy(target.backend, target.backup.Name)
+		drill := database.DrillRecord{
+			Manager:     target.manager,
+			StorageType: target.backend.Name(),
+			Filename:    target.backup.Name,
+			Success:     result.failed == "",
+			FailedCheck: result.failed,
+			Duration:    time.Since(start),
+		}
+		if result.items >= 0 {
+			items := result.items
+			drill.ItemCount = &items
+		}
+		if result.err != nil {
+			drill.Message = result.err.Error()
+		}
+		if err := database.RecordDrill(drill); err != nil {
+			logger.Warning("Failed to record restore drill: %v", err)
+		}
+		drills = append(drills, drill)
+
+		if drill.Success {
+			logger.Success("✓ Restored %s from %s: %d items in %s", display, target.backend.Name(), result.items, formatLatency(drill.Duration))
+		} else {
+			logger.Failure("✗ %s from %s: %s check failed: %s", display, target.backend.Name(), drill.FailedCheck, drill.Message)
+		}
+	}
+	return drills, nil
+}
+
+// runVerifyDrill runs the restore drills for "stashr verify --drill" and exits
+// with status 1 if any failed
+func runVerifyDrill(cfg *config.Config) {
+	drills, err := runDrills(cfg, true)
+	if err != nil {
+		logger.PrintError(err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, drill := range drills {
+		if !drill.Success {
+			failed++
+		}
+	}
+	logger.Separator()
+	if failed > 0 {
+		logger.Failure("⛔ %d of %d restore drills failed", failed, len(drills))
+		os.Exit(1)
+	}
+	logger.Success("✅ All %d restore drills passed: the latest backups can be restored", len(drills))
+}
+
+// runDrillJob runs the restore drills in daemon mode when the drill interval
+// has passed since they last ran, and notifies about failed drills
+func runDrillJob(cfg *config.Config, now time.Time) {
+	if now.Before(nextDrillAttempt) {
+		return
+	}
+	interval, err := cfg.Backup.Drills.RunInterval()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	lastRun, err := database.GetState(drillStateKey)
+	if err != nil {
+		logger.Warning("Failed to read restore drill state: %v", err)
+		return
+	}
+	// The first drills run as soon as the daemon starts
+	if lastRun != "" {
+		last, err := time.Parse(time.RFC3339, lastRun)
+		if err == nil && now.Before(last.Add(interval)) {
+			return
+		}
+	}
+
+	logger.Progress("Running restore drills...")
+	drills, err := runDrills(cfg, false)
+	if err != nil {
+		logger.PrintError(err)
+		nextDrillAttempt = now.Add(drillRetryDelay)
+		return
+	}
+	if err := database.SetState(drillStateKey, now.Format(time.RFC3339)); err != nil {
+		logger.Warning("Failed to record restore drill state: %v", err)
+	}
+
+	var failures []string
+	for _, drill := range drills {
+		if !drill.Success {
+			failures = append(failures, fmt.Sprintf("  • %s from %s (%s): %s check failed: %s",
+				managerDisplayName(drill.Manager), drill.StorageType, drill.Filename, drill.FailedCheck, drill.Message))
+		}
+	}
+	if len(failures) == 0 {
+		logger.Success("✓ All %d restore drills passed", len(drills))
+		return
+	}
+
+	logger.Warning("⚠ %d of %d restore drills failed", len(failures), len(drills))
+	if !cfg.Notifications.ChannelEnabled() {
+		return
+	}
+	message := notify.Message{
+		Subject:  fmt.Sprintf("stashr: %d of %d restore drills failed", len(failures), len(drills)),
+		Severity: notify.SeverityError,
+		Body: fmt.Sprintf("These backups couldn't be restored in the scheduled restore drill:\n\n%s\n\nRun 'stashr verify --decrypt' to check every stored copy.",
+			strings.Join(failures, "\n")),
+	}
+	if err := sendNotification(cfg, message); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Sent restore drill notification")
+}
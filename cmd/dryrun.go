@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+)
+
+// dryRunPlan collects the operations a command would perform. Commands that
+// change or delete anything accept --dry-run, build the same plan they would
+// execute, and print it instead, so every preview lists exact operations.
+type dryRunPlan struct {
+	operations []string
+}
+
+// Add records an operation, e.g. "Delete backup_x.json.enc from Google Drive"
+func (p *dryRunPlan) Add(format string, args ...interface{}) {
+	p.operations = append(p.operations, i18n.Tf(format, args...))
+}
+
+// Print shows the planned operations and how to perform them
+func (p *dryRunPlan) Print() {
+	logger.Info("Planned operations:")
+	if len(p.operations) == 0 {
+		logger.Info("  (none)")
+	}
+	for _, operation := range p.operations {
+		logger.Info("  • %s", operation)
+	}
+	logger.Separator()
+	logger.Success("✅ Dry run complete! Nothing was changed.")
+	logger.Info("To perform these operations, run the same command without --dry-run")
+}
+
+// printDryRunHeader announces that a command is only previewing its operations
+func printDryRunHeader() {
+	logger.Info("🔍 DRY RUN MODE - Preview Only (nothing will be changed)")
+	logger.Separator()
+}
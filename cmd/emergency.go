@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
@@ -11,7 +12,9 @@ import (
 
 	"github.com/harshalranjhani/stashr/internal/config"
 	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
@@ -76,16 +79,22 @@ func runEmergency(cmd *cobra.Command, args []string) {
 	pdf.SetMargins(20, 20, 20)
 	pdf.AddPage()
 
+	// Translate messages and convert them to the PDF core font encoding
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	t := func(message string) string {
+		return tr(i18n.T(message))
+	}
+
 	// Title
 	pdf.SetFont("Arial", "B", 24)
 	pdf.SetTextColor(200, 0, 0)
-	pdf.Cell(0, 15, "EMERGENCY ACCESS KIT")
+	pdf.Cell(0, 15, t("EMERGENCY ACCESS KIT"))
 	pdf.Ln(10)
 
 	// Subtitle
 	pdf.SetFont("Arial", "", 12)
 	pdf.SetTextColor(100, 100, 100)
-	pdf.Cell(0, 8, fmt.Sprintf("Generated: %s", time.Now().Format("2006-01-02 15:04:05")))
+	pdf.Cell(0, 8, fmt.Sprintf(t("Generated: %s"), time.Now().Format("2006-01-02 15:04:05")))
 	pdf.Ln(15)
 
 	// Warning box
@@ -95,69 +104,136 @@ func runEmergency(cmd *cobra.Command, args []string) {
 	pdf.SetY(pdf.GetY() + 5)
 	pdf.SetFont("Arial", "B", 10)
 	pdf.SetTextColor(200, 100, 0)
-	pdf.Cell(0, 5, "WARNING: Keep this document secure!")
+	pdf.Cell(0, 5, t("WARNING: Keep this document secure!"))
 	pdf.Ln(5)
 	pdf.SetFont("Arial", "", 9)
 	pdf.SetTextColor(0, 0, 0)
-	pdf.Cell(0, 5, "This document contains information about your backup configuration.")
+	pdf.Cell(0, 5, t("This document contains information about your backup configuration."))
 	pdf.Ln(5)
-	pdf.Cell(0, 5, "Do not share with unauthorized persons.")
+	pdf.Cell(0, 5, t("Do not share with unauthorized persons."))
 	pdf.Ln(15)
 
 	// Configuration Summary
-	addSection(pdf, "1. Configuration Summary")
+	addSection(pdf, t("1. Configuration Summary"))
 	pdf.SetFont("Arial", "", 10)
 
 	// Password Managers
 	pdf.SetFont("Arial", "B", 10)
-	pdf.Cell(0, 6, "Password Managers:")
+	pdf.Cell(0, 6, t("Password Managers:"))
 	pdf.Ln(6)
 	pdf.SetFont("Arial", "", 10)
 
 	if cfg.PasswordManagers.Bitwarden.Enabled {
-		pdf.Cell(0, 5, fmt.Sprintf("  - Bitwarden: Enabled (Email: %s)", redactEmail(cfg.PasswordManagers.Bitwarden.Email)))
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - Bitwarden: Enabled (Email: %s)"), redactEmail(cfg.PasswordManagers.Bitwarden.Email)))
 		pdf.Ln(5)
+		if labels := cfg.PasswordManagers.Bitwarden.Labels; len(labels) > 0 {
+			pdf.Cell(0, 5, fmt.Sprintf(t("    Classification: %s"), strings.Join(labels, ", ")))
+			pdf.Ln(5)
+		}
 	}
 	if cfg.PasswordManagers.OnePassword.Enabled {
-		pdf.Cell(0, 5, fmt.Sprintf("  - 1Password: Enabled (Account: %s)", redactDomain(cfg.PasswordManagers.OnePassword.Account)))
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - 1Password: Enabled (Account: %s)"), redactDomain(cfg.PasswordManagers.OnePassword.Account)))
 		pdf.Ln(5)
+		if labels := cfg.PasswordManagers.OnePassword.Labels; len(labels) > 0 {
+			pdf.Cell(0, 5, fmt.Sprintf(t("    Classification: %s"), strings.Join(labels, ", ")))
+			pdf.Ln(5)
+		}
 	}
 	pdf.Ln(5)
 
 	// Storage Backends
 	pdf.SetFont("Arial", "B", 10)
-	pdf.Cell(0, 6, "Storage Backends:")
+	pdf.Cell(0, 6, t("Storage Backends:"))
 	pdf.Ln(6)
 	pdf.SetFont("Arial", "", 10)
 
 	if cfg.Storage.Local.Enabled {
-		pdf.Cell(0, 5, fmt.Sprintf("  - Local: %s", cfg.Storage.Local.BackupPath))
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - Local: %s"), cfg.Storage.Local.BackupPath))
 		pdf.Ln(5)
 	}
 	if cfg.Storage.USB.Enabled {
-		pdf.Cell(0, 5, fmt.Sprintf("  - USB: %s/%s", cfg.Storage.USB.MountPath, cfg.Storage.USB.BackupDir))
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - USB: %s/%s"), usbDriveDescription(cfg.Storage.USB), cfg.Storage.USB.BackupDir))
 		pdf.Ln(5)
 	}
 	if cfg.Storage.GoogleDrive.Enabled {
-		pdf.Cell(0, 5, "  - Google Drive: Enabled")
+		pdf.Cell(0, 5, t("  - Google Drive: Enabled"))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.OneDrive.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - OneDrive: %s"), cfg.Storage.OneDrive.Folder))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.WebDAV.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - WebDAV: %s/%s"), cfg.Storage.WebDAV.URL, cfg.Storage.WebDAV.BackupDir))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.GCS.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - Google Cloud Storage: gs://%s/%s"), cfg.Storage.GCS.Bucket, cfg.Storage.GCS.Prefix))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.AzureBlob.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - Azure Blob: %s/%s"), cfg.Storage.AzureBlob.Container, cfg.Storage.AzureBlob.Prefix))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.S3.Enabled {
+		endpoint := cfg.Storage.S3.Endpoint
+		if endpoint == "" {
+			endpoint = storage.DefaultS3Endpoint
+		}
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - S3: s3://%s/%s at %s"), cfg.Storage.S3.Bucket, cfg.Storage.S3.Prefix, endpoint))
 		pdf.Ln(5)
 	}
+	if cfg.Storage.Rclone.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - rclone: %s"), cfg.Storage.Rclone.Remote))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.ICloud.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - iCloud Drive: %s"), cfg.Storage.ICloud.BackupDir))
+		pdf.Ln(5)
+	}
+	if cfg.Storage.GitAnnex.Enabled {
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - git-annex: %s/%s"), cfg.Storage.GitAnnex.RepoPath, cfg.Storage.GitAnnex.BackupDir))
+		pdf.Ln(5)
+	}
+	for _, profile := range cfg.Storage.Destinations {
+		if profile.Enabled() {
+			pdf.Cell(0, 5, fmt.Sprintf(t("  - %s (%s)"), profile.Name, profile.Kind()))
+			pdf.Ln(5)
+		}
+	}
+	for _, plugin := range cfg.Storage.Plugins {
+		if plugin.Enabled {
+			// Options may hold credentials, so only the executable is listed
+			pdf.Cell(0, 5, fmt.Sprintf(t("  - Plugin %s: %s"), plugin.Name, storage.NewPlugin(plugin.Name, plugin.Path, nil).Path))
+			pdf.Ln(5)
+		}
+	}
 	pdf.Ln(5)
 
 	// Backup Settings
 	pdf.SetFont("Arial", "B", 10)
-	pdf.Cell(0, 6, "Backup Settings:")
+	pdf.Cell(0, 6, t("Backup Settings:"))
 	pdf.Ln(6)
 	pdf.SetFont("Arial", "", 10)
-	pdf.Cell(0, 5, fmt.Sprintf("  - Encryption: %v (%s)", cfg.Backup.Encryption.Enabled, cfg.Backup.Encryption.Algorithm))
+	pdf.Cell(0, 5, fmt.Sprintf(t("  - Encryption: %v (%s)"), cfg.Backup.Encryption.Enabled, cfg.Backup.Encryption.Algorithm))
 	pdf.Ln(5)
-	pdf.Cell(0, 5, fmt.Sprintf("  - Compression: %v", cfg.Backup.Compression))
+	pdf.Cell(0, 5, fmt.Sprintf(t("  - Compression: %v"), cfg.Backup.CompressionAlgorithm()))
 	pdf.Ln(5)
-	pdf.Cell(0, 5, fmt.Sprintf("  - Retention: Keep last %d backups", cfg.Backup.Retention.KeepLast))
+	pdf.Cell(0, 5, fmt.Sprintf(t("  - Retention: Keep %s per manager"), retentionPolicy(cfg)))
+	for _, dest := range storageDestinations(cfg) {
+		if dest.enabled && dest.retention != nil {
+			pdf.Ln(5)
+			pdf.Cell(0, 5, fmt.Sprintf(t("  - Retention on %s: Keep %s per manager"), dest.flag, newRetentionPolicy(*dest.retention)))
+		}
+	}
+	if dictionaryEnabled(cfg) {
+		pdf.Ln(5)
+		pdf.Cell(0, 5, fmt.Sprintf(t("  - Compression dictionaries (needed to restore): %s"), cfg.Backup.Dictionary.Dir))
+	}
 	pdf.Ln(10)
 
 	// Recent Backups
-	addSection(pdf, "2. Recent Backups")
+	addSection(pdf, t("2. Recent Backups"))
 	pdf.SetFont("Arial", "", 10)
 
 	backups, err := database.ListBackups("", "", nil)
@@ -171,28 +247,28 @@ func runEmergency(cmd *cobra.Command, args []string) {
 		for i := 0; i < count; i++ {
 			backup := backups[i]
 			pdf.SetFont("Arial", "B", 9)
-			pdf.Cell(0, 5, fmt.Sprintf("Backup %d:", i+1))
+			pdf.Cell(0, 5, fmt.Sprintf(t("Backup %d:"), i+1))
 			pdf.Ln(5)
 			pdf.SetFont("Arial", "", 9)
-			pdf.Cell(0, 4, fmt.Sprintf("  File: %s", truncatePDF(backup.Filename, 60)))
+			pdf.Cell(0, 4, fmt.Sprintf(t("  File: %s"), truncatePDF(backup.Filename, 60)))
 			pdf.Ln(4)
-			pdf.Cell(0, 4, fmt.Sprintf("  Manager: %s", backup.Manager))
+			pdf.Cell(0, 4, fmt.Sprintf(t("  Manager: %s"), backup.Manager))
 			pdf.Ln(4)
-			pdf.Cell(0, 4, fmt.Sprintf("  Storage: %s", backup.StorageType))
+			pdf.Cell(0, 4, fmt.Sprintf(t("  Storage: %s"), backup.StorageType))
 			pdf.Ln(4)
-			pdf.Cell(0, 4, fmt.Sprintf("  Size: %s", utils.FormatBytes(backup.Size)))
+			pdf.Cell(0, 4, fmt.Sprintf(t("  Size: %s"), utils.FormatBytes(backup.Size)))
 			pdf.Ln(4)
-			pdf.Cell(0, 4, fmt.Sprintf("  Date: %s", backup.CreatedAt.Format("2006-01-02 15:04:05")))
+			pdf.Cell(0, 4, fmt.Sprintf(t("  Date: %s"), backup.CreatedAt.Format("2006-01-02 15:04:05")))
 			pdf.Ln(6)
 		}
 	} else {
-		pdf.Cell(0, 5, "No recent backups found in database.")
+		pdf.Cell(0, 5, t("No recent backups found in database."))
 		pdf.Ln(10)
 	}
 
 	// Restoration Guide
 	pdf.AddPage()
-	addSection(pdf, "3. Emergency Restoration Guide")
+	addSection(pdf, t("3. Emergency Restoration Guide"))
 	pdf.SetFont("Arial", "", 10)
 
 	steps := []string{
@@ -231,14 +307,14 @@ func runEmergency(cmd *cobra.Command, args []string) {
 		if step == "" {
 			pdf.Ln(3)
 		} else {
-			pdf.Cell(0, 4, step)
+			pdf.Cell(0, 4, t(step))
 			pdf.Ln(4)
 		}
 	}
 
 	// Important Notes
 	pdf.AddPage()
-	addSection(pdf, "4. Important Notes")
+	addSection(pdf, t("4. Important Notes"))
 	pdf.SetFont("Arial", "", 10)
 
 	notes := []string{
@@ -250,12 +326,12 @@ func runEmergency(cmd *cobra.Command, args []string) {
 		"",
 		"Google Drive Access:",
 		"  - Requires credentials file from Google Cloud Console",
-		"  - Location: " + cfg.Storage.GoogleDrive.CredentialsPath,
+		i18n.T("  - Location: ") + cfg.Storage.GoogleDrive.CredentialsPath,
 		"  - You may need to re-authenticate",
 		"",
 		"USB Drive:",
 		"  - Must be mounted at the configured path",
-		"  - Backup directory: " + cfg.Storage.USB.BackupDir,
+		i18n.T("  - Backup directory: ") + cfg.Storage.USB.BackupDir,
 		"",
 		"Security Recommendations:",
 		"  - Keep this document in a secure location",
@@ -272,7 +348,7 @@ func runEmergency(cmd *cobra.Command, args []string) {
 		if note == "" {
 			pdf.Ln(3)
 		} else {
-			pdf.Cell(0, 4, note)
+			pdf.Cell(0, 4, t(note))
 			pdf.Ln(4)
 		}
 	}
@@ -281,9 +357,9 @@ func runEmergency(cmd *cobra.Command, args []string) {
 	pdf.Ln(10)
 	pdf.SetFont("Arial", "I", 8)
 	pdf.SetTextColor(150, 150, 150)
-	pdf.Cell(0, 4, "Generated by stashr - Password Manager Backup Tool")
+	pdf.Cell(0, 4, t("Generated by stashr - Password Manager Backup Tool"))
 	pdf.Ln(4)
-	pdf.Cell(0, 4, fmt.Sprintf("Document ID: %s", time.Now().Format("20060102-150405")))
+	pdf.Cell(0, 4, fmt.Sprintf(t("Document ID: %s"), time.Now().Format("20060102-150405")))
 
 	// Save PDF
 	if err := pdf.OutputFileAndClose(emergencyOutput); err != nil {
@@ -292,6 +368,7 @@ func runEmergency(cmd *cobra.Command, args []string) {
 	}
 
 	logger.Success("✓ Emergency access kit generated: %s", emergencyOutput)
+	markChecklistItem(checklistEmergencyKit)
 	logger.Separator()
 	logger.Warning("⚠️  IMPORTANT:")
 	logger.Info("  - Store this document in a secure location")
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+const (
+	// healthWindow is how many recent operations of a destination its health score is based on
+	healthWindow = 20
+	// healthPeriod is how far back operations count towards health scores
+	healthPeriod = 30 * 24 * time.Hour
+	// maxLatencyPenalty caps the points slow destinations lose, so a slow but
+	// reliable destination still ranks above a flaky one
+	maxLatencyPenalty = 20
+)
+
+// destinationHealthScore rates a destination from 0 to 100: the percentage of its
+// recent operations that succeeded, less a point for every 10 seconds they took
+// on average. Destinations without history score 100.
+func destinationHealthScore(stats database.DestinationStats) int {
+	score := int(stats.SuccessRate()*100 + 0.5)
+	penalty := int(stats.AvgDuration / (10 * time.Second))
+	if penalty > maxLatencyPenalty {
+		penalty = maxLatencyPenalty
+	}
+	if score -= penalty; score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// destinationHealthScores returns the health score of every destination with
+// recent history, keyed by backend name
+func destinationHealthScores() map[string]int {
+	scores := make(map[string]int)
+	stats, err := database.ListDestinationStats(time.Now().Add(-healthPeriod), healthWindow)
+	if err != nil {
+		logger.Debug("Failed to read destination health: %v", err)
+		return scores
+	}
+	for name, s := range stats {
+		scores[name] = destinationHealthScore(s)
+	}
+	return scores
+}
+
+// healthScore returns a destination's score, 100 if it has no recent history
+func healthScore(scores map[string]int, name string) int {
+	if score, ok := scores[name]; ok {
+		return score
+	}
+	return 100
+}
+
+// orderByHealth sorts backends from the healthiest to the flakiest, keeping the
+// configured order between equally healthy ones. Flaky destinations are still
+// used, only later.
+func orderByHealth(backends []storage.Storage) []storage.Storage {
+	scores := destinationHealthScores()
+	if len(scores) == 0 {
+		return backends
+	}
+
+	ordered := append([]storage.Storage(nil), backends...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return healthScore(scores, ordered[i].Name()) > healthScore(scores, ordered[j].Name())
+	})
+
+	for i := range ordered {
+		if ordered[i].Name() != backends[i].Name() {
+			names := make([]string, len(ordered))
+			for j, backend := range ordered {
+				names[j] = fmt.Sprintf("%s (%d)", backend.Name(), healthScore(scores, backend.Name()))
+			}
+			logger.Info("Destinations ordered by health: %s", strings.Join(names, ", "))
+			break
+		}
+	}
+	return ordered
+}
+
+// recordDestinationOp records the outcome of an upload or download started at
+// start, for destination health scores. Failing to record is not fatal.
+func recordDestinationOp(backend storage.Storage, operation string, start time.Time, err error) {
+	if recordErr := database.RecordDestinationOp(backend.Name(), operation, err == nil, time.Since(start)); recordErr != nil {
+		logger.Debug("Failed to record %s on %s: %v", operation, backend.Name(), recordErr)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+)
+
+// fipsModuleWarning warns once that Go's FIPS module is off
+var fipsModuleWarning sync.Once
+
+// checkFIPSFile fails fast on an encrypted file that uses an algorithm
+// backup.encryption.fips doesn't allow, before any password is asked for
+func checkFIPSFile(cfg *config.Config, name string, data []byte) error {
+	if cfg == nil || !cfg.Backup.Encryption.FIPS {
+		return nil
+	}
+	warnFIPSModule()
+	if err := crypto.CheckFIPS(data); err != nil {
+		return fmt.Errorf("%s: %w (backup.encryption.fips is set)", name, err)
+	}
+	return nil
+}
+
+// checkFIPSMode refuses to encrypt with a mode or iteration count
+// backup.encryption.fips doesn't allow
+func checkFIPSMode(cfg *config.Config, mode string) error {
+	if !cfg.Backup.Encryption.FIPS {
+		return nil
+	}
+	warnFIPSModule()
+	switch mode {
+	case config.EncryptionModeAge, config.EncryptionModeGPG:
+		return fmt.Errorf("%s encryption is %w; backup.encryption.fips allows AES-256-GCM only", mode, crypto.ErrNotFIPSApproved)
+	case config.EncryptionModePassword:
+		if iterations := cfg.Backup.Encryption.KeyIterations(); iterations < crypto.FIPSMinIterations {
+			return fmt.Errorf("backup.encryption.fips requires at least %d PBKDF2 iterations, got %d", crypto.FIPSMinIterations, iterations)
+		}
+	}
+	return nil
+}
+
+// warnFIPSModule warns if the algorithms are restricted but don't run in Go's
+// FIPS 140-3 module
+func warnFIPSModule() {
+	if crypto.FIPSModule() {
+		return
+	}
+	fipsModuleWarning.Do(func() {
+		logger.Warning("⚠ backup.encryption.fips restricts algorithms, but Go's FIPS 140-3 module is off; run stashr with GODEBUG=fips140=on")
+	})
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/notify"
+)
+
+// nextHealthCheck is when the daemon next checks the password manager CLIs
+var nextHealthCheck time.Time
+
+// managerHealth is the outcome of a password manager health check
+type managerHealth string
+
+const (
+	managerHealthy      managerHealth = "healthy"
+	managerLocked       managerHealth = "locked"
+	managerUnresponsive managerHealth = "unresponsive"
+)
+
+// healthProblemKey records a manager's current problem and when it was first seen,
+// as "<health> <RFC3339 time>"
+func healthProblemKey(manager string) string {
+	return "health." + manager + ".problem"
+}
+
+// healthNotifiedKey records which problem of a manager a notification was sent for
+func healthNotifiedKey(manager string) string {
+	return "health." + manager + ".notified"
+}
+
+// healthCheckedManagers returns the enabled password managers keyed by their config name
+func healthCheckedManagers(cfg *config.Config) map[string]managers.Manager {
+	mgrs := make(map[string]managers.Manager)
+	if cfg.PasswordManagers.Bitwarden.Enabled {
+		mgrs["bitwarden"] = newBitwarden(cfg)
+	}
+	if cfg.PasswordManagers.OnePassword.Enabled {
+		mgrs["1password"] = newOnePassword(cfg)
+	}
+	return mgrs
+}
+
+// runHealthJob checks that the manager CLIs respond and their sessions are
+// unlocked, if the check interval has passed since the last check
+func runHealthJob(ctx context.Context, cfg *config.Config, now time.Time) {
+	if now.Before(nextHealthCheck) {
+		return
+	}
+	interval, err := cfg.Notifications.Health.CheckInterval()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	nextHealthCheck = now.Add(interval)
+
+	for name, mgr := range healthCheckedManagers(cfg) {
+		mgr.SetContext(ctx)
+		health, detail := checkManagerHealth(mgr)
+		if ctx.Err() != nil {
+			return
+		}
+		if err := recordManagerHealth(cfg, name, health, detail, interval, now); err != nil {
+			logger.Warning("Failed to record health of %s: %v", managerDisplayName(name), err)
+		}
+	}
+}
+
+// checkManagerHealth runs the manager's status command and describes any problem
+func checkManagerHealth(mgr managers.Manager) (managerHealth, string) {
+	_, err := mgr.IsAuthenticated()
+	if err == nil {
+		return managerHealthy, ""
+	}
+
+	var notAuthenticated *managers.ManagerNotAuthenticatedError
+	if errors.As(err, &notAuthenticated) {
+		return managerLocked, notAuthenticated.Message
+	}
+	return managerUnresponsive, err.Error()
+}
+
+// recordManagerHealth tracks how long a manager has had a problem and notifies
+// once it is worth acting on: a locked session after notifications.health.locked_days,
+// a CLI that fails two checks in a row, and a recovery after either was reported
+func recordManagerHealth(cfg *config.Config, name string, health managerHealth, detail string, interval time.Duration, now time.Time) error {
+	display := managerDisplayName(name)
+
+	notified, err := database.GetState(healthNotifiedKey(name))
+	if err != nil {
+		return err
+	}
+
+	if health == managerHealthy {
+		problem, err := database.GetState(healthProblemKey(name))
+		if err != nil {
+			return err
+		}
+		if problem == "" {
+			return nil
+		}
+		logger.Success("✓ %s is healthy again", display)
+		if notified != "" {
+			message := notify.Message{
+				Subject: fmt.Sprintf("stashr: %s is healthy again", display),
+				Body:    fmt.Sprintf("The %s CLI responds and its session is unlocked. Scheduled backups can run again.", display),
+			}
+			if err := sendNotification(cfg, message); err != nil {
+				logger.PrintError(err)
+			}
+		}
+		if err := database.SetState(healthNotifiedKey(name), ""); err != nil {
+			return err
+		}
+		return database.SetState(healthProblemKey(name), "")
+	}
+
+	since, err := healthProblemSince(name, health, now)
+	if err != nil {
+		return err
+	}
+	logger.Warning("⚠ %s is %s since %s: %s", display, health, since.Local().Format("2006-01-02 15:04"), detail)
+
+	var message notify.Message
+	switch health {
+	case managerLocked:
+		lockedFor := now.Sub(since)
+		if lockedFor < time.Duration(cfg.Notifications.Health.LockedDays)*24*time.Hour {
+			return nil
+		}
+		subject := fmt.Sprintf("stashr: %s session is locked", display)
+		if days := int(lockedFor / (24 * time.Hour)); days > 0 {
+			subject = fmt.Sprintf("stashr: %s session locked for %d days", display, days)
+		}
+		message = notify.Message{
+			Subject:  subject,
+			Severity: notify.SeverityWarning,
+			Body: fmt.Sprintf("The %s session has been locked or signed out since %s, so scheduled backups will fail until it is unlocked.\n\n%s",
+				display, since.Local().Format("2006-01-02 15:04"), detail),
+		}
+	default:
+		// Wait for a second failed check so a single slow command doesn't notify
+		if now.Sub(since) < interval {
+			return nil
+		}
+		message = notify.Message{
+			Subject:  fmt.Sprintf("stashr: %s CLI is not responding", display),
+			Severity: notify.SeverityWarning,
+			Body: fmt.Sprintf("The %s CLI has failed its health checks since %s, so scheduled backups will fail.\n\n%s",
+				display, since.Local().Format("2006-01-02 15:04"), detail),
+		}
+	}
+
+	if notified == string(health) {
+		return nil
+	}
+	if err := sendNotification(cfg, message); err != nil {
+		// Retried at the next check
+		logger.PrintError(err)
+		return nil
+	}
+	logger.Success("✓ Sent %s health notification", display)
+	return database.SetState(healthNotifiedKey(name), string(health))
+}
+
+// healthProblemSince returns when the current problem of a manager was first
+// seen, recording now if it is new
+func healthProblemSince(name string, health managerHealth, now time.Time) (time.Time, error) {
+	problem, err := database.GetState(healthProblemKey(name))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if kind, value, ok := strings.Cut(problem, " "); ok && kind == string(health) {
+		if since, err := time.Parse(time.RFC3339, value); err == nil {
+			return since, nil
+		}
+	}
+
+	if err := database.SetState(healthProblemKey(name), string(health)+" "+now.Format(time.RFC3339)); err != nil {
+		return time.Time{}, err
+	}
+	// A different problem is reported afresh
+	if err := database.SetState(healthNotifiedKey(name), ""); err != nil {
+		return time.Time{}, err
+	}
+	return now, nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/identity"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	identityName          string
+	identityNoKeychain    bool
+	identityForce         bool
+	identityWebhookSecret bool
+)
+
+// identityCmd represents the identity command
+var identityCmd = &cobra.Command{
+	Use:   "identity",
+	Short: "Manage this installation's backup identity key",
+	Long: `Manage the backup identity: an Ed25519 keypair that identifies this
+installation.
+
+The identity names the machine in multi-host setups and signs webhook payloads
+(HMAC-SHA256 in the X-Stashr-Signature header), so receivers can tell which host
+sent a notification and that it wasn't forged. Its private key is stored
+encrypted; the key that unwraps it is kept in the OS keychain.
+
+Subcommands:
+  create - Generate an identity for this installation
+  show   - Show the identity and its public key
+  delete - Delete the identity and its keychain entry`,
+}
+
+// identityCreateCmd represents the identity create command
+var identityCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Generate an identity for this installation",
+	Example: `  # Name the identity after the hostname
+  stashr identity create
+
+  # Choose the name shown to webhook receivers
+  stashr identity create --name backup-server`,
+	Run: runIdentityCreate,
+}
+
+// identityShowCmd represents the identity show command
+var identityShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the identity and its public key",
+	Run:   runIdentityShow,
+}
+
+// identityDeleteCmd represents the identity delete command
+var identityDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the identity and its keychain entry",
+	Run:   runIdentityDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(identityCmd)
+	identityCmd.AddCommand(identityCreateCmd)
+	identityCmd.AddCommand(identityShowCmd)
+	identityCmd.AddCommand(identityDeleteCmd)
+
+	identityCreateCmd.Flags().StringVar(&identityName, "name", "", "Name of the identity (default: hostname)")
+	identityCreateCmd.Flags().BoolVar(&identityNoKeychain, "no-keychain", false, "Store the wrapping key in a file instead of the OS keychain (protected by file permissions only)")
+	identityCreateCmd.Flags().BoolVarP(&identityForce, "force", "f", false, "Replace an existing identity")
+	identityShowCmd.Flags().BoolVar(&identityWebhookSecret, "webhook-secret", false, "Also print the secret webhook receivers verify signatures with")
+	identityDeleteCmd.Flags().BoolVarP(&identityForce, "force", "f", false, "Delete without confirmation")
+	identityDeleteCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deleted without deleting it")
+}
+
+func runIdentityCreate(cmd *cobra.Command, args []string) {
+	logger.Header("🪪 Create Backup Identity")
+
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	existing, err := identity.Load(dir)
+	if err == nil || !errors.Is(err, identity.ErrNoIdentity) {
+		name := "The existing identity"
+		if existing != nil {
+			name = "Identity " + existing.ID
+		}
+		if !identityForce && !utils.ConfirmPrompt(fmt.Sprintf("%s will be replaced and webhook receivers must be given the new secret. Continue?", name)) {
+			logger.Info("Cancelled")
+			return
+		}
+		if err := identity.Delete(dir); err != nil && !errors.Is(err, identity.ErrNoIdentity) {
+			logger.PrintError(err)
+			return
+		}
+	}
+
+	created, err := identity.Create(dir, identityName, !identityNoKeychain)
+	if err != nil {
+		logger.PrintError(err)
+		if !identityNoKeychain {
+			logger.Info("Use --no-keychain to store the key in a file instead")
+		}
+		return
+	}
+
+	logger.Success("✓ Created identity %s", created.ID)
+	if created.KeyStorage == identity.KeyStorageKeychain {
+		logger.Success("✓ Wrapping key stored in the OS keychain")
+	} else {
+		logger.Warning("⚠ Wrapping key stored in %s; the private key is protected by file permissions only", dir)
+	}
+	logger.Separator()
+	logger.Info("Webhook notifications are now signed. Give receivers the secret from:")
+	logger.Info("  stashr identity show --webhook-secret")
+}
+
+func runIdentityShow(cmd *cobra.Command, args []string) {
+	logger.Header("🪪 Backup Identity")
+
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	current, err := identity.Load(dir)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Info("ID:          %s", current.ID)
+	logger.Info("Hostname:    %s", current.Hostname)
+	logger.Info("Created:     %s", current.CreatedAt.Format("2006-01-02 15:04"))
+	logger.Info("Key storage: %s", current.KeyStorage)
+	logger.Info("Public key:  %s", base64.StdEncoding.EncodeToString(current.PublicKey))
+	logger.Info("Fingerprint: %s", current.Fingerprint())
+
+	if identityWebhookSecret {
+		logger.Separator()
+		logger.Warning("⚠ Anyone with this secret can forge notifications from this host")
+		fmt.Println(hex.EncodeToString(current.WebhookSecret()))
+	}
+}
+
+func runIdentityDelete(cmd *cobra.Command, args []string) {
+	logger.Header("🪪 Delete Backup Identity")
+
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		current, err := identity.Load(dir)
+		if err != nil && !errors.Is(err, identity.ErrNoIdentity) {
+			logger.PrintError(err)
+			return
+		}
+		printDryRunHeader()
+		var plan dryRunPlan
+		if current != nil {
+			if current.KeyStorage == identity.KeyStorageKeychain {
+				plan.Add("Delete the wrapping key of %s from the OS keychain", current.ID)
+			} else {
+				plan.Add("Delete %s", filepath.Join(dir, identity.WrapKeyFileName))
+			}
+			plan.Add("Delete %s", filepath.Join(dir, identity.FileName))
+		}
+		plan.Print()
+		return
+	}
+
+	if !identityForce && !utils.ConfirmPrompt("Delete this installation's identity? Webhook notifications will no longer be signed.") {
+		logger.Info("Cancelled")
+		return
+	}
+	if err := identity.Delete(dir); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Identity deleted")
+}
+
+// loadIdentity returns this installation's identity, or nil if none was created
+func loadIdentity() (*identity.Identity, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	current, err := identity.Load(dir)
+	if errors.Is(err, identity.ErrNoIdentity) {
+		return nil, nil
+	}
+	return current, err
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	infoFilename   string
+	infoStaleYears int
+)
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show what a backup contains",
+	Long: `Show recorded metadata for a backup without decrypting it.
+
+Displays the manager, storage, size, tags, and the vault statistics
+captured at backup time (items per category, logins, notes, cards), and how
+many logins haven't had their credentials changed in a given number of years.
+
+Examples:
+  # Show details for a backup
+  stashr info --file backup_bitwarden_20240101_120000.json.enc
+
+  # Highlight logins not rotated in over 3 years
+  stashr info --file backup_bitwarden_20240101_120000.json.enc --stale-years 3`,
+	Run: runInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringVarP(&infoFilename, "file", "f", "", "Backup filename (required)")
+	infoCmd.MarkFlagRequired("file")
+	infoCmd.Flags().IntVar(&infoStaleYears, "stale-years", 2, "Highlight logins whose credentials haven't changed in over this many years")
+}
+
+func runInfo(cmd *cobra.Command, args []string) {
+	logger.Header("ℹ️  Backup Info")
+
+	backup, err := database.GetBackup(infoFilename)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if backup == nil {
+		logger.Failure("Backup not found in database: %s", infoFilename)
+		return
+	}
+
+	logger.Info("Backup: %s", backup.Filename)
+	logger.Info("Manager: %s", backup.Manager)
+	logger.Info("Storage: %s", backup.StorageType)
+	logger.Info("Size: %s", utils.FormatBytes(backup.Size))
+	logger.Info("Created: %s", backup.CreatedAt.Format("2006-01-02 15:04:05"))
+	if copies, err := database.ListBackupCopies(backup.Filename); err == nil && len(copies) > 0 {
+		for _, c := range copies {
+			logger.Info("SHA-256 (%s): %s", c.StorageType, c.Checksum)
+		}
+	} else if backup.Checksum != nil {
+		logger.Info("SHA-256: %s", *backup.Checksum)
+	}
+	if backup.VerifiedAt != nil {
+		logger.Info("Verified: %s", backup.VerifiedAt.Format("2006-01-02 15:04:05"))
+	}
+	if len(backup.Tags) > 0 {
+		logger.Info("Tags: %s", formatTags(backup.Tags))
+	}
+	if len(backup.Labels) > 0 {
+		logger.Info("Classification: %s", formatTags(backup.Labels))
+	}
+	logger.Separator()
+
+	if backup.Stats == nil {
+		if backup.ItemCount != nil {
+			logger.Info("Items: %d", *backup.ItemCount)
+		} else {
+			logger.Info("No vault statistics recorded for this backup")
+		}
+		return
+	}
+
+	var stats managers.VaultStats
+	if err := json.Unmarshal([]byte(*backup.Stats), &stats); err != nil {
+		logger.Warning("Failed to parse recorded statistics: %v", err)
+		return
+	}
+
+	logger.Info("Contents:")
+	logger.Info("  Total items: %d", stats.TotalItems)
+	logger.Info("  Logins: %d", stats.Logins)
+	logger.Info("  Secure notes: %d", stats.Notes)
+	logger.Info("  Cards: %d", stats.Cards)
+	logger.Info("  Identities: %d", stats.Identities)
+	if stats.Other > 0 {
+		logger.Info("  Other: %d", stats.Other)
+	}
+
+	if len(stats.Categories) > 0 {
+		logger.Separator()
+		logger.Info("Items per category:")
+
+		categories := make([]string, 0, len(stats.Categories))
+		for category := range stats.Categories {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			fmt.Printf("  • %-20s %d\n", strings.ReplaceAll(category, "_", " "), stats.Categories[category])
+		}
+	}
+
+	printCredentialAge(stats, infoStaleYears)
+}
+
+// printCredentialAge reports how many logins in the backup were last changed more
+// than the given number of years ago
+func printCredentialAge(stats managers.VaultStats, years int) {
+	if len(stats.LoginsModified) == 0 {
+		return
+	}
+
+	stale, dated := stats.StaleLogins(time.Now().AddDate(-years, 0, 0))
+	logger.Separator()
+	logger.Info("Credential age:")
+	logger.Info("  Oldest login last changed: %s", stats.OldestLoginModified())
+	if stale > 0 {
+		logger.Warning("  ⚠ %d of %d logins not changed in over %d years", stale, dated, years)
+		logger.Info("  Consider rotating these credentials")
+	} else {
+		logger.Success("  ✓ Every login changed within the last %d years", years)
+	}
+}
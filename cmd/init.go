@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
@@ -105,9 +106,10 @@ func runInit(cmd *cobra.Command, args []string) {
 	if promptYesNo(reader, "Enable Google Drive storage?") {
 		cfg.Storage.GoogleDrive.Enabled = true
 
-		logger.Info("Google Drive requires OAuth2 credentials.")
+		logger.Info("Google Drive requires OAuth2 credentials or a service account key.")
 		logger.Info("You'll need to create a project and download credentials from:")
 		logger.Info("https://console.cloud.google.com/apis/credentials")
+		logger.Info("A service account key signs in without a browser (for headless servers).")
 
 		credsPath := promptInput(reader, "Path to Google Drive credentials JSON file")
 		if credsPath != "" {
@@ -120,15 +122,43 @@ func runInit(cmd *cobra.Command, args []string) {
 		if folderID != "" {
 			cfg.Storage.GoogleDrive.FolderID = folderID
 		}
+
+		logger.Info("To store backups in a Shared Drive, enter its ID (the last part of its URL).")
+		driveID := promptInput(reader, "Shared Drive ID (optional)")
+		if driveID != "" {
+			cfg.Storage.GoogleDrive.DriveID = driveID
+		}
+	}
+
+	// OneDrive
+	if promptYesNo(reader, "Enable OneDrive storage?") {
+		cfg.Storage.OneDrive.Enabled = true
+
+		logger.Info("OneDrive requires an Azure app registration with public client flows enabled.")
+		logger.Info("Register one and copy its Application (client) ID from:")
+		logger.Info("https://entra.microsoft.com/#view/Microsoft_AAD_RegisteredApps/ApplicationsListBlade")
+
+		cfg.Storage.OneDrive.ClientID = promptInput(reader, "OneDrive application (client) ID")
+
+		folder := promptInput(reader, "OneDrive backup folder (default: stashr)")
+		if folder != "" {
+			cfg.Storage.OneDrive.Folder = folder
+		}
+		logger.Info("You'll be asked to sign in with a device code on the first backup.")
 	}
 
 	// USB Storage
 	if promptYesNo(reader, "Enable USB storage?") {
 		cfg.Storage.USB.Enabled = true
 
-		mountPath := promptInput(reader, "USB mount path (e.g., /media/backup)")
-		if mountPath != "" {
-			cfg.Storage.USB.MountPath = mountPath
+		label := promptInput(reader, "USB volume label to find the drive by, wherever it is mounted (leave empty to use a fixed mount path)")
+		if label != "" {
+			cfg.Storage.USB.Label = label
+		} else {
+			mountPath := promptInput(reader, "USB mount path (e.g., /media/backup)")
+			if mountPath != "" {
+				cfg.Storage.USB.MountPath = mountPath
+			}
 		}
 
 		backupDir := promptInput(reader, "Backup directory name (default: stashr)")
@@ -168,7 +198,7 @@ func runInit(cmd *cobra.Command, args []string) {
 	}
 
 	if promptYesNo(reader, "Enable compression?") {
-		cfg.Backup.Compression = true
+		cfg.Backup.Compression = utils.CompressionGzip
 	}
 
 	retentionInput := promptInput(reader, "Number of backups to keep (default: 10)")
@@ -205,14 +235,13 @@ func runInit(cmd *cobra.Command, args []string) {
 }
 
 func promptYesNo(reader *bufio.Reader, prompt string) bool {
-	fmt.Printf("%s (y/n): ", prompt)
+	fmt.Printf("%s %s: ", i18n.T(prompt), i18n.T("(y/n)"))
 	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
-	return response == "y" || response == "yes"
+	return i18n.IsYes(response)
 }
 
 func promptInput(reader *bufio.Reader, prompt string) string {
-	fmt.Printf("%s: ", prompt)
+	fmt.Printf("%s: ", i18n.T(prompt))
 	input, _ := reader.ReadString('\n')
 	return strings.TrimSpace(input)
 }
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/keychain"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// keychainCmd represents the keychain command
+var keychainCmd = &cobra.Command{
+	Use:   "keychain",
+	Short: "Store the encryption password in the OS keychain",
+	Long: `Store the shared encryption password in the OS keychain (macOS Keychain,
+Windows Credential Manager or the Secret Service keyring on Linux), so scheduled
+backups can run unattended without keeping the password in a file or
+environment variable.
+
+The stored password is only used when backup.encryption.keychain is enabled.
+Backup and restore then read it instead of prompting; restore still asks if
+it doesn't decrypt the backup.
+
+Subcommands:
+  set    - Store the encryption password
+  status - Show whether a password is stored
+  delete - Delete the stored password`,
+}
+
+// keychainSetCmd represents the keychain set command
+var keychainSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Store the encryption password",
+	Run:   runKeychainSet,
+}
+
+// keychainStatusCmd represents the keychain status command
+var keychainStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether a password is stored",
+	Run:   runKeychainStatus,
+}
+
+// keychainDeleteCmd represents the keychain delete command
+var keychainDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the stored password",
+	Run:   runKeychainDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(keychainCmd)
+	keychainCmd.AddCommand(keychainSetCmd)
+	keychainCmd.AddCommand(keychainStatusCmd)
+	keychainCmd.AddCommand(keychainDeleteCmd)
+
+	keychainDeleteCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show whether a password would be deleted without deleting it")
+}
+
+func runKeychainSet(cmd *cobra.Command, args []string) {
+	logger.Header("🔐 Store Encryption Password")
+
+	if err := keychain.Available(); err != nil {
+		logger.Failure("OS keychain not available: %v", err)
+		return
+	}
+
+	password, err := utils.PromptForSecret("Enter encryption password: ")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer password.Destroy()
+	if password.Len() == 0 {
+		logger.Failure("Encryption password is required")
+		return
+	}
+	confirmPassword, err := utils.PromptForSecret("Confirm encryption password: ")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	matches := password.Equal(confirmPassword)
+	confirmPassword.Destroy()
+	if !matches {
+		logger.Failure("Passwords do not match!")
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := checkPasswordStrength(cfg, password); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// The OS keychain takes the password as a string
+	if err := keychain.Set(keychain.PasswordAccount, string(password.Bytes())); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	// Read it back: a locked or misconfigured keyring can accept a secret it won't return
+	stored, err := keychain.Get(keychain.PasswordAccount)
+	matches = err == nil && stored.Equal(password)
+	stored.Destroy()
+	if !matches {
+		logger.Failure("The OS keychain did not return the stored password; is the keyring unlocked?")
+		return
+	}
+	logger.Success("✓ Encryption password stored in the OS keychain")
+
+	if !cfg.Backup.Encryption.Keychain {
+		logger.Info("💡 Set backup.encryption.keychain: true in your configuration to use it")
+	}
+}
+
+func runKeychainStatus(cmd *cobra.Command, args []string) {
+	logger.Header("🔐 Keychain")
+
+	if err := keychain.Available(); err != nil {
+		logger.Failure("OS keychain not available: %v", err)
+		return
+	}
+	stored, err := keychain.Get(keychain.PasswordAccount)
+	stored.Destroy()
+	switch {
+	case errors.Is(err, keychain.ErrNotFound):
+		logger.Info("Encryption password: not stored")
+	case err != nil:
+		logger.PrintError(err)
+		return
+	default:
+		logger.Info("Encryption password: stored")
+	}
+
+	if cfg, err := config.Load(); err == nil {
+		logger.Info("Used by backups:     %v (backup.encryption.keychain)", cfg.Backup.Encryption.Keychain)
+	}
+}
+
+func runKeychainDelete(cmd *cobra.Command, args []string) {
+	logger.Header("🔐 Delete Encryption Password")
+
+	if err := keychain.Available(); err != nil {
+		logger.Failure("OS keychain not available: %v", err)
+		return
+	}
+	if dryRun {
+		stored, err := keychain.Get(keychain.PasswordAccount)
+		if err != nil && !errors.Is(err, keychain.ErrNotFound) {
+			logger.PrintError(err)
+			return
+		}
+		stored.Destroy()
+		printDryRunHeader()
+		var plan dryRunPlan
+		if err == nil {
+			plan.Add("Delete the encryption password from the OS keychain")
+		}
+		plan.Print()
+		return
+	}
+	if err := keychain.Delete(keychain.PasswordAccount); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Deleted the encryption password from the OS keychain")
+}
+
+// keychainPassword returns the encryption password stored in the OS keychain
+// when backup.encryption.keychain is enabled, or nil to prompt for it
+func keychainPassword(cfg *config.Config) (*secret.Buffer, error) {
+	if cfg == nil || !cfg.Backup.Encryption.Keychain {
+		return nil, nil
+	}
+	password, err := keychain.Get(keychain.PasswordAccount)
+	if errors.Is(err, keychain.ErrNotFound) {
+		return nil, errors.New("no encryption password in the OS keychain; store one with: stashr keychain set")
+	}
+	return password, err
+}
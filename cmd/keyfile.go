@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// defaultKeyFileName is the key file generate writes in the config directory
+const defaultKeyFileName = "backup.key"
+
+var keyfileProtect bool
+
+// keyfileCmd represents the keyfile command
+var keyfileCmd = &cobra.Command{
+	Use:   "keyfile",
+	Short: "Manage key files backups can be encrypted with",
+	Long: `Manage key files: random 256-bit keys that encrypt backups instead of a
+password, so backups can run without a prompt and restores need the file
+rather than something remembered.
+
+Pass a key file to backup and restore with --encryption-key, or set
+backup.encryption.key_file. A key file can itself be protected with a password,
+which is asked for whenever the key is used.
+
+Subcommands:
+  generate - Create a new key file
+  show     - Show a key file's ID and protection`,
+}
+
+// keyfileGenerateCmd represents the keyfile generate command
+var keyfileGenerateCmd = &cobra.Command{
+	Use:   "generate [path]",
+	Short: "Create a new key file",
+	Example: `  # Create ~/.stashr/backup.key
+  stashr keyfile generate
+
+  # Create a key file protected by a password
+  stashr keyfile generate /media/usb/stashr.key --protect`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runKeyfileGenerate,
+}
+
+// keyfileShowCmd represents the keyfile show command
+var keyfileShowCmd = &cobra.Command{
+	Use:   "show [path]",
+	Short: "Show a key file's ID and protection",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runKeyfileShow,
+}
+
+func init() {
+	rootCmd.AddCommand(keyfileCmd)
+	keyfileCmd.AddCommand(keyfileGenerateCmd)
+	keyfileCmd.AddCommand(keyfileShowCmd)
+
+	keyfileGenerateCmd.Flags().BoolVar(&keyfileProtect, "protect", false, "Protect the key file with a password")
+}
+
+func runKeyfileGenerate(cmd *cobra.Command, args []string) {
+	logger.Header("🔑 Generate Key File")
+
+	path, err := keyfileArgPath(args)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	// Replacing a key file would make every backup encrypted with it unrecoverable
+	if utils.FileExists(path) {
+		logger.Failure("%s already exists; backups encrypted with it can't be restored without it", path)
+		logger.Info("Move it somewhere safe first, or choose another path")
+		return
+	}
+
+	var password string
+	if keyfileProtect {
+		password, err = utils.PromptForPassword("Enter key file password: ")
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		if password == "" {
+			logger.Failure("Key file password is required")
+			return
+		}
+		confirmPassword, err := utils.PromptForPassword("Confirm key file password: ")
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		if password != confirmPassword {
+			logger.Failure("Passwords do not match!")
+			return
+		}
+		err = crypto.GetOrCreateEncryptionKey(path, password)
+	} else {
+		err = crypto.CreateKeyFile(path)
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	key, err := crypto.ReadKeyFile(path, func() (string, error) { return password, nil })
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Key file created: %s", path)
+	logger.Info("Key ID: %s", crypto.KeyFileID(key))
+	logger.Separator()
+	logger.Warning("⚠️  Backups encrypted with this key file can't be restored without it. Keep a copy apart from your backups.")
+	logger.Info("💡 Use it with: stashr backup --encryption-key %s", path)
+	logger.Info("   or set backup.encryption.key_file in your configuration")
+}
+
+func runKeyfileShow(cmd *cobra.Command, args []string) {
+	logger.Header("🔑 Key File")
+
+	path, err := keyfileArgPath(args)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	key, err := loadKeyFile(path)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Info("Path:      %s", path)
+	logger.Info("Key ID:    %s", crypto.KeyFileID(key))
+	logger.Info("Protected: %v", crypto.IsKeyFileProtected(data))
+}
+
+// keyfileArgPath returns the key file named on the command line, or the
+// configured one, or the default in the config directory
+func keyfileArgPath(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	if cfg, err := config.Load(); err == nil && cfg.Backup.Encryption.KeyFile != "" {
+		return cfg.Backup.Encryption.KeyFile, nil
+	}
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, defaultKeyFileName), nil
+}
+
+// resolveKeyFile returns the key file backups are encrypted with: the
+// --encryption-key flag, else backup.encryption.key_file, else none
+func resolveKeyFile(cfg *config.Config, flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return cfg.Backup.Encryption.KeyFile
+}
+
+// loadKeyFile reads a key file, prompting for its password if it is protected
+func loadKeyFile(path string) ([]byte, error) {
+	return crypto.ReadKeyFile(path, func() (string, error) {
+		return utils.PromptForPassword(fmt.Sprintf("Enter password for key file %s: ", path))
+	})
+}
+
+// decryptKeyFileBackup decrypts a backup encrypted with a key file, read from
+// the --encryption-key flag or backup.encryption.key_file
+func decryptKeyFileBackup(cfg *config.Config, flag string, data []byte) ([]byte, error) {
+	path := resolveKeyFile(cfg, flag)
+	if path == "" {
+		id, _ := crypto.BackupKeyID(data)
+		return nil, fmt.Errorf("backup was encrypted with key file %s; pass it with --encryption-key or set backup.encryption.key_file", id)
+	}
+	key, err := loadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Progress("Decrypting backup with key file %s...", path)
+	return crypto.DecryptWithKey(data, key)
+}
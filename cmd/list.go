@@ -10,6 +10,7 @@ import (
 
 	"github.com/harshalranjhani/stashr/internal/config"
 	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
 	"github.com/harshalranjhani/stashr/internal/storage"
 	"github.com/harshalranjhani/stashr/pkg/utils"
@@ -34,7 +35,7 @@ Shows backup files with their timestamp, size, and location.`,
 func init() {
 	rootCmd.AddCommand(listCmd)
 
-	listCmd.Flags().StringVarP(&listDestination, "destination", "d", "all", "Destination to list from (gdrive, usb, local, all)")
+	listCmd.Flags().StringVarP(&listDestination, "destination", "d", "all", "Destination to list from (gdrive, onedrive, webdav, gcs, azure, s3, rclone, icloud, usb, local, git-annex, a profile or plugin name, all)")
 	listCmd.Flags().StringSliceVarP(&listTags, "tag", "t", []string{}, "Filter by tags (can specify multiple)")
 	listCmd.Flags().BoolVar(&listShowTags, "show-tags", true, "Show tags in output (default: true)")
 }
@@ -110,10 +111,10 @@ func runList(cmd *cobra.Command, args []string) {
 
 		// Display backups in table format
 		if listShowTags {
-			fmt.Printf("%-45s %-20s %-12s %-15s %-20s\n", "Name", "Modified", "Size", "Age", "Tags")
+			fmt.Printf("%-45s %-20s %-12s %-15s %-20s\n", i18n.T("Name"), i18n.T("Modified"), i18n.T("Size"), i18n.T("Age"), i18n.T("Tags"))
 			fmt.Println(string(make([]rune, 112)))
 		} else {
-			fmt.Printf("%-50s %-20s %-12s %-20s\n", "Name", "Modified", "Size", "Age")
+			fmt.Printf("%-50s %-20s %-12s %-20s\n", i18n.T("Name"), i18n.T("Modified"), i18n.T("Size"), i18n.T("Age"))
 			fmt.Println(string(make([]rune, 102)))
 		}
 
@@ -172,81 +173,53 @@ func runList(cmd *cobra.Command, args []string) {
 }
 
 func getStorageBackendsForList(cfg *config.Config) []storage.Storage {
-	var backends []storage.Storage
-
-	if listDestination == "all" || listDestination == "gdrive" {
-		if cfg.Storage.GoogleDrive.Enabled {
-			backends = append(backends, storage.NewGoogleDrive(
-				cfg.Storage.GoogleDrive.CredentialsPath,
-				cfg.Storage.GoogleDrive.FolderID,
-			))
-		}
-	}
-
-	if listDestination == "all" || listDestination == "usb" {
-		if cfg.Storage.USB.Enabled {
-			backends = append(backends, storage.NewUSB(
-				cfg.Storage.USB.MountPath,
-				cfg.Storage.USB.BackupDir,
-			))
-		}
-	}
-
-	if listDestination == "all" || listDestination == "local" {
-		if cfg.Storage.Local.Enabled {
-			backends = append(backends, storage.NewLocal(
-				cfg.Storage.Local.BackupPath,
-			))
-		}
-	}
-
-	return backends
+	return selectStorageBackends(cfg, listDestination)
 }
 
 func formatAge(d time.Duration) string {
 	if d < time.Minute {
-		return "just now"
+		return i18n.T("just now")
 	}
 	if d < time.Hour {
 		minutes := int(d.Minutes())
 		if minutes == 1 {
-			return "1 minute ago"
+			return i18n.T("1 minute ago")
 		}
-		return fmt.Sprintf("%d minutes ago", minutes)
+		return i18n.Tf("%d minutes ago", minutes)
 	}
 	if d < 24*time.Hour {
 		hours := int(d.Hours())
 		if hours == 1 {
-			return "1 hour ago"
+			return i18n.T("1 hour ago")
 		}
-		return fmt.Sprintf("%d hours ago", hours)
+		return i18n.Tf("%d hours ago", hours)
 	}
 	days := int(d.Hours() / 24)
 	if days == 1 {
-		return "1 day ago"
+		return i18n.T("1 day ago")
 	}
 	if days < 7 {
-		return fmt.Sprintf("%d days ago", days)
+		return i18n.Tf("%d days ago", days)
 	}
 	weeks := days / 7
 	if weeks == 1 {
-		return "1 week ago"
+		return i18n.T("1 week ago")
 	}
 	if weeks < 4 {
-		return fmt.Sprintf("%d weeks ago", weeks)
+		return i18n.Tf("%d weeks ago", weeks)
 	}
 	months := days / 30
 	if months == 1 {
-		return "1 month ago"
+		return i18n.T("1 month ago")
 	}
 	if months < 12 {
-		return fmt.Sprintf("%d months ago", months)
+		return i18n.Tf("%d months ago", months)
 	}
 	years := days / 365
 	if years == 1 {
-		return "1 year ago"
+		return i18n.T("1 year ago")
 	}
-	return fmt.Sprintf("%d years ago", years)
+	return i18n.Tf("%d years ago", years)
 }
 
 func truncate(s string, maxLen int) string {
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/redact"
+)
+
+// configureLogging opens the log file and sets up redaction from the config, falling
+// back to the defaults when there is no usable config (e.g. before "stashr init")
+func configureLogging() {
+	settings := config.GetDefault().Logging
+	if cfg, err := config.Load(); err == nil {
+		settings = cfg.Logging
+	}
+
+	redactor, err := newRedactor(settings.Redaction)
+	if err != nil {
+		logger.Warning("⚠ %v", err)
+		// Never write unredacted logs because of a bad custom pattern
+		settings.Redaction.Patterns = nil
+		redactor, _ = newRedactor(settings.Redaction)
+	}
+	logger.SetRedaction(redactor.Redact)
+
+	path := settings.File
+	if logFile != "" {
+		path = logFile
+	}
+	if path != "" {
+		if err := logger.SetFileOutput(path); err != nil {
+			logger.Warning("⚠ %v", err)
+		}
+	}
+}
+
+// newRedactor creates the redactor for log files and notifications. It returns a
+// nil redactor, which redacts nothing, when redaction is disabled.
+func newRedactor(settings config.RedactionConfig) (*redact.Redactor, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	opts := redact.Options{
+		Emails:   settings.Emails,
+		Tokens:   settings.Tokens,
+		Paths:    settings.Paths,
+		Patterns: settings.Patterns,
+	}
+	if settings.Paths {
+		opts.HomeDir, _ = os.UserHomeDir()
+		if current, err := user.Current(); err == nil {
+			// Windows user names are "DOMAIN\name"
+			opts.Username = current.Username[strings.LastIndex(current.Username, `\`)+1:]
+		}
+	}
+
+	return redact.New(opts)
+}
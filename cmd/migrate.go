@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	migrateBackup string
+	migrateTo     string
+	migrateOutput string
+	migrateVault  string
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move a backup into another password manager",
+	Long: `Restore a backup into a different password manager in one step: the backup
+is decrypted, converted and imported into the target.
+
+Targets:
+  keepassxc  Writes a KeePass database to open or merge in KeePassXC
+  bitwarden  Imports the items into the unlocked Bitwarden vault (bw import)
+  1password  Creates the items in a 1Password vault (op item create)
+
+Before importing, a mapping report lists the categories and fields the target
+can't represent as they were, and how they are kept instead. Use --dry-run to
+see the report and the planned import without changing anything.
+
+Imports add items next to the ones already in the vault; run a migration once.`,
+	Example: `  stashr migrate --from-backup 1password_backup_2025-01-15_10-30-00.json.gz.enc --to bitwarden
+  stashr migrate --from-backup ./bitwarden_backup.json.enc --to keepassxc --output vault.kdbx
+  stashr migrate --from-backup bitwarden_backup_2025-01-15_10-30-00.json.enc --to 1password --vault Imported --dry-run`,
+	Run: runMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&migrateBackup, "from-backup", "f", "", "Backup file path or backup name to migrate")
+	migrateCmd.Flags().StringVarP(&migrateTo, "to", "t", "", "Target password manager ("+strings.Join(convert.MigrationTargets, ", ")+")")
+	migrateCmd.Flags().StringVarP(&migrateOutput, "output", "o", "", "KeePass database path for --to keepassxc (default: current directory)")
+	migrateCmd.Flags().StringVarP(&inputKeyFile, "encryption-key", "k", "", "Key file the backup was encrypted with (default: backup.encryption.key_file)")
+	migrateCmd.Flags().StringVar(&migrateVault, "vault", "", "1Password vault to create the items in (default: the account's default vault)")
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the mapping report and planned import without importing")
+	migrateCmd.MarkFlagRequired("from-backup")
+	migrateCmd.MarkFlagRequired("to")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) {
+	logger.Header("🚚 Migrate Backup")
+
+	// Validate the target before touching any backup
+	if !isMigrationTarget(migrateTo) {
+		logger.Failure("Unknown target: %s (use: %s)", migrateTo, strings.Join(convert.MigrationTargets, ", "))
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		printDryRunHeader()
+	}
+
+	data, password, err := readBackupInput(migrateBackup)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer password.Destroy()
+	defer secret.Wipe(data)
+	entries, err := convert.Parse(data)
+	if err != nil {
+		logger.PrintError(fmt.Errorf("failed to parse vault data: %w", err))
+		return
+	}
+	logger.Success("✓ Read %d items", len(entries))
+
+	printMappingReport(entries, migrateTo)
+
+	outputPath := migrateOutput
+	if outputPath == "" {
+		outputPath = filepath.Join(".", trimBackupExtension(filepath.Base(migrateBackup))+convert.Extension("kdbx"))
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		switch migrateTo {
+		case "keepassxc":
+			plan.Add("Write a KeePass database with %d items to %s", len(entries), outputPath)
+		case "bitwarden":
+			plan.Add("Import %d items into Bitwarden", len(entries))
+		case "1password":
+			plan.Add("Create %d items in 1Password vault %s", len(entries), migrationVaultName())
+		}
+		plan.Print()
+		return
+	}
+
+	switch migrateTo {
+	case "keepassxc":
+		err = migrateToKeePassXC(data, password, outputPath)
+	case "bitwarden":
+		if !utils.ConfirmPrompt(fmt.Sprintf("Import %d items into Bitwarden?", len(entries))) {
+			logger.Info("Migration cancelled")
+			return
+		}
+		err = migrateToBitwarden(cfg, entries)
+	case "1password":
+		if !utils.ConfirmPrompt(fmt.Sprintf("Create %d items in 1Password vault %s?", len(entries), migrationVaultName())) {
+			logger.Info("Migration cancelled")
+			return
+		}
+		err = migrateToOnePassword(cfg, entries)
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Separator()
+	logger.Success("✅ Migration complete!")
+}
+
+// isMigrationTarget reports whether target is a supported migrate target
+func isMigrationTarget(target string) bool {
+	for _, t := range convert.MigrationTargets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// migrationVaultName describes the 1Password vault items are created in
+func migrationVaultName() string {
+	if migrateVault == "" {
+		return "(default)"
+	}
+	return migrateVault
+}
+
+// printMappingReport lists what the target can't represent as it was in the backup
+func printMappingReport(entries []convert.Entry, target string) {
+	logger.Separator()
+	logger.Info("Mapping report:")
+	losses := convert.Losses(entries, target)
+	if len(losses) == 0 {
+		logger.Success("✓ Every item translates as it is")
+	}
+	for _, loss := range losses {
+		logger.Warning("⚠ %s (%d items): %s", loss.What, loss.Items, loss.Instead)
+	}
+	logger.Separator()
+}
+
+// migrateToKeePassXC writes the backup as a KeePass database
+func migrateToKeePassXC(data []byte, password *secret.Buffer, outputPath string) error {
+	output, err := convertToKDBX(data, password)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, output, 0600); err != nil {
+		return err
+	}
+	logger.Success("✓ KeePass database written to: %s", outputPath)
+	logger.Info("Open it in KeePassXC, or merge it into an existing database with Database → Merge From Database")
+	return nil
+}
+
+// migrateToBitwarden imports entries into the Bitwarden vault through a temporary
+// Bitwarden JSON export, which is deleted afterwards
+func migrateToBitwarden(cfg *config.Config, entries []convert.Entry) error {
+	bw := newBitwarden(cfg)
+	if err := checkMigrationTarget(bw); err != nil {
+		return err
+	}
+	before, _ := bw.GetItemCount()
+
+	var buf bytes.Buffer
+	if err := convert.WriteBitwardenJSON(&buf, entries); err != nil {
+		return err
+	}
+	defer secret.Wipe(buf.Bytes())
+
+	return importBitwardenJSON(cfg, bw, buf.Bytes(), len(entries), before)
+}
+
+// importBitwardenJSON imports a Bitwarden JSON export of count items and reports
+// the vault's new size against its item count before the import. The export is
+// piped to bw where the platform allows, and otherwise goes through a temporary
+// file, which is deleted afterwards.
+func importBitwardenJSON(cfg *config.Config, bw *managers.Bitwarden, data []byte, count, before int) error {
+	logger.Progress("Importing %d items into Bitwarden...", count)
+	if bw.CanImportData() {
+		if err := bw.ImportData(data); err != nil {
+			return err
+		}
+	} else if err := importBitwardenFile(cfg, bw, data); err != nil {
+		return err
+	}
+	logger.Success("✓ Imported %d items", count)
+
+	if after, _ := bw.GetItemCount(); after > 0 {
+		logger.Info("  Vault now has %d items (%+d)", after, after-before)
+	}
+	return nil
+}
+
+// importBitwardenFile imports a Bitwarden JSON export through a temporary file
+func importBitwardenFile(cfg *config.Config, bw *managers.Bitwarden, data []byte) error {
+	tmpFile, err := utils.GetTempFile(backupTempDir(cfg), "stashr-import-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer utils.CleanupTempFile(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return bw.Import(tmpFile.Name())
+}
+
+// migrateToOnePassword creates one 1Password item per entry, stopping at the
+// first failure and reporting how many were created
+func migrateToOnePassword(cfg *config.Config, entries []convert.Entry) error {
+	op := newOnePassword(cfg)
+	if err := checkMigrationTarget(op); err != nil {
+		return err
+	}
+	templates, err := convert.OnePasswordTemplates(entries)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	op.SetContext(ctx)
+
+	logger.Progress("Creating %d items in 1Password...", len(templates))
+	for i, template := range templates {
+		if err := op.CreateItem(template, migrateVault); err != nil {
+			logger.Warning("⚠ Created %d of %d items; stopped at %q", i, len(templates), entries[i].Title)
+			return err
+		}
+	}
+	logger.Success("✓ Created %d items", len(templates))
+	return nil
+}
+
+// checkMigrationTarget ensures the target manager's CLI is installed and signed in
+func checkMigrationTarget(mgr managers.Manager) error {
+	if !mgr.IsInstalled() {
+		return fmt.Errorf("%s CLI is not installed", mgr.Name())
+	}
+	authenticated, err := mgr.IsAuthenticated()
+	if err != nil {
+		return fmt.Errorf("authentication check failed: %w", err)
+	}
+	if !authenticated {
+		return fmt.Errorf("%s is not authenticated. Please login first", mgr.Name())
+	}
+	logger.Success("✓ %s is ready", managerDisplayName(mgr.Name()))
+	return nil
+}
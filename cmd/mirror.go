@@ -0,0 +1,501 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	mirrorChecksum bool
+	mirrorFix      bool
+	mirrorYes      bool
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Check that your destinations mirror each other",
+	Long: `Check that the enabled destinations hold the same backups.
+
+Subcommands:
+  verify - Compare the backups of every destination and report the gaps`,
+}
+
+// mirrorVerifyCmd represents the mirror verify command
+var mirrorVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Compare the backups of every destination and report the gaps",
+	Long: `Compare the backup sets of all enabled destinations by name and size, and
+report the backups some destinations are missing and the copies that differ.
+
+A backup a destination's retention policy wouldn't keep, or that its destination
+policies forbid, isn't counted as missing there. With --checksum, copies are
+also compared by MD5, from the provider's checksum where the destination records
+one and by downloading the copy otherwise.
+
+With --fix, missing backups are copied from a destination that has them, and
+copies that differ from the majority are replaced with the majority's copy after
+confirmation (or --yes). When no majority agrees, e.g. between two destinations,
+the copy matching the checksum recorded at backup wins; if none does, the copies
+are left alone.`,
+	Example: `  stashr mirror verify
+  stashr mirror verify --checksum
+  stashr mirror verify --fix --dry-run
+  stashr mirror verify --fix --yes`,
+	Run: runMirrorVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.AddCommand(mirrorVerifyCmd)
+
+	mirrorVerifyCmd.Flags().BoolVar(&mirrorChecksum, "checksum", false, "Also compare the copies by MD5 checksum")
+	mirrorVerifyCmd.Flags().BoolVar(&mirrorFix, "fix", false, "Copy missing backups and replace copies that differ")
+	mirrorVerifyCmd.Flags().BoolVarP(&mirrorYes, "yes", "y", false, "Replace differing copies without confirmation")
+	mirrorVerifyCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip checking each copy against the source")
+	mirrorVerifyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what --fix would copy and replace without changing anything")
+}
+
+// mirrorCopy is one destination's copy of a backup
+type mirrorCopy struct {
+	backend storage.Storage
+	file    storage.BackupFile
+	// checksum is the copy's MD5, with --checksum
+	checksum string
+}
+
+// key identifies the content of a copy: its checksum if known, else its size
+func (c mirrorCopy) key() string {
+	if c.checksum != "" {
+		return c.checksum
+	}
+	return fmt.Sprint(c.file.Size)
+}
+
+// mirrorBackup is a backup and its copies on the destinations that have it
+type mirrorBackup struct {
+	name   string
+	copies []mirrorCopy
+	// missing are the destinations that should have the backup but don't
+	missing []storage.Storage
+	// reference is the copy the others should match: one more than half of the
+	// copies match, or else the one matching the checksum recorded at backup;
+	// nil if neither tells
+	reference *mirrorCopy
+	// differing are the copies that don't match the reference, or all copies
+	// if there is no reference but they differ
+	differing []mirrorCopy
+}
+
+func runMirrorVerify(cmd *cobra.Command, args []string) {
+	logger.Header("🪞 Mirror Verification")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := validatePolicies(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		printDryRunHeader()
+	}
+	// A cached download would compare the local copy, not the stored one
+	noCache = true
+
+	var backends []storage.Storage
+	listings := make(map[storage.Storage][]storage.BackupFile)
+	for _, backend := range selectStorageBackends(cfg, "all") {
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			logger.Warning("⚠ %s: not available, skipped", backend.Name())
+			continue
+		}
+		backups, err := backend.List()
+		if err != nil {
+			logger.Warning("⚠ %s: failed to list backups: %v", backend.Name(), err)
+			continue
+		}
+		logger.Info("📁 %s: %d backups", backend.Name(), len(backups))
+		backends = append(backends, backend)
+		listings[backend] = backups
+	}
+	if len(backends) < 2 {
+		logger.Failure("Mirror verification needs at least two available destinations")
+		return
+	}
+
+	backups := compareMirrors(cfg, backends, listings, time.Now())
+	if mirrorChecksum {
+		logger.Progress("Comparing checksums...")
+		checksumMirrors(backups)
+	}
+	for _, backup := range backups {
+		backup.findReference()
+	}
+	logger.Separator()
+
+	gaps, differing := reportMirrorGaps(backends, backups)
+	if gaps == 0 && differing == 0 {
+		logger.Success("✅ All %d destinations mirror each other (%d backups)", len(backends), len(backups))
+		return
+	}
+	if !mirrorFix {
+		logger.Warning("⚠ %d missing copies, %d backups whose copies differ", gaps, differing)
+		logger.Info("Run with --fix to copy the missing backups and replace the differing copies")
+		return
+	}
+
+	fixMirrors(cfg, backups)
+}
+
+// compareMirrors gathers the copies of every backup on the destinations and
+// the destinations missing it, oldest backup first
+func compareMirrors(cfg *config.Config, backends []storage.Storage, listings map[storage.Storage][]storage.BackupFile, now time.Time) []*mirrorBackup {
+	byName := make(map[string]*mirrorBackup)
+	var backups []*mirrorBackup
+	for _, backend := range backends {
+		for _, file := range listings[backend] {
+			backup, ok := byName[file.Name]
+			if !ok {
+				backup = &mirrorBackup{name: file.Name}
+				byName[file.Name] = backup
+				backups = append(backups, backup)
+			}
+			backup.copies = append(backup.copies, mirrorCopy{backend: backend, file: file})
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].copies[0].file.ModifiedTime.Before(backups[j].copies[0].file.ModifiedTime)
+	})
+
+	// Every backup, as its first copy, to decide what each retention policy keeps
+	all := make([]storage.BackupFile, len(backups))
+	for i, backup := range backups {
+		all[i] = backup.copies[0].file
+	}
+	managerOf := backupManagerOf(cfg)
+	for _, backend := range backends {
+		kept := make(map[string]bool)
+		for _, decision := range storage.EvaluateRetention(all, destinationRetentionPolicy(cfg, backend), managerOf, now) {
+			// Files retention skips aren't known to be backups, so they aren't copied
+			kept[decision.Backup.Name] = decision.Keep && !decision.Skipped
+		}
+		for _, backup := range backups {
+			if backup.copyOn(backend) != nil || !kept[backup.name] {
+				continue
+			}
+			if err := checkPolicies(cfg, managerOf(backup.name), backend); err != nil {
+				continue
+			}
+			backup.missing = append(backup.missing, backend)
+		}
+	}
+	return backups
+}
+
+// copyOn returns the backup's copy on a destination, nil if it has none
+func (b *mirrorBackup) copyOn(backend storage.Storage) *mirrorCopy {
+	for i := range b.copies {
+		if b.copies[i].backend == backend {
+			return &b.copies[i]
+		}
+	}
+	return nil
+}
+
+// findReference sets the copy the others should match and the copies that differ
+func (b *mirrorBackup) findReference() {
+	counts := make(map[string]int)
+	for _, c := range b.copies {
+		counts[c.key()]++
+	}
+	if len(counts) == 1 {
+		b.reference = &b.copies[0]
+		return
+	}
+	for i, c := range b.copies {
+		if counts[c.key()]*2 > len(b.copies) {
+			b.reference = &b.copies[i]
+			break
+		}
+	}
+	if b.reference == nil {
+		b.reference = b.recordedCopy()
+	}
+	for _, c := range b.copies {
+		if b.reference == nil || c.key() != b.reference.key() {
+			b.differing = append(b.differing, c)
+		}
+	}
+}
+
+// recordedCopy returns the copy matching the SHA-256 the metadata database
+// recorded for its destination when the backup was made, nil if none does or
+// none was recorded
+func (b *mirrorBackup) recordedCopy() *mirrorCopy {
+	record, _ := database.GetBackup(b.name)
+	for i, c := range b.copies {
+		recorded := recordedChecksum(b.name, c.backend.Name(), record)
+		if recorded == "" {
+			continue
+		}
+		data, err := c.backend.Download(b.name)
+		if err == nil && utils.SHA256Hex(data) == recorded {
+			return &b.copies[i]
+		}
+	}
+	return nil
+}
+
+// recordedChecksum returns the SHA-256 recorded for a destination's copy of a
+// backup, "" if none was. Backups made before checksums were recorded per
+// destination fall back to the single checksum of their record.
+func recordedChecksum(filename, storageType string, record *database.BackupRecord) string {
+	copies, err := database.ListBackupCopies(filename)
+	if err != nil {
+		logger.Debug("Failed to read the copies of %s: %v", filename, err)
+	}
+	for _, c := range copies {
+		if c.StorageType == storageType {
+			return c.Checksum
+		}
+	}
+	// With other destinations' copies recorded, this one wasn't uploaded by a backup
+	if len(copies) == 0 && record != nil && record.Checksum != nil {
+		return *record.Checksum
+	}
+	return ""
+}
+
+// recordedChecksums returns the SHA-256 checksums recorded for any copy of a backup
+func recordedChecksums(filename string) []string {
+	var checksums []string
+	if copies, err := database.ListBackupCopies(filename); err == nil {
+		for _, c := range copies {
+			checksums = append(checksums, c.Checksum)
+		}
+	}
+	if len(checksums) == 0 {
+		if record, err := database.GetBackup(filename); err == nil && record != nil && record.Checksum != nil {
+			checksums = append(checksums, *record.Checksum)
+		}
+	}
+	return checksums
+}
+
+// checksumMirrors records the MD5 of every copy of the backups stored more than once
+func checksumMirrors(backups []*mirrorBackup) {
+	for _, backup := range backups {
+		if len(backup.copies) < 2 {
+			continue
+		}
+		checksums := make([]string, len(backup.copies))
+		complete := true
+		for i, c := range backup.copies {
+			checksum, err := copyChecksum(c.backend, backup.name)
+			if err != nil {
+				logger.Warning("⚠ %s on %s: %v", backup.name, c.backend.Name(), err)
+				complete = false
+				break
+			}
+			checksums[i] = checksum
+		}
+		// Without every checksum, the copies are compared by size
+		if !complete {
+			continue
+		}
+		for i := range backup.copies {
+			backup.copies[i].checksum = checksums[i]
+		}
+	}
+}
+
+// copyChecksum returns the MD5 of a stored copy, from the provider's checksum
+// when the destination records one and by downloading the copy otherwise
+func copyChecksum(backend storage.Storage, filename string) (string, error) {
+	if provider, ok := backend.(storage.ChecksumProvider); ok {
+		algorithm, checksum, err := provider.StoredChecksum(filename)
+		switch {
+		case err == nil && algorithm == "md5":
+			return strings.ToLower(checksum), nil
+		case err != nil && !errors.Is(err, storage.ErrChecksumUnavailable):
+			return "", fmt.Errorf("failed to get stored checksum: %w", err)
+		}
+	}
+	data, err := backend.Download(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to download copy: %w", err)
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// reportMirrorGaps prints the missing and differing copies and returns how
+// many copies are missing and how many backups have differing copies
+func reportMirrorGaps(backends []storage.Storage, backups []*mirrorBackup) (int, int) {
+	gaps := 0
+	for _, target := range backends {
+		// Missing backups are grouped by the destinations that have them
+		var groups []string
+		byHolders := make(map[string][]string)
+		for _, backup := range backups {
+			if !backup.isMissingFrom(target) {
+				continue
+			}
+			var holders []string
+			for _, c := range backup.copies {
+				holders = append(holders, c.backend.Name())
+			}
+			key := strings.Join(holders, ", ")
+			if _, ok := byHolders[key]; !ok {
+				groups = append(groups, key)
+			}
+			byHolders[key] = append(byHolders[key], backup.name)
+		}
+		for _, holders := range groups {
+			names := byHolders[holders]
+			gaps += len(names)
+			logger.Warning("⚠ %d backups exist on %s but are missing from %s", len(names), holders, target.Name())
+			for _, name := range names {
+				logger.Info("  • %s", name)
+			}
+		}
+	}
+
+	differing := 0
+	for _, backup := range backups {
+		if len(backup.differing) == 0 {
+			continue
+		}
+		differing++
+		logger.Warning("⚠ The copies of %s differ:", backup.name)
+		for _, c := range backup.copies {
+			detail := utils.FormatBytes(c.file.Size)
+			if c.checksum != "" {
+				detail += ", MD5 " + c.checksum
+			}
+			marker := "✓"
+			if backup.reference == nil || c.key() != backup.reference.key() {
+				marker = "✗"
+			}
+			logger.Info("  %s %s: %s", marker, c.backend.Name(), detail)
+		}
+		if backup.reference == nil {
+			logger.Info("  Neither a majority of copies nor the recorded checksum tells which is right; compare them by hand")
+		}
+	}
+	return gaps, differing
+}
+
+// isMissingFrom reports whether a destination should have the backup but doesn't
+func (b *mirrorBackup) isMissingFrom(backend storage.Storage) bool {
+	for _, missing := range b.missing {
+		if missing == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// fixMirrors copies the missing backups from the destination of their reference
+// copy and replaces the copies that differ from it
+func fixMirrors(cfg *config.Config, backups []*mirrorBackup) {
+	type mirrorFixOp struct {
+		backup  *mirrorBackup
+		target  storage.Storage
+		replace bool
+	}
+	var ops []mirrorFixOp
+	replacements := 0
+	for _, backup := range backups {
+		if backup.reference == nil {
+			if len(backup.missing) > 0 {
+				logger.Warning("⚠ %s: not copied, its copies differ and none is known to be right", backup.name)
+			}
+			continue
+		}
+		for _, target := range backup.missing {
+			ops = append(ops, mirrorFixOp{backup: backup, target: target})
+		}
+		for _, c := range backup.differing {
+			ops = append(ops, mirrorFixOp{backup: backup, target: c.backend, replace: true})
+			replacements++
+		}
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		for _, op := range ops {
+			source := op.backup.reference
+			if op.replace {
+				plan.Add("Replace %s on %s with the copy on %s", op.backup.name, op.target.Name(), source.backend.Name())
+			} else {
+				plan.Add("Copy %s (%s) from %s to %s", op.backup.name, utils.FormatBytes(source.file.Size), source.backend.Name(), op.target.Name())
+			}
+		}
+		plan.Print()
+		return
+	}
+	if len(ops) == 0 {
+		logger.Info("Nothing can be fixed automatically")
+		return
+	}
+
+	logger.Separator()
+	replace := replacements == 0 || mirrorYes ||
+		utils.ConfirmPrompt(fmt.Sprintf("Replace %d copies that differ from the right one?", replacements))
+	if !replace {
+		logger.Info("Kept the differing copies")
+	}
+
+	copied, replaced, failed := 0, 0, 0
+	for _, op := range ops {
+		if op.replace && !replace {
+			continue
+		}
+		source := op.backup.reference
+		if op.replace {
+			if err := op.target.Delete(op.backup.name); err != nil {
+				logger.Failure("✗ Failed to delete the differing %s from %s: %v", op.backup.name, op.target.Name(), err)
+				failed++
+				continue
+			}
+		}
+		err := copyBackup(cfg, source.backend, op.target, source.file)
+		switch {
+		case errors.Is(err, errSyncSkipped):
+			logger.Warning("⚠ %s: %v", op.backup.name, err)
+		case err != nil:
+			logger.Failure("✗ %s: %v", op.backup.name, err)
+			failed++
+		case op.replace:
+			replaced++
+		default:
+			copied++
+		}
+	}
+
+	logger.Separator()
+	if failed > 0 {
+		logger.Warning("⚠ Copied %d and replaced %d backups; %d failed", copied, replaced, failed)
+		return
+	}
+	logger.Success("✅ Copied %d and replaced %d backups", copied, replaced)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// passwordEnvVar holds the encryption password for backups that can't prompt for it
+const passwordEnvVar = "STASHR_ENCRYPTION_PASSWORD"
+
+// exitInputRequired is the exit code of a non-interactive backup that needed input
+const exitInputRequired = 3
+
+// Inputs a non-interactive backup can't prompt for, as reported in inputRequiredError
+const (
+	inputEncryptionPassword  = "encryption_password"
+	inputDestinationPassword = "destination_password"
+	inputKeyFilePassword     = "key_file_password"
+	inputInteractive         = "interactive"
+)
+
+// inputRequiredError is written to stderr as one JSON line when a
+// non-interactive backup needs input, for schedulers and scripts to act on
+type inputRequiredError struct {
+	Error   string `json:"error"`
+	Input   string `json:"input"`
+	Message string `json:"message"`
+}
+
+// failInputRequired reports that a non-interactive backup needs input and exits
+// with exitInputRequired
+func failInputRequired(input, message string) {
+	logger.Failure("%s", message)
+	line, _ := json.Marshal(inputRequiredError{Error: "input_required", Input: input, Message: message})
+	fmt.Fprintln(os.Stderr, string(line))
+	// os.Exit skips the deferred send and lock release
+	currentBackupReport.send()
+	releaseRunLock()
+	os.Exit(exitInputRequired)
+}
+
+// environmentPassword returns the encryption password from STASHR_ENCRYPTION_PASSWORD,
+// or nil if it isn't set
+func environmentPassword() *secret.Buffer {
+	value := os.Getenv(passwordEnvVar)
+	if value == "" {
+		return nil
+	}
+	return secret.FromString(value)
+}
+
+// requireUnprotectedKeyFile fails a non-interactive backup whose key file would
+// prompt for its password
+func requireUnprotectedKeyFile(path string) {
+	if keyFileProtected(path) {
+		failInputRequired(inputKeyFilePassword, fmt.Sprintf("Key file %s is password-protected and its password can't be prompted for: use an unprotected key file on an encrypted disk, or the OS keychain", path))
+	}
+}
+
+// keyFileProtected reports whether reading a key file prompts for its password.
+// Unreadable key files are left to loadKeyFile to report.
+func keyFileProtected(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	defer secret.Wipe(data)
+	return crypto.IsKeyFileProtected(data)
+}
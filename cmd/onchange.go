@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+)
+
+const (
+	// changeRetryDelay is how long to wait before retrying a change backup that failed
+	changeRetryDelay = time.Hour
+	// changeBackupStopDelay is how long an interrupted change backup may take to clean up
+	changeBackupStopDelay = 30 * time.Second
+)
+
+var (
+	// nextChangeCheck is when the vaults are next checked for changes
+	nextChangeCheck time.Time
+	// nextChangeBackup delays retries after a manager's change backup failed
+	nextChangeBackup = make(map[string]time.Time)
+	// changePostponed is why each manager's change backup is postponed, so a
+	// postponement is recorded once rather than at every check
+	changePostponed = make(map[string]string)
+)
+
+// changeRevisionKey records the vault revision a manager was last backed up at
+func changeRevisionKey(manager string) string {
+	return "changes." + manager + ".revision"
+}
+
+// runChangeJob backs up the managers whose vault changed since the daemon last
+// backed them up, if the check interval has passed since the last check
+func runChangeJob(ctx context.Context, cfg *config.Config, now time.Time) {
+	if now.Before(nextChangeCheck) {
+		return
+	}
+	interval, err := cfg.Backup.OnChange.CheckInterval()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	nextChangeCheck = now.Add(interval)
+
+	// backup.conditions are checked once per check, and only if a vault changed
+	conditionsChecked := false
+	postponed := ""
+
+	mgrs := healthCheckedManagers(cfg)
+	for _, name := range enabledManagerNames(cfg) {
+		mgr := mgrs[name]
+		detector, ok := mgr.(managers.ChangeDetector)
+		if !ok {
+			continue
+		}
+		display := managerDisplayName(name)
+
+		mgr.SetContext(ctx)
+		revision, err := detector.Revision()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warning("⚠ Can't check %s for changes: %v", display, err)
+			continue
+		}
+
+		last, err := database.GetState(changeRevisionKey(name))
+		if err != nil {
+			logger.Warning("Failed to read change state of %s: %v", display, err)
+			continue
+		}
+		if revision == last || now.Before(nextChangeBackup[name]) {
+			continue
+		}
+
+		if !conditionsChecked && cfg.Backup.Conditions.Enabled() {
+			postponed, conditionsChecked = unmetCondition(cfg), true
+		}
+		if postponed != "" {
+			if changePostponed[name] != postponed {
+				logger.Warning("⏸ Postponing the backup of %s: %s", display, postponed)
+				recordPostponement(name, postponed)
+				changePostponed[name] = postponed
+			}
+			continue
+		}
+		delete(changePostponed, name)
+
+		if last == "" {
+			logger.Progress("No backup of %s is recorded for change detection yet: backing up...", display)
+		} else {
+			logger.Progress("%s changed since its last backup: backing up...", display)
+		}
+		if err := runChangeBackup(ctx, name); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.PrintError(err)
+			nextChangeBackup[name] = now.Add(changeRetryDelay)
+			logger.Info("Retrying at %s if it is still changed", nextChangeBackup[name].Format("15:04"))
+			continue
+		}
+
+		// A change made during the backup has a newer revision, so it is backed up next time
+		if err := database.SetState(changeRevisionKey(name), revision); err != nil {
+			logger.Warning("Failed to record change state of %s: %v", display, err)
+		}
+		delete(nextChangeBackup, name)
+		logger.Success("✓ Backed up %s at its current revision", display)
+	}
+}
+
+// runChangeBackup backs up a manager in a separate "stashr backup --non-interactive"
+// process, which reports its results and notifications like a scheduled backup
+func runChangeBackup(ctx context.Context, manager string) error {
+	// The daemon already checked backup.conditions
+	command, err := stashrCommand("backup", "--non-interactive", "--ignore-conditions", "--manager", manager)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now().Truncate(time.Second)
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Let an interrupted backup remove its temporary export before it is killed
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = changeBackupStopDelay
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == exitInputRequired {
+			return fmt.Errorf("backup of %s needs input it can't prompt for: see the error above", managerDisplayName(manager))
+		}
+		return fmt.Errorf("backup of %s failed: %w", managerDisplayName(manager), err)
+	}
+
+	// An unsuccessful backup can still exit cleanly, so look for the backup it recorded
+	records, err := database.ListBackups(manager, "", nil)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 || records[0].CreatedAt.Before(started) {
+		return fmt.Errorf("backup of %s didn't complete", managerDisplayName(manager))
+	}
+	return nil
+}
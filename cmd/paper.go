@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/paper"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	exportPaperInput  string
+	exportPaperKey    bool
+	exportPaperOutput string
+	importPaperOutput string
+	importPaperForce  bool
+)
+
+// exportPaperCmd represents the export-paper command
+var exportPaperCmd = &cobra.Command{
+	Use:   "export-paper",
+	Short: "Print an encrypted backup or the encryption password for cold storage",
+	Long: `Render an encrypted backup, or the encryption password, as a printable PDF for
+cold storage: a QR code per chunk of the data, followed by the same chunks as
+text that can be typed back in without a scanner.
+
+The input can be a path to a backup file or the name of a backup in any
+configured storage location. Only encrypted backups can be printed, and they
+stay encrypted on paper. Each chunk carries its own checksum, so a damaged code
+or a typo is reported by chunk number when the paper is imported again.
+
+Paper holds about 170 bytes per code and 12 codes per page, so it suits
+compressed backups of small vaults and the encryption password. Keep the
+password's printout apart from the backups.`,
+	Example: `  stashr export-paper --input bitwarden_backup_2025-01-15_10-30-00.json.gz.enc
+  stashr export-paper --key --output key.pdf`,
+	Run: runExportPaper,
+}
+
+// importPaperCmd represents the import-paper command
+var importPaperCmd = &cobra.Command{
+	Use:   "import-paper [file...]",
+	Short: "Reassemble a backup or password printed with export-paper",
+	Long: `Reassemble a paper backup from text files holding its chunks, or from standard
+input if no file is given: the lines of its scanned QR codes or its typed text
+copy, in any order and with any spacing.
+
+Damaged and missing chunks are all listed at once, so they can be rescanned
+together. A backup is written to its original filename (or --output) and
+stays encrypted; "stashr convert --input <file>" decrypts it into any import
+format. A password is printed, or written to --output.`,
+	Example: `  stashr import-paper scans.txt
+  stashr import-paper page1.txt page2.txt --output backup.json.gz.enc
+  pbpaste | stashr import-paper`,
+	Run: runImportPaper,
+}
+
+func init() {
+	rootCmd.AddCommand(exportPaperCmd)
+	rootCmd.AddCommand(importPaperCmd)
+
+	exportPaperCmd.Flags().StringVarP(&exportPaperInput, "input", "i", "", "Backup file path or backup name to print")
+	exportPaperCmd.Flags().BoolVar(&exportPaperKey, "key", false, "Print the encryption password instead of a backup")
+	exportPaperCmd.Flags().StringVarP(&exportPaperOutput, "output", "o", "", "Output path for PDF (default: <backup>.paper.pdf or stashr-key.paper.pdf)")
+
+	importPaperCmd.Flags().StringVarP(&importPaperOutput, "output", "o", "", "Output path (default: the backup's filename in the current directory)")
+	importPaperCmd.Flags().BoolVarP(&importPaperForce, "force", "f", false, "Overwrite an existing output file")
+}
+
+func runExportPaper(cmd *cobra.Command, args []string) {
+	logger.Header("🖨️  Paper Backup Export")
+
+	if exportPaperKey == (exportPaperInput != "") {
+		logger.Failure("Specify either --input or --key")
+		return
+	}
+
+	doc, err := paperDocument()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	chunks, err := doc.Chunks()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if exportPaperOutput == "" {
+		if doc.Kind == paper.KindKey {
+			exportPaperOutput = "stashr-key.paper.pdf"
+		} else {
+			exportPaperOutput = doc.Name + ".paper.pdf"
+		}
+	}
+	if !filepath.IsAbs(exportPaperOutput) {
+		cwd, _ := os.Getwd()
+		exportPaperOutput = filepath.Join(cwd, exportPaperOutput)
+	}
+
+	logger.Progress("Rendering %d QR codes...", len(chunks))
+	if err := paper.WritePDF(doc, exportPaperOutput, time.Now()); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Success("✓ Paper backup generated: %s", exportPaperOutput)
+	logger.Info("Document ID: %s (%d chunks)", doc.ID(), len(chunks))
+	logger.Separator()
+	logger.Info("💡 Print it, then delete the PDF; test the printout with: stashr import-paper")
+	if doc.Kind == paper.KindKey {
+		logger.Warning("⚠️  Anyone with this printout and a backup can read your vault. Store it apart from your backups.")
+	}
+}
+
+// paperDocument returns what export-paper prints: the encryption password, or
+// an encrypted backup found by path or name
+func paperDocument() (*paper.Document, error) {
+	if exportPaperKey {
+		password, err := utils.PromptForPassword("Enter encryption password: ")
+		if err != nil {
+			return nil, err
+		}
+		if password == "" {
+			return nil, fmt.Errorf("encryption password is required")
+		}
+		confirmPassword, err := utils.PromptForPassword("Confirm encryption password: ")
+		if err != nil {
+			return nil, err
+		}
+		if password != confirmPassword {
+			return nil, fmt.Errorf("passwords do not match")
+		}
+		return &paper.Document{Kind: paper.KindKey, Data: []byte(password)}, nil
+	}
+
+	data, err := os.ReadFile(exportPaperInput)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Progress("Searching for backup file: %s", exportPaperInput)
+		var sourceName string
+		data, sourceName, err = findBackupInAllSources(cfg, filepath.Base(exportPaperInput))
+		if err != nil {
+			return nil, err
+		}
+		logger.Success("✓ Found backup in %s", sourceName)
+	}
+
+	// A vault printed in the clear could be read by anyone who sees the paper
+	if !crypto.IsEncrypted(data) && !publicKeyEncrypted(data) {
+		return nil, fmt.Errorf("%s is not encrypted; only encrypted backups can be printed", filepath.Base(exportPaperInput))
+	}
+	return &paper.Document{Kind: paper.KindBackup, Name: filepath.Base(exportPaperInput), Data: data}, nil
+}
+
+func runImportPaper(cmd *cobra.Command, args []string) {
+	logger.Header("🖨️  Paper Backup Import")
+
+	var text strings.Builder
+	if len(args) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			logger.PrintError(fmt.Errorf("failed to read standard input: %w", err))
+			return
+		}
+		text.Write(data)
+	}
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		text.Write(data)
+	}
+
+	doc, err := paper.Reassemble(text.String())
+	if errors.Is(err, paper.ErrNoChunks) {
+		logger.Failure("No paper backup chunks found (each starts with STASHR:)")
+		return
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Reassembled paper backup %s", doc.ID())
+
+	if doc.Kind == paper.KindKey {
+		if importPaperOutput == "" {
+			logger.Warning("⚠️  Showing the encryption password")
+			fmt.Println(string(doc.Data))
+			return
+		}
+		if err := writePaperOutput(importPaperOutput, doc.Data); err != nil {
+			logger.PrintError(err)
+			return
+		}
+		logger.Success("✓ Encryption password written to %s", importPaperOutput)
+		return
+	}
+
+	output := importPaperOutput
+	if output == "" {
+		// The name comes from the paper, so it may not name another directory
+		output = filepath.Base(doc.Name)
+	}
+	if err := writePaperOutput(output, doc.Data); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	checksum := utils.SHA256Hex(doc.Data)
+	logger.Success("✓ Backup written to %s (%s)", output, utils.FormatBytes(int64(len(doc.Data))))
+	logger.Info("SHA-256: %s", checksum)
+	if recorded := recordedChecksums(doc.Name); len(recorded) > 0 {
+		if slices.Contains(recorded, checksum) {
+			logger.Success("✓ Matches the checksum recorded when %s was made", doc.Name)
+		} else {
+			logger.Warning("⚠ Differs from the checksum recorded for %s", doc.Name)
+		}
+	}
+	logger.Info("Decrypt it with: stashr convert --input %s --to <format>", output)
+}
+
+// writePaperOutput writes an imported backup or password, which only the owner may read
+func writePaperOutput(path string, data []byte) error {
+	if !importPaperForce && utils.FileExists(path) {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", path)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+// policyViolationError indicates a policy forbids storing a manager's backups in a destination
+type policyViolationError struct {
+	Policy      string
+	Manager     string
+	Destination string
+	Reason      string
+}
+
+func (e *policyViolationError) Error() string {
+	return fmt.Sprintf("policy %s forbids %s backups on %s: %s", e.Policy, e.Manager, e.Destination, e.Reason)
+}
+
+// validatePolicies checks that every destination referenced by a policy exists, so a
+// typo cannot silently turn an allow list into a rule that blocks nothing
+func validatePolicies(cfg *config.Config) error {
+	known := make(map[string]bool)
+	var flags []string
+	for _, dest := range storageDestinations(cfg) {
+		known[dest.flag] = true
+		flags = append(flags, dest.flag)
+	}
+
+	for i, policy := range cfg.Policies {
+		for _, dest := range append(append([]string{}, policy.AllowDestinations...), policy.DenyDestinations...) {
+			if !known[dest] {
+				return fmt.Errorf("policy %s: unknown destination %s (use: %s)", policyName(policy, i), dest, strings.Join(flags, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPolicies returns a violation if any policy matching the manager forbids the backend
+func checkPolicies(cfg *config.Config, manager string, backend storage.Storage) error {
+	dest, ok := destinationForBackend(cfg, backend)
+	if !ok {
+		return nil
+	}
+	labels := cfg.ManagerLabels(manager)
+
+	for i, policy := range cfg.Policies {
+		if !policy.Matches(manager, labels) {
+			continue
+		}
+
+		violation := &policyViolationError{
+			Policy:      policyName(policy, i),
+			Manager:     manager,
+			Destination: backend.Name(),
+		}
+		if containsString(policy.DenyDestinations, dest.flag) {
+			violation.Reason = "destination is denied"
+			return violation
+		}
+		if len(policy.AllowDestinations) > 0 && !containsString(policy.AllowDestinations, dest.flag) {
+			violation.Reason = fmt.Sprintf("only %s allowed", strings.Join(policy.AllowDestinations, ", "))
+			return violation
+		}
+	}
+
+	return nil
+}
+
+// policyName returns a policy's name, or its position when unnamed
+func policyName(policy config.PolicyConfig, index int) string {
+	if policy.Name != "" {
+		return policy.Name
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+// containsString reports whether a string slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+// preflightResult holds the managers and destinations that passed the pre-flight checks
+type preflightResult struct {
+	managers []managers.Manager
+	backends []storage.Storage
+	// unavailable are the destinations that failed, whose uploads are queued for retry
+	unavailable []storage.Storage
+	// failed names everything that did not pass
+	failed []string
+}
+
+// runPreflight checks every manager and destination before any export starts, so a
+// dead destination is found before a long export instead of after it
+func runPreflight(mgrs []managers.Manager, backends []storage.Storage) preflightResult {
+	logger.Progress("Running pre-flight checks...")
+
+	var result preflightResult
+	for _, mgr := range mgrs {
+		if err := checkManagerReady(mgr); err != nil {
+			logger.Failure("  ✗ %s: %v", mgr.Name(), err)
+			result.failed = append(result.failed, mgr.Name())
+			recordEvent(database.EventRecord{Kind: database.EventBackup, Manager: mgr.Name()}, fmt.Errorf("pre-flight check failed: %w", err))
+			continue
+		}
+		logger.Success("  ✓ %s: installed and authenticated", mgr.Name())
+		result.managers = append(result.managers, mgr)
+	}
+
+	for _, backend := range backends {
+		available, err := backend.IsAvailable()
+		if err == nil && !available {
+			err = fmt.Errorf("not available")
+		}
+		if err != nil {
+			logger.Failure("  ✗ %s: %v", backend.Name(), err)
+			result.failed = append(result.failed, backend.Name())
+			result.unavailable = append(result.unavailable, backend)
+			// Counts as a failed upload towards the destination's health
+			recordDestinationOp(backend, database.OperationUpload, time.Now(), err)
+			continue
+		}
+		logger.Success("  ✓ %s: available", backend.Name())
+		result.backends = append(result.backends, backend)
+	}
+
+	logger.Info("Pre-flight: %d/%d managers ready, %d/%d destinations available",
+		len(result.managers), len(mgrs), len(result.backends), len(backends))
+	return result
+}
+
+// checkManagerReady returns why a manager cannot be backed up, or nil if it can
+func checkManagerReady(mgr managers.Manager) error {
+	if !mgr.IsInstalled() {
+		return fmt.Errorf("CLI is not installed")
+	}
+	authenticated, err := mgr.IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if !authenticated {
+		return fmt.Errorf("not authenticated")
+	}
+	return nil
+}
+
+// preflightDecision reports whether the backup should go ahead after the pre-flight
+// checks. In strict mode any failure is a no-go; otherwise the run continues with
+// whatever passed, as long as at least one manager and one destination did.
+func preflightDecision(result preflightResult, strict bool) bool {
+	if len(result.failed) == 0 {
+		logger.Success("✅ Go: all checks passed")
+		return true
+	}
+
+	if strict {
+		logger.Failure("⛔ No-go: %d check(s) failed (%s). Aborting because of --strict", len(result.failed), strings.Join(result.failed, ", "))
+		return false
+	}
+	if len(result.managers) == 0 {
+		logger.Failure("⛔ No-go: no password manager is ready")
+		return false
+	}
+	if len(result.backends) == 0 {
+		logger.Failure("⛔ No-go: no storage destination is available")
+		return false
+	}
+
+	logger.Warning("⚠ Go with gaps: continuing without %s (use --strict to abort instead)", strings.Join(result.failed, ", "))
+	return true
+}
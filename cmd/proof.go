@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/proof"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	proofSource string
+)
+
+// proofCmd represents the proof command
+var proofCmd = &cobra.Command{
+	Use:   "proof",
+	Short: "Inspect and verify published backup integrity proofs",
+	Long: `Inspect and verify backup integrity proofs.
+
+When proofs are enabled, every backup's SHA-256 checksum (never its content) is
+appended to a file in a git repository and committed. The commit history shows
+when each backup existed, so a later check can prove a backup is the one that
+was made on that date and wasn't replaced since.
+
+Subcommands:
+  list   - Show every published proof
+  verify - Check a backup against the published proofs`,
+}
+
+// proofListCmd represents the proof list command
+var proofListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every published proof",
+	Run:   runProofList,
+}
+
+// proofVerifyCmd represents the proof verify command
+var proofVerifyCmd = &cobra.Command{
+	Use:   "verify <backup>",
+	Short: "Check a backup against the published proofs",
+	Example: `  # Verify a local backup file
+  stashr proof verify ~/.stashr/backups/backup_bitwarden_20240101_120000.json.enc
+
+  # Download a backup from a destination and verify it
+  stashr proof verify backup_bitwarden_20240101_120000.json.enc --source gdrive`,
+	Args: cobra.ExactArgs(1),
+	Run:  runProofVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(proofCmd)
+	proofCmd.AddCommand(proofListCmd)
+	proofCmd.AddCommand(proofVerifyCmd)
+
+	proofVerifyCmd.Flags().StringVarP(&proofSource, "source", "s", "", "Download the backup from this destination (gdrive, onedrive, webdav, gcs, azure, s3, rclone, icloud, usb, local, git-annex, a profile or plugin name)")
+}
+
+// proofPublisher returns the configured proof location
+func proofPublisher(cfg *config.Config) proof.Publisher {
+	return proof.NewGit(cfg.Proofs.Git.RepoPath, cfg.Proofs.Git.File, cfg.Proofs.Git.Remote)
+}
+
+// proofLock serializes publishing across concurrent manager backups
+var proofLock sync.Mutex
+
+// publishProof publishes the checksum of a stored backup. Failing to publish
+// is reported but does not fail the backup.
+func publishProof(out *logger.Scope, cfg *config.Config, manager string, artifact *backupArtifact) {
+	if !cfg.Proofs.Enabled {
+		return
+	}
+
+	proofLock.Lock()
+	defer proofLock.Unlock()
+
+	publisher := proofPublisher(cfg)
+	record := proof.Record{
+		Filename:  artifact.filename,
+		Manager:   manager,
+		SHA256:    utils.SHA256Hex(artifact.data),
+		Size:      int64(len(artifact.data)),
+		CreatedAt: time.Now(),
+	}
+	if err := publisher.Publish(record); err != nil {
+		out.Warning("⚠ %v", err)
+		return
+	}
+	out.Success("✓ Published integrity proof to %s", publisher.Name())
+}
+
+func runProofList(cmd *cobra.Command, args []string) {
+	logger.Header("🧾 Backup Integrity Proofs")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if cfg.Proofs.Git.RepoPath == "" {
+		logger.Failure("No proofs repository configured (set proofs.git.repo_path)")
+		return
+	}
+
+	publications, err := proofPublisher(cfg).History()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if len(publications) == 0 {
+		logger.Info("No proofs published yet")
+		return
+	}
+
+	fmt.Printf("%-17s %-12s %-50s %s\n", i18n.T("PUBLISHED"), i18n.T("MANAGER"), i18n.T("FILENAME"), "SHA-256")
+	for _, publication := range publications {
+		fmt.Printf("%-17s %-12s %-50s %s\n",
+			publication.PublishedAt.Format("2006-01-02 15:04"),
+			publication.Manager,
+			publication.Filename,
+			publication.SHA256,
+		)
+	}
+	logger.Separator()
+	logger.Info("%d proof(s) in %s", len(publications), filepath.Join(cfg.Proofs.Git.RepoPath, cfg.Proofs.Git.File))
+}
+
+func runProofVerify(cmd *cobra.Command, args []string) {
+	logger.Header("🧾 Verify Backup Proof")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if cfg.Proofs.Git.RepoPath == "" {
+		logger.Failure("No proofs repository configured (set proofs.git.repo_path)")
+		return
+	}
+
+	// Read the backup from disk, or download it from the given destination
+	filename := filepath.Base(args[0])
+	var data []byte
+	if proofSource == "" {
+		data, err = os.ReadFile(args[0])
+		if err != nil {
+			logger.PrintError(err)
+			logger.Info("To verify a backup stored in a destination, use --source")
+			return
+		}
+	} else {
+		dest, err := findStorageDestination(cfg, proofSource)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		backend := dest.create()
+		logger.Progress("Downloading %s from %s...", filename, backend.Name())
+		data, err = backend.Download(filename)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+	}
+
+	checksum := utils.SHA256Hex(data)
+	logger.Info("Backup: %s (%s)", filename, utils.FormatBytes(int64(len(data))))
+	logger.Info("SHA-256: %s", checksum)
+	logger.Separator()
+
+	publisher := proofPublisher(cfg)
+	if err := publisher.CheckAppendOnly(); err != nil {
+		logger.Failure("✗ Proof history is not append-only: %v", err)
+		logger.Info("  Proofs published before that commit may have been altered")
+	}
+
+	publications, err := publisher.History()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	var matched bool
+	var replaced []proof.Publication
+	for _, publication := range publications {
+		if publication.SHA256 == checksum {
+			matched = true
+			logger.Success("✓ Proof published %s in commit %s", publication.PublishedAt.Format("2006-01-02 15:04:05"), publication.Revision[:12])
+			if publication.Filename != filename {
+				logger.Info("  Published as %s", publication.Filename)
+			}
+			continue
+		}
+		if publication.Filename == filename {
+			replaced = append(replaced, publication)
+		}
+	}
+
+	if matched {
+		logger.Success("✅ Backup matches its published proof")
+		return
+	}
+	if len(replaced) > 0 {
+		for _, publication := range replaced {
+			logger.Failure("✗ %s was published %s with checksum %s", publication.Filename, publication.PublishedAt.Format("2006-01-02 15:04:05"), publication.SHA256)
+		}
+		logger.Failure("⛔ Backup does not match its published proof: it was modified or replaced")
+		return
+	}
+	logger.Failure("✗ No proof was published for this backup")
+}
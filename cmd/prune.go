@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	pruneDestination string
+	pruneYes         bool
+	pruneEmptyTrash  bool
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old backups according to the retention policy",
+	Long: `Evaluate the retention policy on every destination and delete the backups
+it doesn't keep, the same way each backup does after uploading.
+
+Every backup that would be deleted is listed with the reason no rule keeps it,
+e.g. "not among the newest 10 (#12); older than 14 days". Destinations with a
+retention override use their own policy. Nothing is deleted until you confirm,
+or pass --yes; use --dry-run to only see the plan.
+
+With retention.trash_days set, destinations with a trash (local, USB, Google
+Drive) move these backups to their trash instead. --empty-trash permanently
+deletes the trashed backups whose grace period is over.`,
+	Example: `  stashr prune --dry-run
+  stashr prune --destination usb
+  stashr prune --yes
+  stashr prune --empty-trash`,
+	Run: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().StringVarP(&pruneDestination, "destination", "d", "all", "Destination to prune (gdrive, usb, local, ..., all)")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Delete without confirmation")
+	pruneCmd.Flags().BoolVar(&pruneEmptyTrash, "empty-trash", false, "Permanently delete trashed backups whose grace period is over")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which backups would be deleted and why without deleting them")
+}
+
+// prunePlan is the backups retention deletes from one destination
+type prunePlan struct {
+	backend   storage.Storage
+	deletions []storage.RetentionDecision
+	// trashDays is how long the deleted backups stay in the trash, 0 if they are deleted right away
+	trashDays int
+}
+
+func runPrune(cmd *cobra.Command, args []string) {
+	logger.Header("🧹 Prune Old Backups")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if pruneDestination != "all" {
+		if _, err := findStorageDestination(cfg, pruneDestination); err != nil {
+			logger.PrintError(err)
+			return
+		}
+	}
+
+	if dryRun {
+		printDryRunHeader()
+	}
+
+	backends := selectStorageBackends(cfg, pruneDestination)
+	if len(backends) == 0 {
+		logger.Failure("No storage backends enabled or selected")
+		return
+	}
+
+	if pruneEmptyTrash {
+		emptyTrash(cfg, backends, time.Now())
+		return
+	}
+
+	plans, total := planPrune(cfg, backends, time.Now())
+	logger.Separator()
+
+	if total == 0 {
+		logger.Success("✓ Nothing to prune")
+		return
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		for _, p := range plans {
+			for _, deletion := range p.deletions {
+				plan.Add("%s (%s)", retentionRemoval(cfg, p.backend, deletion.Backup.Name), strings.Join(deletion.Reasons, "; "))
+			}
+		}
+		plan.Print()
+		return
+	}
+
+	if !pruneYes && !utils.ConfirmPrompt(fmt.Sprintf("Prune %d backups?", total)) {
+		logger.Info("Prune cancelled")
+		return
+	}
+
+	deleted := 0
+	for _, p := range plans {
+		deleted += prune(p)
+	}
+
+	logger.Separator()
+	if deleted < total {
+		logger.Warning("⚠ Pruned %d of %d backups", deleted, total)
+		return
+	}
+	logger.Success("✅ Pruned %d backups", deleted)
+}
+
+// planPrune lists, for each available destination, the backups its retention
+// policy deletes, and returns the plans and the number of deletions
+func planPrune(cfg *config.Config, backends []storage.Storage, now time.Time) ([]prunePlan, int) {
+	var plans []prunePlan
+	total := 0
+	for _, backend := range backends {
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			logger.Warning("⚠ %s: not available, skipped", backend.Name())
+			continue
+		}
+		backups, err := backend.List()
+		if err != nil {
+			logger.Warning("⚠ %s: failed to list backups: %v", backend.Name(), err)
+			continue
+		}
+
+		policy := destinationRetentionPolicy(cfg, backend)
+		p := prunePlan{backend: backend, trashDays: destinationTrashDays(cfg, backend)}
+		var locked, skipped []storage.RetentionDecision
+		lockedUntil := map[string]time.Time{}
+		checkLocks := true
+		for _, decision := range storage.EvaluateRetention(backups, policy, backupManagerOf(cfg), now) {
+			if decision.Skipped {
+				skipped = append(skipped, decision)
+				continue
+			}
+			if decision.Keep {
+				continue
+			}
+			// Locked backups can't be deleted yet, a later prune removes them
+			if checkLocks {
+				until, err := storage.LockedUntil(backend, decision.Backup.Name)
+				if errors.Is(err, storage.ErrLockUnsupported) {
+					checkLocks = false
+				} else if err == nil && until.After(now) {
+					locked = append(locked, decision)
+					lockedUntil[decision.Backup.Name] = until
+					continue
+				}
+			}
+			p.deletions = append(p.deletions, decision)
+		}
+
+		logger.Info("📁 %s (keeping %s per manager): %d backups, %d to delete",
+			backend.Name(), policy, len(backups), len(p.deletions))
+		// Files no filename format recognizes may not be backups at all
+		for _, decision := range skipped {
+			logger.Info("  ❔ %s: not a recognized backup name, left alone", decision.Backup.Name)
+		}
+		for i := len(locked) - 1; i >= 0; i-- {
+			name := locked[i].Backup.Name
+			logger.Info("  🔒 %s: locked until %s", name, lockedUntil[name].Local().Format("2006-01-02 15:04"))
+		}
+		if p.trashDays > 0 && len(p.deletions) > 0 {
+			logger.Info("  Moved to the trash for %d days", p.trashDays)
+		}
+		// Oldest backups are listed first
+		for i := len(p.deletions) - 1; i >= 0; i-- {
+			deletion := p.deletions[i]
+			logger.Info("  🗑️  %s (%s, %s)", deletion.Backup.Name, managerDisplayName(deletion.Manager),
+				deletion.Backup.ModifiedTime.Local().Format("2006-01-02 15:04"))
+			logger.Info("      %s", strings.Join(deletion.Reasons, "; "))
+		}
+
+		if len(p.deletions) > 0 {
+			plans = append(plans, p)
+			total += len(p.deletions)
+		}
+	}
+	return plans, total
+}
+
+// prune deletes the planned backups from one destination and returns how many
+// were deleted. Like retention after a backup, it holds the destination's lease
+// so another host doesn't prune at the same time.
+func prune(p prunePlan) int {
+	lease, err := storage.AcquireLease(p.backend, leaseHolder(), storage.DefaultLeaseTTL)
+	if err != nil {
+		logger.Warning("⚠ Skipping %s: %v", p.backend.Name(), err)
+		return 0
+	}
+	defer func() {
+		if err := lease.Release(); err != nil {
+			logger.Warning("Failed to release lock on %s: %v", p.backend.Name(), err)
+		}
+	}()
+
+	remove := withSignature(p.backend.Delete)
+	if p.trashDays > 0 {
+		remove = withSignature(func(filename string) error {
+			return storage.Trash(p.backend, filename)
+		})
+	}
+
+	deleted := 0
+	for _, deletion := range p.deletions {
+		if err := remove(deletion.Backup.Name); err != nil {
+			logger.Failure("✗ Failed to delete %s from %s: %v", deletion.Backup.Name, p.backend.Name(), err)
+			continue
+		}
+		deleted++
+	}
+	if p.trashDays > 0 {
+		logger.Success("✓ Moved %d backups to the trash of %s", deleted, p.backend.Name())
+	} else {
+		logger.Success("✓ Deleted %d backups from %s", deleted, p.backend.Name())
+	}
+	return deleted
+}
+
+// trashPlan is the trashed backups of one destination whose grace period is over
+type trashPlan struct {
+	backend storage.Storage
+	expired []storage.TrashedFile
+}
+
+// emptyTrash permanently deletes the trashed backups whose grace period is over
+// at now, after confirmation
+func emptyTrash(cfg *config.Config, backends []storage.Storage, now time.Time) {
+	var plans []trashPlan
+	total := 0
+	for _, backend := range backends {
+		if !storage.SupportsTrash(backend) {
+			continue
+		}
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			logger.Warning("⚠ %s: not available, skipped", backend.Name())
+			continue
+		}
+		trashed, err := storage.ListTrash(backend)
+		if err != nil {
+			logger.Warning("⚠ %s: %v", backend.Name(), err)
+			continue
+		}
+
+		grace := time.Duration(trashGraceDays(cfg, backend)) * 24 * time.Hour
+		p := trashPlan{backend: backend}
+		logger.Info("📁 %s: %d trashed backups", backend.Name(), len(trashed))
+		for _, file := range trashed {
+			if until := file.TrashedTime.Add(grace); until.After(now) {
+				logger.Info("  ⏳ %s: kept until %s", file.Name, until.Local().Format("2006-01-02"))
+				continue
+			}
+			p.expired = append(p.expired, file)
+		}
+		if len(p.expired) > 0 {
+			plans = append(plans, p)
+			total += len(p.expired)
+		}
+	}
+	logger.Separator()
+
+	if total == 0 {
+		logger.Success("✓ No trashed backups past their grace period")
+		return
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		for _, p := range plans {
+			for _, file := range p.expired {
+				plan.Add("Permanently delete %s from the trash of %s (trashed %s)", file.Name, p.backend.Name(),
+					file.TrashedTime.Local().Format("2006-01-02"))
+			}
+		}
+		plan.Print()
+		return
+	}
+
+	if !pruneYes && !utils.ConfirmPrompt(fmt.Sprintf("Permanently delete %d trashed backups?", total)) {
+		logger.Info("Prune cancelled")
+		return
+	}
+
+	deleted := 0
+	for _, p := range plans {
+		deleted += deleteTrashed(p)
+	}
+
+	logger.Separator()
+	if deleted < total {
+		logger.Warning("⚠ Deleted %d of %d trashed backups", deleted, total)
+		return
+	}
+	logger.Success("✅ Deleted %d trashed backups", deleted)
+}
+
+// deleteTrashed permanently deletes the expired trashed backups of one
+// destination, holding its lease like prune, and returns how many were deleted
+func deleteTrashed(p trashPlan) int {
+	lease, err := storage.AcquireLease(p.backend, leaseHolder(), storage.DefaultLeaseTTL)
+	if err != nil {
+		logger.Warning("⚠ Skipping %s: %v", p.backend.Name(), err)
+		return 0
+	}
+	defer func() {
+		if err := lease.Release(); err != nil {
+			logger.Warning("Failed to release lock on %s: %v", p.backend.Name(), err)
+		}
+	}()
+
+	deleted := 0
+	for _, file := range p.expired {
+		if err := storage.DeleteTrashed(p.backend, file.Name); err != nil {
+			logger.Failure("✗ Failed to delete %s from the trash of %s: %v", file.Name, p.backend.Name(), err)
+			continue
+		}
+		deleted++
+	}
+	logger.Success("✓ Deleted %d trashed backups from %s", deleted, p.backend.Name())
+	return deleted
+}
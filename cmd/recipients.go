@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// publicKeyEncrypted reports whether a backup is encrypted to age or GPG
+// recipients rather than with a password
+func publicKeyEncrypted(data []byte) bool {
+	return crypto.IsAgeEncrypted(data) || crypto.IsGPGEncrypted(data)
+}
+
+// decryptPublicKeyBackup decrypts a backup encrypted to age or GPG recipients.
+// cfg may be nil, in which case the configuration is loaded.
+func decryptPublicKeyBackup(cfg *config.Config, data []byte) ([]byte, error) {
+	if cfg == nil {
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			return nil, err
+		}
+	}
+	if crypto.IsGPGEncrypted(data) {
+		return decryptGPGBackup(cfg, data)
+	}
+	return decryptAgeBackup(cfg, data)
+}
+
+// decryptAgeBackup decrypts a backup encrypted to age recipients with the
+// identity file configured in backup.encryption.identity_file
+func decryptAgeBackup(cfg *config.Config, data []byte) ([]byte, error) {
+	identityFile := cfg.Backup.Encryption.IdentityFile
+	if identityFile == "" {
+		return nil, fmt.Errorf("backup is encrypted with age; set backup.encryption.identity_file to the private key that decrypts it")
+	}
+
+	keyData, err := os.ReadFile(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+	identities, err := crypto.ParseAgeIdentities(keyData, func() ([]byte, error) {
+		passphrase, err := utils.PromptForPassword(fmt.Sprintf("Enter passphrase for %s: ", identityFile))
+		return []byte(passphrase), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Progress("Decrypting backup with %s...", identityFile)
+	plaintext, err := crypto.DecryptAge(data, identities)
+	if errors.Is(err, crypto.ErrNoAgeIdentity) {
+		return nil, fmt.Errorf("%w (%s)", err, identityFile)
+	}
+	return plaintext, err
+}
+
+// decryptGPGBackup decrypts a backup encrypted to GPG recipients with a secret
+// key in the gpg keyring
+func decryptGPGBackup(cfg *config.Config, data []byte) ([]byte, error) {
+	logger.Progress("Decrypting backup with gpg...")
+	logger.Info("gpg may ask for your key's passphrase or your smartcard's PIN")
+	return crypto.GPGDecrypt(cfg.Backup.Encryption.GPG.Executable(), data)
+}
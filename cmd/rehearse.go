@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/backupname"
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	rehearseMaxAgeDays   int
+	rehearseIncludeLocal bool
+	rehearseReportPath   string
+)
+
+// rehearsalCheck is a single scored item in the rehearsal report
+type rehearsalCheck struct {
+	Name   string
+	Passed bool
+	Weight int
+	Detail string
+}
+
+// rehearseCmd represents the rehearse command
+var rehearseCmd = &cobra.Command{
+	Use:   "rehearse",
+	Short: "Simulate a full disaster recovery",
+	Long: `Walk through a simulated full recovery as if this machine were lost.
+
+This command will:
+1. Ignore local storage (pretend the laptop is gone)
+2. Find the latest backup of each manager on the remaining destinations
+3. Download it into a temporary sandbox
+4. Decrypt and decompress it with the password you remember
+5. Validate the vault data and compare it with recorded metadata
+6. Produce a scored report of gaps (missing password, stale backup,
+   unreachable destination)
+
+The sandbox is deleted when the rehearsal finishes.`,
+	Run: runRehearse,
+}
+
+func init() {
+	rootCmd.AddCommand(rehearseCmd)
+
+	rehearseCmd.Flags().IntVar(&rehearseMaxAgeDays, "max-age-days", 7, "Backups older than this are reported as stale")
+	rehearseCmd.Flags().BoolVar(&rehearseIncludeLocal, "include-local", false, "Also use local storage (not a realistic disaster scenario)")
+	rehearseCmd.Flags().StringVarP(&rehearseReportPath, "report", "r", "", "Write the rehearsal report to a file")
+}
+
+func runRehearse(cmd *cobra.Command, args []string) {
+	logger.Header("🧯 Disaster Recovery Rehearsal")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// A lost machine has no cache, and a cached copy proves nothing about the remote one
+	noCache = true
+
+	logger.Info("Scenario: your computer is gone. Only your remote backups and your memory remain.")
+	logger.Separator()
+
+	var checks []rehearsalCheck
+	addCheck := func(name string, passed bool, weight int, detail string) {
+		checks = append(checks, rehearsalCheck{Name: name, Passed: passed, Weight: weight, Detail: detail})
+		if passed {
+			logger.Success("✓ %s", name)
+		} else {
+			logger.Failure("✗ %s: %s", name, detail)
+		}
+	}
+
+	// Step 1: reach remote destinations
+	logger.Progress("Contacting remote destinations...")
+	var reachable []storage.Storage
+	for _, backend := range getStorageBackendsForRestore(cfg) {
+		if dest, ok := destinationForBackend(cfg, backend); ok && dest.kind == "local" && !rehearseIncludeLocal {
+			continue
+		}
+
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			detail := "not available"
+			if err != nil {
+				detail = err.Error()
+			}
+			addCheck(fmt.Sprintf("Destination %s reachable", backend.Name()), false, 10, detail)
+			continue
+		}
+		addCheck(fmt.Sprintf("Destination %s reachable", backend.Name()), true, 10, "")
+		reachable = append(reachable, backend)
+	}
+
+	if len(reachable) == 0 {
+		addCheck("At least one remote destination", false, 30, "no remote destination is reachable; a local-only setup cannot survive losing this machine")
+		printRehearsalReport(checks)
+		return
+	}
+	addCheck("At least one remote destination", true, 30, "")
+
+	// Step 2: find the latest backup per manager
+	names := newBackupNameParser(cfg)
+	latest := make(map[string]BackupWithSource)
+	backendsByName := make(map[string]storage.Storage)
+	for _, backend := range reachable {
+		backendsByName[backend.Name()] = backend
+		backups, err := backend.List()
+		if err != nil {
+			addCheck(fmt.Sprintf("List backups on %s", backend.Name()), false, 5, err.Error())
+			continue
+		}
+		for _, backup := range backups {
+			manager := detectManager(names, backup.Name)
+			current, ok := latest[manager]
+			if !ok || backup.ModifiedTime.After(current.Backup.ModifiedTime) {
+				latest[manager] = BackupWithSource{Backup: backup, Source: backend.Name()}
+			}
+		}
+	}
+
+	for _, manager := range enabledManagerNames(cfg) {
+		if _, ok := latest[manager]; !ok {
+			addCheck(fmt.Sprintf("Remote backup exists for %s", manager), false, 20, "no backup found on any remote destination")
+		}
+	}
+
+	if len(latest) == 0 {
+		printRehearsalReport(checks)
+		return
+	}
+
+	// Step 3: ask for the password from memory, or find the key backups are encrypted to
+	var password *secret.Buffer
+	defer func() { password.Destroy() }()
+	if len(cfg.Backup.Encryption.Recipients) > 0 {
+		identityFile := cfg.Backup.Encryption.IdentityFile
+		addCheck("age identity file available", identityFile != "" && utils.FileExists(identityFile), 20,
+			"without the private key no backup encrypted to the age recipients can be restored")
+	} else if keyFile := cfg.Backup.Encryption.KeyFile; keyFile != "" {
+		addCheck("Key file available", utils.FileExists(keyFile), 20,
+			"without the key file no backup encrypted with it can be restored")
+	} else if gpg := cfg.Backup.Encryption.GPG; len(gpg.Recipients) > 0 {
+		addCheck("GPG secret key available", crypto.GPGHasSecretKey(gpg.Executable(), gpg.Recipients), 20,
+			"no secret key or smartcard of the GPG recipients is in this keyring")
+	} else {
+		logger.Separator()
+		logger.Info("Enter your encryption password from memory (leave empty if you don't know it).")
+		password, err = utils.PromptForSecret("Enter encryption password: ")
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		addCheck("Encryption password remembered", password.Len() > 0, 20, "without the password no backup can be restored")
+	}
+
+	// Step 4: sandbox
+	sandbox, err := os.MkdirTemp("", "stashr-rehearsal-*")
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	defer os.RemoveAll(sandbox)
+	logger.Info("Sandbox: %s", sandbox)
+	logger.Separator()
+
+	managerNames := make([]string, 0, len(latest))
+	for manager := range latest {
+		managerNames = append(managerNames, manager)
+	}
+	sort.Strings(managerNames)
+
+	for _, manager := range managerNames {
+		item := latest[manager]
+		logger.Progress("Rehearsing restore of %s (%s from %s)...", manager, item.Backup.Name, item.Source)
+
+		age := time.Since(item.Backup.ModifiedTime)
+		addCheck(fmt.Sprintf("%s backup is fresh", manager), age <= time.Duration(rehearseMaxAgeDays)*24*time.Hour, 10,
+			fmt.Sprintf("latest backup is %s (limit: %d days)", formatAge(age), rehearseMaxAgeDays))
+
+		downloadStart := time.Now()
+		data, err := backendsByName[item.Source].Download(item.Backup.Name)
+		recordDestinationOp(backendsByName[item.Source], database.OperationDownload, downloadStart, err)
+		if err != nil {
+			addCheck(fmt.Sprintf("%s backup downloads", manager), false, 15, err.Error())
+			continue
+		}
+		addCheck(fmt.Sprintf("%s backup downloads", manager), true, 15, "")
+
+		sandboxFile := filepath.Join(sandbox, path.Base(item.Backup.Name))
+		if err := os.WriteFile(sandboxFile, data, 0600); err != nil {
+			logger.PrintError(err)
+			continue
+		}
+
+		keyEncrypted := publicKeyEncrypted(data) || crypto.UsesKeyFile(data)
+		if password.Len() == 0 && !keyEncrypted {
+			continue
+		}
+
+		verification := database.EventRecord{Kind: database.EventVerification, Manager: manager, StorageType: item.Source, Filename: item.Backup.Name}
+		if err := checkFIPSFile(cfg, item.Backup.Name, data); err != nil {
+			addCheck(fmt.Sprintf("%s backup uses FIPS-approved encryption", manager), false, 20, err.Error())
+			recordEvent(verification, err)
+			continue
+		}
+		var plaintext []byte
+		if crypto.UsesKeyFile(data) {
+			plaintext, err = decryptKeyFileBackup(cfg, "", data)
+		} else if keyEncrypted {
+			plaintext, err = decryptPublicKeyBackup(cfg, data)
+		} else {
+			plaintext, err = crypto.DecryptWithPassword(data, password)
+		}
+		if err != nil {
+			addCheck(fmt.Sprintf("%s backup decrypts", manager), false, 20, err.Error())
+			recordEvent(verification, err)
+			continue
+		}
+		addCheck(fmt.Sprintf("%s backup decrypts", manager), true, 20, "")
+
+		if isCompressedBackup(plaintext) {
+			plaintext, err = decompressBackup(cfg, plaintext)
+			if err != nil {
+				addCheck(fmt.Sprintf("%s backup decompresses", manager), false, 10, err.Error())
+				recordEvent(verification, err)
+				continue
+			}
+		}
+
+		itemCount, err := managers.CountExportItems(plaintext)
+		if err != nil {
+			addCheck(fmt.Sprintf("%s vault data is valid", manager), false, 15, err.Error())
+			recordEvent(verification, err)
+			continue
+		}
+		addCheck(fmt.Sprintf("%s vault data is valid", manager), itemCount > 0, 15, "export contains no items")
+		if itemCount == 0 {
+			recordEvent(verification, fmt.Errorf("export contains no items"))
+		} else {
+			recordEvent(verification, nil)
+			markChecklistItem(checklistTestRestore)
+		}
+
+		if record, _ := database.GetBackup(item.Backup.Name); record != nil && record.ItemCount != nil {
+			addCheck(fmt.Sprintf("%s item count matches records", manager), *record.ItemCount == itemCount, 5,
+				fmt.Sprintf("recorded %d items, found %d", *record.ItemCount, itemCount))
+		}
+	}
+
+	printRehearsalReport(checks)
+}
+
+// printRehearsalReport prints the scored report and optionally writes it to a file
+func printRehearsalReport(checks []rehearsalCheck) {
+	total, earned := 0, 0
+	var gaps []rehearsalCheck
+	for _, check := range checks {
+		total += check.Weight
+		if check.Passed {
+			earned += check.Weight
+		} else {
+			gaps = append(gaps, check)
+		}
+	}
+
+	score := 0
+	if total > 0 {
+		score = earned * 100 / total
+	}
+
+	logger.Separator()
+	logger.Info("📝 Rehearsal Report")
+	logger.Info("  Score: %d/100", score)
+	logger.Info("  Checks passed: %d/%d", len(checks)-len(gaps), len(checks))
+
+	if len(gaps) > 0 {
+		logger.Separator()
+		logger.Warning("Gaps found:")
+		for _, gap := range gaps {
+			logger.Info("  • %s: %s", gap.Name, gap.Detail)
+		}
+	}
+
+	logger.Separator()
+	switch {
+	case score == 100:
+		logger.Success("✅ You could recover from losing this machine today")
+	case score >= 70:
+		logger.Warning("⚠ Recovery is likely possible, but fix the gaps above")
+	default:
+		logger.Failure("❌ Recovery would probably fail. Fix the gaps above")
+	}
+
+	if rehearseReportPath != "" {
+		var report strings.Builder
+		fmt.Fprintf(&report, "stashr disaster recovery rehearsal - %s\n", time.Now().Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&report, "Score: %d/100\n\n", score)
+		for _, check := range checks {
+			status := "PASS"
+			if !check.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(&report, "[%s] %s", status, check.Name)
+			if !check.Passed && check.Detail != "" {
+				fmt.Fprintf(&report, " - %s", check.Detail)
+			}
+			report.WriteString("\n")
+		}
+
+		if err := os.WriteFile(rehearseReportPath, []byte(report.String()), 0600); err != nil {
+			logger.Warning("Failed to write report: %v", err)
+		} else {
+			logger.Info("Report written to: %s", rehearseReportPath)
+		}
+	}
+}
+
+// detectManager determines the password manager from a backup filename
+func detectManager(names *backupname.Parser, filename string) string {
+	if manager := names.Parse(filename).Manager; manager != "" {
+		return manager
+	}
+	return "unknown"
+}
+
+// enabledManagerNames returns the names of all enabled password managers
+func enabledManagerNames(cfg *config.Config) []string {
+	var names []string
+	if cfg.PasswordManagers.Bitwarden.Enabled {
+		names = append(names, "bitwarden")
+	}
+	if cfg.PasswordManagers.OnePassword.Enabled {
+		names = append(names, "1password")
+	}
+	return names
+}
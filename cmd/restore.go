@@ -1,18 +1,34 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"os/user"
+	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 
+	"github.com/harshalranjhani/stashr/internal/backupname"
+	"github.com/harshalranjhani/stashr/internal/clipboard"
 	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
 	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/dictionary"
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
 	"github.com/harshalranjhani/stashr/internal/storage"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
@@ -28,6 +44,20 @@ var (
 	restorePreview       bool
 	restoreAutoDelete    bool
 	restoreAutoDeleteMin int
+	restoreChecksum      string
+	restoreKeyFile       string
+	restoreAs            string
+	restoreOutputMode    string
+	restoreOutputOwner   string
+	restoreForce         bool
+	restoreImport        bool
+	restoreVault         string
+	restoreOnConflict    string
+	restoreStdout        bool
+	restoreClipboard     bool
+	restoreEncrypted     string
+	restoreManager       string
+	restoreAllLatest     bool
 )
 
 // BackupWithSource combines a backup file with its source storage location
@@ -48,26 +78,92 @@ This command will:
 3. Decompress the data
 4. Save as readable JSON file
 
-You can then manually import the JSON file into your password manager.`,
+You can then manually import the JSON file into your password manager, or use
+--import to import the backup without writing the decrypted file:
+  Bitwarden  The current vault is backed up, then the items are imported (bw import)
+  1Password  Full exports are recreated item by item in the --vault vault (op item
+             create); --on-conflict decides what happens to items already there
+
+Use --dry-run with --import to see what would be imported.
+
+To keep the decrypted vault off the filesystem entirely, --stdout writes it to
+standard output for another command to read, and --to-clipboard copies it to
+the clipboard, which is cleared after --auto-delete-minutes.
+
+--latest picks the newest backup of any password manager; add --manager to pick
+the newest of one. --all-latest restores the newest backup of every manager in
+one pass, into the --output directory.
+
+To move the backup to another machine before importing it, --output-encrypted
+writes it into a password-protected container instead of a plaintext file:
+  zip     An AES-256 ZIP archive, opened with 7-Zip, WinZip, Keka and most archivers
+  stashr  A file encrypted like stashr backups, opened with "stashr crypt decrypt"`,
 	Run: runRestore,
 }
 
 func init() {
 	rootCmd.AddCommand(restoreCmd)
 
-	restoreCmd.Flags().StringVarP(&restoreSource, "source", "s", "", "Source to restore from (gdrive, usb, local)")
+	restoreCmd.Flags().StringVarP(&restoreSource, "source", "s", "", "Source to restore from (gdrive, onedrive, webdav, gcs, azure, s3, rclone, icloud, usb, local, git-annex, a profile or plugin name)")
 	restoreCmd.Flags().StringVarP(&restoreBackupFile, "file", "f", "", "Backup file name to restore")
-	restoreCmd.Flags().StringVarP(&restoreOutputPath, "output", "o", "", "Output path for decrypted file (default: current directory)")
+	restoreCmd.Flags().StringVarP(&restoreOutputPath, "output", "o", "", "Output path for decrypted file, or the directory to restore into with --all-latest (default: current directory)")
 	restoreCmd.Flags().BoolVar(&restoreDecryptOnly, "decrypt-only", false, "Only decrypt, don't list available backups")
 	restoreCmd.Flags().BoolVarP(&restoreLatest, "latest", "l", false, "Restore the most recent backup")
 	restoreCmd.Flags().StringVarP(&restoreBefore, "before", "b", "", "Restore latest backup before specified date (format: 2006-01-02)")
 	restoreCmd.Flags().BoolVarP(&restoreInteractive, "interactive", "i", false, "Interactive mode to select backup from list")
-	restoreCmd.Flags().BoolVar(&restorePreview, "preview", false, "Preview backup metadata without decrypting")
+	restoreCmd.Flags().StringVarP(&restoreManager, "manager", "m", "", "Only select backups of one password manager with --latest, --before, --interactive or --all-latest (bitwarden, 1password)")
+	restoreCmd.Flags().BoolVar(&restoreAllLatest, "all-latest", false, "Restore the most recent backup of every password manager into the --output directory")
+	restoreCmd.Flags().BoolVar(&restorePreview, "preview", false, "Preview backup metadata without decrypting the vault")
 	restoreCmd.Flags().BoolVar(&restoreAutoDelete, "auto-delete", false, "Auto-delete decrypted file after specified minutes")
 	restoreCmd.Flags().IntVar(&restoreAutoDeleteMin, "auto-delete-minutes", 5, "Minutes before auto-delete (default: 5)")
+	restoreCmd.Flags().StringVar(&restoreAs, "as", "json", "Output format (json, kdbx, "+strings.Join(convert.Formats, ", ")+"); alias: --format")
+	restoreCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "format" {
+			name = "as"
+		}
+		return pflag.NormalizedName(name)
+	})
+	restoreCmd.Flags().StringVar(&restoreOutputMode, "output-mode", "0600", "File mode of the decrypted output, in octal")
+	restoreCmd.Flags().StringVar(&restoreOutputOwner, "output-owner", "", "Owner of the decrypted output as user[:group] (default: current user)")
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "Write decrypted output into a world-writable directory such as /tmp")
+	restoreCmd.Flags().StringVarP(&restoreKeyFile, "encryption-key", "k", "", "Key file the backup was encrypted with (default: backup.encryption.key_file)")
+	restoreCmd.Flags().StringVar(&restoreChecksum, "checksum", "", "Restore the backup whose stored file has this SHA-256 checksum")
+	restoreCmd.Flags().BoolVar(&restoreImport, "import", false, "Import the backup into Bitwarden or 1Password instead of writing a file")
+	restoreCmd.Flags().StringVar(&restoreVault, "vault", "", "1Password vault to create the items in with --import")
+	restoreCmd.Flags().StringVar(&restoreOnConflict, "on-conflict", conflictSkip, "What --import does with 1Password items already in the vault ("+strings.Join(importConflictModes, ", ")+")")
+	restoreCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what --import would import without importing")
+	restoreCmd.MarkFlagsMutuallyExclusive("import", "output")
+	restoreCmd.MarkFlagsMutuallyExclusive("import", "preview")
+	restoreCmd.MarkFlagsMutuallyExclusive("import", "auto-delete")
+	restoreCmd.Flags().BoolVar(&restoreStdout, "stdout", false, "Write the decrypted output to standard output instead of a file")
+	restoreCmd.Flags().BoolVar(&restoreClipboard, "to-clipboard", false, "Copy the decrypted output to the clipboard instead of a file, clearing it after --auto-delete-minutes")
+	for _, flag := range []string{"output", "import", "preview", "auto-delete"} {
+		restoreCmd.MarkFlagsMutuallyExclusive("stdout", flag)
+		restoreCmd.MarkFlagsMutuallyExclusive("to-clipboard", flag)
+	}
+	restoreCmd.MarkFlagsMutuallyExclusive("stdout", "to-clipboard")
+	restoreCmd.Flags().StringVar(&restoreEncrypted, "output-encrypted", "", "Write the output into a password-protected container ("+strings.Join(encryptedContainers, ", ")+"; default: zip)")
+	restoreCmd.Flags().Lookup("output-encrypted").NoOptDefVal = containerZip
+	for _, flag := range []string{"stdout", "to-clipboard", "import", "preview", "auto-delete"} {
+		restoreCmd.MarkFlagsMutuallyExclusive("output-encrypted", flag)
+	}
+	for _, flag := range []string{"file", "checksum", "latest", "before", "interactive", "stdout", "to-clipboard", "import", "preview", "auto-delete"} {
+		restoreCmd.MarkFlagsMutuallyExclusive("all-latest", flag)
+	}
 }
 
 func runRestore(cmd *cobra.Command, args []string) {
+	// With --stdout the vault is the only output: messages and prompts go to stderr
+	vaultOutput := os.Stdout
+	if restoreStdout {
+		if term.IsTerminal(int(vaultOutput.Fd())) {
+			logger.Failure("--stdout would print your passwords on the terminal; pipe it into a command instead")
+			return
+		}
+		os.Stdout = os.Stderr
+		logger.SetOutput(os.Stderr)
+	}
+
 	logger.Header("🔓 Restore Backup")
 
 	// Load configuration
@@ -77,6 +173,59 @@ func runRestore(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// Validate the output format before touching any backup
+	if restoreAs != "json" && restoreAs != "kdbx" && !isConvertFormat(restoreAs) {
+		logger.Failure("Unknown output format: %s (use: json, kdbx, %s)", restoreAs, strings.Join(convert.Formats, ", "))
+		return
+	}
+	if restoreImport && restoreAs != "json" {
+		logger.Failure("--import imports the backup's JSON export and can't be combined with --as %s", restoreAs)
+		return
+	}
+	if !isImportConflictMode(restoreOnConflict) {
+		logger.Failure("Unknown --on-conflict: %s (use: %s)", restoreOnConflict, strings.Join(importConflictModes, ", "))
+		return
+	}
+	if restoreClipboard && restoreAs == "kdbx" {
+		logger.Failure("A KeePass database can't be copied to the clipboard; use --stdout or write a file")
+		return
+	}
+	if restoreEncrypted != "" {
+		if !isEncryptedContainer(restoreEncrypted) {
+			logger.Failure("Unknown --output-encrypted: %s (use: %s)", restoreEncrypted, strings.Join(encryptedContainers, ", "))
+			return
+		}
+		if restoreEncrypted == containerZip && cfg.Backup.Encryption.FIPS {
+			logger.Failure("ZIP encryption is %v; backup.encryption.fips allows --output-encrypted=stashr only", crypto.ErrNotFIPSApproved)
+			return
+		}
+		if err := checkFIPSMode(cfg, config.EncryptionModePassword); err != nil {
+			logger.PrintError(err)
+			return
+		}
+	}
+	if restoreManager != "" && !restoreLatest && restoreBefore == "" && !restoreInteractive && !restoreAllLatest {
+		logger.Failure("--manager selects a backup with --latest, --before, --interactive or --all-latest")
+		return
+	}
+	if dryRun && !restoreImport {
+		logger.Failure("--dry-run previews --import; a restore without it only writes a file")
+		return
+	}
+	if dryRun {
+		printDryRunHeader()
+	}
+	perms, err := parseOutputPermissions(restoreOutputMode, restoreOutputOwner)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if restoreAllLatest {
+		restoreAllLatestBackups(cfg, perms)
+		return
+	}
+
 	// Determine which backup file to restore
 	selectedFile := restoreBackupFile
 	selectedSource := restoreSource
@@ -93,8 +242,8 @@ func runRestore(cmd *cobra.Command, args []string) {
 	}
 
 	// Validate that we have a file to restore
-	if selectedFile == "" {
-		logger.Failure("No backup file specified. Use --file, --latest, --before, or --interactive")
+	if selectedFile == "" && restoreChecksum == "" {
+		logger.Failure("No backup file specified. Use --file, --checksum, --latest, --before, or --interactive")
 		return
 	}
 
@@ -102,7 +251,15 @@ func runRestore(cmd *cobra.Command, args []string) {
 	var backupData []byte
 	var sourceName string
 
-	if selectedSource == "" {
+	if restoreChecksum != "" {
+		logger.Progress("Searching for backup with checksum: %s", restoreChecksum)
+		backupData, selectedFile, sourceName, err = findBackupByChecksum(cfg, restoreChecksum, selectedSource)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		logger.Success("✓ Found %s in %s (checksum verified)", selectedFile, sourceName)
+	} else if selectedSource == "" {
 		logger.Progress("Searching for backup file: %s", selectedFile)
 		backupData, sourceName, err = findBackupInAllSources(cfg, selectedFile)
 		if err != nil {
@@ -112,75 +269,149 @@ func runRestore(cmd *cobra.Command, args []string) {
 		logger.Success("✓ Found backup in %s", sourceName)
 	} else {
 		logger.Progress("Loading backup from %s...", selectedSource)
+		sourceName = selectedSource
 		backupData, err = downloadBackup(cfg, selectedSource, selectedFile)
+		// A backup selected by --latest, --before or --interactive is read from
+		// another destination if its source fails
+		if err != nil && restoreSource == "" {
+			logger.Warning("⚠ %s: %v", selectedSource, err)
+			logger.Progress("Searching other destinations for: %s", selectedFile)
+			backupData, sourceName, err = findBackupInAllSources(cfg, selectedFile)
+		}
 		if err != nil {
 			logger.PrintError(err)
 			return
 		}
-		sourceName = selectedSource
-		logger.Success("✓ Loaded backup")
+		logger.Success("✓ Loaded backup from %s", sourceName)
 	}
 
 	// Preview mode - show header info without decrypting
 	if restorePreview {
-		handlePreviewMode(backupData, selectedFile, sourceName)
+		handlePreviewMode(cfg, backupData, selectedFile, sourceName, newBackupNameParser(cfg))
+		return
+	}
+
+	// Determine output path
+	outputPath := restoreOutputPath
+	if outputPath == "" {
+		outputPath = filepath.Join(".", restoreOutputName(cfg, selectedFile))
+	}
+
+	// Refuse other managers' backups and shared directories before anything is decrypted
+	if restoreImport {
+		manager := newBackupNameParser(cfg).Parse(selectedFile).Manager
+		if manager != "" && manager != "bitwarden" && manager != "1password" {
+			logger.Failure("--import only imports Bitwarden and 1Password backups; %s is a %s backup", selectedFile, managerDisplayName(manager))
+			return
+		}
+		if manager == "1password" && restoreVault == "" {
+			logger.Failure("--import of a 1Password backup needs --vault, the vault to create the items in")
+			return
+		}
+	} else if restoreClipboard {
+		if err := clipboard.Available(); err != nil {
+			logger.PrintError(clipboardError(err))
+			return
+		}
+	} else if restoreStdout {
+		// Nothing is written to the filesystem
+	} else if err := checkOutputDir(outputPath); err != nil && !restoreForce {
+		logger.PrintError(err)
+		logger.Info("Choose another --output path, or pass --force to write there anyway")
 		return
 	}
 
-	// Get encryption password
-	fmt.Print("Enter encryption password: ")
-	password, err := utils.PromptForPassword("")
+	if err := checkFIPSFile(cfg, selectedFile, backupData); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// Encryption is detected from the content, whatever the file is named
+	decryptedData, password, err := decryptRestoredBackup(cfg, backupData, nil)
+	defer func() { password.Destroy() }()
 	if err != nil {
 		logger.PrintError(err)
 		return
 	}
-	if password == "" {
-		logger.Failure("Encryption password is required")
+
+	finalData, err := decompressRestoredBackup(cfg, decryptedData)
+	if err != nil {
+		logger.PrintError(err)
 		return
 	}
 
-	// Decrypt backup
-	logger.Progress("Decrypting backup...")
-	decryptedData, err := crypto.Decrypt(backupData, password)
+	// Convert to a KeePass database or another import format if requested
+	exportData := finalData
+	finalData, err = convertRestoredBackup(finalData, password)
 	if err != nil {
-		logger.Failure("Failed to decrypt: %v", err)
-		logger.Info("Make sure you're using the correct encryption password")
+		logger.PrintError(err)
 		return
 	}
-	logger.Success("✓ Decrypted successfully")
 
-	// Decompress if needed
-	var finalData []byte
-	if cfg.Backup.Compression {
-		logger.Progress("Decompressing data...")
-		decompressedData, err := utils.DecompressData(decryptedData)
+	if restoreImport {
+		imported, err := importRestoredBackup(cfg, selectedFile, finalData)
+		// The vault is in plaintext in memory until wiped
+		secret.Wipe(decryptedData)
+		secret.Wipe(finalData)
 		if err != nil {
-			logger.Warning("Failed to decompress: %v", err)
-			logger.Info("Backup may not be compressed, using decrypted data as-is")
-			finalData = decryptedData
+			logger.PrintError(err)
+			return
+		}
+		if imported {
+			markChecklistItem(checklistTestRestore)
+			logger.Separator()
+			logger.Success("✅ Backup imported!")
+		}
+		return
+	}
+
+	if restoreStdout || restoreClipboard {
+		if restoreStdout {
+			_, err = vaultOutput.Write(finalData)
 		} else {
-			finalData = decompressedData
-			logger.Success("✓ Decompressed successfully")
+			err = copyToClipboard(finalData)
 		}
-	} else {
-		finalData = decryptedData
+		secret.Wipe(decryptedData)
+		secret.Wipe(exportData)
+		secret.Wipe(finalData)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		markChecklistItem(checklistTestRestore)
+		if restoreStdout {
+			logger.Success("✓ Decrypted output written to standard output")
+			return
+		}
+		logger.Success("✓ Decrypted output copied to the clipboard")
+		handleClipboardClear(restoreAutoDeleteMin)
+		return
 	}
 
-	// Determine output path
-	outputPath := restoreOutputPath
-	if outputPath == "" {
-		// Remove .enc extension and use current directory
-		baseName := strings.TrimSuffix(selectedFile, ".enc")
-		outputPath = filepath.Join(".", baseName)
+	if restoreEncrypted != "" {
+		restoreEncryptedOutput(cfg, outputPath, finalData, password, perms)
+		secret.Wipe(decryptedData)
+		secret.Wipe(exportData)
+		secret.Wipe(finalData)
+		return
 	}
 
 	// Write output file
 	logger.Progress("Writing output file...")
-	if err := os.WriteFile(outputPath, finalData, 0600); err != nil {
+	err = writeRestoredFile(outputPath, finalData, perms)
+	// The vault is in plaintext in memory until wiped
+	secret.Wipe(decryptedData)
+	secret.Wipe(exportData)
+	secret.Wipe(finalData)
+	if err != nil {
 		logger.PrintError(err)
 		return
 	}
 	logger.Success("✓ Output written to: %s", outputPath)
+	if perms.mode&0077 != 0 {
+		logger.Warning("⚠ Output mode %04o lets other users read your passwords", perms.mode)
+	}
+	markChecklistItem(checklistTestRestore)
 
 	// Provide next steps
 	logger.Separator()
@@ -189,12 +420,23 @@ func runRestore(cmd *cobra.Command, args []string) {
 	logger.Info("Next steps:")
 
 	// Determine manager from filename
-	if strings.Contains(selectedFile, "bitwarden") {
+	if restoreAs == "kdbx" {
+		logger.Info("  1. Open the file in any KeePass client (KeePassXC, KeePass, KeeWeb, ...)")
+		logger.Info("  2. Unlock it with the KeePass database password you entered")
+		logger.Info("  3. File location: %s", outputPath)
+	} else if restoreAs == "chrome-csv" {
+		logger.Info("  1. Chrome: open chrome://password-manager/settings → Import passwords")
+		logger.Info("     Edge: open edge://wallet/passwords → Import passwords")
+		logger.Info("  2. Select the file: %s", outputPath)
+	} else if restoreAs != "json" {
+		logger.Info("  1. Import the %s file into your password manager", restoreAs)
+		logger.Info("  2. File location: %s", outputPath)
+	} else if manager := newBackupNameParser(cfg).Parse(selectedFile).Manager; manager == "bitwarden" {
 		logger.Info("  1. Open Bitwarden web vault or desktop app")
 		logger.Info("  2. Go to Tools → Import Data")
 		logger.Info("  3. Select 'Bitwarden (json)' as format")
 		logger.Info("  4. Upload the file: %s", outputPath)
-	} else if strings.Contains(selectedFile, "1password") {
+	} else if manager == "1password" {
 		logger.Info("  1. The JSON file contains your 1Password vault data")
 		logger.Info("  2. You can inspect it manually or use 1Password CLI:")
 		logger.Info("     op item create --vault <vault> --template <template> --title <title>")
@@ -230,79 +472,250 @@ func runRestore(cmd *cobra.Command, args []string) {
 	}
 }
 
-func findBackupInAllSources(cfg *config.Config, filename string) ([]byte, string, error) {
-	// Try local storage first (fastest)
-	if cfg.Storage.Local.Enabled {
-		local := storage.NewLocal(cfg.Storage.Local.BackupPath)
-		if data, err := local.Download(filename); err == nil {
-			return data, "Local Storage", nil
+// restoreOutputName returns the default name of a restored backup: its real
+// name without the encryption extension and destination subfolder, with the
+// extension of the --as format or the --output-encrypted container
+func restoreOutputName(cfg *config.Config, filename string) string {
+	// Backups uploaded under an obfuscated name are restored under their real one
+	baseName := newBackupNameParser(cfg).Original(filename)
+	for _, ext := range []string{".enc", ".age", ".gpg"} {
+		baseName = strings.TrimSuffix(baseName, ext)
+	}
+	if restoreAs != "json" {
+		for _, ext := range []string{".gz", ".zst", ".xz"} {
+			baseName = strings.TrimSuffix(baseName, ext)
 		}
+		baseName = strings.TrimSuffix(baseName, ".json") + convert.Extension(restoreAs)
+	} else if strings.HasSuffix(baseName, backupname.CanonicalExtension) {
+		baseName = strings.TrimSuffix(baseName, backupname.CanonicalExtension) + ".json"
+	}
+	if restoreEncrypted != "" {
+		baseName = containerEntryName(baseName, restoreAs) + containerExtension(restoreEncrypted)
 	}
+	return baseName
+}
 
-	// Try USB
-	if cfg.Storage.USB.Enabled {
-		usb := storage.NewUSB(cfg.Storage.USB.MountPath, cfg.Storage.USB.BackupDir)
-		if available, _ := usb.IsAvailable(); available {
-			if data, err := usb.Download(filename); err == nil {
-				return data, "USB Storage", nil
+// decryptRestoredBackup decrypts a backup, detecting its encryption from the
+// content. A password-encrypted backup is decrypted with known, a password that
+// decrypted an earlier backup, then the keychain password, then one asked for.
+// It returns the password that decrypted it, nil for other encryption.
+func decryptRestoredBackup(cfg *config.Config, backupData []byte, known *secret.Buffer) ([]byte, *secret.Buffer, error) {
+	switch {
+	case crypto.UsesKeyFile(backupData):
+		decryptedData, err := decryptKeyFileBackup(cfg, restoreKeyFile, backupData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+		return decryptedData, nil, nil
+
+	case crypto.IsEncrypted(backupData):
+		if known != nil {
+			if decryptedData, err := crypto.DecryptWithPassword(backupData, known); err == nil {
+				logger.Success("✓ Decrypted successfully")
+				return decryptedData, known, nil
 			}
 		}
-	}
 
-	// Try Google Drive
-	if cfg.Storage.GoogleDrive.Enabled {
-		gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, cfg.Storage.GoogleDrive.FolderID)
-		if available, _ := gdrive.IsAvailable(); available {
-			if data, err := gdrive.Download(filename); err == nil {
-				return data, "Google Drive", nil
+		// The keychain password is tried first; older backups may need another one
+		if stored, err := keychainPassword(cfg); err != nil {
+			logger.Warning("⚠ %v", err)
+		} else if stored != nil {
+			logger.Progress("Decrypting backup with the password from the OS keychain...")
+			if decryptedData, err := crypto.DecryptWithPassword(backupData, stored); err == nil {
+				logger.Success("✓ Decrypted successfully")
+				return decryptedData, stored, nil
 			}
+			stored.Destroy()
+			logger.Warning("⚠ The password from the OS keychain didn't decrypt this backup")
+		}
+
+		password, err := utils.PromptForSecret("Enter encryption password: ")
+		if err != nil {
+			return nil, nil, err
+		}
+		if password.Len() == 0 {
+			password.Destroy()
+			return nil, nil, fmt.Errorf("encryption password is required")
 		}
+
+		// Decrypt backup
+		logger.Progress("Decrypting backup...")
+		decryptedData, err := crypto.DecryptWithPassword(backupData, password)
+		if err != nil {
+			password.Destroy()
+			return nil, nil, fmt.Errorf("%w; make sure you're using the correct encryption password", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+		return decryptedData, password, nil
+
+	case publicKeyEncrypted(backupData):
+		decryptedData, err := decryptPublicKeyBackup(cfg, backupData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		logger.Success("✓ Decrypted successfully")
+		return decryptedData, nil, nil
+
+	default:
+		logger.Info("Backup is not encrypted")
+		return backupData, nil, nil
 	}
+}
 
-	return nil, "", fmt.Errorf("backup file '%s' not found in any storage location", filename)
+// decompressRestoredBackup decompresses decrypted backup data. Data that fails
+// to decompress is used as-is, unless it needs a compression dictionary.
+func decompressRestoredBackup(cfg *config.Config, decryptedData []byte) ([]byte, error) {
+	if !cfg.Backup.Compressed() && !isCompressedBackup(decryptedData) {
+		return decryptedData, nil
+	}
+
+	logger.Progress("Decompressing data...")
+	decompressedData, err := decompressBackup(cfg, decryptedData)
+	if _, ok := dictionary.HeaderID(decryptedData); ok && err != nil {
+		// Dictionary-compressed data can't be used as-is
+		return nil, err
+	}
+	if err != nil {
+		logger.Warning("Failed to decompress: %v", err)
+		logger.Info("Backup may not be compressed, using decrypted data as-is")
+		return decryptedData, nil
+	}
+	logger.Success("✓ Decompressed successfully")
+	return decompressedData, nil
 }
 
-func downloadBackup(cfg *config.Config, source, filename string) ([]byte, error) {
-	switch source {
-	case "local":
-		if !cfg.Storage.Local.Enabled {
-			return nil, fmt.Errorf("local storage is not enabled")
+// convertRestoredBackup converts a restored export to the --as format
+func convertRestoredBackup(data []byte, encryptionPassword *secret.Buffer) ([]byte, error) {
+	switch restoreAs {
+	case "json":
+		return data, nil
+	case "kdbx":
+		return convertToKDBX(data, encryptionPassword)
+	default:
+		return convertExport(data, restoreAs)
+	}
+}
+
+// convertToKDBX converts a decrypted export into a KeePass database
+func convertToKDBX(data []byte, encryptionPassword *secret.Buffer) ([]byte, error) {
+	logger.Progress("Converting to KeePass database...")
+	entries, err := convert.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vault data: %w", err)
+	}
+
+	kdbxPassword, err := utils.PromptForPassword("Enter a password for the KeePass database (leave empty to reuse the encryption password): ")
+	if err != nil {
+		return nil, err
+	}
+	if kdbxPassword == "" {
+		if encryptionPassword.Len() == 0 {
+			return nil, fmt.Errorf("a KeePass database password is required for unencrypted backups")
 		}
-		local := storage.NewLocal(cfg.Storage.Local.BackupPath)
-		return local.Download(filename)
+		kdbxPassword = string(encryptionPassword.Bytes())
+	} else {
+		confirmPassword, err := utils.PromptForPassword("Confirm KeePass database password: ")
+		if err != nil {
+			return nil, err
+		}
+		if kdbxPassword != confirmPassword {
+			return nil, fmt.Errorf("passwords do not match")
+		}
+	}
 
-	case "usb":
-		if !cfg.Storage.USB.Enabled {
-			return nil, fmt.Errorf("USB storage is not enabled")
+	var buf bytes.Buffer
+	if err := convert.WriteKDBX(&buf, entries, kdbxPassword); err != nil {
+		return nil, err
+	}
+	logger.Success("✓ Converted %d items to KeePass format", len(entries))
+
+	return buf.Bytes(), nil
+}
+
+func findBackupInAllSources(cfg *config.Config, filename string) ([]byte, string, error) {
+	var dests []storageDestination
+	for _, dest := range storageDestinations(cfg) {
+		if dest.enabled {
+			dests = append(dests, dest)
 		}
-		usb := storage.NewUSB(cfg.Storage.USB.MountPath, cfg.Storage.USB.BackupDir)
-		return usb.Download(filename)
+	}
 
-	case "gdrive":
-		if !cfg.Storage.GoogleDrive.Enabled {
-			return nil, fmt.Errorf("Google Drive storage is not enabled")
+	// Try the healthiest destinations first and, between equally healthy ones,
+	// on-disk destinations (fastest) before remote ones
+	scores := destinationHealthScores()
+	sort.SliceStable(dests, func(i, j int) bool {
+		si, sj := healthScore(scores, dests[i].name), healthScore(scores, dests[j].name)
+		if si != sj {
+			return si > sj
 		}
-		gdrive := storage.NewGoogleDrive(cfg.Storage.GoogleDrive.CredentialsPath, cfg.Storage.GoogleDrive.FolderID)
-		return gdrive.Download(filename)
+		return !dests[i].remote && dests[j].remote
+	})
 
-	default:
-		return nil, fmt.Errorf("unknown source: %s (use: local, usb, or gdrive)", source)
+	for _, dest := range dests {
+		backend := dest.create()
+		if available, _ := backend.IsAvailable(); !available {
+			continue
+		}
+		// A failed download here usually means the backup isn't on this
+		// destination, so only successes count towards its health
+		start := time.Now()
+		if data, err := backend.Download(filename); err == nil {
+			recordDestinationOp(backend, database.OperationDownload, start, nil)
+			return data, backend.Name(), nil
+		}
 	}
+
+	return nil, "", fmt.Errorf("backup file '%s' not found in any storage location", filename)
 }
 
-// handleSmartFileSelection handles --latest, --before, and --interactive flags
-func handleSmartFileSelection(cfg *config.Config) (string, string, error) {
-	// Collect all backups from all sources
-	allBackups := make(map[string][]storage.BackupFile)
+// findBackupByChecksum locates a backup by the SHA-256 checksum of its stored file.
+// The checksum recorded in the database is tried first; if the file was renamed or
+// never recorded, every backup in the selected sources is downloaded and hashed.
+func findBackupByChecksum(cfg *config.Config, checksum, source string) ([]byte, string, string, error) {
+	checksum = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(checksum), "sha256:"))
+	if len(checksum) != 64 || strings.Trim(checksum, "0123456789abcdef") != "" {
+		return nil, "", "", fmt.Errorf("invalid checksum: expected 64 hexadecimal characters")
+	}
 
-	storageBackends := getStorageBackendsForRestore(cfg)
-	if len(storageBackends) == 0 {
-		return "", "", fmt.Errorf("no storage backends available")
+	// Look up the recorded checksum first, on the destination whose copy has it
+	backupCopy, err := database.FindBackupCopyByChecksum(checksum)
+	if err != nil {
+		logger.Warning("Failed to query backup database: %v", err)
+	}
+	if backupCopy != nil && (source == "" || mapSourceToFlag(backupCopy.StorageType) == source) {
+		data, err := downloadBackup(cfg, mapSourceToFlag(backupCopy.StorageType), backupCopy.Filename)
+		if err == nil && utils.SHA256Hex(data) == checksum {
+			return data, backupCopy.Filename, backupCopy.StorageType, nil
+		}
+		logger.Warning("Recorded copy of %s on %s could not be verified, trying other destinations...", backupCopy.Filename, backupCopy.StorageType)
 	}
 
-	for _, backend := range storageBackends {
-		available, err := backend.IsAvailable()
-		if err != nil || !available {
+	// Backups made before copies were recorded have one checksum in their record
+	record, err := database.FindBackupByChecksum(checksum)
+	if err != nil {
+		logger.Warning("Failed to query backup database: %v", err)
+	}
+	if record != nil {
+		var data []byte
+		sourceName := source
+		if source != "" {
+			data, err = downloadBackup(cfg, source, record.Filename)
+		} else {
+			data, sourceName, err = findBackupInAllSources(cfg, record.Filename)
+		}
+		if err == nil && utils.SHA256Hex(data) == checksum {
+			return data, record.Filename, sourceName, nil
+		}
+		logger.Warning("Recorded backup %s could not be verified, scanning storage...", record.Filename)
+	}
+
+	// Fall back to hashing every backup in the selected sources
+	for _, backend := range getStorageBackendsForRestore(cfg) {
+		if source != "" && mapSourceToFlag(backend.Name()) != source {
+			continue
+		}
+		if available, _ := backend.IsAvailable(); !available {
 			continue
 		}
 
@@ -311,29 +724,41 @@ func handleSmartFileSelection(cfg *config.Config) (string, string, error) {
 			continue
 		}
 
-		allBackups[backend.Name()] = backups
-	}
-
-	// Flatten all backups into a single list with source info
-	var flatBackups []BackupWithSource
-
-	for sourceName, backups := range allBackups {
 		for _, backup := range backups {
-			flatBackups = append(flatBackups, BackupWithSource{
-				Backup: backup,
-				Source: sourceName,
-			})
+			data, err := backend.Download(backup.Name)
+			if err != nil {
+				continue
+			}
+			if utils.SHA256Hex(data) == checksum {
+				return data, backup.Name, backend.Name(), nil
+			}
 		}
 	}
 
-	if len(flatBackups) == 0 {
-		return "", "", fmt.Errorf("no backups found")
+	return nil, "", "", fmt.Errorf("no backup with checksum %s found in any storage location", checksum)
+}
+
+func downloadBackup(cfg *config.Config, source, filename string) ([]byte, error) {
+	dest, err := findStorageDestination(cfg, source)
+	if err != nil {
+		return nil, err
+	}
+	if !dest.enabled {
+		return nil, fmt.Errorf("%s storage is not enabled", dest.name)
 	}
+	backend := dest.create()
+	start := time.Now()
+	data, err := backend.Download(filename)
+	recordDestinationOp(backend, database.OperationDownload, start, err)
+	return data, err
+}
 
-	// Sort by modification time (newest first)
-	sort.Slice(flatBackups, func(i, j int) bool {
-		return flatBackups[i].Backup.ModifiedTime.After(flatBackups[j].Backup.ModifiedTime)
-	})
+// handleSmartFileSelection handles --latest, --before, and --interactive flags
+func handleSmartFileSelection(cfg *config.Config) (string, string, error) {
+	flatBackups, err := listRestoreBackups(cfg)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Handle --latest flag
 	if restoreLatest {
@@ -367,28 +792,76 @@ func handleSmartFileSelection(cfg *config.Config) (string, string, error) {
 
 	// Handle --interactive flag
 	if restoreInteractive {
-		return handleInteractiveRestore(flatBackups)
+		return handleInteractiveRestore(flatBackups, newBackupNameParser(cfg))
 	}
 
 	return "", "", fmt.Errorf("no selection method specified")
 }
 
+// listRestoreBackups lists the backups on every reachable destination, newest
+// first, keeping only those of the --manager password manager if it is set
+func listRestoreBackups(cfg *config.Config) ([]BackupWithSource, error) {
+	// Collect all backups from all sources
+	allBackups := make(map[string][]storage.BackupFile)
+
+	storageBackends := getStorageBackendsForRestore(cfg)
+	if len(storageBackends) == 0 {
+		return nil, fmt.Errorf("no storage backends available")
+	}
+
+	for _, backend := range storageBackends {
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			continue
+		}
+
+		backups, err := backend.List()
+		if err != nil {
+			continue
+		}
+
+		allBackups[backend.Name()] = backups
+	}
+
+	// Flatten all backups into a single list with source info
+	var flatBackups []BackupWithSource
+	names := newBackupNameParser(cfg)
+
+	for sourceName, backups := range allBackups {
+		for _, backup := range backups {
+			if restoreManager != "" && detectManager(names, backup.Name) != restoreManager {
+				continue
+			}
+			flatBackups = append(flatBackups, BackupWithSource{
+				Backup: backup,
+				Source: sourceName,
+			})
+		}
+	}
+
+	if len(flatBackups) == 0 {
+		if restoreManager != "" {
+			return nil, fmt.Errorf("no %s backups found", managerDisplayName(restoreManager))
+		}
+		return nil, fmt.Errorf("no backups found")
+	}
+
+	// Sort by modification time (newest first)
+	sort.Slice(flatBackups, func(i, j int) bool {
+		return flatBackups[i].Backup.ModifiedTime.After(flatBackups[j].Backup.ModifiedTime)
+	})
+	return flatBackups, nil
+}
+
 // handleInteractiveRestore shows a menu of backups for the user to select
-func handleInteractiveRestore(backups []BackupWithSource) (string, string, error) {
+func handleInteractiveRestore(backups []BackupWithSource, names *backupname.Parser) (string, string, error) {
 	logger.Info("📋 Available Backups:")
 	logger.Separator()
 
 	// Group by manager
 	managerGroups := make(map[string][]BackupWithSource)
 	for _, item := range backups {
-		var manager string
-		if strings.Contains(item.Backup.Name, "bitwarden") {
-			manager = "Bitwarden"
-		} else if strings.Contains(item.Backup.Name, "1password") {
-			manager = "1Password"
-		} else {
-			manager = "Other"
-		}
+		manager := managerDisplayName(names.Parse(item.Backup.Name).Manager)
 		managerGroups[manager] = append(managerGroups[manager], item)
 	}
 
@@ -400,7 +873,11 @@ func handleInteractiveRestore(backups []BackupWithSource) (string, string, error
 		logger.Info("\n%s Backups:", manager)
 		for _, item := range items {
 			age := formatAge(time.Since(item.Backup.ModifiedTime))
-			logger.Info("  %d. %s", choiceNum, item.Backup.Name)
+			if original := names.Original(item.Backup.Name); original != path.Base(item.Backup.Name) {
+				logger.Info("  %d. %s (%s)", choiceNum, item.Backup.Name, original)
+			} else {
+				logger.Info("  %d. %s", choiceNum, item.Backup.Name)
+			}
 			logger.Info("     Source: %s | Size: %s | Age: %s",
 				item.Source,
 				utils.FormatBytes(item.Backup.Size),
@@ -411,7 +888,7 @@ func handleInteractiveRestore(backups []BackupWithSource) (string, string, error
 	}
 
 	logger.Separator()
-	fmt.Printf("Enter choice (1-%d): ", len(choices))
+	fmt.Print(i18n.Tf("Enter choice (1-%d): ", len(choices)))
 	var choice int
 	fmt.Scanln(&choice)
 
@@ -424,17 +901,41 @@ func handleInteractiveRestore(backups []BackupWithSource) (string, string, error
 	return selected.Backup.Name, mapSourceToFlag(selected.Source), nil
 }
 
-// handlePreviewMode shows backup metadata without decrypting
-func handlePreviewMode(backupData []byte, filename, source string) {
+// handlePreviewMode shows backup metadata without decrypting the vault
+func handlePreviewMode(cfg *config.Config, backupData []byte, filename, source string, names *backupname.Parser) {
 	logger.Info("🔍 Backup Preview (without decryption)")
 	logger.Separator()
 
 	logger.Info("File Information:")
 	logger.Info("  Name: %s", filename)
+	if original := names.Original(filename); original != path.Base(filename) {
+		logger.Info("  Original Name: %s", original)
+	}
 	logger.Info("  Source: %s", source)
 	logger.Info("  Size: %s", utils.FormatBytes(int64(len(backupData))))
 	logger.Separator()
 
+	if crypto.IsAgeEncrypted(backupData) {
+		logger.Info("Encryption Header:")
+		logger.Info("  Format: age encrypted backup (age-encryption.org/v1)")
+		types, err := crypto.AgeRecipientTypes(backupData)
+		if err != nil {
+			logger.Warning("  %v", err)
+			return
+		}
+		logger.Info("  Recipients: %d (%s)", len(types), strings.Join(types, ", "))
+		logger.Separator()
+		previewBackupName(filename, names)
+		return
+	}
+	if crypto.IsGPGEncrypted(backupData) {
+		logger.Info("Encryption Header:")
+		logger.Info("  Format: OpenPGP message (see its recipients with: gpg --list-packets)")
+		logger.Separator()
+		previewBackupName(filename, names)
+		return
+	}
+
 	// Try to read header information
 	if len(backupData) < 60 {
 		logger.Warning("File too small to contain valid header")
@@ -460,45 +961,244 @@ func handlePreviewMode(backupData []byte, filename, source string) {
 	// Read algorithm
 	algorithm := uint16(backupData[6])<<8 | uint16(backupData[7])
 	algorithmName := "Unknown"
-	if algorithm == 1 {
+	switch algorithm {
+	case 1:
 		algorithmName = "AES-256-GCM"
+		if version >= 3 {
+			algorithmName = "AES-256-GCM (random data key wrapped by the password)"
+		}
+	case 2:
+		algorithmName = "AES-256-GCM (deduplicated chunks)"
+	case 3:
+		algorithmName = "AES-256-GCM (key file)"
 	}
 	logger.Info("  Algorithm: %s", algorithmName)
+	if id, ok := crypto.BackupKeyID(backupData); ok {
+		logger.Info("  Key file: %s", id)
+	}
+	if iterations, err := crypto.KeyIterations(backupData); err == nil {
+		logger.Info("  Key derivation: PBKDF2-SHA256, %d iterations", iterations)
+	}
+	hasMetadata := crypto.HasMetadata(backupData)
+	if hasMetadata {
+		logger.Info("  Metadata: encrypted")
+	}
 
 	logger.Separator()
+	if hasMetadata {
+		if metadata := readPreviewMetadata(cfg, backupData); metadata != nil {
+			previewMetadata(metadata)
+			previewDecryptHint(filename)
+			return
+		}
+		logger.Separator()
+	}
+	previewBackupName(filename, names)
+}
 
-	// Determine manager from filename
-	var manager string
-	if strings.Contains(filename, "bitwarden") {
-		manager = "Bitwarden"
-	} else if strings.Contains(filename, "1password") {
-		manager = "1Password"
-	} else {
-		manager = "Unknown"
-	}
-	logger.Info("Detected Manager: %s", manager)
-
-	// Extract timestamp from filename
-	if strings.Contains(filename, "_") {
-		parts := strings.Split(filename, "_")
-		if len(parts) >= 3 {
-			dateStr := parts[len(parts)-2]
-			timeStr := strings.TrimSuffix(parts[len(parts)-1], ".json.enc")
-			if len(dateStr) == 8 && len(timeStr) == 6 {
-				timestamp, err := time.Parse("20060102_150405", dateStr+"_"+timeStr)
-				if err == nil {
-					logger.Info("Backup Date: %s", timestamp.Format("2006-01-02 15:04:05"))
-					logger.Info("Backup Age: %s", formatAge(time.Since(timestamp)))
-				}
-			}
+// readPreviewMetadata decrypts the metadata of a backup with the keychain
+// password, the key file or a password prompt; nil if it isn't available
+func readPreviewMetadata(cfg *config.Config, backupData []byte) *crypto.Metadata {
+	if crypto.UsesKeyFile(backupData) {
+		path := resolveKeyFile(cfg, restoreKeyFile)
+		if path == "" {
+			logger.Info("💡 Pass the backup's key file with --encryption-key to show its metadata")
+			return nil
+		}
+		key, err := loadKeyFile(path)
+		if err != nil {
+			logger.Warning("⚠ %v", err)
+			return nil
+		}
+		defer secret.Wipe(key)
+		metadata, err := crypto.ReadMetadataWithKey(backupData, key)
+		if err != nil {
+			logger.Warning("⚠ Couldn't read the metadata: %v", err)
+			return nil
 		}
+		return metadata
 	}
 
+	if stored, err := keychainPassword(cfg); err == nil && stored != nil {
+		metadata, err := crypto.ReadMetadata(backupData, stored)
+		stored.Destroy()
+		if err == nil {
+			return metadata
+		}
+	}
+	password, err := utils.PromptForSecret("Enter encryption password to show the backup's metadata (leave empty to skip): ")
+	if err != nil {
+		logger.Warning("⚠ %v", err)
+		return nil
+	}
+	defer password.Destroy()
+	if password.Len() == 0 {
+		return nil
+	}
+	metadata, err := crypto.ReadMetadata(backupData, password)
+	if err != nil {
+		logger.Warning("⚠ Couldn't read the metadata: %v", err)
+		return nil
+	}
+	return metadata
+}
+
+// previewMetadata shows the metadata recorded in a backup's header
+func previewMetadata(metadata *crypto.Metadata) {
+	logger.Info("Backup Metadata:")
+	if metadata.Filename != "" {
+		logger.Info("  Original Name: %s", metadata.Filename)
+	}
+	logger.Info("  Manager: %s", managerDisplayName(metadata.Manager))
+	logger.Info("  Backup Date: %s", metadata.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	logger.Info("  Backup Age: %s", formatAge(time.Since(metadata.CreatedAt)))
+	if metadata.ItemCount > 0 {
+		logger.Info("  Items: %d", metadata.ItemCount)
+	}
+	logger.Info("  Vault Size: %s (uncompressed)", utils.FormatBytes(metadata.UncompressedSize))
+	if metadata.Compression != "" {
+		logger.Info("  Compression: %s", metadata.Compression)
+	}
+	if metadata.AppVersion != "" {
+		logger.Info("  Created With: stashr %s", metadata.AppVersion)
+	}
 	logger.Separator()
+}
+
+// previewBackupName shows what a backup's filename says about it, and how to decrypt it
+func previewBackupName(filename string, names *backupname.Parser) {
+	// Determine manager and backup time from filename
+	name := names.Parse(filename)
+	logger.Info("Detected Manager: %s", managerDisplayName(name.Manager))
+	if name.HasTimestamp() {
+		logger.Info("Backup Date: %s", name.Timestamp.Format("2006-01-02 15:04:05"))
+		logger.Info("Backup Age: %s", formatAge(time.Since(name.Timestamp)))
+	}
+	if name.Format != backupname.FormatUnknown {
+		logger.Info("Naming Format: %s", name.Format)
+	}
+
+	logger.Separator()
+	previewDecryptHint(filename)
+}
+
+// previewDecryptHint shows how to decrypt a previewed backup
+func previewDecryptHint(filename string) {
 	logger.Info("To decrypt this backup, run:")
 	logger.Info("  stashr restore --file %s", filename)
 }
 
+// outputPermissions is the mode and owner a decrypted file is written with
+type outputPermissions struct {
+	mode os.FileMode
+	// uid and gid are -1 to keep the current user and group
+	uid, gid int
+}
+
+// parseOutputPermissions parses an octal file mode and a user[:group] owner
+func parseOutputPermissions(mode, owner string) (outputPermissions, error) {
+	perms := outputPermissions{uid: -1, gid: -1}
+
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 0777 {
+		return perms, fmt.Errorf("invalid output mode %q (use an octal mode such as 0600)", mode)
+	}
+	perms.mode = os.FileMode(value)
+	if perms.mode&0400 == 0 {
+		return perms, fmt.Errorf("output mode %04o doesn't let the owner read the file", perms.mode)
+	}
+
+	if owner == "" {
+		return perms, nil
+	}
+	if runtime.GOOS == "windows" {
+		return perms, fmt.Errorf("--output-owner is not supported on Windows")
+	}
+
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+	if userName != "" {
+		if perms.uid, err = lookupUID(userName); err != nil {
+			return perms, fmt.Errorf("unknown output owner %q: %w", userName, err)
+		}
+	}
+	if hasGroup && groupName != "" {
+		if perms.gid, err = lookupGID(groupName); err != nil {
+			return perms, fmt.Errorf("unknown output group %q: %w", groupName, err)
+		}
+	}
+
+	return perms, nil
+}
+
+// lookupUID resolves a user given by name or numeric ID
+func lookupUID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGID resolves a group given by name or numeric ID
+func lookupGID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// checkOutputDir returns an error if the output directory is writable by every
+// user, where others could read or swap out the decrypted file
+func checkOutputDir(outputPath string) error {
+	// Windows reports every directory as 0777
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir := filepath.Dir(outputPath)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("output directory %s: %w", dir, err)
+	}
+	if info.Mode().Perm()&0002 != 0 {
+		return fmt.Errorf("refusing to write decrypted output to %s: the directory is writable by every user", dir)
+	}
+
+	return nil
+}
+
+// writeRestoredFile writes decrypted data, applying the mode and owner before any
+// data is written so it is never readable with broader permissions
+func writeRestoredFile(path string, data []byte, perms outputPermissions) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// An existing file keeps its mode on open, so set it explicitly
+	if err := file.Chmod(perms.mode); err != nil {
+		return fmt.Errorf("failed to set output mode: %w", err)
+	}
+	if perms.uid != -1 || perms.gid != -1 {
+		if err := file.Chown(perms.uid, perms.gid); err != nil {
+			return fmt.Errorf("failed to set output owner: %w", err)
+		}
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
 // handleAutoDelete schedules auto-deletion of the decrypted file
 func handleAutoDelete(filepath string, minutes int) {
 	logger.Warning("⚠️  SECURITY: Auto-delete enabled")
@@ -529,31 +1229,53 @@ func handleAutoDelete(filepath string, minutes int) {
 	}
 }
 
-// getStorageBackendsForRestore returns all available storage backends
-func getStorageBackendsForRestore(cfg *config.Config) []storage.Storage {
-	var backends []storage.Storage
+// copyToClipboard copies decrypted output to the clipboard
+func copyToClipboard(data []byte) error {
+	if err := clipboard.Copy(data); err != nil {
+		return clipboardError(err)
+	}
+	return nil
+}
 
-	if cfg.Storage.GoogleDrive.Enabled {
-		backends = append(backends, storage.NewGoogleDrive(
-			cfg.Storage.GoogleDrive.CredentialsPath,
-			cfg.Storage.GoogleDrive.FolderID,
-		))
+// clipboardError explains a failure to use the clipboard
+func clipboardError(err error) error {
+	if errors.Is(err, clipboard.ErrUnavailable) {
+		return fmt.Errorf("%w: install wl-clipboard, xclip or xsel, or use --stdout", err)
 	}
+	return fmt.Errorf("failed to copy to the clipboard: %w", err)
+}
 
-	if cfg.Storage.USB.Enabled {
-		backends = append(backends, storage.NewUSB(
-			cfg.Storage.USB.MountPath,
-			cfg.Storage.USB.BackupDir,
-		))
+// handleClipboardClear waits for the given minutes, or until Enter is pressed, and
+// clears the clipboard. Clipboard managers may have kept their own copy.
+func handleClipboardClear(minutes int) {
+	logger.Warning("⚠️  SECURITY: The clipboard holds your passwords until it is cleared")
+	logger.Info("It will be cleared in %d minute(s); paste it where it's needed, then press Enter to clear it now", minutes)
+	logger.Info("Clipboard history tools may keep their own copy")
+
+	// Without a terminal there is no Enter to wait for
+	entered := make(chan struct{})
+	if utils.IsTerminal() {
+		go func() {
+			bufio.NewReader(os.Stdin).ReadString('\n')
+			close(entered)
+		}()
+	}
+	select {
+	case <-entered:
+	case <-time.After(time.Duration(minutes) * time.Minute):
 	}
 
-	if cfg.Storage.Local.Enabled {
-		backends = append(backends, storage.NewLocal(
-			cfg.Storage.Local.BackupPath,
-		))
+	if err := clipboard.Clear(); err != nil {
+		logger.Failure("Failed to clear the clipboard: %v", err)
+		logger.Info("Copy something else to replace it")
+		return
 	}
+	logger.Success("✓ Clipboard cleared")
+}
 
-	return backends
+// getStorageBackendsForRestore returns all available storage backends
+func getStorageBackendsForRestore(cfg *config.Config) []storage.Storage {
+	return selectStorageBackends(cfg, "all")
 }
 
 // mapSourceToFlag maps storage backend name to command flag
@@ -561,11 +1283,28 @@ func mapSourceToFlag(source string) string {
 	switch source {
 	case "Google Drive":
 		return "gdrive"
-	case "USB Storage":
+	case "USB", "USB Storage":
 		return "usb"
-	case "Local Storage":
+	case "Local", "Local Storage":
 		return "local"
+	case "git-annex":
+		return "git-annex"
+	case "OneDrive":
+		return "onedrive"
+	case "WebDAV":
+		return "webdav"
+	case "Google Cloud Storage":
+		return "gcs"
+	case "Azure Blob":
+		return "azure"
+	case "S3":
+		return "s3"
+	case "rclone":
+		return "rclone"
+	case "iCloud Drive":
+		return "icloud"
 	default:
-		return ""
+		// Storage plugins are selected by the name they report
+		return source
 	}
 }
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// restoreAllLatestBackups restores the newest backup of every password manager
+// into the --output directory. Each is read from the destination that has the
+// newest copy, falling back to the others. A password that decrypts one backup
+// is tried on the next before asking again, and the --output-encrypted
+// password is asked for once.
+func restoreAllLatestBackups(cfg *config.Config, perms outputPermissions) {
+	dir := restoreOutputPath
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.PrintError(fmt.Errorf("failed to create output directory: %w", err))
+		return
+	}
+	if err := checkOutputDir(filepath.Join(dir, "restore")); err != nil && !restoreForce {
+		logger.PrintError(err)
+		logger.Info("Choose another --output directory, or pass --force to write there anyway")
+		return
+	}
+
+	backups, err := listRestoreBackups(cfg)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// The list is newest first, so the first backup of a manager is its latest.
+	// Backups whose manager can't be told from the name are left out.
+	names := newBackupNameParser(cfg)
+	latest := make(map[string]BackupWithSource)
+	var managers []string
+	for _, item := range backups {
+		manager := names.Parse(item.Backup.Name).Manager
+		if _, ok := latest[manager]; ok || manager == "" {
+			continue
+		}
+		latest[manager] = item
+		managers = append(managers, manager)
+	}
+	if len(managers) == 0 {
+		logger.Failure("No backups of a known password manager found")
+		return
+	}
+	sort.Strings(managers)
+
+	var password, containerPw *secret.Buffer
+	defer func() {
+		password.Destroy()
+		containerPw.Destroy()
+	}()
+
+	logger.Info("Restoring the latest backup of %d password manager(s) into %s", len(managers), dir)
+	var written []string
+	failed := 0
+	for _, manager := range managers {
+		item := latest[manager]
+		logger.Separator()
+		logger.Info("%s: %s", managerDisplayName(manager), item.Backup.Name)
+		logger.Info("  Source: %s | Modified: %s | Size: %s", item.Source,
+			item.Backup.ModifiedTime.Format("2006-01-02 15:04:05"), utils.FormatBytes(item.Backup.Size))
+
+		outputPath := filepath.Join(dir, restoreOutputName(cfg, item.Backup.Name))
+		if err := restoreLatestBackup(cfg, item, outputPath, perms, &password, &containerPw); err != nil {
+			logger.Failure("✗ %s: %v", managerDisplayName(manager), err)
+			failed++
+			continue
+		}
+		logger.Success("✓ Output written to: %s", outputPath)
+		written = append(written, outputPath)
+	}
+
+	logger.Separator()
+	if len(written) > 0 {
+		markChecklistItem(checklistTestRestore)
+	}
+	if failed > 0 {
+		logger.Warning("⚠ Restored %d of %d backups; %d failed", len(written), len(managers), failed)
+	} else {
+		logger.Info("✅ Restored the latest backup of %d password manager(s)!", len(written))
+	}
+	if perms.mode&0077 != 0 && restoreEncrypted == "" && len(written) > 0 {
+		logger.Warning("⚠ Output mode %04o lets other users read your passwords", perms.mode)
+	}
+	if len(written) == 0 {
+		return
+	}
+
+	logger.Separator()
+	logger.Info("Files:")
+	for _, path := range written {
+		logger.Info("  %s", path)
+	}
+	if restoreEncrypted != "" {
+		if restoreEncrypted == containerZip {
+			logger.Info("Extract them with 7-Zip, WinZip, Keka or another archiver that supports AES")
+		} else {
+			logger.Info("Decrypt them with: stashr crypt decrypt <file>")
+		}
+		return
+	}
+
+	logger.Warning("⚠️  SECURITY WARNING: Decrypted files contain your passwords!")
+	logger.Info("Import them into your password managers, then delete them")
+	fmt.Println()
+	if utils.ConfirmPrompt(fmt.Sprintf("Delete the %d decrypted file(s) now?", len(written))) {
+		for _, path := range written {
+			if err := os.Remove(path); err != nil {
+				logger.Warning("Failed to delete %s: %v", path, err)
+			}
+		}
+		logger.Success("✓ Decrypted files deleted")
+	}
+}
+
+// restoreLatestBackup restores one backup for --all-latest. password holds the
+// password that decrypted the previous backup and containerPw the
+// --output-encrypted password, asked for with the first backup.
+func restoreLatestBackup(cfg *config.Config, item BackupWithSource, outputPath string, perms outputPermissions, password, containerPw **secret.Buffer) error {
+	logger.Progress("Loading backup from %s...", item.Source)
+	backupData, err := downloadBackup(cfg, mapSourceToFlag(item.Source), item.Backup.Name)
+	if err != nil {
+		logger.Warning("⚠ %s: %v", item.Source, err)
+		logger.Progress("Searching other destinations for: %s", item.Backup.Name)
+		if backupData, _, err = findBackupInAllSources(cfg, item.Backup.Name); err != nil {
+			return err
+		}
+	}
+	if err := checkFIPSFile(cfg, item.Backup.Name, backupData); err != nil {
+		return err
+	}
+
+	decryptedData, used, err := decryptRestoredBackup(cfg, backupData, *password)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe(decryptedData)
+	if used != nil && used != *password {
+		(*password).Destroy()
+		*password = used
+	}
+
+	exportData, err := decompressRestoredBackup(cfg, decryptedData)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe(exportData)
+	finalData, err := convertRestoredBackup(exportData, used)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe(finalData)
+
+	if restoreEncrypted != "" {
+		if *containerPw == nil {
+			if *containerPw, err = containerPassword(cfg, restoreEncrypted, used); err != nil {
+				return err
+			}
+		}
+		if finalData, err = encryptRestoredOutput(cfg, restoreEncrypted, containerEntryName(outputPath, restoreAs), finalData, *containerPw); err != nil {
+			return err
+		}
+	}
+
+	logger.Progress("Writing output file...")
+	return writeRestoredFile(outputPath, finalData, perms)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// Containers --output-encrypted writes the restored data into
+const (
+	// containerZip is a WinZip AES-256 ZIP archive, which common archivers open
+	containerZip = "zip"
+	// containerStashr is a stashr encrypted file, read by "stashr crypt decrypt"
+	containerStashr = "stashr"
+)
+
+// encryptedContainers lists the --output-encrypted values
+var encryptedContainers = []string{containerZip, containerStashr}
+
+// isEncryptedContainer reports whether container is an --output-encrypted value
+func isEncryptedContainer(container string) bool {
+	for _, c := range encryptedContainers {
+		if c == container {
+			return true
+		}
+	}
+	return false
+}
+
+// containerExtension returns the file extension of an encrypted container
+func containerExtension(container string) string {
+	if container == containerZip {
+		return ".zip"
+	}
+	return cryptExtension
+}
+
+// encryptRestoredOutput encrypts restored data into a container under the
+// password. The ZIP holds the data as a single file named entryName.
+func encryptRestoredOutput(cfg *config.Config, container, entryName string, data []byte, password *secret.Buffer) ([]byte, error) {
+	if container == containerZip {
+		logger.Progress("Encrypting into a ZIP archive (AES-256)...")
+		return crypto.EncryptZip(entryName, data, password, time.Now())
+	}
+	logger.Progress("Encrypting into a stashr encrypted file...")
+	return crypto.EncryptWithPassword(data, password, cfg.Backup.Encryption.KeyIterations(), nil)
+}
+
+// restoreEncryptedOutput writes restored data into the --output-encrypted
+// container at outputPath, so no plaintext copy reaches the filesystem
+func restoreEncryptedOutput(cfg *config.Config, outputPath string, data []byte, restorePassword *secret.Buffer, perms outputPermissions) {
+	password, err := containerPassword(cfg, restoreEncrypted, restorePassword)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	encrypted, err := encryptRestoredOutput(cfg, restoreEncrypted, containerEntryName(outputPath, restoreAs), data, password)
+	password.Destroy()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Encrypted successfully")
+
+	logger.Progress("Writing output file...")
+	if err := writeRestoredFile(outputPath, encrypted, perms); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Output written to: %s", outputPath)
+	markChecklistItem(checklistTestRestore)
+
+	logger.Separator()
+	logger.Info("✅ Backup restored into an encrypted container!")
+	logger.Separator()
+	logger.Info("Next steps:")
+	logger.Info("  1. Copy %s to the other machine", outputPath)
+	if restoreEncrypted == containerZip {
+		logger.Info("  2. Extract it with 7-Zip, WinZip, Keka or another archiver that supports AES")
+		logger.Info("     (the ZIP support built into Windows and macOS may not open it)")
+	} else {
+		logger.Info("  2. Decrypt it with: stashr crypt decrypt \"%s\"", filepath.Base(outputPath))
+	}
+	logger.Info("  3. Import the extracted file, then delete it: it holds your passwords in plaintext")
+	logger.Separator()
+}
+
+// containerPassword asks for the password of an encrypted container. An empty
+// answer reuses the password the backup was decrypted with.
+func containerPassword(cfg *config.Config, container string, restorePassword *secret.Buffer) (*secret.Buffer, error) {
+	kind := "ZIP"
+	if container == containerStashr {
+		kind = "encrypted file"
+	}
+	logger.Warning("⚠️  If you forget this password, the %s can't be opened", kind)
+	password, err := utils.PromptForSecret(fmt.Sprintf("Enter a password for the %s (leave empty to reuse the encryption password): ", kind))
+	if err != nil {
+		return nil, err
+	}
+	if password.Len() == 0 {
+		password.Destroy()
+		if restorePassword.Len() == 0 {
+			return nil, fmt.Errorf("a %s password is required for backups not encrypted with a password", kind)
+		}
+		reused := secret.New(restorePassword.Len())
+		copy(reused.Bytes(), restorePassword.Bytes())
+		return reused, nil
+	}
+
+	confirmPassword, err := utils.PromptForSecret(fmt.Sprintf("Confirm %s password: ", kind))
+	if err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	matches := password.Equal(confirmPassword)
+	confirmPassword.Destroy()
+	if !matches {
+		password.Destroy()
+		return nil, fmt.Errorf("passwords do not match")
+	}
+	if err := checkPasswordStrength(cfg, password); err != nil {
+		password.Destroy()
+		return nil, err
+	}
+	return password, nil
+}
+
+// containerEntryName returns the name the restored data has inside a ZIP: the
+// output file name without the container or compression extension, since the
+// data is decompressed
+func containerEntryName(outputPath, format string) string {
+	name := strings.TrimSuffix(filepath.Base(outputPath), containerExtension(containerZip))
+	for _, ext := range []string{".gz", ".zst", ".xz"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if filepath.Ext(name) == "" {
+		if format == "json" {
+			name += ".json"
+		} else {
+			name += convert.Extension(format)
+		}
+	}
+	return name
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// Ways to handle a 1Password item whose title and category are already in the
+// target vault, chosen with --on-conflict
+const (
+	conflictSkip      = "skip"
+	conflictRename    = "rename"
+	conflictDuplicate = "duplicate"
+)
+
+// importConflictModes lists the --on-conflict values
+var importConflictModes = []string{conflictSkip, conflictRename, conflictDuplicate}
+
+// isImportConflictMode reports whether mode is an --on-conflict value
+func isImportConflictMode(mode string) bool {
+	for _, m := range importConflictModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// isBitwardenExport reports whether decrypted vault data is an unencrypted
+// Bitwarden JSON export, the only format "bw import bitwardenjson" reads
+func isBitwardenExport(data []byte) bool {
+	var export struct {
+		Encrypted bool               `json:"encrypted"`
+		Items     *[]json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return false
+	}
+	return export.Items != nil && !export.Encrypted
+}
+
+// isOnePasswordExport reports whether decrypted vault data is a 1Password
+// export, an array of items
+func isOnePasswordExport(data []byte) bool {
+	var items []json.RawMessage
+	return json.Unmarshal(data, &items) == nil
+}
+
+// importRestoredBackup imports a restored backup straight into the password
+// manager it was made from, so the plaintext never lands in a file the user has
+// to clean up. It reports whether anything was imported; a dry run or declined
+// confirmation imports nothing without an error.
+func importRestoredBackup(cfg *config.Config, filename string, data []byte) (bool, error) {
+	switch {
+	case isBitwardenExport(data):
+		return importIntoBitwarden(cfg, data)
+	case isOnePasswordExport(data):
+		if restoreVault == "" {
+			return false, fmt.Errorf("--import of a 1Password backup needs --vault, the vault to create the items in")
+		}
+		return importIntoOnePassword(cfg, data)
+	default:
+		return false, fmt.Errorf("%s isn't a Bitwarden or 1Password export, which are the only backups --import reads", filename)
+	}
+}
+
+// importIntoBitwarden imports a Bitwarden export with "bw import". The current
+// vault is backed up first, since an import can't be undone.
+func importIntoBitwarden(cfg *config.Config, data []byte) (bool, error) {
+	count, err := managers.CountExportItems(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the restored backup: %w", err)
+	}
+
+	bw := newBitwarden(cfg)
+	if err := checkMigrationTarget(bw); err != nil {
+		return false, err
+	}
+	before, err := bw.GetItemCount()
+	if err != nil {
+		return false, fmt.Errorf("failed to count the items in the vault: %w", err)
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		if before > 0 {
+			plan.Add("Back up the current Bitwarden vault (%d items) with stashr backup", before)
+		}
+		plan.Add("Import %d items into Bitwarden", count)
+		plan.Print()
+		return false, nil
+	}
+
+	logger.Separator()
+	logger.Info("The vault has %d items. Bitwarden adds imported items next to them without", before)
+	logger.Info("merging, so items that are already there will be duplicated.")
+	if !utils.ConfirmPrompt(fmt.Sprintf("Import %d items into the Bitwarden vault?", count)) {
+		logger.Info("Import cancelled")
+		return false, nil
+	}
+
+	// An empty vault has nothing to lose, and an empty export would fail validation
+	if before > 0 {
+		logger.Progress("Backing up the current vault before importing...")
+		if err := runPreImportBackup(); err != nil {
+			return false, fmt.Errorf("%w; nothing was imported", err)
+		}
+		logger.Success("✓ Backed up the current vault")
+	}
+
+	if err := importBitwardenJSON(cfg, bw, data, count, before); err != nil {
+		return false, err
+	}
+	logger.Info("  Check the vault for duplicates: items it already had were imported again")
+	return true, nil
+}
+
+// importIntoOnePassword recreates the items of a full 1Password export in the
+// --vault vault, one "op item create" per item, since 1Password has no bulk
+// import. Items whose title and category are already in the vault are handled
+// as --on-conflict says. Existing items are never changed, so no backup of the
+// vault is taken first.
+func importIntoOnePassword(cfg *config.Config, data []byte) (bool, error) {
+	items, err := convert.OnePasswordImportItems(data)
+	if err != nil {
+		return false, err
+	}
+
+	op := newOnePassword(cfg)
+	if err := checkMigrationTarget(op); err != nil {
+		return false, err
+	}
+	existing, err := op.ListItems(restoreVault)
+	if err != nil {
+		return false, fmt.Errorf("failed to list the items in vault %s: %w", restoreVault, err)
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		taken[importConflictKey(item.Title, item.Category)] = true
+	}
+
+	var plan dryRunPlan
+	var create []convert.OnePasswordImportItem
+	skipped := 0
+	for _, item := range items {
+		if item.Unsupported != "" {
+			logger.Warning("⚠ Can't import %q (%s): %s", item.Title, managers.OnePasswordCategory(item.Category), item.Unsupported)
+			skipped++
+			continue
+		}
+
+		key := importConflictKey(item.Title, item.Category)
+		if taken[key] {
+			switch restoreOnConflict {
+			case conflictSkip:
+				plan.Add("Skip %q (%s): already in the vault", item.Title, managers.OnePasswordCategory(item.Category))
+				skipped++
+				continue
+			case conflictRename:
+				original := item.Title
+				for n := 1; taken[key]; n++ {
+					item.Title = fmt.Sprintf("%s (restored)", original)
+					if n > 1 {
+						item.Title = fmt.Sprintf("%s (restored %d)", original, n)
+					}
+					key = importConflictKey(item.Title, item.Category)
+				}
+				plan.Add("Create %q (%s) as %q: %q is already in the vault", original, managers.OnePasswordCategory(item.Category), item.Title, original)
+			case conflictDuplicate:
+				plan.Add("Create %q (%s) next to the one already in the vault", item.Title, managers.OnePasswordCategory(item.Category))
+			}
+		} else {
+			plan.Add("Create %q (%s)", item.Title, managers.OnePasswordCategory(item.Category))
+		}
+		taken[key] = true
+		create = append(create, item)
+	}
+
+	if dryRun {
+		plan.Print()
+		return false, nil
+	}
+	if len(create) == 0 {
+		logger.Info("Nothing to import: every item is already in vault %s or can't be imported", restoreVault)
+		return false, nil
+	}
+
+	logger.Separator()
+	if skipped > 0 {
+		logger.Info("%d of %d items are skipped; run with --dry-run to see each item", skipped, len(items))
+	}
+	if !utils.ConfirmPrompt(fmt.Sprintf("Create %d items in 1Password vault %s?", len(create), restoreVault)) {
+		logger.Info("Import cancelled")
+		return false, nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	op.SetContext(ctx)
+
+	logger.Progress("Creating %d items in 1Password vault %s...", len(create), restoreVault)
+	for i, item := range create {
+		template, err := item.Template()
+		if err == nil {
+			err = op.CreateItem(template, restoreVault)
+		}
+		if err != nil {
+			logger.Warning("⚠ Created %d of %d items; stopped at %q", i, len(create), item.Title)
+			if restoreOnConflict == conflictSkip {
+				logger.Info("Run the import again to create the rest: the items already created are skipped")
+			}
+			return false, err
+		}
+	}
+	logger.Success("✓ Created %d items in vault %s", len(create), restoreVault)
+	return true, nil
+}
+
+// importConflictKey identifies 1Password items that conflict: titles are
+// compared without case, within a category
+func importConflictKey(title, category string) string {
+	return category + "\x00" + strings.ToLower(strings.TrimSpace(title))
+}
+
+// runPreImportBackup backs up the Bitwarden vault in a separate "stashr backup"
+// process attached to the terminal, so it asks for passwords like a manual
+// backup and is encrypted and uploaded to every destination
+func runPreImportBackup() error {
+	command, err := stashrCommand("backup", "--ignore-conditions", "--manager", "bitwarden")
+	if err != nil {
+		return err
+	}
+
+	started := time.Now().Truncate(time.Second)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("backup of the current vault failed: %w", err)
+	}
+
+	// An unsuccessful backup can still exit cleanly, so look for the backup it recorded
+	records, err := database.ListBackups("bitwarden", "", nil)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 || records[0].CreatedAt.Before(started) {
+		return fmt.Errorf("backup of the current vault didn't complete")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+// retentionPolicy returns the global retention policy, backup.retention
+func retentionPolicy(cfg *config.Config) storage.RetentionPolicy {
+	return newRetentionPolicy(cfg.Backup.Retention)
+}
+
+// destinationRetentionPolicy returns the retention policy applied to a backend:
+// its own retention override if it has one, or else backup.retention
+func destinationRetentionPolicy(cfg *config.Config, backend storage.Storage) storage.RetentionPolicy {
+	if dest, ok := destinationForBackend(cfg, backend); ok && dest.retention != nil {
+		return newRetentionPolicy(*dest.retention)
+	}
+	return retentionPolicy(cfg)
+}
+
+// destinationTrashDays returns how many days the backups retention removes from
+// backend stay in its trash: retention.trash_days of its policy, or 0 if they are
+// deleted right away because it has none or the destination has no trash
+func destinationTrashDays(cfg *config.Config, backend storage.Storage) int {
+	if !storage.SupportsTrash(backend) {
+		return 0
+	}
+	return trashGraceDays(cfg, backend)
+}
+
+// trashGraceDays returns the trash_days of the retention policy applied to backend
+func trashGraceDays(cfg *config.Config, backend storage.Storage) int {
+	if dest, ok := destinationForBackend(cfg, backend); ok && dest.retention != nil {
+		return dest.retention.TrashDays
+	}
+	return cfg.Backup.Retention.TrashDays
+}
+
+// retentionRemover returns how retention removes a backup from backend: moved
+// to its trash if it keeps removed backups for a grace period, deleted otherwise
+func retentionRemover(cfg *config.Config, backend storage.Storage) func(string) error {
+	if destinationTrashDays(cfg, backend) > 0 {
+		return withSignature(func(filename string) error {
+			return storage.Trash(backend, filename)
+		})
+	}
+	return withSignature(backend.Delete)
+}
+
+// retentionRemoval describes what retention does with a backup it removes from backend
+func retentionRemoval(cfg *config.Config, backend storage.Storage, filename string) string {
+	if days := destinationTrashDays(cfg, backend); days > 0 {
+		return fmt.Sprintf("Move %s to the trash of %s (deleted after %d days)", filename, backend.Name(), days)
+	}
+	return fmt.Sprintf("Delete %s from %s", filename, backend.Name())
+}
+
+// newRetentionPolicy converts retention settings to the policy storage applies
+func newRetentionPolicy(r config.RetentionConfig) storage.RetentionPolicy {
+	return storage.RetentionPolicy{
+		KeepLast:    r.KeepLast,
+		KeepDays:    r.KeepDays,
+		KeepDaily:   r.KeepDaily,
+		KeepWeekly:  r.KeepWeekly,
+		KeepMonthly: r.KeepMonthly,
+		KeepYearly:  r.KeepYearly,
+	}
+}
+
+// backupManagerOf returns a function reading the manager of a backup from its
+// filename, so retention is applied to each manager's backups separately
+func backupManagerOf(cfg *config.Config) func(string) string {
+	names := newBackupNameParser(cfg)
+	return func(name string) string {
+		return names.Parse(name).Manager
+	}
+}
+
+// plannedRetentionDeletions returns the backups the retention policy of backend
+// would delete once the planned uploads are stored next to the existing backups
+func plannedRetentionDeletions(cfg *config.Config, backend storage.Storage, backups []storage.BackupFile, planned []string, now time.Time) []storage.BackupFile {
+	all := make([]storage.BackupFile, 0, len(backups)+len(planned))
+	all = append(all, backups...)
+	isPlanned := make(map[string]bool)
+	for _, name := range planned {
+		all = append(all, storage.BackupFile{Name: name, ModifiedTime: now})
+		isPlanned[name] = true
+	}
+
+	var deletions []storage.BackupFile
+	for _, backup := range storage.RetentionCandidates(all, destinationRetentionPolicy(cfg, backend), backupManagerOf(cfg), now) {
+		if !isPlanned[backup.Name] {
+			deletions = append(deletions, backup)
+		}
+	}
+	return deletions
+}
@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// spoolDirName is the directory in the config directory that keeps encrypted
+// backups no destination has, until their queued uploads succeed
+const spoolDirName = "spool"
+
+// retryQueueInterval is how often the daemon retries queued uploads
+const retryQueueInterval = 15 * time.Minute
+
+var (
+	retryList  bool
+	retryDrop  bool
+	retryForce bool
+)
+
+// nextUploadRetry is when the daemon next retries queued uploads
+var nextUploadRetry time.Time
+
+// unavailableBackends are the destinations the running backup's pre-flight
+// checks skipped. Their uploads are queued instead.
+var unavailableBackends []storage.Storage
+
+// errDeferredUpload is the queued error of an upload to an unavailable destination
+var errDeferredUpload = errors.New("not available during the backup")
+
+// retryCmd represents the retry command
+var retryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Retry uploads that failed during a backup",
+	Long: `Upload backups to the destinations that failed during a backup, e.g. a USB
+drive that wasn't plugged in. Failed uploads are queued by "stashr backup" and
+retried with the already-encrypted file, copied from a destination that has it,
+so nothing is exported or encrypted again. When no destination has the file, an
+encrypted copy is kept in ~/.stashr/spool until it is uploaded; unencrypted
+backups are never kept.
+
+The daemon retries queued uploads every 15 minutes.`,
+	Example: `  # Retry every queued upload
+  stashr retry
+
+  # Show the queued uploads
+  stashr retry --list
+
+  # Give up on the queued uploads and delete their spooled copies
+  stashr retry --drop`,
+	Args: cobra.NoArgs,
+	Run:  runRetry,
+}
+
+func init() {
+	rootCmd.AddCommand(retryCmd)
+
+	retryCmd.Flags().BoolVar(&retryList, "list", false, "List the queued uploads without retrying them")
+	retryCmd.Flags().BoolVar(&retryDrop, "drop", false, "Remove every queued upload and its spooled copy")
+	retryCmd.Flags().BoolVar(&retryForce, "force", false, "Break the lock of a backup that is no longer running")
+	retryCmd.MarkFlagsMutuallyExclusive("list", "drop")
+}
+
+func runRetry(cmd *cobra.Command, args []string) {
+	logger.Header("🔁 Retry Failed Uploads")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// A running backup may be queuing or uploading the same backups
+	if !retryList {
+		if err := acquireRunLock("retry", retryForce); err != nil {
+			logger.PrintError(err)
+			return
+		}
+		defer releaseRunLock()
+	}
+
+	pending, err := database.ListPendingUploads()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if len(pending) == 0 {
+		logger.Success("✓ No uploads are waiting to be retried")
+		return
+	}
+
+	if retryList {
+		for _, upload := range pending {
+			logger.Info("%s → %s", upload.Filename, upload.Destination)
+			logger.Info("  Queued %s, copied from %s, %d attempts", upload.CreatedAt.Local().Format("2006-01-02 15:04"), pendingUploadSource(upload), upload.Attempts)
+			if upload.LastError != "" {
+				logger.Info("  Last error: %s", upload.LastError)
+			}
+		}
+		return
+	}
+
+	if retryDrop {
+		for _, upload := range pending {
+			if err := dropPendingUpload(upload); err != nil {
+				logger.PrintError(err)
+				return
+			}
+		}
+		logger.Success("✓ Dropped %d queued upload(s)", len(pending))
+		return
+	}
+
+	if err := loadRetrySigner(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	uploaded := retryPendingUploads(cfg, pending)
+	logger.Separator()
+	if uploaded < len(pending) {
+		logger.Warning("⚠ Uploaded %d of %d queued backups; the rest stay queued", uploaded, len(pending))
+		return
+	}
+	logger.Success("✅ Uploaded %d queued backup(s)", uploaded)
+}
+
+// runRetryJob retries the queued uploads in daemon mode, if the retry interval
+// has passed since the last retry
+func runRetryJob(cfg *config.Config, now time.Time) {
+	if now.Before(nextUploadRetry) {
+		return
+	}
+	nextUploadRetry = now.Add(retryQueueInterval)
+
+	pending, err := database.ListPendingUploads()
+	if err != nil {
+		logger.Warning("Failed to read the upload queue: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	// Uploads are retried at the next interval while a backup holds the lock
+	if err := acquireRunLock("daemon", false); err != nil {
+		var lockErr *runLockError
+		if errors.As(err, &lockErr) {
+			logger.Info("Not retrying queued uploads: another backup is running")
+			return
+		}
+		logger.PrintError(err)
+		return
+	}
+	defer releaseRunLock()
+
+	if err := loadRetrySigner(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Progress("Retrying %d queued uploads...", len(pending))
+	retryPendingUploads(cfg, pending)
+}
+
+// loadRetrySigner loads the identity key retried backups are signed with, like
+// the backup that queued them
+func loadRetrySigner(cfg *config.Config) error {
+	backupSigner = nil
+	if !cfg.Backup.Signing.Enabled {
+		return nil
+	}
+	signer, err := signingIdentity()
+	if err != nil {
+		return err
+	}
+	backupSigner = signer
+	return nil
+}
+
+// queueFailedUploads queues the uploads of a backup that failed on their
+// destination. The file is copied from a destination that has it when it is
+// retried, or from the spool if none does.
+func queueFailedUploads(out *logger.Scope, manager string, uploads []backupUpload) {
+	spooled := make(map[*backupArtifact]string)
+	for _, upload := range uploads {
+		if !upload.retryable {
+			continue
+		}
+
+		var sources []string
+		for _, other := range uploads {
+			if other.artifact == upload.artifact && other.err == nil {
+				sources = append(sources, other.backend.Name())
+			}
+		}
+		pending := database.PendingUpload{
+			Filename:    upload.artifact.filename,
+			Manager:     manager,
+			Destination: upload.backend.Name(),
+			Sources:     sources,
+			Checksum:    utils.SHA256Hex(upload.artifact.data),
+			LastError:   upload.err.Error(),
+		}
+
+		if len(sources) == 0 {
+			// The export is never kept on disk unencrypted
+			if !upload.artifact.encrypted {
+				out.Warning("⚠ Not queuing the upload to %s: no destination has the backup and it isn't encrypted", upload.backend.Name())
+				continue
+			}
+			path, ok := spooled[upload.artifact]
+			if !ok {
+				var err error
+				path, err = spoolArtifact(upload.artifact)
+				if err != nil {
+					out.Warning("⚠ Not queuing the upload to %s: %v", upload.backend.Name(), err)
+					continue
+				}
+				spooled[upload.artifact] = path
+			}
+			pending.SpoolPath = path
+		}
+
+		if err := database.AddPendingUpload(pending); err != nil {
+			out.Warning("⚠ Failed to queue the upload to %s: %v", upload.backend.Name(), err)
+			continue
+		}
+		out.Info("  Queued the upload to %s: it is retried by 'stashr retry' and the daemon", upload.backend.Name())
+	}
+}
+
+// deferredBackends returns the unavailable destinations a manager's backup may
+// be uploaded to later
+func deferredBackends(cfg *config.Config, manager string) []storage.Storage {
+	var backends []storage.Storage
+	for _, backend := range unavailableBackends {
+		if checkPolicies(cfg, manager, backend) == nil {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// spoolArtifact keeps an encrypted artifact in the spool directory
+func spoolArtifact(artifact *backupArtifact) (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, spoolDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	// Folder layouts name files with slashes
+	path := filepath.Join(dir, strings.ReplaceAll(artifact.filename, "/", "_"))
+	if err := os.WriteFile(path, artifact.data, 0600); err != nil {
+		return "", fmt.Errorf("failed to spool backup: %w", err)
+	}
+	return path, nil
+}
+
+// retryPendingUploads uploads queued backups to their destinations and returns
+// how many succeeded. Uploads that fail again stay queued.
+func retryPendingUploads(cfg *config.Config, pending []database.PendingUpload) int {
+	backends := make(map[string]storage.Storage)
+	for _, backend := range selectStorageBackends(cfg, "all") {
+		backends[backend.Name()] = backend
+	}
+
+	uploaded := 0
+	for _, upload := range pending {
+		out := logger.WithPrefix(upload.Destination)
+		out.Progress("Uploading %s...", upload.Filename)
+		err := retryPendingUpload(out, cfg, backends, upload)
+		recordEvent(database.EventRecord{Kind: database.EventUpload, Manager: upload.Manager, StorageType: upload.Destination, Filename: upload.Filename}, err)
+		if err != nil {
+			out.Failure("✗ %v", err)
+			if err := database.RecordPendingUploadAttempt(upload.ID, err.Error()); err != nil {
+				out.Warning("Failed to record upload attempt: %v", err)
+			}
+			continue
+		}
+		uploaded++
+	}
+	return uploaded
+}
+
+// retryPendingUpload uploads one queued backup and removes it from the queue
+func retryPendingUpload(out *logger.Scope, cfg *config.Config, backends map[string]storage.Storage, upload database.PendingUpload) error {
+	backend, ok := backends[upload.Destination]
+	if !ok {
+		return fmt.Errorf("%s is no longer an enabled destination: remove it with 'stashr retry --drop' if it won't be", upload.Destination)
+	}
+	// A policy added since the backup would refuse it every time
+	if err := checkPolicies(cfg, upload.Manager, backend); err != nil {
+		if dropErr := dropPendingUpload(upload); dropErr != nil {
+			return dropErr
+		}
+		return fmt.Errorf("dropped the queued upload: %w", err)
+	}
+
+	data, err := pendingUploadData(backends, upload)
+	if err != nil {
+		return err
+	}
+	defer secret.Wipe(data)
+
+	if err := uploadToBackend(out, backend, upload.Filename, data, cfg); err != nil {
+		return err
+	}
+
+	if err := database.RecordBackupCopy(upload.Filename, upload.Destination, upload.Checksum, int64(len(data))); err != nil {
+		out.Warning("Failed to record backup checksum: %v", err)
+	}
+
+	// A backup no destination took during the backup is recorded now
+	record, err := database.GetBackup(upload.Filename)
+	if err != nil {
+		out.Warning("Failed to read backup record: %v", err)
+	} else if record == nil {
+		if err := database.RecordBackup(upload.Filename, upload.Manager, upload.Destination, int64(len(data)), nil, ""); err != nil {
+			out.Warning("Failed to record backup in database: %v", err)
+		} else if err := database.UpdateBackupChecksum(upload.Filename, upload.Checksum); err != nil {
+			out.Warning("Failed to record backup checksum: %v", err)
+		}
+	}
+	return dropPendingUpload(upload)
+}
+
+// pendingUploadData returns the queued backup from the spool or a destination
+// that has it, checked against the checksum of the file the backup created
+func pendingUploadData(backends map[string]storage.Storage, upload database.PendingUpload) ([]byte, error) {
+	if upload.SpoolPath != "" {
+		data, err := os.ReadFile(upload.SpoolPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spooled copy: %w", err)
+		}
+		if utils.SHA256Hex(data) != upload.Checksum {
+			return nil, fmt.Errorf("spooled copy %s doesn't match the backup's checksum", upload.SpoolPath)
+		}
+		return data, nil
+	}
+
+	var errs []string
+	for _, name := range upload.Sources {
+		source, ok := backends[name]
+		if !ok {
+			errs = append(errs, name+": no longer enabled")
+			continue
+		}
+		start := time.Now()
+		data, err := source.Download(upload.Filename)
+		recordDestinationOp(source, database.OperationDownload, start, err)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if utils.SHA256Hex(data) != upload.Checksum {
+			errs = append(errs, name+": copy doesn't match the backup's checksum")
+			continue
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("no copy of %s is available (%s)", upload.Filename, strings.Join(errs, "; "))
+}
+
+// pendingUploadSource describes where a queued backup is copied from
+func pendingUploadSource(upload database.PendingUpload) string {
+	if upload.SpoolPath != "" {
+		return "the local spool"
+	}
+	return strings.Join(upload.Sources, ", ")
+}
+
+// dropPendingUpload removes an upload from the queue, and its spooled copy once
+// no other queued upload needs it
+func dropPendingUpload(upload database.PendingUpload) error {
+	if err := database.DeletePendingUpload(upload.ID); err != nil {
+		return err
+	}
+	if upload.SpoolPath == "" {
+		return nil
+	}
+	remaining, err := database.ListPendingUploads()
+	if err != nil {
+		return err
+	}
+	for _, other := range remaining {
+		if other.SpoolPath == upload.SpoolPath {
+			return nil
+		}
+	}
+	if err := os.Remove(upload.SpoolPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spooled copy: %w", err)
+	}
+	return nil
+}
@@ -1,17 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
 	"github.com/harshalranjhani/stashr/internal/version"
 )
 
 var (
-	verbose bool
-	cfgFile string
+	verbose   bool
+	cfgFile   string
+	bwSession string
+	opSession string
+	opToken   string
+	noCache   bool
+	logFile   string
 )
 
 // rootCmd represents the base command
@@ -35,6 +44,15 @@ Supports Bitwarden, 1Password, Google Drive, USB, and local storage.`,
 		if verbose {
 			logger.SetVerbose(true)
 		}
+
+		// Select message language from the environment (config may override later)
+		if err := i18n.SetLanguage(i18n.DetectLanguage()); err != nil {
+			logger.Warning("%v", err)
+		}
+
+		configureLogging()
+		// Cloud destinations connect through the proxy when they're created
+		configureProxy()
 	},
 }
 
@@ -52,6 +70,32 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.stashr/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "also write output to this file, with secrets and personal data redacted (overrides logging.file)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "always download backups instead of reading them from the local cache")
+
+	// Manager credentials passed explicitly to the CLIs (for CI and containers)
+	rootCmd.PersistentFlags().StringVar(&bwSession, "bw-session", "", "Bitwarden session token (env: STASHR_BW_SESSION)")
+	rootCmd.PersistentFlags().StringVar(&opSession, "op-session", "", "1Password session token (env: STASHR_OP_SESSION)")
+	rootCmd.PersistentFlags().StringVar(&opToken, "op-token", "", "1Password service account token (env: STASHR_OP_TOKEN)")
+}
+
+// interruptContext returns a context cancelled on Ctrl+C or SIGTERM. After the first
+// signal the default handling is restored so a second Ctrl+C exits immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// flagOrEnv returns a flag value, falling back to an environment variable
+func flagOrEnv(value, env string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(env)
 }
 
 func initConfig() {
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+)
+
+// runLockName is the lock file in the config directory held by the running
+// backup or upload retry
+const runLockName = "backup.lock"
+
+// runLock is the content of the lock file, identifying the run that holds it
+type runLock struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Command  string    `json:"command"`
+	Started  time.Time `json:"started"`
+}
+
+// runLockError is returned when another run holds the lock
+type runLockError struct {
+	lock runLock
+	path string
+}
+
+func (e *runLockError) Error() string {
+	since := e.lock.Started.Local().Format("15:04")
+	if !sameDay(e.lock.Started.Local(), time.Now()) {
+		since = e.lock.Started.Local().Format("Jan 2 15:04")
+	}
+	return fmt.Sprintf("another backup is running since %s (stashr %s, PID %d on %s). If it is no longer running, break its lock with --force or remove %s",
+		since, e.lock.Command, e.lock.PID, e.lock.Hostname, e.path)
+}
+
+// heldRunLock is the path of the lock this process holds, if any
+var heldRunLock string
+
+// runLockPath returns the path of the lock file
+func runLockPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, runLockName), nil
+}
+
+// acquireRunLock takes the lock that keeps backups and upload retries from
+// running at the same time, so they can't upload the same backup twice or race
+// on the database. A lock left by a process that is gone from this host is
+// broken automatically; force breaks any lock.
+func acquireRunLock(command string, force bool) error {
+	path, err := runLockPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	data, err := json.Marshal(runLock{PID: os.Getpid(), Hostname: hostname, Command: command, Started: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	// The second attempt follows breaking a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = file.Write(data)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return fmt.Errorf("failed to write lock file: %w", err)
+			}
+			heldRunLock = path
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		held, readErr := readRunLock(path)
+		switch {
+		case force && readErr != nil:
+			logger.Warning("⚠ Breaking the unreadable lock %s with --force", path)
+		case force:
+			logger.Warning("⚠ Breaking the lock of stashr %s (PID %d on %s) with --force", held.Command, held.PID, held.Hostname)
+		case readErr != nil:
+			return fmt.Errorf("another backup holds %s, which can't be read (%v): remove it with --force if no backup is running", path, readErr)
+		case held.Hostname == hostname && !processRunning(held.PID):
+			logger.Warning("⚠ Breaking the stale lock of stashr %s (PID %d exited without releasing it)", held.Command, held.PID)
+		default:
+			return &runLockError{lock: held, path: path}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to break lock: %w", err)
+		}
+	}
+	return fmt.Errorf("another backup took the lock %s while its stale lock was broken", path)
+}
+
+// releaseRunLock removes the lock this process holds
+func releaseRunLock() {
+	if heldRunLock == "" {
+		return
+	}
+	if err := os.Remove(heldRunLock); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warning("Failed to remove lock file %s: %v", heldRunLock, err)
+	}
+	heldRunLock = ""
+}
+
+// readRunLock reads the lock file of another run
+func readRunLock(path string) (runLock, error) {
+	var lock runLock
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lock, err
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, fmt.Errorf("invalid lock file: %w", err)
+	}
+	return lock, nil
+}
+
+// processRunning reports whether a process with the PID exists on this host.
+// On Windows, finding the process opens it, which fails once it exited.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		process.Release()
+		return true
+	}
+	// Signal 0 checks for the process without signalling it
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// sameDay reports whether two times fall on the same calendar day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/schedule"
+)
+
+// scheduleLogName is the file launchd writes scheduled backup output to, in the config directory
+const scheduleLogName = "schedule.log"
+
+var (
+	scheduleDaily  string
+	scheduleWeekly string
+)
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run backups on a schedule with the OS scheduler",
+	Long: `Install a recurring "stashr backup" in the OS scheduler: a systemd user timer
+on Linux, a launchd agent on macOS or a Scheduled Task on Windows. The backup
+runs as the current user, so it can use the OS keychain and password manager
+sessions, and runs as soon as possible when the machine was off at the
+scheduled time.
+
+Scheduled backups run with --non-interactive and fail instead of prompting, so
+store the encryption password in the OS keychain (stashr keychain set) or use
+a key file.
+
+Subcommands:
+  install   - Install the scheduled backup
+  status    - Show the scheduled backup and its last run
+  uninstall - Remove the scheduled backup`,
+}
+
+// scheduleInstallCmd represents the schedule install command
+var scheduleInstallCmd = &cobra.Command{
+	Use:   "install [-- backup flags]",
+	Short: "Install the scheduled backup",
+	Long: `Install the scheduled backup, replacing one installed before. Flags after --
+are passed to "stashr backup".`,
+	Example: `  # Back up every day at 02:00
+  stashr schedule install --daily 02:00
+
+  # Back up to Google Drive every Sunday at 03:30
+  stashr schedule install --weekly "sunday 03:30" -- --destination gdrive
+
+  # Show the generated timer, plist or task without installing it
+  stashr schedule install --daily 02:00 --dry-run`,
+	Run: runScheduleInstall,
+}
+
+// scheduleStatusCmd represents the schedule status command
+var scheduleStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the scheduled backup and its last run",
+	Args:  cobra.NoArgs,
+	Run:   runScheduleStatus,
+}
+
+// scheduleUninstallCmd represents the schedule uninstall command
+var scheduleUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the scheduled backup",
+	Args:  cobra.NoArgs,
+	Run:   runScheduleUninstall,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleInstallCmd)
+	scheduleCmd.AddCommand(scheduleStatusCmd)
+	scheduleCmd.AddCommand(scheduleUninstallCmd)
+
+	scheduleInstallCmd.Flags().StringVar(&scheduleDaily, "daily", "", "Back up every day at this time (HH:MM)")
+	scheduleInstallCmd.Flags().StringVar(&scheduleWeekly, "weekly", "", "Back up every week at this day and time (e.g. \"sunday 02:00\")")
+	scheduleInstallCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the job definition and what installing it would do, without installing it")
+	scheduleInstallCmd.Flags().BoolVar(&dryRun, "print", false, "Alias for --dry-run")
+	scheduleInstallCmd.MarkFlagsMutuallyExclusive("daily", "weekly")
+	scheduleUninstallCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without removing it")
+}
+
+func runScheduleInstall(cmd *cobra.Command, args []string) {
+	logger.Header("🕑 Schedule Backups")
+
+	if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+		logger.Failure("Unexpected argument %q: pass backup flags after --, e.g. stashr schedule install --daily 02:00 -- --destination gdrive", args[0])
+		return
+	}
+	job, err := scheduledJob(args)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		scheduler, err := schedule.SchedulerName()
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		files, err := schedule.Plan(job)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		printDryRunHeader()
+		var plan dryRunPlan
+		for _, file := range files {
+			fmt.Printf("# %s\n", file.Path)
+			fmt.Print(file.Content)
+			fmt.Println()
+			plan.Add("Write %s", file.Path)
+		}
+		plan.Add("Enable it in %s to run stashr backup %s", scheduler, job.Describe())
+		plan.Print()
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	warnUnattendedBackup(cfg, args)
+
+	scheduler, err := schedule.SchedulerName()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Progress("Installing the scheduled backup with %s...", scheduler)
+	files, err := schedule.Install(job)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	for _, file := range files {
+		logger.Success("✓ Installed %s", file.Path)
+	}
+	logger.Separator()
+	logger.Success("✅ stashr backup will run %s", job.Describe())
+	logger.Info("Command: %s", strings.Join(job.Command, " "))
+	if scheduler == "systemd" {
+		logger.Info("💡 To run backups while you're logged out, enable lingering: loginctl enable-linger")
+	}
+	logger.Info("💡 Check the last run with: stashr schedule status")
+}
+
+func runScheduleStatus(cmd *cobra.Command, args []string) {
+	logger.Header("🕑 Scheduled Backup")
+
+	status, err := schedule.QueryStatus()
+	if errors.Is(err, schedule.ErrNotInstalled) {
+		logger.Info("No scheduled backup is installed")
+		logger.Info("💡 Install one with: stashr schedule install --daily 02:00")
+		return
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	logger.Info("Scheduler:   %s", status.Scheduler)
+	logger.Info("Definition:  %s", strings.Join(status.Definition, ", "))
+	logger.Info("State:       %s", valueOr(status.State, "unknown"))
+	logger.Info("Next run:    %s", valueOr(status.NextRun, "unknown"))
+	logger.Info("Last run:    %s", valueOr(status.LastRun, "never"))
+	if status.LastResult != "" {
+		logger.Info("Last result: %s", status.LastResult)
+	}
+	if status.Logs != "" {
+		logger.Info("Logs:        %s", status.Logs)
+	}
+}
+
+func runScheduleUninstall(cmd *cobra.Command, args []string) {
+	logger.Header("🕑 Remove Scheduled Backup")
+
+	if dryRun {
+		scheduler, err := schedule.SchedulerName()
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		paths, err := schedule.Installed()
+		if err != nil && !errors.Is(err, schedule.ErrNotInstalled) {
+			logger.PrintError(err)
+			return
+		}
+		printDryRunHeader()
+		var plan dryRunPlan
+		if len(paths) > 0 {
+			plan.Add("Disable the scheduled backup in %s", scheduler)
+		}
+		for _, path := range paths {
+			plan.Add("Delete %s", path)
+		}
+		plan.Print()
+		return
+	}
+
+	err := schedule.Uninstall()
+	if errors.Is(err, schedule.ErrNotInstalled) {
+		logger.Info("No scheduled backup is installed")
+		return
+	}
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	logger.Success("✓ Removed the scheduled backup")
+}
+
+// scheduledJob builds the job from the install flags, running this stashr
+// binary's backup command with the given backup flags
+func scheduledJob(backupArgs []string) (schedule.Job, error) {
+	var job schedule.Job
+	var err error
+	switch {
+	case scheduleDaily != "":
+		job.Hour, job.Minute, err = schedule.ParseTime(scheduleDaily)
+	case scheduleWeekly != "":
+		job.Weekly = true
+		job.Weekday, job.Hour, job.Minute, err = schedule.ParseWeekly(scheduleWeekly)
+	default:
+		err = errors.New("choose when to back up with --daily HH:MM or --weekly \"<weekday> HH:MM\"")
+	}
+	if err != nil {
+		return job, err
+	}
+
+	job.Command, err = stashrCommand(append([]string{"backup", "--non-interactive"}, backupArgs...)...)
+	if err != nil {
+		return job, err
+	}
+	if strings.Contains(job.Command[0], "go-build") {
+		logger.Warning("⚠ %s is a temporary build from \"go run\"; install stashr and schedule the installed binary", job.Command[0])
+	}
+
+	// The scheduler's PATH lacks the directories the password manager CLIs are
+	// usually installed in
+	job.Path = os.Getenv("PATH")
+	if dir, err := config.GetConfigDir(); err == nil {
+		job.LogFile = filepath.Join(dir, scheduleLogName)
+	}
+	return job, nil
+}
+
+// stashrCommand returns the command line running this stashr binary with args,
+// reading the config file this one was started with
+func stashrCommand(args ...string) ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the stashr executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	command := []string{executable}
+	if cfgFile != "" {
+		path, err := filepath.Abs(cfgFile)
+		if err != nil {
+			return nil, err
+		}
+		command = append(command, "--config", path)
+	}
+	return append(command, args...), nil
+}
+
+// warnUnattendedBackup warns when the scheduled backup would need to prompt
+func warnUnattendedBackup(cfg *config.Config, backupArgs []string) {
+	if !cfg.Backup.Encryption.Enabled || hasFlag(backupArgs, "--no-encrypt") {
+		return
+	}
+	backends := selectStorageBackends(cfg, "all")
+	keyFile := resolveKeyFile(cfg, "") != "" || hasFlag(backupArgs, "-k", "--encryption-key")
+	if requiresSharedPassword(cfg, backends) && !keyFile && !cfg.Backup.Encryption.Keychain {
+		logger.Warning("⚠ Scheduled backups can't prompt for the encryption password: store it with \"stashr keychain set\" and enable backup.encryption.keychain, or use a key file (backup.encryption.key_file)")
+	}
+	for _, backend := range backends {
+		if effectiveEncryptionMode(cfg, backend) == config.EncryptionModePassword && destinationEncryption(cfg, backend).SeparatePassword {
+			logger.Warning("⚠ %s uses a separate password, which scheduled backups can't prompt for", backend.Name())
+		}
+	}
+	if hasFlag(backupArgs, "-i", "--interactive", "--prompt-each") {
+		logger.Warning("⚠ Scheduled backups can't answer prompts: remove --interactive and --prompt-each")
+	}
+}
+
+// hasFlag reports whether args contain one of the given flags, alone or as --flag=value
+func hasFlag(args []string, flags ...string) bool {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		for _, flag := range flags {
+			if name == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/convert"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+var (
+	searchManager     string
+	searchSource      string
+	searchLimit       int
+	searchShowSecrets bool
+	searchKeyFile     string
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find an item across backups and see how it changed",
+	Long: `Search every backup for items whose title, username or URL contains the
+query, ignoring case, and show in which snapshots each item exists and how its
+username, URL and password changed over time.
+
+Backups are decrypted in memory, newest first, and never written to disk. The
+encryption password is asked for once, if STASHR_ENCRYPTION_PASSWORD and the OS
+keychain don't have it. Items are followed by title and category, so a renamed
+item shows up as two items.
+
+Passwords are masked, showing only when they changed, unless --show-secrets is given.`,
+	Example: `  stashr search github
+  stashr search "bank" --manager 1password --limit 10
+  stashr search github --show-secrets`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringVarP(&searchManager, "manager", "m", "", "Only search backups of one password manager (bitwarden, 1password)")
+	searchCmd.Flags().StringVarP(&searchSource, "source", "s", "", "Only search backups on one destination (default: all)")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 0, "Only search the newest n backups (default: all)")
+	searchCmd.Flags().BoolVar(&searchShowSecrets, "show-secrets", false, "Show passwords instead of masking them")
+	searchCmd.Flags().StringVarP(&searchKeyFile, "encryption-key", "k", "", "Key file to decrypt with (default: backup.encryption.key_file)")
+}
+
+// searchSnapshot is a backup to search, read from the first destination that has it
+type searchSnapshot struct {
+	name    string
+	manager string
+	time    time.Time
+	backend storage.Storage
+}
+
+// itemVersion is an item as it was in one snapshot
+type itemVersion struct {
+	snapshot searchSnapshot
+	entry    convert.Entry
+}
+
+// itemHistory is an item followed across snapshots, newest first
+type itemHistory struct {
+	manager  string
+	title    string
+	category string
+	versions []itemVersion
+}
+
+func runSearch(cmd *cobra.Command, args []string) {
+	logger.Header("🔎 Search Backups")
+
+	query := strings.ToLower(strings.TrimSpace(args[0]))
+	if query == "" {
+		logger.Failure("Search query is empty")
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	var backends []storage.Storage
+	if searchSource != "" {
+		dest, err := findStorageDestination(cfg, searchSource)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		backends = []storage.Storage{dest.create()}
+	} else {
+		backends = getStorageBackendsForRestore(cfg)
+	}
+	if len(backends) == 0 {
+		logger.Failure("No storage destinations enabled")
+		return
+	}
+
+	verifier := &integrityVerifier{cfg: cfg, names: newBackupNameParser(cfg), decrypt: true, keyFile: searchKeyFile, interactive: true, passwords: make(map[string]*secret.Buffer)}
+	defer func() {
+		for _, password := range verifier.passwords {
+			password.Destroy()
+		}
+	}()
+
+	snapshots := listSearchSnapshots(cfg, verifier, backends)
+	if len(snapshots) == 0 {
+		logger.Info("No backups found")
+		return
+	}
+	if searchLimit > 0 && len(snapshots) > searchLimit {
+		snapshots = snapshots[:searchLimit]
+	}
+
+	logger.Progress("Searching %d backup(s), newest first...", len(snapshots))
+	histories := make(map[string]*itemHistory)
+	searched := make(map[string]int)
+	for _, snapshot := range snapshots {
+		entries, err := readSnapshotEntries(cfg, verifier, snapshot)
+		if err != nil {
+			logger.Warning("⚠ Skipping %s: %v", snapshot.name, err)
+			continue
+		}
+		searched[snapshot.manager]++
+
+		for _, entry := range entries {
+			if !entryMatches(entry, query) {
+				continue
+			}
+			key := snapshot.manager + "\x00" + entry.Category + "\x00" + entry.Title
+			history, ok := histories[key]
+			if !ok {
+				history = &itemHistory{manager: snapshot.manager, title: entry.Title, category: entry.Category}
+				histories[key] = history
+			}
+			// Of items sharing a title and category in one snapshot, the first is followed
+			if last := len(history.versions) - 1; last >= 0 && history.versions[last].snapshot.name == snapshot.name {
+				continue
+			}
+			history.versions = append(history.versions, itemVersion{snapshot: snapshot, entry: entry})
+		}
+	}
+
+	total := 0
+	for _, count := range searched {
+		total += count
+	}
+	if total == 0 {
+		logger.Failure("No backup could be searched")
+		return
+	}
+	if len(histories) == 0 {
+		logger.Info("No items match %q in %d backup(s)", args[0], total)
+		return
+	}
+
+	keys := make([]string, 0, len(histories))
+	for key := range histories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	logger.Separator()
+	for _, key := range keys {
+		printItemHistory(histories[key], searched[histories[key].manager])
+	}
+	logger.Success("✓ %d item(s) match %q in %d backup(s)", len(histories), args[0], total)
+	if searchShowSecrets {
+		logger.Warning("⚠ Passwords were shown in clear text; clear your terminal scrollback")
+	}
+}
+
+// listSearchSnapshots lists the backups of every destination, newest first.
+// Backups stored in several places are read from one destination, preferring
+// those that use the shared encryption password so it is asked for only once.
+func listSearchSnapshots(cfg *config.Config, verifier *integrityVerifier, backends []storage.Storage) []searchSnapshot {
+	sort.SliceStable(backends, func(i, j int) bool {
+		return !destinationEncryption(cfg, backends[i]).SeparatePassword && destinationEncryption(cfg, backends[j]).SeparatePassword
+	})
+
+	seen := make(map[string]bool)
+	var snapshots []searchSnapshot
+	for _, backend := range backends {
+		if available, err := backend.IsAvailable(); err != nil || !available {
+			logger.Warning("⚠ Skipping %s: not available", backend.Name())
+			continue
+		}
+		backups, err := backend.List()
+		if err != nil {
+			logger.Warning("⚠ Skipping %s: %v", backend.Name(), err)
+			continue
+		}
+		for _, backup := range backups {
+			manager := detectManager(verifier.names, backup.Name)
+			if seen[backup.Name] || (searchManager != "" && manager != searchManager) {
+				continue
+			}
+			seen[backup.Name] = true
+
+			taken := verifier.names.Parse(backup.Name).Timestamp
+			if taken.IsZero() {
+				taken = backup.ModifiedTime
+			}
+			snapshots = append(snapshots, searchSnapshot{name: backup.Name, manager: manager, time: taken, backend: backend})
+		}
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].time.After(snapshots[j].time) })
+	return snapshots
+}
+
+// readSnapshotEntries downloads, decrypts and parses a backup in memory
+func readSnapshotEntries(cfg *config.Config, verifier *integrityVerifier, snapshot searchSnapshot) ([]convert.Entry, error) {
+	logger.Debug("Searching %s from %s", snapshot.name, snapshot.backend.Name())
+	data, err := snapshot.backend.Download(snapshot.name)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := data
+	if crypto.UsesKeyFile(data) || crypto.IsEncrypted(data) || publicKeyEncrypted(data) {
+		if err := checkFIPSFile(cfg, snapshot.name, data); err != nil {
+			return nil, err
+		}
+		if plaintext, err = verifier.decryptCopy(snapshot.backend, data); err != nil {
+			return nil, err
+		}
+		defer secret.Wipe(plaintext)
+	}
+	if isCompressedBackup(plaintext) {
+		decompressed, err := decompressBackup(cfg, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress: %w", err)
+		}
+		defer secret.Wipe(decompressed)
+		plaintext = decompressed
+	}
+
+	entries, err := convert.Parse(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vault data: %w", err)
+	}
+	return entries, nil
+}
+
+// entryMatches reports whether an item's title, username or a URL contains the
+// lowercased query
+func entryMatches(entry convert.Entry, query string) bool {
+	if strings.Contains(strings.ToLower(entry.Title), query) || strings.Contains(strings.ToLower(entry.Username), query) {
+		return true
+	}
+	for _, url := range entry.URLs {
+		if strings.Contains(strings.ToLower(url), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// printItemHistory prints the snapshots an item exists in, newest first, marking
+// the values that differ from the snapshot before it
+func printItemHistory(history *itemHistory, searched int) {
+	newest := history.versions[0].snapshot.time
+	oldest := history.versions[len(history.versions)-1].snapshot.time
+	logger.Info("%s (%s, %s)", history.title, valueOr(history.category, "item"), managerDisplayName(history.manager))
+	logger.Info("  In %d of %d %s backup(s), from %s to %s", len(history.versions), searched,
+		managerDisplayName(history.manager), oldest.Format("2006-01-02 15:04"), newest.Format("2006-01-02 15:04"))
+
+	for i, version := range history.versions {
+		entry := version.entry
+		var previous *convert.Entry
+		if i+1 < len(history.versions) {
+			previous = &history.versions[i+1].entry
+		}
+
+		var changed []string
+		if previous != nil {
+			if entry.Username != previous.Username {
+				changed = append(changed, "username")
+			}
+			if strings.Join(entry.URLs, " ") != strings.Join(previous.URLs, " ") {
+				changed = append(changed, "URL")
+			}
+			if entry.Password != previous.Password {
+				changed = append(changed, "password")
+			}
+		}
+
+		password := "-"
+		if entry.Password != "" {
+			password = "••••••••"
+			if searchShowSecrets {
+				password = entry.Password
+			}
+		}
+		url := "-"
+		if len(entry.URLs) > 0 {
+			url = entry.URLs[0]
+			if len(entry.URLs) > 1 {
+				url += fmt.Sprintf(" (+%d)", len(entry.URLs)-1)
+			}
+		}
+
+		line := fmt.Sprintf("  %s  %-24s  %-32s  %s", version.snapshot.time.Format("2006-01-02 15:04"), valueOr(entry.Username, "-"), url, password)
+		if len(changed) > 0 {
+			line += "  ← " + strings.Join(changed, ", ") + " changed"
+		}
+		logger.Info("%s", line)
+	}
+	logger.Separator()
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+)
+
+// defaultWarnAge is how old the last backup of a manager may be before status warns
+const defaultWarnAge = "7d"
+
+var (
+	statusWarnAge string
+	statusQuiet   bool
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Warn about password managers without a recent backup",
+	Long: `Show when each enabled password manager was last backed up successfully, and
+exit with status 1 if any of them hasn't been backed up within --warn-age.
+
+With --quiet, nothing is printed while backups are recent, so the command can
+go in a shell profile as a reminder, or in a monitoring script.`,
+	Example: `  # Show the last backup of each manager
+  stashr status
+
+  # Remind me in every new shell when backups are more than a week old
+  stashr status --warn-age 7d --quiet
+
+  # Alert from a monitoring script when a backup is over 36 hours old
+  stashr status --warn-age 36h || send-alert`,
+	Args: cobra.NoArgs,
+	Run:  runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusWarnAge, "warn-age", defaultWarnAge, "Warn about managers whose last backup is older than this (e.g. 36h, 7d, 2w)")
+	statusCmd.Flags().BoolVarP(&statusQuiet, "quiet", "q", false, "Only print managers whose backups are too old")
+}
+
+// managerStatus is when a manager was last backed up, and where to
+type managerStatus struct {
+	manager string
+	last    *database.BackupRecord
+	stale   bool
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	if !statusQuiet {
+		logger.Header("📊 Backup Status")
+	}
+
+	warnAge, err := parseAge(statusWarnAge)
+	if err != nil {
+		logger.Failure("Invalid --warn-age %q: %v", statusWarnAge, err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		os.Exit(1)
+	}
+	names := enabledManagerNames(cfg)
+	if len(names) == 0 {
+		logger.Failure("No password managers enabled")
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	var statuses []managerStatus
+	stale := 0
+	for _, name := range names {
+		records, err := database.ListBackups(name, "", nil)
+		if err != nil {
+			logger.PrintError(err)
+			os.Exit(1)
+		}
+		status := managerStatus{manager: name, stale: true}
+		// Backups are listed newest first
+		if len(records) > 0 {
+			status.last = &records[0]
+			status.stale = now.Sub(records[0].CreatedAt) > warnAge
+		}
+		if status.stale {
+			stale++
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Postponed backups explain why a scheduled backup is missing. Events are
+	// listed oldest first.
+	events, err := database.ListEvents(now.Add(-warnAge))
+	if err != nil {
+		logger.PrintError(err)
+		os.Exit(1)
+	}
+	var postponed []database.EventRecord
+	for _, event := range events {
+		if event.Kind == database.EventPostponed {
+			postponed = append(postponed, event)
+		}
+	}
+	// The last restore drill of each manager is the evidence its backups restore
+	drills, err := database.ListDrills(time.Time{})
+	if err != nil {
+		logger.PrintError(err)
+		os.Exit(1)
+	}
+	lastDrills := make(map[string]database.DrillRecord)
+	for _, drill := range drills {
+		lastDrills[drill.Manager] = drill
+	}
+
+	if statusQuiet {
+		for _, status := range statuses {
+			if !status.stale {
+				continue
+			}
+			if status.last == nil {
+				fmt.Println(i18n.Tf("stashr: %s has never been backed up", managerDisplayName(status.manager)))
+			} else {
+				fmt.Println(i18n.Tf("stashr: %s was last backed up %s", managerDisplayName(status.manager), formatAge(now.Sub(status.last.CreatedAt))))
+			}
+		}
+		if stale > 0 && len(postponed) > 0 {
+			last := postponed[len(postponed)-1]
+			fmt.Println(i18n.Tf("stashr: %d backup(s) were postponed by backup.conditions, most recently %s: %s", len(postponed), formatAge(now.Sub(last.CreatedAt)), last.Message))
+		}
+		if stale > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, status := range statuses {
+		name := managerDisplayName(status.manager)
+		switch {
+		case status.last == nil:
+			logger.Failure("✗ %s: never backed up", name)
+		case status.stale:
+			logger.Failure("✗ %s: last backed up %s (%s, %s)", name, formatAge(now.Sub(status.last.CreatedAt)), status.last.CreatedAt.Format("2006-01-02 15:04"), status.last.StorageType)
+		default:
+			logger.Success("✓ %s: last backed up %s (%s, %s)", name, formatAge(now.Sub(status.last.CreatedAt)), status.last.CreatedAt.Format("2006-01-02 15:04"), status.last.StorageType)
+		}
+		if drill, ok := lastDrills[status.manager]; ok {
+			if drill.Success {
+				logger.Info("  Last restore drill passed %s (%s)", formatAge(now.Sub(drill.CreatedAt)), drill.StorageType)
+			} else {
+				logger.Warning("  ⚠ Last restore drill failed %s (%s): %s check failed", formatAge(now.Sub(drill.CreatedAt)), drill.StorageType, drill.FailedCheck)
+			}
+		}
+	}
+
+	if len(postponed) > 0 {
+		last := postponed[len(postponed)-1]
+		logger.Warning("⏸ %d backup(s) were postponed by backup.conditions in the last %s, most recently %s: %s", len(postponed), statusWarnAge, formatAge(now.Sub(last.CreatedAt)), last.Message)
+	}
+
+	logger.Separator()
+	if stale > 0 {
+		logger.Warning("⚠ %d of %d managers haven't been backed up in %s: run 'stashr backup'", stale, len(statuses), statusWarnAge)
+		os.Exit(1)
+	}
+	logger.Success("✅ Every manager was backed up in the last %s", statusWarnAge)
+}
+
+// parseAge parses an age such as "36h", "7d" or "2w". Days and weeks are
+// calendar-agnostic multiples of 24 hours.
+func parseAge(value string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(value, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		age, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("use a number with h, d or w, e.g. 7d")
+		}
+		if age <= 0 {
+			return 0, fmt.Errorf("age must be positive")
+		}
+		return age, nil
+	}
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return 0, fmt.Errorf("use a number with h, d or w, e.g. 7d")
+	}
+	if count <= 0 {
+		return 0, fmt.Errorf("age must be positive")
+	}
+	return time.Duration(count) * unit, nil
+}
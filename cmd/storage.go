@@ -0,0 +1,498 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+// storageDestination describes a configured storage backend
+type storageDestination struct {
+	// flag is the value that selects this destination in --destination and --source
+	flag string
+	// name matches the Name() of the backend created by create
+	name string
+	// kind is the flag of the built-in destination type, e.g. "local" for a local profile
+	kind       string
+	enabled    bool
+	remote     bool
+	encryption config.DestinationEncryptionConfig
+	// artifact sets the file extension and MIME type backups get
+	artifact config.ArtifactConfig
+	// retention overrides backup.retention for this destination when set
+	retention *config.RetentionConfig
+	create    func() storage.Storage
+}
+
+// proxyOnce sets the proxy a single time, before any destination is created;
+// uploads running in parallel read it
+var proxyOnce sync.Once
+
+// configureProxy routes cloud destinations through storage.proxy
+func configureProxy() {
+	proxyOnce.Do(func() {
+		cfg, err := config.Load()
+		if err != nil {
+			return
+		}
+		if err := storage.SetProxy(cfg.Storage.Proxy.URL, cfg.Storage.Proxy.NoProxy); err != nil {
+			logger.Warning("⚠ Ignoring proxy: %v", err)
+		}
+	})
+}
+
+// storageDestinations returns every storage destination known to the configuration
+func storageDestinations(cfg *config.Config) []storageDestination {
+	dests := []storageDestination{
+		googleDriveDestination(cfg.Storage.GoogleDrive),
+		usbDestination(cfg.Storage.USB),
+		localDestination(cfg.Storage.Local),
+		gitAnnexDestination(cfg.Storage.GitAnnex),
+		oneDriveDestination(cfg.Storage.OneDrive),
+		webDAVDestination(cfg.Storage.WebDAV),
+		gcsDestination(cfg.Storage.GCS),
+		azureBlobDestination(cfg.Storage.AzureBlob),
+		s3Destination(cfg.Storage.S3),
+		rcloneDestination(cfg.Storage.Rclone),
+		icloudDestination(cfg.Storage.ICloud),
+	}
+
+	// Destination profiles are built-in types under their own name
+	for _, profile := range cfg.Storage.Destinations {
+		if dest, ok := profileDestination(profile); ok {
+			dests = append(dests, dest)
+		}
+	}
+
+	// Plugins are selected by their configured name
+	for _, plugin := range cfg.Storage.Plugins {
+		plugin := plugin
+		dests = append(dests, storageDestination{
+			flag:       plugin.Name,
+			name:       plugin.Name,
+			kind:       "plugin",
+			enabled:    plugin.Enabled,
+			remote:     true,
+			encryption: plugin.Encryption,
+			artifact:   plugin.Artifact,
+			retention:  plugin.Retention,
+			create: func() storage.Storage {
+				p := storage.NewPlugin(plugin.Name, plugin.Path, plugin.Options)
+				p.ContentType = plugin.Artifact.ContentType
+				return p
+			},
+		})
+	}
+
+	// Transient failures of remote destinations are retried; cache hits never reach the network
+	for i := range dests {
+		if !dests[i].remote || cfg.Storage.Retry.Attempts <= 1 {
+			continue
+		}
+		create := dests[i].create
+		dests[i].create = func() storage.Storage {
+			return storage.NewRetryStorage(create(), retryPolicy(cfg), logRetry)
+		}
+	}
+
+	// Deduplicated destinations store chunks, each uploaded with retries
+	for i := range dests {
+		if !dests[i].artifact.Dedup {
+			continue
+		}
+		create := dests[i].create
+		dests[i].create = func() storage.Storage {
+			return storage.NewDedupStorage(create())
+		}
+	}
+
+	// Downloads from remote destinations are read through the local cache
+	for i := range dests {
+		if !dests[i].remote || !cacheable(cfg, dests[i]) {
+			continue
+		}
+		create := dests[i].create
+		dests[i].create = func() storage.Storage {
+			return storage.NewCachedStorage(create(), backupCache(cfg))
+		}
+	}
+
+	return dests
+}
+
+// profileDestination returns the destination a storage.destinations entry
+// configures, which reports the profile's name instead of its type's
+func profileDestination(profile config.DestinationConfig) (storageDestination, bool) {
+	var dest storageDestination
+	switch profile.Kind() {
+	case "google_drive":
+		dest = googleDriveDestination(*profile.GoogleDrive)
+	case "usb":
+		dest = usbDestination(*profile.USB)
+	case "local":
+		dest = localDestination(*profile.Local)
+	case "git_annex":
+		dest = gitAnnexDestination(*profile.GitAnnex)
+	case "onedrive":
+		dest = oneDriveDestination(*profile.OneDrive)
+	case "webdav":
+		dest = webDAVDestination(*profile.WebDAV)
+	case "gcs":
+		dest = gcsDestination(*profile.GCS)
+	case "azure_blob":
+		dest = azureBlobDestination(*profile.AzureBlob)
+	case "s3":
+		dest = s3Destination(*profile.S3)
+	case "rclone":
+		dest = rcloneDestination(*profile.Rclone)
+	case "icloud":
+		dest = icloudDestination(*profile.ICloud)
+	default:
+		// Rejected by config validation
+		return storageDestination{}, false
+	}
+
+	create := dest.create
+	dest.flag = profile.Name
+	dest.name = profile.Name
+	dest.create = func() storage.Storage {
+		return storage.NewNamedStorage(create(), profile.Name)
+	}
+	return dest, true
+}
+
+// googleDriveDestination describes a Google Drive destination
+func googleDriveDestination(c config.GoogleDriveConfig) storageDestination {
+	return storageDestination{
+		flag:       "gdrive",
+		name:       "Google Drive",
+		kind:       "gdrive",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			gdrive := storage.NewGoogleDrive(c.CredentialsPath, c.FolderID, c.DriveID)
+			gdrive.ContentType = c.Artifact.ContentType
+			gdrive.PageSize = int64(c.PageSize)
+			return gdrive
+		},
+	}
+}
+
+// usbDestination describes a USB destination
+func usbDestination(c config.USBConfig) storageDestination {
+	return storageDestination{
+		flag:       "usb",
+		name:       "USB",
+		kind:       "usb",
+		enabled:    c.Enabled,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return newUSB(c)
+		},
+	}
+}
+
+// localDestination describes a local destination
+func localDestination(c config.LocalConfig) storageDestination {
+	return storageDestination{
+		flag:       "local",
+		name:       "Local",
+		kind:       "local",
+		enabled:    c.Enabled,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return storage.NewLocal(c.BackupPath)
+		},
+	}
+}
+
+// gitAnnexDestination describes a git-annex destination
+func gitAnnexDestination(c config.GitAnnexConfig) storageDestination {
+	return storageDestination{
+		flag:       "git-annex",
+		name:       "git-annex",
+		kind:       "git-annex",
+		enabled:    c.Enabled,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return storage.NewGitAnnex(c.RepoPath, c.BackupDir, c.Remotes)
+		},
+	}
+}
+
+// oneDriveDestination describes a OneDrive destination
+func oneDriveDestination(c config.OneDriveConfig) storageDestination {
+	return storageDestination{
+		flag:       "onedrive",
+		name:       "OneDrive",
+		kind:       "onedrive",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return storage.NewOneDrive(c.ClientID, c.Tenant, c.Folder, c.TokenPath)
+		},
+	}
+}
+
+// webDAVDestination describes a WebDAV destination
+func webDAVDestination(c config.WebDAVConfig) storageDestination {
+	return storageDestination{
+		flag:       "webdav",
+		name:       "WebDAV",
+		kind:       "webdav",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return newWebDAV(c)
+		},
+	}
+}
+
+// gcsDestination describes a Google Cloud Storage destination
+func gcsDestination(c config.GCSConfig) storageDestination {
+	return storageDestination{
+		flag:       "gcs",
+		name:       "Google Cloud Storage",
+		kind:       "gcs",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			bucket := storage.NewGCS(c.Bucket, c.Prefix, c.CredentialsPath)
+			bucket.ContentType = c.Artifact.ContentType
+			bucket.LockDays = c.LockDays
+			return bucket
+		},
+	}
+}
+
+// azureBlobDestination describes an Azure Blob destination
+func azureBlobDestination(c config.AzureBlobConfig) storageDestination {
+	return storageDestination{
+		flag:       "azure",
+		name:       "Azure Blob",
+		kind:       "azure",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return newAzureBlob(c)
+		},
+	}
+}
+
+// s3Destination describes an S3 destination
+func s3Destination(c config.S3Config) storageDestination {
+	return storageDestination{
+		flag:       "s3",
+		name:       "S3",
+		kind:       "s3",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return newS3(c)
+		},
+	}
+}
+
+// rcloneDestination describes an rclone destination
+func rcloneDestination(c config.RcloneConfig) storageDestination {
+	return storageDestination{
+		flag:       "rclone",
+		name:       "rclone",
+		kind:       "rclone",
+		enabled:    c.Enabled,
+		remote:     true,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			return storage.NewRclone(c.Remote, c.CLIPath, c.ConfigPath)
+		},
+	}
+}
+
+// icloudDestination describes an iCloud Drive destination
+func icloudDestination(c config.ICloudConfig) storageDestination {
+	return storageDestination{
+		flag:       "icloud",
+		name:       "iCloud Drive",
+		kind:       "icloud",
+		enabled:    c.Enabled,
+		encryption: c.Encryption,
+		artifact:   c.Artifact,
+		retention:  c.Retention,
+		create: func() storage.Storage {
+			// The timeout was checked by config validation
+			timeout, _ := c.Timeout()
+			return storage.NewICloudDrive(c.DrivePath, c.BackupDir, timeout)
+		},
+	}
+}
+
+// newUSB creates a USB backend, found by its volume label or UUID when configured with one
+func newUSB(c config.USBConfig) *storage.USB {
+	usb := storage.NewUSB(c.MountPath, c.BackupDir)
+	usb.Label = c.Label
+	usb.UUID = c.UUID
+	return usb
+}
+
+// usbDriveDescription describes where a USB destination's drive is found
+func usbDriveDescription(c config.USBConfig) string {
+	switch {
+	case c.Label != "":
+		return fmt.Sprintf("drive labeled %q", c.Label)
+	case c.UUID != "":
+		return fmt.Sprintf("drive %s", c.UUID)
+	default:
+		return c.MountPath
+	}
+}
+
+// newWebDAV creates a WebDAV backend, reading the app password from
+// STASHR_WEBDAV_PASSWORD when it isn't in the config file
+func newWebDAV(dav config.WebDAVConfig) *storage.WebDAV {
+	password := dav.Password
+	if password == "" {
+		password = os.Getenv("STASHR_WEBDAV_PASSWORD")
+	}
+	backend := storage.NewWebDAV(dav.URL, dav.Username, password, dav.BackupDir)
+	backend.ContentType = dav.Artifact.ContentType
+	return backend
+}
+
+// newAzureBlob creates an Azure Blob backend, reading the connection string from
+// STASHR_AZURE_CONNECTION_STRING when it isn't in the config file
+func newAzureBlob(azure config.AzureBlobConfig) *storage.AzureBlob {
+	connectionString := azure.ConnectionString
+	if connectionString == "" {
+		connectionString = os.Getenv("STASHR_AZURE_CONNECTION_STRING")
+	}
+	backend := storage.NewAzureBlob(connectionString, azure.Container, azure.Prefix)
+	backend.ContentType = azure.Artifact.ContentType
+	backend.LockDays = azure.LockDays
+	return backend
+}
+
+// newS3 creates an S3 backend, reading the secret access key from
+// STASHR_S3_SECRET_ACCESS_KEY when it isn't in the config file
+func newS3(s3 config.S3Config) *storage.S3 {
+	secretAccessKey := s3.SecretAccessKey
+	if secretAccessKey == "" && s3.AccessKeyID != "" {
+		secretAccessKey = os.Getenv("STASHR_S3_SECRET_ACCESS_KEY")
+	}
+	backend := storage.NewS3(s3.Endpoint, s3.Region, s3.Bucket, s3.Prefix, s3.AccessKeyID, secretAccessKey)
+	backend.ContentType = s3.Artifact.ContentType
+	backend.LockDays = s3.LockDays
+	return backend
+}
+
+// retryPolicy returns how remote operations are retried, storage.retry
+func retryPolicy(cfg *config.Config) storage.RetryPolicy {
+	// Invalid delays are rejected by config validation
+	initial, maxDelay, _ := cfg.Storage.Retry.Delays()
+	return storage.RetryPolicy{
+		Attempts:     cfg.Storage.Retry.Attempts,
+		InitialDelay: initial,
+		MaxDelay:     maxDelay,
+		Jitter:       cfg.Storage.Retry.Jitter,
+	}
+}
+
+// logRetry reports a transient failure that is about to be retried
+func logRetry(operation string, attempt int, err error, delay time.Duration) {
+	logger.Warning("⚠ %s failed (attempt %d), retrying in %s: %v", operation, attempt, delay.Round(100*time.Millisecond), err)
+}
+
+// cacheable reports whether downloads from a destination may be cached. Only
+// encrypted backups are kept on disk.
+func cacheable(cfg *config.Config, dest storageDestination) bool {
+	if noCache || !cfg.Cache.Enabled {
+		return false
+	}
+	switch dest.encryption.Mode {
+	case config.EncryptionModePassword, config.EncryptionModeAge, config.EncryptionModeGPG:
+		return true
+	default:
+		return cfg.Backup.Encryption.Enabled
+	}
+}
+
+// backupCache returns the configured download cache
+func backupCache(cfg *config.Config) *storage.Cache {
+	return storage.NewCache(cfg.Cache.Dir, int64(cfg.Cache.MaxSizeMB)*1024*1024)
+}
+
+// selectStorageBackends creates the enabled backends matching a destination flag ("all" selects every one)
+func selectStorageBackends(cfg *config.Config, flag string) []storage.Storage {
+	var backends []storage.Storage
+	for _, dest := range storageDestinations(cfg) {
+		if !dest.enabled || (flag != "all" && flag != dest.flag) {
+			continue
+		}
+		backends = append(backends, dest.create())
+	}
+	return backends
+}
+
+// findStorageDestination returns the destination selected by a flag value
+func findStorageDestination(cfg *config.Config, flag string) (storageDestination, error) {
+	var flags []string
+	for _, dest := range storageDestinations(cfg) {
+		if dest.flag == flag {
+			return dest, nil
+		}
+		flags = append(flags, dest.flag)
+	}
+	return storageDestination{}, fmt.Errorf("unknown source: %s (use: %s)", flag, strings.Join(flags, ", "))
+}
+
+// destinationForBackend returns the destination that created a backend
+func destinationForBackend(cfg *config.Config, backend storage.Storage) (storageDestination, bool) {
+	for _, dest := range storageDestinations(cfg) {
+		if dest.name == backend.Name() {
+			return dest, true
+		}
+	}
+	return storageDestination{}, false
+}
+
+// enabledDestinationFlags returns the flag values of all enabled destinations
+func enabledDestinationFlags(cfg *config.Config) []string {
+	var flags []string
+	for _, dest := range storageDestinations(cfg) {
+		if dest.enabled {
+			flags = append(flags, dest.flag)
+		}
+	}
+	return flags
+}
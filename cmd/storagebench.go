@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	benchDestination string
+	benchSizeKB      int
+)
+
+// storageCmd represents the storage command
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Inspect storage destinations",
+	Long: `Inspect the configured storage destinations.
+
+Subcommands:
+  bench - Measure the latency and throughput of every enabled destination`,
+}
+
+// storageBenchCmd represents the storage bench command
+var storageBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure the latency and throughput of every enabled destination",
+	Long: `Upload a test object of random bytes to every enabled destination, download
+and compare it, list the backups and delete the object again, timing each step.
+
+A destination passes if every step succeeds, which proves that stashr can write,
+read, list and delete there before you rely on it. The first failing step shows
+which permission is missing. The test object's name starts with a dot, so it
+never shows up among your backups, and the download cache is bypassed.`,
+	Example: `  stashr storage bench
+  stashr storage bench --destination gdrive --size-kb 10240`,
+	Run: runStorageBench,
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.AddCommand(storageBenchCmd)
+
+	storageBenchCmd.Flags().StringVarP(&benchDestination, "destination", "d", "all", "Destination to benchmark (gdrive, usb, local, ..., all)")
+	storageBenchCmd.Flags().IntVar(&benchSizeKB, "size-kb", 1024, "Size of the test object in KB")
+}
+
+// benchOperations are the steps of a benchmark, in the order they run
+var benchOperations = []string{"Upload", "Download", "List", "Delete"}
+
+func runStorageBench(cmd *cobra.Command, args []string) {
+	logger.Header("⏱️  Storage Benchmark")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if benchSizeKB < 1 {
+		logger.Failure("--size-kb must be at least 1")
+		return
+	}
+	if benchDestination != "all" {
+		if _, err := findStorageDestination(cfg, benchDestination); err != nil {
+			logger.PrintError(err)
+			return
+		}
+	}
+
+	// A cached download would measure the local disk
+	noCache = true
+
+	backends := selectStorageBackends(cfg, benchDestination)
+	if len(backends) == 0 {
+		logger.Failure("No storage backends enabled or selected")
+		return
+	}
+
+	size := benchSizeKB * 1024
+	logger.Info("Test object: %s of random data", utils.FormatBytes(int64(size)))
+
+	type benchOutcome struct {
+		name   string
+		result storage.BenchResult
+		reason string
+	}
+	var outcomes []benchOutcome
+	for _, backend := range backends {
+		logger.Separator()
+		logger.Progress("Benchmarking %s...", backend.Name())
+
+		available, err := backend.IsAvailable()
+		if err != nil || !available {
+			reason := "not available"
+			if err != nil {
+				reason = err.Error()
+			}
+			logger.Failure("✗ %s", reason)
+			outcomes = append(outcomes, benchOutcome{name: backend.Name(), reason: reason})
+			continue
+		}
+
+		result := storage.Bench(backend, size)
+		for _, step := range result.Steps {
+			if step.Err != nil {
+				logger.Failure("✗ %-8s failed after %s: %v", step.Operation, formatLatency(step.Duration), step.Err)
+				continue
+			}
+			if throughput := step.Throughput(); throughput > 0 {
+				logger.Success("✓ %-8s %8s  %s/s", step.Operation, formatLatency(step.Duration), utils.FormatBytes(int64(throughput)))
+			} else {
+				logger.Success("✓ %-8s %8s", step.Operation, formatLatency(step.Duration))
+			}
+		}
+		outcomes = append(outcomes, benchOutcome{name: backend.Name(), result: result})
+	}
+
+	logger.Separator()
+	fmt.Printf("%-20s %-8s", i18n.T("DESTINATION"), i18n.T("STATUS"))
+	for _, operation := range benchOperations {
+		fmt.Printf(" %-10s", operation)
+	}
+	fmt.Println()
+	passed := 0
+	for _, outcome := range outcomes {
+		status := i18n.T("FAIL")
+		if outcome.reason == "" && outcome.result.OK() {
+			status = i18n.T("OK")
+			passed++
+		}
+		fmt.Printf("%-20s %-8s", outcome.name, status)
+		for _, operation := range benchOperations {
+			cell := "-"
+			if step, ok := outcome.result.Step(operation); ok && step.Err != nil {
+				cell = i18n.T("failed")
+			} else if ok {
+				cell = formatLatency(step.Duration)
+			}
+			fmt.Printf(" %-10s", cell)
+		}
+		fmt.Println()
+	}
+
+	logger.Separator()
+	if passed < len(outcomes) {
+		logger.Warning("⚠ %d of %d destinations passed", passed, len(outcomes))
+		return
+	}
+	logger.Success("✅ All %d destinations passed", passed)
+}
+
+// formatLatency formats a duration in milliseconds, or seconds from 10s
+func formatLatency(d time.Duration) string {
+	if d >= 10*time.Second {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
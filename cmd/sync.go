@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	syncFrom   string
+	syncTo     string
+	syncDelete bool
+	syncYes    bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copy backups from one destination to another",
+	Long: `Reconcile the backups of two destinations: every backup on the source that
+the target doesn't have is copied to it, under the same name and folder. Use it
+to seed a new destination with your full backup history.
+
+Copies follow the target's rules: destination policies are checked for each
+backup's manager, and unencrypted backups are not copied to a destination whose
+encryption mode is "password". Backups with the same name on both destinations
+are left alone, even if their sizes differ.
+
+With --delete, backups on the target that the source doesn't have are deleted,
+after confirmation (or --yes); files whose name isn't a recognized backup name
+are listed and kept. Note that the target's retention policy still
+applies at its next backup; give it a retention override to keep the full history.`,
+	Example: `  stashr sync --from local --to gdrive --dry-run
+  stashr sync --from local --to gdrive
+  stashr sync --from gdrive --to usb --delete --yes`,
+	Run: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&syncFrom, "from", "", "Destination to copy backups from (gdrive, usb, local, ...)")
+	syncCmd.Flags().StringVar(&syncTo, "to", "", "Destination to copy backups to")
+	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "Delete backups on the target that the source doesn't have")
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Delete without confirmation")
+	syncCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip checking each copy against the source")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied and deleted without changing anything")
+	syncCmd.MarkFlagRequired("from")
+	syncCmd.MarkFlagRequired("to")
+}
+
+func runSync(cmd *cobra.Command, args []string) {
+	logger.Header("🔁 Sync Backups")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := validatePolicies(cfg); err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if err := cfg.ValidateEncryptionOverrides(); err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if syncFrom == syncTo {
+		logger.Failure("--from and --to must be different destinations")
+		return
+	}
+	source, err := openSyncDestination(cfg, syncFrom)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	target, err := openSyncDestination(cfg, syncTo)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		printDryRunHeader()
+	}
+
+	sourceBackups, err := source.List()
+	if err != nil {
+		logger.PrintError(fmt.Errorf("failed to list backups on %s: %w", source.Name(), err))
+		return
+	}
+	targetBackups, err := target.List()
+	if err != nil {
+		logger.PrintError(fmt.Errorf("failed to list backups on %s: %w", target.Name(), err))
+		return
+	}
+
+	missing, extra := diffBackupSets(sourceBackups, targetBackups)
+	logger.Info("📁 %s: %d backups", source.Name(), len(sourceBackups))
+	logger.Info("📁 %s: %d backups", target.Name(), len(targetBackups))
+	logger.Info("  %d to copy, %d only on %s", len(missing), len(extra), target.Name())
+
+	// Copies the target's policies forbid are skipped before anything is downloaded
+	managerOf := backupManagerOf(cfg)
+	var copies []storage.BackupFile
+	for _, backup := range missing {
+		if err := checkPolicies(cfg, managerOf(backup.Name), target); err != nil {
+			logger.Warning("  ⛔ %s: blocked (%v)", backup.Name, err)
+			continue
+		}
+		copies = append(copies, backup)
+	}
+	if !syncDelete {
+		extra = nil
+	}
+	// Only backups are deleted: files no filename format recognizes are left alone
+	var unrecognized []storage.BackupFile
+	extra = slices.DeleteFunc(extra, func(backup storage.BackupFile) bool {
+		if managerOf(backup.Name) == "" {
+			unrecognized = append(unrecognized, backup)
+			return true
+		}
+		return false
+	})
+	for _, backup := range unrecognized {
+		logger.Info("  ❔ %s: not a recognized backup name, not deleted", backup.Name)
+	}
+	logger.Separator()
+
+	if dryRun {
+		var plan dryRunPlan
+		for _, backup := range copies {
+			plan.Add("Copy %s (%s) from %s to %s", backup.Name, utils.FormatBytes(backup.Size), source.Name(), target.Name())
+		}
+		for _, backup := range extra {
+			plan.Add("Delete %s from %s (not on %s)", backup.Name, target.Name(), source.Name())
+		}
+		plan.Print()
+		return
+	}
+
+	if len(copies) == 0 && len(extra) == 0 {
+		logger.Success("✓ %s already has every backup on %s", target.Name(), source.Name())
+		return
+	}
+
+	copied, skipped, failed := 0, 0, 0
+	for _, backup := range copies {
+		err := copyBackup(cfg, source, target, backup)
+		switch {
+		case errors.Is(err, errSyncSkipped):
+			logger.Warning("⚠ %s: %v", backup.Name, err)
+			skipped++
+		case err != nil:
+			logger.Failure("✗ %s: %v", backup.Name, err)
+			failed++
+		default:
+			copied++
+		}
+	}
+
+	deleted := 0
+	if len(extra) > 0 {
+		if syncYes || utils.ConfirmPrompt(fmt.Sprintf("Delete %d backups from %s that %s doesn't have?", len(extra), target.Name(), source.Name())) {
+			for _, backup := range extra {
+				if err := withSignature(target.Delete)(backup.Name); err != nil {
+					logger.Failure("✗ Failed to delete %s: %v", backup.Name, err)
+					failed++
+					continue
+				}
+				logger.Success("✓ Deleted %s", backup.Name)
+				deleted++
+			}
+		} else {
+			logger.Info("Kept the backups only on %s", target.Name())
+		}
+	}
+
+	logger.Separator()
+	if failed > 0 {
+		logger.Warning("⚠ Copied %d and deleted %d backups; %d failed, %d skipped", copied, deleted, failed, skipped)
+		return
+	}
+	if skipped > 0 {
+		logger.Success("✅ Copied %d and deleted %d backups; %d skipped", copied, deleted, skipped)
+		return
+	}
+	logger.Success("✅ Copied %d and deleted %d backups", copied, deleted)
+}
+
+// errSyncSkipped marks backups the target must not store
+var errSyncSkipped = errors.New("skipped")
+
+// openSyncDestination returns the backend of an enabled, available destination
+func openSyncDestination(cfg *config.Config, flag string) (storage.Storage, error) {
+	dest, err := findStorageDestination(cfg, flag)
+	if err != nil {
+		return nil, err
+	}
+	if !dest.enabled {
+		return nil, fmt.Errorf("%s storage is not enabled", dest.name)
+	}
+	backend := dest.create()
+	available, err := backend.IsAvailable()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", backend.Name(), err)
+	}
+	if !available {
+		return nil, fmt.Errorf("%s storage is not available", backend.Name())
+	}
+	return backend, nil
+}
+
+// diffBackupSets returns the source backups the target lacks, and the target
+// backups the source lacks, both oldest first
+func diffBackupSets(source, target []storage.BackupFile) (missing, extra []storage.BackupFile) {
+	inSource := make(map[string]bool)
+	for _, backup := range source {
+		inSource[backup.Name] = true
+	}
+	inTarget := make(map[string]bool)
+	for _, backup := range target {
+		inTarget[backup.Name] = true
+	}
+
+	for _, backup := range source {
+		if !inTarget[backup.Name] {
+			missing = append(missing, backup)
+		}
+	}
+	for _, backup := range target {
+		if !inSource[backup.Name] {
+			extra = append(extra, backup)
+		}
+	}
+	for _, backups := range [][]storage.BackupFile{missing, extra} {
+		sort.SliceStable(backups, func(i, j int) bool {
+			return backups[i].ModifiedTime.Before(backups[j].ModifiedTime)
+		})
+	}
+	return missing, extra
+}
+
+// copyBackup downloads a backup from source and uploads it to target under the
+// same name, checking the stored copy unless verification is off
+func copyBackup(cfg *config.Config, source, target storage.Storage, backup storage.BackupFile) error {
+	logger.Progress("Copying %s...", backup.Name)
+
+	start := time.Now()
+	data, err := source.Download(backup.Name)
+	recordDestinationOp(source, database.OperationDownload, start, err)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	mode := effectiveEncryptionMode(cfg, target)
+	encrypted := crypto.IsEncrypted(data) || crypto.IsAgeEncrypted(data) || crypto.IsGPGEncrypted(data)
+	if !encrypted && mode != config.EncryptionModeNone {
+		return fmt.Errorf("%w: not encrypted, and %s requires encryption", errSyncSkipped, target.Name())
+	}
+	if err := checkFreeSpace(logger.WithPrefix(""), target, int64(len(data)), cfg); err != nil {
+		return err
+	}
+
+	start = time.Now()
+	if err := storage.UploadWithProgress(target, backup.Name, data, nil); err != nil {
+		recordDestinationOp(target, database.OperationUpload, start, err)
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	if verifyUploads(cfg) {
+		if _, err := storage.VerifyUpload(target, backup.Name, data); err != nil {
+			err = fmt.Errorf("verification failed: %w", err)
+			recordDestinationOp(target, database.OperationUpload, start, err)
+			return err
+		}
+	}
+	recordDestinationOp(target, database.OperationUpload, start, nil)
+
+	// A signed backup keeps its signature; most have none to copy
+	if signature, err := source.Download(storage.SignatureName(backup.Name)); err == nil {
+		if err := storage.UploadWithProgress(target, storage.SignatureName(backup.Name), signature, nil); err != nil {
+			logger.Warning("⚠ %s: failed to copy signature: %v", backup.Name, err)
+		}
+	}
+
+	logger.Success("✓ Copied %s (%s)", backup.Name, utils.FormatBytes(int64(len(data))))
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
 	"github.com/harshalranjhani/stashr/internal/logger"
 )
 
@@ -212,7 +213,7 @@ func runTagShowAll(cmd *cobra.Command, args []string) {
 	for _, tag := range tags {
 		// Get count of backups with this tag
 		backups, _ := database.GetBackupsByTag(tag)
-		fmt.Printf("  • %-20s (%d backup%s)\n", tag, len(backups), pluralize(len(backups)))
+		fmt.Println(i18n.Tf("  • %-20s (%d backup%s)", tag, len(backups), pluralize(len(backups))))
 	}
 }
 
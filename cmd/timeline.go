@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	timelineManager     string
+	timelineMonths      int
+	timelineInteractive bool
+)
+
+// timelineCmd represents the timeline command
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Browse restore points on a calendar",
+	Long: `Show a calendar of the restore points recorded for each password manager,
+colored by how well each backup has been verified:
+
+  green   test-restored by stashr rehearse
+  cyan    stored copies verified against the upload
+  yellow  not verified
+  red     the last test restore failed
+
+With --interactive, pick a restore point to restore it, preview its header,
+or compare its vault statistics with the restore point before it.`,
+	Example: `  stashr timeline
+  stashr timeline --manager bitwarden --months 6
+  stashr timeline -i`,
+	Run: runTimeline,
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+
+	timelineCmd.Flags().StringVarP(&timelineManager, "manager", "m", "", "Only show one password manager (bitwarden, 1password)")
+	timelineCmd.Flags().IntVar(&timelineMonths, "months", 3, "Number of months to show")
+	timelineCmd.Flags().BoolVarP(&timelineInteractive, "interactive", "i", false, "Pick a restore point to restore, preview or diff")
+}
+
+// restorePointStatus is how well a restore point has been verified, worst first
+type restorePointStatus int
+
+const (
+	restorePointFailed restorePointStatus = iota
+	restorePointUnverified
+	restorePointVerified
+	restorePointRehearsed
+)
+
+// label describes a status in the restore point list
+func (s restorePointStatus) label() string {
+	switch s {
+	case restorePointRehearsed:
+		return i18n.T("✓ rehearsed")
+	case restorePointVerified:
+		return i18n.T("✓ verified")
+	case restorePointFailed:
+		return i18n.T("✗ failed")
+	default:
+		return i18n.T("? unverified")
+	}
+}
+
+// paint colors text for a status
+func (s restorePointStatus) paint(text string) string {
+	switch s {
+	case restorePointRehearsed:
+		return color.GreenString(text)
+	case restorePointVerified:
+		return color.CyanString(text)
+	case restorePointFailed:
+		return color.RedString(text)
+	default:
+		return color.YellowString(text)
+	}
+}
+
+// restorePoint is one recorded backup on the timeline
+type restorePoint struct {
+	record database.BackupRecord
+	status restorePointStatus
+}
+
+func runTimeline(cmd *cobra.Command, args []string) {
+	logger.Header("🗓️  Restore Point Timeline")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if timelineMonths < 1 {
+		logger.Failure("--months must be at least 1")
+		return
+	}
+
+	points, err := loadRestorePoints(timelineManager)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// Months run from the first of the oldest month shown up to today
+	now := time.Now()
+	firstMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -(timelineMonths - 1), 0)
+
+	byManager := make(map[string][]restorePoint)
+	var managerNames []string
+	for _, point := range points {
+		if point.record.CreatedAt.Before(firstMonth) {
+			continue
+		}
+		manager := point.record.Manager
+		if _, ok := byManager[manager]; !ok {
+			managerNames = append(managerNames, manager)
+		}
+		byManager[manager] = append(byManager[manager], point)
+	}
+	sort.Strings(managerNames)
+
+	if len(managerNames) == 0 {
+		logger.Info("No restore points in the last %d months", timelineMonths)
+		logger.Info("Run 'stashr backup' to create one")
+		return
+	}
+
+	// Numbered across managers so any point can be picked
+	var numbered []restorePoint
+	for _, manager := range managerNames {
+		managerPoints := byManager[manager]
+		fmt.Println(color.New(color.Bold).Sprint(managerDisplayName(manager)))
+		for month := firstMonth; !month.After(now); month = month.AddDate(0, 1, 0) {
+			printTimelineMonth(month, managerPoints)
+		}
+
+		fmt.Println()
+		fmt.Printf("  %3s  %-16s  %-12s  %6s  %9s  %s\n", "#", i18n.T("Date"), i18n.T("Status"), i18n.T("Items"), i18n.T("Size"), i18n.T("File"))
+		for _, point := range managerPoints {
+			numbered = append(numbered, point)
+			items := "-"
+			if point.record.ItemCount != nil {
+				items = strconv.Itoa(*point.record.ItemCount)
+			}
+			fmt.Printf("  %3d  %-16s  %s  %6s  %9s  %s\n",
+				len(numbered),
+				point.record.CreatedAt.Local().Format("2006-01-02 15:04"),
+				point.status.paint(fmt.Sprintf("%-12s", point.status.label())),
+				items,
+				utils.FormatBytes(point.record.Size),
+				point.record.Filename)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(i18n.Tf("Legend: %s  %s  %s  %s",
+		restorePointRehearsed.paint(i18n.T("■ rehearsed")),
+		restorePointVerified.paint(i18n.T("■ verified")),
+		restorePointUnverified.paint(i18n.T("■ unverified")),
+		restorePointFailed.paint(i18n.T("■ failed"))))
+
+	if !timelineInteractive {
+		fmt.Println()
+		logger.Info("Use --interactive to restore, preview or diff a restore point")
+		return
+	}
+	browseRestorePoints(cfg, cmd, numbered)
+}
+
+// loadRestorePoints returns the recorded backups, newest first, with the
+// verification status of each
+func loadRestorePoints(manager string) ([]restorePoint, error) {
+	records, err := database.ListBackups(manager, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	events, err := database.ListEvents(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Events are oldest first, so the last test restore of each file wins
+	rehearsals := make(map[string]bool)
+	for _, event := range events {
+		if event.Kind == database.EventVerification && event.Filename != "" {
+			rehearsals[event.Filename] = event.Success
+		}
+	}
+
+	points := make([]restorePoint, 0, len(records))
+	for _, record := range records {
+		status := restorePointUnverified
+		if success, ok := rehearsals[record.Filename]; ok {
+			status = restorePointFailed
+			if success {
+				status = restorePointRehearsed
+			}
+		} else if record.VerifiedAt != nil {
+			status = restorePointVerified
+		}
+		points = append(points, restorePoint{record: record, status: status})
+	}
+	return points, nil
+}
+
+// printTimelineMonth prints a month as a calendar, coloring the days with
+// restore points by the best verified backup of the day
+func printTimelineMonth(month time.Time, points []restorePoint) {
+	best := make(map[int]restorePointStatus)
+	for _, point := range points {
+		created := point.record.CreatedAt.Local()
+		if created.Year() != month.Year() || created.Month() != month.Month() {
+			continue
+		}
+		if status, ok := best[created.Day()]; !ok || point.status > status {
+			best[created.Day()] = point.status
+		}
+	}
+
+	fmt.Printf("\n  %s\n", month.Format("January 2006"))
+	fmt.Println(i18n.T("  Mo Tu We Th Fr Sa Su"))
+
+	// Weeks start on Monday
+	offset := (int(month.Weekday()) + 6) % 7
+	line := "  " + strings.Repeat("   ", offset)
+	days := month.AddDate(0, 1, -1).Day()
+	for day := 1; day <= days; day++ {
+		cell := fmt.Sprintf("%2d", day)
+		if status, ok := best[day]; ok {
+			cell = status.paint(cell)
+		}
+		line += cell + " "
+		if (offset+day)%7 == 0 || day == days {
+			fmt.Println(strings.TrimRight(line, " "))
+			line = "  "
+		}
+	}
+}
+
+// browseRestorePoints lets the user act on restore points until they quit
+func browseRestorePoints(cfg *config.Config, cmd *cobra.Command, points []restorePoint) {
+	for {
+		fmt.Println()
+		choice := utils.PromptForInput(fmt.Sprintf("Restore point (1-%d, empty to quit)", len(points)))
+		if choice == "" || choice == "q" {
+			return
+		}
+		index, err := strconv.Atoi(choice)
+		if err != nil || index < 1 || index > len(points) {
+			logger.Warning("⚠ Pick a number between 1 and %d", len(points))
+			continue
+		}
+		point := points[index-1]
+
+		action := utils.PromptForInput("[r]estore, [p]review or [d]iff with the previous restore point")
+		switch strings.ToLower(action) {
+		case "r", "restore":
+			restoreBackupFile = point.record.Filename
+			restorePreview = false
+			runRestore(cmd, nil)
+			return
+		case "p", "preview":
+			restoreBackupFile = point.record.Filename
+			restorePreview = true
+			runRestore(cmd, nil)
+		case "d", "diff":
+			printRestorePointDiff(point, previousRestorePoint(points, point))
+		default:
+			logger.Warning("⚠ Unknown action: %s", action)
+		}
+	}
+}
+
+// previousRestorePoint returns the restore point of the same manager made
+// just before point, if any. Points are newest first.
+func previousRestorePoint(points []restorePoint, point restorePoint) *restorePoint {
+	for i := range points {
+		candidate := points[i]
+		if candidate.record.Manager == point.record.Manager && candidate.record.CreatedAt.Before(point.record.CreatedAt) {
+			return &candidate
+		}
+	}
+	return nil
+}
+
+// printRestorePointDiff compares the vault statistics recorded for two restore points
+func printRestorePointDiff(point restorePoint, previous *restorePoint) {
+	logger.Separator()
+	if previous == nil {
+		logger.Info("%s is the oldest restore point shown for %s", point.record.Filename, managerDisplayName(point.record.Manager))
+		return
+	}
+	logger.Info("%s → %s", previous.record.Filename, point.record.Filename)
+
+	before, beforeOK := restorePointStats(*previous)
+	after, afterOK := restorePointStats(point)
+	if !beforeOK || !afterOK {
+		logger.Warning("⚠ Vault statistics were not recorded for both backups")
+	} else {
+		for _, row := range []struct {
+			name          string
+			before, after int
+		}{
+			{"Total items", before.TotalItems, after.TotalItems},
+			{"Logins", before.Logins, after.Logins},
+			{"Secure notes", before.Notes, after.Notes},
+			{"Cards", before.Cards, after.Cards},
+			{"Identities", before.Identities, after.Identities},
+			{"Other", before.Other, after.Other},
+		} {
+			logger.Info("  %-13s %5d → %5d  (%+d)", row.name, row.before, row.after, row.after-row.before)
+		}
+	}
+	logger.Info("  %-13s %s → %s", "Size", utils.FormatBytes(previous.record.Size), utils.FormatBytes(point.record.Size))
+	logger.Info("  %-13s %s → %s", "Status", previous.status.label(), point.status.label())
+}
+
+// restorePointStats returns the vault statistics recorded with a backup
+func restorePointStats(point restorePoint) (managers.VaultStats, bool) {
+	var stats managers.VaultStats
+	if point.record.Stats == nil {
+		return stats, false
+	}
+	if err := json.Unmarshal([]byte(*point.record.Stats), &stats); err != nil {
+		return stats, false
+	}
+	return stats, true
+}
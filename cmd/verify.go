@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/identity"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+var (
+	verifySignature bool
+	verifySource    string
+	verifyDecrypt   bool
+	verifyManager   string
+	verifyKeyFile   string
+	verifyDrill     bool
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [backup]",
+	Short: "Check stored backups for corruption or tampering",
+	Long: `Check that stored backups are intact and can be restored.
+
+Each stored copy of a backup is downloaded and checked in turn:
+1. Its header is an encryption or compression format stashr writes
+2. Its SHA-256 matches the checksum recorded when it was uploaded
+3. With --decrypt, it decrypts with the encryption password, key file or
+   age/GPG identity (unencrypted backups are always checked)
+4. Its vault data parses and has the item count recorded at backup time
+
+Every destination is checked unless --source names one, and the results are
+printed as a matrix of backups and destinations. The command exits with
+status 1 if any copy fails.
+
+With --drill, only the latest backup of each manager on each destination is
+restored, entirely in memory, and the result is recorded as a restore drill.
+The daemon runs drills on a schedule with backup.drills.enabled.
+
+With --signature, each backup is instead checked against the signature stored
+next to it when backup.signing is enabled. A backup fails if it was modified, if
+it was replaced by another backup, or if it was signed by a key that isn't
+trusted: this installation's identity key and backup.signing.trusted_keys.
+Without a backup, every backup in the --source destination is checked.`,
+	Example: `  # Check every stored copy of every backup
+  stashr verify
+
+  # Also test-decrypt them and check their vault data
+  stashr verify --decrypt
+
+  # Run a restore drill of the latest backups now
+  stashr verify --drill
+
+  # Check the Bitwarden backups in the USB drive
+  stashr verify --source usb --manager bitwarden
+
+  # Check every backup signature in Google Drive
+  stashr verify --signature --source gdrive
+
+  # Check one backup in a destination
+  stashr verify backup_bitwarden_20240101_120000.json.enc --signature --source gdrive
+
+  # Check a backup file and the .sig file next to it
+  stashr verify ~/Downloads/backup_bitwarden_20240101_120000.json.enc --signature`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().BoolVar(&verifySignature, "signature", false, "Check backup signatures")
+	verifyCmd.Flags().StringVarP(&verifySource, "source", "s", "", "Destination the backups are stored in (gdrive, onedrive, webdav, gcs, azure, s3, rclone, icloud, usb, local, git-annex, a profile or plugin name)")
+	verifyCmd.Flags().BoolVar(&verifyDecrypt, "decrypt", false, "Test-decrypt each backup and check its vault data")
+	verifyCmd.Flags().StringVarP(&verifyManager, "manager", "m", "", "Only check backups of this password manager (bitwarden, 1password)")
+	verifyCmd.Flags().StringVarP(&verifyKeyFile, "encryption-key", "k", "", "Key file to decrypt with (default: backup.encryption.key_file)")
+	verifyCmd.Flags().BoolVar(&verifyDrill, "drill", false, "Restore the latest backup of each manager on each destination in memory, and record the results")
+	verifyCmd.MarkFlagsMutuallyExclusive("signature", "decrypt", "drill")
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	logger.Header("🔏 Verify Backups")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	if verifyDrill {
+		runVerifyDrill(cfg)
+		return
+	}
+	if !verifySignature {
+		runIntegrityVerify(cfg, args)
+		return
+	}
+
+	if len(args) == 0 && verifySource == "" {
+		logger.Failure("Name a backup, or pass --source to check every backup in a destination")
+		return
+	}
+
+	trusted, err := trustedSigningKeys(cfg)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	// A backup file on disk has its signature next to it
+	if verifySource == "" {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			logger.PrintError(err)
+			logger.Info("To verify a backup stored in a destination, use --source")
+			return
+		}
+		signature, err := os.ReadFile(storage.SignatureName(args[0]))
+		if err != nil && !os.IsNotExist(err) {
+			logger.PrintError(err)
+			return
+		}
+		reportSignature(filepath.Base(args[0]), data, signature, trusted)
+		return
+	}
+
+	dest, err := findStorageDestination(cfg, verifySource)
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+	backend := dest.create()
+
+	var names []string
+	if len(args) == 1 {
+		names = []string{args[0]}
+	} else {
+		logger.Progress("Listing backups in %s...", backend.Name())
+		backups, err := backend.List()
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		for _, backup := range backups {
+			names = append(names, backup.Name)
+		}
+		if len(names) == 0 {
+			logger.Info("No backups in %s", backend.Name())
+			return
+		}
+	}
+
+	verified, unsigned, failed := 0, 0, 0
+	for _, name := range names {
+		data, err := backend.Download(name)
+		if err != nil {
+			logger.Failure("✗ %s: %v", name, err)
+			failed++
+			continue
+		}
+		// A missing signature is reported as unsigned
+		signature, _ := backend.Download(storage.SignatureName(name))
+		switch reportSignature(name, data, signature, trusted) {
+		case signatureValid:
+			verified++
+		case signatureMissing:
+			unsigned++
+		default:
+			failed++
+		}
+	}
+
+	logger.Separator()
+	switch {
+	case failed > 0:
+		logger.Failure("⛔ %d of %d backups failed signature verification", failed, len(names))
+	case unsigned > 0:
+		logger.Warning("⚠ %d of %d backups are not signed: made before backup.signing was enabled, or their signature was removed", unsigned, len(names))
+	default:
+		logger.Success("✅ All %d backups have valid signatures", verified)
+	}
+}
+
+// Results of checking a backup's signature
+const (
+	signatureValid = iota
+	signatureMissing
+	signatureInvalid
+)
+
+// reportSignature checks one backup against its signature and reports the result
+func reportSignature(name string, data, signatureFile []byte, trusted []ed25519.PublicKey) int {
+	if len(signatureFile) == 0 {
+		logger.Warning("⚠ %s: not signed", name)
+		return signatureMissing
+	}
+	signature, err := identity.VerifyBackup(signatureFile, name, data, trusted)
+	if err != nil {
+		logger.Failure("✗ %s: %v", name, err)
+		return signatureInvalid
+	}
+	logger.Success("✓ %s: signed by %s on %s", name, signature.Signer, signature.SignedAt.Local().Format("2006-01-02 15:04:05"))
+	return signatureValid
+}
+
+// signingIdentity loads the identity backups are signed with
+func signingIdentity() (*identity.Identity, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return identity.Load(dir)
+}
+
+// trustedSigningKeys returns the keys whose backup signatures are accepted:
+// this installation's identity key and backup.signing.trusted_keys
+func trustedSigningKeys(cfg *config.Config) ([]ed25519.PublicKey, error) {
+	trusted, err := cfg.Backup.Signing.PublicKeys()
+	if err != nil {
+		return nil, err
+	}
+	current, err := signingIdentity()
+	switch {
+	case err == nil:
+		trusted = append(trusted, current.PublicKey)
+	case errors.Is(err, identity.ErrNoIdentity):
+	default:
+		logger.Warning("⚠ Couldn't load this installation's identity, only backup.signing.trusted_keys are trusted: %v", err)
+	}
+	if len(trusted) == 0 {
+		return nil, errors.New("no trusted signing keys: create an identity (stashr identity create) or set backup.signing.trusted_keys")
+	}
+	return trusted, nil
+}
+
+// uploadSignature signs a backup and stores the signature next to it
+func uploadSignature(backend storage.Storage, signer *identity.Identity, filename string, data []byte) error {
+	signature, err := signer.SignBackup(filename, data)
+	if err != nil {
+		return err
+	}
+	return storage.UploadWithProgress(backend, storage.SignatureName(filename), signature, nil)
+}
+
+// withSignature extends a function removing a backup to also remove its
+// signature. Most backups have none, so failing to remove it is ignored.
+func withSignature(remove func(string) error) func(string) error {
+	return func(filename string) error {
+		if err := remove(filename); err != nil {
+			return err
+		}
+		_ = remove(storage.SignatureName(filename))
+		return nil
+	}
+}
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/backupname"
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/database"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/internal/managers"
+	"github.com/harshalranjhani/stashr/internal/secret"
+	"github.com/harshalranjhani/stashr/internal/storage"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// Checks a stored copy can fail, in the order they run. Each names its column
+// value in the verification matrix.
+const (
+	checkDownload = "download"
+	checkHeader   = "header"
+	checkChecksum = "checksum"
+	checkDecrypt  = "decrypt"
+	checkParse    = "parse"
+	checkItems    = "items"
+)
+
+// copyVerification is the result of verifying one stored copy of a backup
+type copyVerification struct {
+	// failed is the check that failed, or "" if every check passed
+	failed string
+	err    error
+	// format describes the file's header, e.g. "password-encrypted"
+	format string
+	// checksummed is set when the copy matched the checksum in the database
+	checksummed bool
+	// items is the number of items in the vault data, or -1 if it wasn't read
+	items int
+}
+
+// integrityVerifier verifies stored copies of backups, asking for each
+// encryption password once if --decrypt needs it
+type integrityVerifier struct {
+	cfg     *config.Config
+	names   *backupname.Parser
+	decrypt bool
+	// keyFile is the --encryption-key flag for backups encrypted with a key file
+	keyFile string
+	// interactive allows asking for passwords the environment and keychain don't have
+	interactive bool
+	// passwords are the shared password, under "", and the passwords of
+	// destinations with their own; nil when one wasn't given
+	passwords map[string]*secret.Buffer
+}
+
+// runIntegrityVerify verifies every stored copy of the selected backups and
+// prints a matrix of the results per destination
+func runIntegrityVerify(cfg *config.Config, args []string) {
+	// A cached copy proves nothing about the stored one
+	noCache = true
+
+	var backends []storage.Storage
+	if verifySource != "" {
+		dest, err := findStorageDestination(cfg, verifySource)
+		if err != nil {
+			logger.PrintError(err)
+			return
+		}
+		backends = []storage.Storage{dest.create()}
+	} else {
+		backends = getStorageBackendsForRestore(cfg)
+	}
+	if len(backends) == 0 {
+		logger.Failure("No storage destinations enabled")
+		return
+	}
+
+	verifier := &integrityVerifier{cfg: cfg, names: newBackupNameParser(cfg), decrypt: verifyDecrypt, keyFile: verifyKeyFile, interactive: true, passwords: make(map[string]*secret.Buffer)}
+	defer func() {
+		for _, password := range verifier.passwords {
+			password.Destroy()
+		}
+	}()
+	results := make(map[string]map[string]copyVerification)
+	modified := make(map[string]time.Time)
+	var columns []string
+	unreachable := make(map[string]string)
+	for _, backend := range backends {
+		columns = append(columns, backend.Name())
+		if available, err := backend.IsAvailable(); err != nil || !available {
+			reason := "not available"
+			if err != nil {
+				reason = err.Error()
+			}
+			logger.Failure("✗ %s: %s", backend.Name(), reason)
+			unreachable[backend.Name()] = reason
+			continue
+		}
+
+		logger.Progress("Listing backups in %s...", backend.Name())
+		backups, err := backend.List()
+		if err != nil {
+			logger.Failure("✗ %s: %v", backend.Name(), err)
+			unreachable[backend.Name()] = err.Error()
+			continue
+		}
+		for _, backup := range backups {
+			if len(args) == 1 && backup.Name != args[0] {
+				continue
+			}
+			if verifyManager != "" && detectManager(verifier.names, backup.Name) != verifyManager {
+				continue
+			}
+
+			result := verifier.verifyCopy(backend, backup.Name)
+			if result.failed != "" {
+				logger.Failure("✗ %s on %s: %s check failed: %v", backup.Name, backend.Name(), result.failed, result.err)
+			} else {
+				logger.Success("✓ %s on %s: %s", backup.Name, backend.Name(), result.describe())
+			}
+			if results[backup.Name] == nil {
+				results[backup.Name] = make(map[string]copyVerification)
+			}
+			results[backup.Name][backend.Name()] = result
+			if backup.ModifiedTime.After(modified[backup.Name]) {
+				modified[backup.Name] = backup.ModifiedTime
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		logger.Separator()
+		if len(unreachable) == len(backends) {
+			logger.Failure("⛔ No destination could be checked")
+			os.Exit(1)
+		}
+		logger.Info("No backups to verify")
+		return
+	}
+
+	// Newest first, like stashr list
+	rows := make([]string, 0, len(results))
+	for name := range results {
+		rows = append(rows, name)
+	}
+	sort.Slice(rows, func(i, j int) bool { return modified[rows[i]].After(modified[rows[j]]) })
+
+	copies, failed := 0, 0
+	now := time.Now()
+	for _, name := range rows {
+		// A backup whose every copy matched its checksum counts as verified, as after an upload
+		allChecksummed := true
+		for _, result := range results[name] {
+			copies++
+			if result.failed != "" {
+				failed++
+			}
+			allChecksummed = allChecksummed && result.checksummed
+		}
+		if allChecksummed {
+			if err := database.UpdateBackupVerified(name, now); err != nil {
+				logger.Debug("Failed to record verification of %s: %v", name, err)
+			}
+		}
+	}
+
+	logger.Separator()
+	printVerificationMatrix(rows, columns, results, unreachable)
+
+	logger.Separator()
+	if failed > 0 {
+		logger.Failure("⛔ %d of %d stored copies failed verification", failed, copies)
+		os.Exit(1)
+	}
+	if len(unreachable) > 0 {
+		logger.Warning("⚠ %d of %d destinations couldn't be checked", len(unreachable), len(backends))
+	}
+	if !verifyDecrypt {
+		logger.Info("Checked downloads, headers and checksums; add --decrypt to also check that the vault data decrypts and is complete")
+	}
+	logger.Success("✅ All %d stored copies of %d backups passed", copies, len(rows))
+}
+
+// verifyCopy downloads a stored copy and checks its header and recorded checksum,
+// then, with --decrypt or for an unencrypted backup, its vault data
+func (v *integrityVerifier) verifyCopy(backend storage.Storage, name string) copyVerification {
+	result := copyVerification{items: -1}
+	fail := func(check string, err error) copyVerification {
+		result.failed, result.err = check, err
+		return result
+	}
+
+	start := time.Now()
+	data, err := backend.Download(name)
+	recordDestinationOp(backend, database.OperationDownload, start, err)
+	if err != nil {
+		return fail(checkDownload, err)
+	}
+
+	// Checks of the content are recorded like test restores
+	verification := database.EventRecord{Kind: database.EventVerification, Manager: detectManager(v.names, name), StorageType: backend.Name(), Filename: name}
+	failContent := func(check string, err error) copyVerification {
+		recordEvent(verification, fmt.Errorf("%s check failed: %w", check, err))
+		return fail(check, err)
+	}
+
+	result.format = backupFormat(data)
+	if result.format == "" {
+		return failContent(checkHeader, errors.New("not a stashr backup: the header isn't an encryption or compression format stashr writes, or vault data"))
+	}
+
+	record, err := database.GetBackup(name)
+	if err != nil {
+		logger.Debug("Failed to read the record of %s: %v", name, err)
+	}
+	if recorded := recordedChecksum(name, backend.Name(), record); recorded != "" {
+		if checksum := utils.SHA256Hex(data); checksum != recorded {
+			return failContent(checkChecksum, fmt.Errorf("SHA-256 %s doesn't match the recorded %s", shortChecksum(checksum), shortChecksum(recorded)))
+		}
+		result.checksummed = true
+	}
+
+	encrypted := crypto.UsesKeyFile(data) || crypto.IsEncrypted(data) || publicKeyEncrypted(data)
+	if encrypted && !v.decrypt {
+		return result
+	}
+
+	plaintext := data
+	if encrypted {
+		if err := checkFIPSFile(v.cfg, name, data); err != nil {
+			return failContent(checkDecrypt, err)
+		}
+		plaintext, err = v.decryptCopy(backend, data)
+		if err != nil {
+			return failContent(checkDecrypt, err)
+		}
+	}
+	if isCompressedBackup(plaintext) {
+		if plaintext, err = decompressBackup(v.cfg, plaintext); err != nil {
+			return failContent(checkParse, fmt.Errorf("failed to decompress: %w", err))
+		}
+	}
+
+	result.items, err = managers.CountExportItems(plaintext)
+	if err != nil {
+		return failContent(checkParse, err)
+	}
+	if record != nil && record.ItemCount != nil && *record.ItemCount != result.items {
+		return failContent(checkItems, fmt.Errorf("recorded %d items, found %d", *record.ItemCount, result.items))
+	}
+
+	recordEvent(verification, nil)
+	markChecklistItem(checklistTestRestore)
+	return result
+}
+
+// decryptCopy decrypts a backup with its key file, the age or GPG identity or
+// the encryption password of its destination
+func (v *integrityVerifier) decryptCopy(backend storage.Storage, data []byte) ([]byte, error) {
+	switch {
+	case crypto.UsesKeyFile(data):
+		if !v.interactive && keyFileProtected(resolveKeyFile(v.cfg, v.keyFile)) {
+			return nil, errors.New("the key file is password-protected and its password can't be asked for")
+		}
+		return decryptKeyFileBackup(v.cfg, v.keyFile, data)
+	case publicKeyEncrypted(data):
+		return decryptPublicKeyBackup(v.cfg, data)
+	}
+
+	// Destinations with their own password are asked for it separately
+	key := ""
+	if destinationEncryption(v.cfg, backend).SeparatePassword {
+		key = backend.Name()
+	}
+	password, asked := v.passwords[key]
+	if !asked {
+		var err error
+		password, err = v.encryptionPassword(key)
+		if err != nil {
+			logger.Warning("⚠ %v", err)
+		}
+		if password.Len() == 0 {
+			password.Destroy()
+			password = nil
+		}
+		v.passwords[key] = password
+	}
+	if password == nil {
+		return nil, errors.New("no encryption password was given")
+	}
+	return crypto.DecryptWithPassword(data, password)
+}
+
+// encryptionPassword returns the shared password from STASHR_ENCRYPTION_PASSWORD
+// or the OS keychain, or asks for it. A destination's own password is always asked for.
+func (v *integrityVerifier) encryptionPassword(destination string) (*secret.Buffer, error) {
+	prompt := "Enter encryption password: "
+	if destination == "" {
+		if password := environmentPassword(); password != nil {
+			return password, nil
+		}
+		stored, err := keychainPassword(v.cfg)
+		if err != nil {
+			logger.Warning("⚠ %v", err)
+		}
+		if stored != nil {
+			return stored, nil
+		}
+	} else {
+		prompt = fmt.Sprintf("Enter encryption password for %s: ", destination)
+	}
+	if !v.interactive || !utils.IsTerminal() {
+		return nil, fmt.Errorf("no encryption password to decrypt with: set %s or store it in the OS keychain", passwordEnvVar)
+	}
+	return utils.PromptForSecret(prompt)
+}
+
+// describe summarizes the checks a copy passed
+func (r copyVerification) describe() string {
+	parts := []string{r.format}
+	if r.checksummed {
+		parts = append(parts, "checksum matches")
+	} else {
+		parts = append(parts, "no recorded checksum")
+	}
+	if r.items >= 0 {
+		parts = append(parts, fmt.Sprintf("%d items", r.items))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// backupFormat describes the header of a stored backup, or returns "" if it
+// isn't one stashr writes
+func backupFormat(data []byte) string {
+	switch {
+	case crypto.UsesKeyFile(data):
+		return "encrypted with a key file"
+	case crypto.IsEncrypted(data):
+		return "password-encrypted"
+	case crypto.IsAgeEncrypted(data):
+		return "encrypted to age recipients"
+	case crypto.IsGPGEncrypted(data):
+		return "encrypted to GPG recipients"
+	case isCompressedBackup(data):
+		return "unencrypted, compressed"
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "unencrypted"
+	}
+	return ""
+}
+
+// shortChecksum abbreviates a checksum for messages
+func shortChecksum(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return checksum
+}
+
+// printVerificationMatrix prints a row per backup and a column per destination:
+// OK, the check that failed, or - where the destination has no copy
+func printVerificationMatrix(rows, columns []string, results map[string]map[string]copyVerification, unreachable map[string]string) {
+	header := i18n.T("BACKUP")
+	width := len(header)
+	for _, name := range rows {
+		width = max(width, len(name))
+	}
+
+	fmt.Printf("%-*s", width, header)
+	for _, column := range columns {
+		fmt.Printf("  %-12s", column)
+	}
+	fmt.Println()
+	for _, name := range rows {
+		fmt.Printf("%-*s", width, name)
+		for _, column := range columns {
+			cell := "-"
+			if result, ok := results[name][column]; ok {
+				cell = valueOr(result.failed, i18n.T("OK"))
+			} else if _, ok := unreachable[column]; ok {
+				cell = i18n.T("unreachable")
+			}
+			fmt.Printf("  %-12s", cell)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/harshalranjhani/stashr/internal/config"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/keychain"
+	"github.com/harshalranjhani/stashr/internal/logger"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+var (
+	wipeLocal    bool
+	wipeKeychain bool
+)
+
+// wipeCmd represents the wipe command
+var wipeCmd = &cobra.Command{
+	Use:   "wipe",
+	Short: "Securely delete local plaintext artifacts and caches",
+	Long: `Sanitize this machine before handing it over, or after it was compromised.
+
+With --local, stashr overwrites and deletes the data it keeps on this machine
+outside your backups:
+  - the download cache of remote backups
+  - leftover unencrypted exports and staging files of interrupted backups,
+    migrations and rehearsals
+  - the anonymized export samples compression dictionaries are trained on
+
+With --keychain, the encryption password cached in the OS keychain is deleted too.
+
+Backups are never touched, on remote destinations or local ones, and neither are
+compression dictionaries, which restores need. Since every byte is gone for
+good, you must type a confirmation phrase; there is no --yes.
+
+Overwriting is best effort: SSDs and journaling or copy-on-write file systems
+may keep old copies of the data. Use full-disk encryption for stronger guarantees.`,
+	Example: `  stashr wipe --local --dry-run
+  stashr wipe --local
+  stashr wipe --local --keychain`,
+	Run: runWipe,
+}
+
+func init() {
+	rootCmd.AddCommand(wipeCmd)
+
+	wipeCmd.Flags().BoolVar(&wipeLocal, "local", false, "Wipe the plaintext artifacts and caches on this machine (required)")
+	wipeCmd.Flags().BoolVar(&wipeKeychain, "keychain", false, "Also delete the encryption password cached in the OS keychain")
+	wipeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be wiped without deleting anything")
+}
+
+// wipeTarget is a kind of local data wipe deletes
+type wipeTarget struct {
+	description string
+	paths       []string
+	files       int
+	size        int64
+}
+
+func runWipe(cmd *cobra.Command, args []string) {
+	logger.Header("🧽 Wipe Local Data")
+
+	if !wipeLocal {
+		logger.Failure("Specify --local: only data on this machine can be wiped, never remote backups")
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.PrintError(err)
+		return
+	}
+
+	if dryRun {
+		printDryRunHeader()
+	}
+
+	targets := wipeTargets(cfg)
+	total := 0
+	for _, target := range targets {
+		if target.files == 0 {
+			logger.Info("  %s: nothing to wipe", i18n.T(target.description))
+			continue
+		}
+		logger.Info("  %s: %d files (%s)", i18n.T(target.description), target.files, utils.FormatBytes(target.size))
+		total += target.files
+	}
+	if wipeKeychain {
+		logger.Info("  Cached encryption password in the OS keychain")
+	}
+	logger.Separator()
+
+	if total == 0 && !wipeKeychain {
+		logger.Success("✓ Nothing to wipe")
+		return
+	}
+
+	if dryRun {
+		var plan dryRunPlan
+		for _, target := range targets {
+			for _, path := range target.paths {
+				plan.Add("Overwrite and delete %s", path)
+			}
+		}
+		if wipeKeychain {
+			plan.Add("Delete the cached encryption password from the OS keychain")
+		}
+		plan.Print()
+		return
+	}
+
+	phrase := wipeConfirmPhrase()
+	logger.Warning("⚠️  This permanently deletes the data above. Your backups are not affected.")
+	fmt.Print(i18n.Tf("Type %q to continue: ", phrase))
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(input) != phrase {
+		logger.Info("Wipe cancelled")
+		return
+	}
+
+	failed := 0
+	for _, target := range targets {
+		for _, path := range target.paths {
+			if err := utils.SecureDelete(path); err != nil {
+				logger.Failure("✗ %v", err)
+				failed++
+			}
+		}
+		if len(target.paths) > 0 {
+			logger.Success("✓ Wiped %s", strings.ToLower(i18n.T(target.description)))
+		}
+	}
+
+	if wipeKeychain {
+		if err := keychain.Available(); err != nil {
+			logger.Warning("⚠ OS keychain not available: %v", err)
+		} else if err := keychain.Delete(keychain.PasswordAccount); err != nil {
+			logger.Failure("✗ Failed to delete the cached encryption password: %v", err)
+			failed++
+		} else {
+			logger.Success("✓ Deleted the cached encryption password from the OS keychain")
+		}
+	}
+
+	logger.Separator()
+	if failed > 0 {
+		logger.Warning("⚠ Wipe finished with %d errors", failed)
+		return
+	}
+	logger.Success("✅ Local data wiped")
+}
+
+// wipeTargets collects the local data wipe deletes. Backups on destinations and
+// compression dictionaries are deliberately not among it.
+func wipeTargets(cfg *config.Config) []wipeTarget {
+	var targets []wipeTarget
+
+	cache := wipeTarget{description: "Download cache"}
+	if cfg.Cache.Dir != "" {
+		cache.paths = existingPaths(cfg.Cache.Dir)
+	}
+	targets = append(targets, cache)
+
+	// Exports are staged in the temp directory, or the configured one
+	staging := wipeTarget{description: "Unencrypted exports and staging files"}
+	seen := make(map[string]bool)
+	for _, dir := range []string{backupTempDir(cfg), os.TempDir()} {
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		matches, _ := filepath.Glob(filepath.Join(dir, "stashr-*"))
+		staging.paths = append(staging.paths, matches...)
+	}
+	targets = append(targets, staging)
+
+	samples := wipeTarget{description: "Compression dictionary samples"}
+	if cfg.Backup.Dictionary.Dir != "" {
+		samples.paths = existingPaths(filepath.Join(cfg.Backup.Dictionary.Dir, "samples"))
+	}
+	targets = append(targets, samples)
+
+	// Encrypted, but the only copy of backups whose uploads all failed
+	spool := wipeTarget{description: "Spooled backups waiting for 'stashr retry'"}
+	if dir, err := config.GetConfigDir(); err == nil {
+		spool.paths = existingPaths(filepath.Join(dir, spoolDirName))
+	}
+	targets = append(targets, spool)
+
+	for i := range targets {
+		for _, path := range targets[i].paths {
+			filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+				if err == nil && info.Mode().IsRegular() {
+					targets[i].files++
+					targets[i].size += info.Size()
+				}
+				return nil
+			})
+		}
+	}
+	return targets
+}
+
+// existingPaths returns path if it exists
+func existingPaths(path string) []string {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return []string{path}
+}
+
+// wipeConfirmPhrase is what must be typed to wipe. It names the machine, so a
+// wipe over SSH doesn't hit the wrong one.
+func wipeConfirmPhrase() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "wipe this machine"
+	}
+	return "wipe " + host
+}
@@ -0,0 +1,273 @@
+// Package anonymize turns a decrypted vault export into a sample with the same
+// structure and fake values, safe to attach to bug reports about parsing and
+// importing exports.
+package anonymize
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Result describes an anonymized export
+type Result struct {
+	Data []byte
+	// Replaced is the number of values that were replaced with fake data
+	Replaced int
+}
+
+// structuralKeys hold values that describe the export's structure rather than
+// its contents, such as item types, so they are kept
+var structuralKeys = map[string]bool{
+	"type":        true,
+	"category":    true,
+	"kind":        true,
+	"object":      true,
+	"purpose":     true,
+	"designation": true,
+	"match":       true,
+	"format":      true,
+	"version":     true,
+	"encrypted":   true,
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?$`)
+	emailPattern     = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[A-Za-z]{2,}$`)
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Export anonymizes a JSON vault export. Keys, numbers, booleans, timestamps
+// and structural values are kept in their original order; every other string
+// is replaced. Names, usernames, URLs and IDs map to the same fake value each
+// time they appear, so references between items still line up; secrets and
+// notes are replaced by random text of the same length and character classes.
+func Export(data []byte) (*Result, error) {
+	a := &anonymizer{fakes: make(map[string]string), used: make(map[string]bool), counters: make(map[string]int)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	// frame is an object or array being copied
+	type frame struct {
+		object  bool
+		wantKey bool
+		count   int
+		// key is the current key, or for an array the key it is the value of
+		key string
+	}
+	var stack []*frame
+	var out bytes.Buffer
+
+	// beginValue writes the separator before a value in an array
+	beginValue := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if !top.object {
+			if top.count > 0 {
+				out.WriteByte(',')
+			}
+			top.count++
+		}
+	}
+	// endValue expects the next key after a value in an object
+	endValue := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].wantKey = true
+		}
+	}
+	currentKey := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].key
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export is not valid JSON: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				beginValue()
+				out.WriteByte(byte(t))
+				stack = append(stack, &frame{object: t == '{', wantKey: t == '{', key: currentKey()})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				out.WriteByte(byte(t))
+				endValue()
+			}
+			continue
+		case string:
+			if len(stack) > 0 && stack[len(stack)-1].object && stack[len(stack)-1].wantKey {
+				top := stack[len(stack)-1]
+				if top.count > 0 {
+					out.WriteByte(',')
+				}
+				top.count++
+				top.key = t
+				top.wantKey = false
+				writeJSON(&out, t)
+				out.WriteByte(':')
+				continue
+			}
+			beginValue()
+			writeJSON(&out, a.replace(currentKey(), t))
+		default:
+			beginValue()
+			writeJSON(&out, t)
+		}
+		endValue()
+	}
+	if dec.More() || len(stack) != 0 {
+		return nil, fmt.Errorf("export is not valid JSON")
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to format anonymized export: %w", err)
+	}
+	indented.WriteByte('\n')
+	return &Result{Data: indented.Bytes(), Replaced: a.replaced}, nil
+}
+
+// writeJSON writes one JSON value without HTML escaping
+func writeJSON(out *bytes.Buffer, value interface{}) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+	// Encode ends every value with a newline
+	out.Truncate(out.Len() - 1)
+}
+
+// anonymizer chooses the fake value for each string in an export
+type anonymizer struct {
+	// fakes maps original values to the fake ones that replaced them
+	fakes map[string]string
+	// used holds the fake values already handed out, so two originals never
+	// share one
+	used     map[string]bool
+	counters map[string]int
+	replaced int
+}
+
+// replace returns the value that replaces a string found under key
+func (a *anonymizer) replace(key, value string) string {
+	lower := strings.ToLower(key)
+	if value == "" || structuralKeys[lower] || timestampPattern.MatchString(value) {
+		return value
+	}
+	a.replaced++
+
+	switch {
+	case lower == "id" || strings.HasSuffix(lower, "id") || strings.HasSuffix(lower, "ids") || lower == "uuid":
+		return a.consistent("id", value, func(int) string {
+			if uuidPattern.MatchString(value) {
+				return randomUUID()
+			}
+			return scramble(value)
+		})
+	case strings.Contains(lower, "url") || strings.Contains(lower, "uri") || strings.Contains(lower, "href") ||
+		strings.Contains(lower, "website") || strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return a.consistent("url", value, func(n int) string {
+			return fmt.Sprintf("https://site%d.example.com/", n)
+		})
+	case emailPattern.MatchString(value):
+		return a.consistent("email", value, func(n int) string {
+			return fmt.Sprintf("user%d@example.com", n)
+		})
+	case strings.Contains(lower, "user") || strings.Contains(lower, "login") || strings.Contains(lower, "email"):
+		return a.consistent("user", value, func(n int) string {
+			return fmt.Sprintf("user%d", n)
+		})
+	case lower == "name" || lower == "title" || lower == "label" || lower == "folder" || lower == "vault":
+		return a.consistent("name", value, func(n int) string {
+			return fmt.Sprintf("Name %d", n)
+		})
+	default:
+		return scramble(value)
+	}
+}
+
+// consistent returns the fake value already chosen for an original value, or
+// makes one with the next number of its kind
+func (a *anonymizer) consistent(kind, value string, fake func(n int) string) string {
+	key := kind + "\x00" + value
+	if replacement, ok := a.fakes[key]; ok {
+		return replacement
+	}
+	a.counters[kind]++
+	replacement := fake(a.counters[kind])
+	for attempt := 0; a.used[kind+"\x00"+replacement]; attempt++ {
+		if attempt >= 10 {
+			// Short scrambled values run out of room
+			replacement = fmt.Sprintf("%s-%d", kind, a.counters[kind])
+			break
+		}
+		replacement = fake(a.counters[kind])
+	}
+	a.fakes[key] = replacement
+	a.used[kind+"\x00"+replacement] = true
+	return replacement
+}
+
+// scramble returns random text with the same length and character classes,
+// keeping punctuation and whitespace so multi-line notes stay multi-line
+func scramble(value string) string {
+	const (
+		lower  = "abcdefghijklmnopqrstuvwxyz"
+		upper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		digits = "0123456789"
+	)
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteByte(randomChar(lower))
+		case r >= 'A' && r <= 'Z':
+			b.WriteByte(randomChar(upper))
+		case r >= '0' && r <= '9':
+			b.WriteByte(randomChar(digits))
+		case r < 128:
+			b.WriteRune(r)
+		default:
+			// Other scripts are replaced too, as they may be names
+			b.WriteByte(randomChar(lower))
+		}
+	}
+	return b.String()
+}
+
+// randomChar picks a random byte from chars
+func randomChar(chars string) byte {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	if err != nil {
+		// crypto/rand doesn't fail on supported platforms
+		panic(err)
+	}
+	return chars[n.Int64()]
+}
+
+// randomUUID returns a random version 4 UUID
+func randomUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
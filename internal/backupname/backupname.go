@@ -0,0 +1,315 @@
+// Package backupname reads the manager and time a backup was made from its
+// filename. It understands every naming format stashr and credstash (its
+// former name) have used, and filename_format templates users configured.
+package backupname
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampLayout is the time format backup filenames embed
+const TimestampLayout = "20060102_150405"
+
+// CanonicalExtension marks a stashr backup whatever its encoding; readers
+// detect encryption and compression from the content
+const CanonicalExtension = ".stashr"
+
+// Format names the naming scheme a filename matched
+const (
+	// FormatStashr is the backup_<manager>_<timestamp> scheme stashr writes
+	FormatStashr = "stashr"
+	// FormatCredstash is the scheme used before the project was renamed
+	FormatCredstash = "credstash"
+	// FormatTemplate is a user-configured filename_format
+	FormatTemplate = "template"
+	// FormatUnknown is a filename no format matched; fields were guessed
+	FormatUnknown = "unknown"
+)
+
+// Name is what a backup filename says about the backup
+type Name struct {
+	// Manager is the password manager, e.g. "bitwarden", or "" if the name doesn't say
+	Manager string
+	// Timestamp is when the backup was made, or zero if the name doesn't say
+	Timestamp time.Time
+	// Encrypted and Compressed are read from the file extension. A
+	// CanonicalExtension name doesn't say, so both are false.
+	Encrypted  bool
+	Compressed bool
+	// Format is the naming scheme that matched
+	Format string
+	// Version is the -N suffix the backup was renamed with because another
+	// backup had its name, or 0
+	Version int
+}
+
+// HasTimestamp reports whether the filename recorded when the backup was made
+func (n Name) HasTimestamp() bool {
+	return !n.Timestamp.IsZero()
+}
+
+// builtinFormats are the filename formats stashr and credstash have written, newest first
+var builtinFormats = []struct {
+	format   string
+	template string
+}{
+	{FormatStashr, "backup_%s_%s.json.enc"},
+	{FormatStashr, "backup_%s_%s.json.gz.age"},
+	{FormatStashr, "backup_%s_%s.json.zst.age"},
+	{FormatStashr, "backup_%s_%s.json.xz.age"},
+	{FormatStashr, "backup_%s_%s.json.age"},
+	{FormatStashr, "backup_%s_%s.json.gz.gpg"},
+	{FormatStashr, "backup_%s_%s.json.zst.gpg"},
+	{FormatStashr, "backup_%s_%s.json.xz.gpg"},
+	{FormatStashr, "backup_%s_%s.json.gpg"},
+	{FormatStashr, "backup_%s_%s.json.gz"},
+	{FormatStashr, "backup_%s_%s.json.zst"},
+	{FormatStashr, "backup_%s_%s.json.xz"},
+	{FormatStashr, "backup_%s_%s.json"},
+	{FormatStashr, "backup_%s_%s" + CanonicalExtension},
+	{FormatCredstash, "credstash_%s_%s.json.enc"},
+	{FormatCredstash, "credstash_%s_%s.enc"},
+	{FormatCredstash, "credstash-%s-%s.enc"},
+}
+
+// knownManagers are the manager names recognized in filenames no format matched
+var knownManagers = []string{"bitwarden", "1password"}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{8}_\d{6}`)
+	managerPattern   = `([A-Za-z0-9][A-Za-z0-9-]*?)`
+	// versionPattern splits a versioned base name into its stem, version and extension
+	versionPattern = regexp.MustCompile(`^([^.]*)-(\d+)(\..*)?$`)
+)
+
+// Versioned returns the name of version n of a backup filename: the -N suffix
+// goes before the extension, e.g. backup_bitwarden_20240115_143022-1.json.enc
+func Versioned(filename string, n int) string {
+	dir, base := path.Split(filename)
+	stem, extension := base, ""
+	if i := strings.Index(base, "."); i > 0 {
+		stem, extension = base[:i], base[i:]
+	}
+	return dir + stem + "-" + strconv.Itoa(n) + extension
+}
+
+// pattern is a compiled filename template
+type pattern struct {
+	format string
+	regex  *regexp.Regexp
+	// managerGroup and timestampGroup are the submatch indexes, or 0 if absent
+	managerGroup   int
+	timestampGroup int
+}
+
+// Parser parses filenames against the built-in formats and user templates
+type Parser struct {
+	patterns []pattern
+	// aliases maps obfuscated names to the names they stand for
+	aliases map[string]string
+}
+
+// NewParser returns a parser that also understands the given filename_format
+// templates. Templates are tried before the built-in formats; invalid ones are ignored.
+func NewParser(templates ...string) *Parser {
+	p := &Parser{}
+	seen := make(map[string]bool)
+	for _, builtin := range builtinFormats {
+		seen[builtin.template] = true
+	}
+	for _, template := range templates {
+		if template == "" || seen[template] {
+			continue
+		}
+		seen[template] = true
+		if compiled, ok := compile(FormatTemplate, template); ok {
+			p.patterns = append(p.patterns, compiled)
+		}
+	}
+	for _, builtin := range builtinFormats {
+		if compiled, ok := compile(builtin.format, builtin.template); ok {
+			p.patterns = append(p.patterns, compiled)
+		}
+	}
+	return p
+}
+
+// defaultParser knows only the built-in formats
+var defaultParser = NewParser()
+
+// Parse parses a filename against the built-in formats
+func Parse(filename string) Name {
+	return defaultParser.Parse(filename)
+}
+
+// SetAliases tells the parser the names behind obfuscated filenames, which say
+// nothing about the backup themselves. aliases maps a base name to the name it
+// stands for.
+func (p *Parser) SetAliases(aliases map[string]string) {
+	p.aliases = aliases
+}
+
+// Original returns the name an obfuscated filename stands for, or its base name
+func (p *Parser) Original(filename string) string {
+	base := path.Base(filename)
+	if original, ok := p.aliases[base]; ok {
+		return original
+	}
+	return base
+}
+
+// Parse reads a backup filename, which may include its subfolders. A filename
+// no format matches still gets a best guess at its manager and timestamp.
+func (p *Parser) Parse(filename string) Name {
+	base := path.Base(filename)
+	if original, ok := p.aliases[base]; ok && original != base {
+		return p.Parse(original)
+	}
+	unencrypted := strings.TrimSuffix(strings.TrimSuffix(base, ".age"), ".gpg")
+	name := Name{
+		Encrypted:  strings.HasSuffix(base, ".enc") || strings.HasSuffix(base, ".age") || strings.HasSuffix(base, ".gpg"),
+		Compressed: strings.HasSuffix(unencrypted, ".gz") || strings.HasSuffix(unencrypted, ".zst") || strings.HasSuffix(unencrypted, ".xz"),
+		Format:     FormatUnknown,
+	}
+
+	for _, pat := range p.patterns {
+		match := pat.regex.FindStringSubmatch(base)
+		if match == nil {
+			continue
+		}
+		var timestamp time.Time
+		if pat.timestampGroup > 0 {
+			parsed, err := time.ParseInLocation(TimestampLayout, match[pat.timestampGroup], time.Local)
+			if err != nil {
+				continue
+			}
+			timestamp = parsed
+		}
+		name.Format = pat.format
+		name.Timestamp = timestamp
+		if pat.managerGroup > 0 {
+			name.Manager = strings.ToLower(match[pat.managerGroup])
+		}
+		return name
+	}
+
+	// A versioned name parses like the name it was versioned from
+	if match := versionPattern.FindStringSubmatch(base); match != nil {
+		if unversioned := p.Parse(match[1] + match[3]); unversioned.Format != FormatUnknown {
+			unversioned.Version, _ = strconv.Atoi(match[2])
+			return unversioned
+		}
+	}
+
+	lower := strings.ToLower(base)
+	for _, manager := range knownManagers {
+		if strings.Contains(lower, manager) {
+			name.Manager = manager
+			break
+		}
+	}
+	if stamp := timestampPattern.FindString(base); stamp != "" {
+		if parsed, err := time.ParseInLocation(TimestampLayout, stamp, time.Local); err == nil {
+			name.Timestamp = parsed
+		}
+	}
+	return name
+}
+
+// compile turns a filename_format template into a pattern
+func compile(format, template string) (pattern, bool) {
+	regex, err := regexp.Compile(templateRegex(template))
+	if err != nil {
+		return pattern{}, false
+	}
+	pat := pattern{format: format, regex: regex}
+	for i, verb := range templateVerbs(template) {
+		switch verb {
+		case 1:
+			pat.managerGroup = i + 1
+		case 2:
+			pat.timestampGroup = i + 1
+		}
+	}
+	if pat.managerGroup == 0 && pat.timestampGroup == 0 {
+		return pattern{}, false
+	}
+	return pat, true
+}
+
+// templateRegex converts a template to an anchored regular expression. The
+// template is filled in like fmt.Sprintf(template, manager, timestamp), so %s
+// verbs take the arguments in order and %[n]s picks one.
+func templateRegex(template string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	arg := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			b.WriteString(regexp.QuoteMeta(template[i : i+1]))
+			continue
+		}
+		verb, width, ok := readVerb(template[i:], arg)
+		if !ok {
+			b.WriteString(regexp.QuoteMeta(template[i : i+1]))
+			continue
+		}
+		i += width - 1
+		switch verb {
+		case 0:
+			b.WriteString("%")
+		case 1:
+			b.WriteString(managerPattern)
+			arg = 1
+		case 2:
+			b.WriteString(`(\d{8}_\d{6})`)
+			arg = 2
+		default:
+			b.WriteString(`(.*?)`)
+			arg = verb
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// templateVerbs lists the argument each capturing verb in a template refers to
+func templateVerbs(template string) []int {
+	var verbs []int
+	arg := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		verb, width, ok := readVerb(template[i:], arg)
+		if !ok {
+			continue
+		}
+		i += width - 1
+		if verb != 0 {
+			verbs = append(verbs, verb)
+			arg = verb
+		}
+	}
+	return verbs
+}
+
+// readVerb reads the verb at the start of s, which begins with '%'. It returns
+// the argument it refers to (0 for a literal %%), its length, and whether it
+// is a verb at all.
+func readVerb(s string, previousArg int) (int, int, bool) {
+	if strings.HasPrefix(s, "%%") {
+		return 0, 2, true
+	}
+	if strings.HasPrefix(s, "%s") || strings.HasPrefix(s, "%v") {
+		return previousArg + 1, 2, true
+	}
+	if len(s) >= 5 && s[1] == '[' && s[3] == ']' && s[2] >= '1' && s[2] <= '9' && (s[4] == 's' || s[4] == 'v') {
+		return int(s[2] - '0'), 5, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,54 @@
+// Package clipboard copies text to the system clipboard through the platform's
+// clipboard command. The text is written to the command's standard input, so it
+// never appears in a process list or on disk.
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnavailable is returned when no clipboard command is installed
+var ErrUnavailable = errors.New("no clipboard command found")
+
+// Available returns ErrUnavailable if no clipboard command is installed
+func Available() error {
+	_, err := copyCommand()
+	return err
+}
+
+// Copy replaces the clipboard contents with data
+func Copy(data []byte) error {
+	command, err := copyCommand()
+	if err != nil {
+		return err
+	}
+	return run(command, data)
+}
+
+// Clear empties the clipboard
+func Clear() error {
+	command, err := clearCommand()
+	if err != nil {
+		return err
+	}
+	return run(command, nil)
+}
+
+// run runs a clipboard command with data as its standard input
+func run(command []string, data []byte) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s failed: %w (%s)", command[0], err, message)
+		}
+		return fmt.Errorf("%s failed: %w", command[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package clipboard
+
+// copyCommand returns pbcopy, which every macOS has
+func copyCommand() ([]string, error) {
+	return []string{"pbcopy"}, nil
+}
+
+// clearCommand returns pbcopy, which empties the clipboard given no input
+func clearCommand() ([]string, error) {
+	return copyCommand()
+}
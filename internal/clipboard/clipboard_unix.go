@@ -0,0 +1,34 @@
+//go:build !darwin && !windows
+
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+)
+
+// copyCommand returns wl-copy on Wayland, or xclip or xsel on X11, whichever is installed
+func copyCommand() ([]string, error) {
+	return firstInstalled([]string{"wl-copy"}, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+}
+
+// clearCommand returns the command of the same tool emptying the clipboard;
+// xclip does given no input
+func clearCommand() ([]string, error) {
+	return firstInstalled([]string{"wl-copy", "--clear"}, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--clear"})
+}
+
+// firstInstalled returns the first command that is installed. The Wayland
+// command is only tried, before the X11 ones, in a Wayland session.
+func firstInstalled(wayland []string, x11 ...[]string) ([]string, error) {
+	candidates := x11
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{wayland}, x11...)
+	}
+	for _, command := range candidates {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return command, nil
+		}
+	}
+	return nil, ErrUnavailable
+}
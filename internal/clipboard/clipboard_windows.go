@@ -0,0 +1,17 @@
+package clipboard
+
+// copyCommand returns a PowerShell command reading standard input as UTF-8; the
+// older clip.exe mangles non-ASCII text
+func copyCommand() ([]string, error) {
+	return powershell("[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"), nil
+}
+
+// clearCommand returns a PowerShell command emptying the clipboard
+func clearCommand() ([]string, error) {
+	return powershell("Set-Clipboard -Value $null"), nil
+}
+
+// powershell returns the command running a PowerShell script
+func powershell(script string) []string {
+	return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}
+}
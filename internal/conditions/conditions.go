@@ -0,0 +1,20 @@
+// Package conditions reports the state of the machine that unattended backups
+// wait on: whether it runs on AC power and whether its network is metered.
+package conditions
+
+import "errors"
+
+// ErrUnknown is returned when the state can't be determined on this machine
+var ErrUnknown = errors.New("can't be determined on this machine")
+
+// OnACPower reports whether the machine runs on AC power. Machines without a
+// battery always do.
+func OnACPower() (bool, error) {
+	return onACPower()
+}
+
+// OnMeteredNetwork reports whether the default network connection is metered,
+// e.g. a mobile hotspot, as marked by the user or guessed by the OS
+func OnMeteredNetwork() (bool, error) {
+	return onMeteredNetwork()
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package conditions
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// NetworkManager's NMMetered values that mean the connection is metered
+const (
+	nmMeteredYes      = "1"
+	nmMeteredGuessYes = "3"
+)
+
+// onMeteredNetwork reads NetworkManager's Metered property over D-Bus, which
+// covers the primary connection. Machines without NetworkManager can't tell.
+func onMeteredNetwork() (bool, error) {
+	output, err := exec.Command("busctl", "get-property", "org.freedesktop.NetworkManager",
+		"/org/freedesktop/NetworkManager", "org.freedesktop.NetworkManager", "Metered").Output()
+	if err != nil {
+		return false, fmt.Errorf("can't ask NetworkManager: %w", err)
+	}
+	// busctl prints the type and value, e.g. "u 4"
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 || fields[0] != "u" {
+		return false, fmt.Errorf("unexpected busctl output: %s", strings.TrimSpace(string(output)))
+	}
+	return fields[1] == nmMeteredYes || fields[1] == nmMeteredGuessYes, nil
+}
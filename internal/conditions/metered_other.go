@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package conditions
+
+// onMeteredNetwork isn't supported on this platform. macOS has no command line
+// interface to its Low Data Mode.
+func onMeteredNetwork() (bool, error) {
+	return false, ErrUnknown
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package conditions
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// connectionCostScript prints the NetworkCostType of the internet connection:
+// Unrestricted, Fixed, Variable or Unknown
+const connectionCostScript = `[Windows.Networking.Connectivity.NetworkInformation, Windows.Networking.Connectivity, ContentType = WindowsRuntime] | Out-Null; ` +
+	`[Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile().GetConnectionCost().NetworkCostType`
+
+// onMeteredNetwork asks Windows for the cost of the internet connection, which
+// is metered when its data is billed (Fixed) or charged by use (Variable)
+func onMeteredNetwork() (bool, error) {
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", connectionCostScript).Output()
+	if err != nil {
+		return false, fmt.Errorf("can't read the connection cost: %w", err)
+	}
+	switch cost := strings.TrimSpace(string(output)); cost {
+	case "Unrestricted":
+		return false, nil
+	case "Fixed", "Variable":
+		return true, nil
+	default:
+		return false, ErrUnknown
+	}
+}
@@ -0,0 +1,26 @@
+//go:build darwin
+
+package conditions
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// onACPower asks pmset which power source the machine is drawing from
+func onACPower() (bool, error) {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, fmt.Errorf("pmset failed: %w", err)
+	}
+	// The first line is e.g. "Now drawing from 'Battery Power'"
+	line, _, _ := strings.Cut(string(output), "\n")
+	switch {
+	case strings.Contains(line, "'AC Power'"):
+		return true, nil
+	case strings.Contains(line, "'Battery Power'"), strings.Contains(line, "'UPS Power'"):
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected pmset output: %s", strings.TrimSpace(line))
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package conditions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// powerSupplyDir lists the power supplies known to the kernel
+const powerSupplyDir = "/sys/class/power_supply"
+
+// onACPower reads the power supplies in sysfs. The machine is on battery when a
+// system battery is discharging and no mains or USB supply is online.
+func onACPower() (bool, error) {
+	supplies, err := filepath.Glob(filepath.Join(powerSupplyDir, "*"))
+	if err != nil {
+		return false, err
+	}
+	discharging := false
+	for _, supply := range supplies {
+		switch readAttribute(supply, "type") {
+		case "Mains", "USB", "USB_C", "USB_PD":
+			if readAttribute(supply, "online") == "1" {
+				return true, nil
+			}
+		case "Battery":
+			// Batteries of wireless mice and keyboards don't power the machine
+			if readAttribute(supply, "scope") == "Device" {
+				continue
+			}
+			if readAttribute(supply, "status") == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return !discharging, nil
+}
+
+// readAttribute returns a power supply attribute, or "" if it isn't readable
+func readAttribute(supply, name string) string {
+	data, err := os.ReadFile(filepath.Join(supply, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package conditions
+
+// onACPower isn't supported on this platform
+func onACPower() (bool, error) {
+	return false, ErrUnknown
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package conditions
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetSystemPowerStatus = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus is SYSTEM_POWER_STATUS
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// acLineOnline and acLineUnknown are values of ACLineStatus
+const (
+	acLineOnline  = 1
+	acLineUnknown = 255
+)
+
+// onACPower reads the AC line status with GetSystemPowerStatus
+func onACPower() (bool, error) {
+	var status systemPowerStatus
+	if ret, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ret == 0 {
+		return false, fmt.Errorf("GetSystemPowerStatus failed: %w", err)
+	}
+	if status.ACLineStatus == acLineUnknown {
+		return false, ErrUnknown
+	}
+	return status.ACLineStatus == acLineOnline, nil
+}
@@ -1,19 +1,38 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
+	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
+
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/notify"
+	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
 // Config represents the application configuration
 type Config struct {
-	PasswordManagers PasswordManagers `yaml:"password_managers" mapstructure:"password_managers"`
-	Storage          Storage          `yaml:"storage" mapstructure:"storage"`
-	Backup           BackupConfig     `yaml:"backup" mapstructure:"backup"`
+	PasswordManagers PasswordManagers    `yaml:"password_managers" mapstructure:"password_managers"`
+	Storage          Storage             `yaml:"storage" mapstructure:"storage"`
+	Backup           BackupConfig        `yaml:"backup" mapstructure:"backup"`
+	Notifications    NotificationsConfig `yaml:"notifications" mapstructure:"notifications"`
+	Policies         []PolicyConfig      `yaml:"policies" mapstructure:"policies"`
+	Cache            CacheConfig         `yaml:"cache" mapstructure:"cache"`
+	Proofs           ProofsConfig        `yaml:"proofs" mapstructure:"proofs"`
+	Logging          LoggingConfig       `yaml:"logging" mapstructure:"logging"`
+	Language         string              `yaml:"language" mapstructure:"language"`
 }
 
 // PasswordManagers holds configuration for all password managers
@@ -27,6 +46,9 @@ type BitwardenConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
 	CLIPath string `yaml:"cli_path" mapstructure:"cli_path"`
 	Email   string `yaml:"email" mapstructure:"email"`
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+	// Labels classify this manager's backups (e.g. confidential, personal, work)
+	Labels []string `yaml:"labels" mapstructure:"labels"`
 }
 
 // OnePasswordConfig holds 1Password-specific configuration
@@ -34,6 +56,9 @@ type OnePasswordConfig struct {
 	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
 	CLIPath string `yaml:"cli_path" mapstructure:"cli_path"`
 	Account string `yaml:"account" mapstructure:"account"`
+	Timeout string `yaml:"timeout" mapstructure:"timeout"`
+	// Labels classify this manager's backups (e.g. confidential, personal, work)
+	Labels []string `yaml:"labels" mapstructure:"labels"`
 }
 
 // Storage holds configuration for all storage backends
@@ -41,47 +66,852 @@ type Storage struct {
 	GoogleDrive GoogleDriveConfig `yaml:"google_drive" mapstructure:"google_drive"`
 	USB         USBConfig         `yaml:"usb" mapstructure:"usb"`
 	Local       LocalConfig       `yaml:"local" mapstructure:"local"`
+	GitAnnex    GitAnnexConfig    `yaml:"git_annex" mapstructure:"git_annex"`
+	OneDrive    OneDriveConfig    `yaml:"onedrive" mapstructure:"onedrive"`
+	WebDAV      WebDAVConfig      `yaml:"webdav" mapstructure:"webdav"`
+	GCS         GCSConfig         `yaml:"gcs" mapstructure:"gcs"`
+	AzureBlob   AzureBlobConfig   `yaml:"azure_blob" mapstructure:"azure_blob"`
+	S3          S3Config          `yaml:"s3" mapstructure:"s3"`
+	Rclone      RcloneConfig      `yaml:"rclone" mapstructure:"rclone"`
+	ICloud      ICloudConfig      `yaml:"icloud" mapstructure:"icloud"`
+	// Destinations are additional named destinations of the built-in types
+	Destinations []DestinationConfig `yaml:"destinations" mapstructure:"destinations"`
+	// Plugins are destinations implemented by stashr-storage-<name> executables
+	Plugins []PluginConfig `yaml:"plugins" mapstructure:"plugins"`
+	// Retry retries operations on remote destinations that fail transiently
+	Retry RetryConfig `yaml:"retry" mapstructure:"retry"`
+	// Proxy routes the requests of cloud destinations through a proxy
+	Proxy ProxyConfig `yaml:"proxy" mapstructure:"proxy"`
+}
+
+// ProxyConfig holds the proxy cloud destinations connect through. Without a
+// URL the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables apply.
+type ProxyConfig struct {
+	// URL is an http://, https://, socks5:// or socks5h:// proxy, e.g. http://proxy.corp:3128
+	URL string `yaml:"url" mapstructure:"url"`
+	// NoProxy lists hosts and domains reached directly, comma-separated like NO_PROXY
+	NoProxy string `yaml:"no_proxy" mapstructure:"no_proxy"`
+}
+
+// proxySchemes are the proxy URL schemes the HTTP client supports
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// validate checks that a configured proxy URL has a supported scheme and a host
+func (p ProxyConfig) validate() error {
+	if p.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Host == "" || !slices.Contains(proxySchemes, u.Scheme) {
+		return fmt.Errorf("invalid storage.proxy.url: %s (use %s://host:port)", p.URL, strings.Join(proxySchemes, ", "))
+	}
+	return nil
+}
+
+// RetryConfig holds how uploads, downloads, listings and deletions on remote
+// destinations are retried after transient errors such as rate limits
+type RetryConfig struct {
+	// Attempts is how often an operation is tried in total; 1 disables retries
+	Attempts int `yaml:"attempts" mapstructure:"attempts"`
+	// InitialDelay is the wait before the first retry, doubled for each further one up to MaxDelay
+	InitialDelay string `yaml:"initial_delay" mapstructure:"initial_delay"`
+	MaxDelay     string `yaml:"max_delay" mapstructure:"max_delay"`
+	// Jitter randomizes each delay by up to this fraction, e.g. 0.2 for ±20%
+	Jitter float64 `yaml:"jitter" mapstructure:"jitter"`
+}
+
+const (
+	// DefaultRetryAttempts is how often remote operations are tried by default
+	DefaultRetryAttempts = 4
+	// DefaultRetryInitialDelay is the default wait before the first retry
+	DefaultRetryInitialDelay = "1s"
+	// DefaultRetryMaxDelay caps the wait between retries by default
+	DefaultRetryMaxDelay = "30s"
+	// DefaultRetryJitter is the default fraction delays are randomized by
+	DefaultRetryJitter = 0.2
+)
+
+// Delays returns the wait before the first retry and the longest wait between retries
+func (r RetryConfig) Delays() (time.Duration, time.Duration, error) {
+	initialValue, maxValue := r.InitialDelay, r.MaxDelay
+	if initialValue == "" {
+		initialValue = DefaultRetryInitialDelay
+	}
+	if maxValue == "" {
+		maxValue = DefaultRetryMaxDelay
+	}
+	initial, err := time.ParseDuration(initialValue)
+	if err != nil || initial < 0 {
+		return 0, 0, fmt.Errorf("invalid storage.retry.initial_delay: %s (use a duration, e.g. 1s)", initialValue)
+	}
+	maxDelay, err := time.ParseDuration(maxValue)
+	if err != nil || maxDelay < initial {
+		return 0, 0, fmt.Errorf("invalid storage.retry.max_delay: %s (use a duration of at least initial_delay, e.g. 30s)", maxValue)
+	}
+	return initial, maxDelay, nil
 }
 
 // GoogleDriveConfig holds Google Drive-specific configuration
 type GoogleDriveConfig struct {
-	Enabled         bool   `yaml:"enabled" mapstructure:"enabled"`
-	FolderID        string `yaml:"folder_id" mapstructure:"folder_id"`
-	CredentialsPath string `yaml:"credentials_path" mapstructure:"credentials_path"`
+	Enabled         bool                        `yaml:"enabled" mapstructure:"enabled"`
+	FolderID        string                      `yaml:"folder_id" mapstructure:"folder_id"`
+	CredentialsPath string                      `yaml:"credentials_path" mapstructure:"credentials_path"`
+	Encryption      DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	// DriveID selects a Shared Drive; leave empty to use My Drive
+	DriveID string `yaml:"drive_id" mapstructure:"drive_id"`
+	// PageSize is how many files each listing request returns, up to 1000; 0 uses 1000
+	PageSize  int              `yaml:"page_size,omitempty" mapstructure:"page_size"`
+	Artifact  ArtifactConfig   `yaml:"artifact" mapstructure:"artifact"`
+	Retention *RetentionConfig `yaml:"retention,omitempty" mapstructure:"retention"`
 }
 
 // USBConfig holds USB drive-specific configuration
 type USBConfig struct {
-	Enabled   bool   `yaml:"enabled" mapstructure:"enabled"`
-	MountPath string `yaml:"mount_path" mapstructure:"mount_path"`
-	BackupDir string `yaml:"backup_dir" mapstructure:"backup_dir"`
+	Enabled    bool                        `yaml:"enabled" mapstructure:"enabled"`
+	MountPath  string                      `yaml:"mount_path" mapstructure:"mount_path"`
+	BackupDir  string                      `yaml:"backup_dir" mapstructure:"backup_dir"`
+	Label      string                      `yaml:"label" mapstructure:"label"`
+	UUID       string                      `yaml:"uuid" mapstructure:"uuid"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
 }
 
 // LocalConfig holds local storage-specific configuration
 type LocalConfig struct {
-	Enabled    bool   `yaml:"enabled" mapstructure:"enabled"`
-	BackupPath string `yaml:"backup_path" mapstructure:"backup_path"`
+	Enabled    bool                        `yaml:"enabled" mapstructure:"enabled"`
+	BackupPath string                      `yaml:"backup_path" mapstructure:"backup_path"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// GitAnnexConfig holds git-annex repository-specific configuration
+type GitAnnexConfig struct {
+	Enabled    bool                        `yaml:"enabled" mapstructure:"enabled"`
+	RepoPath   string                      `yaml:"repo_path" mapstructure:"repo_path"`
+	BackupDir  string                      `yaml:"backup_dir" mapstructure:"backup_dir"`
+	Remotes    []string                    `yaml:"remotes" mapstructure:"remotes"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// OneDriveConfig holds OneDrive (Microsoft Graph) specific configuration
+type OneDriveConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// ClientID is the application (client) ID of an Azure app registration allowing public client flows
+	ClientID string `yaml:"client_id" mapstructure:"client_id"`
+	// Tenant is "common", "consumers", "organizations" or a directory (tenant) ID
+	Tenant     string                      `yaml:"tenant" mapstructure:"tenant"`
+	Folder     string                      `yaml:"folder" mapstructure:"folder"`
+	TokenPath  string                      `yaml:"token_path" mapstructure:"token_path"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// WebDAVConfig holds WebDAV (Nextcloud, ownCloud, ...) specific configuration
+type WebDAVConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// URL is the WebDAV root, e.g. https://cloud.example.com/remote.php/dav/files/<user>
+	URL      string `yaml:"url" mapstructure:"url"`
+	Username string `yaml:"username" mapstructure:"username"`
+	// Password is an app password; leave empty to read STASHR_WEBDAV_PASSWORD
+	Password   string                      `yaml:"password" mapstructure:"password"`
+	BackupDir  string                      `yaml:"backup_dir" mapstructure:"backup_dir"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// GCSConfig holds Google Cloud Storage specific configuration
+type GCSConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Bucket  string `yaml:"bucket" mapstructure:"bucket"`
+	Prefix  string `yaml:"prefix" mapstructure:"prefix"`
+	// CredentialsPath is a service account key file; leave empty to use Application Default Credentials
+	CredentialsPath string `yaml:"credentials_path" mapstructure:"credentials_path"`
+	// LockDays locks each backup against deletion and overwrites for this many
+	// days with object retention; 0 disables locking
+	LockDays   int                         `yaml:"lock_days,omitempty" mapstructure:"lock_days"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// AzureBlobConfig holds Azure Blob Storage specific configuration
+type AzureBlobConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// ConnectionString is the storage account connection string; leave empty to read
+	// STASHR_AZURE_CONNECTION_STRING
+	ConnectionString string `yaml:"connection_string" mapstructure:"connection_string"`
+	Container        string `yaml:"container" mapstructure:"container"`
+	Prefix           string `yaml:"prefix" mapstructure:"prefix"`
+	// LockDays locks each backup against deletion and overwrites for this many
+	// days with an immutability policy; 0 disables locking
+	LockDays   int                         `yaml:"lock_days,omitempty" mapstructure:"lock_days"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// S3Config holds configuration for Amazon S3 and S3-compatible services such as
+// Backblaze B2, Wasabi and MinIO
+type S3Config struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Endpoint is the host of the S3 API, e.g. "s3.us-west-004.backblazeb2.com";
+	// leave empty for Amazon S3. An http:// prefix connects without TLS.
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	Region   string `yaml:"region" mapstructure:"region"`
+	Bucket   string `yaml:"bucket" mapstructure:"bucket"`
+	Prefix   string `yaml:"prefix" mapstructure:"prefix"`
+	// AccessKeyID and SecretAccessKey are the access key (on B2, the application
+	// key ID and key); leave the secret empty to read STASHR_S3_SECRET_ACCESS_KEY,
+	// or both empty to use the AWS_* environment variables and ~/.aws/credentials
+	AccessKeyID     string `yaml:"access_key_id" mapstructure:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" mapstructure:"secret_access_key"`
+	// LockDays locks each backup against deletion and overwrites for this many
+	// days with Object Lock (on B2, file lock); 0 disables locking
+	LockDays   int                         `yaml:"lock_days,omitempty" mapstructure:"lock_days"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// RcloneConfig holds configuration for storing backups through an rclone remote
+type RcloneConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Remote is an rclone path such as "b2:my-bucket/stashr", using a remote from "rclone config"
+	Remote  string `yaml:"remote" mapstructure:"remote"`
+	CLIPath string `yaml:"cli_path" mapstructure:"cli_path"`
+	// ConfigPath overrides rclone's default config file; leave empty to use rclone's own
+	ConfigPath string                      `yaml:"config_path" mapstructure:"config_path"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// ICloudConfig holds iCloud Drive (macOS) specific configuration
+type ICloudConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// DrivePath is the iCloud Drive folder; leave empty for ~/Library/Mobile Documents/com~apple~CloudDocs
+	DrivePath string `yaml:"drive_path" mapstructure:"drive_path"`
+	BackupDir string `yaml:"backup_dir" mapstructure:"backup_dir"`
+	// SyncTimeout is how long to wait after each upload for iCloud to take the
+	// backup before warning that it hasn't, e.g. "2m"; "0s" checks only once
+	SyncTimeout string                      `yaml:"sync_timeout" mapstructure:"sync_timeout"`
+	Encryption  DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact    ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention   *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// DefaultICloudSyncTimeout is how long uploads wait for iCloud by default
+const DefaultICloudSyncTimeout = "2m"
+
+// Timeout returns how long to wait for iCloud after each upload
+func (c ICloudConfig) Timeout() (time.Duration, error) {
+	value := c.SyncTimeout
+	if value == "" {
+		value = DefaultICloudSyncTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid icloud sync_timeout: %s (use a duration, e.g. 2m)", value)
+	}
+	return timeout, nil
+}
+
+// PluginConfig holds configuration for a storage plugin
+type PluginConfig struct {
+	// Name selects the destination in --destination and names the executable
+	// stashr-storage-<name>
+	Name    string `yaml:"name" mapstructure:"name"`
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	// Path is the plugin executable; leave empty to find stashr-storage-<name> in PATH
+	Path string `yaml:"path" mapstructure:"path"`
+	// Options are passed to the plugin with every command
+	Options    map[string]string           `yaml:"options" mapstructure:"options"`
+	Encryption DestinationEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	Artifact   ArtifactConfig              `yaml:"artifact" mapstructure:"artifact"`
+	Retention  *RetentionConfig            `yaml:"retention,omitempty" mapstructure:"retention"`
+}
+
+// DestinationEncryptionConfig overrides the global encryption settings for one destination
+type DestinationEncryptionConfig struct {
+	// Mode is empty (inherit global settings), "password" (always encrypt) or
+	// "age" or "gpg" (encrypt to public keys). Overrides can only make a
+	// destination's copy stricter, so none of them stores it unencrypted.
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	// SeparatePassword requires a dedicated password for this destination
+	SeparatePassword bool `yaml:"separate_password" mapstructure:"separate_password"`
+	// Recipients replaces the global age or GPG recipients for this destination
+	Recipients []string `yaml:"recipients,omitempty" mapstructure:"recipients"`
+}
+
+// ArtifactConfig controls how backup files are named and labelled on one destination
+type ArtifactConfig struct {
+	// Extension replaces the .json.enc, .json.gz or .json extension of backup
+	// files, e.g. ".stashr"; leave empty to keep it
+	Extension string `yaml:"extension" mapstructure:"extension"`
+	// ContentType is the MIME type cloud destinations store with backups, e.g.
+	// "text/plain"; leave empty for application/octet-stream
+	ContentType string `yaml:"content_type" mapstructure:"content_type"`
+	// Dedup stores backups as content-defined chunks shared between backups,
+	// so unchanged parts of the vault are stored once
+	Dedup bool `yaml:"dedup" mapstructure:"dedup"`
+}
+
+const (
+	// EncryptionModeInherit uses the global encryption settings
+	EncryptionModeInherit = ""
+	// EncryptionModePassword encrypts with a password-derived key
+	EncryptionModePassword = "password"
+	// EncryptionModeAge encrypts to age recipients, so no password is needed
+	EncryptionModeAge = "age"
+	// EncryptionModeGPG encrypts to GPG recipients with the gpg tool
+	EncryptionModeGPG = "gpg"
+	// EncryptionModeNone stores the backup without encryption, when encryption
+	// is disabled globally; it isn't a destination override
+	EncryptionModeNone = "none"
+)
+
+// CacheConfig holds the download cache configuration for remote destinations
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Dir     string `yaml:"dir" mapstructure:"dir"`
+	// MaxSizeMB bounds the cache; least recently used backups are evicted first
+	MaxSizeMB int `yaml:"max_size_mb" mapstructure:"max_size_mb"`
+}
+
+const (
+	// DefaultCacheDir is where downloaded backups are cached
+	DefaultCacheDir = "~/.stashr/cache"
+	// DefaultCacheMaxSizeMB is the default cache size limit
+	DefaultCacheMaxSizeMB = 256
+)
+
+// ProofsConfig holds backup integrity proof publishing configuration. Only the
+// SHA-256 of each stored backup is published, never its content.
+type ProofsConfig struct {
+	Enabled bool            `yaml:"enabled" mapstructure:"enabled"`
+	Git     GitProofsConfig `yaml:"git" mapstructure:"git"`
+}
+
+// GitProofsConfig holds the git repository proofs are appended to
+type GitProofsConfig struct {
+	RepoPath string `yaml:"repo_path" mapstructure:"repo_path"`
+	File     string `yaml:"file" mapstructure:"file"`
+	// Remote is pushed to after every proof; leave empty to only commit locally
+	Remote string `yaml:"remote" mapstructure:"remote"`
+}
+
+// DefaultProofsFile is the file proofs are appended to in the proofs repository
+const DefaultProofsFile = "proofs.log"
+
+// LoggingConfig holds log file configuration
+type LoggingConfig struct {
+	// File receives a copy of all output; leave empty to log to the console only
+	File      string          `yaml:"file" mapstructure:"file"`
+	Redaction RedactionConfig `yaml:"redaction" mapstructure:"redaction"`
+}
+
+// RedactionConfig selects what is masked in log files and notifications so they
+// are safe to share. Console output is never redacted.
+type RedactionConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	Emails  bool `yaml:"emails" mapstructure:"emails"`
+	Tokens  bool `yaml:"tokens" mapstructure:"tokens"`
+	// Paths masks the home directory and the user name in paths and filenames
+	Paths bool `yaml:"paths" mapstructure:"paths"`
+	// Patterns are extra regular expressions whose matches are masked
+	Patterns []string `yaml:"patterns" mapstructure:"patterns"`
 }
 
 // BackupConfig holds backup-specific configuration
 type BackupConfig struct {
-	Encryption     EncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
-	Compression    bool             `yaml:"compression" mapstructure:"compression"`
-	Retention      RetentionConfig  `yaml:"retention" mapstructure:"retention"`
-	FilenameFormat string           `yaml:"filename_format" mapstructure:"filename_format"`
+	Encryption EncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	// Compression is the algorithm exports are compressed with: "gzip", "zstd",
+	// "xz" or "none". true and false, from before the algorithm could be
+	// chosen, mean gzip and none.
+	Compression string `yaml:"compression" mapstructure:"compression"`
+	// CompressionLevel is the compression algorithm's level, 0 for its default
+	CompressionLevel int              `yaml:"compression_level,omitempty" mapstructure:"compression_level"`
+	Retention        RetentionConfig  `yaml:"retention" mapstructure:"retention"`
+	FilenameFormat   string           `yaml:"filename_format" mapstructure:"filename_format"`
+	Validation       ValidationConfig `yaml:"validation" mapstructure:"validation"`
+	// MaxParallel is the number of managers backed up at once
+	MaxParallel int `yaml:"max_parallel" mapstructure:"max_parallel"`
+	// MaxParallelUploads is the number of destinations each backup is uploaded to at once
+	MaxParallelUploads int `yaml:"max_parallel_uploads" mapstructure:"max_parallel_uploads"`
+	// TempDir holds unencrypted exports until they are encrypted; empty uses the OS default
+	TempDir string `yaml:"temp_dir" mapstructure:"temp_dir"`
+	// FolderLayout organizes backups into subfolders: "flat", "manager" or "manager-month"
+	FolderLayout string `yaml:"folder_layout" mapstructure:"folder_layout"`
+	// ObfuscateNames uploads backups under random names that reveal neither the
+	// manager nor the date; the real names are kept in the local database and
+	// the encrypted header
+	ObfuscateNames bool `yaml:"obfuscate_names" mapstructure:"obfuscate_names"`
+	// OnCollision is what an upload does when the destination already has a file
+	// with its name: "version", "refuse" or "overwrite"
+	OnCollision string `yaml:"on_collision" mapstructure:"on_collision"`
+	// VerifyUploads checks each stored copy against the uploaded data
+	VerifyUploads bool `yaml:"verify_uploads" mapstructure:"verify_uploads"`
+	// FreeSpace checks that destinations have room for a backup before uploading it
+	FreeSpace FreeSpaceConfig `yaml:"free_space" mapstructure:"free_space"`
+	// Dictionary compresses encrypted backups with a dictionary trained on past exports
+	Dictionary DictionaryConfig `yaml:"dictionary" mapstructure:"dictionary"`
+	// Signing signs backups with the installation's identity key
+	Signing SigningConfig `yaml:"signing" mapstructure:"signing"`
+	// OnChange backs up vaults from the daemon when they change
+	OnChange ChangeBackupConfig `yaml:"on_change" mapstructure:"on_change"`
+	// Conditions postpone unattended backups while the machine is in a poor state to run them
+	Conditions ConditionsConfig `yaml:"conditions" mapstructure:"conditions"`
+	// Drills test-restore the latest backups from the daemon
+	Drills DrillConfig `yaml:"drills" mapstructure:"drills"`
+}
+
+// DrillConfig holds restore drills in daemon mode: the latest backup of each
+// manager on each destination is downloaded, decrypted and decompressed in
+// memory, and the result is recorded as evidence that it can be restored
+type DrillConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Interval is how often the drills run, e.g. "168h" for weekly
+	Interval string `yaml:"interval" mapstructure:"interval"`
+}
+
+// DefaultDrillInterval runs restore drills weekly by default
+const DefaultDrillInterval = "168h"
+
+// RunInterval returns how often restore drills run
+func (c DrillConfig) RunInterval() (time.Duration, error) {
+	value := c.Interval
+	if value == "" {
+		value = DefaultDrillInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < time.Hour {
+		return 0, fmt.Errorf("invalid backup.drills.interval: %s (use a duration of at least 1h, e.g. 168h)", value)
+	}
+	return interval, nil
+}
+
+// ConditionsConfig holds the conditions scheduled backups and the daemon's change
+// backups wait for. A postponed backup runs at the next scheduled time or change
+// check that finds them met. Backups run by hand ignore them.
+type ConditionsConfig struct {
+	// OnlyOnACPower postpones backups while the machine runs on battery
+	OnlyOnACPower bool `yaml:"only_on_ac_power" mapstructure:"only_on_ac_power"`
+	// OnlyOnUnmeteredNetwork postpones backups while the network connection is metered
+	OnlyOnUnmeteredNetwork bool `yaml:"only_on_unmetered_network" mapstructure:"only_on_unmetered_network"`
+	// MinFreeDiskMB postpones backups while the temporary directory has less free space; 0 disables it
+	MinFreeDiskMB int `yaml:"min_free_disk_mb" mapstructure:"min_free_disk_mb"`
+}
+
+// Enabled reports whether any condition is set
+func (c ConditionsConfig) Enabled() bool {
+	return c.OnlyOnACPower || c.OnlyOnUnmeteredNetwork || c.MinFreeDiskMB > 0
+}
+
+// ChangeBackupConfig holds change detection in daemon mode: the daemon polls each
+// manager for a cheap fingerprint of its vault and backs it up only when it changed
+type ChangeBackupConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Interval is how often the vaults are checked for changes, e.g. "15m"
+	Interval string `yaml:"interval" mapstructure:"interval"`
+}
+
+// DefaultChangeInterval is how often the daemon checks the vaults for changes by default
+const DefaultChangeInterval = "15m"
+
+// CheckInterval returns how often the vaults are checked for changes
+func (c ChangeBackupConfig) CheckInterval() (time.Duration, error) {
+	value := c.Interval
+	if value == "" {
+		value = DefaultChangeInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < time.Minute {
+		return 0, fmt.Errorf("invalid backup.on_change.interval: %s (use a duration of at least 1m, e.g. 15m)", value)
+	}
+	return interval, nil
+}
+
+// SigningConfig holds backup signing. Each backup gets a signature file next to
+// it, so a backup modified or replaced on a destination fails "stashr verify
+// --signature".
+type SigningConfig struct {
+	// Enabled signs every backup with the key created by "stashr identity create"
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// TrustedKeys are the base64 public keys ("stashr identity show") of other
+	// installations whose signatures are accepted, besides this one's
+	TrustedKeys []string `yaml:"trusted_keys,omitempty" mapstructure:"trusted_keys"`
 }
 
+// PublicKeys decodes the trusted public keys
+func (s SigningConfig) PublicKeys() ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(s.TrustedKeys))
+	for _, encoded := range s.TrustedKeys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid backup signing trusted key %q: expected a base64 Ed25519 public key", encoded)
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	return keys, nil
+}
+
+// DictionaryConfig holds compression dictionary training. Dictionaries are trained
+// on anonymized copies of past exports, so they hold the structure of a vault but
+// none of its contents.
+type DictionaryConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Dir holds the dictionaries and the samples they are trained on. Restoring a
+	// backup needs the dictionary it was compressed with.
+	Dir string `yaml:"dir" mapstructure:"dir"`
+	// Samples is how many past exports of each manager a dictionary is trained on
+	Samples int `yaml:"samples" mapstructure:"samples"`
+}
+
+const (
+	// DefaultDictionaryDir is where compression dictionaries are kept
+	DefaultDictionaryDir = "~/.stashr/dictionaries"
+	// DefaultDictionarySamples is how many past exports a dictionary is trained on
+	DefaultDictionarySamples = 5
+)
+
+// FreeSpaceConfig holds the free space check run before each upload
+type FreeSpaceConfig struct {
+	// Action is "refuse" (skip the destination), "warn" or "off"
+	Action string `yaml:"action" mapstructure:"action"`
+	// HeadroomMB is the space to leave free on top of the backup itself
+	HeadroomMB int `yaml:"headroom_mb" mapstructure:"headroom_mb"`
+}
+
+const (
+	// FreeSpaceRefuse skips destinations without room for the backup
+	FreeSpaceRefuse = "refuse"
+	// FreeSpaceWarn uploads anyway after a warning
+	FreeSpaceWarn = "warn"
+	// FreeSpaceOff doesn't check free space
+	FreeSpaceOff = "off"
+)
+
+// DefaultFreeSpaceHeadroomMB is the free space left on destinations by default
+const DefaultFreeSpaceHeadroomMB = 50
+
+const (
+	// FolderLayoutFlat stores every backup in the destination's backup folder
+	FolderLayoutFlat = "flat"
+	// FolderLayoutManager stores backups in a subfolder per manager, e.g. bitwarden/
+	FolderLayoutManager = "manager"
+	// FolderLayoutManagerMonth adds a subfolder per month, e.g. bitwarden/2025-01/
+	FolderLayoutManagerMonth = "manager-month"
+)
+
+const (
+	// CollisionVersion saves the backup as name-1, name-2, ... next to the existing file
+	CollisionVersion = "version"
+	// CollisionRefuse skips the destinations that already have the file
+	CollisionRefuse = "refuse"
+	// CollisionOverwrite replaces the existing file
+	CollisionOverwrite = "overwrite"
+)
+
 // EncryptionConfig holds encryption-specific configuration
 type EncryptionConfig struct {
 	Enabled   bool   `yaml:"enabled" mapstructure:"enabled"`
 	Algorithm string `yaml:"algorithm" mapstructure:"algorithm"`
+	// Iterations is the PBKDF2 iteration count new backups are encrypted
+	// with; each backup records its own, so changing it keeps older backups
+	// readable. 0 uses crypto.DefaultIterations.
+	Iterations int `yaml:"iterations,omitempty" mapstructure:"iterations"`
+	// Recipients are age public keys ("age1...") or SSH public keys that
+	// encrypted backups are encrypted to instead of a password
+	Recipients []string `yaml:"recipients,omitempty" mapstructure:"recipients"`
+	// IdentityFile is the age identity file or SSH private key that decrypts
+	// backups encrypted to the recipients
+	IdentityFile string `yaml:"identity_file,omitempty" mapstructure:"identity_file"`
+	// GPG encrypts backups to OpenPGP keys instead
+	GPG GPGConfig `yaml:"gpg,omitempty" mapstructure:"gpg"`
+	// KeyFile is a key file (see "stashr keyfile generate") backups are
+	// encrypted with instead of the shared password; --encryption-key overrides it
+	KeyFile string `yaml:"key_file,omitempty" mapstructure:"key_file"`
+	// Keychain reads the shared encryption password from the OS keychain
+	// (see "stashr keychain set") instead of prompting for it
+	Keychain bool `yaml:"keychain,omitempty" mapstructure:"keychain"`
+	// MinEntropy rejects new encryption passwords estimated to be weaker
+	// than this many bits; 0 only warns about weak ones
+	MinEntropy int `yaml:"min_entropy,omitempty" mapstructure:"min_entropy"`
+	// FIPS restricts encryption to FIPS-approved algorithms, see crypto.CheckFIPS:
+	// age and GPG are refused, and so are backups that use them
+	FIPS bool `yaml:"fips,omitempty" mapstructure:"fips"`
+}
+
+// GPGConfig holds the settings for encrypting backups with gpg
+type GPGConfig struct {
+	// Recipients are key IDs, fingerprints or email addresses of public keys
+	// in the gpg keyring; any of their secret keys decrypts a backup
+	Recipients []string `yaml:"recipients,omitempty" mapstructure:"recipients"`
+	// CLIPath is the gpg executable; empty uses gpg on the PATH
+	CLIPath string `yaml:"cli_path,omitempty" mapstructure:"cli_path"`
+}
+
+// Executable returns the gpg executable to run
+func (g GPGConfig) Executable() string {
+	if g.CLIPath == "" {
+		return crypto.DefaultGPGPath
+	}
+	return g.CLIPath
+}
+
+// MaxMinEntropy is the highest password strength policy allowed, in bits; any
+// stricter and few memorable passwords would pass
+const MaxMinEntropy = 128
+
+// CompressionAlgorithm returns the algorithm backups are compressed with
+func (b BackupConfig) CompressionAlgorithm() string {
+	// Booleans are decoded as "1" and "0"
+	switch strings.ToLower(b.Compression) {
+	case "true", "1", "yes", "on":
+		return utils.CompressionGzip
+	case "", "false", "0", "no", "off":
+		return utils.CompressionNone
+	default:
+		return strings.ToLower(b.Compression)
+	}
+}
+
+// Compressed reports whether backups are compressed
+func (b BackupConfig) Compressed() bool {
+	return b.CompressionAlgorithm() != utils.CompressionNone
+}
+
+// KeyIterations returns the PBKDF2 iterations new backups are encrypted with
+func (e EncryptionConfig) KeyIterations() int {
+	if e.Iterations == 0 {
+		return crypto.DefaultIterations
+	}
+	return e.Iterations
 }
 
+// ValidationConfig holds export sanity check configuration
+type ValidationConfig struct {
+	// TolerancePercent is the allowed difference between exported and reported item counts
+	TolerancePercent int `yaml:"tolerance_percent" mapstructure:"tolerance_percent"`
+	// AllowEmpty permits uploading exports with no items
+	AllowEmpty bool `yaml:"allow_empty" mapstructure:"allow_empty"`
+}
+
+const (
+	// DefaultMaxParallel backs up both supported managers at once
+	DefaultMaxParallel = 2
+	// DefaultMaxParallelUploads uploads to a typical cloud, USB and local setup at once
+	DefaultMaxParallelUploads = 4
+	// DefaultValidationTolerance is the default item count tolerance in percent
+	DefaultValidationTolerance = 5
+)
+
 // RetentionConfig holds retention policy configuration
 type RetentionConfig struct {
 	KeepLast int `yaml:"keep_last" mapstructure:"keep_last"`
+	// KeepDays keeps every backup made in the last N days
+	KeepDays int `yaml:"keep_days" mapstructure:"keep_days"`
+	// KeepDaily, KeepWeekly, KeepMonthly and KeepYearly keep the newest backup of
+	// each of the last N days, weeks, months and years (grandfather-father-son)
+	KeepDaily   int `yaml:"keep_daily" mapstructure:"keep_daily"`
+	KeepWeekly  int `yaml:"keep_weekly" mapstructure:"keep_weekly"`
+	KeepMonthly int `yaml:"keep_monthly" mapstructure:"keep_monthly"`
+	KeepYearly  int `yaml:"keep_yearly" mapstructure:"keep_yearly"`
+	// TrashDays moves the backups retention removes to the destination's trash,
+	// where `stashr prune --empty-trash` deletes them after N days. Destinations
+	// without a trash delete them right away; 0 always does.
+	TrashDays int `yaml:"trash_days,omitempty" mapstructure:"trash_days"`
+}
+
+// HasAgeRules reports whether the policy keeps backups by age or calendar
+// period, in addition to keep_last
+func (r RetentionConfig) HasAgeRules() bool {
+	return r.KeepDays > 0 || r.KeepDaily > 0 || r.KeepWeekly > 0 || r.KeepMonthly > 0 || r.KeepYearly > 0
+}
+
+// PolicyConfig restricts the destinations the backups of matching managers may be stored in.
+// A policy matches a manager by name and/or by one of its labels; with neither it matches all.
+type PolicyConfig struct {
+	Name    string `yaml:"name" mapstructure:"name"`
+	Manager string `yaml:"manager" mapstructure:"manager"`
+	Label   string `yaml:"label" mapstructure:"label"`
+	// AllowDestinations, when set, lists the only permitted destinations (e.g. usb, git-annex)
+	AllowDestinations []string `yaml:"allow_destinations" mapstructure:"allow_destinations"`
+	// DenyDestinations lists destinations that are never permitted
+	DenyDestinations []string `yaml:"deny_destinations" mapstructure:"deny_destinations"`
+}
+
+// Matches reports whether the policy applies to a manager with the given labels
+func (p PolicyConfig) Matches(manager string, labels []string) bool {
+	if p.Manager != "" && p.Manager != manager {
+		return false
+	}
+	if p.Label != "" {
+		for _, label := range labels {
+			if label == p.Label {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// NotificationsConfig holds notification channel and digest configuration
+type NotificationsConfig struct {
+	Webhook  WebhookConfig  `yaml:"webhook" mapstructure:"webhook"`
+	Email    EmailConfig    `yaml:"email" mapstructure:"email"`
+	Slack    SlackConfig    `yaml:"slack" mapstructure:"slack"`
+	Discord  DiscordConfig  `yaml:"discord" mapstructure:"discord"`
+	Telegram TelegramConfig `yaml:"telegram" mapstructure:"telegram"`
+	// Backups selects which backup results are sent: "failures", "all" or "none"
+	Backups string       `yaml:"backups" mapstructure:"backups"`
+	Digest  DigestConfig `yaml:"digest" mapstructure:"digest"`
+	Health  HealthConfig `yaml:"health" mapstructure:"health"`
+	Ping    PingConfig   `yaml:"ping" mapstructure:"ping"`
+}
+
+// Values of notifications.backups
+const (
+	BackupNotificationsFailures = "failures"
+	BackupNotificationsAll      = "all"
+	BackupNotificationsNone     = "none"
+)
+
+// ChannelEnabled reports whether any notification channel is enabled
+func (n NotificationsConfig) ChannelEnabled() bool {
+	return n.Webhook.Enabled || n.Email.Enabled || n.Slack.Enabled || n.Discord.Enabled || n.Telegram.Enabled
+}
+
+// WebhookConfig holds webhook notification configuration
+type WebhookConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	URL     string `yaml:"url" mapstructure:"url"`
+	// MinSeverity drops messages below "info", "warning" or "error"; empty sends all
+	MinSeverity string `yaml:"min_severity,omitempty" mapstructure:"min_severity"`
+}
+
+// SlackConfig holds Slack incoming webhook notification configuration
+type SlackConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// WebhookURL is read from the STASHR_SLACK_WEBHOOK_URL environment variable when empty
+	WebhookURL  string `yaml:"webhook_url" mapstructure:"webhook_url"`
+	MinSeverity string `yaml:"min_severity,omitempty" mapstructure:"min_severity"`
+}
+
+// URL returns the webhook URL from the config or the environment
+func (s SlackConfig) URL() string {
+	return valueOrEnv(s.WebhookURL, "STASHR_SLACK_WEBHOOK_URL")
+}
+
+// DiscordConfig holds Discord webhook notification configuration
+type DiscordConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// WebhookURL is read from the STASHR_DISCORD_WEBHOOK_URL environment variable when empty
+	WebhookURL  string `yaml:"webhook_url" mapstructure:"webhook_url"`
+	MinSeverity string `yaml:"min_severity,omitempty" mapstructure:"min_severity"`
+}
+
+// URL returns the webhook URL from the config or the environment
+func (d DiscordConfig) URL() string {
+	return valueOrEnv(d.WebhookURL, "STASHR_DISCORD_WEBHOOK_URL")
+}
+
+// TelegramConfig holds Telegram bot notification configuration
+type TelegramConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// BotToken is read from the STASHR_TELEGRAM_BOT_TOKEN environment variable when empty
+	BotToken string `yaml:"bot_token" mapstructure:"bot_token"`
+	// ChatID is the chat, group or channel the bot posts to
+	ChatID      string `yaml:"chat_id" mapstructure:"chat_id"`
+	MinSeverity string `yaml:"min_severity,omitempty" mapstructure:"min_severity"`
+	// APIURL is a self-hosted Bot API server; empty uses api.telegram.org
+	APIURL string `yaml:"api_url,omitempty" mapstructure:"api_url"`
+}
+
+// Token returns the bot token from the config or the environment
+func (t TelegramConfig) Token() string {
+	return valueOrEnv(t.BotToken, "STASHR_TELEGRAM_BOT_TOKEN")
+}
+
+// PingConfig holds the dead-man switch check non-interactive backups ping, which
+// alerts when the pings stop arriving
+type PingConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Service is "healthchecks" (Healthchecks.io or self-hosted) or "uptime-kuma"
+	Service string `yaml:"service" mapstructure:"service"`
+	// URL is the check's ping or push URL, read from the STASHR_PING_URL
+	// environment variable when empty
+	URL string `yaml:"url" mapstructure:"url"`
 }
 
+// Values of notifications.ping.service
+const (
+	PingServiceHealthchecks = notify.ServiceHealthchecks
+	PingServiceUptimeKuma   = notify.ServiceUptimeKuma
+)
+
+// PingURL returns the ping URL from the config or the environment
+func (p PingConfig) PingURL() string {
+	return valueOrEnv(p.URL, "STASHR_PING_URL")
+}
+
+// valueOrEnv returns value, or the environment variable when it is empty
+func valueOrEnv(value, env string) string {
+	if value == "" {
+		return os.Getenv(env)
+	}
+	return value
+}
+
+// EmailConfig holds SMTP email notification configuration
+type EmailConfig struct {
+	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`
+	SMTPHost string `yaml:"smtp_host" mapstructure:"smtp_host"`
+	SMTPPort int    `yaml:"smtp_port" mapstructure:"smtp_port"`
+	Username string `yaml:"username" mapstructure:"username"`
+	// Password is read from the STASHR_SMTP_PASSWORD environment variable when empty
+	Password    string   `yaml:"password" mapstructure:"password"`
+	From        string   `yaml:"from" mapstructure:"from"`
+	To          []string `yaml:"to" mapstructure:"to"`
+	MinSeverity string   `yaml:"min_severity,omitempty" mapstructure:"min_severity"`
+}
+
+// DigestConfig holds the weekly digest schedule used in daemon mode
+type DigestConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Weekday string `yaml:"weekday" mapstructure:"weekday"`
+	Time    string `yaml:"time" mapstructure:"time"`
+}
+
+// HealthConfig holds the password manager health checks run in daemon mode
+type HealthConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Interval is how often the manager CLIs are checked, e.g. "1h"
+	Interval string `yaml:"interval" mapstructure:"interval"`
+	// LockedDays is how long a session may stay locked before a notification is sent
+	LockedDays int `yaml:"locked_days" mapstructure:"locked_days"`
+}
+
+const (
+	// DefaultDigestWeekday is the default day the weekly digest is sent
+	DefaultDigestWeekday = "monday"
+	// DefaultDigestTime is the default time of day (HH:MM) the weekly digest is sent
+	DefaultDigestTime = "09:00"
+	// DefaultHealthInterval is how often the daemon checks the password manager CLIs by default
+	DefaultHealthInterval = "1h"
+	// DefaultHealthLockedDays is how many days a session may stay locked before notifying by default
+	DefaultHealthLockedDays = 3
+	// DefaultSMTPPort is the default SMTP submission port
+	DefaultSMTPPort = 587
+)
+
 const (
 	// DefaultConfigDir is the default directory for configuration files
 	DefaultConfigDir = ".stashr"
@@ -119,6 +949,15 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("configuration file not found at %s. Run 'stashr init' to create one", configPath)
 	}
 
+	return LoadFile(configPath)
+}
+
+// LoadFile loads the configuration from a specific file
+func LoadFile(configPath string) (*Config, error) {
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
 
@@ -126,6 +965,49 @@ func Load() (*Config, error) {
 	viper.SetEnvPrefix("stashr")
 	viper.AutomaticEnv()
 
+	// Defaults for settings added after the initial config format
+	viper.SetDefault("backup.validation.tolerance_percent", DefaultValidationTolerance)
+	viper.SetDefault("backup.max_parallel", DefaultMaxParallel)
+	viper.SetDefault("backup.max_parallel_uploads", DefaultMaxParallelUploads)
+	viper.SetDefault("backup.folder_layout", FolderLayoutFlat)
+	viper.SetDefault("backup.on_collision", CollisionVersion)
+	viper.SetDefault("backup.verify_uploads", true)
+	viper.SetDefault("backup.free_space.action", FreeSpaceRefuse)
+	viper.SetDefault("backup.free_space.headroom_mb", DefaultFreeSpaceHeadroomMB)
+	viper.SetDefault("backup.dictionary.dir", DefaultDictionaryDir)
+	viper.SetDefault("backup.dictionary.samples", DefaultDictionarySamples)
+	viper.SetDefault("backup.on_change.interval", DefaultChangeInterval)
+	viper.SetDefault("backup.drills.interval", DefaultDrillInterval)
+	viper.SetDefault("notifications.email.smtp_port", DefaultSMTPPort)
+	viper.SetDefault("notifications.backups", BackupNotificationsFailures)
+	viper.SetDefault("notifications.digest.weekday", DefaultDigestWeekday)
+	viper.SetDefault("notifications.digest.time", DefaultDigestTime)
+	viper.SetDefault("notifications.health.interval", DefaultHealthInterval)
+	viper.SetDefault("notifications.health.locked_days", DefaultHealthLockedDays)
+	viper.SetDefault("notifications.ping.service", PingServiceHealthchecks)
+	viper.SetDefault("storage.onedrive.tenant", "common")
+	viper.SetDefault("storage.onedrive.folder", "stashr")
+	viper.SetDefault("storage.onedrive.token_path", "~/.stashr/onedrive-token.json")
+	viper.SetDefault("storage.webdav.backup_dir", "stashr")
+	viper.SetDefault("storage.gcs.prefix", "stashr")
+	viper.SetDefault("storage.azure_blob.prefix", "stashr")
+	viper.SetDefault("storage.s3.prefix", "stashr")
+	viper.SetDefault("storage.rclone.cli_path", "rclone")
+	viper.SetDefault("storage.icloud.backup_dir", "stashr")
+	viper.SetDefault("storage.icloud.sync_timeout", DefaultICloudSyncTimeout)
+	viper.SetDefault("storage.retry.attempts", DefaultRetryAttempts)
+	viper.SetDefault("storage.retry.initial_delay", DefaultRetryInitialDelay)
+	viper.SetDefault("storage.retry.max_delay", DefaultRetryMaxDelay)
+	viper.SetDefault("storage.retry.jitter", DefaultRetryJitter)
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.dir", DefaultCacheDir)
+	viper.SetDefault("cache.max_size_mb", DefaultCacheMaxSizeMB)
+	viper.SetDefault("proofs.git.file", DefaultProofsFile)
+	viper.SetDefault("logging.redaction.enabled", true)
+	viper.SetDefault("logging.redaction.emails", true)
+	viper.SetDefault("logging.redaction.tokens", true)
+	viper.SetDefault("logging.redaction.paths", true)
+
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -134,12 +1016,22 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	for i := range cfg.Storage.Destinations {
+		cfg.Storage.Destinations[i].applyDefaults()
+	}
 
 	// Expand home directory in paths
 	if err := expandPaths(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to expand paths: %w", err)
 	}
 
+	// Apply the configured language unless overridden by STASHR_LANG
+	if cfg.Language != "" && !i18n.HasEnvOverride() {
+		if err := i18n.SetLanguage(cfg.Language); err != nil {
+			return nil, fmt.Errorf("invalid language in config: %w", err)
+		}
+	}
+
 	return &cfg, nil
 }
 
@@ -192,6 +1084,78 @@ func expandPaths(cfg *Config) error {
 		cfg.Storage.Local.BackupPath = expandHome(cfg.Storage.Local.BackupPath, home)
 	}
 
+	// Expand git-annex repository path
+	if cfg.Storage.GitAnnex.RepoPath != "" {
+		cfg.Storage.GitAnnex.RepoPath = expandHome(cfg.Storage.GitAnnex.RepoPath, home)
+	}
+
+	// Expand cache directory
+	if cfg.Cache.Dir != "" {
+		cfg.Cache.Dir = expandHome(cfg.Cache.Dir, home)
+	}
+
+	// Expand compression dictionary directory
+	if cfg.Backup.Dictionary.Dir != "" {
+		cfg.Backup.Dictionary.Dir = expandHome(cfg.Backup.Dictionary.Dir, home)
+	}
+
+	// Expand age identity file path
+	if cfg.Backup.Encryption.IdentityFile != "" {
+		cfg.Backup.Encryption.IdentityFile = expandHome(cfg.Backup.Encryption.IdentityFile, home)
+	}
+
+	// Expand encryption key file path
+	if cfg.Backup.Encryption.KeyFile != "" {
+		cfg.Backup.Encryption.KeyFile = expandHome(cfg.Backup.Encryption.KeyFile, home)
+	}
+
+	// Expand OneDrive token path
+	if cfg.Storage.OneDrive.TokenPath != "" {
+		cfg.Storage.OneDrive.TokenPath = expandHome(cfg.Storage.OneDrive.TokenPath, home)
+	}
+
+	// Expand GCS service account key path
+	if cfg.Storage.GCS.CredentialsPath != "" {
+		cfg.Storage.GCS.CredentialsPath = expandHome(cfg.Storage.GCS.CredentialsPath, home)
+	}
+
+	// Expand rclone config path
+	if cfg.Storage.Rclone.ConfigPath != "" {
+		cfg.Storage.Rclone.ConfigPath = expandHome(cfg.Storage.Rclone.ConfigPath, home)
+	}
+
+	// Expand iCloud Drive folder
+	if cfg.Storage.ICloud.DrivePath != "" {
+		cfg.Storage.ICloud.DrivePath = expandHome(cfg.Storage.ICloud.DrivePath, home)
+	}
+
+	// Expand destination profile paths
+	for i := range cfg.Storage.Destinations {
+		cfg.Storage.Destinations[i].expandPaths(home)
+	}
+
+	// Expand plugin executable paths
+	for i := range cfg.Storage.Plugins {
+		if cfg.Storage.Plugins[i].Path != "" {
+			cfg.Storage.Plugins[i].Path = expandHome(cfg.Storage.Plugins[i].Path, home)
+		}
+	}
+
+	// Expand proofs repository path
+	if cfg.Proofs.Git.RepoPath != "" {
+		cfg.Proofs.Git.RepoPath = expandHome(cfg.Proofs.Git.RepoPath, home)
+	}
+
+	// Expand export temp directory
+	if cfg.Backup.TempDir != "" {
+		cfg.Backup.TempDir = expandHome(cfg.Backup.TempDir, home)
+	}
+
+	// Expand log file path
+	if cfg.Logging.File != "" {
+		cfg.Logging.File = expandHome(cfg.Logging.File, home)
+	}
+
 	return nil
 }
 
@@ -203,6 +1167,126 @@ func expandHome(path, home string) string {
 	return path
 }
 
+// Schedule returns the weekday and time of day the digest is sent
+func (d DigestConfig) Schedule() (time.Weekday, int, int, error) {
+	weekday := d.Weekday
+	if weekday == "" {
+		weekday = DefaultDigestWeekday
+	}
+	clock := d.Time
+	if clock == "" {
+		clock = DefaultDigestTime
+	}
+
+	day := -1
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		if strings.EqualFold(i.String(), weekday) {
+			day = int(i)
+		}
+	}
+	if day < 0 {
+		return 0, 0, 0, fmt.Errorf("invalid digest weekday: %s", weekday)
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid digest time: %s (use HH:MM)", clock)
+	}
+
+	return time.Weekday(day), t.Hour(), t.Minute(), nil
+}
+
+// validate checks that every rule is non-negative and at least one keeps backups
+func (r RetentionConfig) validate() error {
+	for _, rule := range []struct {
+		name  string
+		value int
+	}{
+		{"keep_last", r.KeepLast},
+		{"keep_days", r.KeepDays},
+		{"keep_daily", r.KeepDaily},
+		{"keep_weekly", r.KeepWeekly},
+		{"keep_monthly", r.KeepMonthly},
+		{"keep_yearly", r.KeepYearly},
+		{"trash_days", r.TrashDays},
+	} {
+		if rule.value < 0 {
+			return fmt.Errorf("retention %s must not be negative", rule.name)
+		}
+	}
+	if r.KeepLast < 1 && !r.HasAgeRules() {
+		return fmt.Errorf("retention keep_last must be at least 1 unless keep_days or a keep_daily/weekly/monthly/yearly rule is set")
+	}
+	return nil
+}
+
+// CheckInterval returns how often the health checks run
+func (h HealthConfig) CheckInterval() (time.Duration, error) {
+	value := h.Interval
+	if value == "" {
+		value = DefaultHealthInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < time.Minute {
+		return 0, fmt.Errorf("invalid health check interval: %s (use a duration of at least 1m, e.g. 1h)", value)
+	}
+	return interval, nil
+}
+
+// labelPattern restricts classification labels to lowercase words
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// artifactExtensionPattern matches the file extensions destinations may give backups
+var artifactExtensionPattern = regexp.MustCompile(`^(\.[A-Za-z0-9]+)+$`)
+
+// ManagerLabels returns the classification labels configured for a password manager
+func (c *Config) ManagerLabels(manager string) []string {
+	switch manager {
+	case "bitwarden":
+		return c.PasswordManagers.Bitwarden.Labels
+	case "1password":
+		return c.PasswordManagers.OnePassword.Labels
+	default:
+		return nil
+	}
+}
+
+// validateLabels checks that labels are lowercase words such as "work" or "high-risk"
+func validateLabels(manager string, labels []string) error {
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return fmt.Errorf("invalid %s label %q: use lowercase letters, digits, '-' and '_'", manager, label)
+		}
+	}
+	return nil
+}
+
+// validateRecipients checks that age recipients are keys backups can be encrypted to
+func validateRecipients(name string, recipients []string) error {
+	for _, recipient := range recipients {
+		if _, err := crypto.ParseAgeRecipient(recipient); err != nil {
+			return fmt.Errorf("invalid age recipient for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ParseTimeout parses a duration such as "90s" or "5m". An empty value returns -1
+// so callers keep their default, and "0" disables the timeout.
+func ParseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return -1, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("timeout must not be negative")
+	}
+	return timeout, nil
+}
+
 // GetDefault returns a default configuration
 func GetDefault() *Config {
 	return &Config{
@@ -233,17 +1317,366 @@ func GetDefault() *Config {
 				Enabled:    false,
 				BackupPath: "~/.stashr/backups",
 			},
+			GitAnnex: GitAnnexConfig{
+				Enabled:   false,
+				RepoPath:  "",
+				BackupDir: "stashr",
+			},
+			OneDrive: OneDriveConfig{
+				Enabled:   false,
+				Tenant:    "common",
+				Folder:    "stashr",
+				TokenPath: "~/.stashr/onedrive-token.json",
+			},
+			WebDAV: WebDAVConfig{
+				Enabled:   false,
+				BackupDir: "stashr",
+			},
+			GCS: GCSConfig{
+				Enabled: false,
+				Prefix:  "stashr",
+			},
+			AzureBlob: AzureBlobConfig{
+				Enabled: false,
+				Prefix:  "stashr",
+			},
+			S3: S3Config{
+				Enabled: false,
+				Prefix:  "stashr",
+			},
+			Rclone: RcloneConfig{
+				Enabled: false,
+				CLIPath: "rclone",
+			},
+			ICloud: ICloudConfig{
+				Enabled:     false,
+				BackupDir:   "stashr",
+				SyncTimeout: DefaultICloudSyncTimeout,
+			},
+			Retry: RetryConfig{
+				Attempts:     DefaultRetryAttempts,
+				InitialDelay: DefaultRetryInitialDelay,
+				MaxDelay:     DefaultRetryMaxDelay,
+				Jitter:       DefaultRetryJitter,
+			},
 		},
 		Backup: BackupConfig{
 			Encryption: EncryptionConfig{
-				Enabled:   true,
-				Algorithm: "AES-256-GCM",
+				Enabled:    true,
+				Algorithm:  "AES-256-GCM",
+				Iterations: crypto.DefaultIterations,
+			},
+			Compression:        utils.CompressionGzip,
+			Retention:          RetentionConfig{KeepLast: 10},
+			FilenameFormat:     "backup_%s_%s.json.enc",
+			Validation:         ValidationConfig{TolerancePercent: DefaultValidationTolerance},
+			MaxParallel:        DefaultMaxParallel,
+			MaxParallelUploads: DefaultMaxParallelUploads,
+			FolderLayout:       FolderLayoutFlat,
+			OnCollision:        CollisionVersion,
+			VerifyUploads:      true,
+			FreeSpace: FreeSpaceConfig{
+				Action:     FreeSpaceRefuse,
+				HeadroomMB: DefaultFreeSpaceHeadroomMB,
+			},
+			Dictionary: DictionaryConfig{
+				Dir:     DefaultDictionaryDir,
+				Samples: DefaultDictionarySamples,
+			},
+			OnChange: ChangeBackupConfig{Interval: DefaultChangeInterval},
+			Drills:   DrillConfig{Interval: DefaultDrillInterval},
+		},
+		Notifications: NotificationsConfig{
+			Email:   EmailConfig{SMTPPort: DefaultSMTPPort},
+			Backups: BackupNotificationsFailures,
+			Digest: DigestConfig{
+				Enabled: false,
+				Weekday: DefaultDigestWeekday,
+				Time:    DefaultDigestTime,
+			},
+			Health: HealthConfig{
+				Enabled:    false,
+				Interval:   DefaultHealthInterval,
+				LockedDays: DefaultHealthLockedDays,
+			},
+			Ping: PingConfig{Service: PingServiceHealthchecks},
+		},
+		Cache: CacheConfig{
+			Enabled:   true,
+			Dir:       DefaultCacheDir,
+			MaxSizeMB: DefaultCacheMaxSizeMB,
+		},
+		Proofs: ProofsConfig{
+			Git: GitProofsConfig{File: DefaultProofsFile},
+		},
+		Logging: LoggingConfig{
+			Redaction: RedactionConfig{
+				Enabled: true,
+				Emails:  true,
+				Tokens:  true,
+				Paths:   true,
 			},
-			Compression:    true,
-			Retention:      RetentionConfig{KeepLast: 10},
-			FilenameFormat: "backup_%s_%s.json.enc",
 		},
+		Language: i18n.DefaultLanguage,
+	}
+}
+
+// Redacted returns a copy of the configuration with secrets masked, for display
+func (c Config) Redacted() Config {
+	if c.Storage.WebDAV.Password != "" {
+		c.Storage.WebDAV.Password = "********"
+	}
+	if c.Storage.AzureBlob.ConnectionString != "" {
+		c.Storage.AzureBlob.ConnectionString = "********"
+	}
+	if c.Storage.S3.SecretAccessKey != "" {
+		c.Storage.S3.SecretAccessKey = "********"
+	}
+	if c.Notifications.Email.Password != "" {
+		c.Notifications.Email.Password = "********"
+	}
+	// Webhook URLs embed their credentials
+	if c.Notifications.Slack.WebhookURL != "" {
+		c.Notifications.Slack.WebhookURL = "********"
+	}
+	if c.Notifications.Discord.WebhookURL != "" {
+		c.Notifications.Discord.WebhookURL = "********"
+	}
+	if c.Notifications.Telegram.BotToken != "" {
+		c.Notifications.Telegram.BotToken = "********"
+	}
+	// Anyone with a check's URL can report it as up
+	if c.Notifications.Ping.URL != "" {
+		c.Notifications.Ping.URL = "********"
+	}
+	if u, err := url.Parse(c.Storage.Proxy.URL); err == nil && u.User != nil {
+		c.Storage.Proxy.URL = u.Redacted()
+	}
+	// Copy destination profiles and plugins so masking them leaves the original config intact
+	c.Storage.Destinations = append([]DestinationConfig(nil), c.Storage.Destinations...)
+	for i, dest := range c.Storage.Destinations {
+		if dest.WebDAV != nil && dest.WebDAV.Password != "" {
+			dav := *dest.WebDAV
+			dav.Password = "********"
+			c.Storage.Destinations[i].WebDAV = &dav
+		}
+		if dest.AzureBlob != nil && dest.AzureBlob.ConnectionString != "" {
+			azure := *dest.AzureBlob
+			azure.ConnectionString = "********"
+			c.Storage.Destinations[i].AzureBlob = &azure
+		}
+		if dest.S3 != nil && dest.S3.SecretAccessKey != "" {
+			s3 := *dest.S3
+			s3.SecretAccessKey = "********"
+			c.Storage.Destinations[i].S3 = &s3
+		}
 	}
+	c.Storage.Plugins = append([]PluginConfig(nil), c.Storage.Plugins...)
+	for i, plugin := range c.Storage.Plugins {
+		options := make(map[string]string, len(plugin.Options))
+		for key, value := range plugin.Options {
+			if isSecretOption(key) && value != "" {
+				value = "********"
+			}
+			options[key] = value
+		}
+		c.Storage.Plugins[i].Options = options
+	}
+	return c
+}
+
+// isSecretOption reports whether a plugin option name suggests it holds a credential
+func isSecretOption(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"password", "secret", "token", "key", "credential"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaultExpanded returns the default configuration with paths expanded, as
+// Load would return it
+func GetDefaultExpanded() (*Config, error) {
+	cfg := GetDefault()
+	if err := expandPaths(cfg); err != nil {
+		return nil, fmt.Errorf("failed to expand paths: %w", err)
+	}
+	return cfg, nil
+}
+
+// validateBackends checks the settings of each enabled built-in destination
+func (s *Storage) validateBackends() error {
+	// Validate Google Drive configuration
+	if s.GoogleDrive.Enabled {
+		if s.GoogleDrive.CredentialsPath == "" {
+			return fmt.Errorf("google drive credentials path is required when google drive is enabled")
+		}
+		if s.GoogleDrive.PageSize < 0 || s.GoogleDrive.PageSize > 1000 {
+			return fmt.Errorf("google drive page_size must be between 1 and 1000, or 0 for the default")
+		}
+	}
+
+	// Validate USB configuration
+	if s.USB.Enabled {
+		if s.USB.MountPath == "" && s.USB.Label == "" && s.USB.UUID == "" {
+			return fmt.Errorf("USB mount path, label or uuid is required when USB is enabled")
+		}
+	}
+
+	// Validate Local storage configuration
+	if s.Local.Enabled {
+		if s.Local.BackupPath == "" {
+			return fmt.Errorf("local backup path is required when local storage is enabled")
+		}
+	}
+
+	// Validate git-annex configuration
+	if s.GitAnnex.Enabled {
+		if s.GitAnnex.RepoPath == "" {
+			return fmt.Errorf("git-annex repository path is required when git-annex is enabled")
+		}
+	}
+
+	// Validate OneDrive configuration
+	if s.OneDrive.Enabled {
+		if s.OneDrive.ClientID == "" {
+			return fmt.Errorf("onedrive client ID is required when onedrive is enabled")
+		}
+		if s.OneDrive.TokenPath == "" {
+			return fmt.Errorf("onedrive token path is required when onedrive is enabled")
+		}
+	}
+
+	// Validate WebDAV configuration
+	if s.WebDAV.Enabled {
+		if s.WebDAV.URL == "" {
+			return fmt.Errorf("webdav URL is required when webdav is enabled")
+		}
+		if !strings.HasPrefix(s.WebDAV.URL, "https://") && !strings.HasPrefix(s.WebDAV.URL, "http://") {
+			return fmt.Errorf("webdav URL must start with https:// or http://")
+		}
+	}
+
+	// Validate Google Cloud Storage configuration
+	if s.GCS.Enabled {
+		if s.GCS.Bucket == "" {
+			return fmt.Errorf("gcs bucket is required when gcs is enabled")
+		}
+		if s.GCS.LockDays < 0 {
+			return fmt.Errorf("gcs lock_days cannot be negative")
+		}
+	}
+
+	// Validate Azure Blob Storage configuration
+	if s.AzureBlob.Enabled {
+		if s.AzureBlob.Container == "" {
+			return fmt.Errorf("azure blob container is required when azure blob is enabled")
+		}
+		if s.AzureBlob.LockDays < 0 {
+			return fmt.Errorf("azure blob lock_days cannot be negative")
+		}
+	}
+
+	// Validate S3 configuration
+	if s.S3.Enabled {
+		if s.S3.Bucket == "" {
+			return fmt.Errorf("s3 bucket is required when s3 is enabled")
+		}
+		if s.S3.LockDays < 0 {
+			return fmt.Errorf("s3 lock_days cannot be negative")
+		}
+	}
+
+	// Validate rclone configuration
+	if s.Rclone.Enabled {
+		if s.Rclone.Remote == "" {
+			return fmt.Errorf("rclone remote is required when rclone is enabled")
+		}
+	}
+
+	// Validate iCloud Drive configuration
+	if s.ICloud.Enabled {
+		if _, err := s.ICloud.Timeout(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// anyNamedDestinationEnabled reports whether a destination profile or storage plugin is enabled
+func (c *Config) anyNamedDestinationEnabled() bool {
+	for _, dest := range c.Storage.Destinations {
+		if dest.Enabled() {
+			return true
+		}
+	}
+	for _, plugin := range c.Storage.Plugins {
+		if plugin.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateEncryptionOverrides checks the per-destination encryption overrides.
+// Commands that upload check them even where the rest of the configuration
+// isn't validated, since a bad override could weaken what is uploaded.
+func (c *Config) ValidateEncryptionOverrides() error {
+	destinations := map[string]DestinationEncryptionConfig{
+		"google_drive": c.Storage.GoogleDrive.Encryption,
+		"usb":          c.Storage.USB.Encryption,
+		"local":        c.Storage.Local.Encryption,
+		"git_annex":    c.Storage.GitAnnex.Encryption,
+		"onedrive":     c.Storage.OneDrive.Encryption,
+		"webdav":       c.Storage.WebDAV.Encryption,
+		"gcs":          c.Storage.GCS.Encryption,
+		"azure_blob":   c.Storage.AzureBlob.Encryption,
+		"s3":           c.Storage.S3.Encryption,
+		"rclone":       c.Storage.Rclone.Encryption,
+		"icloud":       c.Storage.ICloud.Encryption,
+	}
+	for _, dest := range c.Storage.Destinations {
+		destinations["destination "+dest.Name] = dest.Encryption()
+	}
+	for _, plugin := range c.Storage.Plugins {
+		destinations["plugin "+plugin.Name] = plugin.Encryption
+	}
+	for name, enc := range destinations {
+		switch enc.Mode {
+		case EncryptionModeInherit, EncryptionModePassword, EncryptionModeAge, EncryptionModeGPG:
+		case EncryptionModeNone:
+			return fmt.Errorf("%s can't set encryption mode none: a destination can only be held to a stricter standard; to store unencrypted backups, disable backup.encryption", name)
+		default:
+			return fmt.Errorf("invalid encryption mode for %s: %s (use: password, age or gpg)", name, enc.Mode)
+		}
+		if enc.SeparatePassword && enc.Mode != EncryptionModeInherit && enc.Mode != EncryptionModePassword {
+			return fmt.Errorf("%s cannot require a separate password with encryption mode %s", name, enc.Mode)
+		}
+		if c.Backup.Encryption.FIPS && (enc.Mode == EncryptionModeAge || enc.Mode == EncryptionModeGPG) {
+			return fmt.Errorf("%s uses encryption mode %s, which backup encryption fips doesn't allow", name, enc.Mode)
+		}
+		switch enc.Mode {
+		case EncryptionModeAge:
+			if len(enc.Recipients) == 0 && len(c.Backup.Encryption.Recipients) == 0 {
+				return fmt.Errorf("%s uses encryption mode age but no recipients are configured", name)
+			}
+			if err := validateRecipients(name, enc.Recipients); err != nil {
+				return err
+			}
+		case EncryptionModeGPG:
+			if len(enc.Recipients) == 0 && len(c.Backup.Encryption.GPG.Recipients) == 0 {
+				return fmt.Errorf("%s uses encryption mode gpg but no recipients are configured", name)
+			}
+		default:
+			if len(enc.Recipients) > 0 {
+				return fmt.Errorf("%s has recipients but its encryption mode is not age or gpg", name)
+			}
+		}
+	}
+	return nil
 }
 
 // Validate validates the configuration
@@ -254,7 +1687,8 @@ func (c *Config) Validate() error {
 	}
 
 	// Check if at least one storage backend is enabled
-	if !c.Storage.GoogleDrive.Enabled && !c.Storage.USB.Enabled && !c.Storage.Local.Enabled {
+	if !c.Storage.GoogleDrive.Enabled && !c.Storage.USB.Enabled && !c.Storage.Local.Enabled && !c.Storage.GitAnnex.Enabled && !c.Storage.OneDrive.Enabled && !c.Storage.WebDAV.Enabled &&
+		!c.Storage.GCS.Enabled && !c.Storage.AzureBlob.Enabled && !c.Storage.S3.Enabled && !c.Storage.Rclone.Enabled && !c.Storage.ICloud.Enabled && !c.anyNamedDestinationEnabled() {
 		return fmt.Errorf("at least one storage backend must be enabled")
 	}
 
@@ -263,6 +1697,12 @@ func (c *Config) Validate() error {
 		if c.PasswordManagers.Bitwarden.CLIPath == "" {
 			return fmt.Errorf("bitwarden CLI path is required when bitwarden is enabled")
 		}
+		if _, err := ParseTimeout(c.PasswordManagers.Bitwarden.Timeout); err != nil {
+			return fmt.Errorf("invalid bitwarden timeout: %w", err)
+		}
+		if err := validateLabels("bitwarden", c.PasswordManagers.Bitwarden.Labels); err != nil {
+			return err
+		}
 	}
 
 	// Validate 1Password configuration
@@ -270,32 +1710,330 @@ func (c *Config) Validate() error {
 		if c.PasswordManagers.OnePassword.CLIPath == "" {
 			return fmt.Errorf("1password CLI path is required when 1password is enabled")
 		}
+		if _, err := ParseTimeout(c.PasswordManagers.OnePassword.Timeout); err != nil {
+			return fmt.Errorf("invalid 1password timeout: %w", err)
+		}
+		if err := validateLabels("1password", c.PasswordManagers.OnePassword.Labels); err != nil {
+			return err
+		}
 	}
 
-	// Validate Google Drive configuration
-	if c.Storage.GoogleDrive.Enabled {
-		if c.Storage.GoogleDrive.CredentialsPath == "" {
-			return fmt.Errorf("google drive credentials path is required when google drive is enabled")
+	if err := c.Storage.validateBackends(); err != nil {
+		return err
+	}
+	if err := c.validateDestinations(); err != nil {
+		return err
+	}
+
+	// Validate export sanity checks
+	if c.Backup.Validation.TolerancePercent < 0 || c.Backup.Validation.TolerancePercent > 100 {
+		return fmt.Errorf("backup validation tolerance must be between 0 and 100 percent")
+	}
+
+	if err := c.ValidateEncryptionOverrides(); err != nil {
+		return err
+	}
+
+	// Validate per-destination file naming and labelling
+	artifacts := map[string]ArtifactConfig{
+		"google_drive": c.Storage.GoogleDrive.Artifact,
+		"usb":          c.Storage.USB.Artifact,
+		"local":        c.Storage.Local.Artifact,
+		"git_annex":    c.Storage.GitAnnex.Artifact,
+		"onedrive":     c.Storage.OneDrive.Artifact,
+		"webdav":       c.Storage.WebDAV.Artifact,
+		"gcs":          c.Storage.GCS.Artifact,
+		"azure_blob":   c.Storage.AzureBlob.Artifact,
+		"s3":           c.Storage.S3.Artifact,
+		"rclone":       c.Storage.Rclone.Artifact,
+		"icloud":       c.Storage.ICloud.Artifact,
+	}
+	for _, dest := range c.Storage.Destinations {
+		artifacts["destination "+dest.Name] = dest.Artifact()
+	}
+	for _, plugin := range c.Storage.Plugins {
+		artifacts["plugin "+plugin.Name] = plugin.Artifact
+	}
+	for name, artifact := range artifacts {
+		if artifact.Extension != "" && !artifactExtensionPattern.MatchString(artifact.Extension) {
+			return fmt.Errorf("invalid artifact extension for %s: %s (use a dot followed by letters and digits, e.g. .stashr)", name, artifact.Extension)
+		}
+		if artifact.ContentType != "" {
+			if _, _, err := mime.ParseMediaType(artifact.ContentType); err != nil {
+				return fmt.Errorf("invalid artifact content_type for %s: %s", name, artifact.ContentType)
+			}
 		}
 	}
 
-	// Validate USB configuration
-	if c.Storage.USB.Enabled {
-		if c.Storage.USB.MountPath == "" {
-			return fmt.Errorf("USB mount path is required when USB is enabled")
+	switch c.Backup.FreeSpace.Action {
+	case FreeSpaceRefuse, FreeSpaceWarn, FreeSpaceOff:
+	default:
+		return fmt.Errorf("invalid backup free_space action: %s (use: refuse, warn or off)", c.Backup.FreeSpace.Action)
+	}
+	if c.Backup.FreeSpace.HeadroomMB < 0 {
+		return fmt.Errorf("backup free_space headroom_mb cannot be negative")
+	}
+
+	if c.Backup.MaxParallel < 1 {
+		return fmt.Errorf("backup max_parallel must be at least 1")
+	}
+	if c.Backup.MaxParallelUploads < 1 {
+		return fmt.Errorf("backup max_parallel_uploads must be at least 1")
+	}
+
+	switch c.Backup.FolderLayout {
+	case "", FolderLayoutFlat, FolderLayoutManager, FolderLayoutManagerMonth:
+	default:
+		return fmt.Errorf("invalid backup folder_layout: %s (use: flat, manager or manager-month)", c.Backup.FolderLayout)
+	}
+	if c.Backup.ObfuscateNames && c.Backup.FolderLayout != "" && c.Backup.FolderLayout != FolderLayoutFlat {
+		return fmt.Errorf("backup obfuscate_names requires folder_layout: flat; %s subfolders would reveal the manager", c.Backup.FolderLayout)
+	}
+
+	switch c.Backup.OnCollision {
+	case "", CollisionVersion, CollisionRefuse, CollisionOverwrite:
+	default:
+		return fmt.Errorf("invalid backup on_collision: %s (use: version, refuse or overwrite)", c.Backup.OnCollision)
+	}
+
+	if iterations := c.Backup.Encryption.Iterations; iterations != 0 &&
+		(iterations < crypto.MinIterations || iterations > crypto.MaxIterations) {
+		return fmt.Errorf("invalid backup encryption iterations: %d (use %d to %d)", iterations, crypto.MinIterations, crypto.MaxIterations)
+	}
+	if minEntropy := c.Backup.Encryption.MinEntropy; minEntropy < 0 || minEntropy > MaxMinEntropy {
+		return fmt.Errorf("invalid backup encryption min_entropy: %d (use 0 to %d bits)", minEntropy, MaxMinEntropy)
+	}
+	if _, err := c.Backup.Signing.PublicKeys(); err != nil {
+		return err
+	}
+	if err := validateRecipients("backup encryption", c.Backup.Encryption.Recipients); err != nil {
+		return err
+	}
+	if len(c.Backup.Encryption.Recipients) > 0 && len(c.Backup.Encryption.GPG.Recipients) > 0 {
+		return fmt.Errorf("backup encryption can use age recipients or gpg recipients, not both")
+	}
+	if c.Backup.Encryption.FIPS {
+		if len(c.Backup.Encryption.Recipients) > 0 || len(c.Backup.Encryption.GPG.Recipients) > 0 {
+			return fmt.Errorf("backup encryption fips allows AES-256-GCM only: remove the age and gpg recipients")
+		}
+		if c.Backup.Encryption.KeyIterations() < crypto.FIPSMinIterations {
+			return fmt.Errorf("backup encryption fips requires at least %d iterations, got %d", crypto.FIPSMinIterations, c.Backup.Encryption.Iterations)
 		}
 	}
 
-	// Validate Local storage configuration
-	if c.Storage.Local.Enabled {
-		if c.Storage.Local.BackupPath == "" {
-			return fmt.Errorf("local backup path is required when local storage is enabled")
+	// Validate compression
+	algorithm := c.Backup.CompressionAlgorithm()
+	if !slices.Contains(utils.CompressionAlgorithms, algorithm) {
+		return fmt.Errorf("invalid backup compression: %s (use: %s)", c.Backup.Compression, strings.Join(utils.CompressionAlgorithms, ", "))
+	}
+	if level := c.Backup.CompressionLevel; level != 0 {
+		min, max := utils.CompressionLevels(algorithm)
+		if level < min || level > max {
+			if max == 0 {
+				return fmt.Errorf("backup compression_level needs a compression algorithm")
+			}
+			return fmt.Errorf("invalid backup compression_level %d for %s (use %d-%d, or 0 for the default)", level, algorithm, min, max)
+		}
+	}
+
+	// Validate compression dictionaries
+	if c.Backup.Dictionary.Enabled {
+		if !c.Backup.Compressed() {
+			return fmt.Errorf("backup.dictionary requires backup.compression")
+		}
+		if c.Backup.Dictionary.Dir == "" {
+			return fmt.Errorf("dictionary directory is required when backup.dictionary is enabled")
+		}
+		if c.Backup.Dictionary.Samples < 1 {
+			return fmt.Errorf("backup.dictionary samples must be at least 1")
+		}
+	}
+
+	// Validate download cache
+	if c.Cache.Enabled {
+		if c.Cache.Dir == "" {
+			return fmt.Errorf("cache directory is required when the cache is enabled")
+		}
+		if c.Cache.MaxSizeMB <= 0 {
+			return fmt.Errorf("cache max_size_mb must be positive")
+		}
+	}
+
+	// Validate proof publishing
+	if c.Proofs.Enabled {
+		if c.Proofs.Git.RepoPath == "" {
+			return fmt.Errorf("proofs git repo_path is required when proofs are enabled")
+		}
+		if c.Proofs.Git.File == "" || filepath.IsAbs(c.Proofs.Git.File) || strings.HasPrefix(filepath.Clean(c.Proofs.Git.File), "..") {
+			return fmt.Errorf("proofs git file must be a path inside the repository")
+		}
+	}
+
+	// Validate redaction patterns
+	for _, pattern := range c.Logging.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid logging redaction pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Validate destination policies
+	for i, policy := range c.Policies {
+		name := policy.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if len(policy.AllowDestinations) == 0 && len(policy.DenyDestinations) == 0 {
+			return fmt.Errorf("policy %s must set allow_destinations or deny_destinations", name)
+		}
+		if policy.Manager != "" && policy.Manager != "bitwarden" && policy.Manager != "1password" {
+			return fmt.Errorf("policy %s: unknown manager %s (use: bitwarden or 1password)", name, policy.Manager)
+		}
+		if policy.Label != "" {
+			if err := validateLabels("policy "+name, []string{policy.Label}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Validate notification channels
+	if c.Notifications.Webhook.Enabled && c.Notifications.Webhook.URL == "" {
+		return fmt.Errorf("webhook URL is required when webhook notifications are enabled")
+	}
+	if c.Notifications.Email.Enabled {
+		if c.Notifications.Email.SMTPHost == "" {
+			return fmt.Errorf("SMTP host is required when email notifications are enabled")
+		}
+		if c.Notifications.Email.From == "" || len(c.Notifications.Email.To) == 0 {
+			return fmt.Errorf("email notifications require a from address and at least one recipient")
+		}
+	}
+	if c.Notifications.Slack.Enabled && c.Notifications.Slack.URL() == "" {
+		return fmt.Errorf("notifications.slack.webhook_url (or STASHR_SLACK_WEBHOOK_URL) is required when Slack notifications are enabled")
+	}
+	if c.Notifications.Discord.Enabled && c.Notifications.Discord.URL() == "" {
+		return fmt.Errorf("notifications.discord.webhook_url (or STASHR_DISCORD_WEBHOOK_URL) is required when Discord notifications are enabled")
+	}
+	if c.Notifications.Telegram.Enabled {
+		if c.Notifications.Telegram.Token() == "" {
+			return fmt.Errorf("notifications.telegram.bot_token (or STASHR_TELEGRAM_BOT_TOKEN) is required when Telegram notifications are enabled")
+		}
+		if c.Notifications.Telegram.ChatID == "" {
+			return fmt.Errorf("notifications.telegram.chat_id is required when Telegram notifications are enabled")
+		}
+	}
+	for _, channel := range []struct{ name, severity string }{
+		{"webhook", c.Notifications.Webhook.MinSeverity},
+		{"email", c.Notifications.Email.MinSeverity},
+		{"slack", c.Notifications.Slack.MinSeverity},
+		{"discord", c.Notifications.Discord.MinSeverity},
+		{"telegram", c.Notifications.Telegram.MinSeverity},
+	} {
+		if _, err := notify.ParseSeverity(channel.severity); err != nil {
+			return fmt.Errorf("notifications.%s.min_severity: %w", channel.name, err)
+		}
+	}
+	switch c.Notifications.Backups {
+	case "", BackupNotificationsFailures, BackupNotificationsAll, BackupNotificationsNone:
+	default:
+		return fmt.Errorf("invalid notifications.backups: %s (use: failures, all, none)", c.Notifications.Backups)
+	}
+	if c.Notifications.Ping.Enabled {
+		switch c.Notifications.Ping.Service {
+		case "", PingServiceHealthchecks, PingServiceUptimeKuma:
+		default:
+			return fmt.Errorf("invalid notifications.ping.service: %s (use: healthchecks, uptime-kuma)", c.Notifications.Ping.Service)
+		}
+		pingURL := c.Notifications.Ping.PingURL()
+		if pingURL == "" {
+			return fmt.Errorf("notifications.ping.url (or STASHR_PING_URL) is required when pings are enabled")
+		}
+		if u, err := url.Parse(pingURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifications.ping.url must be an http or https URL")
+		}
+	}
+
+	// Validate digest schedule
+	if c.Notifications.Digest.Enabled {
+		if !c.Notifications.ChannelEnabled() {
+			return fmt.Errorf("the weekly digest requires a notification channel to be enabled")
+		}
+		if _, _, _, err := c.Notifications.Digest.Schedule(); err != nil {
+			return err
+		}
+	}
+	if c.Notifications.Health.Enabled {
+		if !c.Notifications.ChannelEnabled() {
+			return fmt.Errorf("health checks require a notification channel to be enabled")
+		}
+		if _, err := c.Notifications.Health.CheckInterval(); err != nil {
+			return err
+		}
+		if c.Notifications.Health.LockedDays < 0 {
+			return fmt.Errorf("notifications.health.locked_days must not be negative")
+		}
+	}
+	if c.Backup.OnChange.Enabled {
+		if _, err := c.Backup.OnChange.CheckInterval(); err != nil {
+			return err
+		}
+	}
+	if c.Backup.Drills.Enabled {
+		if _, err := c.Backup.Drills.RunInterval(); err != nil {
+			return err
+		}
+	}
+	if c.Backup.Conditions.MinFreeDiskMB < 0 {
+		return fmt.Errorf("backup.conditions.min_free_disk_mb must not be negative")
+	}
+
+	if err := c.Storage.Proxy.validate(); err != nil {
+		return err
+	}
+	if c.Storage.Retry.Attempts < 1 {
+		return fmt.Errorf("storage.retry.attempts must be at least 1")
+	}
+	if _, _, err := c.Storage.Retry.Delays(); err != nil {
+		return err
+	}
+	if c.Storage.Retry.Jitter < 0 || c.Storage.Retry.Jitter > 1 {
+		return fmt.Errorf("storage.retry.jitter must be between 0 and 1")
+	}
+
+	// Validate the retention policy and per-destination overrides
+	if err := c.Backup.Retention.validate(); err != nil {
+		return err
+	}
+	retentions := map[string]*RetentionConfig{
+		"google_drive": c.Storage.GoogleDrive.Retention,
+		"usb":          c.Storage.USB.Retention,
+		"local":        c.Storage.Local.Retention,
+		"git_annex":    c.Storage.GitAnnex.Retention,
+		"onedrive":     c.Storage.OneDrive.Retention,
+		"webdav":       c.Storage.WebDAV.Retention,
+		"gcs":          c.Storage.GCS.Retention,
+		"azure_blob":   c.Storage.AzureBlob.Retention,
+		"s3":           c.Storage.S3.Retention,
+		"rclone":       c.Storage.Rclone.Retention,
+		"icloud":       c.Storage.ICloud.Retention,
+	}
+	for _, dest := range c.Storage.Destinations {
+		retentions["destination "+dest.Name] = dest.Retention()
+	}
+	for _, plugin := range c.Storage.Plugins {
+		retentions["plugin "+plugin.Name] = plugin.Retention
+	}
+	for name, retention := range retentions {
+		if retention == nil {
+			continue
+		}
+		if err := retention.validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
 		}
 	}
 
-	// Validate retention policy
-	if c.Backup.Retention.KeepLast < 1 {
-		return fmt.Errorf("retention keep_last must be at least 1")
+	// Validate language
+	if c.Language != "" && !i18n.IsSupported(c.Language) {
+		return fmt.Errorf("unsupported language: %s", c.Language)
 	}
 
 	return nil
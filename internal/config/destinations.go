@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DestinationConfig is a named destination of one of the built-in types, so
+// the same type can be used more than once (e.g. a local folder and a NAS
+// mount). Exactly one of the type sections is set; it holds the same
+// settings as the built-in destination, including enabled and encryption.
+type DestinationConfig struct {
+	// Name selects the destination in --destination and policies
+	Name        string             `yaml:"name" mapstructure:"name"`
+	GoogleDrive *GoogleDriveConfig `yaml:"google_drive,omitempty" mapstructure:"google_drive"`
+	USB         *USBConfig         `yaml:"usb,omitempty" mapstructure:"usb"`
+	Local       *LocalConfig       `yaml:"local,omitempty" mapstructure:"local"`
+	GitAnnex    *GitAnnexConfig    `yaml:"git_annex,omitempty" mapstructure:"git_annex"`
+	OneDrive    *OneDriveConfig    `yaml:"onedrive,omitempty" mapstructure:"onedrive"`
+	WebDAV      *WebDAVConfig      `yaml:"webdav,omitempty" mapstructure:"webdav"`
+	GCS         *GCSConfig         `yaml:"gcs,omitempty" mapstructure:"gcs"`
+	AzureBlob   *AzureBlobConfig   `yaml:"azure_blob,omitempty" mapstructure:"azure_blob"`
+	S3          *S3Config          `yaml:"s3,omitempty" mapstructure:"s3"`
+	Rclone      *RcloneConfig      `yaml:"rclone,omitempty" mapstructure:"rclone"`
+	ICloud      *ICloudConfig      `yaml:"icloud,omitempty" mapstructure:"icloud"`
+}
+
+// Kind returns the config section of the destination's type, e.g. "local",
+// or "" unless exactly one type section is set
+func (d DestinationConfig) Kind() string {
+	kinds := d.kinds()
+	if len(kinds) != 1 {
+		return ""
+	}
+	return kinds[0]
+}
+
+// kinds lists the type sections that are set
+func (d DestinationConfig) kinds() []string {
+	var kinds []string
+	if d.GoogleDrive != nil {
+		kinds = append(kinds, "google_drive")
+	}
+	if d.USB != nil {
+		kinds = append(kinds, "usb")
+	}
+	if d.Local != nil {
+		kinds = append(kinds, "local")
+	}
+	if d.GitAnnex != nil {
+		kinds = append(kinds, "git_annex")
+	}
+	if d.OneDrive != nil {
+		kinds = append(kinds, "onedrive")
+	}
+	if d.WebDAV != nil {
+		kinds = append(kinds, "webdav")
+	}
+	if d.GCS != nil {
+		kinds = append(kinds, "gcs")
+	}
+	if d.AzureBlob != nil {
+		kinds = append(kinds, "azure_blob")
+	}
+	if d.S3 != nil {
+		kinds = append(kinds, "s3")
+	}
+	if d.Rclone != nil {
+		kinds = append(kinds, "rclone")
+	}
+	if d.ICloud != nil {
+		kinds = append(kinds, "icloud")
+	}
+	return kinds
+}
+
+// storage returns a Storage holding only this destination's type section
+func (d DestinationConfig) storage() Storage {
+	var s Storage
+	switch {
+	case d.GoogleDrive != nil:
+		s.GoogleDrive = *d.GoogleDrive
+	case d.USB != nil:
+		s.USB = *d.USB
+	case d.Local != nil:
+		s.Local = *d.Local
+	case d.GitAnnex != nil:
+		s.GitAnnex = *d.GitAnnex
+	case d.OneDrive != nil:
+		s.OneDrive = *d.OneDrive
+	case d.WebDAV != nil:
+		s.WebDAV = *d.WebDAV
+	case d.GCS != nil:
+		s.GCS = *d.GCS
+	case d.AzureBlob != nil:
+		s.AzureBlob = *d.AzureBlob
+	case d.S3 != nil:
+		s.S3 = *d.S3
+	case d.Rclone != nil:
+		s.Rclone = *d.Rclone
+	case d.ICloud != nil:
+		s.ICloud = *d.ICloud
+	}
+	return s
+}
+
+// Enabled reports whether the destination's type section is enabled
+func (d DestinationConfig) Enabled() bool {
+	s := d.storage()
+	return s.GoogleDrive.Enabled || s.USB.Enabled || s.Local.Enabled || s.GitAnnex.Enabled || s.OneDrive.Enabled ||
+		s.WebDAV.Enabled || s.GCS.Enabled || s.AzureBlob.Enabled || s.S3.Enabled || s.Rclone.Enabled || s.ICloud.Enabled
+}
+
+// Encryption returns the destination's encryption override
+func (d DestinationConfig) Encryption() DestinationEncryptionConfig {
+	switch {
+	case d.GoogleDrive != nil:
+		return d.GoogleDrive.Encryption
+	case d.USB != nil:
+		return d.USB.Encryption
+	case d.Local != nil:
+		return d.Local.Encryption
+	case d.GitAnnex != nil:
+		return d.GitAnnex.Encryption
+	case d.OneDrive != nil:
+		return d.OneDrive.Encryption
+	case d.WebDAV != nil:
+		return d.WebDAV.Encryption
+	case d.GCS != nil:
+		return d.GCS.Encryption
+	case d.AzureBlob != nil:
+		return d.AzureBlob.Encryption
+	case d.S3 != nil:
+		return d.S3.Encryption
+	case d.Rclone != nil:
+		return d.Rclone.Encryption
+	case d.ICloud != nil:
+		return d.ICloud.Encryption
+	}
+	return DestinationEncryptionConfig{}
+}
+
+// Artifact returns the destination's backup file naming and labelling
+func (d DestinationConfig) Artifact() ArtifactConfig {
+	switch {
+	case d.GoogleDrive != nil:
+		return d.GoogleDrive.Artifact
+	case d.USB != nil:
+		return d.USB.Artifact
+	case d.Local != nil:
+		return d.Local.Artifact
+	case d.GitAnnex != nil:
+		return d.GitAnnex.Artifact
+	case d.OneDrive != nil:
+		return d.OneDrive.Artifact
+	case d.WebDAV != nil:
+		return d.WebDAV.Artifact
+	case d.GCS != nil:
+		return d.GCS.Artifact
+	case d.AzureBlob != nil:
+		return d.AzureBlob.Artifact
+	case d.S3 != nil:
+		return d.S3.Artifact
+	case d.Rclone != nil:
+		return d.Rclone.Artifact
+	case d.ICloud != nil:
+		return d.ICloud.Artifact
+	}
+	return ArtifactConfig{}
+}
+
+// Retention returns the destination's retention override, or nil to use backup.retention
+func (d DestinationConfig) Retention() *RetentionConfig {
+	switch {
+	case d.GoogleDrive != nil:
+		return d.GoogleDrive.Retention
+	case d.USB != nil:
+		return d.USB.Retention
+	case d.Local != nil:
+		return d.Local.Retention
+	case d.GitAnnex != nil:
+		return d.GitAnnex.Retention
+	case d.OneDrive != nil:
+		return d.OneDrive.Retention
+	case d.WebDAV != nil:
+		return d.WebDAV.Retention
+	case d.GCS != nil:
+		return d.GCS.Retention
+	case d.AzureBlob != nil:
+		return d.AzureBlob.Retention
+	case d.S3 != nil:
+		return d.S3.Retention
+	case d.Rclone != nil:
+		return d.Rclone.Retention
+	case d.ICloud != nil:
+		return d.ICloud.Retention
+	}
+	return nil
+}
+
+// applyDefaults fills in the defaults the built-in destinations get from viper.
+// Each OneDrive profile keeps its own token.
+func (d *DestinationConfig) applyDefaults() {
+	if d.OneDrive != nil {
+		setDefault(&d.OneDrive.Tenant, "common")
+		setDefault(&d.OneDrive.Folder, "stashr")
+		setDefault(&d.OneDrive.TokenPath, fmt.Sprintf("~/.stashr/onedrive-%s-token.json", d.Name))
+	}
+	if d.WebDAV != nil {
+		setDefault(&d.WebDAV.BackupDir, "stashr")
+	}
+	if d.GCS != nil {
+		setDefault(&d.GCS.Prefix, "stashr")
+	}
+	if d.AzureBlob != nil {
+		setDefault(&d.AzureBlob.Prefix, "stashr")
+	}
+	if d.S3 != nil {
+		setDefault(&d.S3.Prefix, "stashr")
+	}
+	if d.Rclone != nil {
+		setDefault(&d.Rclone.CLIPath, "rclone")
+	}
+	if d.ICloud != nil {
+		setDefault(&d.ICloud.BackupDir, "stashr")
+	}
+}
+
+// setDefault sets an empty setting to its default
+func setDefault(setting *string, value string) {
+	if *setting == "" {
+		*setting = value
+	}
+}
+
+// expandPaths expands ~ in the destination's paths
+func (d *DestinationConfig) expandPaths(home string) {
+	if d.GoogleDrive != nil {
+		d.GoogleDrive.CredentialsPath = expandHome(d.GoogleDrive.CredentialsPath, home)
+	}
+	if d.USB != nil {
+		d.USB.MountPath = expandHome(d.USB.MountPath, home)
+	}
+	if d.Local != nil {
+		d.Local.BackupPath = expandHome(d.Local.BackupPath, home)
+	}
+	if d.GitAnnex != nil {
+		d.GitAnnex.RepoPath = expandHome(d.GitAnnex.RepoPath, home)
+	}
+	if d.OneDrive != nil {
+		d.OneDrive.TokenPath = expandHome(d.OneDrive.TokenPath, home)
+	}
+	if d.GCS != nil {
+		d.GCS.CredentialsPath = expandHome(d.GCS.CredentialsPath, home)
+	}
+	if d.Rclone != nil {
+		d.Rclone.ConfigPath = expandHome(d.Rclone.ConfigPath, home)
+	}
+	if d.ICloud != nil {
+		d.ICloud.DrivePath = expandHome(d.ICloud.DrivePath, home)
+	}
+}
+
+// destinationNamePattern restricts destination and plugin names to ones usable
+// as a --destination value and in an executable name
+var destinationNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// builtinDestinationFlags are the --destination values named destinations cannot take
+var builtinDestinationFlags = map[string]bool{
+	"gdrive": true, "usb": true, "local": true, "git-annex": true, "onedrive": true,
+	"webdav": true, "gcs": true, "azure": true, "s3": true, "rclone": true, "icloud": true, "all": true,
+}
+
+// validateDestinations checks destination profiles and storage plugins, whose
+// names share the --destination namespace
+func (c *Config) validateDestinations() error {
+	names := make(map[string]bool)
+	checkName := func(what, name string, index int) error {
+		if !destinationNamePattern.MatchString(name) {
+			return fmt.Errorf("%s %d: invalid name %q (use lowercase letters, digits and -)", what, index+1, name)
+		}
+		if builtinDestinationFlags[name] {
+			return fmt.Errorf("%s %s: name is used by a built-in destination", what, name)
+		}
+		if names[name] {
+			return fmt.Errorf("%s %s: name is already used by another destination", what, name)
+		}
+		names[name] = true
+		return nil
+	}
+
+	for i, dest := range c.Storage.Destinations {
+		if err := checkName("storage destination", dest.Name, i); err != nil {
+			return err
+		}
+		if kinds := dest.kinds(); len(kinds) != 1 {
+			found := strings.Join(kinds, ", ")
+			if found == "" {
+				found = "none"
+			}
+			return fmt.Errorf("storage destination %s must set exactly one type section such as local or rclone (found: %s)", dest.Name, found)
+		}
+		s := dest.storage()
+		if err := s.validateBackends(); err != nil {
+			return fmt.Errorf("storage destination %s: %w", dest.Name, err)
+		}
+	}
+
+	for i, plugin := range c.Storage.Plugins {
+		if err := checkName("storage plugin", plugin.Name, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unset is shown for a setting that only exists on one side of a diff
+const unset = "(unset)"
+
+// Change is a setting that differs between two configurations
+type Change struct {
+	// Key is the dotted setting path, e.g. backup.retention.keep_last
+	Key  string
+	From string
+	To   string
+	// Risk explains why the new value weakens the backups, if it does
+	Risk string
+}
+
+// Diff returns the settings that differ between two configurations, sorted by
+// key. Secrets are redacted, and changes that weaken the backups carry a Risk.
+func Diff(from, to *Config) ([]Change, error) {
+	fromValues, err := flatten(from.Redacted())
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := flatten(to.Redacted())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for key := range fromValues {
+		keys[key] = true
+	}
+	for key := range toValues {
+		keys[key] = true
+	}
+
+	risks := to.Risks()
+	var changes []Change
+	for key := range keys {
+		fromValue, ok := fromValues[key]
+		if !ok {
+			fromValue = unset
+		}
+		toValue, ok := toValues[key]
+		if !ok {
+			toValue = unset
+		}
+		if fromValue == toValue {
+			continue
+		}
+		changes = append(changes, Change{Key: key, From: fromValue, To: toValue, Risk: risks[key]})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// Risks returns the settings that weaken the backups, keyed by their dotted
+// path, with the reason each one is risky
+func (c *Config) Risks() map[string]string {
+	risks := make(map[string]string)
+	if !c.Backup.Encryption.Enabled {
+		risks["backup.encryption.enabled"] = "backups are stored unencrypted"
+	}
+	if c.Backup.Retention.KeepLast <= 1 && !c.Backup.Retention.HasAgeRules() {
+		risks["backup.retention.keep_last"] = "no older backup survives a bad export"
+	}
+	if c.Backup.Validation.TolerancePercent > 50 {
+		risks["backup.validation.tolerance_percent"] = "an export missing most items is still uploaded"
+	}
+	if !c.Logging.Redaction.Enabled {
+		risks["logging.redaction.enabled"] = "log files may contain secrets"
+	}
+
+	destinations := map[string]DestinationEncryptionConfig{
+		"google_drive": c.Storage.GoogleDrive.Encryption,
+		"usb":          c.Storage.USB.Encryption,
+		"local":        c.Storage.Local.Encryption,
+		"git_annex":    c.Storage.GitAnnex.Encryption,
+		"onedrive":     c.Storage.OneDrive.Encryption,
+		"webdav":       c.Storage.WebDAV.Encryption,
+		"gcs":          c.Storage.GCS.Encryption,
+		"azure_blob":   c.Storage.AzureBlob.Encryption,
+		"s3":           c.Storage.S3.Encryption,
+		"rclone":       c.Storage.Rclone.Encryption,
+		"icloud":       c.Storage.ICloud.Encryption,
+	}
+	for name, enc := range destinations {
+		if enc.Mode == EncryptionModeNone {
+			risks[fmt.Sprintf("storage.%s.encryption.mode", name)] = "backups on this destination are stored unencrypted"
+		}
+	}
+	for i, dest := range c.Storage.Destinations {
+		if dest.Encryption().Mode == EncryptionModeNone {
+			risks[fmt.Sprintf("storage.destinations[%d].%s.encryption.mode", i, dest.Kind())] = "backups on this destination are stored unencrypted"
+		}
+		if dest.WebDAV != nil && dest.WebDAV.Enabled && strings.HasPrefix(dest.WebDAV.URL, "http://") {
+			risks[fmt.Sprintf("storage.destinations[%d].webdav.url", i)] = "the WebDAV password is sent without TLS"
+		}
+	}
+	for i, plugin := range c.Storage.Plugins {
+		if plugin.Encryption.Mode == EncryptionModeNone {
+			risks[fmt.Sprintf("storage.plugins[%d].encryption.mode", i)] = "backups on this destination are stored unencrypted"
+		}
+	}
+
+	if c.Storage.WebDAV.Enabled && strings.HasPrefix(c.Storage.WebDAV.URL, "http://") {
+		risks["storage.webdav.url"] = "the WebDAV password is sent without TLS"
+	}
+	return risks
+}
+
+// flatten renders a configuration as dotted setting paths and their values,
+// using the same names as the config file
+func flatten(cfg Config) (map[string]string, error) {
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	values := make(map[string]string)
+	flattenValue("", tree, values)
+	return values, nil
+}
+
+// flattenValue adds a YAML value and its children to values
+func flattenValue(key string, value interface{}, values map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for child, childValue := range v {
+			if key != "" {
+				child = key + "." + child
+			}
+			flattenValue(child, childValue, values)
+		}
+	case []interface{}:
+		// Lists of settings are compared item by item; lists of values as a whole
+		scalars := make([]string, 0, len(v))
+		for i, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				flattenValue(fmt.Sprintf("%s[%d]", key, i), item, values)
+				continue
+			}
+			scalars = append(scalars, fmt.Sprint(item))
+		}
+		if len(scalars) == len(v) {
+			values[key] = "[" + strings.Join(scalars, ", ") + "]"
+		} else {
+			values[key] = fmt.Sprintf("[%d entries]", len(v))
+		}
+	case nil:
+		values[key] = `""`
+	case string:
+		values[key] = fmt.Sprintf("%q", v)
+	default:
+		values[key] = fmt.Sprint(v)
+	}
+}
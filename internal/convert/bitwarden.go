@@ -0,0 +1,160 @@
+package convert
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// bitwardenItemTypes maps normalized categories to Bitwarden item type identifiers
+var bitwardenItemTypes = map[string]int{
+	"login":       1,
+	"secure_note": 2,
+	"card":        3,
+	"identity":    4,
+}
+
+// bitwardenCardKeys and bitwardenIdentityKeys are the structured fields Bitwarden
+// stores on cards and identities; other fields become custom fields
+var (
+	bitwardenCardKeys     = []string{"cardholderName", "brand", "number", "expMonth", "expYear", "code"}
+	bitwardenIdentityKeys = []string{
+		"title", "firstName", "middleName", "lastName", "address1", "address2", "address3",
+		"city", "state", "postalCode", "country", "company", "email", "phone", "ssn",
+		"username", "passportNumber", "licenseNumber",
+	}
+)
+
+// WriteBitwardenJSON writes entries as an unencrypted Bitwarden JSON export,
+// which can be imported with Tools → Import Data → "Bitwarden (json)"
+func WriteBitwardenJSON(out io.Writer, entries []Entry) error {
+	type uri struct {
+		Match *int   `json:"match"`
+		URI   string `json:"uri"`
+	}
+	type field struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+		Type  int    `json:"type"`
+	}
+
+	folders := []map[string]string{}
+	folderIDs := make(map[string]string)
+	items := make([]map[string]interface{}, 0, len(entries))
+
+	for _, entry := range entries {
+		itemType, ok := bitwardenItemTypes[entry.Category]
+		if !ok {
+			// Unknown categories are kept as secure notes so no data is dropped
+			itemType = bitwardenItemTypes["secure_note"]
+		}
+
+		item := map[string]interface{}{
+			"id":       newUUID(),
+			"type":     itemType,
+			"name":     entry.Title,
+			"notes":    nullable(entry.Notes),
+			"favorite": false,
+		}
+
+		if entry.Folder != "" {
+			id, ok := folderIDs[entry.Folder]
+			if !ok {
+				id = newUUID()
+				folderIDs[entry.Folder] = id
+				folders = append(folders, map[string]string{"id": id, "name": entry.Folder})
+			}
+			item["folderId"] = id
+		} else {
+			item["folderId"] = nil
+		}
+
+		var structured map[string]interface{}
+		var structuredKeys []string
+		switch itemType {
+		case bitwardenItemTypes["login"]:
+			uris := make([]uri, 0, len(entry.URLs))
+			for _, u := range entry.URLs {
+				uris = append(uris, uri{URI: u})
+			}
+			item["login"] = map[string]interface{}{
+				"username": nullable(entry.Username),
+				"password": nullable(entry.Password),
+				"totp":     nullable(entry.TOTP),
+				"uris":     uris,
+			}
+		case bitwardenItemTypes["secure_note"]:
+			item["secureNote"] = map[string]int{"type": 0}
+		case bitwardenItemTypes["card"]:
+			structured = map[string]interface{}{}
+			structuredKeys = bitwardenCardKeys
+			item["card"] = structured
+		case bitwardenItemTypes["identity"]:
+			structured = map[string]interface{}{}
+			structuredKeys = bitwardenIdentityKeys
+			item["identity"] = structured
+		}
+
+		var fields []field
+		for _, f := range entry.Fields {
+			if structured != nil && contains(structuredKeys, f.Name) {
+				structured[f.Name] = f.Value
+				continue
+			}
+			fieldType := 0
+			if f.Sensitive {
+				fieldType = 1
+			}
+			fields = append(fields, field{Name: f.Name, Value: f.Value, Type: fieldType})
+		}
+
+		// Non-login entries keep any credentials as custom fields
+		if itemType != bitwardenItemTypes["login"] {
+			if entry.Username != "" {
+				fields = append(fields, field{Name: "username", Value: entry.Username})
+			}
+			if entry.Password != "" {
+				fields = append(fields, field{Name: "password", Value: entry.Password, Type: 1})
+			}
+			for _, u := range entry.URLs {
+				fields = append(fields, field{Name: "url", Value: u})
+			}
+		}
+		if len(fields) > 0 {
+			item["fields"] = fields
+		}
+
+		items = append(items, item)
+	}
+
+	export := map[string]interface{}{
+		"encrypted": false,
+		"folders":   folders,
+		"items":     items,
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return fmt.Errorf("failed to write Bitwarden export: %w", err)
+	}
+	return nil
+}
+
+// nullable returns nil for empty strings so they are written as JSON null
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
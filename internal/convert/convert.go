@@ -0,0 +1,49 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the import formats entries can be written as, other than KDBX
+// which needs a database password (see WriteKDBX)
+var Formats = []string{"bitwarden-json", "bitwarden-csv", "1password-csv", "chrome-csv", "csv"}
+
+// Write writes entries in the named import format
+func Write(out io.Writer, entries []Entry, format string) error {
+	switch format {
+	case "bitwarden-json":
+		return WriteBitwardenJSON(out, entries)
+	case "bitwarden-csv", "1password-csv", "chrome-csv", "csv":
+		return WriteCSV(out, entries, format)
+	default:
+		return fmt.Errorf("unknown format: %s (use: %s, kdbx)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Extension returns the file extension used for a format
+func Extension(format string) string {
+	switch format {
+	case "bitwarden-json":
+		return ".json"
+	case "kdbx":
+		return ".kdbx"
+	default:
+		return ".csv"
+	}
+}
+
+// Skipped returns the number of entries a format cannot represent and leaves out
+func Skipped(entries []Entry, format string) int {
+	if format != "chrome-csv" {
+		return 0
+	}
+	skipped := 0
+	for _, entry := range entries {
+		if !chromeImportable(entry) {
+			skipped++
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,117 @@
+package convert
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumns maps a normalized entry to CSV rows
+type csvColumns struct {
+	header []string
+	rows   func(Entry) [][]string
+}
+
+// singleRow adapts a one-row-per-entry mapping
+func singleRow(row func(Entry) []string) func(Entry) [][]string {
+	return func(e Entry) [][]string {
+		return [][]string{row(e)}
+	}
+}
+
+// csvFormats lists the CSV layouts understood by each target
+var csvFormats = map[string]csvColumns{
+	// csv is the normalized schema itself
+	"csv": {
+		header: []string{"title", "category", "folder", "username", "password", "url", "totp", "notes"},
+		rows: singleRow(func(e Entry) []string {
+			return []string{e.Title, e.Category, e.Folder, e.Username, e.Password, strings.Join(e.URLs, " "), e.TOTP, notesWithFields(e)}
+		}),
+	},
+	// bitwarden-csv matches Tools → Import Data → "Bitwarden (csv)"
+	"bitwarden-csv": {
+		header: []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt", "login_uri", "login_username", "login_password", "login_totp"},
+		rows: singleRow(func(e Entry) []string {
+			itemType := "login"
+			if e.Category != "login" {
+				itemType = "note"
+			}
+			var fields []string
+			for _, f := range e.Fields {
+				fields = append(fields, f.Name+": "+f.Value)
+			}
+			return []string{e.Folder, "", itemType, e.Title, e.Notes, strings.Join(fields, "\n"), "0", strings.Join(e.URLs, ","), e.Username, e.Password, e.TOTP}
+		}),
+	},
+	// 1password-csv matches the 1Password CSV import template
+	"1password-csv": {
+		header: []string{"Title", "Url", "Username", "Password", "OTPAuth", "Notes"},
+		rows: singleRow(func(e Entry) []string {
+			return []string{e.Title, firstURL(e), e.Username, e.Password, e.TOTP, notesWithFields(e)}
+		}),
+	},
+	// chrome-csv matches the Chrome/Edge password importer, which only accepts
+	// logins with a website; entries with several URLs get one row per URL
+	"chrome-csv": {
+		header: []string{"name", "url", "username", "password", "note"},
+		rows: func(e Entry) [][]string {
+			if !chromeImportable(e) {
+				return nil
+			}
+			var rows [][]string
+			for _, u := range e.URLs {
+				rows = append(rows, []string{e.Title, u, e.Username, e.Password, e.Notes})
+			}
+			return rows
+		},
+	},
+}
+
+// WriteCSV writes entries in one of the CSV layouts (csv, bitwarden-csv, 1password-csv, chrome-csv)
+func WriteCSV(out io.Writer, entries []Entry, format string) error {
+	columns, ok := csvFormats[format]
+	if !ok {
+		return fmt.Errorf("unknown CSV format: %s", format)
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write(columns.header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, entry := range entries {
+		if err := writer.WriteAll(columns.rows(entry)); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// chromeImportable reports whether Chrome can import an entry as a saved password
+func chromeImportable(e Entry) bool {
+	return e.Category == "login" && len(e.URLs) > 0 && e.Password != ""
+}
+
+// firstURL returns the primary URL of an entry
+func firstURL(e Entry) string {
+	if len(e.URLs) == 0 {
+		return ""
+	}
+	return e.URLs[0]
+}
+
+// notesWithFields appends custom fields to the notes for formats without field support
+func notesWithFields(e Entry) string {
+	if len(e.Fields) == 0 {
+		return e.Notes
+	}
+	lines := []string{}
+	if e.Notes != "" {
+		lines = append(lines, e.Notes, "")
+	}
+	for _, f := range e.Fields {
+		lines = append(lines, f.Name+": "+f.Value)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,236 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/internal/managers"
+)
+
+// Entry is a password manager item normalized across export formats
+type Entry struct {
+	Title    string
+	Category string
+	Username string
+	Password string
+	URLs     []string
+	Notes    string
+	TOTP     string
+	Folder   string
+	Fields   []Field
+}
+
+// Field is an additional named value on an entry
+type Field struct {
+	Name      string
+	Value     string
+	Sensitive bool
+}
+
+// Parse reads a decrypted Bitwarden or 1Password JSON export into normalized entries
+func Parse(data []byte) ([]Entry, error) {
+	// Bitwarden exports are an object with an items array
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err == nil {
+		if _, ok := probe["items"]; ok {
+			return parseBitwarden(data)
+		}
+		return nil, fmt.Errorf("unrecognized export format")
+	}
+
+	// 1Password exports are an array of items
+	return parseOnePassword(data)
+}
+
+// bitwardenExport mirrors the parts of a Bitwarden JSON export that are converted
+type bitwardenExport struct {
+	Folders []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []struct {
+		Type     int    `json:"type"`
+		Name     string `json:"name"`
+		Notes    string `json:"notes"`
+		FolderID string `json:"folderId"`
+		Fields   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+			Type  int    `json:"type"`
+		} `json:"fields"`
+		Login *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			TOTP     string `json:"totp"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+		Card     map[string]interface{} `json:"card"`
+		Identity map[string]interface{} `json:"identity"`
+	} `json:"items"`
+}
+
+// bitwardenHiddenField is the Bitwarden custom field type for hidden values
+const bitwardenHiddenField = 1
+
+func parseBitwarden(data []byte) ([]Entry, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden export: %w", err)
+	}
+
+	folders := make(map[string]string)
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	entries := make([]Entry, 0, len(export.Items))
+	for _, item := range export.Items {
+		entry := Entry{
+			Title:    item.Name,
+			Category: managers.BitwardenItemType(item.Type),
+			Notes:    item.Notes,
+			Folder:   folders[item.FolderID],
+		}
+
+		if item.Login != nil {
+			entry.Username = item.Login.Username
+			entry.Password = item.Login.Password
+			entry.TOTP = item.Login.TOTP
+			for _, uri := range item.Login.URIs {
+				if uri.URI != "" {
+					entry.URLs = append(entry.URLs, uri.URI)
+				}
+			}
+		}
+
+		// Card and identity details become fields; card numbers and codes are sensitive
+		entry.Fields = append(entry.Fields, objectFields(item.Card, "number", "code")...)
+		entry.Fields = append(entry.Fields, objectFields(item.Identity, "ssn", "passportNumber", "licenseNumber")...)
+
+		for _, field := range item.Fields {
+			entry.Fields = append(entry.Fields, Field{
+				Name:      field.Name,
+				Value:     field.Value,
+				Sensitive: field.Type == bitwardenHiddenField,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// objectFields flattens the non-empty string values of a JSON object into fields
+func objectFields(object map[string]interface{}, sensitive ...string) []Field {
+	var fields []Field
+	for _, key := range sortedKeys(object) {
+		value, ok := object[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		fields = append(fields, Field{
+			Name:      key,
+			Value:     value,
+			Sensitive: contains(sensitive, key),
+		})
+	}
+	return fields
+}
+
+// onePasswordItem mirrors the parts of a 1Password item that are converted
+type onePasswordItem struct {
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Vault    struct {
+		Name string `json:"name"`
+	} `json:"vault"`
+	URLs []struct {
+		Href    string `json:"href"`
+		Primary bool   `json:"primary"`
+	} `json:"urls"`
+	Fields []struct {
+		Label   string `json:"label"`
+		Type    string `json:"type"`
+		Purpose string `json:"purpose"`
+		Value   string `json:"value"`
+	} `json:"fields"`
+	AdditionalInformation string `json:"additional_information"`
+}
+
+func parseOnePassword(data []byte) ([]Entry, error) {
+	var items []onePasswordItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse 1Password export: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entry := Entry{
+			Title:    item.Title,
+			Category: managers.OnePasswordCategory(item.Category),
+			Folder:   item.Vault.Name,
+		}
+
+		// The primary URL comes first
+		for _, url := range item.URLs {
+			if url.Primary {
+				entry.URLs = append([]string{url.Href}, entry.URLs...)
+			} else {
+				entry.URLs = append(entry.URLs, url.Href)
+			}
+		}
+
+		for _, field := range item.Fields {
+			switch {
+			case field.Purpose == "USERNAME":
+				entry.Username = field.Value
+			case field.Purpose == "PASSWORD":
+				entry.Password = field.Value
+			case field.Purpose == "NOTES":
+				entry.Notes = field.Value
+			case field.Type == "OTP":
+				entry.TOTP = field.Value
+			case field.Value != "":
+				entry.Fields = append(entry.Fields, Field{
+					Name:      field.Label,
+					Value:     field.Value,
+					Sensitive: field.Type == "CONCEALED",
+				})
+			}
+		}
+
+		// Metadata-only exports carry the username as additional information
+		if entry.Username == "" && entry.Category == "login" {
+			entry.Username = item.AdditionalInformation
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// sortedKeys returns the keys of a JSON object in a stable order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// contains reports whether a string slice contains a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
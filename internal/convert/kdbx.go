@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	w "github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+// WriteKDBX writes entries to a KeePass (KDBX 4) database protected by password.
+// Folders (Bitwarden) and vaults (1Password) become groups under the root group.
+func WriteKDBX(out io.Writer, entries []Entry, password string) error {
+	if password == "" {
+		return fmt.Errorf("a KeePass database password is required")
+	}
+
+	root := gokeepasslib.NewGroup()
+	root.Name = "stashr"
+
+	groups := make(map[string]int)
+	for _, entry := range entries {
+		kdbxEntry := newKDBXEntry(entry)
+		if entry.Folder == "" {
+			root.Entries = append(root.Entries, kdbxEntry)
+			continue
+		}
+
+		idx, ok := groups[entry.Folder]
+		if !ok {
+			group := gokeepasslib.NewGroup()
+			group.Name = entry.Folder
+			root.Groups = append(root.Groups, group)
+			idx = len(root.Groups) - 1
+			groups[entry.Folder] = idx
+		}
+		root.Groups[idx].Entries = append(root.Groups[idx].Entries, kdbxEntry)
+	}
+
+	db := gokeepasslib.NewDatabase(gokeepasslib.WithDatabaseKDBXVersion4())
+	db.Credentials = gokeepasslib.NewPasswordCredentials(password)
+	db.Content.Root = &gokeepasslib.RootData{
+		Groups: []gokeepasslib.Group{root},
+	}
+
+	if err := db.LockProtectedEntries(); err != nil {
+		return fmt.Errorf("failed to protect entries: %w", err)
+	}
+	if err := gokeepasslib.NewEncoder(out).Encode(db); err != nil {
+		return fmt.Errorf("failed to write KeePass database: %w", err)
+	}
+
+	return nil
+}
+
+// newKDBXEntry maps a normalized entry to KeePass standard and custom fields
+func newKDBXEntry(entry Entry) gokeepasslib.Entry {
+	kdbxEntry := gokeepasslib.NewEntry()
+	kdbxEntry.Values = append(kdbxEntry.Values,
+		kdbxValue("Title", entry.Title, false),
+		kdbxValue("UserName", entry.Username, false),
+		kdbxValue("Password", entry.Password, true),
+		kdbxValue("Notes", entry.Notes, false),
+	)
+
+	for i, u := range entry.URLs {
+		key := "URL"
+		if i > 0 {
+			key = fmt.Sprintf("URL %d", i+1)
+		}
+		kdbxEntry.Values = append(kdbxEntry.Values, kdbxValue(key, u, false))
+	}
+
+	if entry.TOTP != "" {
+		kdbxEntry.Values = append(kdbxEntry.Values, kdbxValue("otp", otpauthURI(entry.Title, entry.TOTP), true))
+	}
+
+	used := map[string]bool{"Title": true, "UserName": true, "Password": true, "Notes": true, "URL": true, "otp": true}
+	for _, field := range entry.Fields {
+		// KeePass requires unique keys per entry
+		key := field.Name
+		for n := 2; key == "" || used[key]; n++ {
+			key = fmt.Sprintf("%s (%d)", field.Name, n)
+		}
+		used[key] = true
+		kdbxEntry.Values = append(kdbxEntry.Values, kdbxValue(key, field.Value, field.Sensitive))
+	}
+
+	return kdbxEntry
+}
+
+func kdbxValue(key, value string, protected bool) gokeepasslib.ValueData {
+	return gokeepasslib.ValueData{
+		Key:   key,
+		Value: gokeepasslib.V{Content: value, Protected: w.NewBoolWrapper(protected)},
+	}
+}
+
+// otpauthURI converts a bare TOTP secret into the otpauth URI KeePass clients expect
+func otpauthURI(title, secret string) string {
+	if strings.HasPrefix(secret, "otpauth://") {
+		return secret
+	}
+	return fmt.Sprintf("otpauth://totp/%s?secret=%s", url.PathEscape(title), url.QueryEscape(strings.ReplaceAll(secret, " ", "")))
+}
@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MigrationTargets lists the password managers a backup can be migrated into
+var MigrationTargets = []string{"keepassxc", "bitwarden", "1password"}
+
+// Loss is something a migration target can't represent as it was in the
+// backup, and how it is kept instead
+type Loss struct {
+	What    string
+	Instead string
+	Items   int
+}
+
+// Losses returns what migrating entries into a target changes, most common first
+func Losses(entries []Entry, target string) []Loss {
+	counts := make(map[Loss]int)
+	add := func(what, instead string) {
+		counts[Loss{What: what, Instead: instead}]++
+	}
+
+	for _, entry := range entries {
+		category := entry.Category
+		hasCredentials := entry.Username != "" || entry.Password != "" || len(entry.URLs) > 0
+
+		switch target {
+		case "keepassxc":
+			if category != "login" {
+				add(fmt.Sprintf("category %q", category), "imported as plain entries (KeePass entries have no type), details kept as custom fields")
+			}
+		case "bitwarden":
+			if _, ok := bitwardenItemTypes[category]; !ok {
+				add(fmt.Sprintf("category %q", category), "imported as secure notes, details kept as custom fields")
+			} else if category != "login" && hasCredentials {
+				add(fmt.Sprintf("usernames, passwords or URLs on %s items", category), "kept as custom fields")
+			}
+		case "1password":
+			if _, ok := onePasswordCategories[category]; !ok {
+				add(fmt.Sprintf("category %q", category), "imported as secure notes, details kept as custom fields")
+			} else if category != "login" && (entry.Username != "" || entry.Password != "") {
+				add(fmt.Sprintf("usernames or passwords on %s items", category), "kept as custom fields")
+			}
+			if entry.Folder != "" {
+				add("folders and vaults", "kept as tags, all items are created in one vault")
+			}
+		}
+	}
+
+	losses := make([]Loss, 0, len(counts))
+	for loss, items := range counts {
+		loss.Items = items
+		losses = append(losses, loss)
+	}
+	sort.Slice(losses, func(i, j int) bool {
+		if losses[i].Items != losses[j].Items {
+			return losses[i].Items > losses[j].Items
+		}
+		return losses[i].What < losses[j].What
+	})
+	return losses
+}
@@ -0,0 +1,199 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// onePasswordCategories maps normalized categories to the 1Password categories
+// items can be created in. Other categories are created as secure notes.
+var onePasswordCategories = map[string]string{
+	"login":                  "LOGIN",
+	"secure_note":            "SECURE_NOTE",
+	"card":                   "CREDIT_CARD",
+	"identity":               "IDENTITY",
+	"api_credential":         "API_CREDENTIAL",
+	"bank_account":           "BANK_ACCOUNT",
+	"database":               "DATABASE",
+	"driver_license":         "DRIVER_LICENSE",
+	"email_account":          "EMAIL_ACCOUNT",
+	"medical_record":         "MEDICAL_RECORD",
+	"membership":             "MEMBERSHIP",
+	"outdoor_license":        "OUTDOOR_LICENSE",
+	"passport":               "PASSPORT",
+	"reward_program":         "REWARD_PROGRAM",
+	"server":                 "SERVER",
+	"social_security_number": "SOCIAL_SECURITY_NUMBER",
+	"software_license":       "SOFTWARE_LICENSE",
+	"wireless_router":        "WIRELESS_ROUTER",
+}
+
+// onePasswordTemplate is the item JSON accepted by 'op item create'
+type onePasswordTemplate struct {
+	Title    string                     `json:"title"`
+	Category string                     `json:"category"`
+	Tags     []string                   `json:"tags,omitempty"`
+	URLs     []onePasswordURL           `json:"urls,omitempty"`
+	Sections []onePasswordSection       `json:"sections,omitempty"`
+	Fields   []onePasswordTemplateField `json:"fields"`
+}
+
+type onePasswordURL struct {
+	Label   string `json:"label,omitempty"`
+	Href    string `json:"href"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type onePasswordSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+type onePasswordTemplateField struct {
+	ID      string              `json:"id,omitempty"`
+	Type    string              `json:"type"`
+	Purpose string              `json:"purpose,omitempty"`
+	Label   string              `json:"label"`
+	Value   string              `json:"value"`
+	Section *onePasswordSection `json:"section,omitempty"`
+}
+
+// onePasswordExportItem is an item of a 1Password export as 'op item get'
+// returns it. Fields is nil in metadata-only exports.
+type onePasswordExportItem struct {
+	Title    string                      `json:"title"`
+	Category string                      `json:"category"`
+	Tags     []string                    `json:"tags"`
+	URLs     []onePasswordURL            `json:"urls"`
+	Sections []onePasswordSection        `json:"sections"`
+	Fields   *[]onePasswordTemplateField `json:"fields"`
+}
+
+// OnePasswordImportItem is an item of a 1Password export to recreate with
+// 'op item create'. Title may be changed before the template is written.
+type OnePasswordImportItem struct {
+	Title    string
+	Category string
+	// Unsupported says why the item can't be recreated, if it can't
+	Unsupported string
+	item        onePasswordTemplate
+}
+
+// Template returns the 'op item create' JSON of the item under its Title
+func (i OnePasswordImportItem) Template() ([]byte, error) {
+	item := i.item
+	item.Title = i.Title
+	template, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write 1Password item %q: %w", i.Title, err)
+	}
+	return template, nil
+}
+
+// ErrMetadataOnly is returned for 1Password exports made without --full-export,
+// which have no passwords or fields to recreate items from
+var ErrMetadataOnly = errors.New("the backup is a metadata-only 1Password export without passwords; only backups made with --full-export can be imported")
+
+// OnePasswordTemplates returns one 'op item create' template per entry. Folders
+// become tags, since items are created in a single vault.
+func OnePasswordTemplates(entries []Entry) ([][]byte, error) {
+	templates := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		category, ok := onePasswordCategories[entry.Category]
+		if !ok {
+			// Unknown categories are kept as secure notes so no data is dropped
+			category = onePasswordCategories["secure_note"]
+		}
+
+		item := onePasswordTemplate{
+			Title:    entry.Title,
+			Category: category,
+			Fields:   []onePasswordTemplateField{},
+		}
+		if entry.Folder != "" {
+			item.Tags = []string{entry.Folder}
+		}
+		for i, u := range entry.URLs {
+			item.URLs = append(item.URLs, onePasswordURL{Href: u, Primary: i == 0})
+		}
+
+		if category == "LOGIN" {
+			item.Fields = append(item.Fields,
+				onePasswordTemplateField{ID: "username", Type: "STRING", Purpose: "USERNAME", Label: "username", Value: entry.Username},
+				onePasswordTemplateField{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: entry.Password},
+			)
+		} else {
+			// Other categories keep any credentials as custom fields
+			if entry.Username != "" {
+				item.Fields = append(item.Fields, onePasswordTemplateField{Type: "STRING", Label: "username", Value: entry.Username})
+			}
+			if entry.Password != "" {
+				item.Fields = append(item.Fields, onePasswordTemplateField{Type: "CONCEALED", Label: "password", Value: entry.Password})
+			}
+		}
+		if entry.Notes != "" {
+			item.Fields = append(item.Fields, onePasswordTemplateField{ID: "notesPlain", Type: "STRING", Purpose: "NOTES", Label: "notesPlain", Value: entry.Notes})
+		}
+		if entry.TOTP != "" {
+			item.Fields = append(item.Fields, onePasswordTemplateField{Type: "OTP", Label: "one-time password", Value: otpauthURI(entry.Title, entry.TOTP)})
+		}
+		for _, f := range entry.Fields {
+			fieldType := "STRING"
+			if f.Sensitive {
+				fieldType = "CONCEALED"
+			}
+			item.Fields = append(item.Fields, onePasswordTemplateField{Type: fieldType, Label: f.Name, Value: f.Value})
+		}
+
+		template, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write 1Password item %q: %w", entry.Title, err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// OnePasswordImportItems returns the items of a full 1Password export to
+// recreate with 'op item create'. Unlike OnePasswordTemplates, nothing is
+// normalized: items keep their category, sections and fields as they were
+// exported, and only the IDs, references and timestamps 1Password assigns
+// itself are left out. Documents can't be created from a template and are
+// returned as unsupported.
+func OnePasswordImportItems(data []byte) ([]OnePasswordImportItem, error) {
+	var items []onePasswordExportItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse 1Password export: %w", err)
+	}
+
+	imports := make([]OnePasswordImportItem, 0, len(items))
+	full := false
+	for _, item := range items {
+		imported := OnePasswordImportItem{Title: item.Title, Category: item.Category}
+		if item.Fields == nil {
+			imported.Unsupported = "exported without its fields"
+			imports = append(imports, imported)
+			continue
+		}
+		full = true
+		if item.Category == "DOCUMENT" {
+			imported.Unsupported = "documents can't be recreated from a template"
+			imports = append(imports, imported)
+			continue
+		}
+
+		imported.item = onePasswordTemplate{
+			Category: item.Category,
+			Tags:     item.Tags,
+			URLs:     item.URLs,
+			Sections: item.Sections,
+			Fields:   *item.Fields,
+		}
+		imports = append(imports, imported)
+	}
+	if len(items) > 0 && !full {
+		return nil, ErrMetadataOnly
+	}
+	return imports, nil
+}
@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"golang.org/x/crypto/ssh"
+)
+
+// Backups encrypted to public keys use the age file format
+// (https://age-encryption.org/v1), so a backup can be decrypted with
+// "age -d -i key.txt" as well as with stashr. Recipients are X25519 keys
+// ("age1...") and SSH ed25519 and RSA keys.
+
+// ageIntro is the first line of every age file
+const ageIntro = "age-encryption.org/v1\n"
+
+// ErrNoAgeIdentity is returned when none of the identities can decrypt an age file
+var ErrNoAgeIdentity = errors.New("no identity matches the recipients of this backup")
+
+// AgeRecipient is a public key backups can be encrypted to
+type AgeRecipient = age.Recipient
+
+// AgeIdentity is a private key that decrypts backups encrypted to its recipient
+type AgeIdentity = age.Identity
+
+// IsAgeEncrypted reports whether data is an age file
+func IsAgeEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageIntro))
+}
+
+// ParseAgeRecipient parses an age recipient ("age1...") or an SSH public key
+// in authorized_keys format ("ssh-ed25519 AAAA..." or "ssh-rsa AAAA...")
+func ParseAgeRecipient(s string) (AgeRecipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		recipient, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q", s)
+		}
+		return recipient, nil
+	case strings.HasPrefix(s, "ssh-"):
+		recipient, err := agessh.ParseRecipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH recipient: %w", err)
+		}
+		return recipient, nil
+	default:
+		return nil, fmt.Errorf("unknown recipient %q (use an age1... key or an ssh-ed25519 or ssh-rsa public key)", s)
+	}
+}
+
+// ParseAgeIdentities parses an age identity file, with one "AGE-SECRET-KEY-1..."
+// per line, or an SSH private key. passphrase is called for an SSH key that is
+// encrypted, and may be nil.
+func ParseAgeIdentities(data []byte, passphrase func() ([]byte, error)) ([]AgeIdentity, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		key, err := ssh.ParseRawPrivateKey(data)
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) && passphrase != nil {
+			var secret []byte
+			if secret, err = passphrase(); err != nil {
+				return nil, err
+			}
+			key, err = ssh.ParseRawPrivateKeyWithPassphrase(data, secret)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH private key: %w", err)
+		}
+		identity, err := sshIdentity(key)
+		if err != nil {
+			return nil, err
+		}
+		return []AgeIdentity{identity}, nil
+	}
+
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity file: %w", err)
+	}
+	return identities, nil
+}
+
+// sshIdentity returns the age identity of an SSH private key
+func sshIdentity(key any) (AgeIdentity, error) {
+	switch privateKey := key.(type) {
+	case *ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(*privateKey)
+	case ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(privateKey)
+	case *rsa.PrivateKey:
+		return agessh.NewRSAIdentity(privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported SSH private key type %T (use ed25519 or RSA)", key)
+	}
+}
+
+// AgeRecipientTypes lists the type of each recipient an age file is encrypted
+// to, e.g. "X25519" or "ssh-ed25519", without decrypting it
+func AgeRecipientTypes(data []byte) ([]string, error) {
+	if !IsAgeEncrypted(data) {
+		return nil, fmt.Errorf("invalid age header: not an age file")
+	}
+	var types []string
+	scanner := bufio.NewScanner(bytes.NewReader(data[len(ageIntro):]))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "---") {
+			return types, nil
+		}
+		// Each recipient stanza starts "-> <type> <args...>", followed by its body
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "->" {
+			types = append(types, fields[1])
+		}
+	}
+	return nil, fmt.Errorf("invalid age header: truncated")
+}
+
+// EncryptAge encrypts data to one or more recipients in the age format
+func EncryptAge(plaintext []byte, recipients []AgeRecipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one age recipient is required")
+	}
+
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	return encrypted.Bytes(), nil
+}
+
+// DecryptAge decrypts an age file with the first identity that matches one of its recipients
+func DecryptAge(data []byte, identities []AgeIdentity) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrNoAgeIdentity
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file (corrupted data): %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// EncryptChunks encrypts data that was split into chunks with AES-256-GCM,
+// sealing each chunk on its own. Unlike Encrypt it is deterministic: under the
+// same password and salt, equal chunks encrypt to equal bytes, so backups of a
+// mostly unchanged vault share most of their ciphertext and can be
+// deduplicated. The cost is that anyone holding two backups can tell which
+// chunks they have in common.
+//
+// Decrypt returns the chunks joined back together.
+func EncryptChunks(chunks [][]byte, password *secret.Buffer, salt []byte, iterations int) ([]byte, error) {
+	if len(salt) != saltLength {
+		return nil, fmt.Errorf("salt must be %d bytes", saltLength)
+	}
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
+	}
+
+	key := deriveKey(password.Bytes(), salt, iterations)
+	defer key.Destroy()
+
+	block, err := aes.NewCipher(key.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	// Nonces are derived from the chunk, so only equal chunks share one
+	nonceKey := chunkNonceKey(key.Bytes())
+	defer secret.Wipe(nonceKey)
+
+	header := EncryptedFileHeader{
+		Version:    fileVersion,
+		Algorithm:  algorithmAES256GCMChunks,
+		Iterations: uint32(iterations),
+	}
+	copy(header.Magic[:], fileMagic)
+	copy(header.Salt[:], salt)
+
+	size := 0
+	for _, chunk := range chunks {
+		size += 4 + nonceLength + len(chunk) + gcm.Overhead()
+	}
+	result := header.marshal(size)
+	for _, chunk := range chunks {
+		mac := hmac.New(sha256.New, nonceKey)
+		mac.Write(chunk)
+		nonce := mac.Sum(nil)[:nonceLength]
+
+		result = binary.BigEndian.AppendUint32(result, uint32(len(chunk)+gcm.Overhead()))
+		result = append(result, nonce...)
+		result = gcm.Seal(result, nonce, chunk, nil)
+	}
+	return result, nil
+}
+
+// chunkNonceKey derives the key chunk nonces are computed with from the encryption key
+func chunkNonceKey(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("stashr chunk nonce"))
+	return mac.Sum(nil)
+}
+
+// openChunks decrypts the chunks written by EncryptChunks and joins them
+func openChunks(gcm cipher.AEAD, data []byte) ([]byte, error) {
+	var plaintext []byte
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < 4+nonceLength {
+			return nil, fmt.Errorf("invalid file format: truncated chunk")
+		}
+		length := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		offset += 4
+		nonce := data[offset : offset+nonceLength]
+		offset += nonceLength
+		if length > len(data)-offset {
+			return nil, fmt.Errorf("invalid file format: truncated chunk")
+		}
+
+		var err error
+		plaintext, err = gcm.Open(plaintext, nonce, data[offset:offset+length], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w (incorrect password or corrupted data)", err)
+		}
+		offset += length
+	}
+	return plaintext, nil
+}
@@ -11,38 +11,97 @@ import (
 	"os"
 
 	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
 )
 
 const (
 	// Magic bytes for encrypted files: "PWBK"
 	fileMagic = "PWBK"
-	// Version of the encryption format
-	fileVersion = uint16(1)
+	// Version of the encryption format; version 2 records the PBKDF2
+	// iterations in the header
+	fileVersion = uint16(2)
+	// Version of the envelope format password-encrypted files use: the data is
+	// encrypted with a random data key, which the password-derived key wraps
+	// in the header. Changing the password only re-wraps the data key.
+	fileVersionEnvelope = uint16(3)
+	// Version of the format with an encrypted metadata section after the
+	// header, see Metadata. Envelope and key file backups use it.
+	fileVersionMetadata = uint16(4)
+	// Version of the first encryption format, whose keys were all derived
+	// with legacyIterations
+	fileVersionFixedIterations = uint16(1)
 	// Algorithm identifier for AES-256-GCM
 	algorithmAES256GCM = uint16(1)
+	// Algorithm identifier for AES-256-GCM over separately sealed chunks, see EncryptChunks
+	algorithmAES256GCMChunks = uint16(2)
 	// Salt length in bytes
 	saltLength = 32
 	// Nonce length for GCM
 	nonceLength = 12
-	// Key derivation iterations
-	pbkdf2Iterations = 100000
+	// Key derivation iterations of version 1 files
+	legacyIterations = 100000
 	// Key length for AES-256
 	keyLength = 32
+	// Length of a wrapped data key: its nonce, the sealed key and the GCM tag
+	wrappedKeyLength = nonceLength + keyLength + 16
+)
+
+const (
+	// DefaultIterations is the PBKDF2-HMAC-SHA256 iteration count new files
+	// use, following OWASP's guidance
+	DefaultIterations = 600000
+	// MinIterations is the fewest iterations a file can be encrypted with
+	MinIterations = 100000
+	// MaxIterations bounds the iterations a header may ask for, so a damaged
+	// file can't stall decryption for hours
+	MaxIterations = 10000000
 )
 
 // EncryptedFileHeader represents the header of an encrypted file
 type EncryptedFileHeader struct {
-	Magic     [4]byte  // "PWBK"
-	Version   uint16   // File format version
-	Algorithm uint16   // Encryption algorithm identifier
-	Reserved  [8]byte  // Reserved for future use
-	Salt      [32]byte // Salt for key derivation
-	Nonce     [12]byte // Nonce for GCM
+	Magic      [4]byte  // "PWBK"
+	Version    uint16   // File format version
+	Algorithm  uint16   // Encryption algorithm identifier
+	Iterations uint32   // PBKDF2 iterations, from version 2; reserved before
+	Reserved   [4]byte  // Reserved for future use
+	Salt       [32]byte // Salt for key derivation
+	Nonce      [12]byte // Nonce for GCM
+	WrappedKey [60]byte // Data key sealed with the password key, from version 3
+	Metadata   []byte   // Sealed metadata: its nonce, the sealed JSON and the GCM tag, from version 4
 }
 
-// GenerateKey generates a new encryption key from a password
+// GenerateKey generates a new encryption key from a password with DefaultIterations
 func GenerateKey(password string, salt []byte) []byte {
-	return pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, keyLength, sha256.New)
+	key := deriveKey([]byte(password), salt, DefaultIterations)
+	defer key.Destroy()
+	return append([]byte(nil), key.Bytes()...)
+}
+
+// deriveKey derives an encryption key from a password with PBKDF2-HMAC-SHA256
+func deriveKey(password, salt []byte, iterations int) *secret.Buffer {
+	return secret.FromBytes(pbkdf2.Key(password, salt, iterations, keyLength, sha256.New))
+}
+
+// newGCM returns AES-256-GCM under a key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// checkIterations rejects iteration counts new files can't be encrypted with
+func checkIterations(iterations int) error {
+	if iterations < MinIterations || iterations > MaxIterations {
+		return fmt.Errorf("PBKDF2 iterations must be between %d and %d, got %d", MinIterations, MaxIterations, iterations)
+	}
+	return nil
 }
 
 // GenerateSalt generates a random salt
@@ -54,27 +113,45 @@ func GenerateSalt() ([]byte, error) {
 	return salt, nil
 }
 
-// Encrypt encrypts data using AES-256-GCM with the provided password
+// Encrypt encrypts data using AES-256-GCM with the provided password and
+// DefaultIterations of key derivation
 func Encrypt(plaintext []byte, password string) ([]byte, error) {
+	return EncryptWithIterations(plaintext, password, DefaultIterations)
+}
+
+// EncryptWithIterations encrypts data using AES-256-GCM with the provided
+// password, deriving the key with the given PBKDF2 iterations. The iterations
+// are recorded in the header, so Decrypt needs no settings.
+func EncryptWithIterations(plaintext []byte, password string, iterations int) ([]byte, error) {
+	passwordSecret := secret.FromString(password)
+	defer passwordSecret.Destroy()
+	return EncryptWithPassword(plaintext, passwordSecret, iterations, nil)
+}
+
+// EncryptWithPassword is EncryptWithIterations with the password held in
+// locked memory, for callers that keep it out of strings. Metadata, if not
+// nil, is encrypted into the header and can be read with ReadMetadata.
+func EncryptWithPassword(plaintext []byte, password *secret.Buffer, iterations int, metadata *Metadata) ([]byte, error) {
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
+	}
+
 	// Generate a random salt
 	salt, err := GenerateSalt()
 	if err != nil {
 		return nil, err
 	}
 
-	// Derive key from password
-	key := GenerateKey(password, salt)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	// Generate the data key, which encrypts the data
+	dataKey := secret.New(keyLength)
+	defer dataKey.Destroy()
+	if _, err := io.ReadFull(rand.Reader, dataKey.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
 	}
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newGCM(dataKey.Bytes())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+		return nil, err
 	}
 
 	// Generate nonce
@@ -86,95 +163,303 @@ func Encrypt(plaintext []byte, password string) ([]byte, error) {
 	// Encrypt data
 	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
 
-	// Build header
+	// Build header, wrapping the data key with the password
 	header := EncryptedFileHeader{
-		Version:   fileVersion,
+		Version:   fileVersionEnvelope,
 		Algorithm: algorithmAES256GCM,
 	}
+	if metadata != nil {
+		header.Version = fileVersionMetadata
+	}
 	copy(header.Magic[:], fileMagic)
-	copy(header.Salt[:], salt)
 	copy(header.Nonce[:], nonce)
+	if err := header.wrapKey(dataKey, password, salt, iterations); err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		if err := header.sealMetadata(dataKey.Bytes(), metadata); err != nil {
+			return nil, err
+		}
+	}
 
 	// Combine header and ciphertext
-	result := make([]byte, 0, len(header.Magic)+2+2+len(header.Reserved)+len(header.Salt)+len(header.Nonce)+len(ciphertext))
-	result = append(result, header.Magic[:]...)
-	result = append(result, byte(header.Version>>8), byte(header.Version))
-	result = append(result, byte(header.Algorithm>>8), byte(header.Algorithm))
-	result = append(result, header.Reserved[:]...)
-	result = append(result, header.Salt[:]...)
-	result = append(result, header.Nonce[:]...)
-	result = append(result, ciphertext...)
-
-	// Clear sensitive data
-	clearBytes(key)
-
-	return result, nil
+	return append(header.marshal(len(ciphertext)), ciphertext...), nil
 }
 
-// Decrypt decrypts data using AES-256-GCM with the provided password
-func Decrypt(ciphertext []byte, password string) ([]byte, error) {
+// wrapKey seals the data key of an envelope file with a key derived from the
+// password, recording the salt and iterations it was derived with
+func (h *EncryptedFileHeader) wrapKey(dataKey, password *secret.Buffer, salt []byte, iterations int) error {
+	key := deriveKey(password.Bytes(), salt, iterations)
+	defer key.Destroy()
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		return err
+	}
+
+	wrapNonce := make([]byte, nonceLength)
+	if _, err := io.ReadFull(rand.Reader, wrapNonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	h.Iterations = uint32(iterations)
+	copy(h.Salt[:], salt)
+	copy(h.WrappedKey[:], gcm.Seal(wrapNonce, wrapNonce, dataKey.Bytes(), h.wrapAAD()))
+	return nil
+}
+
+// unwrapKey recovers the data key of an envelope file with the password
+func (h *EncryptedFileHeader) unwrapKey(password *secret.Buffer) (*secret.Buffer, error) {
+	iterations, err := headerIterations(h.Version, h.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	key := deriveKey(password.Bytes(), h.Salt[:], iterations)
+	defer key.Destroy()
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	// Open into the locked buffer: it has room for the key, so nothing is allocated
+	dataKey := secret.New(keyLength)
+	if _, err := gcm.Open(dataKey.Bytes()[:0], h.WrappedKey[:nonceLength], h.WrappedKey[nonceLength:], h.wrapAAD()); err != nil {
+		dataKey.Destroy()
+		return nil, fmt.Errorf("failed to decrypt: %w (incorrect password or corrupted data)", err)
+	}
+	return dataKey, nil
+}
+
+// wrapAAD binds a wrapped data key to the format and algorithm of its file
+func (h *EncryptedFileHeader) wrapAAD() []byte {
+	return h.marshal(0)[:8]
+}
+
+// Rekey re-encrypts a file for a new password. Envelope files only have their
+// data key re-wrapped, so the data and metadata are left untouched however
+// large they are; files in older formats are decrypted and encrypted again as
+// envelope files.
+// Backups for deduplicated destinations can't be rekeyed: their key is shared
+// with every other backup of the destination.
+func Rekey(ciphertext []byte, password, newPassword *secret.Buffer, iterations int) ([]byte, error) {
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
+	}
+	header, encryptedData, err := readHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case header.Algorithm == algorithmAES256GCMKeyFile:
+		return nil, ErrKeyFileRequired
+	case header.Algorithm == algorithmAES256GCMChunks:
+		return nil, fmt.Errorf("deduplicated backups can't be rekeyed")
+	case !header.hasWrappedKey():
+		plaintext, err := DecryptWithPassword(ciphertext, password)
+		if err != nil {
+			return nil, err
+		}
+		defer secret.Wipe(plaintext)
+		return EncryptWithPassword(plaintext, newPassword, iterations, nil)
+	}
+
+	dataKey, err := header.unwrapKey(password)
+	if err != nil {
+		return nil, err
+	}
+	defer dataKey.Destroy()
+
+	salt, err := GenerateSalt()
+	if err != nil {
+		return nil, err
+	}
+	if err := header.wrapKey(dataKey, newPassword, salt, iterations); err != nil {
+		return nil, err
+	}
+	return append(header.marshal(len(encryptedData)), encryptedData...), nil
+}
+
+// marshal encodes the header, with room for size more bytes
+func (h EncryptedFileHeader) marshal(size int) []byte {
+	result := make([]byte, 0, len(h.Magic)+2+2+4+len(h.Reserved)+len(h.Salt)+len(h.Nonce)+len(h.WrappedKey)+4+len(h.Metadata)+size)
+	result = append(result, h.Magic[:]...)
+	result = append(result, byte(h.Version>>8), byte(h.Version))
+	result = append(result, byte(h.Algorithm>>8), byte(h.Algorithm))
+	result = binary.BigEndian.AppendUint32(result, h.Iterations)
+	result = append(result, h.Reserved[:]...)
+	result = append(result, h.Salt[:]...)
+	result = append(result, h.Nonce[:]...)
+	if h.hasWrappedKey() {
+		result = append(result, h.WrappedKey[:]...)
+	}
+	if h.Version >= fileVersionMetadata {
+		result = binary.BigEndian.AppendUint32(result, uint32(len(h.Metadata)))
+		result = append(result, h.Metadata...)
+	}
+	return result
+}
+
+// hasWrappedKey reports whether the header wraps a data key: password-encrypted
+// files from version 3
+func (h *EncryptedFileHeader) hasWrappedKey() bool {
+	return h.Algorithm == algorithmAES256GCM && h.Version >= fileVersionEnvelope
+}
+
+// headerIterations returns the key derivation iterations of a file version,
+// read from the header field from version 2
+func headerIterations(version uint16, iterations uint32) (int, error) {
+	if version == fileVersionFixedIterations {
+		return legacyIterations, nil
+	}
+	if iterations == 0 || iterations > MaxIterations {
+		return 0, fmt.Errorf("invalid file format: %d key derivation iterations", iterations)
+	}
+	return int(iterations), nil
+}
+
+// KeyIterations returns the PBKDF2 iterations the key of an encrypted file was derived with
+func KeyIterations(data []byte) (int, error) {
+	if !IsEncrypted(data) || len(data) < 12 {
+		return 0, fmt.Errorf("invalid file format: bad magic bytes")
+	}
+	version := binary.BigEndian.Uint16(data[4:6])
+	if !supportedVersion(version) {
+		return 0, fmt.Errorf("unsupported file version: %d", version)
+	}
+	return headerIterations(version, binary.BigEndian.Uint32(data[8:12]))
+}
+
+// supportedVersion reports whether a file format version can be read
+func supportedVersion(version uint16) bool {
+	return version >= fileVersionFixedIterations && version <= fileVersionMetadata
+}
+
+// IsEncrypted reports whether data starts with the stashr encrypted file header
+func IsEncrypted(data []byte) bool {
+	return len(data) >= len(fileMagic) && string(data[:len(fileMagic)]) == fileMagic
+}
+
+// readHeader parses the header of an encrypted file and returns it with the
+// encrypted data that follows
+func readHeader(ciphertext []byte) (*EncryptedFileHeader, []byte, error) {
 	// Check minimum length
 	minLength := 4 + 2 + 2 + 8 + 32 + 12 + 16 // header + minimum ciphertext with auth tag
 	if len(ciphertext) < minLength {
-		return nil, fmt.Errorf("ciphertext too short")
+		return nil, nil, fmt.Errorf("ciphertext too short")
 	}
 
 	// Parse header
+	var header EncryptedFileHeader
 	offset := 0
 
 	// Check magic
-	magic := ciphertext[offset : offset+4]
+	copy(header.Magic[:], ciphertext[offset:offset+4])
 	offset += 4
-	if string(magic) != fileMagic {
-		return nil, fmt.Errorf("invalid file format: bad magic bytes")
+	if string(header.Magic[:]) != fileMagic {
+		return nil, nil, fmt.Errorf("invalid file format: bad magic bytes")
 	}
 
 	// Read version
-	version := binary.BigEndian.Uint16(ciphertext[offset : offset+2])
+	header.Version = binary.BigEndian.Uint16(ciphertext[offset : offset+2])
 	offset += 2
-	if version != fileVersion {
-		return nil, fmt.Errorf("unsupported file version: %d", version)
+	if !supportedVersion(header.Version) {
+		return nil, nil, fmt.Errorf("unsupported file version: %d", header.Version)
 	}
 
 	// Read algorithm
-	algorithm := binary.BigEndian.Uint16(ciphertext[offset : offset+2])
+	header.Algorithm = binary.BigEndian.Uint16(ciphertext[offset : offset+2])
 	offset += 2
-	if algorithm != algorithmAES256GCM {
-		return nil, fmt.Errorf("unsupported algorithm: %d", algorithm)
+	switch header.Algorithm {
+	case algorithmAES256GCM, algorithmAES256GCMChunks, algorithmAES256GCMKeyFile:
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm: %d", header.Algorithm)
+	}
+	// Only password-encrypted files use the envelope format, and deduplicated
+	// chunks have no metadata
+	if (header.Version == fileVersionEnvelope && header.Algorithm != algorithmAES256GCM) ||
+		(header.Version == fileVersionMetadata && header.Algorithm == algorithmAES256GCMChunks) {
+		return nil, nil, fmt.Errorf("unsupported algorithm for file version %d: %d", header.Version, header.Algorithm)
 	}
 
-	// Skip reserved bytes
+	// Read the key derivation iterations, skipping reserved bytes
+	header.Iterations = binary.BigEndian.Uint32(ciphertext[offset : offset+4])
 	offset += 8
 
 	// Read salt
-	salt := ciphertext[offset : offset+saltLength]
+	copy(header.Salt[:], ciphertext[offset:offset+saltLength])
 	offset += saltLength
 
 	// Read nonce
-	nonce := ciphertext[offset : offset+nonceLength]
+	copy(header.Nonce[:], ciphertext[offset:offset+nonceLength])
 	offset += nonceLength
 
+	// Read the wrapped data key of envelope files
+	if header.hasWrappedKey() {
+		if len(ciphertext) < minLength+wrappedKeyLength {
+			return nil, nil, fmt.Errorf("ciphertext too short")
+		}
+		copy(header.WrappedKey[:], ciphertext[offset:offset+wrappedKeyLength])
+		offset += wrappedKeyLength
+	}
+
+	// Read the sealed metadata
+	if header.Version >= fileVersionMetadata {
+		if len(ciphertext)-offset < 4 {
+			return nil, nil, fmt.Errorf("ciphertext too short")
+		}
+		length := int(binary.BigEndian.Uint32(ciphertext[offset : offset+4]))
+		offset += 4
+		if length < nonceLength+16 || length > maxMetadataLength || length > len(ciphertext)-offset-16 {
+			return nil, nil, fmt.Errorf("invalid file format: %d bytes of metadata", length)
+		}
+		header.Metadata = ciphertext[offset : offset+length]
+		offset += length
+	}
+
 	// Remaining bytes are the actual ciphertext
-	encryptedData := ciphertext[offset:]
+	return &header, ciphertext[offset:], nil
+}
 
-	// Derive key from password
-	key := GenerateKey(password, salt)
-	defer clearBytes(key)
+// Decrypt decrypts data using AES-256-GCM with the provided password
+func Decrypt(ciphertext []byte, password string) ([]byte, error) {
+	passwordSecret := secret.FromString(password)
+	defer passwordSecret.Destroy()
+	return DecryptWithPassword(ciphertext, passwordSecret)
+}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
+// DecryptWithPassword is Decrypt with the password held in locked memory. The
+// plaintext is returned on the heap; wipe it with secret.Wipe once used.
+func DecryptWithPassword(ciphertext []byte, password *secret.Buffer) ([]byte, error) {
+	header, encryptedData, err := readHeader(ciphertext)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return nil, err
+	}
+	if header.Algorithm == algorithmAES256GCMKeyFile {
+		return nil, ErrKeyFileRequired
+	}
+	if header.hasWrappedKey() {
+		return decryptEnvelope(header, encryptedData, password)
 	}
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	iterations, err := headerIterations(header.Version, header.Iterations)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+		return nil, err
+	}
+
+	// Derive key from password
+	key := deriveKey(password.Bytes(), header.Salt[:], iterations)
+	defer key.Destroy()
+
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Algorithm == algorithmAES256GCMChunks {
+		return openChunks(gcm, encryptedData)
 	}
 
 	// Decrypt data
-	plaintext, err := gcm.Open(nil, nonce, encryptedData, nil)
+	plaintext, err := gcm.Open(nil, header.Nonce[:], encryptedData, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt: %w (incorrect password or corrupted data)", err)
 	}
@@ -182,6 +467,25 @@ func Decrypt(ciphertext []byte, password string) ([]byte, error) {
 	return plaintext, nil
 }
 
+// decryptEnvelope decrypts the data of an envelope file with its data key
+func decryptEnvelope(header *EncryptedFileHeader, encryptedData []byte, password *secret.Buffer) ([]byte, error) {
+	dataKey, err := header.unwrapKey(password)
+	if err != nil {
+		return nil, err
+	}
+	defer dataKey.Destroy()
+
+	gcm, err := newGCM(dataKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, header.Nonce[:], encryptedData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w (corrupted data)", err)
+	}
+	return plaintext, nil
+}
+
 // EncryptFile encrypts a file and writes it to the output path
 func EncryptFile(inputPath, outputPath, password string) error {
 	// Read input file
@@ -248,7 +552,7 @@ func GetOrCreateEncryptionKey(keyPath, password string) error {
 		}
 
 		// Clear sensitive data
-		clearBytes(key)
+		secret.Wipe(key)
 	}
 
 	return nil
@@ -270,10 +574,3 @@ func LoadEncryptionKey(keyPath, password string) ([]byte, error) {
 
 	return key, nil
 }
-
-// clearBytes securely clears a byte slice
-func clearBytes(b []byte) {
-	for i := range b {
-		b[i] = 0
-	}
-}
@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"crypto/fips140"
+	"errors"
+	"fmt"
+)
+
+// FIPSMinIterations is the fewest PBKDF2-HMAC-SHA256 iterations the FIPS
+// policy accepts
+const FIPSMinIterations = 600000
+
+// ErrNotFIPSApproved is returned for files that use an algorithm outside the
+// FIPS-approved set
+var ErrNotFIPSApproved = errors.New("not FIPS-approved")
+
+// CheckFIPS checks that an encrypted file only uses FIPS-approved algorithms:
+// AES-256-GCM, under a key file or a key derived with PBKDF2-HMAC-SHA256 and
+// at least FIPSMinIterations. Unencrypted data uses no algorithm and passes.
+func CheckFIPS(data []byte) error {
+	switch {
+	case IsAgeEncrypted(data):
+		return fmt.Errorf("age encryption (X25519, scrypt and ChaCha20-Poly1305) is %w", ErrNotFIPSApproved)
+	case IsGPGEncrypted(data):
+		return fmt.Errorf("OpenPGP messages can use any algorithm gpg supports, so they are %w", ErrNotFIPSApproved)
+	case !IsEncrypted(data):
+		return nil
+	}
+
+	header, _, err := readHeader(data)
+	if err != nil {
+		return err
+	}
+	if header.Algorithm == algorithmAES256GCMKeyFile {
+		return nil
+	}
+	iterations, err := headerIterations(header.Version, header.Iterations)
+	if err != nil {
+		return err
+	}
+	if iterations < FIPSMinIterations {
+		return fmt.Errorf("key derived with %d PBKDF2 iterations, fewer than the %d required: %w", iterations, FIPSMinIterations, ErrNotFIPSApproved)
+	}
+	return nil
+}
+
+// FIPSModule reports whether Go's FIPS 140-3 cryptographic module is enabled,
+// with GODEBUG=fips140=on
+func FIPSModule() bool {
+	return fips140.Enabled()
+}
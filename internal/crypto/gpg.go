@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Backups can also be encrypted to OpenPGP public keys by the gpg tool, so
+// that teams can share a keyring and keys held on smartcards can decrypt them:
+// gpg-agent asks for the card and its PIN, which no library could do.
+
+// DefaultGPGPath is the gpg executable used when none is configured
+const DefaultGPGPath = "gpg"
+
+// gpgArmorHeader starts an ASCII-armored OpenPGP message
+const gpgArmorHeader = "-----BEGIN PGP MESSAGE-----"
+
+// IsGPGEncrypted reports whether data is an OpenPGP message: binary, starting
+// with a public-key or symmetric-key encrypted session key packet, or armored
+func IsGPGEncrypted(data []byte) bool {
+	if bytes.HasPrefix(data, []byte(gpgArmorHeader)) {
+		return true
+	}
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		return false
+	}
+	tag := data[0] & 0x3f
+	if data[0]&0x40 == 0 {
+		// Old format packets keep the tag in bits 5-2
+		tag = (data[0] >> 2) & 0x0f
+	}
+	return tag == 1 || tag == 3
+}
+
+// GPGEncrypt encrypts data to one or more recipients in the gpg keyring: key
+// IDs, fingerprints or email addresses. Recipients are trusted as configured,
+// so keys imported from teammates need no signature first.
+func GPGEncrypt(gpgPath string, plaintext []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one GPG recipient is required")
+	}
+
+	args := []string{"--batch", "--yes", "--no-tty", "--trust-model", "always",
+		// Only keys in the keyring are used; nothing is looked up online
+		"--auto-key-locate", "local",
+		// Backups are compressed already
+		"--compress-algo", "none",
+		"--encrypt", "--output", "-"}
+	for _, recipient := range recipients {
+		args = append(args, "--recipient", recipient)
+	}
+	return runGPG(gpgPath, plaintext, args...)
+}
+
+// GPGDecrypt decrypts an OpenPGP message with a secret key in the gpg keyring.
+// gpg-agent prompts for the key's passphrase or the smartcard's PIN as needed.
+func GPGDecrypt(gpgPath string, data []byte) ([]byte, error) {
+	return runGPG(gpgPath, data, "--batch", "--decrypt", "--output", "-")
+}
+
+// GPGHasSecretKey reports whether the keyring holds the secret key, or a
+// smartcard stub for it, of any of the recipients
+func GPGHasSecretKey(gpgPath string, recipients []string) bool {
+	for _, recipient := range recipients {
+		if _, err := runGPG(gpgPath, nil, "--batch", "--list-secret-keys", recipient); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckGPGRecipients reports the recipients the keyring has no public key for
+func CheckGPGRecipients(gpgPath string, recipients []string) error {
+	var missing []string
+	for _, recipient := range recipients {
+		if _, err := runGPG(gpgPath, nil, "--batch", "--list-keys", recipient); err != nil {
+			missing = append(missing, recipient)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no public key in the gpg keyring for %s (import it with: gpg --import)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// runGPG runs gpg with data on its stdin and returns its output
+func runGPG(gpgPath string, stdin []byte, args ...string) ([]byte, error) {
+	if gpgPath == "" {
+		gpgPath = DefaultGPGPath
+	}
+	cmd := exec.Command(gpgPath, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("gpg failed: %w (output: %s)", err, message)
+		}
+		return nil, fmt.Errorf("gpg failed: %w", err)
+	}
+	return output, nil
+}
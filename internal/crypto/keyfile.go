@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// Algorithm identifier for AES-256-GCM under a key read from a key file. No
+// key is derived: the header's iterations are 0 and its salt holds the key ID,
+// so a backup tells which key file decrypts it.
+const algorithmAES256GCMKeyFile = uint16(3)
+
+// ErrKeyFileRequired is returned when a backup encrypted with a key file is
+// decrypted with a password
+var ErrKeyFileRequired = errors.New("backup was encrypted with a key file, not a password")
+
+// CreateKeyFile writes a new random 256-bit key to keyPath, readable only by
+// its owner. Use GetOrCreateEncryptionKey for a key file protected by a password.
+func CreateKeyFile(keyPath string) error {
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	defer secret.Wipe(key)
+
+	file, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return file.Close()
+}
+
+// IsKeyFileProtected reports whether a key file is encrypted with a password
+func IsKeyFileProtected(data []byte) bool {
+	return IsEncrypted(data)
+}
+
+// ReadKeyFile reads the key of a key file. password is called for a key file
+// protected with GetOrCreateEncryptionKey.
+func ReadKeyFile(keyPath string, password func() (string, error)) ([]byte, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := data
+	if IsKeyFileProtected(data) {
+		secret, err := password()
+		if err != nil {
+			return nil, err
+		}
+		if key, err = LoadEncryptionKey(keyPath, secret); err != nil {
+			return nil, err
+		}
+	}
+	if len(key) != keyLength {
+		return nil, fmt.Errorf("invalid key file %s: expected a %d-byte key, found %d bytes", keyPath, keyLength, len(key))
+	}
+	return key, nil
+}
+
+// KeyFileID identifies a key without revealing it, as a short hex string
+func KeyFileID(key []byte) string {
+	return hex.EncodeToString(keyID(key)[:8])
+}
+
+// keyID is the value recorded in the salt field of backups encrypted with a key
+func keyID(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("stashr key file id"))
+	return mac.Sum(nil)
+}
+
+// UsesKeyFile reports whether an encrypted backup was encrypted with a key file
+func UsesKeyFile(data []byte) bool {
+	header, _, err := readHeader(data)
+	return err == nil && header.Algorithm == algorithmAES256GCMKeyFile
+}
+
+// BackupKeyID returns the ID of the key file a backup was encrypted with
+func BackupKeyID(data []byte) (string, bool) {
+	header, _, err := readHeader(data)
+	if err != nil || header.Algorithm != algorithmAES256GCMKeyFile {
+		return "", false
+	}
+	return hex.EncodeToString(header.Salt[:8]), true
+}
+
+// EncryptWithKey encrypts data using AES-256-GCM with a key read from a key
+// file. Metadata, if not nil, is encrypted into the header and can be read
+// with ReadMetadataWithKey.
+func EncryptWithKey(plaintext, key []byte, metadata *Metadata) ([]byte, error) {
+	if len(key) != keyLength {
+		return nil, fmt.Errorf("key must be %d bytes", keyLength)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceLength)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	header := EncryptedFileHeader{
+		Version:   fileVersion,
+		Algorithm: algorithmAES256GCMKeyFile,
+	}
+	copy(header.Magic[:], fileMagic)
+	copy(header.Salt[:], keyID(key))
+	copy(header.Nonce[:], nonce)
+	if metadata != nil {
+		header.Version = fileVersionMetadata
+		if err := header.sealMetadata(key, metadata); err != nil {
+			return nil, err
+		}
+	}
+	return append(header.marshal(len(ciphertext)), ciphertext...), nil
+}
+
+// checkKeyID rejects a key that isn't the one a key file backup was encrypted with
+func (h *EncryptedFileHeader) checkKeyID(key []byte) error {
+	if !hmac.Equal(h.Salt[:], keyID(key)) {
+		return fmt.Errorf("backup was encrypted with key file %s, not %s", hex.EncodeToString(h.Salt[:8]), KeyFileID(key))
+	}
+	return nil
+}
+
+// DecryptWithKey decrypts a backup encrypted with EncryptWithKey
+func DecryptWithKey(ciphertext, key []byte) ([]byte, error) {
+	header, encryptedData, err := readHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm != algorithmAES256GCMKeyFile {
+		return nil, fmt.Errorf("backup was encrypted with a password, not a key file")
+	}
+	if err := header.checkKeyID(key); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, header.Nonce[:], encryptedData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w (corrupted data)", err)
+	}
+	return plaintext, nil
+}
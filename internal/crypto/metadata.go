@@ -0,0 +1,128 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// maxMetadataLength bounds the metadata section a header may declare
+const maxMetadataLength = 64 * 1024
+
+// ErrNoMetadata is returned for backups made before metadata was recorded
+var ErrNoMetadata = errors.New("backup has no metadata")
+
+// Metadata describes a backup. It is encrypted with the backup's key in the
+// header, so it can be read without decrypting the vault, but not without the
+// password or key file.
+type Metadata struct {
+	Manager   string    `json:"manager"`
+	CreatedAt time.Time `json:"created_at"`
+	// ItemCount is the number of items in the vault, 0 if unknown
+	ItemCount  int    `json:"item_count"`
+	AppVersion string `json:"app_version"`
+	// UncompressedSize is the size of the vault export in bytes
+	UncompressedSize int64 `json:"uncompressed_size"`
+	// Compression is the algorithm the vault export was compressed with:
+	// "gzip", "zstd", "xz", "dictionary" or "none"
+	Compression string `json:"compression,omitempty"`
+	// Filename is the descriptive name of a backup uploaded under an obfuscated one
+	Filename string `json:"filename,omitempty"`
+}
+
+// HasMetadata reports whether an encrypted backup records its metadata
+func HasMetadata(data []byte) bool {
+	header, _, err := readHeader(data)
+	return err == nil && header.Version >= fileVersionMetadata
+}
+
+// ReadMetadata decrypts the metadata of a password-encrypted backup. Only the
+// data key is unwrapped: the vault itself is not decrypted.
+func ReadMetadata(data []byte, password *secret.Buffer) (*Metadata, error) {
+	header, _, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm == algorithmAES256GCMKeyFile {
+		return nil, ErrKeyFileRequired
+	}
+	if header.Version < fileVersionMetadata {
+		return nil, ErrNoMetadata
+	}
+
+	dataKey, err := header.unwrapKey(password)
+	if err != nil {
+		return nil, err
+	}
+	defer dataKey.Destroy()
+	return header.openMetadata(dataKey.Bytes())
+}
+
+// ReadMetadataWithKey decrypts the metadata of a backup encrypted with a key file
+func ReadMetadataWithKey(data, key []byte) (*Metadata, error) {
+	header, _, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm != algorithmAES256GCMKeyFile {
+		return nil, fmt.Errorf("backup was encrypted with a password, not a key file")
+	}
+	if header.Version < fileVersionMetadata {
+		return nil, ErrNoMetadata
+	}
+	if err := header.checkKeyID(key); err != nil {
+		return nil, err
+	}
+	return header.openMetadata(key)
+}
+
+// sealMetadata encrypts metadata into the header with the key the data is
+// encrypted with
+func (h *EncryptedFileHeader) sealMetadata(key []byte, metadata *Metadata) error {
+	plaintext, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, nonceLength)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	h.Metadata = gcm.Seal(nonce, nonce, plaintext, h.metadataAAD())
+	if len(h.Metadata) > maxMetadataLength {
+		return fmt.Errorf("metadata too large: %d bytes", len(h.Metadata))
+	}
+	return nil
+}
+
+// openMetadata decrypts the metadata sealed in the header
+func (h *EncryptedFileHeader) openMetadata(key []byte) (*Metadata, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, h.Metadata[:nonceLength], h.Metadata[nonceLength:], h.metadataAAD())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt metadata: %w (corrupted data)", err)
+	}
+	var metadata Metadata
+	if err := json.Unmarshal(plaintext, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// metadataAAD binds the metadata to the format and algorithm of its file, and
+// keeps it from being swapped with the data of key file backups, which are
+// sealed with the same key
+func (h *EncryptedFileHeader) metadataAAD() []byte {
+	return append(h.wrapAAD(), "metadata"...)
+}
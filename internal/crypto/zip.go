@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// WinZip AES (AE-2) parameters, as documented at
+// https://www.winzip.com/en/support/aes-encryption/
+const (
+	// zipMethodAES is the compression method of AES-encrypted entries; the real
+	// method is in the AES extra field
+	zipMethodAES = 99
+	// zipAESExtraID identifies the AES extra field
+	zipAESExtraID = 0x9901
+	// zipAESVersion is AE-2, which leaves out the CRC of the plaintext
+	zipAESVersion = 2
+	// zipAESStrength 3 is AES-256, with a 16-byte salt
+	zipAESStrength = 3
+	zipAESSaltLen  = 16
+	zipAESKeyLen   = 32
+	// zipAESIterations is fixed by the format
+	zipAESIterations = 1000
+	zipAESVerifyLen  = 2
+	zipAESAuthLen    = 10
+	// zipAESReaderVersion is the ZIP version AES encryption needs, 5.1
+	zipAESReaderVersion = 51
+)
+
+// EncryptZip returns a ZIP archive holding data as a single entry named name,
+// compressed and encrypted with WinZip AES-256 under the password. 7-Zip,
+// WinZip, Keka, libarchive and most other archivers open it; the ZIP encryption
+// some built-in extractors support is the broken ZipCrypto, which isn't offered.
+func EncryptZip(name string, data []byte, password *secret.Buffer, modified time.Time) ([]byte, error) {
+	if password.Len() == 0 {
+		return nil, fmt.Errorf("a ZIP password is required")
+	}
+
+	var compressed bytes.Buffer
+	deflater, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := deflater.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+	if err := deflater.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+	defer secret.Wipe(compressed.Bytes())
+
+	salt := make([]byte, zipAESSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	keys := secret.FromBytes(pbkdf2.Key(password.Bytes(), salt, zipAESIterations, 2*zipAESKeyLen+zipAESVerifyLen, sha1.New))
+	defer keys.Destroy()
+	encryptionKey := keys.Bytes()[:zipAESKeyLen]
+	authKey := keys.Bytes()[zipAESKeyLen : 2*zipAESKeyLen]
+	verifier := keys.Bytes()[2*zipAESKeyLen:]
+
+	ciphertext, err := zipAESCTR(encryptionKey, compressed.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(ciphertext)
+
+	// The entry is the salt, the password verifier, the ciphertext and its authentication code
+	entry := make([]byte, 0, len(salt)+len(verifier)+len(ciphertext)+zipAESAuthLen)
+	entry = append(entry, salt...)
+	entry = append(entry, verifier...)
+	entry = append(entry, ciphertext...)
+	entry = append(entry, mac.Sum(nil)[:zipAESAuthLen]...)
+
+	extra := make([]byte, 11)
+	binary.LittleEndian.PutUint16(extra[0:], zipAESExtraID)
+	binary.LittleEndian.PutUint16(extra[2:], 7)
+	binary.LittleEndian.PutUint16(extra[4:], zipAESVersion)
+	copy(extra[6:], "AE")
+	extra[8] = zipAESStrength
+	binary.LittleEndian.PutUint16(extra[9:], zip.Deflate)
+
+	// Raw entries get no headers filled in by archive/zip
+	modifiedDate, modifiedTime := msDosTime(modified)
+	header := &zip.FileHeader{
+		Name:               name,
+		Method:             zipMethodAES,
+		CreatorVersion:     zipAESReaderVersion,
+		ReaderVersion:      zipAESReaderVersion,
+		Modified:           modified,
+		ModifiedDate:       modifiedDate,
+		ModifiedTime:       modifiedTime,
+		Extra:              extra,
+		CompressedSize64:   uint64(len(entry)),
+		UncompressedSize64: uint64(len(data)),
+		// Bit 0 marks the entry encrypted; AE-2 entries have no CRC
+		Flags: 0x1,
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	out, err := writer.CreateRaw(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write ZIP entry: %w", err)
+	}
+	if _, err := out.Write(entry); err != nil {
+		return nil, fmt.Errorf("failed to write ZIP entry: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write ZIP archive: %w", err)
+	}
+	return archive.Bytes(), nil
+}
+
+// msDosTime returns the MS-DOS date and time ZIP headers record, in local time
+func msDosTime(t time.Time) (uint16, uint16) {
+	t = t.Local()
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.Local)
+	}
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	clock := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, clock
+}
+
+// zipAESCTR encrypts data with AES in the CTR mode of WinZip AES, whose counter
+// is little-endian and starts at 1, unlike the big-endian counter of cipher.NewCTR
+func zipAESCTR(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	out := make([]byte, len(data))
+	counter := make([]byte, aes.BlockSize)
+	keystream := make([]byte, aes.BlockSize)
+	for offset, n := 0, uint64(1); offset < len(data); offset, n = offset+aes.BlockSize, n+1 {
+		binary.LittleEndian.PutUint64(counter, n)
+		block.Encrypt(keystream, counter)
+		for i := 0; i < aes.BlockSize && offset+i < len(data); i++ {
+			out[offset+i] = data[offset+i] ^ keystream[i]
+		}
+	}
+	return out, nil
+}
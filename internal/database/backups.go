@@ -3,21 +3,27 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // BackupRecord represents a backup in the database
 type BackupRecord struct {
-	ID           int64
-	Filename     string
-	Manager      string
-	StorageType  string
-	Size         int64
-	CreatedAt    time.Time
-	ModifiedAt   *time.Time
-	Checksum     *string
-	Notes        *string
-	Tags         []string
+	ID          int64
+	Filename    string
+	Manager     string
+	StorageType string
+	Size        int64
+	CreatedAt   time.Time
+	ModifiedAt  *time.Time
+	Checksum    *string
+	Notes       *string
+	Tags        []string
+	ItemCount   *int
+	Stats       *string
+	Labels      []string
+	// VerifiedAt is when every stored copy was proven to match Checksum
+	VerifiedAt *time.Time
 }
 
 // RecordBackup records a backup in the database
@@ -89,11 +95,13 @@ func GetBackup(filename string) (*BackupRecord, error) {
 	}
 
 	var record BackupRecord
-	var modifiedAt sql.NullTime
-	var checksum, notes sql.NullString
+	var modifiedAt, verifiedAt sql.NullTime
+	var checksum, notes, stats, labels sql.NullString
+	var itemCount sql.NullInt64
 
 	err = db.QueryRow(`
-		SELECT id, filename, manager, storage_type, size, created_at, modified_at, checksum, notes
+		SELECT id, filename, manager, storage_type, size, created_at, modified_at, checksum, notes,
+		       item_count, stats, labels, verified_at
 		FROM backups WHERE filename = ?
 	`, filename).Scan(
 		&record.ID,
@@ -105,6 +113,10 @@ func GetBackup(filename string) (*BackupRecord, error) {
 		&modifiedAt,
 		&checksum,
 		&notes,
+		&itemCount,
+		&stats,
+		&labels,
+		&verifiedAt,
 	)
 
 	if err != nil {
@@ -123,6 +135,17 @@ func GetBackup(filename string) (*BackupRecord, error) {
 	if notes.Valid {
 		record.Notes = &notes.String
 	}
+	if itemCount.Valid {
+		count := int(itemCount.Int64)
+		record.ItemCount = &count
+	}
+	if stats.Valid {
+		record.Stats = &stats.String
+	}
+	record.Labels = splitLabels(labels)
+	if verifiedAt.Valid {
+		record.VerifiedAt = &verifiedAt.Time
+	}
 
 	// Get tags
 	record.Tags, err = GetTags(filename)
@@ -142,7 +165,7 @@ func ListBackups(manager, storageType string, tags []string) ([]BackupRecord, er
 
 	query := `
 		SELECT DISTINCT b.id, b.filename, b.manager, b.storage_type, b.size,
-		       b.created_at, b.modified_at, b.checksum, b.notes
+		       b.created_at, b.modified_at, b.checksum, b.notes, b.item_count, b.stats, b.labels, b.verified_at
 		FROM backups b
 	`
 
@@ -196,8 +219,9 @@ func ListBackups(manager, storageType string, tags []string) ([]BackupRecord, er
 	var records []BackupRecord
 	for rows.Next() {
 		var record BackupRecord
-		var modifiedAt sql.NullTime
-		var checksum, notes sql.NullString
+		var modifiedAt, verifiedAt sql.NullTime
+		var checksum, notes, stats, labels sql.NullString
+		var itemCount sql.NullInt64
 
 		err := rows.Scan(
 			&record.ID,
@@ -209,6 +233,10 @@ func ListBackups(manager, storageType string, tags []string) ([]BackupRecord, er
 			&modifiedAt,
 			&checksum,
 			&notes,
+			&itemCount,
+			&stats,
+			&labels,
+			&verifiedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan backup: %w", err)
@@ -223,6 +251,17 @@ func ListBackups(manager, storageType string, tags []string) ([]BackupRecord, er
 		if notes.Valid {
 			record.Notes = &notes.String
 		}
+		if itemCount.Valid {
+			count := int(itemCount.Int64)
+			record.ItemCount = &count
+		}
+		if stats.Valid {
+			record.Stats = &stats.String
+		}
+		record.Labels = splitLabels(labels)
+		if verifiedAt.Valid {
+			record.VerifiedAt = &verifiedAt.Time
+		}
 
 		// Get tags for this backup
 		record.Tags, _ = GetTags(record.Filename)
@@ -244,6 +283,9 @@ func DeleteBackup(filename string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete backup: %w", err)
 	}
+	if _, err := db.Exec("DELETE FROM backup_copies WHERE filename = ?", filename); err != nil {
+		return fmt.Errorf("failed to delete backup copies: %w", err)
+	}
 
 	return nil
 }
@@ -266,3 +308,113 @@ func UpdateBackupNotes(filename, notes string) error {
 
 	return nil
 }
+
+// UpdateBackupStats records the vault item count and category statistics for a backup
+func UpdateBackupStats(filename string, itemCount int, stats string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE backups SET item_count = ?, stats = ?
+		WHERE filename = ?
+	`, itemCount, sql.NullString{String: stats, Valid: stats != ""}, filename)
+
+	if err != nil {
+		return fmt.Errorf("failed to update stats: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBackupChecksum records the SHA-256 checksum of the stored backup file
+func UpdateBackupChecksum(filename, checksum string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE backups SET checksum = ?
+		WHERE filename = ?
+	`, checksum, filename)
+
+	if err != nil {
+		return fmt.Errorf("failed to update checksum: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBackupVerified records when a backup's stored copies were verified
+func UpdateBackupVerified(filename string, verifiedAt time.Time) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE backups SET verified_at = ?
+		WHERE filename = ?
+	`, verifiedAt, filename)
+
+	if err != nil {
+		return fmt.Errorf("failed to update verification time: %w", err)
+	}
+
+	return nil
+}
+
+// FindBackupByChecksum retrieves a backup record by its recorded checksum.
+// Checksums are stored as lowercase hex, so the argument is lowercased here and
+// the lookup can use the checksum index.
+func FindBackupByChecksum(checksum string) (*BackupRecord, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var filename string
+	err = db.QueryRow(`
+		SELECT filename FROM backups WHERE checksum = ?
+		ORDER BY created_at DESC LIMIT 1
+	`, strings.ToLower(checksum)).Scan(&filename)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find backup by checksum: %w", err)
+	}
+
+	return GetBackup(filename)
+}
+
+// UpdateBackupLabels records the classification labels of a backup
+func UpdateBackupLabels(filename string, labels []string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	value := strings.Join(labels, ",")
+	_, err = db.Exec(`
+		UPDATE backups SET labels = ?
+		WHERE filename = ?
+	`, sql.NullString{String: value, Valid: value != ""}, filename)
+
+	if err != nil {
+		return fmt.Errorf("failed to update labels: %w", err)
+	}
+
+	return nil
+}
+
+// splitLabels parses the comma-separated labels column
+func splitLabels(value sql.NullString) []string {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	return strings.Split(value.String, ",")
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackupCopy is the file a destination stores for a backup. Destinations with
+// their own encryption settings store a file of the same name with different
+// content, so checksums are recorded per destination.
+type BackupCopy struct {
+	Filename    string
+	StorageType string
+	Checksum    string
+	Size        int64
+	CreatedAt   time.Time
+}
+
+// RecordBackupCopy records the SHA-256 checksum of the file a destination stores
+func RecordBackupCopy(filename, storageType, checksum string, size int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO backup_copies (filename, storage_type, checksum, size, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(filename, storage_type) DO UPDATE SET
+			checksum = excluded.checksum,
+			size = excluded.size,
+			created_at = excluded.created_at
+	`, filename, storageType, checksum, size, time.Now())
+
+	if err != nil {
+		return fmt.Errorf("failed to record backup copy: %w", err)
+	}
+
+	return nil
+}
+
+// ListBackupCopies lists the recorded copies of a backup
+func ListBackupCopies(filename string) ([]BackupCopy, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT filename, storage_type, checksum, size, created_at
+		FROM backup_copies WHERE filename = ?
+		ORDER BY storage_type
+	`, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup copies: %w", err)
+	}
+	defer rows.Close()
+
+	var copies []BackupCopy
+	for rows.Next() {
+		var c BackupCopy
+		if err := rows.Scan(&c.Filename, &c.StorageType, &c.Checksum, &c.Size, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan backup copy: %w", err)
+		}
+		copies = append(copies, c)
+	}
+
+	return copies, rows.Err()
+}
+
+// FindBackupCopyByChecksum retrieves the newest copy with the given SHA-256
+// checksum, nil if none has it. Checksums are stored as lowercase hex.
+func FindBackupCopyByChecksum(checksum string) (*BackupCopy, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	var c BackupCopy
+	err = db.QueryRow(`
+		SELECT filename, storage_type, checksum, size, created_at
+		FROM backup_copies WHERE checksum = ?
+		ORDER BY created_at DESC LIMIT 1
+	`, strings.ToLower(checksum)).Scan(&c.Filename, &c.StorageType, &c.Checksum, &c.Size, &c.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find backup copy: %w", err)
+	}
+
+	return &c, nil
+}
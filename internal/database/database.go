@@ -32,7 +32,8 @@ func GetDB() (*sql.DB, error) {
 		}
 
 		// Open database
-		db, err = sql.Open("sqlite3", dbPath)
+		// Concurrent backups write at the same time, so wait for locks instead of failing
+		db, err = sql.Open("sqlite3", dbPath+"?_busy_timeout=5000")
 		if err != nil {
 			dbErr = fmt.Errorf("failed to open database: %w", err)
 			return
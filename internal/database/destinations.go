@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Destination operations whose outcome feeds destination health
+const (
+	// OperationUpload is an upload of a backup to a destination
+	OperationUpload = "upload"
+	// OperationDownload is a download of a backup from a destination
+	OperationDownload = "download"
+)
+
+// DestinationStats summarizes the recent operations of one destination
+type DestinationStats struct {
+	StorageType string
+	Attempts    int
+	Successes   int
+	// AvgDuration is the mean duration of the successful operations
+	AvgDuration time.Duration
+}
+
+// SuccessRate returns the share of operations that succeeded, from 0 to 1
+func (s DestinationStats) SuccessRate() float64 {
+	if s.Attempts == 0 {
+		return 1
+	}
+	return float64(s.Successes) / float64(s.Attempts)
+}
+
+// RecordDestinationOp records the outcome and duration of an upload or download
+func RecordDestinationOp(storageType, operation string, success bool, duration time.Duration) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO destination_ops (storage_type, operation, success, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, storageType, operation, success, duration.Milliseconds(), time.Now())
+
+	if err != nil {
+		return fmt.Errorf("failed to record destination operation: %w", err)
+	}
+
+	return nil
+}
+
+// ListDestinationStats summarizes the last `recent` operations of each destination
+// recorded since the given time, keyed by storage type
+func ListDestinationStats(since time.Time, recent int) (map[string]DestinationStats, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT storage_type, success, duration_ms
+		FROM destination_ops WHERE created_at >= ?
+		ORDER BY created_at DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination operations: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]DestinationStats)
+	totals := make(map[string]time.Duration)
+	for rows.Next() {
+		var storageType string
+		var success bool
+		var durationMs int64
+		if err := rows.Scan(&storageType, &success, &durationMs); err != nil {
+			return nil, fmt.Errorf("failed to scan destination operation: %w", err)
+		}
+
+		s := stats[storageType]
+		if s.Attempts >= recent {
+			continue
+		}
+		s.StorageType = storageType
+		s.Attempts++
+		if success {
+			s.Successes++
+			totals[storageType] += time.Duration(durationMs) * time.Millisecond
+		}
+		stats[storageType] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for storageType, s := range stats {
+		if s.Successes > 0 {
+			s.AvgDuration = totals[storageType] / time.Duration(s.Successes)
+			stats[storageType] = s
+		}
+	}
+
+	return stats, nil
+}
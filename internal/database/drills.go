@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DrillRecord is the result of a restore drill of one stored backup: a full
+// download, decryption and decompression in memory
+type DrillRecord struct {
+	ID          int64
+	Manager     string
+	StorageType string
+	Filename    string
+	Success     bool
+	// FailedCheck is the step that failed, e.g. "decrypt"
+	FailedCheck string
+	Message     string
+	// ItemCount is the number of items restored, if the vault data was read
+	ItemCount *int
+	Duration  time.Duration
+	CreatedAt time.Time
+}
+
+// RecordDrill records the result of a restore drill
+func RecordDrill(drill DrillRecord) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	createdAt := drill.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	var itemCount sql.NullInt64
+	if drill.ItemCount != nil {
+		itemCount = sql.NullInt64{Int64: int64(*drill.ItemCount), Valid: true}
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO restore_drills (manager, storage_type, filename, success, failed_check, message, item_count, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, drill.Manager, drill.StorageType, drill.Filename, drill.Success,
+		sql.NullString{String: drill.FailedCheck, Valid: drill.FailedCheck != ""},
+		sql.NullString{String: drill.Message, Valid: drill.Message != ""},
+		itemCount, drill.Duration.Milliseconds(), createdAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record restore drill: %w", err)
+	}
+
+	return nil
+}
+
+// ListDrills lists restore drills run since the given time, oldest first
+func ListDrills(since time.Time) ([]DrillRecord, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, manager, storage_type, filename, success, failed_check, message, item_count, duration_ms, created_at
+		FROM restore_drills
+		WHERE created_at >= ?
+		ORDER BY created_at ASC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restore drills: %w", err)
+	}
+	defer rows.Close()
+
+	var drills []DrillRecord
+	for rows.Next() {
+		var drill DrillRecord
+		var failedCheck, message sql.NullString
+		var itemCount sql.NullInt64
+		var durationMS int64
+
+		if err := rows.Scan(
+			&drill.ID,
+			&drill.Manager,
+			&drill.StorageType,
+			&drill.Filename,
+			&drill.Success,
+			&failedCheck,
+			&message,
+			&itemCount,
+			&durationMS,
+			&drill.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan restore drill: %w", err)
+		}
+
+		drill.FailedCheck = failedCheck.String
+		drill.Message = message.String
+		if itemCount.Valid {
+			count := int(itemCount.Int64)
+			drill.ItemCount = &count
+		}
+		drill.Duration = time.Duration(durationMS) * time.Millisecond
+		drills = append(drills, drill)
+	}
+
+	return drills, nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Event kinds recorded in the event log
+const (
+	// EventBackup is a backup run of one password manager
+	EventBackup = "backup"
+	// EventUpload is an upload of a backup to one destination
+	EventUpload = "upload"
+	// EventVerification is a test decryption of a stored backup
+	EventVerification = "verification"
+	// EventPostponed is an unattended backup postponed until its conditions are met
+	EventPostponed = "postponed"
+)
+
+// EventRecord represents an entry in the event log
+type EventRecord struct {
+	ID          int64
+	Kind        string
+	Manager     string
+	StorageType string
+	Filename    string
+	Success     bool
+	Message     string
+	CreatedAt   time.Time
+}
+
+// RecordEvent appends an entry to the event log
+func RecordEvent(event EventRecord) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	createdAt := event.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO events (kind, manager, storage_type, filename, success, message, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, event.Kind, event.Manager, event.StorageType, event.Filename, event.Success,
+		sql.NullString{String: event.Message, Valid: event.Message != ""}, createdAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+
+	return nil
+}
+
+// ListEvents lists events recorded since the given time, oldest first
+func ListEvents(since time.Time) ([]EventRecord, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, kind, manager, storage_type, filename, success, message, created_at
+		FROM events WHERE created_at >= ?
+		ORDER BY created_at ASC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []EventRecord
+	for rows.Next() {
+		var event EventRecord
+		var manager, storageType, filename, message sql.NullString
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.Kind,
+			&manager,
+			&storageType,
+			&filename,
+			&event.Success,
+			&message,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		event.Manager = manager.String
+		event.StorageType = storageType.String
+		event.Filename = filename.String
+		event.Message = message.String
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetState returns a persisted value, or an empty string if it was never set
+func GetState(key string) (string, error) {
+	db, err := GetDB()
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	err = db.QueryRow("SELECT value FROM state WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get state: %w", err)
+	}
+
+	return value, nil
+}
+
+// SetState persists a value that must survive restarts (e.g. when a job last ran)
+func SetState(key, value string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO state (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value, time.Now())
+
+	if err != nil {
+		return fmt.Errorf("failed to set state: %w", err)
+	}
+
+	return nil
+}
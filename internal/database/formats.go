@@ -0,0 +1,45 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// filenameFormatPrefix prefixes the state keys that record filename formats
+const filenameFormatPrefix = "filename_format."
+
+// RecordFilenameFormat remembers a filename_format template backups were named
+// with, so their names can still be parsed after the setting changes
+func RecordFilenameFormat(format string) error {
+	return SetState(filenameFormatPrefix+format, time.Now().Format(time.RFC3339))
+}
+
+// ListFilenameFormats returns every filename_format template backups were named with
+func ListFilenameFormats() ([]string, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT key FROM state
+		WHERE substr(key, 1, ?) = ?
+		ORDER BY updated_at DESC
+	`, len(filenameFormatPrefix), filenameFormatPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filename formats: %w", err)
+	}
+	defer rows.Close()
+
+	var formats []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan filename format: %w", err)
+		}
+		formats = append(formats, strings.TrimPrefix(key, filenameFormatPrefix))
+	}
+
+	return formats, rows.Err()
+}
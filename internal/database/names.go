@@ -0,0 +1,60 @@
+package database
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/internal/crypto"
+)
+
+// obfuscatedNamePrefix prefixes the state keys that record obfuscated filenames
+const obfuscatedNamePrefix = "obfuscated_name."
+
+// RecordObfuscatedName remembers the name a backup uploaded under an opaque
+// name stands for, so it can still be listed by manager and date. The name is
+// encrypted with nameKey, so the database alone doesn't reveal it.
+func RecordObfuscatedName(name, original string, nameKey []byte) error {
+	sealed, err := crypto.EncryptWithKey([]byte(original), nameKey, nil)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt obfuscated name: %w", err)
+	}
+	return SetState(obfuscatedNamePrefix+name, base64.StdEncoding.EncodeToString(sealed))
+}
+
+// ListObfuscatedNames maps every obfuscated filename to the name it stands for.
+// Names that don't decrypt with nameKey are left out.
+func ListObfuscatedNames(nameKey []byte) (map[string]string, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT key, value FROM state
+		WHERE substr(key, 1, ?) = ?
+	`, len(obfuscatedNamePrefix), obfuscatedNamePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list obfuscated names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan obfuscated name: %w", err)
+		}
+		sealed, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		original, err := crypto.DecryptWithKey(sealed, nameKey)
+		if err != nil {
+			continue
+		}
+		names[strings.TrimPrefix(key, obfuscatedNamePrefix)] = string(original)
+	}
+
+	return names, rows.Err()
+}
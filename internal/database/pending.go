@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PendingUpload is an upload of a backup that failed and is retried later,
+// from a destination that has the same file or from a local spooled copy
+type PendingUpload struct {
+	ID          int64
+	Filename    string
+	Manager     string
+	Destination string
+	// Sources are the destinations the same file was uploaded to
+	Sources []string
+	// SpoolPath is a local copy of the encrypted file, kept when no destination has it
+	SpoolPath string
+	// Checksum is the SHA-256 of the file, checked before it is uploaded
+	Checksum  string
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AddPendingUpload queues an upload for retry, replacing a queued upload of the
+// same file to the same destination
+func AddPendingUpload(upload PendingUpload) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO pending_uploads (filename, manager, destination, sources, spool_path, checksum, attempts, last_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+		ON CONFLICT(filename, destination) DO UPDATE SET
+			sources = excluded.sources, spool_path = excluded.spool_path, checksum = excluded.checksum,
+			last_error = excluded.last_error, updated_at = excluded.updated_at
+	`, upload.Filename, upload.Manager, upload.Destination, strings.Join(upload.Sources, ","),
+		sql.NullString{String: upload.SpoolPath, Valid: upload.SpoolPath != ""}, upload.Checksum,
+		sql.NullString{String: upload.LastError, Valid: upload.LastError != ""}, now, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to queue upload: %w", err)
+	}
+
+	return nil
+}
+
+// ListPendingUploads lists the queued uploads, oldest first
+func ListPendingUploads() ([]PendingUpload, error) {
+	db, err := GetDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, filename, manager, destination, sources, spool_path, checksum, attempts, last_error, created_at, updated_at
+		FROM pending_uploads
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var uploads []PendingUpload
+	for rows.Next() {
+		var upload PendingUpload
+		var sources, spoolPath, lastError sql.NullString
+
+		if err := rows.Scan(
+			&upload.ID,
+			&upload.Filename,
+			&upload.Manager,
+			&upload.Destination,
+			&sources,
+			&spoolPath,
+			&upload.Checksum,
+			&upload.Attempts,
+			&lastError,
+			&upload.CreatedAt,
+			&upload.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pending upload: %w", err)
+		}
+
+		if sources.String != "" {
+			upload.Sources = strings.Split(sources.String, ",")
+		}
+		upload.SpoolPath = spoolPath.String
+		upload.LastError = lastError.String
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
+
+// RecordPendingUploadAttempt records a failed retry of a queued upload
+func RecordPendingUploadAttempt(id int64, message string) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE pending_uploads SET attempts = attempts + 1, last_error = ?, updated_at = ? WHERE id = ?
+	`, message, time.Now(), id)
+
+	if err != nil {
+		return fmt.Errorf("failed to record upload attempt: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePendingUpload removes an upload from the queue
+func DeletePendingUpload(id int64) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("DELETE FROM pending_uploads WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete pending upload: %w", err)
+	}
+
+	return nil
+}
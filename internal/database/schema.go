@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 const schema = `
@@ -35,13 +36,102 @@ CREATE TABLE IF NOT EXISTS tags (
 
 CREATE INDEX IF NOT EXISTS idx_tags_backup ON tags(backup_filename);
 CREATE INDEX IF NOT EXISTS idx_tags_tag ON tags(tag);
+
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    manager TEXT,
+    storage_type TEXT,
+    filename TEXT,
+    success BOOLEAN NOT NULL,
+    message TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_created ON events(created_at);
+
+CREATE TABLE IF NOT EXISTS state (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS destination_ops (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    storage_type TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_destination_ops_created ON destination_ops(created_at);
+
+CREATE TABLE IF NOT EXISTS pending_uploads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL,
+    manager TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    sources TEXT,
+    spool_path TEXT,
+    checksum TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE(filename, destination)
+);
+
+CREATE TABLE IF NOT EXISTS restore_drills (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    manager TEXT NOT NULL,
+    storage_type TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    failed_check TEXT,
+    message TEXT,
+    item_count INTEGER,
+    duration_ms INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_restore_drills_created ON restore_drills(created_at);
+
+CREATE TABLE IF NOT EXISTS backup_copies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL,
+    storage_type TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE(filename, storage_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_copies_checksum ON backup_copies(checksum);
 `
 
+// migrations add columns introduced after the initial schema.
+// Each statement is applied once; "duplicate column" errors are ignored.
+var migrations = []string{
+	`ALTER TABLE backups ADD COLUMN item_count INTEGER`,
+	`ALTER TABLE backups ADD COLUMN stats TEXT`,
+	`CREATE INDEX IF NOT EXISTS idx_backups_checksum ON backups(checksum)`,
+	`ALTER TABLE backups ADD COLUMN labels TEXT`,
+	`ALTER TABLE backups ADD COLUMN verified_at DATETIME`,
+}
+
 // initSchema initializes the database schema
 func initSchema(db *sql.DB) error {
 	_, err := db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
+
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("failed to apply migration %q: %w", migration, err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,223 @@
+// Package dictionary compresses backups with a zstd dictionary trained on past
+// exports of the same password manager. Vault exports repeat the same keys and
+// item layouts, so a dictionary of those fragments makes small exports
+// noticeably smaller than plain zstd or gzip.
+//
+// Compressed data is a zstd frame whose header records the dictionary ID, so
+// restores know which dictionary to load. Dictionaries are standard zstd
+// dictionaries: "zstd -d -D <id>.dict" decompresses a backup too.
+package dictionary
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxSize is the largest dictionary Train builds. Exports are a few hundred KiB
+// at most, so larger dictionaries barely help.
+const MaxSize = 64 * 1024
+
+// DestinationDir is the hidden folder destinations keep a copy of each
+// dictionary in, next to the backups compressed with it
+const DestinationDir = ".stashr-dictionaries"
+
+// ErrNotFound is returned when a backup's dictionary isn't in the dictionary directory
+var ErrNotFound = errors.New("compression dictionary not found")
+
+// zstdMagic starts every zstd frame
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// ID returns the ID a zstd dictionary records, which compressed data refers to it by
+func ID(dict []byte) (uint32, error) {
+	info, err := zstd.InspectDictionary(dict)
+	if err != nil {
+		return 0, fmt.Errorf("invalid compression dictionary: %w", err)
+	}
+	return info.ID(), nil
+}
+
+// contentID derives a dictionary ID from the dictionary content, in the range
+// the zstd format leaves for private dictionaries (32768 to 2^31-1)
+func contentID(content []byte) uint32 {
+	sum := sha256.Sum256(content)
+	return 32768 + binary.BigEndian.Uint32(sum[:4])%(1<<31-32768)
+}
+
+// DestinationPath returns the path of a dictionary's copy on a destination
+func DestinationPath(id uint32) string {
+	return path.Join(DestinationDir, FormatID(id)+".dict")
+}
+
+// FormatID formats a dictionary ID as it appears in file names and messages
+func FormatID(id uint32) string {
+	return fmt.Sprintf("%08x", id)
+}
+
+// Compress compresses data with a dictionary
+func Compress(data, dict []byte) ([]byte, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderDict(dict), zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil), nil
+}
+
+// HeaderID returns the dictionary ID of data compressed with Compress, and
+// false if data isn't a zstd frame that needs a dictionary
+func HeaderID(data []byte) (uint32, bool) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return 0, false
+	}
+	var header zstd.Header
+	if err := header.Decode(data); err != nil || header.DictionaryID == 0 {
+		return 0, false
+	}
+	return header.DictionaryID, true
+}
+
+// Decompress decompresses data compressed with Compress and dict
+func Decompress(data, dict []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dict))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	defer decoder.Close()
+
+	decompressed, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return decompressed, nil
+}
+
+// Store is a directory of dictionaries and the anonymized export samples they
+// are trained on. Dictionaries are never deleted, since restoring a backup needs
+// the dictionary it was compressed with.
+type Store struct {
+	Dir string
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// dictionaryPath returns the file holding a dictionary
+func (s *Store) dictionaryPath(id uint32) string {
+	return filepath.Join(s.Dir, FormatID(id)+".dict")
+}
+
+// samplesDir returns the directory holding a manager's samples
+func (s *Store) samplesDir(manager string) string {
+	return filepath.Join(s.Dir, "samples", filepath.Base(manager))
+}
+
+// Save stores a dictionary and returns its ID
+func (s *Store) Save(dict []byte) (uint32, error) {
+	id, err := ID(dict)
+	if err != nil {
+		return 0, err
+	}
+	path := s.dictionaryPath(id)
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to create dictionary directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, dict, 0600); err != nil {
+		return 0, fmt.Errorf("failed to save dictionary: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to save dictionary: %w", err)
+	}
+	return id, nil
+}
+
+// Load returns the dictionary with the given ID
+func (s *Store) Load(id uint32) ([]byte, error) {
+	dict, err := os.ReadFile(s.dictionaryPath(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s is not in %s (copy it from the machine that made the backup, or from the %s folder of a destination)", ErrNotFound, FormatID(id)+".dict", s.Dir, DestinationDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	return dict, nil
+}
+
+// AddSample stores an anonymized export of a manager, keeping its newest keep samples
+func (s *Store) AddSample(manager string, sample []byte, keep int) error {
+	dir := s.samplesDir(manager)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create sample directory: %w", err)
+	}
+	name := fmt.Sprintf("%d.json", time.Now().UnixNano())
+	if err := os.WriteFile(filepath.Join(dir, name), sample, 0600); err != nil {
+		return fmt.Errorf("failed to save sample: %w", err)
+	}
+
+	names, err := s.sampleNames(manager)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old sample: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Samples returns a manager's stored samples, oldest first
+func (s *Store) Samples(manager string) ([][]byte, error) {
+	names, err := s.sampleNames(manager)
+	if err != nil {
+		return nil, err
+	}
+	var samples [][]byte
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(s.samplesDir(manager), name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sample: %w", err)
+		}
+		samples = append(samples, data)
+	}
+	return samples, nil
+}
+
+// sampleNames lists a manager's sample files, oldest first
+func (s *Store) sampleNames(manager string) ([]string, error) {
+	entries, err := os.ReadDir(s.samplesDir(manager))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	// Names are nanosecond timestamps of the same length for centuries
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,123 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// minSegment is the shortest fragment worth putting in a dictionary; zstd
+// can't reference fewer than 3 bytes and short references barely save anything
+const minSegment = 4
+
+// minContent is the least content a zstd dictionary can hold
+const minContent = 8
+
+// Train builds a zstd dictionary with at most size bytes of content from
+// samples. Samples are cut into JSON fragments ending at a newline or comma,
+// such as `"type": 1,`, and the fragments that recur most are kept, weighted by
+// the bytes they would save. The most valuable fragments go last, where zstd
+// references them most cheaply. The entropy tables are then tuned on the
+// samples. It returns nil if no fragment recurs.
+func Train(samples [][]byte, size int) ([]byte, error) {
+	if size <= 0 || size > MaxSize {
+		size = MaxSize
+	}
+
+	// A fragment must recur within a sample, or appear in two samples when there are several
+	minSamples := 1
+	if len(samples) > 1 {
+		minSamples = 2
+	}
+
+	type stat struct {
+		count   int
+		samples int
+		last    int
+	}
+	stats := make(map[string]*stat)
+	for i, sample := range samples {
+		for _, segment := range segments(sample) {
+			if len(segment) < minSegment || len(segment) > size {
+				continue
+			}
+			s, ok := stats[string(segment)]
+			if !ok {
+				s = &stat{last: -1}
+				stats[string(segment)] = s
+			}
+			s.count++
+			if s.last != i {
+				s.samples++
+				s.last = i
+			}
+		}
+	}
+
+	type scored struct {
+		segment string
+		score   int
+	}
+	var candidates []scored
+	for segment, s := range stats {
+		if s.count < 2 || s.samples < minSamples {
+			continue
+		}
+		candidates = append(candidates, scored{segment, s.count * len(segment)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].segment < candidates[j].segment
+	})
+
+	// Take the best fragments that fit, then lay them out from least to most valuable
+	var chosen []string
+	total := 0
+	for _, c := range candidates {
+		if total+len(c.segment) > size {
+			continue
+		}
+		chosen = append(chosen, c.segment)
+		total += len(c.segment)
+	}
+
+	var content bytes.Buffer
+	for i := len(chosen) - 1; i >= 0; i-- {
+		content.WriteString(chosen[i])
+	}
+	if content.Len() < minContent {
+		return nil, nil
+	}
+
+	dict, err := zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       contentID(content.Bytes()),
+		Contents: samples,
+		History:  content.Bytes(),
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstd.SpeedBestCompression,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build compression dictionary: %w", err)
+	}
+	return dict, nil
+}
+
+// segments cuts data after every newline and comma
+func segments(data []byte) [][]byte {
+	var out [][]byte
+	start := 0
+	for i, b := range data {
+		if b == '\n' || b == ',' {
+			out = append(out, data[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		out = append(out, data[start:])
+	}
+	return out
+}
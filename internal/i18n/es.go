@@ -0,0 +1,1138 @@
+package i18n
+
+// spanish is the Spanish (es) message catalog
+var spanish = map[string]string{
+	// Prompts
+	"(y/n)":                               "(s/n)",
+	"y|yes":                               "s|si|sí",
+	"Enter encryption password: ":         "Introduzca la contraseña de cifrado: ",
+	"Confirm encryption password: ":       "Confirme la contraseña de cifrado: ",
+	"Enter choice (1-%d): ":               "Introduzca una opción (1-%d): ",
+	"Enter choice (1-2)":                  "Introduzca una opción (1-2)",
+	"Enter choice (1-3)":                  "Introduzca una opción (1-3)",
+	"Delete decrypted file now?":          "¿Eliminar ahora el archivo descifrado?",
+	"Proceed with backup?":                "¿Continuar con la copia de seguridad?",
+	"Continue with metadata-only backup?": "¿Continuar con una copia solo de metadatos?",
+	"Use separate password for each manager? (more secure)": "¿Usar una contraseña distinta para cada gestor? (más seguro)",
+	"Do you want to overwrite it?":                          "¿Desea sobrescribirlo?",
+
+	// Common
+	"Error: %v":                                "Error: %v",
+	"Encryption password is required":          "La contraseña de cifrado es obligatoria",
+	"Passwords do not match!":                  "¡Las contraseñas no coinciden!",
+	"Next steps:":                              "Próximos pasos:",
+	"No backups found":                         "No se encontraron copias de seguridad",
+	"No password managers enabled or selected": "No hay gestores de contraseñas habilitados o seleccionados",
+	"No storage backends enabled or selected":  "No hay destinos de almacenamiento habilitados o seleccionados",
+	"⚠ %s: %v":                                 "⚠ %s: %v",
+	"⚠ %s is not available":                    "⚠ %s no está disponible",
+	"  (none)":                                 "  (ninguna)",
+	"Planned operations:":                      "Operaciones previstas:",
+	"To perform these operations, run the same command without --dry-run": "Para realizar estas operaciones, ejecute el mismo comando sin --dry-run",
+	"✅ Dry run complete! Nothing was changed.":                            "✅ ¡Simulación completada! No se cambió nada.",
+	"🔍 DRY RUN MODE - Preview Only (nothing will be changed)":             "🔍 MODO SIMULACIÓN - Solo vista previa (no se cambiará nada)",
+
+	// Restore
+	"🔓 Restore Backup": "🔓 Restaurar copia de seguridad",
+	"No backup file specified. Use --file, --latest, --before, or --interactive": "No se indicó ningún archivo. Use --file, --latest, --before o --interactive",
+	"Searching for backup file: %s":                                              "Buscando el archivo de copia: %s",
+	"✓ Found backup in %s":                                                       "✓ Copia encontrada en %s",
+	"Loading backup from %s...":                                                  "Cargando copia desde %s...",
+	"✓ Loaded backup":                                                            "✓ Copia cargada",
+	"Decrypting backup...":                                                       "Descifrando la copia...",
+	"Failed to decrypt: %v":                                                      "No se pudo descifrar: %v",
+	"Make sure you're using the correct encryption password":                     "Asegúrese de usar la contraseña de cifrado correcta",
+	"✓ Decrypted successfully":                                                   "✓ Descifrado correctamente",
+	"Decompressing data...":                                                      "Descomprimiendo datos...",
+	"Failed to decompress: %v":                                                   "No se pudo descomprimir: %v",
+	"Backup may not be compressed, using decrypted data as-is":                   "Puede que la copia no esté comprimida; se usarán los datos descifrados tal cual",
+	"✓ Decompressed successfully":                                                "✓ Descomprimido correctamente",
+	"Writing output file...":                                                     "Escribiendo el archivo de salida...",
+	"✓ Output written to: %s":                                                    "✓ Archivo guardado en: %s",
+	"✅ Backup restored successfully!":                                            "✅ ¡Copia restaurada correctamente!",
+	"  1. Open Bitwarden web vault or desktop app":                               "  1. Abra la bóveda web o la aplicación de escritorio de Bitwarden",
+	"  2. Go to Tools → Import Data":                                             "  2. Vaya a Herramientas → Importar datos",
+	"  3. Select 'Bitwarden (json)' as format":                                   "  3. Seleccione 'Bitwarden (json)' como formato",
+	"  4. Upload the file: %s":                                                   "  4. Suba el archivo: %s",
+	"  1. The JSON file contains your 1Password vault data":                      "  1. El archivo JSON contiene los datos de su bóveda de 1Password",
+	"  2. You can inspect it manually or use 1Password CLI:":                     "  2. Puede revisarlo manualmente o usar la CLI de 1Password:",
+	"  3. Alternatively, contact 1Password support for import assistance":        "  3. También puede pedir ayuda al soporte de 1Password para importarlo",
+	"  4. File location: %s":                                                     "  4. Ubicación del archivo: %s",
+	"  1. The decrypted file is at: %s":                                          "  1. El archivo descifrado está en: %s",
+	"  2. Import it into your password manager":                                  "  2. Impórtelo en su gestor de contraseñas",
+	"⚠️  SECURITY WARNING: Decrypted file contains your passwords!":              "⚠️  AVISO DE SEGURIDAD: ¡El archivo descifrado contiene sus contraseñas!",
+	"This file will NOT auto-delete. Please delete it manually after use:":       "Este archivo NO se eliminará solo. Elimínelo manualmente después de usarlo:",
+	"Or press any key to delete it now...":                                       "O elimínelo ahora...",
+	"✓ Decrypted file deleted":                                                   "✓ Archivo descifrado eliminado",
+	"Failed to delete file: %v":                                                  "No se pudo eliminar el archivo: %v",
+	"⚠️  SECURITY: Auto-delete enabled":                                          "⚠️  SEGURIDAD: Eliminación automática activada",
+	"Decrypted file will be automatically deleted in %d minute(s)":               "El archivo descifrado se eliminará automáticamente en %d minuto(s)",
+	"File location: %s":                                                          "Ubicación del archivo: %s",
+	"Press Ctrl+C to cancel auto-delete":                                         "Pulse Ctrl+C para cancelar la eliminación automática",
+	"⚠️  1 minute remaining until auto-delete...":                                "⚠️  Queda 1 minuto para la eliminación automática...",
+	"%d minutes remaining...":                                                    "Quedan %d minutos...",
+	"Deleting decrypted file...":                                                 "Eliminando el archivo descifrado...",
+	"Please delete manually: rm \"%s\"":                                          "Elimínelo manualmente: rm \"%s\"",
+	"✓ Decrypted file deleted successfully":                                      "✓ Archivo descifrado eliminado correctamente",
+	"Selected latest backup: %s":                                                 "Copia más reciente seleccionada: %s",
+	"Selected backup before %s: %s":                                              "Copia anterior a %s seleccionada: %s",
+	"  Source: %s":                                                               "  Origen: %s",
+	"  Modified: %s":                                                             "  Modificado: %s",
+	"  Size: %s":                                                                 "  Tamaño: %s",
+	"  Name: %s":                                                                 "  Nombre: %s",
+	"📋 Available Backups:":                                                       "📋 Copias disponibles:",
+	"\n%s Backups:":                                                              "\nCopias de %s:",
+	"     Source: %s | Size: %s | Age: %s":                                       "     Origen: %s | Tamaño: %s | Antigüedad: %s",
+	"✓ Selected: %s":                                                             "✓ Seleccionado: %s",
+	"🔍 Backup Preview (without decryption)":                                      "🔍 Vista previa de la copia (sin descifrar)",
+	"File Information:":                                                          "Información del archivo:",
+	"Encryption Header:":                                                         "Cabecera de cifrado:",
+	"Detected Manager: %s":                                                       "Gestor detectado: %s",
+	"Backup Date: %s":                                                            "Fecha de la copia: %s",
+	"Backup Age: %s":                                                             "Antigüedad de la copia: %s",
+	"To decrypt this backup, run:":                                               "Para descifrar esta copia, ejecute:",
+	"     Edge: open edge://wallet/passwords → Import passwords":                 "     Edge: abra edge://wallet/passwords → Importar contraseñas",
+	"     op item create --vault <vault> --template <template> --title <title>":  "     op item create --vault <bóveda> --template <plantilla> --title <título>",
+	"  1. Chrome: open chrome://password-manager/settings → Import passwords":    "  1. Chrome: abra chrome://password-manager/settings → Importar contraseñas",
+	"  1. Import the %s file into your password manager":                         "  1. Importe el archivo %s en su gestor de contraseñas",
+	"  1. Open the file in any KeePass client (KeePassXC, KeePass, KeeWeb, ...)": "  1. Abra el archivo en cualquier cliente de KeePass (KeePassXC, KeePass, KeeWeb, ...)",
+	"  2. File location: %s":   "  2. Ubicación del archivo: %s",
+	"  2. Select the file: %s": "  2. Seleccione el archivo: %s",
+	"  2. Unlock it with the KeePass database password you entered": "  2. Desbloquéelo con la contraseña de la base de datos KeePass que introdujo",
+	"  3. File location: %s":    "  3. Ubicación del archivo: %s",
+	"  Backup Age: %s":          "  Antigüedad de la copia: %s",
+	"  Backup Date: %s":         "  Fecha de la copia: %s",
+	"  Created With: stashr %s": "  Creada con: stashr %s",
+	"  Format: OpenPGP message (see its recipients with: gpg --list-packets)": "  Formato: mensaje OpenPGP (vea sus destinatarios con: gpg --list-packets)",
+	"  Format: Valid stashr encrypted backup":                                 "  Formato: copia cifrada de stashr válida",
+	"  Format: age encrypted backup (age-encryption.org/v1)":                  "  Formato: copia cifrada con age (age-encryption.org/v1)",
+	"  Items: %d":                     "  Elementos: %d",
+	"  Magic: %s ✓":                   "  Firma mágica: %s ✓",
+	"  Metadata: encrypted":           "  Metadatos: cifrados",
+	"  Original Name: %s":             "  Nombre original: %s",
+	"  Recipients: %d (%s)":           "  Destinatarios: %d (%s)",
+	"  Vault Size: %s (uncompressed)": "  Tamaño de la bóveda: %s (sin comprimir)",
+	"  Version: %d":                   "  Versión: %d",
+	"  stashr restore --file %s":      "  stashr restore --file %s",
+	"--dry-run previews --import; a restore without it only writes a file":                "--dry-run muestra una vista previa de --import; una restauración sin él solo escribe un archivo",
+	"--import imports the backup's JSON export and can't be combined with --as %s":        "--import importa la exportación JSON de la copia y no se puede combinar con --as %s",
+	"--import of a 1Password backup needs --vault, the vault to create the items in":      "--import de una copia de 1Password necesita --vault, la bóveda donde crear los elementos",
+	"--import only imports Bitwarden and 1Password backups; %s is a %s backup":            "--import solo importa copias de Bitwarden y 1Password; %s es una copia de %s",
+	"--manager selects a backup with --latest, --before, --interactive or --all-latest":   "--manager selecciona una copia con --latest, --before, --interactive o --all-latest",
+	"--stdout would print your passwords on the terminal; pipe it into a command instead": "--stdout mostraría sus contraseñas en el terminal; rediríjalo a un comando",
+	"A KeePass database can't be copied to the clipboard; use --stdout or write a file":   "Una base de datos KeePass no se puede copiar al portapapeles; use --stdout o escriba un archivo",
+	"Backup Metadata:":        "Metadatos de la copia:",
+	"Backup is not encrypted": "La copia no está cifrada",
+	"Choose another --output path, or pass --force to write there anyway":                              "Elija otra ruta para --output, o use --force para escribir ahí de todos modos",
+	"Clipboard history tools may keep their own copy":                                                  "Las herramientas de historial del portapapeles pueden guardar su propia copia",
+	"Confirm KeePass database password: ":                                                              "Confirme la contraseña de la base de datos KeePass: ",
+	"Converting to KeePass database...":                                                                "Convirtiendo a base de datos KeePass...",
+	"Copy something else to replace it":                                                                "Copie otra cosa para reemplazarlo",
+	"Decrypting backup with the password from the OS keychain...":                                      "Descifrando la copia con la contraseña del llavero del sistema...",
+	"Enter a password for the KeePass database (leave empty to reuse the encryption password): ":       "Introduzca una contraseña para la base de datos KeePass (déjela vacía para reutilizar la contraseña de cifrado): ",
+	"Enter encryption password to show the backup's metadata (leave empty to skip): ":                  "Introduzca la contraseña de cifrado para mostrar los metadatos de la copia (déjela vacía para omitirlo): ",
+	"Failed to clear the clipboard: %v":                                                                "No se pudo vaciar el portapapeles: %v",
+	"Failed to query backup database: %v":                                                              "No se pudo consultar la base de datos de copias: %v",
+	"File does not appear to be an encrypted stashr backup":                                            "El archivo no parece una copia cifrada de stashr",
+	"File too small to contain valid header":                                                           "El archivo es demasiado pequeño para contener una cabecera válida",
+	"It will be cleared in %d minute(s); paste it where it's needed, then press Enter to clear it now": "Se vaciará en %d minuto(s); péguelo donde lo necesite y pulse Intro para vaciarlo ya",
+	"Magic bytes: %s (expected: PWBK)":                                                                 "Bytes mágicos: %s (se esperaba: PWBK)",
+	"Naming Format: %s":                                                                                "Formato de nombre: %s",
+	"No backup file specified. Use --file, --checksum, --latest, --before, or --interactive":           "No se indicó ningún archivo. Use --file, --checksum, --latest, --before o --interactive",
+	"Recorded backup %s could not be verified, scanning storage...":                                    "No se pudo verificar la copia registrada %s, examinando el almacenamiento...",
+	"Recorded copy of %s on %s could not be verified, trying other destinations...":                    "No se pudo verificar la copia registrada de %s en %s, probando otros destinos...",
+	"Searching for backup with checksum: %s":                                                           "Buscando la copia con la suma de comprobación: %s",
+	"Searching other destinations for: %s":                                                             "Buscando en otros destinos: %s",
+	"Unknown --on-conflict: %s (use: %s)":                                                              "--on-conflict desconocido: %s (use: %s)",
+	"Unknown --output-encrypted: %s (use: %s)":                                                         "--output-encrypted desconocido: %s (use: %s)",
+	"Unknown output format: %s (use: json, kdbx, %s)":                                                  "Formato de salida desconocido: %s (use: json, kdbx, %s)",
+	"ZIP encryption is %v; backup.encryption.fips allows --output-encrypted=stashr only":               "El cifrado ZIP es %v; backup.encryption.fips solo permite --output-encrypted=stashr",
+	"⚠ Couldn't read the metadata: %v":                                                                 "⚠ No se pudieron leer los metadatos: %v",
+	"⚠ Output mode %04o lets other users read your passwords":                                          "⚠ El modo de salida %04o permite a otros usuarios leer sus contraseñas",
+	"⚠ The password from the OS keychain didn't decrypt this backup":                                   "⚠ La contraseña del llavero del sistema no descifró esta copia",
+	"⚠️  SECURITY: The clipboard holds your passwords until it is cleared":                             "⚠️  SEGURIDAD: el portapapeles contiene sus contraseñas hasta que se vacíe",
+	"✅ Backup imported!":                                                                               "✅ ¡Copia importada!",
+	"✓ Clipboard cleared":                                                                              "✓ Portapapeles vaciado",
+	"✓ Converted %d items to KeePass format":                                                           "✓ %d elementos convertidos a formato KeePass",
+	"✓ Decrypted output copied to the clipboard":                                                       "✓ Salida descifrada copiada al portapapeles",
+	"✓ Decrypted output written to standard output":                                                    "✓ Salida descifrada escrita en la salida estándar",
+	"✓ Found %s in %s (checksum verified)":                                                             "✓ %s encontrado en %s (suma de comprobación verificada)",
+	"✓ Loaded backup from %s":                                                                          "✓ Copia cargada desde %s",
+	"💡 Pass the backup's key file with --encryption-key to show its metadata":                          "💡 Indique el archivo de clave de la copia con --encryption-key para mostrar sus metadatos",
+	"  Source: %s | Modified: %s | Size: %s":                                                           "  Origen: %s | Modificada: %s | Tamaño: %s",
+	"Choose another --output directory, or pass --force to write there anyway":                         "Elija otra carpeta para --output, o use --force para escribir ahí de todos modos",
+	"Decrypt them with: stashr crypt decrypt <file>":                                                   "Descífrelos con: stashr crypt decrypt <archivo>",
+	"Extract them with 7-Zip, WinZip, Keka or another archiver that supports AES":                      "Extráigalos con 7-Zip, WinZip, Keka u otro compresor compatible con AES",
+	"Failed to delete %s: %v":                                                                          "No se pudo eliminar %s: %v",
+	"Files:":                                                                                           "Archivos:",
+	"Import them into your password managers, then delete them":                                        "Impórtelos en sus gestores de contraseñas y elimínelos después",
+	"No backups of a known password manager found":                                                     "No se encontraron copias de ningún gestor de contraseñas conocido",
+	"Restoring the latest backup of %d password manager(s) into %s":                                    "Restaurando la copia más reciente de %d gestor(es) de contraseñas en %s",
+	"⚠ Restored %d of %d backups; %d failed":                                                           "⚠ Restauradas %d de %d copias; %d fallaron",
+	"⚠️  SECURITY WARNING: Decrypted files contain your passwords!":                                    "⚠️  ADVERTENCIA DE SEGURIDAD: ¡los archivos descifrados contienen sus contraseñas!",
+	"✅ Restored the latest backup of %d password manager(s)!":                                          "✅ ¡Restaurada la copia más reciente de %d gestor(es) de contraseñas!",
+	"✓ Decrypted files deleted":                                                                        "✓ Archivos descifrados eliminados",
+	"     (the ZIP support built into Windows and macOS may not open it)":                              "     (puede que la compatibilidad con ZIP integrada en Windows y macOS no lo abra)",
+	"  1. Copy %s to the other machine":                                                                "  1. Copie %s al otro equipo",
+	"  2. Decrypt it with: stashr crypt decrypt \"%s\"":                                                "  2. Descífrelo con: stashr crypt decrypt \"%s\"",
+	"  2. Extract it with 7-Zip, WinZip, Keka or another archiver that supports AES":                   "  2. Extráigalo con 7-Zip, WinZip, Keka u otro compresor compatible con AES",
+	"  3. Import the extracted file, then delete it: it holds your passwords in plaintext":             "  3. Importe el archivo extraído y elimínelo después: contiene sus contraseñas en texto plano",
+	"Encrypting into a ZIP archive (AES-256)...":                                                       "Cifrando en un archivo ZIP (AES-256)...",
+	"Encrypting into a stashr encrypted file...":                                                       "Cifrando en un archivo cifrado de stashr...",
+	"⚠️  If you forget this password, the %s can't be opened":                                          "⚠️  Si olvida esta contraseña, el %s no se podrá abrir",
+	"✅ Backup restored into an encrypted container!":                                                   "✅ ¡Copia restaurada en un contenedor cifrado!",
+	"✓ Encrypted successfully":                                                                         "✓ Cifrado correctamente",
+	"  Check the vault for duplicates: items it already had were imported again":                       "  Revise si hay duplicados en la bóveda: los elementos que ya tenía se importaron de nuevo",
+	"%d of %d items are skipped; run with --dry-run to see each item":                                  "Se omiten %d de %d elementos; ejecute con --dry-run para ver cada elemento",
+	"Back up the current Bitwarden vault (%d items) with stashr backup":                                "Hacer copia de la bóveda actual de Bitwarden (%d elementos) con stashr backup",
+	"Backing up the current vault before importing...":                                                 "Haciendo copia de la bóveda actual antes de importar...",
+	"Create %q (%s)": "Crear %q (%s)",
+	"Create %q (%s) as %q: %q is already in the vault":                               "Crear %q (%s) como %q: %q ya está en la bóveda",
+	"Create %q (%s) next to the one already in the vault":                            "Crear %q (%s) junto al que ya está en la bóveda",
+	"Creating %d items in 1Password vault %s...":                                     "Creando %d elementos en la bóveda de 1Password %s...",
+	"Nothing to import: every item is already in vault %s or can't be imported":      "Nada que importar: todos los elementos ya están en la bóveda %s o no se pueden importar",
+	"Run the import again to create the rest: the items already created are skipped": "Vuelva a ejecutar la importación para crear el resto: se omiten los elementos ya creados",
+	"Skip %q (%s): already in the vault":                                             "Omitir %q (%s): ya está en la bóveda",
+	"The vault has %d items. Bitwarden adds imported items next to them without":     "La bóveda tiene %d elementos. Bitwarden añade los elementos importados junto a ellos sin",
+	"merging, so items that are already there will be duplicated.":                   "combinarlos, así que los elementos que ya estén se duplicarán.",
+	"⚠ Can't import %q (%s): %s":                                                     "⚠ No se puede importar %q (%s): %s",
+	"✓ Backed up the current vault":                                                  "✓ Copia de la bóveda actual hecha",
+	"✓ Created %d items in vault %s":                                                 "✓ %d elementos creados en la bóveda %s",
+
+	// Backup
+	"🔐 Password Manager Backup Tool":                                            "🔐 Herramienta de copias de seguridad de gestores de contraseñas",
+	"⚠️  CRITICAL: If you forget this password, your backups are LOST FOREVER!": "⚠️  CRÍTICO: ¡Si olvida esta contraseña, sus copias se PERDERÁN PARA SIEMPRE!",
+	"💡 Store this password in your password manager or write it down securely":  "💡 Guarde esta contraseña en su gestor de contraseñas o anótela en un lugar seguro",
+	"Backing up %s...":                               "Haciendo copia de %s...",
+	"✓ %s CLI found":                                 "✓ CLI de %s encontrada",
+	"✓ Authenticated":                                "✓ Autenticado",
+	"  Found %d items":                               "  %d elementos encontrados",
+	"Exporting vault data...":                        "Exportando los datos de la bóveda...",
+	"✓ Exported vault data (%s)":                     "✓ Datos de la bóveda exportados (%s)",
+	"Compressing data...":                            "Comprimiendo datos...",
+	"✓ Compressed (%s → %s)":                         "✓ Comprimido (%s → %s)",
+	"Encrypting backup...":                           "Cifrando la copia...",
+	"✓ Encrypted":                                    "✓ Cifrado",
+	"Uploading to %s...":                             "Subiendo a %s...",
+	"✓ Uploaded to %s (%.1fs)":                       "✓ Subido a %s (%.1fs)",
+	"Applying retention policy...":                   "Aplicando la política de retención...",
+	"  Deleted %d old backup(s)":                     "  %d copia(s) antigua(s) eliminada(s)",
+	"✅ Backup completed for %s (%s)":                 "✅ Copia completada para %s (%s)",
+	"✅ Backup completed!":                            "✅ ¡Copia de seguridad completada!",
+	"Backup cancelled":                               "Copia de seguridad cancelada",
+	"     - Complete backup":                         "     - Copia completa",
+	"     - Everything including passwords":          "     - Todo, incluidas las contraseñas",
+	"     - Item titles, usernames, URLs":            "     - Títulos de elementos, nombres de usuario, URL",
+	"     - ⚠️  NO passwords included":               "     - ⚠️  NO incluye contraseñas",
+	"    Run: bw unlock":                             "    Ejecute: bw unlock",
+	"    Run: op signin":                             "    Ejecute: op signin",
+	"  %d. All enabled destinations":                 "  %d. Todos los destinos habilitados",
+	"  %s already exists, saving as %s":              "  %s ya existe, se guarda como %s",
+	"  1. Bitwarden only":                            "  1. Solo Bitwarden",
+	"  1. Metadata only (fast, ~1-2 seconds)":        "  1. Solo metadatos (rápido, ~1-2 segundos)",
+	"  1Password mode: %s":                           "  Modo de 1Password: %s",
+	"  2. 1Password only":                            "  2. Solo 1Password",
+	"  2. Full export (slow, ~5-10 minutes)":         "  2. Exportación completa (lenta, ~5-10 minutos)",
+	"  3. Both (all)":                                "  3. Ambos (todos)",
+	"  Algorithm: %s":                                "  Algoritmo: %s",
+	"  Algorithm: OpenPGP (gpg)":                     "  Algoritmo: OpenPGP (gpg)",
+	"  Algorithm: age (ChaCha20-Poly1305)":           "  Algoritmo: age (ChaCha20-Poly1305)",
+	"  Classification: %s":                           "  Clasificación: %s",
+	"  Compression: %s":                              "  Compresión: %s",
+	"  Encryption: %v":                               "  Cifrado: %v",
+	"  Key derivation: PBKDF2-SHA256, %d iterations": "  Derivación de clave: PBKDF2-SHA256, %d iteraciones",
+	"  Key file: %s":                                 "  Archivo de clave: %s",
+	"  Managers: %s":                                 "  Gestores: %s",
+	"  Moved %d old backup(s) to the trash":          "  %d copia(s) antigua(s) movida(s) a la papelera",
+	"  Password prompt: %s":                          "  Solicitud de contraseña: %s",
+	"  Processing item %d/%d: %s":                    "  Procesando el elemento %d/%d: %s",
+	"  Recipients: %d (no password prompt)":          "  Destinatarios: %d (sin solicitud de contraseña)",
+	"  Recipients: %s (no password prompt)":          "  Destinatarios: %s (sin solicitud de contraseña)",
+	"  Uploaded %s of %s (%d%%)":                     "  Subido %s de %s (%d%%)",
+	"  Uploaded to %d of %d destinations in %.1fs":   "  Subida a %d de %d destinos en %.1fs",
+	"  Using: 1Password (only enabled manager)":      "  Se usa: 1Password (único gestor habilitado)",
+	"  Using: Bitwarden (only enabled manager)":      "  Se usa: Bitwarden (único gestor habilitado)",
+	"  ⏱️  Estimated time: %d-%d minutes":            "  ⏱️  Tiempo estimado: %d-%d minutos",
+	"  ⏱️  Estimated time: <5 seconds":               "  ⏱️  Tiempo estimado: <5 segundos",
+	"  ⚠ %s does not exist (create it with: stashr keyfile generate)": "  ⚠ %s no existe (créelo con: stashr keyfile generate)",
+	"  ⚠ Could not list existing backups: %v":                         "  ⚠ No se pudieron listar las copias existentes: %v",
+	"  ⚠️  Export mode: Metadata only (NO passwords)":                 "  ⚠️  Modo de exportación: solo metadatos (SIN contraseñas)",
+	"  ⛔ %s: blocked (%v)":                                            "  ⛔ %s: bloqueado (%v)",
+	"  ✓ Authenticated":                                               "  ✓ Autenticado",
+	"  ✓ Available":                                                   "  ✓ Disponible",
+	"  ✓ CLI found":                                                   "  ✓ CLI encontrada",
+	"  ✓ Categories and tags":                                         "  ✓ Categorías y etiquetas",
+	"  ✓ Item titles, usernames, URLs":                                "  ✓ Títulos de elementos, nombres de usuario, URL",
+	"  ✗ Actual passwords (NOT included)":                             "  ✗ Contraseñas reales (NO incluidas)",
+	"  ✗ CLI not installed at: %s":                                    "  ✗ CLI no instalada en: %s",
+	"  ✗ Error: %v":                                                   "  ✗ Error: %v",
+	"  ✗ Not authenticated":                                           "  ✗ No autenticado",
+	"  ✗ Not available":                                               "  ✗ No disponible",
+	"  🏷️  Classification: %s":                                        "  🏷️  Clasificación: %s",
+	"  💾 Free space: %s":                                              "  💾 Espacio libre: %s",
+	"  📁 Existing backups: %d":                                        "  📁 Copias existentes: %d",
+	"  📊 Items: %d":                                                   "  📊 Elementos: %d",
+	"  📦 Estimated size: %s":                                          "  📦 Tamaño estimado: %s",
+	"  🔐 Encryption: %s":                                              "  🔐 Cifrado: %s",
+	"  🔐 Export mode: Full (with passwords)":                          "  🔐 Modo de exportación: completo (con contraseñas)",
+	"  🗑️  Old backups to delete: %d (keeping %s per manager)":        "  🗑️  Copias antiguas que se eliminarán: %d (se conservan %s por gestor)",
+	"%s (retention)":                                                  "%s (retención)",
+	"1Password Export Mode:":                                          "Modo de exportación de 1Password:",
+	"Backing up %d managers in parallel":                              "Copiando %d gestores en paralelo",
+	"Checking %s...":                                                  "Comprobando %s...",
+	"Compressing data with %s...":                                     "Comprimiendo los datos con %s...",
+	"Continuing with a metadata-only backup (non-interactive)":        "Se continúa con una copia solo de metadatos (modo no interactivo)",
+	"Encrypting backup for deduplication...":                          "Cifrando la copia para la deduplicación...",
+	"Encrypting backup to %d GPG recipient(s)...":                     "Cifrando la copia para %d destinatario(s) GPG...",
+	"Encrypting backup to %d age recipient(s)...":                     "Cifrando la copia para %d destinatario(s) age...",
+	"Encrypting backup with key file...":                              "Cifrando la copia con el archivo de clave...",
+	"Encryption Settings:":                                            "Configuración de cifrado:",
+	"Exporting vault data with full details (including passwords)...": "Exportando los datos de la bóveda con todos los detalles (incluidas las contraseñas)...",
+	"Failed to apply retention policy: %v":                            "No se pudo aplicar la política de retención: %v",
+	"Failed to collect vault statistics: %v":                          "No se pudieron recopilar las estadísticas de la bóveda: %v",
+	"Failed to list backups for retention: %v":                        "No se pudieron listar las copias para la retención: %v",
+	"Failed to record backup checksum: %v":                            "No se pudo registrar la suma de comprobación de la copia: %v",
+	"Failed to record backup in database: %v":                         "No se pudo registrar la copia en la base de datos: %v",
+	"Failed to record backup labels: %v":                              "No se pudieron registrar las etiquetas de clasificación de la copia: %v",
+	"Failed to record backup verification: %v":                        "No se pudo registrar la verificación de la copia: %v",
+	"Failed to record filename format: %v":                            "No se pudo registrar el formato del nombre de archivo: %v",
+	"Failed to record vault statistics: %v":                           "No se pudieron registrar las estadísticas de la bóveda: %v",
+	"Failed to release lock on %s: %v":                                "No se pudo liberar el bloqueo de %s: %v",
+	"For a complete backup with passwords, use: --full-export":        "Para una copia completa con contraseñas, use: --full-export",
+	"Invalid choice, using default (all)":                             "Opción no válida, se usa el valor predeterminado (todos)",
+	"It runs at the next scheduled time the conditions are met, or now with --ignore-conditions": "Se ejecutará en la próxima hora programada en que se cumplan las condiciones, o ahora con --ignore-conditions",
+	"Note: Full export is slower but includes all sensitive data":                                "Nota: la exportación completa es más lenta pero incluye todos los datos sensibles",
+	"Password Managers to Backup:":                       "Gestores de contraseñas que se copiarán:",
+	"Skipping retention policy: %v":                      "Se omite la política de retención: %v",
+	"Storage Destinations:":                              "Destinos de almacenamiento:",
+	"This backup will include:":                          "Esta copia incluirá:",
+	"Upload %s to %s":                                    "Subir %s a %s",
+	"Uploading to %d destinations in parallel...":        "Subiendo a %d destinos en paralelo...",
+	"Verify the stored copy of %s on %s":                 "Verificar la copia almacenada de %s en %s",
+	"Verifying stored copy...":                           "Verificando la copia almacenada...",
+	"Waiting for %s to upload the backup...":             "Esperando a que %s suba la copia...",
+	"Which password managers would you like to backup?":  "¿De qué gestores de contraseñas desea hacer copia?",
+	"Which storage destinations would you like to use?":  "¿Qué destinos de almacenamiento desea usar?",
+	"stdin is not a terminal: running non-interactively": "stdin no es un terminal: se ejecuta en modo no interactivo",
+	"⏸ Postponing the backup: %s":                        "⏸ Se pospone la copia: %s",
+	"⚠ %s: backup hasn't reached the cloud yet (%s); until it does it's only on this machine":                           "⚠ %s: la copia aún no ha llegado a la nube (%s); hasta entonces solo está en este equipo",
+	"⚠ %s: couldn't confirm the backup left this machine: %v":                                                           "⚠ %s: no se pudo confirmar que la copia haya salido de este equipo: %v",
+	"⚠ Could not check free space on %s: %v":                                                                            "⚠ No se pudo comprobar el espacio libre en %s: %v",
+	"⚠ Failed to upload signature: %v":                                                                                  "⚠ No se pudo subir la firma: %v",
+	"⚠ Low free space on %s: %s":                                                                                        "⚠ Poco espacio libre en %s: %s",
+	"⚠ Not queuing the upload to %s: it uses a separate password, which wasn't asked for":                               "⚠ No se pone en cola la subida a %s: usa una contraseña propia, que no se solicitó",
+	"⚠️  1PASSWORD BACKUP MODE: Metadata Only (Fast)":                                                                   "⚠️  MODO DE COPIA DE 1PASSWORD: solo metadatos (rápido)",
+	"⚠️  Backup interrupted":                                                                                            "⚠️  Copia interrumpida",
+	"⚠️  Full export is only supported for 1Password. Using standard export for %s.":                                    "⚠️  La exportación completa solo es compatible con 1Password. Se usa la exportación estándar para %s.",
+	"⚠️  Skipping export validation":                                                                                    "⚠️  Se omite la validación de la exportación",
+	"⚠️  This may take several minutes for large vaults...":                                                             "⚠️  Puede tardar varios minutos en bóvedas grandes...",
+	"⚠️  Weak encryption password (about %.0f bits): backups can be attacked offline, so a stronger one is recommended": "⚠️  Contraseña de cifrado débil (unos %.0f bits): las copias pueden atacarse sin conexión, así que se recomienda una más segura",
+	"✓ %s has the backup":                                                                                               "✓ %s tiene la copia",
+	"✓ %s: done in %.1fs":                                                                                               "✓ %s: terminado en %.1fs",
+	"✓ Encrypted (%d pieces)":                                                                                           "✓ Cifrado (%d fragmentos)",
+	"✓ Encrypting with key file %s (key %s)":                                                                            "✓ Cifrando con el archivo de clave %s (clave %s)",
+	"✓ Exported %d items with full details":                                                                             "✓ %d elementos exportados con todos los detalles",
+	"✓ Signed as %s":                                                              "✓ Firmado como %s",
+	"✓ Signing backups as %s":                                                     "✓ Las copias se firman como %s",
+	"✓ Using the encryption password from %s":                                     "✓ Se usa la contraseña de cifrado de %s",
+	"✓ Using the encryption password from the OS keychain":                        "✓ Se usa la contraseña de cifrado del llavero del sistema",
+	"✓ Validated export (%d items)":                                               "✓ Exportación validada (%d elementos)",
+	"✓ Verified stored copy (%s)":                                                 "✓ Copia almacenada verificada (%s)",
+	"✓ Will perform full export with passwords":                                   "✓ Se hará una exportación completa con contraseñas",
+	"✓ Will perform metadata-only export":                                         "✓ Se hará una exportación solo de metadatos",
+	"✗ %s: failed after %.1fs":                                                    "✗ %s: falló tras %.1fs",
+	"✗ Upload to %s blocked: %v":                                                  "✗ Subida a %s bloqueada: %v",
+	"📋 Interactive Backup Setup":                                                  "📋 Configuración interactiva de la copia",
+	"📝 Backup Summary:":                                                           "📝 Resumen de la copia:",
+	"⚠ %s not pinged: %v":                                                         "⚠ No se hizo ping a %s: %v",
+	"⚠ Backup notification not sent: %v":                                          "⚠ Notificación de copia no enviada: %v",
+	"✓ Pinged %s":                                                                 "✓ Ping enviado a %s",
+	"✓ Sent backup notification":                                                  "✓ Notificación de copia enviada",
+	"  ✓ %s: available":                                                           "  ✓ %s: disponible",
+	"  ✓ %s: installed and authenticated":                                         "  ✓ %s: instalado y autenticado",
+	"Pre-flight: %d/%d managers ready, %d/%d destinations available":              "Comprobación previa: %d/%d gestores listos, %d/%d destinos disponibles",
+	"Running pre-flight checks...":                                                "Ejecutando las comprobaciones previas...",
+	"⚠ Go with gaps: continuing without %s (use --strict to abort instead)":       "⚠ Adelante con carencias: se continúa sin %s (use --strict para abortar)",
+	"⛔ No-go: %d check(s) failed (%s). Aborting because of --strict":              "⛔ Alto: fallaron %d comprobación(es) (%s). Se aborta por --strict",
+	"⛔ No-go: no password manager is ready":                                       "⛔ Alto: ningún gestor de contraseñas está listo",
+	"⛔ No-go: no storage destination is available":                                "⛔ Alto: no hay ningún destino de almacenamiento disponible",
+	"✅ Go: all checks passed":                                                     "✅ Adelante: se superaron todas las comprobaciones",
+	"⚠ Can't read the free space of %s (%v): not postponing":                      "⚠ No se puede leer el espacio libre de %s (%v): no se pospone",
+	"⚠ Can't tell whether the machine runs on AC power (%v): not postponing":      "⚠ No se sabe si el equipo está conectado a la corriente (%v): no se pospone",
+	"⚠ Can't tell whether the network connection is metered (%v): not postponing": "⚠ No se sabe si la conexión de red es de uso medido (%v): no se pospone",
+	"%s changed since its last backup: backing up...":                             "%s cambió desde su última copia: haciendo copia...",
+	"Failed to read change state of %s: %v":                                       "No se pudo leer el estado de cambios de %s: %v",
+	"Failed to record change state of %s: %v":                                     "No se pudo registrar el estado de cambios de %s: %v",
+	"No backup of %s is recorded for change detection yet: backing up...":         "Aún no hay ninguna copia de %s registrada para detectar cambios: haciendo copia...",
+	"Retrying at %s if it is still changed":                                       "Se reintentará a las %s si sigue habiendo cambios",
+	"⏸ Postponing the backup of %s: %s":                                           "⏸ Se pospone la copia de %s: %s",
+	"⚠ Can't check %s for changes: %v":                                            "⚠ No se pueden comprobar los cambios de %s: %v",
+	"✓ Backed up %s at its current revision":                                      "✓ Copia de %s hecha en su revisión actual",
+	"Failed to remove lock file %s: %v":                                           "No se pudo eliminar el archivo de bloqueo %s: %v",
+	"⚠ Breaking the lock of stashr %s (PID %d on %s) with --force":                "⚠ Rompiendo el bloqueo de stashr %s (PID %d en %s) con --force",
+	"⚠ Breaking the stale lock of stashr %s (PID %d exited without releasing it)": "⚠ Rompiendo el bloqueo obsoleto de stashr %s (el PID %d terminó sin liberarlo)",
+	"⚠ Breaking the unreadable lock %s with --force":                              "⚠ Rompiendo el bloqueo ilegible %s con --force",
+	"⚠ backup.encryption.fips restricts algorithms, but Go's FIPS 140-3 module is off; run stashr with GODEBUG=fips140=on": "⚠ backup.encryption.fips restringe los algoritmos, pero el módulo FIPS 140-3 de Go está desactivado; ejecute stashr con GODEBUG=fips140=on",
+	"  Last error: %s":                         "  Último error: %s",
+	"  Queued %s, copied from %s, %d attempts": "  En cola el %s, copiada de %s, %d intentos",
+	"  Queued the upload to %s: it is retried by 'stashr retry' and the daemon":            "  Subida a %s puesta en cola: la reintentarán 'stashr retry' y el demonio",
+	"Failed to read backup record: %v":                                                     "No se pudo leer el registro de la copia: %v",
+	"Failed to read the upload queue: %v":                                                  "No se pudo leer la cola de subidas: %v",
+	"Failed to record upload attempt: %v":                                                  "No se pudo registrar el intento de subida: %v",
+	"Not retrying queued uploads: another backup is running":                               "No se reintentan las subidas en cola: hay otra copia en curso",
+	"Retrying %d queued uploads...":                                                        "Reintentando %d subidas en cola...",
+	"Uploading %s...":                                                                      "Subiendo %s...",
+	"⚠ Failed to queue the upload to %s: %v":                                               "⚠ No se pudo poner en cola la subida a %s: %v",
+	"⚠ Not queuing the upload to %s: %v":                                                   "⚠ No se pone en cola la subida a %s: %v",
+	"⚠ Not queuing the upload to %s: no destination has the backup and it isn't encrypted": "⚠ No se pone en cola la subida a %s: ningún destino tiene la copia y no está cifrada",
+	"⚠ Uploaded %d of %d queued backups; the rest stay queued":                             "⚠ Subidas %d de %d copias en cola; el resto sigue en cola",
+	"✅ Uploaded %d queued backup(s)":                                                       "✅ %d copia(s) en cola subida(s)",
+	"✓ Dropped %d queued upload(s)":                                                        "✓ %d subida(s) en cola descartada(s)",
+	"✓ No uploads are waiting to be retried":                                               "✓ No hay subidas pendientes de reintento",
+	"🔁 Retry Failed Uploads":                                                               "🔁 Reintentar subidas fallidas",
+
+	// List
+	"📋 Backup List":                "📋 Lista de copias",
+	"Listing backups from %s...":   "Listando copias de %s...",
+	"✓ Found %d backup(s)":         "✓ %d copia(s) encontrada(s)",
+	"Total backups: %d":            "Total de copias: %d",
+	"Age":                          "Antigüedad",
+	"Modified":                     "Modificada",
+	"Name":                         "Nombre",
+	"Size":                         "Tamaño",
+	"Tags":                         "Etiquetas",
+	"⚠ Failed to list backups: %v": "⚠ No se pudieron listar las copias: %v",
+	"just now":                     "ahora mismo",
+	"1 minute ago":                 "hace 1 minuto",
+	"%d minutes ago":               "hace %d minutos",
+	"1 hour ago":                   "hace 1 hora",
+	"%d hours ago":                 "hace %d horas",
+	"1 day ago":                    "hace 1 día",
+	"%d days ago":                  "hace %d días",
+	"1 week ago":                   "hace 1 semana",
+	"%d weeks ago":                 "hace %d semanas",
+	"1 month ago":                  "hace 1 mes",
+	"%d months ago":                "hace %d meses",
+	"1 year ago":                   "hace 1 año",
+	"%d years ago":                 "hace %d años",
+	"  In %d of %d %s backup(s), from %s to %s":                            "  En %d de %d copia(s) de %s, de %s a %s",
+	"No backup could be searched":                                          "No se pudo buscar en ninguna copia",
+	"No items match %q in %d backup(s)":                                    "Ningún elemento coincide con %q en %d copia(s)",
+	"No storage destinations enabled":                                      "No hay destinos de almacenamiento habilitados",
+	"Search query is empty":                                                "La búsqueda está vacía",
+	"Searching %d backup(s), newest first...":                              "Buscando en %d copia(s), de la más reciente a la más antigua...",
+	"⚠ Passwords were shown in clear text; clear your terminal scrollback": "⚠ Se mostraron contraseñas en texto claro; borre el historial de su terminal",
+	"✓ %d item(s) match %q in %d backup(s)":                                "✓ %d elemento(s) coinciden con %q en %d copia(s)",
+	"🔎 Search Backups":                                                     "🔎 Buscar en las copias",
+	"  • %-20s (%d backup%s)":                                              "  • %-20s (%d copia%s)",
+	"Found %d backup(s) with tag '%s':":                                    "Se encontraron %d copia(s) con la etiqueta '%s':",
+	"Found %d unique tag(s):":                                              "Se encontraron %d etiqueta(s) distinta(s):",
+	"No backups found with tag: %s":                                        "No se encontraron copias con la etiqueta: %s",
+	"No note found for this backup":                                        "No hay ninguna nota para esta copia",
+	"No tags found":                                                        "No se encontraron etiquetas",
+	"Note:":                                                                "Nota:",
+	"Note: Only backups created after database feature was added are tracked.": "Nota: solo se registran las copias creadas después de añadir la base de datos.",
+	"Tags: %v": "Etiquetas: %v",
+	"You can run a new backup to start tracking.": "Puede hacer una nueva copia para empezar a registrarlas.",
+	"✓ Note added to backup: %s":                  "✓ Nota añadida a la copia: %s",
+	"✓ Tag '%s' added to backup: %s":              "✓ Etiqueta '%s' añadida a la copia: %s",
+	"✓ Tag '%s' removed from backup: %s":          "✓ Etiqueta '%s' quitada de la copia: %s",
+	"🏷️  Add Tag":                                 "🏷️  Añadir etiqueta",
+	"🏷️  All Tags":                                "🏷️  Todas las etiquetas",
+	"🏷️  List Backups by Tag":                     "🏷️  Listar copias por etiqueta",
+	"🏷️  Remove Tag":                              "🏷️  Quitar etiqueta",
+	"📝 Add Note":                                  "📝 Añadir nota",
+	"📝 Show Note":                                 "📝 Mostrar nota",
+	"  Mo Tu We Th Fr Sa Su":                      "  Lu Ma Mi Ju Vi Sá Do",
+	"%s is the oldest restore point shown for %s": "%s es el punto de restauración más antiguo que se muestra para %s",
+	"--months must be at least 1":                 "--months debe ser al menos 1",
+	"? unverified":                                "? sin verificar",
+	"Date":                                        "Fecha",
+	"File":                                        "Archivo",
+	"Items":                                       "Elementos",
+	"Legend: %s  %s  %s  %s":                      "Leyenda: %s  %s  %s  %s",
+	"No restore points in the last %d months":     "No hay puntos de restauración en los últimos %d meses",
+	"Run 'stashr backup' to create one":           "Ejecute 'stashr backup' para crear uno",
+	"Status":                                      "Estado",
+	"Use --interactive to restore, preview or diff a restore point":  "Use --interactive para restaurar, previsualizar o comparar un punto de restauración",
+	"[r]estore, [p]review or [d]iff with the previous restore point": "[r]estaurar, [p]revisualizar o ver [d]iferencias con el punto de restauración anterior",
+	"■ failed":                         "■ fallido",
+	"■ rehearsed":                      "■ ensayado",
+	"■ unverified":                     "■ sin verificar",
+	"■ verified":                       "■ verificado",
+	"⚠ Pick a number between 1 and %d": "⚠ Elija un número entre 1 y %d",
+	"⚠ Unknown action: %s":             "⚠ Acción desconocida: %s",
+	"⚠ Vault statistics were not recorded for both backups": "⚠ No se registraron estadísticas de la bóveda de ambas copias",
+	"✓ rehearsed":                         "✓ ensayado",
+	"✓ verified":                          "✓ verificado",
+	"✗ failed":                            "✗ fallido",
+	"🗓️  Restore Point Timeline":          "🗓️  Cronología de puntos de restauración",
+	"  Last restore drill passed %s (%s)": "  El último simulacro de restauración se superó %s (%s)",
+	"  ⚠ Last restore drill failed %s (%s): %s check failed":                                  "  ⚠ El último simulacro de restauración falló %s (%s): falló la comprobación %s",
+	"Invalid --warn-age %q: %v":                                                               "--warn-age no válido %q: %v",
+	"No password managers enabled":                                                            "No hay gestores de contraseñas habilitados",
+	"stashr: %d backup(s) were postponed by backup.conditions, most recently %s: %s":          "stashr: backup.conditions pospuso %d copia(s), la más reciente %s: %s",
+	"stashr: %s has never been backed up":                                                     "stashr: nunca se ha hecho copia de %s",
+	"stashr: %s was last backed up %s":                                                        "stashr: la última copia de %s se hizo %s",
+	"⏸ %d backup(s) were postponed by backup.conditions in the last %s, most recently %s: %s": "⏸ backup.conditions pospuso %d copia(s) en los últimos %s, la más reciente %s: %s",
+	"⚠ %d of %d managers haven't been backed up in %s: run 'stashr backup'":                   "⚠ %d de %d gestores no tienen copia desde hace %s: ejecute 'stashr backup'",
+	"✅ Every manager was backed up in the last %s":                                            "✅ Todos los gestores tienen copia de los últimos %s",
+	"✓ %s: last backed up %s (%s, %s)":                                                        "✓ %s: última copia %s (%s, %s)",
+	"✗ %s: last backed up %s (%s, %s)":                                                        "✗ %s: última copia %s (%s, %s)",
+	"✗ %s: never backed up":                                                                   "✗ %s: sin copias",
+	"📊 Backup Status":                                                                         "📊 Estado de las copias",
+	"  Cards: %d":                                                                             "  Tarjetas: %d",
+	"  Consider rotating these credentials":                                                   "  Considere renovar estas credenciales",
+	"  Identities: %d":                                                                        "  Identidades: %d",
+	"  Logins: %d":                                                                            "  Inicios de sesión: %d",
+	"  Oldest login last changed: %s":                                                         "  Último cambio del inicio de sesión más antiguo: %s",
+	"  Other: %d":                                                                             "  Otros: %d",
+	"  Secure notes: %d":                                                                      "  Notas seguras: %d",
+	"  Total items: %d":                                                                       "  Total de elementos: %d",
+	"  ⚠ %d of %d logins not changed in over %d years":                                        "  ⚠ %d de %d inicios de sesión sin cambios en más de %d años",
+	"  ✓ Every login changed within the last %d years":                                        "  ✓ Todos los inicios de sesión han cambiado en los últimos %d años",
+	"Backup not found in database: %s":                                                        "Copia no encontrada en la base de datos: %s",
+	"Backup: %s":                                                                              "Copia: %s",
+	"Classification: %s":                                                                      "Clasificación: %s",
+	"Contents:":                                                                               "Contenido:",
+	"Created: %s":                                                                             "Creada: %s",
+	"Credential age:":                                                                         "Antigüedad de las credenciales:",
+	"Failed to parse recorded statistics: %v":                                                 "No se pudieron interpretar las estadísticas registradas: %v",
+	"Items per category:":                                                                     "Elementos por categoría:",
+	"Items: %d":                                                                               "Elementos: %d",
+	"Manager: %s":                                                                             "Gestor: %s",
+	"No vault statistics recorded for this backup":                                            "No hay estadísticas de la bóveda registradas para esta copia",
+	"SHA-256 (%s): %s":                                                                        "SHA-256 (%s): %s",
+	"SHA-256: %s":                                                                             "SHA-256: %s",
+	"Size: %s":                                                                                "Tamaño: %s",
+	"Storage: %s":                                                                             "Almacenamiento: %s",
+	"Tags: %s":                                                                                "Etiquetas: %s",
+	"Verified: %s":                                                                            "Verificada: %s",
+	"ℹ️  Backup Info":                                                                         "ℹ️  Información de la copia",
+
+	// Emergency kit
+	"🚨 Emergency Access Kit Generator":                                    "🚨 Generador del kit de acceso de emergencia",
+	"Generating emergency access kit...":                                  "Generando el kit de acceso de emergencia...",
+	"✓ Emergency access kit generated: %s":                                "✓ Kit de acceso de emergencia generado: %s",
+	"EMERGENCY ACCESS KIT":                                                "KIT DE ACCESO DE EMERGENCIA",
+	"Generated: %s":                                                       "Generado: %s",
+	"WARNING: Keep this document secure!":                                 "AVISO: ¡Guarde este documento en un lugar seguro!",
+	"This document contains information about your backup configuration.": "Este documento contiene información sobre su configuración de copias.",
+	"Do not share with unauthorized persons.":                             "No lo comparta con personas no autorizadas.",
+	"1. Configuration Summary":                                            "1. Resumen de la configuración",
+	"2. Recent Backups":                                                   "2. Copias recientes",
+	"3. Emergency Restoration Guide":                                      "3. Guía de restauración de emergencia",
+	"4. Important Notes":                                                  "4. Notas importantes",
+	"No recent backups found in database.":                                "No hay copias recientes en la base de datos.",
+	"1. Ensure you have stashr CLI installed:":                            "1. Asegúrese de tener instalada la CLI de stashr:",
+	"   (or download from GitHub releases)":                               "   (o descárguela desde las versiones de GitHub)",
+	"2. Locate your backup files:":                                        "2. Localice sus archivos de copia:",
+	"   - Check local storage path (see section 1)":                       "   - Revise la ruta de almacenamiento local (ver sección 1)",
+	"   - Check USB drive if available":                                   "   - Revise la unidad USB si está disponible",
+	"   - Check Google Drive if configured":                               "   - Revise Google Drive si está configurado",
+	"3. List available backups:":                                          "3. Liste las copias disponibles:",
+	"4. Restore the backup you need:":                                     "4. Restaure la copia que necesite:",
+	"   (You will be prompted for encryption password)":                   "   (Se le pedirá la contraseña de cifrado)",
+	"5. Import restored data:":                                            "5. Importe los datos restaurados:",
+	"   For Bitwarden:":                                                   "   Para Bitwarden:",
+	"     - Open Bitwarden web vault or desktop app":                      "     - Abra la bóveda web o la aplicación de escritorio de Bitwarden",
+	"     - Go to Tools -> Import Data":                                   "     - Vaya a Herramientas -> Importar datos",
+	"     - Select 'Bitwarden (json)' format":                             "     - Seleccione el formato 'Bitwarden (json)'",
+	"     - Upload the decrypted JSON file":                               "     - Suba el archivo JSON descifrado",
+	"   For 1Password:":                                                   "   Para 1Password:",
+	"     - Use 1Password CLI to import":                                  "     - Use la CLI de 1Password para importar",
+	"     - Or contact 1Password support for assistance":                  "     - O pida ayuda al soporte de 1Password",
+	"6. Delete decrypted file after import:":                              "6. Elimine el archivo descifrado después de importarlo:",
+	"Encryption Password:":                                                "Contraseña de cifrado:",
+	"  - You MUST remember your encryption password":                      "  - DEBE recordar su contraseña de cifrado",
+	"  - It is NOT stored anywhere by stashr":                             "  - stashr NO la guarda en ningún sitio",
+	"  - Without it, backups cannot be decrypted":                         "  - Sin ella, las copias no se pueden descifrar",
+	"  - Consider storing it in a secure password manager":                "  - Considere guardarla en un gestor de contraseñas seguro",
+	"Google Drive Access:":                                                "Acceso a Google Drive:",
+	"  - Requires credentials file from Google Cloud Console":             "  - Requiere el archivo de credenciales de Google Cloud Console",
+	"  - Location: ":                                                      "  - Ubicación: ",
+	"  - You may need to re-authenticate":                                 "  - Puede que tenga que volver a autenticarse",
+	"USB Drive:":                                                          "Unidad USB:",
+	"  - Must be mounted at the configured path":                          "  - Debe estar montada en la ruta configurada",
+	"  - Backup directory: ":                                              "  - Directorio de copias: ",
+	"Security Recommendations:":                                           "Recomendaciones de seguridad:",
+	"  - Keep this document in a secure location":                         "  - Guarde este documento en un lugar seguro",
+	"  - Update it after significant configuration changes":               "  - Actualícelo tras cambios importantes de configuración",
+	"  - Test restoration periodically":                                   "  - Pruebe la restauración periódicamente",
+	"  - Maintain multiple backup destinations":                           "  - Mantenga varios destinos de copia",
+	"Getting Help:":                                                       "Cómo obtener ayuda:",
+	"Generated by stashr - Password Manager Backup Tool":                  "Generado por stashr - Herramienta de copias de gestores de contraseñas",
+	"Document ID: %s":                                                     "ID del documento: %s",
+	"Password Managers:":                                                  "Gestores de contraseñas:",
+	"  - Bitwarden: Enabled (Email: %s)":                                  "  - Bitwarden: Activado (Correo: %s)",
+	"  - 1Password: Enabled (Account: %s)":                                "  - 1Password: Activado (Cuenta: %s)",
+	"    Classification: %s":                                              "    Clasificación: %s",
+	"Storage Backends:":                                                   "Destinos de almacenamiento:",
+	"  - Local: %s":                                                       "  - Local: %s",
+	"  - USB: %s/%s":                                                      "  - USB: %s/%s",
+	"  - Google Drive: Enabled":                                           "  - Google Drive: Activado",
+	"Backup Settings:":                                                    "Ajustes de copia:",
+	"  - Encryption: %v (%s)":                                             "  - Cifrado: %v (%s)",
+	"  - Compression: %v":                                                 "  - Compresión: %v",
+	"  - Retention: Keep %s per manager":                                  "  - Retención: conservar %s por gestor",
+	"  - Retention on %s: Keep %s per manager":                            "  - Retención en %s: conservar %s por gestor",
+	"  - Compression dictionaries (needed to restore): %s":                "  - Diccionarios de compresión (necesarios para restaurar): %s",
+	"Backup %d:":            "Copia %d:",
+	"  File: %s":            "  Archivo: %s",
+	"  Manager: %s":         "  Gestor: %s",
+	"  Storage: %s":         "  Almacenamiento: %s",
+	"  Date: %s":            "  Fecha: %s",
+	"  - Azure Blob: %s/%s": "  - Azure Blob: %s/%s",
+	"  - Do not share with unauthorized persons":               "  - No lo comparta con personas no autorizadas",
+	"  - Google Cloud Storage: gs://%s/%s":                     "  - Google Cloud Storage: gs://%s/%s",
+	"  - OneDrive: %s":                                         "  - OneDrive: %s",
+	"  - Plugin %s: %s":                                        "  - Complemento %s: %s",
+	"  - S3: s3://%s/%s at %s":                                 "  - S3: s3://%s/%s en %s",
+	"  - Store this document in a secure location":             "  - Guarde este documento en un lugar seguro",
+	"  - Test your restoration process regularly":              "  - Pruebe su proceso de restauración con regularidad",
+	"  - Update periodically after configuration changes":      "  - Actualícelo periódicamente tras cambiar la configuración",
+	"  - WebDAV: %s/%s":                                        "  - WebDAV: %s/%s",
+	"  - git-annex: %s/%s":                                     "  - git-annex: %s/%s",
+	"  - iCloud Drive: %s":                                     "  - iCloud Drive: %s",
+	"  - rclone: %s":                                           "  - rclone: %s",
+	"Failed to generate PDF: %v":                               "No se pudo generar el PDF: %v",
+	"⚠️  IMPORTANT:":                                           "⚠️  IMPORTANTE:",
+	"Decrypt it with: stashr convert --input %s --to <format>": "Descífrela con: stashr convert --input %s --to <formato>",
+	"Document ID: %s (%d chunks)":                              "ID del documento: %s (%d fragmentos)",
+	"No paper backup chunks found (each starts with STASHR:)":  "No se encontraron fragmentos de copia en papel (cada uno empieza por STASHR:)",
+	"Rendering %d QR codes...":                                 "Generando %d códigos QR...",
+	"Specify either --input or --key":                          "Indique --input o --key",
+	"⚠ Differs from the checksum recorded for %s":              "⚠ No coincide con la suma de comprobación registrada para %s",
+	"⚠️  Anyone with this printout and a backup can read your vault. Store it apart from your backups.": "⚠️  Cualquiera con esta impresión y una copia puede leer su bóveda. Guárdela separada de sus copias.",
+	"⚠️  Showing the encryption password":                                          "⚠️  Mostrando la contraseña de cifrado",
+	"✓ Backup written to %s (%s)":                                                  "✓ Copia escrita en %s (%s)",
+	"✓ Encryption password written to %s":                                          "✓ Contraseña de cifrado escrita en %s",
+	"✓ Matches the checksum recorded when %s was made":                             "✓ Coincide con la suma de comprobación registrada al crear %s",
+	"✓ Paper backup generated: %s":                                                 "✓ Copia en papel generada: %s",
+	"✓ Reassembled paper backup %s":                                                "✓ Copia en papel %s reconstruida",
+	"💡 Print it, then delete the PDF; test the printout with: stashr import-paper": "💡 Imprímala y elimine el PDF; pruebe la impresión con: stashr import-paper",
+	"🖨️  Paper Backup Export":                                                      "🖨️  Exportar copia en papel",
+	"🖨️  Paper Backup Import":                                                      "🖨️  Importar copia en papel",
+	"STASHR PAPER BACKUP":                                                          "COPIA EN PAPEL DE STASHR",
+	"STASHR PAPER KEY":                                                             "CLAVE EN PAPEL DE STASHR",
+	"Spaces and line breaks don't matter; each chunk starts with STASHR and has its own checksum.": "Los espacios y saltos de línea no importan; cada fragmento empieza por STASHR y tiene su propia suma de comprobación.",
+	"Text copy":                             "Copia en texto",
+	"stashr paper backup %s - page %d":      "copia en papel de stashr %s - página %d",
+	"Backups go to at least 2 destinations": "Las copias van a al menos 2 destinos",
+	"Emergency kit generated":               "Kit de emergencia generado",
+	"Enable a second destination with 'stashr init' or in ~/.stashr/config.yaml":                     "Habilite un segundo destino con 'stashr init' o en ~/.stashr/config.yaml",
+	"Encryption is enabled for every destination":                                                    "El cifrado está habilitado en todos los destinos",
+	"Encryption password is stored somewhere safe":                                                   "La contraseña de cifrado está guardada en un lugar seguro",
+	"Is your encryption password stored somewhere safe, outside this computer?":                      "¿Está su contraseña de cifrado guardada en un lugar seguro, fuera de este equipo?",
+	"Keep it outside this computer (e.g. written down in a safe, or in a separate password manager)": "Guárdela fuera de este equipo (p. ej. anotada en una caja fuerte o en otro gestor de contraseñas)",
+	"Run 'stashr emergency-kit' and keep the PDF with your password":                                 "Ejecute 'stashr emergency-kit' y guarde el PDF junto con su contraseña",
+	"Run 'stashr rehearse' or 'stashr restore --latest' to prove a backup can be restored":           "Ejecute 'stashr rehearse' o 'stashr restore --latest' para demostrar que una copia se puede restaurar",
+	"Set backup.encryption.enabled: true":                                                            "Defina backup.encryption.enabled: true",
+	"Test restore performed":                                                                         "Restauración de prueba realizada",
+	"⚠ %d of %d safety items are incomplete. Run 'stashr checklist' again once fixed":                "⚠ %d de %d puntos de seguridad están incompletos. Ejecute 'stashr checklist' de nuevo cuando los resuelva",
+	"✅ Safety Checklist":                                               "✅ Lista de seguridad",
+	"🎉 All %d safety items are complete":                               "🎉 Los %d puntos de seguridad están completos",
+	"💡 %d safety checklist item(s) incomplete. Run 'stashr checklist'": "💡 %d punto(s) de la lista de seguridad incompleto(s). Ejecute 'stashr checklist'",
+
+	// Setup
+	"  1. Ensure your password manager CLI is authenticated":                        "  1. Asegúrese de que la CLI de su gestor de contraseñas esté autenticada",
+	"  2. If using Google Drive, run a test backup to complete OAuth2 flow":         "  2. Si usa Google Drive, haga una copia de prueba para completar el flujo OAuth2",
+	"  3. Run 'stashr backup' to create your first backup":                          "  3. Ejecute 'stashr backup' para crear su primera copia",
+	"1Password CLI detected":                                                        "CLI de 1Password detectada",
+	"1Password CLI not found":                                                       "CLI de 1Password no encontrada",
+	"1Password account (e.g., my.1password.com, optional)":                          "Cuenta de 1Password (p. ej., my.1password.com, opcional)",
+	"A service account key signs in without a browser (for headless servers).":      "Una clave de cuenta de servicio inicia sesión sin navegador (para servidores sin pantalla).",
+	"Backup directory name (default: stashr)":                                       "Nombre de la carpeta de copias (predeterminado: stashr)",
+	"Backups will NOT be encrypted!":                                                "¡Las copias NO se cifrarán!",
+	"Bitwarden CLI detected":                                                        "CLI de Bitwarden detectada",
+	"Bitwarden CLI not found":                                                       "CLI de Bitwarden no encontrada",
+	"Bitwarden email (optional)":                                                    "Correo de Bitwarden (opcional)",
+	"Configuration file already exists at: %s":                                      "Ya existe un archivo de configuración en: %s",
+	"Configuration is valid":                                                        "La configuración es válida",
+	"Configuration saved to: %s":                                                    "Configuración guardada en: %s",
+	"Configuring backup settings...":                                                "Configurando las opciones de copia...",
+	"Configuring storage backends...":                                               "Configurando los destinos de almacenamiento...",
+	"Detecting password managers...":                                                "Detectando gestores de contraseñas...",
+	"Enable 1Password backups?":                                                     "¿Habilitar las copias de 1Password?",
+	"Enable Bitwarden backups?":                                                     "¿Habilitar las copias de Bitwarden?",
+	"Enable Google Drive storage?":                                                  "¿Habilitar el almacenamiento en Google Drive?",
+	"Enable OneDrive storage?":                                                      "¿Habilitar el almacenamiento en OneDrive?",
+	"Enable USB storage?":                                                           "¿Habilitar el almacenamiento USB?",
+	"Enable compression?":                                                           "¿Habilitar la compresión?",
+	"Enable encryption? (recommended)":                                              "¿Habilitar el cifrado? (recomendado)",
+	"Enable local storage? (recommended as fallback)":                               "¿Habilitar el almacenamiento local? (recomendado como respaldo)",
+	"Google Drive folder ID (optional)":                                             "ID de la carpeta de Google Drive (opcional)",
+	"Google Drive requires OAuth2 credentials or a service account key.":            "Google Drive necesita credenciales OAuth2 o una clave de cuenta de servicio.",
+	"Install from: https://bitwarden.com/help/cli/":                                 "Instálela desde: https://bitwarden.com/help/cli/",
+	"Install from: https://developer.1password.com/docs/cli/":                       "Instálela desde: https://developer.1password.com/docs/cli/",
+	"Leave empty to store backups in root directory.":                               "Déjelo vacío para guardar las copias en la carpeta raíz.",
+	"Local storage serves as a reliable fallback when cloud/USB is unavailable":     "El almacenamiento local sirve de respaldo fiable cuando la nube o el USB no están disponibles",
+	"Number of backups to keep (default: 10)":                                       "Número de copias que conservar (predeterminado: 10)",
+	"OneDrive application (client) ID":                                              "ID de aplicación (cliente) de OneDrive",
+	"OneDrive backup folder (default: stashr)":                                      "Carpeta de copias de OneDrive (predeterminado: stashr)",
+	"OneDrive requires an Azure app registration with public client flows enabled.": "OneDrive necesita un registro de aplicación de Azure con los flujos de cliente público habilitados.",
+	"Path to Google Drive credentials JSON file":                                    "Ruta del archivo JSON de credenciales de Google Drive",
+	"Register one and copy its Application (client) ID from:":                       "Registre una y copie su ID de aplicación (cliente) desde:",
+	"Setup cancelled":                                                               "Configuración cancelada",
+	"Shared Drive ID (optional)":                                                    "ID de la unidad compartida (opcional)",
+	"To store backups in a Shared Drive, enter its ID (the last part of its URL).":  "Para guardar las copias en una unidad compartida, introduzca su ID (la última parte de su URL).",
+	"USB mount path (e.g., /media/backup)":                                          "Ruta de montaje del USB (p. ej., /media/backup)",
+	"USB volume label to find the drive by, wherever it is mounted (leave empty to use a fixed mount path)": "Etiqueta de volumen USB con la que encontrar la unidad, esté donde esté montada (déjela vacía para usar una ruta de montaje fija)",
+	"Validating configuration...":                                                          "Validando la configuración...",
+	"You can create a dedicated backup folder in Google Drive.":                            "Puede crear una carpeta dedicada a las copias en Google Drive.",
+	"You'll be asked to sign in with a device code on the first backup.":                   "En la primera copia se le pedirá que inicie sesión con un código de dispositivo.",
+	"You'll need to create a project and download credentials from:":                       "Tendrá que crear un proyecto y descargar las credenciales desde:",
+	"https://console.cloud.google.com/apis/credentials":                                    "https://console.cloud.google.com/apis/credentials",
+	"https://entra.microsoft.com/#view/Microsoft_AAD_RegisteredApps/ApplicationsListBlade": "https://entra.microsoft.com/#view/Microsoft_AAD_RegisteredApps/ApplicationsListBlade",
+	"🔐 Password Manager Backup Tool - Setup":                                               "🔐 Herramienta de copias de gestores de contraseñas - Configuración",
+	"  (USB drives may not always be connected)":                                           "  (puede que las unidades USB no estén siempre conectadas)",
+	"  Password Managers: %d/%d ready":                                                     "  Gestores de contraseñas: %d/%d listos",
+	"  Storage Backends: %d/%d available":                                                  "  Destinos de almacenamiento: %d/%d disponibles",
+	"  ⚠ Authentication check failed: %v":                                                  "  ⚠ Falló la comprobación de autenticación: %v",
+	"  ⚠ Not authenticated":                                                                "  ⚠ No autenticado",
+	"%d setting(s) differ":                                                                 "%d ajuste(s) difieren",
+	"Comparing %s with %s":                                                                 "Comparando %s con %s",
+	"Configuration file: %s":                                                               "Archivo de configuración: %s",
+	"Configuration validation failed: %v":                                                  "Falló la validación de la configuración: %v",
+	"Please authenticate your password manager CLIs before backing up":                     "Autentique las CLI de sus gestores de contraseñas antes de hacer copias",
+	"Please configure at least one storage backend":                                        "Configure al menos un destino de almacenamiento",
+	"Review the messages above for details":                                                "Revise los mensajes anteriores para más detalles",
+	"Summary:":                                                                             "Resumen:",
+	"Testing password managers...":                                                         "Probando los gestores de contraseñas...",
+	"Testing storage backends...":                                                          "Probando los destinos de almacenamiento...",
+	"Validating configuration file...":                                                     "Validando el archivo de configuración...",
+	"⚙️  Configuration":                                                                    "⚙️  Configuración",
+	"⚙️  Configuration Differences":                                                        "⚙️  Diferencias de configuración",
+	"⚠ %d risky change(s) in %s":                                                           "⚠ %d cambio(s) arriesgado(s) en %s",
+	"⚠ Some systems are not ready":                                                         "⚠ Algunos sistemas no están listos",
+	"⚠ USB: %v":                                                                            "⚠ USB: %v",
+	"⚠ USB: Not available":                                                                 "⚠ USB: no disponible",
+	"✅ All systems ready!":                                                                 "✅ ¡Todos los sistemas listos!",
+	"✓ %s (%s): Available":                                                                 "✓ %s (%s): disponible",
+	"✓ 1Password: CLI found":                                                               "✓ 1Password: CLI encontrada",
+	"✓ Azure Blob: Available in container %s":                                              "✓ Azure Blob: disponible en el contenedor %s",
+	"✓ Bitwarden: CLI found":                                                               "✓ Bitwarden: CLI encontrada",
+	"✓ Configuration Validation":                                                           "✓ Validación de la configuración",
+	"✓ Configuration is valid":                                                             "✓ La configuración es válida",
+	"✓ Google Cloud Storage: Available at gs://%s":                                         "✓ Google Cloud Storage: disponible en gs://%s",
+	"✓ Google Drive: Available":                                                            "✓ Google Drive: disponible",
+	"✓ Local: Available at %s":                                                             "✓ Local: disponible en %s",
+	"✓ No differences":                                                                     "✓ Sin diferencias",
+	"✓ OneDrive: Available":                                                                "✓ OneDrive: disponible",
+	"✓ Plugin %s: Available (%s)":                                                          "✓ Complemento %s: disponible (%s)",
+	"✓ S3: Available at s3://%s":                                                           "✓ S3: disponible en s3://%s",
+	"✓ USB: Available at %s":                                                               "✓ USB: disponible en %s",
+	"✓ WebDAV: Available at %s":                                                            "✓ WebDAV: disponible en %s",
+	"✓ git-annex: Available at %s":                                                         "✓ git-annex: disponible en %s",
+	"✓ iCloud Drive: Available at %s":                                                      "✓ iCloud Drive: disponible en %s",
+	"✓ rclone: Available at %s":                                                            "✓ rclone: disponible en %s",
+	"✗ %s (%s): Not available":                                                             "✗ %s (%s): no disponible",
+	"✗ 1Password: CLI not found at %s":                                                     "✗ 1Password: CLI no encontrada en %s",
+	"✗ Azure Blob: %v":                                                                     "✗ Azure Blob: %v",
+	"✗ Azure Blob: Not available":                                                          "✗ Azure Blob: no disponible",
+	"✗ Bitwarden: CLI not found at %s":                                                     "✗ Bitwarden: CLI no encontrada en %s",
+	"✗ Google Cloud Storage: %v":                                                           "✗ Google Cloud Storage: %v",
+	"✗ Google Cloud Storage: Not available":                                                "✗ Google Cloud Storage: no disponible",
+	"✗ Google Drive: %v":                                                                   "✗ Google Drive: %v",
+	"✗ Google Drive: Not available":                                                        "✗ Google Drive: no disponible",
+	"✗ Local: %v":                                                                          "✗ Local: %v",
+	"✗ Local: Not available":                                                               "✗ Local: no disponible",
+	"✗ OneDrive: %v":                                                                       "✗ OneDrive: %v",
+	"✗ OneDrive: Not available":                                                            "✗ OneDrive: no disponible",
+	"✗ Plugin %s: %v":                                                                      "✗ Complemento %s: %v",
+	"✗ Plugin %s: Not available":                                                           "✗ Complemento %s: no disponible",
+	"✗ S3: Not available":                                                                  "✗ S3: no disponible",
+	"✗ WebDAV: %v":                                                                         "✗ WebDAV: %v",
+	"✗ WebDAV: Not available":                                                              "✗ WebDAV: no disponible",
+	"✗ git-annex: %v":                                                                      "✗ git-annex: %v",
+	"✗ git-annex: Not available":                                                           "✗ git-annex: no disponible",
+	"✗ iCloud Drive: %v":                                                                   "✗ iCloud Drive: %v",
+	"✗ iCloud Drive: Not available":                                                        "✗ iCloud Drive: no disponible",
+	"✗ rclone: %v":                                                                         "✗ rclone: %v",
+	"✗ rclone: Not available":                                                              "✗ rclone: no disponible",
+	"❌ No password managers are ready":                                                     "❌ Ningún gestor de contraseñas está listo",
+	"❌ No storage backends are available":                                                  "❌ No hay ningún destino de almacenamiento disponible",
+	"  %s (since %s): use %s":                                                              "  %s (desde %s): use %s",
+	"API %s is not supported by this stashr (API %s)":                                      "Este stashr no admite la API %s (API %s)",
+	"Deprecated APIs:":                                                                     "API obsoletas:",
+	"Deprecated APIs: none":                                                                "API obsoletas: ninguna",
+	"Library API: %s (github.com/harshalranjhani/stashr/pkg/stashr/v1)":                    "API de la biblioteca: %s (github.com/harshalranjhani/stashr/pkg/stashr/v1)",
+	"Plugin protocol: %d":                                                                  "Protocolo de complementos: %d",
+	"stashr: %s":                                                                           "stashr: %s",
+	"🧩 API Version":                                                                        "🧩 Versión de la API",
+
+	// Storage
+	"⚠ %s failed (attempt %d), retrying in %s: %v": "⚠ %s falló (intento %d), se reintentará en %s: %v",
+	"⚠ Ignoring proxy: %v":                         "⚠ Se ignora el proxy: %v",
+	"Destinations ordered by health: %s":           "Destinos ordenados por estado: %s",
+	"--size-kb must be at least 1":                 "--size-kb debe ser al menos 1",
+	"Benchmarking %s...":                           "Midiendo %s...",
+	"DESTINATION":                                  "DESTINO",
+	"FAIL":                                         "FALLO",
+	"OK":                                           "OK",
+	"STATUS":                                       "ESTADO",
+	"Test object: %s of random data":               "Objeto de prueba: %s de datos aleatorios",
+	"failed":                                       "falló",
+	"⏱️  Storage Benchmark":                        "⏱️  Prueba de rendimiento del almacenamiento",
+	"⚠ %d of %d destinations passed":               "⚠ Superaron la prueba %d de %d destinos",
+	"✅ All %d destinations passed":                 "✅ Los %d destinos superaron la prueba",
+	"✗ %-8s failed after %s: %v":                   "✗ %-8s falló tras %s: %v",
+	"Delete cached %s from %s (%s)":                "Eliminar %s en caché de %s (%s)",
+	"Directory: %s":                                "Carpeta: %s",
+	"FILENAME":                                     "ARCHIVO",
+	"LAST USED":                                    "ÚLTIMO USO",
+	"SIZE":                                         "TAMAÑO",
+	"STORAGE":                                      "ALMACÉN",
+	"Status: disabled (cache.enabled: false)": "Estado: deshabilitada (cache.enabled: false)",
+	"Status: enabled":                         "Estado: habilitada",
+	"Usage: %s of %s (%d backups)":            "Uso: %s de %s (%d copias)",
+	"✓ Cleared %s from %s":                    "✓ Liberados %s de %s",
+	"🗄️  Clear Download Cache":                "🗄️  Vaciar la caché de descargas",
+	"🗄️  Download Cache":                      "🗄️  Caché de descargas",
+	"  Neither a majority of copies nor the recorded checksum tells which is right; compare them by hand": "  Ni la mayoría de las copias ni la suma de comprobación registrada indican cuál es la correcta; compárelas a mano",
+	"Comparing checksums...":                                                      "Comparando las sumas de comprobación...",
+	"Copy %s (%s) from %s to %s":                                                  "Copiar %s (%s) de %s a %s",
+	"Kept the differing copies":                                                   "Se conservaron las copias que difieren",
+	"Mirror verification needs at least two available destinations":               "La verificación de réplicas necesita al menos dos destinos disponibles",
+	"Nothing can be fixed automatically":                                          "No se puede corregir nada automáticamente",
+	"Replace %s on %s with the copy on %s":                                        "Reemplazar %s en %s por la copia de %s",
+	"Run with --fix to copy the missing backups and replace the differing copies": "Ejecute con --fix para copiar las copias que faltan y reemplazar las que difieren",
+	"⚠ %d backups exist on %s but are missing from %s":                            "⚠ %d copias existen en %s pero faltan en %s",
+	"⚠ %d missing copies, %d backups whose copies differ":                         "⚠ %d copias que faltan, %d copias cuyas réplicas difieren",
+	"⚠ %s on %s: %v":                                                              "⚠ %s en %s: %v",
+	"⚠ %s: failed to list backups: %v":                                            "⚠ %s: no se pudieron listar las copias: %v",
+	"⚠ %s: not available, skipped":                                                "⚠ %s: no disponible, se omite",
+	"⚠ %s: not copied, its copies differ and none is known to be right":           "⚠ %s: no se copia, sus réplicas difieren y no se sabe cuál es la correcta",
+	"⚠ Copied %d and replaced %d backups; %d failed":                              "⚠ %d copias copiadas y %d reemplazadas; %d fallaron",
+	"⚠ The copies of %s differ:":                                                  "⚠ Las réplicas de %s difieren:",
+	"✅ All %d destinations mirror each other (%d backups)":                        "✅ Los %d destinos son réplicas entre sí (%d copias)",
+	"✅ Copied %d and replaced %d backups":                                         "✅ %d copias copiadas y %d reemplazadas",
+	"✗ Failed to delete the differing %s from %s: %v":                             "✗ No se pudo eliminar la réplica distinta de %s en %s: %v",
+	"📁 %s: %d backups":                                                            "📁 %s: %d copias",
+	"🪞 Mirror Verification":                                                       "🪞 Verificación de réplicas",
+	"  %d to copy, %d only on %s":                                                 "  %d por copiar, %d solo en %s",
+	"  ❔ %s: not a recognized backup name, not deleted":                           "  ❔ %s: no es un nombre de copia reconocido, no se elimina",
+	"--from and --to must be different destinations":                              "--from y --to deben ser destinos distintos",
+	"Copying %s...":                                             "Copiando %s...",
+	"Delete %s from %s (not on %s)":                             "Eliminar %s de %s (no está en %s)",
+	"Kept the backups only on %s":                               "Se conservaron las copias que solo están en %s",
+	"⚠ %s: failed to copy signature: %v":                        "⚠ %s: no se pudo copiar la firma: %v",
+	"⚠ Copied %d and deleted %d backups; %d failed, %d skipped": "⚠ %d copias copiadas y %d eliminadas; %d fallaron, %d omitidas",
+	"✅ Copied %d and deleted %d backups":                        "✅ %d copias copiadas y %d eliminadas",
+	"✅ Copied %d and deleted %d backups; %d skipped":            "✅ %d copias copiadas y %d eliminadas; %d omitidas",
+	"✓ %s already has every backup on %s":                       "✓ %s ya tiene todas las copias de %s",
+	"✓ Copied %s (%s)":                                          "✓ %s copiado (%s)",
+	"✓ Deleted %s":                                              "✓ %s eliminado",
+	"✗ Failed to delete %s: %v":                                 "✗ No se pudo eliminar %s: %v",
+	"🔁 Sync Backups":                                            "🔁 Sincronizar copias",
+	"  Moved to the trash for %d days":                          "  Se mueven a la papelera durante %d días",
+	"  ⏳ %s: kept until %s":                                     "  ⏳ %s: se conserva hasta %s",
+	"  ❔ %s: not a recognized backup name, left alone":          "  ❔ %s: no es un nombre de copia reconocido, no se toca",
+	"  🔒 %s: locked until %s":                                   "  🔒 %s: bloqueado hasta %s",
+	"Permanently delete %s from the trash of %s (trashed %s)":   "Eliminar para siempre %s de la papelera de %s (enviado a la papelera %s)",
+	"Prune cancelled":                                           "Limpieza cancelada",
+	"⚠ Deleted %d of %d trashed backups":                        "⚠ Eliminadas %d de %d copias de la papelera",
+	"⚠ Pruned %d of %d backups":                                 "⚠ Limpiadas %d de %d copias",
+	"✅ Deleted %d trashed backups":                              "✅ %d copias de la papelera eliminadas",
+	"✅ Pruned %d backups":                                       "✅ %d copias limpiadas",
+	"✓ Deleted %d backups from %s":                              "✓ %d copias eliminadas de %s",
+	"✓ Deleted %d trashed backups from %s":                      "✓ %d copias eliminadas de la papelera de %s",
+	"✓ Moved %d backups to the trash of %s":                     "✓ %d copias movidas a la papelera de %s",
+	"✓ No trashed backups past their grace period":              "✓ No hay copias en la papelera con el periodo de gracia vencido",
+	"✓ Nothing to prune":                                        "✓ Nada que limpiar",
+	"✗ Failed to delete %s from %s: %v":                         "✗ No se pudo eliminar %s de %s: %v",
+	"✗ Failed to delete %s from the trash of %s: %v":            "✗ No se pudo eliminar %s de la papelera de %s: %v",
+	"📁 %s (keeping %s per manager): %d backups, %d to delete":   "📁 %s (se conservan %s por gestor): %d copias, %d por eliminar",
+	"📁 %s: %d trashed backups":                                  "📁 %s: %d copias en la papelera",
+	"🧹 Prune Old Backups":                                       "🧹 Limpiar copias antiguas",
+	"BACKUP":                                                    "COPIA",
+	"Checked downloads, headers and checksums; add --decrypt to also check that the vault data decrypts and is complete": "Se comprobaron las descargas, las cabeceras y las sumas de comprobación; añada --decrypt para comprobar también que los datos de la bóveda se descifran y están completos",
+	"No backups to verify": "No hay copias que verificar",
+	"unreachable":          "inaccesible",
+	"⚠ %d of %d destinations couldn't be checked":                                   "⚠ No se pudieron comprobar %d de %d destinos",
+	"⛔ %d of %d stored copies failed verification":                                  "⛔ %d de %d copias almacenadas no superaron la verificación",
+	"⛔ No destination could be checked":                                             "⛔ No se pudo comprobar ningún destino",
+	"✅ All %d stored copies of %d backups passed":                                   "✅ Las %d copias almacenadas de %d copias de seguridad superaron la verificación",
+	"✓ %s on %s: %s":                                                                "✓ %s en %s: %s",
+	"✗ %s on %s: %s check failed: %v":                                               "✗ %s en %s: falló la comprobación de %s: %v",
+	"  %s: %d files (%s)":                                                           "  %s: %d archivos (%s)",
+	"  %s: nothing to wipe":                                                         "  %s: nada que borrar",
+	"  Cached encryption password in the OS keychain":                               "  Contraseña de cifrado guardada en el llavero del sistema",
+	"Delete the cached encryption password from the OS keychain":                    "Eliminar la contraseña de cifrado guardada en el llavero del sistema",
+	"Overwrite and delete %s":                                                       "Sobrescribir y eliminar %s",
+	"Specify --local: only data on this machine can be wiped, never remote backups": "Indique --local: solo se pueden borrar los datos de este equipo, nunca las copias remotas",
+	"Type %q to continue: ":                                                         "Escriba %q para continuar: ",
+	"Wipe cancelled":                                                                "Borrado cancelado",
+	"⚠ OS keychain not available: %v":                                               "⚠ Llavero del sistema no disponible: %v",
+	"⚠ Wipe finished with %d errors":                                                "⚠ El borrado terminó con %d errores",
+	"⚠️  This permanently deletes the data above. Your backups are not affected.":   "⚠️  Esto elimina para siempre los datos anteriores. Sus copias de seguridad no se ven afectadas.",
+	"✅ Local data wiped":                                                            "✅ Datos locales borrados",
+	"✓ Deleted the cached encryption password from the OS keychain":                 "✓ Contraseña de cifrado eliminada del llavero del sistema",
+	"✓ Nothing to wipe":                                                             "✓ Nada que borrar",
+	"✓ Wiped %s":                                                                    "✓ Borrado: %s",
+	"✗ Failed to delete the cached encryption password: %v":                         "✗ No se pudo eliminar la contraseña de cifrado guardada: %v",
+	"🧽 Wipe Local Data":                                                             "🧽 Borrar datos locales",
+	"Download cache":                                                                "Caché de descargas",
+	"Unencrypted exports and staging files":                                         "Exportaciones sin cifrar y archivos temporales",
+	"Compression dictionary samples":                                                "Muestras del diccionario de compresión",
+	"Spooled backups waiting for 'stashr retry'":                                    "Copias en cola a la espera de 'stashr retry'",
+
+	// Encryption
+	"%s has no %s extension; choose where to write it with --output": "%s no tiene la extensión %s; elija dónde escribirlo con --output",
+	"%s is not encrypted":                            "%s no está cifrado",
+	"Decrypting...":                                  "Descifrando...",
+	"Encrypting with key file %s (key %s)...":        "Cifrando con el archivo de clave %s (clave %s)...",
+	"Encrypting...":                                  "Cifrando...",
+	"Encryption failed: %v":                          "Error al cifrar: %v",
+	"⚠ %s is already encrypted; encrypting it again": "⚠ %s ya está cifrado; se cifrará de nuevo",
+	"⚠ The password from the OS keychain didn't decrypt this file": "⚠ La contraseña del llavero del sistema no descifró este archivo",
+	"⚠️  If you forget this password, the file can't be decrypted": "⚠️  Si olvida esta contraseña, el archivo no se podrá descifrar",
+	"✓ Decrypted to %s":      "✓ Descifrado en %s",
+	"✓ Encrypted to %s (%s)": "✓ Cifrado en %s (%s)",
+	"💡 The original is unchanged; delete it once you've checked the encrypted copy decrypts": "💡 El original no se ha modificado; elimínelo cuando haya comprobado que la copia cifrada se descifra",
+	"🔒 Encrypt File": "🔒 Cifrar archivo",
+	"🔓 Decrypt File": "🔓 Descifrar archivo",
+	"   or set backup.encryption.key_file in your configuration":                "   o defina backup.encryption.key_file en su configuración",
+	"%s already exists; backups encrypted with it can't be restored without it": "%s ya existe; las copias cifradas con él no se pueden restaurar sin él",
+	"Confirm key file password: ":                                               "Confirme la contraseña del archivo de clave: ",
+	"Decrypting backup with key file %s...":                                     "Descifrando la copia con el archivo de clave %s...",
+	"Enter key file password: ":                                                 "Introduzca la contraseña del archivo de clave: ",
+	"Key ID:    %s":                                                             "ID de clave: %s",
+	"Key ID: %s":                                                                "ID de clave: %s",
+	"Key file password is required":                                             "La contraseña del archivo de clave es obligatoria",
+	"Move it somewhere safe first, or choose another path":                      "Muévalo antes a un lugar seguro, o elija otra ruta",
+	"Path:      %s":                                                             "Ruta:        %s",
+	"Protected: %v":                                                             "Protegido:   %v",
+	"⚠️  Backups encrypted with this key file can't be restored without it. Keep a copy apart from your backups.": "⚠️  Las copias cifradas con este archivo de clave no se pueden restaurar sin él. Guarde una copia aparte de sus copias de seguridad.",
+	"✓ Key file created: %s":                              "✓ Archivo de clave creado: %s",
+	"💡 Use it with: stashr backup --encryption-key %s":    "💡 Úselo con: stashr backup --encryption-key %s",
+	"🔑 Generate Key File":                                 "🔑 Generar archivo de clave",
+	"🔑 Key File":                                          "🔑 Archivo de clave",
+	"Delete the encryption password from the OS keychain": "Eliminar la contraseña de cifrado del llavero del sistema",
+	"Encryption password: not stored":                     "Contraseña de cifrado: no guardada",
+	"Encryption password: stored":                         "Contraseña de cifrado: guardada",
+	"OS keychain not available: %v":                       "El llavero del sistema no está disponible: %v",
+	"The OS keychain did not return the stored password; is the keyring unlocked?": "El llavero del sistema no devolvió la contraseña guardada; ¿está desbloqueado?",
+	"Used by backups:     %v (backup.encryption.keychain)":                         "Usada por las copias:   %v (backup.encryption.keychain)",
+	"✓ Deleted the encryption password from the OS keychain":                       "✓ Contraseña de cifrado eliminada del llavero del sistema",
+	"✓ Encryption password stored in the OS keychain":                              "✓ Contraseña de cifrado guardada en el llavero del sistema",
+	"💡 Set backup.encryption.keychain: true in your configuration to use it":       "💡 Defina backup.encryption.keychain: true en su configuración para usarla",
+	"🔐 Delete Encryption Password":                                                 "🔐 Eliminar la contraseña de cifrado",
+	"🔐 Keychain":                                                                   "🔐 Llavero",
+	"🔐 Store Encryption Password":                                                  "🔐 Guardar la contraseña de cifrado",
+	"Decrypting backup with %s...":                                                 "Descifrando la copia con %s...",
+	"Decrypting backup with gpg...":                                                "Descifrando la copia con gpg...",
+	"gpg may ask for your key's passphrase or your smartcard's PIN":                "gpg puede pedirle la frase de contraseña de su clave o el PIN de su tarjeta inteligente",
+	"  Dictionary %s doesn't beat %s for this export; using %s":                    "  El diccionario %s no mejora a %s en esta exportación; se usa %s",
+	"  No compression dictionary for %s yet; one is trained after this backup":     "  Aún no hay diccionario de compresión para %s; se entrenará uno tras esta copia",
+	"  Trained compression dictionary %s on %d past exports":                       "  Diccionario de compresión %s entrenado con %d exportaciones anteriores",
+	"Compression dictionary %s is not in %s, searching the destinations...":        "El diccionario de compresión %s no está en %s, buscando en los destinos...",
+	"Failed to read compression dictionary state: %v":                              "No se pudo leer el estado del diccionario de compresión: %v",
+	"Failed to record compression dictionary copy: %v":                             "No se pudo registrar la copia del diccionario de compresión: %v",
+	"Failed to record compression dictionary: %v":                                  "No se pudo registrar el diccionario de compresión: %v",
+	"Failed to save compression dictionary: %v":                                    "No se pudo guardar el diccionario de compresión: %v",
+	"Invalid compression dictionary ID %q":                                         "ID de diccionario de compresión no válido: %q",
+	"Skipping compression dictionary training: %v":                                 "Se omite el entrenamiento del diccionario de compresión: %v",
+	"⚠ Failed to copy compression dictionary %s to %s: %v":                         "⚠ No se pudo copiar el diccionario de compresión %s a %s: %v",
+	"✓ Compressed with dictionary %s (%s → %s)":                                    "✓ Comprimido con el diccionario %s (%s → %s)",
+	"✓ Loaded compression dictionary %s from %s":                                   "✓ Diccionario de compresión %s cargado desde %s",
+
+	// Identity and proofs
+	"  stashr identity show --webhook-secret": "  stashr identity show --webhook-secret",
+	"Cancelled":       "Cancelado",
+	"Created:     %s": "Creada:           %s",
+	"Delete %s":       "Eliminar %s",
+	"Delete the wrapping key of %s from the OS keychain":                                   "Eliminar la clave de envoltura de %s del llavero del sistema",
+	"Delete this installation's identity? Webhook notifications will no longer be signed.": "¿Eliminar la identidad de esta instalación? Las notificaciones webhook dejarán de firmarse.",
+	"Fingerprint: %s": "Huella:           %s",
+	"Hostname:    %s": "Nombre de host:   %s",
+	"ID:          %s": "ID:               %s",
+	"Key storage: %s": "Almacén de clave: %s",
+	"Public key:  %s": "Clave pública:    %s",
+	"Use --no-keychain to store the key in a file instead":                               "Use --no-keychain para guardar la clave en un archivo",
+	"Webhook notifications are now signed. Give receivers the secret from:":              "Las notificaciones webhook ahora se firman. Dé a los receptores el secreto que muestra:",
+	"⚠ Anyone with this secret can forge notifications from this host":                   "⚠ Cualquiera con este secreto puede falsificar notificaciones de este host",
+	"⚠ Wrapping key stored in %s; the private key is protected by file permissions only": "⚠ Clave de envoltura guardada en %s; la clave privada solo está protegida por los permisos del archivo",
+	"✓ Created identity %s":                    "✓ Identidad %s creada",
+	"✓ Identity deleted":                       "✓ Identidad eliminada",
+	"✓ Wrapping key stored in the OS keychain": "✓ Clave de envoltura guardada en el llavero del sistema",
+	"🪪 Backup Identity":                        "🪪 Identidad de copia",
+	"🪪 Create Backup Identity":                 "🪪 Crear identidad de copia",
+	"🪪 Delete Backup Identity":                 "🪪 Eliminar identidad de copia",
+	"Listing backups in %s...":                 "Listando las copias de %s...",
+	"Name a backup, or pass --source to check every backup in a destination": "Indique una copia, o use --source para comprobar todas las copias de un destino",
+	"No backups in %s": "No hay copias en %s",
+	"⚠ %d of %d backups are not signed: made before backup.signing was enabled, or their signature was removed": "⚠ %d de %d copias no están firmadas: se hicieron antes de habilitar backup.signing, o se les quitó la firma",
+	"⚠ %s: not signed": "⚠ %s: sin firmar",
+	"⚠ Couldn't load this installation's identity, only backup.signing.trusted_keys are trusted: %v": "⚠ No se pudo cargar la identidad de esta instalación; solo se confía en backup.signing.trusted_keys: %v",
+	"⛔ %d of %d backups failed signature verification":                                               "⛔ %d de %d copias no superaron la verificación de firma",
+	"✅ All %d backups have valid signatures":                                                         "✅ Las %d copias tienen firmas válidas",
+	"✓ %s: signed by %s on %s":                                                                       "✓ %s: firmada por %s el %s",
+	"🔏 Verify Backups":                                                                               "🔏 Verificar copias",
+	"  Proofs published before that commit may have been altered":                                    "  Las pruebas publicadas antes de ese commit pueden haber sido alteradas",
+	"  Published as %s":                                                                              "  Publicada como %s",
+	"%d proof(s) in %s":                                                                              "%d prueba(s) en %s",
+	"Backup: %s (%s)":                                                                                "Copia: %s (%s)",
+	"Downloading %s from %s...":                                                                      "Descargando %s desde %s...",
+	"MANAGER":                                                                                        "GESTOR",
+	"No proofs published yet":                                                                        "Aún no se ha publicado ninguna prueba",
+	"No proofs repository configured (set proofs.git.repo_path)":                                     "No hay ningún repositorio de pruebas configurado (defina proofs.git.repo_path)",
+	"PUBLISHED": "PUBLICADA",
+	"To verify a backup stored in a destination, use --source":                 "Para verificar una copia almacenada en un destino, use --source",
+	"⛔ Backup does not match its published proof: it was modified or replaced": "⛔ La copia no coincide con su prueba publicada: se modificó o se reemplazó",
+	"✅ Backup matches its published proof":                                     "✅ La copia coincide con su prueba publicada",
+	"✓ Proof published %s in commit %s":                                        "✓ Prueba publicada %s en el commit %s",
+	"✓ Published integrity proof to %s":                                        "✓ Prueba de integridad publicada en %s",
+	"✗ %s was published %s with checksum %s":                                   "✗ %s se publicó %s con la suma de comprobación %s",
+	"✗ No proof was published for this backup":                                 "✗ No se publicó ninguna prueba para esta copia",
+	"✗ Proof history is not append-only: %v":                                   "✗ El historial de pruebas no es de solo anexado: %v",
+	"🧾 Backup Integrity Proofs":                                                "🧾 Pruebas de integridad de las copias",
+	"🧾 Verify Backup Proof":                                                    "🧾 Verificar la prueba de una copia",
+
+	// Storage authentication
+	"  rm \"%s\"":                   "  rm \"%s\"",
+	"A bundle password is required": "La contraseña del paquete es obligatoria",
+	"Bundle is for %s, not %s":      "El paquete es para %s, no para %s",
+	"Bundle version %d is newer than supported (%d); upgrade stashr":                           "La versión %d del paquete es más reciente que la admitida (%d); actualice stashr",
+	"Confirm bundle password: ":                                                                "Confirme la contraseña del paquete: ",
+	"Delete the bundle once it has been imported.":                                             "Elimine el paquete una vez importado.",
+	"Enter a password for the bundle: ":                                                        "Introduzca una contraseña para el paquete: ",
+	"Enter the bundle password: ":                                                              "Introduzca la contraseña del paquete: ",
+	"Failed to decrypt bundle: %v":                                                             "No se pudo descifrar el paquete: %v",
+	"Failed to read Google Drive credentials: %v":                                              "No se pudieron leer las credenciales de Google Drive: %v",
+	"Failed to read Google Drive token: %v":                                                    "No se pudo leer el token de Google Drive: %v",
+	"Google Drive credentials already exist on this machine. Overwrite them?":                  "Ya existen credenciales de Google Drive en este equipo. ¿Sobrescribirlas?",
+	"Google Drive credentials or token file is not valid JSON":                                 "El archivo de credenciales o de token de Google Drive no es JSON válido",
+	"Import cancelled":                                                                         "Importación cancelada",
+	"Invalid authentication bundle: %v":                                                        "Paquete de autenticación no válido: %v",
+	"Make sure you're using the password chosen during 'auth export'":                          "Asegúrese de usar la contraseña elegida durante 'auth export'",
+	"On the other machine, run: stashr auth import %s %s":                                      "En el otro equipo, ejecute: stashr auth import %s %s",
+	"Run 'stashr config validate' to test the connection, then delete the bundle:":             "Ejecute 'stashr config validate' para probar la conexión y después elimine el paquete:",
+	"Sign in on this machine first, e.g. with 'stashr config validate'":                        "Inicie sesión antes en este equipo, p. ej. con 'stashr config validate'",
+	"⚠️  The bundle grants access to your Google Drive. Only import it on machines you trust.": "⚠️  El paquete da acceso a su Google Drive. Impórtelo solo en equipos de confianza.",
+	"✓ Authentication bundle written to: %s":                                                   "✓ Paquete de autenticación escrito en: %s",
+	"✓ Credentials written to: %s":                                                             "✓ Credenciales escritas en: %s",
+	"✓ Decrypted bundle exported from %s on %s":                                                "✓ Paquete descifrado, exportado desde %s el %s",
+	"✓ Google Drive enabled in configuration":                                                  "✓ Google Drive habilitado en la configuración",
+	"✓ Token written to: %s":                                                                   "✓ Token escrito en: %s",
+	"🔑 Export Storage Authentication":                                                          "🔑 Exportar la autenticación del almacenamiento",
+	"🔑 Import Storage Authentication":                                                          "🔑 Importar la autenticación del almacenamiento",
+
+	// Migrate and convert
+	"  Vault now has %d items (%+d)":        "  La bóveda tiene ahora %d elementos (%+d)",
+	"Create %d items in 1Password vault %s": "Crear %d elementos en la bóveda de 1Password %s",
+	"Creating %d items in 1Password...":     "Creando %d elementos en 1Password...",
+	"Import %d items into Bitwarden":        "Importar %d elementos en Bitwarden",
+	"Importing %d items into Bitwarden...":  "Importando %d elementos en Bitwarden...",
+	"Mapping report:":                       "Informe de correspondencias:",
+	"Migration cancelled":                   "Migración cancelada",
+	"Open it in KeePassXC, or merge it into an existing database with Database → Merge From Database": "Ábrala en KeePassXC, o combínela con una base de datos existente con Base de datos → Combinar desde base de datos",
+	"Unknown target: %s (use: %s)":                 "Destino desconocido: %s (use: %s)",
+	"Write a KeePass database with %d items to %s": "Escribir una base de datos KeePass con %d elementos en %s",
+	"⚠ %s (%d items): %s":                          "⚠ %s (%d elementos): %s",
+	"⚠ Created %d of %d items; stopped at %q":      "⚠ Creados %d de %d elementos; se detuvo en %q",
+	"✅ Migration complete!":                        "✅ ¡Migración completada!",
+	"✓ %s is ready":                                "✓ %s está listo",
+	"✓ Created %d items":                           "✓ %d elementos creados",
+	"✓ Every item translates as it is":             "✓ Todos los elementos se trasladan tal cual",
+	"✓ Imported %d items":                          "✓ %d elementos importados",
+	"✓ KeePass database written to: %s":            "✓ Base de datos KeePass escrita en: %s",
+	"✓ Read %d items":                              "✓ %d elementos leídos",
+	"🚚 Migrate Backup":                             "🚚 Migrar copia",
+	"Converting to %s...":                          "Convirtiendo a %s...",
+	"Delete it after importing:":                   "Elimínelo después de importarlo:",
+	"Unknown format: %s (use: %s, kdbx)":           "Formato desconocido: %s (use: %s, kdbx)",
+	"⚠ %d items skipped (%s only supports logins with a website and password)":        "⚠ %d elementos omitidos (%s solo admite inicios de sesión con sitio web y contraseña)",
+	"⚠️  SECURITY WARNING: The converted file contains your passwords in plain text!": "⚠️  ADVERTENCIA DE SEGURIDAD: ¡el archivo convertido contiene sus contraseñas en texto plano!",
+	"✓ Converted %d items": "✓ %d elementos convertidos",
+	"🔁 Convert Backup":     "🔁 Convertir copia",
+	"Check the sample before sharing it: values are replaced by key name,":           "Revise la muestra antes de compartirla: los valores se reemplazan según el nombre de la clave,",
+	"Replacing vault contents with fake data...":                                     "Reemplazando el contenido de la bóveda por datos ficticios...",
+	"so text under keys stashr doesn't recognize is scrambled but keeps its length.": "así que el texto de las claves que stashr no reconoce se desordena pero conserva su longitud.",
+	"⚠ The sample doesn't parse like the original export":                            "⚠ La muestra no se interpreta igual que la exportación original",
+	"✓ Replaced %d values":                                                           "✓ %d valores reemplazados",
+	"✓ Sample parses to %d items, like the original":                                 "✓ La muestra contiene %d elementos, como el original",
+	"✓ Sample written to: %s":                                                        "✓ Muestra escrita en: %s",
+	"🎭 Anonymize Backup":                                                             "🎭 Anonimizar copia",
+
+	// Restore drills
+	"  Checks passed: %d/%d":            "  Comprobaciones superadas: %d/%d",
+	"  Score: %d/100":                   "  Puntuación: %d/100",
+	"Contacting remote destinations...": "Contactando con los destinos remotos...",
+	"Enter your encryption password from memory (leave empty if you don't know it).": "Introduzca su contraseña de cifrado de memoria (déjela vacía si no la sabe).",
+	"Failed to write report: %v":               "No se pudo escribir el informe: %v",
+	"Gaps found:":                              "Carencias encontradas:",
+	"Rehearsing restore of %s (%s from %s)...": "Ensayando la restauración de %s (%s desde %s)...",
+	"Report written to: %s":                    "Informe escrito en: %s",
+	"Sandbox: %s":                              "Entorno aislado: %s",
+	"Scenario: your computer is gone. Only your remote backups and your memory remain.": "Escenario: ha perdido su equipo. Solo le quedan sus copias remotas y su memoria.",
+	"⚠ Recovery is likely possible, but fix the gaps above":                             "⚠ Es probable que la recuperación sea posible, pero corrija las carencias anteriores",
+	"✅ You could recover from losing this machine today":                                "✅ Podría recuperarse hoy de la pérdida de este equipo",
+	"❌ Recovery would probably fail. Fix the gaps above":                                "❌ La recuperación probablemente fallaría. Corrija las carencias anteriores",
+	"📝 Rehearsal Report":                       "📝 Informe del ensayo",
+	"🧯 Disaster Recovery Rehearsal":            "🧯 Ensayo de recuperación ante desastres",
+	"Failed to read restore drill state: %v":   "No se pudo leer el estado de los simulacros de restauración: %v",
+	"Failed to record restore drill state: %v": "No se pudo registrar el estado de los simulacros de restauración: %v",
+	"Failed to record restore drill: %v":       "No se pudo registrar el simulacro de restauración: %v",
+	"Restore drill of %s from %s (%s)...":      "Simulacro de restauración de %s desde %s (%s)...",
+	"Running restore drills...":                "Ejecutando simulacros de restauración...",
+	"Skipping %s: it uses a separate password, which unattended drills can't ask for": "Se omite %s: usa una contraseña propia, que los simulacros desatendidos no pueden pedir",
+	"⚠ %d of %d restore drills failed":                                                "⚠ Fallaron %d de %d simulacros de restauración",
+	"⚠ Skipping %s: %v":                                                               "⚠ Se omite %s: %v",
+	"⚠ Skipping %s: not available":                                                    "⚠ Se omite %s: no disponible",
+	"⛔ %d of %d restore drills failed":                                                "⛔ Fallaron %d de %d simulacros de restauración",
+	"✅ All %d restore drills passed: the latest backups can be restored":              "✅ Se superaron los %d simulacros de restauración: las copias más recientes se pueden restaurar",
+	"✓ All %d restore drills passed":                                                  "✓ Se superaron los %d simulacros de restauración",
+	"✓ Restored %s from %s: %d items in %s":                                           "✓ %s restaurada desde %s: %d elementos en %s",
+	"✓ Sent restore drill notification":                                               "✓ Notificación del simulacro de restauración enviada",
+	"✗ %s from %s: %s check failed: %s":                                               "✗ %s desde %s: falló la comprobación de %s: %s",
+
+	// Schedule and daemon
+	"Command: %s":                                "Comando: %s",
+	"Definition:  %s":                            "Definición:        %s",
+	"Disable the scheduled backup in %s":         "Deshabilitar la copia programada en %s",
+	"Enable it in %s to run stashr backup %s":    "Habilitarla en %s para ejecutar stashr backup %s",
+	"Installing the scheduled backup with %s...": "Instalando la copia programada con %s...",
+	"Last result: %s":                            "Último resultado:  %s",
+	"Last run:    %s":                            "Última ejecución:  %s",
+	"Logs:        %s":                            "Registros:         %s",
+	"Next run:    %s":                            "Próxima ejecución: %s",
+	"No scheduled backup is installed":           "No hay ninguna copia programada instalada",
+	"Scheduler:   %s":                            "Programador:       %s",
+	"State:       %s":                            "Estado:            %s",
+	"Unexpected argument %q: pass backup flags after --, e.g. stashr schedule install --daily 02:00 -- --destination gdrive": "Argumento inesperado %q: pase las opciones de backup después de --, p. ej. stashr schedule install --daily 02:00 -- --destination gdrive",
+	"Write %s": "Escribir %s",
+	"⚠ %s is a temporary build from \"go run\"; install stashr and schedule the installed binary":                                                                                               "⚠ %s es una compilación temporal de \"go run\"; instale stashr y programe el binario instalado",
+	"⚠ %s uses a separate password, which scheduled backups can't prompt for":                                                                                                                   "⚠ %s usa una contraseña propia, que las copias programadas no pueden pedir",
+	"⚠ Scheduled backups can't answer prompts: remove --interactive and --prompt-each":                                                                                                          "⚠ Las copias programadas no pueden responder preguntas: quite --interactive y --prompt-each",
+	"⚠ Scheduled backups can't prompt for the encryption password: store it with \"stashr keychain set\" and enable backup.encryption.keychain, or use a key file (backup.encryption.key_file)": "⚠ Las copias programadas no pueden pedir la contraseña de cifrado: guárdela con \"stashr keychain set\" y habilite backup.encryption.keychain, o use un archivo de clave (backup.encryption.key_file)",
+	"✅ stashr backup will run %s":                                                        "✅ stashr backup se ejecutará %s",
+	"✓ Installed %s":                                                                     "✓ %s instalado",
+	"✓ Removed the scheduled backup":                                                     "✓ Copia programada eliminada",
+	"💡 Check the last run with: stashr schedule status":                                  "💡 Consulte la última ejecución con: stashr schedule status",
+	"💡 Install one with: stashr schedule install --daily 02:00":                          "💡 Instale una con: stashr schedule install --daily 02:00",
+	"💡 To run backups while you're logged out, enable lingering: loginctl enable-linger": "💡 Para hacer copias sin tener la sesión iniciada, habilite lingering: loginctl enable-linger",
+	"🕑 Remove Scheduled Backup":                                                          "🕑 Eliminar copia programada",
+	"🕑 Schedule Backups":                                                                 "🕑 Programar copias",
+	"🕑 Scheduled Backup":                                                                 "🕑 Copia programada",
+	"Change backups: checking for changes every %s":                                      "Copias por cambios: se buscan cambios cada %s",
+	"Daemon stopped":                                                                     "Demonio detenido",
+	"Failed to read digest state: %v":                                                    "No se pudo leer el estado del resumen: %v",
+	"Failed to record digest state: %v":                                                  "No se pudo registrar el estado del resumen: %v",
+	"Health checks: every %s, notifying after %d days locked":                            "Comprobaciones de estado: cada %s, con aviso tras %d días bloqueado",
+	"Restore drills: every %s":                                                           "Simulacros de restauración: cada %s",
+	"Retrying at %s":                                                                     "Se reintentará a las %s",
+	"Sending weekly digest...":                                                           "Enviando el resumen semanal...",
+	"Upload retries: every %d minutes while uploads are queued":                          "Reintentos de subida: cada %d minutos mientras haya subidas en cola",
+	"Weekly digest: every %s at %02d:%02d":                                               "Resumen semanal: cada %s a las %02d:%02d",
+	"⚠ No jobs are enabled. Enable notifications.digest to send a weekly digest, notifications.health to check password manager sessions, backup.on_change to back up changed vaults or backup.drills to test-restore backups": "⚠ No hay ninguna tarea habilitada. Habilite notifications.digest para enviar un resumen semanal, notifications.health para comprobar las sesiones de los gestores de contraseñas, backup.on_change para copiar las bóvedas que cambien o backup.drills para hacer restauraciones de prueba",
+	"✓ Weekly digest sent":              "✓ Resumen semanal enviado",
+	"🕑 stashr daemon":                   "🕑 Demonio de stashr",
+	"⚠ Sending unsigned webhook: %v":    "⚠ Enviando un webhook sin firmar: %v",
+	"✓ Digest sent":                     "✓ Resumen enviado",
+	"📬 Backup Digest":                   "📬 Resumen de copias",
+	"Failed to record health of %s: %v": "No se pudo registrar el estado de %s: %v",
+	"⚠ %s is %s since %s: %s":           "⚠ %s está %s desde %s: %s",
+	"✓ %s is healthy again":             "✓ %s vuelve a estar en buen estado",
+	"✓ Sent %s health notification":     "✓ Notificación de estado de %s enviada",
+}
@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultLanguage is the language used when no other language is selected
+	DefaultLanguage = "en"
+	// EnvLanguage is the environment variable that overrides the configured language
+	EnvLanguage = "STASHR_LANG"
+)
+
+var (
+	mu       sync.RWMutex
+	language = DefaultLanguage
+
+	// catalogs maps a language code to its message catalog. Catalogs are keyed
+	// by the English message (or format string) so untranslated messages fall
+	// back to English automatically.
+	catalogs = map[string]map[string]string{
+		"es": spanish,
+	}
+)
+
+// Supported returns the list of supported language codes
+func Supported() []string {
+	langs := []string{DefaultLanguage}
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// IsSupported checks if a language code has a message catalog
+func IsSupported(lang string) bool {
+	lang = normalize(lang)
+	if lang == DefaultLanguage {
+		return true
+	}
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// SetLanguage sets the language used for user-facing messages
+func SetLanguage(lang string) error {
+	lang = normalize(lang)
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	if !IsSupported(lang) {
+		return fmt.Errorf("unsupported language: %s (supported: %s)", lang, strings.Join(Supported(), ", "))
+	}
+
+	mu.Lock()
+	language = lang
+	mu.Unlock()
+	return nil
+}
+
+// Language returns the current language code
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// DetectLanguage determines the language from the environment.
+// STASHR_LANG takes precedence over the standard locale variables.
+func DetectLanguage() string {
+	for _, env := range []string{EnvLanguage, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			lang := normalize(value)
+			if IsSupported(lang) {
+				return lang
+			}
+			// An explicit stashr override wins even if unsupported, so the
+			// caller can report it
+			if env == EnvLanguage {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+// HasEnvOverride reports whether the language was explicitly set via STASHR_LANG
+func HasEnvOverride() bool {
+	return os.Getenv(EnvLanguage) != ""
+}
+
+// T translates a message (or format string) into the current language
+func T(message string) string {
+	mu.RLock()
+	lang := language
+	mu.RUnlock()
+
+	if lang == DefaultLanguage {
+		return message
+	}
+	if translated, ok := catalogs[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Tf translates a format string and formats it with the given arguments
+func Tf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// IsYes checks if a response means "yes" in English or the current language
+func IsYes(response string) bool {
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response == "y" || response == "yes" {
+		return true
+	}
+	for _, word := range strings.Split(T("y|yes"), "|") {
+		if response == word {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts locale strings like "es_ES.UTF-8" into a language code
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if idx := strings.IndexAny(lang, "_.-@"); idx >= 0 {
+		lang = lang[:idx]
+	}
+	if lang == "c" || lang == "posix" {
+		return DefaultLanguage
+	}
+	return lang
+}
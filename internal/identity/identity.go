@@ -0,0 +1,276 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/keychain"
+)
+
+const (
+	// FileName is the identity file in the stashr config directory
+	FileName = "identity.json"
+
+	// WrapKeyFileName holds the wrapping key when the keychain isn't used
+	WrapKeyFileName = "identity.wrap"
+
+	// fileVersion is the format version of the identity file
+	fileVersion = 1
+
+	// webhookSecretContext separates the webhook secret from other uses of the key
+	webhookSecretContext = "stashr webhook signing v1"
+)
+
+// Where the key that wraps the private key is stored
+const (
+	KeyStorageKeychain = "keychain"
+	KeyStorageFile     = "file"
+)
+
+// ErrNoIdentity is returned when no identity has been created
+var ErrNoIdentity = errors.New("no identity created (run: stashr identity create)")
+
+// invalidNameChars are replaced in identity names
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Identity is a per-installation Ed25519 keypair identifying this machine
+type Identity struct {
+	// ID names the installation, e.g. "laptop-3f9a12c0"
+	ID         string
+	Hostname   string
+	PublicKey  ed25519.PublicKey
+	CreatedAt  time.Time
+	KeyStorage string
+	privateKey ed25519.PrivateKey
+}
+
+// identityFile is the on-disk form of an identity. The private key is encrypted
+// with a random wrapping key kept in the keychain (or a separate file).
+type identityFile struct {
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	Hostname   string    `json:"hostname"`
+	PublicKey  []byte    `json:"public_key"`
+	CreatedAt  time.Time `json:"created_at"`
+	KeyStorage string    `json:"key_storage"`
+	WrappedKey []byte    `json:"wrapped_key"`
+}
+
+// Create generates a new identity in dir. The wrapping key is stored in the OS
+// keychain, or next to the identity file when useKeychain is false.
+func Create(dir, name string, useKeychain bool) (*Identity, error) {
+	if useKeychain {
+		if err := keychain.Available(); err != nil {
+			return nil, fmt.Errorf("keychain unavailable: %w", err)
+		}
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	if name == "" {
+		name = hostname
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		name = "stashr"
+	}
+
+	identity := &Identity{
+		ID:         fmt.Sprintf("%s-%s", name, fingerprint(publicKey)[:8]),
+		Hostname:   hostname,
+		PublicKey:  publicKey,
+		CreatedAt:  time.Now(),
+		KeyStorage: KeyStorageFile,
+		privateKey: privateKey,
+	}
+	if useKeychain {
+		identity.KeyStorage = KeyStorageKeychain
+	}
+
+	// Wrap the private key with a random key
+	wrapBytes := make([]byte, 32)
+	if _, err := rand.Read(wrapBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate wrapping key: %w", err)
+	}
+	wrapKey := hex.EncodeToString(wrapBytes)
+	wrapped, err := crypto.Encrypt(privateKey.Seed(), wrapKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap private key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if useKeychain {
+		if err := keychain.Set(keychainAccount(identity.ID), wrapKey); err != nil {
+			return nil, fmt.Errorf("failed to store wrapping key in keychain: %w", err)
+		}
+		// Some keyrings report success even when nothing was stored
+		stored, err := keychain.Get(keychainAccount(identity.ID))
+		matches := err == nil && string(stored.Bytes()) == wrapKey
+		stored.Destroy()
+		if !matches {
+			return nil, fmt.Errorf("failed to store wrapping key in keychain: it could not be read back")
+		}
+	} else if err := os.WriteFile(filepath.Join(dir, WrapKeyFileName), []byte(wrapKey), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write wrapping key: %w", err)
+	}
+
+	data, err := json.MarshalIndent(identityFile{
+		Version:    fileVersion,
+		ID:         identity.ID,
+		Hostname:   identity.Hostname,
+		PublicKey:  publicKey,
+		CreatedAt:  identity.CreatedAt,
+		KeyStorage: identity.KeyStorage,
+		WrappedKey: wrapped,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+// Load reads the identity in dir and unwraps its private key. It returns
+// ErrNoIdentity if none was created.
+func Load(dir string) (*Identity, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return nil, ErrNoIdentity
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity: %w", err)
+	}
+
+	var file identityFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid identity file: %w", err)
+	}
+	if file.Version > fileVersion {
+		return nil, fmt.Errorf("identity file version %d is newer than supported (%d); upgrade stashr", file.Version, fileVersion)
+	}
+	if len(file.PublicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid identity file: bad public key")
+	}
+
+	var wrapKey string
+	switch file.KeyStorage {
+	case KeyStorageKeychain:
+		stored, err := keychain.Get(keychainAccount(file.ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wrapping key from keychain: %w", err)
+		}
+		wrapKey = string(stored.Bytes())
+		stored.Destroy()
+	case KeyStorageFile:
+		wrapBytes, err := os.ReadFile(filepath.Join(dir, WrapKeyFileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wrapping key: %w", err)
+		}
+		wrapKey = string(wrapBytes)
+	default:
+		return nil, fmt.Errorf("invalid identity file: unknown key storage %q", file.KeyStorage)
+	}
+
+	seed, err := crypto.Decrypt(file.WrappedKey, wrapKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap private key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid identity file: bad private key")
+	}
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	if !privateKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(file.PublicKey)) {
+		return nil, fmt.Errorf("identity private key doesn't match its public key")
+	}
+
+	return &Identity{
+		ID:         file.ID,
+		Hostname:   file.Hostname,
+		PublicKey:  file.PublicKey,
+		CreatedAt:  file.CreatedAt,
+		KeyStorage: file.KeyStorage,
+		privateKey: privateKey,
+	}, nil
+}
+
+// Delete removes the identity in dir and its wrapping key
+func Delete(dir string) error {
+	identity, err := Load(dir)
+	if err != nil && !errors.Is(err, ErrNoIdentity) {
+		// Still remove what can be removed, e.g. when the keychain entry is gone
+		data, readErr := os.ReadFile(filepath.Join(dir, FileName))
+		var file identityFile
+		if readErr != nil || json.Unmarshal(data, &file) != nil {
+			return err
+		}
+		identity = &Identity{ID: file.ID, KeyStorage: file.KeyStorage}
+	}
+	if identity == nil {
+		return ErrNoIdentity
+	}
+
+	if identity.KeyStorage == KeyStorageKeychain {
+		if err := keychain.Delete(keychainAccount(identity.ID)); err != nil {
+			return fmt.Errorf("failed to delete wrapping key from keychain: %w", err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, WrapKeyFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, FileName))
+}
+
+// Fingerprint returns the hex SHA-256 of the public key, for comparing identities
+func (i *Identity) Fingerprint() string {
+	return fingerprint(i.PublicKey)
+}
+
+// Sign signs data with the identity's private key
+func (i *Identity) Sign(data []byte) []byte {
+	return ed25519.Sign(i.privateKey, data)
+}
+
+// Verify reports whether signature is a valid signature of data by publicKey
+func Verify(publicKey ed25519.PublicKey, data, signature []byte) bool {
+	return len(publicKey) == ed25519.PublicKeySize && ed25519.Verify(publicKey, data, signature)
+}
+
+// WebhookSecret returns the HMAC key webhook payloads are signed with. It is
+// derived from the private key, so it is shared with receivers instead of the key.
+func (i *Identity) WebhookSecret() []byte {
+	mac := hmac.New(sha256.New, i.privateKey.Seed())
+	mac.Write([]byte(webhookSecretContext))
+	return mac.Sum(nil)
+}
+
+// fingerprint returns the hex SHA-256 of a public key
+func fingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// keychainAccount returns the keychain account holding an identity's wrapping key
+func keychainAccount(id string) string {
+	return "identity:" + id
+}
@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
+
+// signatureVersion is the format version of backup signatures
+const signatureVersion = 1
+
+// signatureContext separates backup signatures from anything else the key signs
+const signatureContext = "stashr backup signature v1"
+
+// ErrUntrustedSigner is returned for a valid signature by a key that isn't trusted
+var ErrUntrustedSigner = errors.New("signed by an untrusted key")
+
+// BackupSignature is the signature file stored next to a signed backup. It
+// signs the backup's name and checksum, so a destination can neither modify
+// a backup nor pass off one backup as another without it being noticed.
+type BackupSignature struct {
+	Version int `json:"version"`
+	// Signer is the ID of the identity that signed the backup
+	Signer    string            `json:"signer"`
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	Filename  string            `json:"filename"`
+	SHA256    string            `json:"sha256"`
+	SignedAt  time.Time         `json:"signed_at"`
+	Signature []byte            `json:"signature"`
+}
+
+// SignBackup signs an encrypted backup stored under filename and returns its signature file
+func (i *Identity) SignBackup(filename string, data []byte) ([]byte, error) {
+	sum := sha256.Sum256(data)
+	signature := BackupSignature{
+		Version:   signatureVersion,
+		Signer:    i.ID,
+		PublicKey: i.PublicKey,
+		Filename:  path.Base(filename),
+		SHA256:    hex.EncodeToString(sum[:]),
+		SignedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	signature.Signature = i.Sign(signature.payload())
+	return json.MarshalIndent(signature, "", "  ")
+}
+
+// VerifyBackup checks a backup against its signature file. The signature must
+// be made by one of the trusted keys: the key recorded in the file only says
+// who claims to have signed it.
+func VerifyBackup(signatureFile []byte, filename string, data []byte, trusted []ed25519.PublicKey) (*BackupSignature, error) {
+	var signature BackupSignature
+	if err := json.Unmarshal(signatureFile, &signature); err != nil {
+		return nil, fmt.Errorf("invalid signature file: %w", err)
+	}
+	if signature.Version != signatureVersion {
+		return nil, fmt.Errorf("unsupported signature version %d", signature.Version)
+	}
+	if !Verify(signature.PublicKey, signature.payload(), signature.Signature) {
+		return &signature, fmt.Errorf("signature is invalid: the signature file was modified")
+	}
+
+	if !trustedKey(signature.PublicKey, trusted) {
+		return &signature, fmt.Errorf("%w %s (%s)", ErrUntrustedSigner, signature.Signer, fingerprint(signature.PublicKey)[:16])
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != signature.SHA256 {
+		return &signature, fmt.Errorf("backup doesn't match its signature: it was modified or replaced")
+	}
+	if name := path.Base(filename); name != signature.Filename {
+		return &signature, fmt.Errorf("signature is for %s, not %s: the backup was renamed or substituted", signature.Filename, name)
+	}
+	return &signature, nil
+}
+
+// payload is the message a backup signature signs
+func (s *BackupSignature) payload() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", signatureContext, s.Signer, s.Filename, s.SHA256, s.SignedAt.UTC().Format(time.RFC3339)))
+}
+
+// trustedKey reports whether key is one of the trusted keys
+func trustedKey(key ed25519.PublicKey, trusted []ed25519.PublicKey) bool {
+	for _, candidate := range trusted {
+		if bytes.Equal(candidate, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/harshalranjhani/stashr/internal/secret"
+)
+
+// Service is the keychain service name all stashr secrets are stored under
+const Service = "stashr"
+
+// PasswordAccount is the account the encryption password is cached under
+const PasswordAccount = "encryption-password"
+
+// NamesAccount is the account the key encrypting obfuscated backup names is stored under
+const NamesAccount = "obfuscated-names-key"
+
+// probeAccount is looked up to check that the keychain answers; nothing is stored under it
+const probeAccount = "availability-check"
+
+// ErrNotFound is returned when no secret is stored for an account
+var ErrNotFound = errors.New("secret not found in keychain")
+
+// Available returns an error explaining why the OS keychain (macOS Keychain,
+// Windows Credential Manager or the Secret Service on Linux) can't be used
+func Available() error {
+	if _, err := keyring.Get(Service, probeAccount); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("the OS keychain did not respond: %w", err)
+	}
+	return nil
+}
+
+// Set stores a secret for an account, replacing any existing one
+func Set(account, value string) error {
+	if err := keyring.Set(Service, account, value); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w", err)
+	}
+	return nil
+}
+
+// Get returns the secret stored for an account, or ErrNotFound
+func Get(account string) (*secret.Buffer, error) {
+	value, err := keyring.Get(Service, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret from keychain: %w", err)
+	}
+	// The keyring returns a string, which can't be wiped; keep only the locked copy
+	return secret.FromString(value), nil
+}
+
+// Delete removes the secret stored for an account. Deleting a missing secret succeeds.
+func Delete(account string) error {
+	if err := keyring.Delete(Service, account); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete secret from keychain: %w", err)
+	}
+	return nil
+}
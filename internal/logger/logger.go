@@ -6,9 +6,12 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
+
+	"github.com/harshalranjhani/stashr/internal/i18n"
 )
 
 // Level represents the log level
@@ -48,6 +51,12 @@ type Logger struct {
 	fileLogger *log.Logger
 	verbose    bool
 	colorized  bool
+	// redact masks sensitive text before it is written to the file logger
+	redact func(string) string
+	// lastFailure is the last failure message printed, untranslated
+	lastFailure string
+	// mu keeps lines from concurrent tasks from being interleaved mid-line
+	mu sync.Mutex
 }
 
 var (
@@ -105,19 +114,45 @@ func SetFileOutput(path string) error {
 	return nil
 }
 
+// SetRedaction sets the function that masks sensitive text in file logs.
+// Console output is not redacted. A nil function disables redaction.
+func SetRedaction(redact func(string) string) {
+	defaultLogger.mu.Lock()
+	defer defaultLogger.mu.Unlock()
+	defaultLogger.redact = redact
+}
+
+// writeFile writes a line to the file logger, if set. The caller must hold l.mu.
+func (l *Logger) writeFile(line string) {
+	if l.fileLogger == nil {
+		return
+	}
+	if l.redact != nil {
+		line = l.redact(line)
+	}
+	l.fileLogger.Print(line)
+}
+
 // log is the internal logging function
 func (l *Logger) log(level Level, format string, args ...interface{}) {
+	l.logPrefixed(level, "", format, args...)
+}
+
+// logPrefixed logs a message with a prefix placed before the message text
+func (l *Logger) logPrefixed(level Level, prefix, format string, args ...interface{}) {
 	if level < l.level {
 		return
 	}
 
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	message := fmt.Sprintf(format, args...)
 
-	// Log to file if file logger is set
-	if l.fileLogger != nil {
-		l.fileLogger.Printf("[%s] %s", level.String(), message)
-	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Log to file if file logger is set (file logs stay in English for bug reports)
+	l.writeFile(fmt.Sprintf("[%s] %s%s", level.String(), prefix, fmt.Sprintf(format, args...)))
+
+	message := prefix + i18n.Tf(format, args...)
 
 	// Format for console output
 	var levelStr string
@@ -162,46 +197,52 @@ func Error(format string, args ...interface{}) {
 
 // Success prints a success message with a checkmark
 func Success(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	if defaultLogger.colorized {
-		fmt.Fprintf(defaultLogger.output, "%s %s\n", successColor("✓"), message)
-	} else {
-		fmt.Fprintf(defaultLogger.output, "✓ %s\n", message)
-	}
+	defaultLogger.symbol("✓", successColor, "", format, args...)
 }
 
 // Failure prints a failure message with an X
 func Failure(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	if defaultLogger.colorized {
-		fmt.Fprintf(defaultLogger.output, "%s %s\n", errorColor("✗"), message)
-	} else {
-		fmt.Fprintf(defaultLogger.output, "✗ %s\n", message)
-	}
+	defaultLogger.symbol("✗", errorColor, "", format, args...)
+}
+
+// LastFailure returns the last failure message printed, so a command can report
+// why it stopped, or "" if there was none
+func LastFailure() string {
+	defaultLogger.mu.Lock()
+	defer defaultLogger.mu.Unlock()
+	return defaultLogger.lastFailure
 }
 
 // Warning prints a warning message with a warning symbol
 func Warning(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	if defaultLogger.colorized {
-		fmt.Fprintf(defaultLogger.output, "%s %s\n", warnColor("⚠"), message)
-	} else {
-		fmt.Fprintf(defaultLogger.output, "⚠ %s\n", message)
-	}
+	defaultLogger.symbol("⚠", warnColor, "", format, args...)
 }
 
 // Progress prints a progress message
 func Progress(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	if defaultLogger.colorized {
-		fmt.Fprintf(defaultLogger.output, "%s %s\n", infoColor("→"), message)
+	defaultLogger.symbol("→", infoColor, "", format, args...)
+}
+
+// symbol prints a message led by a status symbol
+func (l *Logger) symbol(symbol string, colorize func(a ...interface{}) string, prefix, format string, args ...interface{}) {
+	message := prefix + i18n.Tf(format, args...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writeFile(fmt.Sprintf("%s %s%s", symbol, prefix, fmt.Sprintf(format, args...)))
+	if symbol == "✗" {
+		l.lastFailure = prefix + fmt.Sprintf(format, args...)
+	}
+	if l.colorized {
+		fmt.Fprintf(l.output, "%s %s\n", colorize(symbol), message)
 	} else {
-		fmt.Fprintf(defaultLogger.output, "→ %s\n", message)
+		fmt.Fprintf(l.output, "%s %s\n", symbol, message)
 	}
 }
 
 // Header prints a formatted header
 func Header(title string) {
+	title = i18n.T(title)
 	line := strings.Repeat("━", len(title))
 	if defaultLogger.colorized {
 		fmt.Fprintf(defaultLogger.output, "\n%s\n%s\n\n", color.New(color.Bold).Sprint(title), line)
@@ -221,3 +262,70 @@ func PrintError(err error) {
 		Failure("Error: %v", err)
 	}
 }
+
+// Scope prefixes every message with a task name, so the output of tasks running
+// concurrently stays readable when their lines interleave
+type Scope struct {
+	name   string
+	prefix string
+}
+
+// WithPrefix returns a Scope that prefixes messages with "[name] ". An empty name
+// returns a Scope that prints exactly like the package-level functions.
+func WithPrefix(name string) *Scope {
+	if name == "" {
+		return &Scope{}
+	}
+	return &Scope{name: name, prefix: "[" + name + "] "}
+}
+
+// WithPrefix returns a Scope that adds name to the scope's prefix, e.g.
+// "[bitwarden/USB] ", or prefixes messages with "[name] " if it has none
+func (s *Scope) WithPrefix(name string) *Scope {
+	if s.name == "" {
+		return WithPrefix(name)
+	}
+	return WithPrefix(s.name + "/" + name)
+}
+
+// Prefixed reports whether the scope adds a prefix
+func (s *Scope) Prefixed() bool {
+	return s.prefix != ""
+}
+
+// Debug logs a debug message
+func (s *Scope) Debug(format string, args ...interface{}) {
+	defaultLogger.logPrefixed(DEBUG, s.prefix, format, args...)
+}
+
+// Info logs an info message
+func (s *Scope) Info(format string, args ...interface{}) {
+	defaultLogger.logPrefixed(INFO, s.prefix, format, args...)
+}
+
+// Success prints a success message with a checkmark
+func (s *Scope) Success(format string, args ...interface{}) {
+	defaultLogger.symbol("✓", successColor, s.prefix, format, args...)
+}
+
+// Failure prints a failure message with an X
+func (s *Scope) Failure(format string, args ...interface{}) {
+	defaultLogger.symbol("✗", errorColor, s.prefix, format, args...)
+}
+
+// Warning prints a warning message with a warning symbol
+func (s *Scope) Warning(format string, args ...interface{}) {
+	defaultLogger.symbol("⚠", warnColor, s.prefix, format, args...)
+}
+
+// Progress prints a progress message
+func (s *Scope) Progress(format string, args ...interface{}) {
+	defaultLogger.symbol("→", infoColor, s.prefix, format, args...)
+}
+
+// PrintError prints a formatted error message
+func (s *Scope) PrintError(err error) {
+	if err != nil {
+		s.Failure("Error: %v", err)
+	}
+}
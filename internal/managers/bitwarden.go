@@ -12,15 +12,19 @@ import (
 
 // Bitwarden represents the Bitwarden password manager
 type Bitwarden struct {
+	cliRunner
 	CLIPath string
 	Email   string
+	// Session is an explicit session token; when empty BW_SESSION is inherited
+	Session string
 }
 
 // NewBitwarden creates a new Bitwarden manager instance
 func NewBitwarden(cliPath, email string) *Bitwarden {
 	return &Bitwarden{
-		CLIPath: cliPath,
-		Email:   email,
+		cliRunner: cliRunner{timeout: DefaultCommandTimeout},
+		CLIPath:   cliPath,
+		Email:     email,
 	}
 }
 
@@ -29,6 +33,13 @@ func (b *Bitwarden) Name() string {
 	return "bitwarden"
 }
 
+// SetSession passes a session token to every bw command instead of relying on
+// the ambient BW_SESSION environment variable
+func (b *Bitwarden) SetSession(token string) {
+	b.Session = token
+	b.setEnv("BW_SESSION", token)
+}
+
 // IsInstalled checks if the Bitwarden CLI is installed
 func (b *Bitwarden) IsInstalled() bool {
 	return utils.IsCommandAvailable(b.CLIPath)
@@ -44,9 +55,9 @@ func (b *Bitwarden) IsAuthenticated() (bool, error) {
 	}
 
 	// Run 'bw status' to check authentication status
-	output, err := utils.RunCommand(b.CLIPath, "status")
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, "status")
 	if err != nil {
-		return false, fmt.Errorf("failed to check status: %w", err)
+		return false, fmt.Errorf("failed to check status: %w (output: %s)", err, string(output))
 	}
 
 	// Parse JSON output
@@ -98,20 +109,9 @@ func (b *Bitwarden) Export(outputPath string) error {
 		}
 	}
 
-	// Get session token from environment
-	sessionToken := os.Getenv("BW_SESSION")
-
-	var cmd *exec.Cmd
-	if sessionToken != "" {
-		// Use session token
-		cmd = exec.Command(b.CLIPath, "export", "--format", "json", "--output", outputPath, "--session", sessionToken)
-	} else {
-		// Try without session token (user might be unlocked)
-		cmd = exec.Command(b.CLIPath, "export", "--format", "json", "--output", outputPath)
-	}
-
 	// Run export command
-	output, err := cmd.CombinedOutput()
+	args := b.sessionArgs("export", "--format", "json", "--output", outputPath)
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, args...)
 	if err != nil {
 		return &ExportError{
 			Manager: b.Name(),
@@ -130,6 +130,84 @@ func (b *Bitwarden) Export(outputPath string) error {
 	return nil
 }
 
+// Import imports an unencrypted Bitwarden JSON export into the vault. Items are
+// added alongside existing ones; Bitwarden doesn't merge duplicates.
+func (b *Bitwarden) Import(importPath string) error {
+	authenticated, err := b.IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if !authenticated {
+		return &ManagerNotAuthenticatedError{
+			Manager: b.Name(),
+			Message: "not authenticated",
+		}
+	}
+
+	args := b.sessionArgs("import", "bitwardenjson", importPath)
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, args...)
+	if err != nil {
+		return &ImportError{
+			Manager: b.Name(),
+			Err:     fmt.Errorf("import failed: %w (output: %s)", err, strings.TrimSpace(string(output))),
+		}
+	}
+
+	return nil
+}
+
+// CanImportData reports whether ImportData works on this platform
+func (b *Bitwarden) CanImportData() bool {
+	return stdinPath != ""
+}
+
+// ImportData imports an unencrypted Bitwarden JSON export held in memory like
+// Import. bw only imports files, so the data is piped to it as its standard
+// input and read back through /dev/stdin, never written to disk.
+func (b *Bitwarden) ImportData(data []byte) error {
+	if !b.CanImportData() {
+		return &ImportError{
+			Manager: b.Name(),
+			Err:     fmt.Errorf("importing from a pipe isn't supported on this platform"),
+		}
+	}
+	authenticated, err := b.IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if !authenticated {
+		return &ManagerNotAuthenticatedError{
+			Manager: b.Name(),
+			Message: "not authenticated",
+		}
+	}
+
+	args := b.sessionArgs("import", "bitwardenjson", stdinPath)
+	output, err := b.combinedOutputWithInput(b.Name(), data, b.CLIPath, args...)
+	if err != nil {
+		return &ImportError{
+			Manager: b.Name(),
+			Err:     fmt.Errorf("import failed: %w (output: %s)", err, strings.TrimSpace(string(output))),
+		}
+	}
+
+	return nil
+}
+
+// sessionArgs appends the session token to CLI arguments, using the explicit
+// token and falling back to the environment. Without one the command runs as
+// is, which works if the vault is already unlocked.
+func (b *Bitwarden) sessionArgs(args ...string) []string {
+	sessionToken := b.Session
+	if sessionToken == "" {
+		sessionToken = os.Getenv("BW_SESSION")
+	}
+	if sessionToken != "" {
+		args = append(args, "--session", sessionToken)
+	}
+	return args
+}
+
 // GetItemCount returns the number of items in the vault
 func (b *Bitwarden) GetItemCount() (int, error) {
 	if !b.IsInstalled() {
@@ -140,7 +218,7 @@ func (b *Bitwarden) GetItemCount() (int, error) {
 	}
 
 	// Run 'bw list items' to get all items
-	output, err := utils.RunCommand(b.CLIPath, "list", "items")
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, "list", "items")
 	if err != nil {
 		// If command fails, return 0 (we can't get count)
 		return 0, nil
@@ -156,6 +234,56 @@ func (b *Bitwarden) GetItemCount() (int, error) {
 	return len(items), nil
 }
 
+// Revision syncs the local vault cache with the server and fingerprints its items
+// and folders by revision date
+func (b *Bitwarden) Revision() (string, error) {
+	if _, err := b.IsAuthenticated(); err != nil {
+		return "", err
+	}
+
+	// List commands read the local cache, which only sync updates
+	if output, err := b.combinedOutput(b.Name(), b.CLIPath, b.sessionArgs("sync")...); err != nil {
+		return "", fmt.Errorf("failed to sync vault: %w (output: %s)", err, string(output))
+	}
+
+	var entries []string
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, b.sessionArgs("list", "items")...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list items: %w (output: %s)", err, string(output))
+	}
+	var items []struct {
+		ID           string `json:"id"`
+		RevisionDate string `json:"revisionDate"`
+	}
+	if err := json.Unmarshal(output, &items); err != nil {
+		return "", fmt.Errorf("failed to parse items: %w", err)
+	}
+	for _, item := range items {
+		entries = append(entries, "item "+item.ID+" "+item.RevisionDate)
+	}
+
+	// Folders have no revision date, so a rename is detected by the name
+	output, err = b.combinedOutput(b.Name(), b.CLIPath, b.sessionArgs("list", "folders")...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list folders: %w (output: %s)", err, string(output))
+	}
+	var folders []struct {
+		ID   *string `json:"id"`
+		Name string  `json:"name"`
+	}
+	if err := json.Unmarshal(output, &folders); err != nil {
+		return "", fmt.Errorf("failed to parse folders: %w", err)
+	}
+	for _, folder := range folders {
+		// "No Folder" is listed with a null ID
+		if folder.ID != nil {
+			entries = append(entries, "folder "+*folder.ID+" "+folder.Name)
+		}
+	}
+
+	return revisionFingerprint(entries), nil
+}
+
 // Unlock prompts the user to unlock the vault
 func (b *Bitwarden) Unlock() error {
 	if !b.IsInstalled() {
@@ -166,7 +294,7 @@ func (b *Bitwarden) Unlock() error {
 	}
 
 	fmt.Println("Please unlock your Bitwarden vault:")
-	cmd := exec.Command(b.CLIPath, "unlock")
+	cmd := b.interactiveCommand(b.CLIPath, "unlock")
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -190,9 +318,9 @@ func (b *Bitwarden) Login() error {
 	fmt.Println("Please login to Bitwarden:")
 	var cmd *exec.Cmd
 	if b.Email != "" {
-		cmd = exec.Command(b.CLIPath, "login", b.Email)
+		cmd = b.interactiveCommand(b.CLIPath, "login", b.Email)
 	} else {
-		cmd = exec.Command(b.CLIPath, "login")
+		cmd = b.interactiveCommand(b.CLIPath, "login")
 	}
 
 	cmd.Stdin = os.Stdin
@@ -215,9 +343,9 @@ func (b *Bitwarden) GetStatus() (string, error) {
 		}
 	}
 
-	output, err := utils.RunCommand(b.CLIPath, "status")
+	output, err := b.combinedOutput(b.Name(), b.CLIPath, "status")
 	if err != nil {
-		return "", fmt.Errorf("failed to get status: %w", err)
+		return "", fmt.Errorf("failed to get status: %w (output: %s)", err, string(output))
 	}
 
 	var status struct {
@@ -229,3 +357,59 @@ func (b *Bitwarden) GetStatus() (string, error) {
 
 	return strings.ToUpper(status.Status[:1]) + status.Status[1:], nil
 }
+
+// bitwardenItemTypes maps Bitwarden item type identifiers to normalized categories
+var bitwardenItemTypes = map[int]string{
+	1: "login",
+	2: "secure_note",
+	3: "card",
+	4: "identity",
+	5: "ssh_key",
+}
+
+// GetStats parses a Bitwarden JSON export and returns item statistics
+func (b *Bitwarden) GetStats(exportPath string) (*VaultStats, error) {
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var export struct {
+		Items []struct {
+			Type         int    `json:"type"`
+			RevisionDate string `json:"revisionDate"`
+			Login        *struct {
+				PasswordRevisionDate string `json:"passwordRevisionDate"`
+			} `json:"login"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	stats := &VaultStats{Categories: make(map[string]int)}
+	for _, item := range export.Items {
+		category := BitwardenItemType(item.Type)
+		stats.add(category)
+
+		// The password revision date is when the password last changed; items whose
+		// password never changed only have the item revision date
+		if category == "login" {
+			modified := item.RevisionDate
+			if item.Login != nil && item.Login.PasswordRevisionDate != "" {
+				modified = item.Login.PasswordRevisionDate
+			}
+			stats.addLoginModified(modified)
+		}
+	}
+
+	return stats, nil
+}
+
+// BitwardenItemType returns the normalized category for a Bitwarden item type
+func BitwardenItemType(itemType int) string {
+	if category, ok := bitwardenItemTypes[itemType]; ok {
+		return category
+	}
+	return "other"
+}
@@ -1,7 +1,22 @@
 package managers
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultCommandTimeout bounds a single password manager CLI invocation
+	DefaultCommandTimeout = 5 * time.Minute
+	// commandWaitDelay limits how long to wait for output pipes after a CLI is killed
+	commandWaitDelay = 5 * time.Second
 )
 
 // Manager represents a password manager interface
@@ -20,6 +35,200 @@ type Manager interface {
 
 	// GetItemCount returns the number of items in the vault (if available)
 	GetItemCount() (int, error)
+
+	// GetStats parses an export file and returns item statistics
+	GetStats(exportPath string) (*VaultStats, error)
+
+	// SetContext sets the context that cancels running CLI commands
+	SetContext(ctx context.Context)
+
+	// SetTimeout sets the maximum duration of a single CLI command
+	SetTimeout(timeout time.Duration)
+}
+
+// ChangeDetector is implemented by managers that can tell whether their vault
+// changed without exporting it
+type ChangeDetector interface {
+	// Revision returns a fingerprint of the vault that changes whenever an item
+	// is added, edited or deleted. It lists item metadata, never secrets.
+	Revision() (string, error)
+}
+
+// revisionFingerprint hashes the entries describing a vault's items, in any order
+func revisionFingerprint(entries []string) string {
+	sort.Strings(entries)
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry))
+		hash.Write([]byte{'\n'})
+	}
+	return fmt.Sprintf("%d:%x", len(entries), hash.Sum(nil))
+}
+
+// VaultStats summarizes what an export contains
+type VaultStats struct {
+	TotalItems int            `json:"total_items"`
+	Logins     int            `json:"logins"`
+	Notes      int            `json:"notes"`
+	Cards      int            `json:"cards"`
+	Identities int            `json:"identities"`
+	Other      int            `json:"other"`
+	Categories map[string]int `json:"categories"`
+	// LoginsModified counts logins by the month ("2006-01") their credentials were last changed
+	LoginsModified map[string]int `json:"logins_modified,omitempty"`
+}
+
+// modifiedMonthFormat is the key format of VaultStats.LoginsModified
+const modifiedMonthFormat = "2006-01"
+
+// addLoginModified records when a login's credentials were last changed; unparseable
+// or missing timestamps are skipped
+func (s *VaultStats) addLoginModified(timestamp string) {
+	modified, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return
+	}
+	if s.LoginsModified == nil {
+		s.LoginsModified = make(map[string]int)
+	}
+	s.LoginsModified[modified.UTC().Format(modifiedMonthFormat)]++
+}
+
+// StaleLogins returns how many logins with a known modification date were last
+// changed before the cutoff, counted by month, and how many have a known date
+func (s *VaultStats) StaleLogins(cutoff time.Time) (stale, dated int) {
+	cutoffMonth := cutoff.UTC().Format(modifiedMonthFormat)
+	for month, count := range s.LoginsModified {
+		dated += count
+		if month < cutoffMonth {
+			stale += count
+		}
+	}
+	return stale, dated
+}
+
+// OldestLoginModified returns the month of the least recently changed login, or "" if unknown
+func (s *VaultStats) OldestLoginModified() string {
+	oldest := ""
+	for month := range s.LoginsModified {
+		if oldest == "" || month < oldest {
+			oldest = month
+		}
+	}
+	return oldest
+}
+
+// add counts an item in the given normalized category
+func (s *VaultStats) add(category string) {
+	if s.Categories == nil {
+		s.Categories = make(map[string]int)
+	}
+	s.TotalItems++
+	s.Categories[category]++
+
+	switch category {
+	case "login":
+		s.Logins++
+	case "secure_note":
+		s.Notes++
+	case "card":
+		s.Cards++
+	case "identity":
+		s.Identities++
+	default:
+		s.Other++
+	}
+}
+
+// cliRunner holds the execution settings shared by CLI-backed managers
+type cliRunner struct {
+	ctx     context.Context
+	timeout time.Duration
+	// env holds variables added to the inherited environment of every CLI command
+	env []string
+}
+
+// SetContext sets the context that cancels running CLI commands
+func (r *cliRunner) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// SetTimeout sets the maximum duration of a single CLI command (0 disables the timeout)
+func (r *cliRunner) SetTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// setEnv sets a variable in the environment of CLI commands, overriding the inherited value
+func (r *cliRunner) setEnv(key, value string) {
+	r.env = append(r.env, key+"="+value)
+}
+
+// command creates a CLI command with the runner's environment
+func (r *cliRunner) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	if len(r.env) > 0 {
+		// Later entries win, so explicit values replace ambient ones
+		cmd.Env = append(os.Environ(), r.env...)
+	}
+	return cmd
+}
+
+// context returns the manager context, defaulting to the background context
+func (r *cliRunner) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// interactiveCommand creates a command bound only by the manager context,
+// since interactive commands wait on the user and must not time out
+func (r *cliRunner) interactiveCommand(name string, args ...string) *exec.Cmd {
+	return r.command(r.context(), name, args...)
+}
+
+// combinedOutput runs a CLI command bounded by the manager context and timeout.
+// The process is killed when either expires so a hung CLI cannot block a backup.
+func (r *cliRunner) combinedOutput(manager, name string, args ...string) ([]byte, error) {
+	return r.combinedOutputWithInput(manager, nil, name, args...)
+}
+
+// combinedOutputWithInput runs a CLI command like combinedOutput, writing stdin to its
+// standard input. Secrets passed this way never appear in the process list.
+func (r *cliRunner) combinedOutputWithInput(manager string, stdin []byte, name string, args ...string) ([]byte, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(r.context(), r.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(r.context())
+	}
+	defer cancel()
+
+	cmd := r.command(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	killProcessGroup(cmd)
+	output, err := cmd.CombinedOutput()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) && r.context().Err() == nil {
+			command := name
+			if len(args) > 0 {
+				command = args[0]
+			}
+			return output, &CommandTimeoutError{
+				Manager: manager,
+				Command: command,
+				Timeout: r.timeout,
+			}
+		}
+		return output, fmt.Errorf("%s: command cancelled: %w", manager, ctxErr)
+	}
+
+	return output, err
 }
 
 // ManagerNotAuthenticatedError indicates the user is not authenticated
@@ -55,3 +264,38 @@ func (e *ExportError) Error() string {
 func (e *ExportError) Unwrap() error {
 	return e.Err
 }
+
+// ImportError indicates an error while importing items into a vault
+type ImportError struct {
+	Manager string
+	Err     error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%s import failed: %v", e.Manager, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// CommandTimeoutError indicates a manager CLI command exceeded its timeout
+type CommandTimeoutError struct {
+	Manager string
+	Command string
+	Timeout time.Duration
+}
+
+func (e *CommandTimeoutError) Error() string {
+	return fmt.Sprintf("%s: '%s' timed out after %s", e.Manager, e.Command, e.Timeout)
+}
+
+// ExportValidationError indicates an export failed sanity checks and was not uploaded
+type ExportValidationError struct {
+	Manager string
+	Reason  string
+}
+
+func (e *ExportValidationError) Error() string {
+	return fmt.Sprintf("%s export validation failed: %s", e.Manager, e.Reason)
+}
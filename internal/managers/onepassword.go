@@ -5,21 +5,28 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
 // OnePassword represents the 1Password password manager
 type OnePassword struct {
+	cliRunner
 	CLIPath string
 	Account string
+	// Session is an explicit session token passed with --session
+	Session string
+	// ServiceAccountToken authenticates as a 1Password service account
+	ServiceAccountToken string
 }
 
 // NewOnePassword creates a new 1Password manager instance
 func NewOnePassword(cliPath, account string) *OnePassword {
 	return &OnePassword{
-		CLIPath: cliPath,
-		Account: account,
+		cliRunner: cliRunner{timeout: DefaultCommandTimeout},
+		CLIPath:   cliPath,
+		Account:   account,
 	}
 }
 
@@ -28,6 +35,18 @@ func (o *OnePassword) Name() string {
 	return "1password"
 }
 
+// SetSession passes a session token to every op command with --session
+func (o *OnePassword) SetSession(token string) {
+	o.Session = token
+}
+
+// SetServiceAccountToken authenticates every op command as a service account
+// through OP_SERVICE_ACCOUNT_TOKEN, which is the only way op accepts it
+func (o *OnePassword) SetServiceAccountToken(token string) {
+	o.ServiceAccountToken = token
+	o.setEnv("OP_SERVICE_ACCOUNT_TOKEN", token)
+}
+
 // IsInstalled checks if the 1Password CLI is installed
 func (o *OnePassword) IsInstalled() bool {
 	return utils.IsCommandAvailable(o.CLIPath)
@@ -43,14 +62,7 @@ func (o *OnePassword) IsAuthenticated() (bool, error) {
 	}
 
 	// Run 'op whoami' to check authentication
-	var cmd *exec.Cmd
-	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "whoami", "--account", o.Account)
-	} else {
-		cmd = exec.Command(o.CLIPath, "whoami")
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("whoami")...)
 	if err != nil {
 		// If whoami fails, user is not signed in
 		return false, &ManagerNotAuthenticatedError{
@@ -123,6 +135,14 @@ func (o *OnePassword) exportItems(outputPath string, fullExport bool, progressCa
 			// Get full details for each item
 			totalItems := len(items)
 			for idx, item := range items {
+				// Stop promptly when the backup is interrupted
+				if err := o.context().Err(); err != nil {
+					return &ExportError{
+						Manager: o.Name(),
+						Err:     fmt.Errorf("export cancelled: %w", err),
+					}
+				}
+
 				itemID, ok := item["id"].(string)
 				if !ok {
 					continue
@@ -203,6 +223,36 @@ func (o *OnePassword) GetItemCount() (int, error) {
 	return totalCount, nil
 }
 
+// Revision fingerprints the items of every vault by version, from a single
+// item listing that doesn't fetch item details
+func (o *OnePassword) Revision() (string, error) {
+	if _, err := o.IsAuthenticated(); err != nil {
+		return "", err
+	}
+
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("item", "list", "--format", "json")...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list items: %w (output: %s)", err, string(output))
+	}
+	var items []struct {
+		ID        string `json:"id"`
+		Version   int    `json:"version"`
+		UpdatedAt string `json:"updated_at"`
+		Vault     struct {
+			ID string `json:"id"`
+		} `json:"vault"`
+	}
+	if err := json.Unmarshal(output, &items); err != nil {
+		return "", fmt.Errorf("failed to parse items: %w", err)
+	}
+
+	entries := make([]string, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, fmt.Sprintf("%s/%s %d %s", item.Vault.ID, item.ID, item.Version, item.UpdatedAt))
+	}
+	return revisionFingerprint(entries), nil
+}
+
 // Vault represents a 1Password vault
 type Vault struct {
 	ID   string `json:"id"`
@@ -211,14 +261,7 @@ type Vault struct {
 
 // listVaults lists all available vaults
 func (o *OnePassword) listVaults() ([]Vault, error) {
-	var cmd *exec.Cmd
-	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "vault", "list", "--format", "json", "--account", o.Account)
-	} else {
-		cmd = exec.Command(o.CLIPath, "vault", "list", "--format", "json")
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("vault", "list", "--format", "json")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list vaults: %w (output: %s)", err, string(output))
 	}
@@ -233,14 +276,7 @@ func (o *OnePassword) listVaults() ([]Vault, error) {
 
 // listItemsInVault lists all items in a specific vault
 func (o *OnePassword) listItemsInVault(vaultID string) ([]map[string]interface{}, error) {
-	var cmd *exec.Cmd
-	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "item", "list", "--vault", vaultID, "--format", "json", "--account", o.Account)
-	} else {
-		cmd = exec.Command(o.CLIPath, "item", "list", "--vault", vaultID, "--format", "json")
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("item", "list", "--vault", vaultID, "--format", "json")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list items: %w (output: %s)", err, string(output))
 	}
@@ -253,16 +289,31 @@ func (o *OnePassword) listItemsInVault(vaultID string) ([]map[string]interface{}
 	return items, nil
 }
 
-// getItemDetails gets full details for a specific item including passwords and sensitive fields
-func (o *OnePassword) getItemDetails(itemID string) (map[string]interface{}, error) {
-	var cmd *exec.Cmd
-	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "item", "get", itemID, "--format", "json", "--account", o.Account)
-	} else {
-		cmd = exec.Command(o.CLIPath, "item", "get", itemID, "--format", "json")
+// ItemSummary identifies a 1Password item without its fields
+type ItemSummary struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+// ListItems lists the items of a vault, by ID or name, without their fields
+func (o *OnePassword) ListItems(vault string) ([]ItemSummary, error) {
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("item", "list", "--vault", vault, "--format", "json")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	var items []ItemSummary
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse items: %w", err)
 	}
 
-	output, err := cmd.CombinedOutput()
+	return items, nil
+}
+
+// getItemDetails gets full details for a specific item including passwords and sensitive fields
+func (o *OnePassword) getItemDetails(itemID string) (map[string]interface{}, error) {
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("item", "get", itemID, "--format", "json")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item: %w (output: %s)", err, string(output))
 	}
@@ -287,9 +338,9 @@ func (o *OnePassword) SignIn() error {
 	fmt.Println("Please sign in to 1Password:")
 	var cmd *exec.Cmd
 	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "signin", "--account", o.Account)
+		cmd = o.interactiveCommand(o.CLIPath, "signin", "--account", o.Account)
 	} else {
-		cmd = exec.Command(o.CLIPath, "signin")
+		cmd = o.interactiveCommand(o.CLIPath, "signin")
 	}
 
 	cmd.Stdin = os.Stdin
@@ -303,6 +354,26 @@ func (o *OnePassword) SignIn() error {
 	return nil
 }
 
+// CreateItem creates an item from a JSON item template, in the given vault or the
+// account's default vault if vault is empty. The template is piped to 'op item
+// create' so its secrets are never written to disk.
+func (o *OnePassword) CreateItem(template []byte, vault string) error {
+	args := []string{"item", "create", "--format", "json"}
+	if vault != "" {
+		args = append(args, "--vault", vault)
+	}
+
+	output, err := o.combinedOutputWithInput(o.Name(), template, o.CLIPath, o.args(args...)...)
+	if err != nil {
+		return &ImportError{
+			Manager: o.Name(),
+			Err:     fmt.Errorf("failed to create item: %w (output: %s)", err, strings.TrimSpace(string(output))),
+		}
+	}
+
+	return nil
+}
+
 // GetUserInfo returns information about the signed-in user
 func (o *OnePassword) GetUserInfo() (string, error) {
 	if !o.IsInstalled() {
@@ -312,17 +383,67 @@ func (o *OnePassword) GetUserInfo() (string, error) {
 		}
 	}
 
-	var cmd *exec.Cmd
-	if o.Account != "" {
-		cmd = exec.Command(o.CLIPath, "whoami", "--account", o.Account)
-	} else {
-		cmd = exec.Command(o.CLIPath, "whoami")
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := o.combinedOutput(o.Name(), o.CLIPath, o.args("whoami")...)
 	if err != nil {
 		return "", fmt.Errorf("failed to get user info: %w", err)
 	}
 
 	return string(output), nil
 }
+
+// args appends the configured account and session to CLI arguments.
+// Service accounts are bound to a single account, so --account is omitted for them.
+func (o *OnePassword) args(args ...string) []string {
+	if o.Account != "" && o.ServiceAccountToken == "" {
+		args = append(args, "--account", o.Account)
+	}
+	if o.Session != "" {
+		args = append(args, "--session", o.Session)
+	}
+	return args
+}
+
+// GetStats parses a 1Password export and returns item statistics
+func (o *OnePassword) GetStats(exportPath string) (*VaultStats, error) {
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var items []struct {
+		Category  string `json:"category"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	stats := &VaultStats{Categories: make(map[string]int)}
+	for _, item := range items {
+		category := OnePasswordCategory(item.Category)
+		stats.add(category)
+		if category == "login" {
+			stats.addLoginModified(item.UpdatedAt)
+		}
+	}
+
+	return stats, nil
+}
+
+// OnePasswordCategory maps 1Password categories to normalized categories
+func OnePasswordCategory(category string) string {
+	switch strings.ToUpper(category) {
+	case "LOGIN", "PASSWORD":
+		return "login"
+	case "SECURE_NOTE":
+		return "secure_note"
+	case "CREDIT_CARD":
+		return "card"
+	case "IDENTITY":
+		return "identity"
+	case "":
+		return "other"
+	default:
+		return strings.ToLower(category)
+	}
+}
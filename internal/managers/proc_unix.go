@@ -0,0 +1,21 @@
+//go:build !windows
+
+package managers
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup runs the command in its own process group and kills the
+// whole group on cancellation, so helpers spawned by the CLI don't outlive it
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// stdinPath is the path a CLI reads its standard input from when it only takes
+// a file
+const stdinPath = "/dev/stdin"
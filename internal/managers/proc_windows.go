@@ -0,0 +1,12 @@
+//go:build windows
+
+package managers
+
+import "os/exec"
+
+// killProcessGroup keeps the default behaviour on Windows, where cancellation
+// kills the CLI process itself
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// stdinPath is empty: Windows has no path for a process's standard input
+const stdinPath = ""
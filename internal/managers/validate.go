@@ -0,0 +1,60 @@
+package managers
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CountExportItems parses an export and returns its item count
+func CountExportItems(data []byte) (int, error) {
+	// Bitwarden exports are an object with an items array
+	var bitwarden struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &bitwarden); err == nil {
+		return len(bitwarden.Items), nil
+	}
+
+	// 1Password exports are an array of items
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return 0, fmt.Errorf("vault data is not valid JSON: %w", err)
+	}
+	return len(items), nil
+}
+
+// ValidateExport checks that an export is complete before it is uploaded.
+// The export must parse, must not be empty unless allowEmpty is set, and its
+// item count must be within tolerancePercent of the count reported by the CLI.
+// An expected count of 0 or less skips the comparison.
+func ValidateExport(manager string, data []byte, expected, tolerancePercent int, allowEmpty bool) (int, error) {
+	count, err := CountExportItems(data)
+	if err != nil {
+		return 0, &ExportValidationError{
+			Manager: manager,
+			Reason:  fmt.Sprintf("export is truncated or malformed: %v", err),
+		}
+	}
+
+	if count == 0 && !allowEmpty {
+		return 0, &ExportValidationError{
+			Manager: manager,
+			Reason:  "export contains no items",
+		}
+	}
+
+	if expected > 0 {
+		diff := expected - count
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff*100 > expected*tolerancePercent {
+			return count, &ExportValidationError{
+				Manager: manager,
+				Reason:  fmt.Sprintf("export contains %d items but the vault reports %d (tolerance %d%%)", count, expected, tolerancePercent),
+			}
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"net/http"
+	"time"
+)
+
+// Limits of Discord embeds
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+)
+
+// discordColors are the embed colors of each severity
+var discordColors = map[Severity]int{
+	SeverityInfo:    0x2ecc71,
+	SeverityWarning: 0xf1c40f,
+	SeverityError:   0xe74c3c,
+}
+
+// Discord posts notifications to a Discord channel webhook
+type Discord struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+// NewDiscord creates a new Discord notifier
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{
+		WebhookURL: webhookURL,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the name of the notification channel
+func (d *Discord) Name() string {
+	return "Discord"
+}
+
+// discordPayload is a webhook message with one embed, colored by severity
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Color       int       `json:"color"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Send posts the message to the Discord webhook
+func (d *Discord) Send(message Message) error {
+	return postJSON(d.client, d.Name(), d.WebhookURL, discordPayload{
+		Username: "stashr",
+		Embeds: []discordEmbed{{
+			Title:       truncate(message.Subject, discordTitleLimit),
+			Description: truncate(message.Body, discordDescriptionLimit),
+			Color:       discordColors[message.Severity],
+			Timestamp:   message.SentAt,
+		}},
+	})
+}
+
+// truncate shortens text to at most limit characters, marking the cut with an ellipsis
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email sends notifications through an SMTP server. Servers that support
+// STARTTLS are upgraded automatically by net/smtp before authenticating.
+type Email struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NewEmail creates a new SMTP email notifier
+func NewEmail(host string, port int, username, password, from string, to []string) *Email {
+	return &Email{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+		To:       to,
+	}
+}
+
+// Name returns the name of the notification channel
+func (e *Email) Name() string {
+	return "Email"
+}
+
+// Send delivers the message as a plain text email
+func (e *Email) Send(message Message) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	if err := smtp.SendMail(addr, auth, e.From, e.To, e.build(message)); err != nil {
+		return &SendError{Channel: e.Name(), Err: err}
+	}
+
+	return nil
+}
+
+// build formats the message as an RFC 5322 email
+func (e *Email) build(message Message) []byte {
+	sentAt := message.SentAt
+	if sentAt.IsZero() {
+		sentAt = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", sentAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity ranks how much attention a notification needs. Channels can be
+// limited to messages of a minimum severity.
+type Severity int
+
+const (
+	// SeverityInfo is for routine messages: digests and successful backups
+	SeverityInfo Severity = iota
+	// SeverityWarning is for problems that will cause failures if left alone
+	SeverityWarning
+	// SeverityError is for failed backups
+	SeverityError
+)
+
+// severityNames are the names of severities in config files
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < SeverityInfo || s > SeverityError {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses a severity name; an empty name is SeverityInfo
+func ParseSeverity(name string) (Severity, error) {
+	if name == "" {
+		return SeverityInfo, nil
+	}
+	for i, known := range severityNames {
+		if strings.EqualFold(name, known) {
+			return Severity(i), nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("invalid severity: %s (use: %s)", name, strings.Join(severityNames, ", "))
+}
+
+// Notifier represents a notification channel interface
+type Notifier interface {
+	// Name returns the name of the notification channel
+	Name() string
+
+	// Send delivers a message with a subject and a plain text body
+	Send(message Message) error
+}
+
+// Message is a notification delivered to one or more channels
+type Message struct {
+	Subject  string
+	Body     string
+	Severity Severity
+	SentAt   time.Time
+}
+
+// SendError indicates a notification could not be delivered
+type SendError struct {
+	Channel string
+	Err     error
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("%s notification failed: %v", e.Channel, e.Err)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// SendAll delivers a message to every notifier and returns the errors of the
+// channels that failed. One failing channel does not stop the others.
+func SendAll(notifiers []Notifier, message Message) []error {
+	if message.SentAt.IsZero() {
+		message.SentAt = time.Now()
+	}
+
+	var errs []error
+	for _, notifier := range notifiers {
+		if err := notifier.Send(message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PingEvent is the stage of a backup run reported to a dead-man switch
+type PingEvent int
+
+const (
+	// PingStart reports that a backup started, so the service can time it
+	PingStart PingEvent = iota
+	// PingSuccess reports that a backup finished
+	PingSuccess
+	// PingFailure reports that a backup failed
+	PingFailure
+)
+
+// Dead-man switch services
+const (
+	// ServiceHealthchecks is Healthchecks.io or a self-hosted Healthchecks instance
+	ServiceHealthchecks = "healthchecks"
+	// ServiceUptimeKuma is an Uptime Kuma push monitor
+	ServiceUptimeKuma = "uptime-kuma"
+)
+
+// healthchecksBodyLimit is how much of a message Healthchecks keeps with a ping
+const healthchecksBodyLimit = 10000
+
+// uptimeKumaMessageLimit keeps push messages short enough for a query string
+const uptimeKumaMessageLimit = 250
+
+// Ping reports backup runs to a dead-man switch service, which alerts when
+// pings stop arriving or report a failure
+type Ping struct {
+	Service string
+	URL     string
+	client  *http.Client
+}
+
+// NewPing creates a pinger for a Healthchecks check or Uptime Kuma push monitor
+func NewPing(service, url string) *Ping {
+	return &Ping{
+		Service: service,
+		URL:     url,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the name of the service
+func (p *Ping) Name() string {
+	if p.Service == ServiceUptimeKuma {
+		return "Uptime Kuma"
+	}
+	return "Healthchecks"
+}
+
+// Send pings the service for an event, with a message describing the run
+func (p *Ping) Send(event PingEvent, message string) error {
+	if p.Service == ServiceUptimeKuma {
+		return p.sendUptimeKuma(event, message)
+	}
+	return p.sendHealthchecks(event, message)
+}
+
+// sendHealthchecks posts to the ping URL, or its /start and /fail endpoints. The
+// body is stored with the ping and shown in the check's log.
+func (p *Ping) sendHealthchecks(event PingEvent, message string) error {
+	endpoint := strings.TrimSuffix(p.URL, "/")
+	switch event {
+	case PingStart:
+		endpoint += "/start"
+	case PingFailure:
+		endpoint += "/fail"
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(truncate(message, healthchecksBodyLimit)))
+	if err != nil {
+		return &SendError{Channel: p.Name(), Err: errors.New("invalid URL")}
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return post(p.client, p.Name(), req)
+}
+
+// sendUptimeKuma calls the push URL with status=up or status=down. Push monitors
+// have no start event, so starts aren't sent.
+func (p *Ping) sendUptimeKuma(event PingEvent, message string) error {
+	if event == PingStart {
+		return nil
+	}
+	endpoint, err := url.Parse(p.URL)
+	if err != nil {
+		return &SendError{Channel: p.Name(), Err: errors.New("invalid URL")}
+	}
+	status := "up"
+	if event == PingFailure {
+		status = "down"
+	}
+	// The push URL Uptime Kuma shows comes with placeholder status and msg values
+	query := endpoint.Query()
+	query.Set("status", status)
+	query.Set("msg", truncate(strings.Join(strings.Fields(message), " "), uptimeKumaMessageLimit))
+	query.Del("ping")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return &SendError{Channel: p.Name(), Err: errors.New("invalid URL")}
+	}
+	return post(p.client, p.Name(), req)
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"net/http"
+	"time"
+)
+
+// slackColors are the attachment colors of each severity
+var slackColors = map[Severity]string{
+	SeverityInfo:    "#2eb67d",
+	SeverityWarning: "#ecb22e",
+	SeverityError:   "#e01e5a",
+}
+
+// Slack posts notifications to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	client     *http.Client
+}
+
+// NewSlack creates a new Slack notifier
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{
+		WebhookURL: webhookURL,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the name of the notification channel
+func (s *Slack) Name() string {
+	return "Slack"
+}
+
+// slackPayload is an incoming webhook message with one attachment, colored by severity
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string `json:"color"`
+	Text   string `json:"text"`
+	Footer string `json:"footer"`
+	TS     int64  `json:"ts"`
+}
+
+// Send posts the message to the Slack webhook
+func (s *Slack) Send(message Message) error {
+	return postJSON(s.client, s.Name(), s.WebhookURL, slackPayload{
+		Text: "*" + message.Subject + "*",
+		Attachments: []slackAttachment{{
+			Color:  slackColors[message.Severity],
+			Text:   message.Body,
+			Footer: "stashr",
+			TS:     message.SentAt.Unix(),
+		}},
+	})
+}
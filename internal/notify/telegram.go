@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API endpoint
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// telegramTextLimit is the longest message the Bot API accepts
+const telegramTextLimit = 4096
+
+// telegramIcons lead messages by severity, since Telegram messages have no color
+var telegramIcons = map[Severity]string{
+	SeverityInfo:    "ℹ️",
+	SeverityWarning: "⚠️",
+	SeverityError:   "🚨",
+}
+
+// Telegram sends notifications to a chat through a Telegram bot
+type Telegram struct {
+	// APIURL is the Bot API endpoint, DefaultTelegramAPI unless self-hosted
+	APIURL   string
+	BotToken string
+	ChatID   string
+	client   *http.Client
+}
+
+// NewTelegram creates a new Telegram notifier
+func NewTelegram(botToken, chatID string) *Telegram {
+	return &Telegram{
+		APIURL:   DefaultTelegramAPI,
+		BotToken: botToken,
+		ChatID:   chatID,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the name of the notification channel
+func (t *Telegram) Name() string {
+	return "Telegram"
+}
+
+// telegramMessage is the sendMessage request. Plain text is sent, so subjects
+// and bodies need no escaping.
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// telegramResponse is the Bot API's reply
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Send sends the message to the chat
+func (t *Telegram) Send(message Message) error {
+	payload, err := json.Marshal(telegramMessage{
+		ChatID:                t.ChatID,
+		Text:                  truncate(telegramIcons[message.Severity]+" "+message.Subject+"\n\n"+message.Body, telegramTextLimit),
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return &SendError{Channel: t.Name(), Err: err}
+	}
+
+	endpoint := t.APIURL + "/bot" + url.PathEscape(t.BotToken) + "/sendMessage"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return &SendError{Channel: t.Name(), Err: errors.New("invalid API URL")}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// The token is part of the URL, so errors must not include it
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return &SendError{Channel: t.Name(), Err: withoutURL(err)}
+	}
+	defer resp.Body.Close()
+
+	// The API explains rejected messages in the body (e.g. "chat not found")
+	var reply telegramResponse
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(body, &reply); err != nil || !reply.OK {
+		description := reply.Description
+		if description == "" {
+			description = string(bytes.TrimSpace(body))
+		}
+		return &SendError{Channel: t.Name(), Err: fmt.Errorf("unexpected status %s: %s", resp.Status, description)}
+	}
+	return nil
+}
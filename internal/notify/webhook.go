@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Webhook posts notifications as JSON to an HTTP endpoint
+type Webhook struct {
+	URL string
+	// Identity names the sending installation; empty sends unsigned payloads
+	Identity string
+	// Secret is the HMAC key signed payloads are signed with
+	Secret []byte
+	client *http.Client
+}
+
+// NewWebhook creates a new webhook notifier
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewSignedWebhook creates a webhook notifier that identifies the installation and
+// signs every payload with an HMAC secret, so receivers can verify its origin
+func NewSignedWebhook(url, identity string, secret []byte) *Webhook {
+	webhook := NewWebhook(url)
+	webhook.Identity = identity
+	webhook.Secret = secret
+	return webhook
+}
+
+// Name returns the name of the notification channel
+func (w *Webhook) Name() string {
+	return "Webhook"
+}
+
+// webhookPayload is the JSON body posted to the webhook. The text field makes
+// the payload usable as-is with Slack and Mattermost incoming webhooks.
+type webhookPayload struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Text    string `json:"text"`
+	// Severity is "info", "warning" or "error"
+	Severity string    `json:"severity"`
+	SentAt   time.Time `json:"sent_at"`
+	// Identity tells payloads from several hosts apart
+	Identity string `json:"identity,omitempty"`
+}
+
+// Send posts the message to the webhook URL
+func (w *Webhook) Send(message Message) error {
+	payload, err := json.Marshal(webhookPayload{
+		Subject:  message.Subject,
+		Body:     message.Body,
+		Text:     message.Subject + "\n\n" + message.Body,
+		Severity: message.Severity.String(),
+		SentAt:   message.SentAt,
+		Identity: w.Identity,
+	})
+	if err != nil {
+		return &SendError{Channel: w.Name(), Err: err}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return &SendError{Channel: w.Name(), Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Stashr-Identity", w.Identity)
+		req.Header.Set("X-Stashr-Timestamp", timestamp)
+		req.Header.Set("X-Stashr-Signature", "sha256="+SignPayload(w.Secret, timestamp, payload))
+	}
+
+	return post(w.client, w.Name(), req)
+}
+
+// postJSON posts a JSON payload for a channel
+func postJSON(client *http.Client, channel, endpoint string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &SendError{Channel: channel, Err: err}
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return &SendError{Channel: channel, Err: errors.New("invalid URL")}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(client, channel, req)
+}
+
+// post sends a request for a channel and checks the response status. Webhook
+// URLs and bot tokens are secrets, so errors never include the URL.
+func post(client *http.Client, channel string, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return &SendError{Channel: channel, Err: withoutURL(err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &SendError{
+			Channel: channel,
+			Err:     fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body)),
+		}
+	}
+
+	return nil
+}
+
+// SignPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>". Receivers
+// recompute it from the X-Stashr-Timestamp header and the raw body, and should
+// reject old timestamps to prevent replays.
+func SignPayload(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// withoutURL strips the request URL net/http adds to errors
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
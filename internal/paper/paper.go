@@ -0,0 +1,182 @@
+// Package paper prints backups for cold storage, as QR codes and as text that
+// can be typed back in, and reassembles them from the scanned or typed chunks.
+//
+// A document is split into chunks, each a line of text such as
+//
+//	STASHR:B1:K3J5QW2A:003/120:MFRGGZDF...:1A2B3C4D
+//
+// giving the kind of document (B for a backup, K for a key) and the format
+// version, the document ID, the chunk's number and the number of chunks, the
+// chunk's data in base32 and the CRC-32 of that data. Lines only use characters
+// that QR codes hold in alphanumeric mode, so scanning a code with any app gives
+// the same line that is printed below it.
+package paper
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is what a paper document holds
+type Kind string
+
+const (
+	// KindBackup is an encrypted backup file
+	KindBackup Kind = "B"
+	// KindKey is an encryption password
+	KindKey Kind = "K"
+)
+
+const (
+	// formatVersion is the version of the chunk format
+	formatVersion = 1
+
+	// chunkPrefix starts every chunk
+	chunkPrefix = "STASHR"
+
+	// chunkBytes is the data in a chunk. Its 272 base32 characters and the
+	// 36 of the rest of the line fit a QR code.
+	chunkBytes = 170
+
+	// MaxChunks is the most chunks a document can be split into
+	MaxChunks = 999
+)
+
+// ErrNoChunks is returned when a text contains no chunk of a paper document
+var ErrNoChunks = errors.New("no paper backup chunks found")
+
+// encoding is base32 without padding, whose alphabet QR codes hold in alphanumeric mode
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// chunkPattern matches a chunk in text with whitespace removed
+var chunkPattern = regexp.MustCompile(chunkPrefix + `:([A-Z])(\d+):([A-Z2-7]{8}):(\d{3})/(\d{3}):([A-Z2-7]*):([0-9A-F]{8})`)
+
+// Document is what a paper backup holds
+type Document struct {
+	Kind Kind
+	// Name is the backup's filename; keys have none
+	Name string
+	Data []byte
+}
+
+// payload is the document as split into chunks: the length of the name as a
+// uvarint, the name and the data
+func (d *Document) payload() []byte {
+	payload := binary.AppendUvarint(nil, uint64(len(d.Name)))
+	payload = append(payload, d.Name...)
+	return append(payload, d.Data...)
+}
+
+// ID identifies the document, so chunks of different documents aren't mixed up
+func (d *Document) ID() string {
+	return documentID(d.payload())
+}
+
+// documentID returns the ID of a payload: the first 40 bits of its SHA-256 checksum
+func documentID(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return encoding.EncodeToString(sum[:5])
+}
+
+// Chunks splits the document into the lines printed on paper
+func (d *Document) Chunks() ([]string, error) {
+	payload := d.payload()
+	count := (len(payload) + chunkBytes - 1) / chunkBytes
+	if count > MaxChunks {
+		return nil, fmt.Errorf("%d bytes need %d chunks, more than the %d a paper backup can hold", len(payload), count, MaxChunks)
+	}
+
+	id := documentID(payload)
+	chunks := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		data := payload[i*chunkBytes : min((i+1)*chunkBytes, len(payload))]
+		chunks = append(chunks, fmt.Sprintf("%s:%s%d:%s:%03d/%03d:%s:%08X",
+			chunkPrefix, d.Kind, formatVersion, id, i+1, count, encoding.EncodeToString(data), crc32.ChecksumIEEE(data)))
+	}
+	return chunks, nil
+}
+
+// Reassemble finds the chunks of a document in text, e.g. the lines of scanned
+// QR codes or typed from the printout, in any order and with any whitespace. It
+// reports every damaged or missing chunk at once, so they can be rescanned
+// together.
+func Reassemble(text string) (*Document, error) {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, text)
+
+	matches := chunkPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil, ErrNoChunks
+	}
+
+	kind, id, count := Kind(matches[0][1]), matches[0][3], matches[0][5]
+	if kind != KindBackup && kind != KindKey {
+		return nil, fmt.Errorf("unknown paper backup kind %q", kind)
+	}
+	chunks := make(map[int][]byte)
+	damaged := make(map[int]bool)
+	for _, match := range matches {
+		if match[2] != strconv.Itoa(formatVersion) {
+			return nil, fmt.Errorf("unsupported paper backup format version %s (this stashr reads version %d)", match[2], formatVersion)
+		}
+		if Kind(match[1]) != kind || match[3] != id || match[5] != count {
+			return nil, fmt.Errorf("found chunks of more than one paper backup (%s and %s); import them separately", id, match[3])
+		}
+		number, _ := strconv.Atoi(match[4])
+		data, err := encoding.DecodeString(match[6])
+		if err != nil || fmt.Sprintf("%08X", crc32.ChecksumIEEE(data)) != match[7] {
+			damaged[number] = true
+			continue
+		}
+		chunks[number] = data
+	}
+
+	total, _ := strconv.Atoi(count)
+	var problems, missing []string
+	for number := 1; number <= total; number++ {
+		if _, ok := chunks[number]; ok {
+			continue
+		}
+		if damaged[number] {
+			problems = append(problems, fmt.Sprintf("chunk %03d is damaged (checksum mismatch)", number))
+		} else {
+			missing = append(missing, fmt.Sprintf("%03d", number))
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing chunks %s of %03d", strings.Join(missing, ", "), total))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("paper backup %s is incomplete: %s", id, strings.Join(problems, "; "))
+	}
+
+	var payload []byte
+	for number := 1; number <= total; number++ {
+		payload = append(payload, chunks[number]...)
+	}
+	if documentID(payload) != id {
+		return nil, fmt.Errorf("paper backup %s doesn't match its ID after reassembly", id)
+	}
+
+	nameLength, n := binary.Uvarint(payload)
+	if n <= 0 || uint64(len(payload)-n) < nameLength {
+		return nil, fmt.Errorf("paper backup %s is malformed", id)
+	}
+	return &Document{
+		Kind: kind,
+		Name: string(payload[n : n+int(nameLength)]),
+		Data: payload[n+int(nameLength):],
+	}, nil
+}
@@ -0,0 +1,181 @@
+package paper
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// Layout of the printout, in millimeters on A4
+const (
+	pageMargin = 15.0
+	// moduleSize is the width of a QR code module; 0.8mm scans well from any
+	// phone while three codes fit across the page
+	moduleSize   = 0.8
+	quietModules = 4
+	codeColumns  = 3
+	labelHeight  = 6.0
+
+	// The text copy is printed in groups of characters, like a product key
+	textGroupSize = 8
+	textGroups    = 10
+	textRowHeight = 4.0
+)
+
+// WritePDF renders a document as a printable PDF with a QR code per chunk,
+// followed by a text copy of the chunks for typing in without a scanner
+func WritePDF(doc *Document, path string, created time.Time) error {
+	chunks, err := doc.Chunks()
+	if err != nil {
+		return err
+	}
+	id := doc.ID()
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(false, pageMargin)
+	pageWidth, pageHeight := pdf.GetPageSize()
+	bottom := pageHeight - pageMargin
+
+	// Translate messages and convert them to the PDF core font encoding
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	t := func(message string) string {
+		return tr(i18n.T(message))
+	}
+	line := func(format string, args ...any) {
+		pdf.Cell(0, 5, fmt.Sprintf(t(format), args...))
+		pdf.Ln(5)
+	}
+	newPage := func() {
+		pdf.AddPage()
+		pdf.SetFont("Courier", "", 8)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 4, fmt.Sprintf(t("stashr paper backup %s - page %d"), id, pdf.PageNo()), "", 1, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+	}
+
+	newPage()
+	pdf.SetFont("Arial", "B", 20)
+	if doc.Kind == KindKey {
+		pdf.Cell(0, 12, t("STASHR PAPER KEY"))
+	} else {
+		pdf.Cell(0, 12, t("STASHR PAPER BACKUP"))
+	}
+	pdf.Ln(14)
+
+	pdf.SetFont("Arial", "", 10)
+	if doc.Kind == KindKey {
+		line("This page holds the encryption password of your backups.")
+		line("Store it apart from the backups: anyone with both can read your vault.")
+	} else {
+		line("Backup: %s", doc.Name)
+		line("Size: %s", utils.FormatBytes(int64(len(doc.Data))))
+		line("SHA-256: %s", utils.SHA256Hex(doc.Data))
+		line("The backup stays encrypted: restoring it needs the encryption password.")
+	}
+	line("Document ID: %s (%d chunks)", id, len(chunks))
+	line("Created: %s", created.Format("2006-01-02 15:04:05"))
+	pdf.Ln(3)
+	pdf.SetFont("Arial", "B", 10)
+	line("To restore:")
+	pdf.SetFont("Arial", "", 10)
+	line("  1. Scan every code, in any order, into a text file (one line per code),")
+	line("     or type the text copy at the end of this document")
+	line("  2. Run: stashr import-paper <file>")
+	pdf.Ln(5)
+
+	// QR codes, one per chunk
+	codeWidth := float64(qrSize+2*quietModules) * moduleSize
+	columnWidth := (pageWidth - 2*pageMargin) / codeColumns
+	rowHeight := codeWidth + labelHeight
+	column, y := 0, pdf.GetY()
+	for i, chunk := range chunks {
+		code, err := EncodeQR(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to encode chunk %d: %w", i+1, err)
+		}
+		if column == 0 {
+			if y+rowHeight > bottom {
+				newPage()
+			}
+			y = pdf.GetY()
+		}
+
+		x := pageMargin + float64(column)*columnWidth
+		drawQR(pdf, code, x+(columnWidth-codeWidth)/2, y)
+		pdf.SetXY(x, y+codeWidth)
+		pdf.SetFont("Courier", "B", 9)
+		pdf.CellFormat(columnWidth, 4, fmt.Sprintf("%03d/%03d", i+1, len(chunks)), "", 0, "C", false, 0, "")
+
+		if column++; column == codeColumns {
+			column = 0
+			pdf.SetXY(pageMargin, y+rowHeight)
+		}
+	}
+
+	// The same chunks as text
+	newPage()
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(0, 8, t("Text copy"))
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "", 9)
+	pdf.Cell(0, 5, t("Spaces and line breaks don't matter; each chunk starts with STASHR and has its own checksum."))
+	pdf.Ln(8)
+	for _, chunk := range chunks {
+		var rows []string
+		for start := 0; start < len(chunk); start += textGroupSize * textGroups {
+			row := chunk[start:min(start+textGroupSize*textGroups, len(chunk))]
+			var groups string
+			for i := 0; i < len(row); i += textGroupSize {
+				groups += row[i:min(i+textGroupSize, len(row))] + " "
+			}
+			rows = append(rows, groups)
+		}
+		if pdf.GetY()+float64(len(rows))*textRowHeight > bottom {
+			newPage()
+		}
+		pdf.SetFont("Courier", "", 8)
+		for _, row := range rows {
+			pdf.Cell(0, textRowHeight, row)
+			pdf.Ln(textRowHeight)
+		}
+		pdf.Ln(2)
+	}
+
+	// The printout holds the backup or the key, so only the owner may read it
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create PDF: %w", err)
+	}
+	if err := pdf.OutputAndClose(file); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// drawQR draws a QR code with its quiet zone, its top left corner at x, y
+func drawQR(pdf *gofpdf.Fpdf, code *QRCode, x, y float64) {
+	pdf.SetFillColor(0, 0, 0)
+	offset := quietModules * moduleSize
+	for row, modules := range code.Modules {
+		// Runs of dark modules are drawn as one rectangle
+		for column := 0; column < len(modules); {
+			if !modules[column] {
+				column++
+				continue
+			}
+			start := column
+			for column < len(modules) && modules[column] {
+				column++
+			}
+			pdf.Rect(x+offset+float64(start)*moduleSize, y+offset+float64(row)*moduleSize,
+				float64(column-start)*moduleSize, moduleSize, "F")
+		}
+	}
+}
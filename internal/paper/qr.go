@@ -0,0 +1,53 @@
+package paper
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Every code of a paper backup is the same symbol: version 10 (57x57 modules)
+// with error correction level M, holding text in alphanumeric mode. That is
+// large enough for a useful chunk of data per code and small enough to scan
+// reliably from a printed page, and the fixed size keeps the page layout simple.
+const (
+	qrVersion = 10
+	qrSize    = 17 + 4*qrVersion
+
+	// qrMaxChars is how many alphanumeric characters a code holds
+	qrMaxChars = 311
+)
+
+// qrAlphanumeric are the characters of alphanumeric mode
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// QRCode is a QR code symbol; Modules[y][x] is true for dark modules
+type QRCode struct {
+	Modules [][]bool
+}
+
+// Size returns the width and height of the code in modules, without the quiet zone
+func (q *QRCode) Size() int {
+	return len(q.Modules)
+}
+
+// EncodeQR encodes text made of the characters 0-9, A-Z, space and $%*+-./: as a QR code
+func EncodeQR(text string) (*QRCode, error) {
+	if len(text) > qrMaxChars {
+		return nil, fmt.Errorf("text of %d characters does not fit a QR code (maximum %d)", len(text), qrMaxChars)
+	}
+	for _, c := range text {
+		if !strings.ContainsRune(qrAlphanumeric, c) {
+			return nil, fmt.Errorf("character %q can't be encoded in alphanumeric mode", c)
+		}
+	}
+
+	code, err := qrcode.NewWithForcedVersion(text, qrVersion, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	// The PDF draws the quiet zone itself
+	code.DisableBorder = true
+	return &QRCode{Modules: code.Bitmap()}, nil
+}
@@ -0,0 +1,164 @@
+package proof
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Git publishes proofs by appending them to a file in a git repository and
+// committing each one. Pushing to a remote the user does not control alone
+// (e.g. a protected branch) makes the history hard to rewrite.
+type Git struct {
+	RepoPath string
+	File     string
+	// Remote is pushed to after every commit; empty disables pushing
+	Remote string
+}
+
+// NewGit creates a git proof publisher
+func NewGit(repoPath, file, remote string) *Git {
+	return &Git{
+		RepoPath: repoPath,
+		File:     file,
+		Remote:   remote,
+	}
+}
+
+// Name returns the name of the location
+func (g *Git) Name() string {
+	return "git"
+}
+
+// Publish appends a record to the proof file, commits it and pushes if a remote is set
+func (g *Git) Publish(record Record) error {
+	if _, err := g.git("rev-parse", "--git-dir"); err != nil {
+		return &PublishError{
+			Location: g.Name(),
+			File:     record.Filename,
+			Err:      fmt.Errorf("%s is not a git repository (run: git init)", g.RepoPath),
+		}
+	}
+
+	path := filepath.Join(g.RepoPath, g.File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return &PublishError{Location: g.Name(), File: record.Filename, Err: err}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return &PublishError{Location: g.Name(), File: record.Filename, Err: err}
+	}
+	_, err = fmt.Fprintln(file, formatLine(record))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &PublishError{Location: g.Name(), File: record.Filename, Err: fmt.Errorf("failed to append proof: %w", err)}
+	}
+
+	if _, err := g.git("add", "--", g.File); err != nil {
+		return &PublishError{Location: g.Name(), File: record.Filename, Err: err}
+	}
+	if _, err := g.git("commit", "--quiet", "-m", "stashr: proof for "+record.Filename, "--", g.File); err != nil {
+		return &PublishError{Location: g.Name(), File: record.Filename, Err: err}
+	}
+
+	if g.Remote != "" {
+		if _, err := g.git("push", "--quiet", g.Remote, "HEAD"); err != nil {
+			return &PublishError{
+				Location: g.Name(),
+				File:     record.Filename,
+				Err:      fmt.Errorf("committed but failed to push to %s: %w", g.Remote, err),
+			}
+		}
+	}
+
+	return nil
+}
+
+// History returns every committed record with the commit that added it, oldest first.
+// Lines that are not committed yet are not published and are skipped.
+func (g *Git) History() ([]Publication, error) {
+	if _, err := os.Stat(filepath.Join(g.RepoPath, g.File)); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	output, err := g.git("blame", "--line-porcelain", "--", g.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof history: %w", err)
+	}
+
+	var publications []Publication
+	var revision string
+	var publishedAt time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			// The line content ends each porcelain entry
+			if strings.Trim(revision, "0") == "" {
+				continue
+			}
+			record, err := parseLine(strings.TrimPrefix(line, "\t"))
+			if err != nil {
+				continue
+			}
+			publications = append(publications, Publication{
+				Record:      record,
+				Revision:    revision,
+				PublishedAt: publishedAt,
+			})
+		case strings.HasPrefix(line, "committer-time "):
+			seconds, _ := strconv.ParseInt(strings.TrimPrefix(line, "committer-time "), 10, 64)
+			publishedAt = time.Unix(seconds, 0)
+		default:
+			// Each entry starts with "<commit> <original line> <final line> [<group size>]"
+			if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) >= 40 && strings.Trim(fields[0], "0123456789abcdef") == "" {
+				revision = fields[0]
+			}
+		}
+	}
+
+	return publications, scanner.Err()
+}
+
+// CheckAppendOnly returns an error naming the first commit that removed or changed a proof line
+func (g *Git) CheckAppendOnly() error {
+	output, err := g.git("log", "--format=commit %H", "--numstat", "--", g.File)
+	if err != nil {
+		return fmt.Errorf("failed to read proof history: %w", err)
+	}
+
+	var commit string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "commit ") {
+			commit = strings.TrimPrefix(line, "commit ")
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] != "0" {
+			return fmt.Errorf("commit %s removed or changed %s line(s) of %s", commit, fields[1], g.File)
+		}
+	}
+
+	return nil
+}
+
+// git runs a git command in the repository and returns its output
+func (g *Git) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.RepoPath}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, fmt.Errorf("git %s failed: %w (output: %s)", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
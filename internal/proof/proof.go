@@ -0,0 +1,94 @@
+package proof
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record states that a backup file with a given SHA-256 checksum existed. It
+// carries no backup content.
+type Record struct {
+	Filename  string
+	Manager   string
+	SHA256    string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Publication is a record as found in an append-only location
+type Publication struct {
+	Record
+	// Revision identifies where the record was published, e.g. a git commit
+	Revision string
+	// PublishedAt is when the location accepted the record
+	PublishedAt time.Time
+}
+
+// Publisher represents an append-only location proofs are published to
+type Publisher interface {
+	// Name returns the name of the location
+	Name() string
+
+	// Publish appends a record
+	Publish(record Record) error
+
+	// History returns every published record, oldest first
+	History() ([]Publication, error)
+
+	// CheckAppendOnly returns an error if published records were ever changed or removed
+	CheckAppendOnly() error
+}
+
+// PublishError indicates a proof could not be published
+type PublishError struct {
+	Location string
+	File     string
+	Err      error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("publishing proof for %s to %s failed: %v", e.File, e.Location, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// formatLine encodes a record as one tab-separated line:
+// created_at, sha256, size, manager, filename
+func formatLine(record Record) string {
+	return strings.Join([]string{
+		record.CreatedAt.UTC().Format(time.RFC3339),
+		record.SHA256,
+		strconv.FormatInt(record.Size, 10),
+		record.Manager,
+		record.Filename,
+	}, "\t")
+}
+
+// parseLine decodes a line written by formatLine
+func parseLine(line string) (Record, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 5 {
+		return Record{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid size: %w", err)
+	}
+
+	return Record{
+		CreatedAt: createdAt,
+		SHA256:    fields[1],
+		Size:      size,
+		Manager:   fields[3],
+		Filename:  fields[4],
+	}, nil
+}
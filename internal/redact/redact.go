@@ -0,0 +1,101 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Markers that replace redacted text
+const (
+	EmailMarker  = "<email>"
+	TokenMarker  = "<token>"
+	UserMarker   = "<user>"
+	CustomMarker = "<redacted>"
+)
+
+var (
+	// emailPattern matches email addresses
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// secretAssignmentPattern matches credentials passed as key=value or key: value,
+	// such as "--session abc" or "token=abc"
+	secretAssignmentPattern = regexp.MustCompile(`(?i)\b((?:bw_session|session|token|access_token|refresh_token|password|passwd|secret|api[_-]?key|client_secret|sig)["']?\s*[=:]\s*["']?|--(?:session|token|password)[= ])[^\s"'&;,]+`)
+
+	// bearerPattern matches HTTP bearer and basic credentials
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/\-]+=*`)
+
+	// longTokenPattern matches opaque strings such as session keys and checksums. A run of
+	// 24 letters and digits with no separator tells them apart from filenames and paths.
+	longTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_\-]*[A-Za-z0-9]{24,}[A-Za-z0-9+/_\-]*={0,2}`)
+)
+
+// Options selects what a Redactor masks
+type Options struct {
+	Emails bool
+	Tokens bool
+	// Paths masks the home directory and the user name in paths and filenames
+	Paths    bool
+	HomeDir  string
+	Username string
+	// Patterns are extra regular expressions whose matches are masked
+	Patterns []string
+}
+
+// Redactor masks sensitive text before it is written to log files or notifications
+type Redactor struct {
+	rules []rule
+}
+
+// rule replaces every match of a pattern
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// New creates a Redactor. It returns an error if a custom pattern doesn't compile.
+func New(opts Options) (*Redactor, error) {
+	r := &Redactor{}
+
+	// Custom patterns run first so they see the original text
+	for _, pattern := range opts.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.rules = append(r.rules, rule{pattern: re, replacement: CustomMarker})
+	}
+
+	if opts.Tokens {
+		r.rules = append(r.rules,
+			rule{pattern: secretAssignmentPattern, replacement: "${1}" + TokenMarker},
+			rule{pattern: bearerPattern, replacement: "${1} " + TokenMarker},
+			rule{pattern: longTokenPattern, replacement: TokenMarker},
+		)
+	}
+	if opts.Emails {
+		r.rules = append(r.rules, rule{pattern: emailPattern, replacement: EmailMarker})
+	}
+	if opts.Paths {
+		if home := strings.TrimRight(opts.HomeDir, `/\`); len(home) > 1 {
+			r.rules = append(r.rules, rule{pattern: regexp.MustCompile(regexp.QuoteMeta(home)), replacement: "~"})
+		}
+		// Short names would mask ordinary words
+		if len(opts.Username) >= 3 {
+			r.rules = append(r.rules, rule{pattern: regexp.MustCompile(`(?i)` + regexp.QuoteMeta(opts.Username)), replacement: UserMarker})
+		}
+	}
+
+	return r, nil
+}
+
+// Redact returns text with every sensitive match masked
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.rules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
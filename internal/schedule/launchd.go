@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// launchdLabel is the label of the launchd agent
+const launchdLabel = "com.stashr.backup"
+
+// launchd schedules the backup with a launchd agent
+type launchd struct{}
+
+func (launchd) name() string {
+	return "launchd"
+}
+
+// plistPath returns the path of the agent's property list
+func (launchd) plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+// domain returns the launchctl domain of the user's GUI session, where agents
+// can reach the login keychain
+func (launchd) domain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func (l launchd) files(job Job) ([]File, error) {
+	path, err := l.plistPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var plist strings.Builder
+	plist.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	plist.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	plist.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	fmt.Fprintf(&plist, "\t<key>Label</key>\n\t<string>%s</string>\n", launchdLabel)
+	plist.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range job.Command {
+		fmt.Fprintf(&plist, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	plist.WriteString("\t</array>\n")
+
+	// launchd runs a calendar job missed while the machine slept when it wakes
+	plist.WriteString("\t<key>StartCalendarInterval</key>\n\t<dict>\n")
+	if job.Weekly {
+		fmt.Fprintf(&plist, "\t\t<key>Weekday</key>\n\t\t<integer>%d</integer>\n", int(job.Weekday))
+	}
+	fmt.Fprintf(&plist, "\t\t<key>Hour</key>\n\t\t<integer>%d</integer>\n", job.Hour)
+	fmt.Fprintf(&plist, "\t\t<key>Minute</key>\n\t\t<integer>%d</integer>\n", job.Minute)
+	plist.WriteString("\t</dict>\n")
+
+	if job.Path != "" {
+		plist.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		fmt.Fprintf(&plist, "\t\t<key>PATH</key>\n\t\t<string>%s</string>\n", xmlEscape(job.Path))
+		plist.WriteString("\t</dict>\n")
+	}
+	if job.LogFile != "" {
+		fmt.Fprintf(&plist, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", xmlEscape(job.LogFile))
+		fmt.Fprintf(&plist, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", xmlEscape(job.LogFile))
+	}
+	plist.WriteString("</dict>\n</plist>\n")
+
+	return []File{{Path: path, Content: plist.String()}}, nil
+}
+
+func (l launchd) install(job Job, files []File) error {
+	if err := writeFiles(files); err != nil {
+		return err
+	}
+	if job.LogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(job.LogFile), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(job.LogFile), err)
+		}
+	}
+	// Unload an agent installed before so the new definition takes effect
+	_, _ = run("launchctl", "bootout", l.domain()+"/"+launchdLabel)
+	_, err := run("launchctl", "bootstrap", l.domain(), files[0].Path)
+	return err
+}
+
+func (l launchd) installed() ([]string, error) {
+	path, err := l.plistPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, ErrNotInstalled
+	}
+	return []string{path}, nil
+}
+
+func (l launchd) uninstall() error {
+	paths, err := l.installed()
+	if err != nil {
+		return err
+	}
+	// The agent isn't loaded if the user logged out since it was installed
+	_, _ = run("launchctl", "bootout", l.domain()+"/"+launchdLabel)
+	return removeFiles(paths...)
+}
+
+func (l launchd) status() (*Status, error) {
+	path, err := l.plistPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, ErrNotInstalled
+	}
+
+	status := &Status{
+		Scheduler:  l.name(),
+		Definition: []string{path},
+	}
+	if content, err := os.ReadFile(path); err == nil {
+		if log := plistString(content, "StandardOutPath"); log != "" {
+			status.Logs = log
+		}
+	}
+
+	output, err := run("launchctl", "print", l.domain()+"/"+launchdLabel)
+	if err != nil {
+		status.State = "not loaded"
+		return status, nil
+	}
+	properties := parseProperties(output, " = ")
+	status.State = properties["state"]
+	if runs := properties["runs"]; runs != "" && runs != "0" {
+		status.LastRun = runs + " runs since loaded"
+		status.LastResult = "exit code " + properties["last exit code"]
+	}
+	return status, nil
+}
+
+// plistString returns the string value of a top-level key in a property list
+func plistString(content []byte, key string) string {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var element, lastKey string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			element = t.Name.Local
+		case xml.CharData:
+			switch element {
+			case "key":
+				lastKey = string(t)
+			case "string":
+				if lastKey == key {
+					return string(t)
+				}
+			}
+		case xml.EndElement:
+			element = ""
+		}
+	}
+}
+
+// xmlEscape escapes text for an XML element
+func xmlEscape(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
@@ -0,0 +1,245 @@
+// Package schedule installs recurring backups in the OS scheduler: systemd user
+// timers on Linux, launchd agents on macOS and Scheduled Tasks on Windows.
+package schedule
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Name identifies the scheduled backup in every scheduler
+const Name = "stashr-backup"
+
+// ErrNotInstalled is returned when no scheduled backup is installed
+var ErrNotInstalled = errors.New("no scheduled backup is installed")
+
+// Job is a recurring stashr backup
+type Job struct {
+	// Command is the stashr executable followed by its arguments
+	Command []string
+	// Weekly runs the job on Weekday only, instead of every day
+	Weekly  bool
+	Weekday time.Weekday
+	Hour    int
+	Minute  int
+	// Path is the PATH the job runs with, so it finds the password manager CLIs
+	Path string
+	// LogFile receives the job's output where the scheduler doesn't keep it (launchd)
+	LogFile string
+}
+
+// Describe returns when the job runs, e.g. "daily at 02:00"
+func (j Job) Describe() string {
+	if j.Weekly {
+		return fmt.Sprintf("every %s at %02d:%02d", j.Weekday, j.Hour, j.Minute)
+	}
+	return fmt.Sprintf("daily at %02d:%02d", j.Hour, j.Minute)
+}
+
+// File is a job definition a scheduler installs. Path is the file it is written
+// to, or the task name for Task Scheduler, which keeps definitions itself.
+type File struct {
+	Path    string
+	Content string
+}
+
+// Status describes the installed scheduled backup. Fields the scheduler doesn't
+// report are empty.
+type Status struct {
+	Scheduler string
+	// Definition is where the job is defined: files or a task name
+	Definition []string
+	State      string
+	NextRun    string
+	LastRun    string
+	LastResult string
+	// Logs tells where the job's output can be read
+	Logs string
+}
+
+// scheduler installs jobs in one OS scheduler
+type scheduler interface {
+	name() string
+	files(job Job) ([]File, error)
+	install(job Job, files []File) error
+	uninstall() error
+	// installed returns the paths of the installed job definitions, or ErrNotInstalled
+	installed() ([]string, error)
+	status() (*Status, error)
+}
+
+// current returns the scheduler of this OS
+func current() (scheduler, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return systemd{}, nil
+	case "darwin":
+		return launchd{}, nil
+	case "windows":
+		return taskScheduler{}, nil
+	default:
+		return nil, fmt.Errorf("scheduled backups are not supported on %s: run \"stashr backup\" from cron instead", runtime.GOOS)
+	}
+}
+
+// SchedulerName returns the name of this OS's scheduler
+func SchedulerName() (string, error) {
+	s, err := current()
+	if err != nil {
+		return "", err
+	}
+	return s.name(), nil
+}
+
+// Plan returns the job definitions Install would write, without installing them
+func Plan(job Job) ([]File, error) {
+	s, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return s.files(job)
+}
+
+// Install installs the job, replacing any scheduled backup already installed
+func Install(job Job) ([]File, error) {
+	s, err := current()
+	if err != nil {
+		return nil, err
+	}
+	if len(job.Command) == 0 {
+		return nil, errors.New("scheduled job has no command")
+	}
+	files, err := s.files(job)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.install(job, files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Uninstall removes the scheduled backup, or returns ErrNotInstalled
+func Uninstall() error {
+	s, err := current()
+	if err != nil {
+		return err
+	}
+	return s.uninstall()
+}
+
+// Installed returns the job definitions Uninstall would remove, or ErrNotInstalled
+func Installed() ([]string, error) {
+	s, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return s.installed()
+}
+
+// QueryStatus returns the state of the scheduled backup, or ErrNotInstalled
+func QueryStatus() (*Status, error) {
+	s, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return s.status()
+}
+
+// ParseTime parses an HH:MM time of day
+func ParseTime(clock string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time: %s (use HH:MM, e.g. 02:00)", clock)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// ParseWeekly parses a weekday and time of day, e.g. "sunday 02:00"
+func ParseWeekly(value string) (time.Weekday, int, int, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, 0, 0, fmt.Errorf("invalid weekly schedule: %q (use \"<weekday> HH:MM\", e.g. \"sunday 02:00\")", value)
+	}
+
+	day := -1
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		name := i.String()
+		if strings.EqualFold(name, fields[0]) || strings.EqualFold(name[:3], fields[0]) {
+			day = int(i)
+		}
+	}
+	if day < 0 {
+		return 0, 0, 0, fmt.Errorf("invalid weekday: %s", fields[0])
+	}
+
+	hour, minute, err := ParseTime(fields[1])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return time.Weekday(day), hour, minute, nil
+}
+
+// writeFiles writes job definitions, creating their directories
+func writeFiles(files []File) error {
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(file.Path), err)
+		}
+		if err := os.WriteFile(file.Path, []byte(file.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// removeFiles removes job definitions, ignoring ones that don't exist
+func removeFiles(paths ...string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// run runs a scheduler tool and returns its output
+func run(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%s not found", name)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return string(output), fmt.Errorf("%s %s failed: %v (output: %s)", name, strings.Join(args, " "), err, message)
+		}
+		return string(output), fmt.Errorf("%s %s failed: %w", name, strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// parseProperties parses "key<sep>value" lines, trimming both sides
+func parseProperties(output, separator string) map[string]string {
+	properties := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, separator)
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, seen := properties[key]; !seen {
+			properties[key] = strings.TrimSpace(value)
+		}
+	}
+	return properties
+}
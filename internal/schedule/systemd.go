@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemd schedules the backup with a systemd user timer
+type systemd struct{}
+
+func (systemd) name() string {
+	return "systemd"
+}
+
+// unitDir returns the directory of the user's systemd units
+func (systemd) unitDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+func (s systemd) files(job Job) ([]File, error) {
+	dir, err := s.unitDir()
+	if err != nil {
+		return nil, err
+	}
+
+	quoted := make([]string, len(job.Command))
+	for i, arg := range job.Command {
+		quoted[i] = systemdQuote(arg)
+	}
+	var service strings.Builder
+	service.WriteString("[Unit]\n")
+	service.WriteString("Description=stashr password manager backup\n")
+	service.WriteString("Documentation=https://github.com/harshalranjhani/stashr\n")
+	service.WriteString("Wants=network-online.target\n")
+	service.WriteString("After=network-online.target\n\n")
+	service.WriteString("[Service]\n")
+	service.WriteString("Type=oneshot\n")
+	if job.Path != "" {
+		fmt.Fprintf(&service, "Environment=%s\n", systemdQuote("PATH="+job.Path))
+	}
+	fmt.Fprintf(&service, "ExecStart=%s\n", strings.Join(quoted, " "))
+
+	// Persistent runs a backup missed while the machine was off once it is back
+	calendar := fmt.Sprintf("*-*-* %02d:%02d:00", job.Hour, job.Minute)
+	if job.Weekly {
+		calendar = job.Weekday.String()[:3] + " " + calendar
+	}
+	var timer strings.Builder
+	timer.WriteString("[Unit]\n")
+	fmt.Fprintf(&timer, "Description=Run stashr backup %s\n\n", job.Describe())
+	timer.WriteString("[Timer]\n")
+	fmt.Fprintf(&timer, "OnCalendar=%s\n", calendar)
+	timer.WriteString("Persistent=true\n\n")
+	timer.WriteString("[Install]\n")
+	timer.WriteString("WantedBy=timers.target\n")
+
+	return []File{
+		{Path: filepath.Join(dir, Name+".service"), Content: service.String()},
+		{Path: filepath.Join(dir, Name+".timer"), Content: timer.String()},
+	}, nil
+}
+
+func (systemd) install(job Job, files []File) error {
+	if err := writeFiles(files); err != nil {
+		return err
+	}
+	if _, err := run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err := run("systemctl", "--user", "enable", "--now", Name+".timer")
+	return err
+}
+
+func (s systemd) installed() ([]string, error) {
+	dir, err := s.unitDir()
+	if err != nil {
+		return nil, err
+	}
+	timer := filepath.Join(dir, Name+".timer")
+	if _, err := os.Stat(timer); os.IsNotExist(err) {
+		return nil, ErrNotInstalled
+	}
+	return []string{timer, filepath.Join(dir, Name+".service")}, nil
+}
+
+func (s systemd) uninstall() error {
+	paths, err := s.installed()
+	if err != nil {
+		return err
+	}
+
+	if _, err := run("systemctl", "--user", "disable", "--now", Name+".timer"); err != nil {
+		return err
+	}
+	if err := removeFiles(paths...); err != nil {
+		return err
+	}
+	_, err = run("systemctl", "--user", "daemon-reload")
+	return err
+}
+
+func (s systemd) status() (*Status, error) {
+	dir, err := s.unitDir()
+	if err != nil {
+		return nil, err
+	}
+	timer := filepath.Join(dir, Name+".timer")
+	if _, err := os.Stat(timer); os.IsNotExist(err) {
+		return nil, ErrNotInstalled
+	}
+
+	status := &Status{
+		Scheduler:  s.name(),
+		Definition: []string{filepath.Join(dir, Name+".service"), timer},
+		Logs:       "journalctl --user -u " + Name + ".service",
+	}
+
+	output, err := run("systemctl", "--user", "show", Name+".timer", "--property=ActiveState,NextElapseUSecRealtime,LastTriggerUSec")
+	if err != nil {
+		return nil, err
+	}
+	properties := parseProperties(output, "=")
+	status.State = properties["ActiveState"]
+	status.NextRun = systemdTime(properties["NextElapseUSecRealtime"])
+	status.LastRun = systemdTime(properties["LastTriggerUSec"])
+
+	output, err = run("systemctl", "--user", "show", Name+".service", "--property=Result,ExecMainStatus")
+	if err != nil {
+		return nil, err
+	}
+	properties = parseProperties(output, "=")
+	if status.LastRun != "" && properties["Result"] != "" {
+		status.LastResult = fmt.Sprintf("%s (exit code %s)", properties["Result"], properties["ExecMainStatus"])
+	}
+	return status, nil
+}
+
+// systemdTime returns a timestamp property, or "" when systemd has none
+func systemdTime(value string) string {
+	if value == "" || value == "n/a" || value == "0" {
+		return ""
+	}
+	return value
+}
+
+// systemdQuote quotes a unit file value, escaping the characters systemd expands
+func systemdQuote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(value)
+	return `"` + value + `"`
+}
@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// taskNeverRun is the last result Task Scheduler reports for a task that
+// hasn't run yet (SCHED_S_TASK_HAS_NOT_RUN)
+const taskNeverRun = "267011"
+
+// taskScheduler schedules the backup with a Windows Scheduled Task
+type taskScheduler struct{}
+
+func (taskScheduler) name() string {
+	return "Task Scheduler"
+}
+
+// files returns the task definition. It isn't kept on disk: its path is the
+// name of the task it is registered as.
+func (t taskScheduler) files(job Job) ([]File, error) {
+	trigger := "\t\t\t<ScheduleByDay>\n\t\t\t\t<DaysInterval>1</DaysInterval>\n\t\t\t</ScheduleByDay>\n"
+	if job.Weekly {
+		trigger = fmt.Sprintf("\t\t\t<ScheduleByWeek>\n\t\t\t\t<DaysOfWeek>\n\t\t\t\t\t<%s />\n\t\t\t\t</DaysOfWeek>\n\t\t\t\t<WeeksInterval>1</WeeksInterval>\n\t\t\t</ScheduleByWeek>\n", job.Weekday)
+	}
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), job.Hour, job.Minute, 0, 0, time.Local)
+
+	args := make([]string, len(job.Command)-1)
+	for i, arg := range job.Command[1:] {
+		args[i] = windowsQuote(arg)
+	}
+
+	var task strings.Builder
+	task.WriteString(`<?xml version="1.0" encoding="UTF-16"?>` + "\n")
+	task.WriteString(`<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">` + "\n")
+	task.WriteString("\t<RegistrationInfo>\n\t\t<Description>stashr password manager backup</Description>\n\t</RegistrationInfo>\n")
+	task.WriteString("\t<Triggers>\n\t\t<CalendarTrigger>\n")
+	fmt.Fprintf(&task, "\t\t\t<StartBoundary>%s</StartBoundary>\n", start.Format("2006-01-02T15:04:05"))
+	task.WriteString("\t\t\t<Enabled>true</Enabled>\n")
+	task.WriteString(trigger)
+	task.WriteString("\t\t</CalendarTrigger>\n\t</Triggers>\n")
+	// Run as the logged-on user, whose Credential Manager and CLI sessions the backup needs
+	task.WriteString("\t<Principals>\n\t\t<Principal id=\"Author\">\n\t\t\t<LogonType>InteractiveToken</LogonType>\n\t\t\t<RunLevel>LeastPrivilege</RunLevel>\n\t\t</Principal>\n\t</Principals>\n")
+	task.WriteString("\t<Settings>\n")
+	task.WriteString("\t\t<MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>\n")
+	task.WriteString("\t\t<DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>\n")
+	task.WriteString("\t\t<StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>\n")
+	// Run a backup missed while the machine was off as soon as possible
+	task.WriteString("\t\t<StartWhenAvailable>true</StartWhenAvailable>\n")
+	task.WriteString("\t\t<ExecutionTimeLimit>PT2H</ExecutionTimeLimit>\n")
+	task.WriteString("\t\t<Enabled>true</Enabled>\n")
+	task.WriteString("\t</Settings>\n")
+	task.WriteString("\t<Actions Context=\"Author\">\n\t\t<Exec>\n")
+	fmt.Fprintf(&task, "\t\t\t<Command>%s</Command>\n", xmlEscape(job.Command[0]))
+	fmt.Fprintf(&task, "\t\t\t<Arguments>%s</Arguments>\n", xmlEscape(strings.Join(args, " ")))
+	task.WriteString("\t\t</Exec>\n\t</Actions>\n")
+	task.WriteString("</Task>\n")
+
+	return []File{{Path: `\` + Name, Content: task.String()}}, nil
+}
+
+func (taskScheduler) install(job Job, files []File) error {
+	// schtasks reads task definitions as UTF-16
+	encoded := utf16.Encode([]rune("\ufeff" + files[0].Content))
+	data := make([]byte, 0, 2*len(encoded))
+	for _, unit := range encoded {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+
+	file, err := os.CreateTemp("", Name+"-*.xml")
+	if err != nil {
+		return fmt.Errorf("failed to create task definition: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write task definition: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write task definition: %w", err)
+	}
+
+	_, err = run("schtasks", "/Create", "/TN", Name, "/XML", file.Name(), "/F")
+	return err
+}
+
+// installed returns the name of the registered task, like files
+func (taskScheduler) installed() ([]string, error) {
+	if _, err := exec.LookPath("schtasks"); err != nil {
+		return nil, fmt.Errorf("schtasks not found")
+	}
+	if _, err := run("schtasks", "/Query", "/TN", Name); err != nil {
+		return nil, ErrNotInstalled
+	}
+	return []string{`\` + Name}, nil
+}
+
+func (t taskScheduler) uninstall() error {
+	if _, err := t.installed(); err != nil {
+		return err
+	}
+	_, err := run("schtasks", "/Delete", "/TN", Name, "/F")
+	return err
+}
+
+func (t taskScheduler) status() (*Status, error) {
+	if _, err := exec.LookPath("schtasks"); err != nil {
+		return nil, fmt.Errorf("schtasks not found")
+	}
+	output, err := run("schtasks", "/Query", "/TN", Name, "/FO", "LIST", "/V")
+	if err != nil {
+		return nil, ErrNotInstalled
+	}
+
+	properties := parseProperties(output, ":")
+	status := &Status{
+		Scheduler:  t.name(),
+		Definition: []string{`\` + Name},
+		State:      properties["Status"],
+		NextRun:    properties["Next Run Time"],
+		Logs:       "the \"Last Run Result\" in Task Scheduler, or stashr's log file (logging.file)",
+	}
+	if result := properties["Last Result"]; result != "" && result != taskNeverRun {
+		status.LastRun = properties["Last Run Time"]
+		status.LastResult = "exit code " + result
+	}
+	return status, nil
+}
+
+// windowsQuote quotes an argument the way Windows programs split their command
+// line: backslashes are literal unless they precede a quote
+func windowsQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	backslashes := 0
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			backslashes++
+		case '"':
+			quoted.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+			quoted.WriteRune(r)
+			backslashes = 0
+		default:
+			quoted.WriteString(strings.Repeat(`\`, backslashes))
+			quoted.WriteRune(r)
+			backslashes = 0
+		}
+	}
+	quoted.WriteString(strings.Repeat(`\`, 2*backslashes))
+	quoted.WriteByte('"')
+	return quoted.String()
+}
@@ -0,0 +1,10 @@
+//go:build linux
+
+package secret
+
+import "golang.org/x/sys/unix"
+
+// excludeFromCoreDumps keeps a secret's memory out of core dumps
+func excludeFromCoreDumps(data []byte) {
+	_ = unix.Madvise(data, unix.MADV_DONTDUMP)
+}
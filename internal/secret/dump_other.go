@@ -0,0 +1,6 @@
+//go:build unix && !linux
+
+package secret
+
+// excludeFromCoreDumps is a no-op: only Linux can exclude memory from core dumps
+func excludeFromCoreDumps(data []byte) {}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package secret
+
+// alloc allocates a secret on the heap: memory can't be locked on this platform
+func alloc(size int) ([]byte, bool) {
+	return make([]byte, size), false
+}
+
+// free leaves the wiped memory to the garbage collector
+func free(data []byte, locked bool) {}
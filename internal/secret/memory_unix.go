@@ -0,0 +1,25 @@
+//go:build unix
+
+package secret
+
+import "golang.org/x/sys/unix"
+
+// alloc maps private anonymous memory for a secret, so it never moves and
+// isn't part of heap dumps, and locks it into RAM if the limit allows
+func alloc(size int) ([]byte, bool) {
+	data, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size), false
+	}
+	excludeFromCoreDumps(data)
+	return data, unix.Mlock(data) == nil
+}
+
+// free unlocks and unmaps the memory of a secret
+func free(data []byte, locked bool) {
+	if locked {
+		_ = unix.Munlock(data)
+	}
+	// Memory that couldn't be mapped came from the heap
+	_ = unix.Munmap(data)
+}
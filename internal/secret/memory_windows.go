@@ -0,0 +1,23 @@
+//go:build windows
+
+package secret
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// alloc allocates a secret on the heap, which never moves, and locks it into
+// RAM if the working set allows
+func alloc(size int) ([]byte, bool) {
+	data := make([]byte, size)
+	return data, windows.VirtualLock(uintptr(unsafe.Pointer(&data[0])), uintptr(size)) == nil
+}
+
+// free unlocks the memory of a secret
+func free(data []byte, locked bool) {
+	if locked {
+		_ = windows.VirtualUnlock(uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	}
+}
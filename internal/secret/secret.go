@@ -0,0 +1,97 @@
+// Package secret holds passwords and keys in memory that is locked against
+// being swapped to disk and is wiped as soon as it is no longer needed.
+package secret
+
+import (
+	"crypto/subtle"
+	"runtime"
+	"sync"
+)
+
+// Buffer is a fixed-size secret kept outside the garbage-collected heap where
+// the platform allows it. The memory is locked so it isn't written to swap,
+// and is zeroed by Destroy; a Buffer must not be used after that.
+type Buffer struct {
+	mu     sync.Mutex
+	data   []byte
+	locked bool
+}
+
+// New returns a zeroed secret of size bytes
+func New(size int) *Buffer {
+	b := &Buffer{}
+	if size > 0 {
+		b.data, b.locked = alloc(size)
+	}
+	return b
+}
+
+// FromBytes moves data into a new secret and wipes the original
+func FromBytes(data []byte) *Buffer {
+	b := New(len(data))
+	copy(b.data, data)
+	Wipe(data)
+	return b
+}
+
+// FromString copies a string into a new secret. The string itself can't be
+// wiped, so prefer FromBytes where the secret is read as bytes.
+func FromString(s string) *Buffer {
+	return FromBytes([]byte(s))
+}
+
+// Bytes returns the secret's memory; it is only valid until Destroy. Copies
+// of it, e.g. as a string, aren't protected.
+func (b *Buffer) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.data
+}
+
+// Len returns the size of the secret in bytes
+func (b *Buffer) Len() int {
+	return len(b.Bytes())
+}
+
+// Locked reports whether the secret's memory is locked against swapping. It
+// isn't when the platform has no support or the memory lock limit is reached.
+func (b *Buffer) Locked() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.locked
+}
+
+// Equal compares two secrets in constant time
+func (b *Buffer) Equal(other *Buffer) bool {
+	x, y := b.Bytes(), other.Bytes()
+	return len(x) == len(y) && subtle.ConstantTimeCompare(x, y) == 1
+}
+
+// Destroy wipes the secret and releases its memory. It is safe to call more
+// than once.
+func (b *Buffer) Destroy() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.data == nil {
+		return
+	}
+	Wipe(b.data)
+	free(b.data, b.locked)
+	b.data, b.locked = nil, false
+}
+
+// Wipe zeroes data in place, for plaintext and keys that live on the heap
+func Wipe(data []byte) {
+	clear(data)
+	// Keep the stores from being optimized away as dead
+	runtime.KeepAlive(data)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// partialSuffix marks a backup that is still being written
+const partialSuffix = ".tmp"
+
+// isPartialFile reports whether a file is an unfinished write, left behind if
+// the write was interrupted
+func isPartialFile(filename string) bool {
+	return strings.HasSuffix(filename, partialSuffix)
+}
+
+// writeFileAtomic writes data to <path>.tmp, flushes it to disk and renames it
+// into place, so a power loss or a removed drive never leaves a truncated file
+// under the backup's name
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + partialSuffix
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to flush directory: %w", err)
+	}
+	return nil
+}
+
+// syncDir flushes a directory so a rename into it survives a power loss.
+// Windows can't open directories for flushing and makes renames durable itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,523 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion is the Blob service REST API version requests are made with
+const azureAPIVersion = "2021-08-06"
+
+// Well-known Azurite (local storage emulator) account used by UseDevelopmentStorage=true
+const (
+	azuriteAccount  = "devstoreaccount1"
+	azuriteKey      = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	azuriteEndpoint = "http://127.0.0.1:10000/devstoreaccount1"
+)
+
+// AzureBlob represents an Azure Blob Storage container backend
+type AzureBlob struct {
+	Container string
+	Prefix    string
+
+	// ContentType is the MIME type uploads are stored with; empty uses DefaultContentType
+	ContentType string
+	// LockDays gives each uploaded backup an immutability policy of this many
+	// days, so it can't be deleted or overwritten until then; 0 uploads unlocked
+	// backups. The container needs version-level immutability support.
+	LockDays int
+
+	account  string
+	key      []byte
+	sas      string
+	endpoint string
+	// connErr is set when the connection string could not be parsed
+	connErr error
+	client  *http.Client
+}
+
+// NewAzureBlob creates a new Azure Blob Storage backend from a storage account
+// connection string, authenticating with the account key or a SAS token
+func NewAzureBlob(connectionString, container, prefix string) *AzureBlob {
+	a := &AzureBlob{
+		Container: container,
+		Prefix:    strings.Trim(prefix, "/"),
+		client:    newHTTPClient(5 * time.Minute),
+	}
+	a.connErr = a.parseConnectionString(connectionString)
+	return a
+}
+
+// parseConnectionString reads the account, credentials and endpoint from a connection string
+func (a *AzureBlob) parseConnectionString(connectionString string) error {
+	if connectionString == "" {
+		return fmt.Errorf("connection string not configured")
+	}
+
+	values := make(map[string]string)
+	for _, part := range strings.Split(connectionString, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// Values such as account keys may themselves contain '='
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("invalid connection string segment %q", part)
+		}
+		values[strings.ToLower(key)] = value
+	}
+
+	if strings.EqualFold(values["usedevelopmentstorage"], "true") {
+		values["accountname"] = azuriteAccount
+		values["accountkey"] = azuriteKey
+		values["blobendpoint"] = azuriteEndpoint
+	}
+
+	a.account = values["accountname"]
+	a.sas = strings.TrimPrefix(values["sharedaccesssignature"], "?")
+	if accountKey := values["accountkey"]; accountKey != "" {
+		key, err := base64.StdEncoding.DecodeString(accountKey)
+		if err != nil {
+			return fmt.Errorf("invalid account key: %w", err)
+		}
+		a.key = key
+	}
+	if a.key == nil && a.sas == "" {
+		return fmt.Errorf("connection string has neither AccountKey nor SharedAccessSignature")
+	}
+
+	a.endpoint = strings.TrimRight(values["blobendpoint"], "/")
+	if a.endpoint == "" {
+		if a.account == "" {
+			return fmt.Errorf("connection string has neither AccountName nor BlobEndpoint")
+		}
+		protocol := values["defaultendpointsprotocol"]
+		if protocol == "" {
+			protocol = "https"
+		}
+		suffix := values["endpointsuffix"]
+		if suffix == "" {
+			suffix = "core.windows.net"
+		}
+		a.endpoint = fmt.Sprintf("%s://%s.blob.%s", protocol, a.account, suffix)
+	}
+	if a.key != nil && a.account == "" {
+		return fmt.Errorf("connection string has an AccountKey but no AccountName")
+	}
+
+	return nil
+}
+
+// Name returns the name of the storage backend
+func (a *AzureBlob) Name() string {
+	return "Azure Blob"
+}
+
+// IsAvailable checks if the container is reachable with the configured credentials
+func (a *AzureBlob) IsAvailable() (bool, error) {
+	if a.connErr != nil {
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  a.connErr.Error(),
+		}
+	}
+	if a.Container == "" {
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  "container not configured",
+		}
+	}
+
+	// Listing works with container-scoped SAS tokens, unlike reading the container properties
+	query := url.Values{
+		"restype":    {"container"},
+		"comp":       {"list"},
+		"maxresults": {"1"},
+	}
+	resp, err := a.do(http.MethodGet, a.containerURL(), query, nil, nil)
+	if err != nil {
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  fmt.Sprintf("failed to reach storage account: %v", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  "authentication failed (check the connection string)",
+		}
+	case http.StatusNotFound:
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  fmt.Sprintf("container %s not found", a.Container),
+		}
+	default:
+		return false, &StorageUnavailableError{
+			Storage: a.Name(),
+			Reason:  fmt.Sprintf("unexpected response: %v", azureError(resp)),
+		}
+	}
+}
+
+// containerURL returns the URL of the container
+func (a *AzureBlob) containerURL() string {
+	return a.endpoint + "/" + url.PathEscape(a.Container)
+}
+
+// blobURL returns the URL of a backup file
+func (a *AzureBlob) blobURL(filename string) string {
+	return a.containerURL() + "/" + escapePath(path.Join(a.Prefix, filename))
+}
+
+// do sends a request signed with the account key, or authorized by the SAS token
+func (a *AzureBlob) do(method, target string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	if a.connErr != nil {
+		return nil, a.connErr
+	}
+
+	if query == nil {
+		query = url.Values{}
+	}
+	rawQuery := query.Encode()
+	if a.sas != "" && a.key == nil {
+		if rawQuery != "" {
+			rawQuery += "&"
+		}
+		rawQuery += a.sas
+	}
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if a.key != nil {
+		req.Header.Set("Authorization", "SharedKey "+a.account+":"+a.sign(req, len(body)))
+	}
+
+	return a.client.Do(req)
+}
+
+// sign computes the Shared Key signature of a request
+func (a *AzureBlob) sign(req *http.Request, contentLength int) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	// Canonicalized x-ms-* headers, sorted by name
+	var msHeaders []string
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	// Canonicalized resource: account, path and sorted query parameters
+	resource := "/" + a.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date is sent as x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		resource,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// azureErrorBody is the error response body of the Blob service
+type azureErrorBody struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// azureError describes a failed response using the service's error code when present
+func azureError(resp *http.Response) error {
+	var body azureErrorBody
+	if err := xml.NewDecoder(resp.Body).Decode(&body); err != nil || body.Code == "" {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return fmt.Errorf("%s (%s)", resp.Status, body.Code)
+}
+
+// Upload uploads a file as a block blob
+func (a *AzureBlob) Upload(filename string, data []byte) error {
+	headers := map[string]string{
+		"Content-Type":   contentTypeOrDefault(a.ContentType),
+		"x-ms-blob-type": "BlockBlob",
+	}
+	if a.LockDays > 0 {
+		headers["x-ms-immutability-policy-until-date"] = lockUntil(a.LockDays).Format(http.TimeFormat)
+		headers["x-ms-immutability-policy-mode"] = "Locked"
+	}
+	resp, err := a.do(http.MethodPut, a.blobURL(filename), nil, data, headers)
+	if err != nil {
+		return &UploadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return &UploadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", azureError(resp)),
+		}
+	}
+
+	return nil
+}
+
+// Download downloads a file from the container
+func (a *AzureBlob) Download(filename string) ([]byte, error) {
+	resp, err := a.do(http.MethodGet, a.blobURL(filename), nil, nil, nil)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &DownloadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("file not found"),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &DownloadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", azureError(resp)),
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: a.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file content: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// azureBlobList is the List Blobs response body
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List lists all backup files under the prefix, including those in subfolders
+func (a *AzureBlob) List() ([]BackupFile, error) {
+	prefix := ""
+	if a.Prefix != "" {
+		prefix = a.Prefix + "/"
+	}
+
+	var backups []BackupFile
+	marker := ""
+	for {
+		query := url.Values{
+			"restype": {"container"},
+			"comp":    {"list"},
+			"prefix":  {prefix},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := a.do(http.MethodGet, a.containerURL(), query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := azureError(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+
+		var list azureBlobList
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file list: %w", err)
+		}
+
+		for _, blob := range list.Blobs {
+			// Names are relative to the prefix, keeping any subfolder
+			name := strings.TrimPrefix(blob.Name, prefix)
+			if name == "" || hasIgnoredElement(name) {
+				continue
+			}
+
+			modTime, _ := http.ParseTime(blob.Properties.LastModified)
+			backups = append(backups, BackupFile{
+				Name:         name,
+				Size:         blob.Properties.ContentLength,
+				ModifiedTime: modTime,
+				Location:     a.containerURL() + "/" + blob.Name,
+				StorageType:  a.Name(),
+			})
+		}
+
+		if list.NextMarker == "" {
+			break
+		}
+		marker = list.NextMarker
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file from the container
+func (a *AzureBlob) Delete(filename string) error {
+	resp, err := a.do(http.MethodDelete, a.blobURL(filename), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("file not found")
+	}
+	if resp.StatusCode == http.StatusConflict {
+		// Deleting a blob under an immutability policy conflicts with it
+		if lockErr := checkUnlocked(a, filename); lockErr != nil {
+			return lockErr
+		}
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to delete file: %w", azureError(resp))
+	}
+
+	return nil
+}
+
+// StoredChecksum returns the Content-MD5 Azure recorded for a blob. Blobs
+// uploaded in blocks have none.
+func (a *AzureBlob) StoredChecksum(filename string) (string, string, error) {
+	resp, err := a.do(http.MethodHead, a.blobURL(filename), nil, nil, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get blob properties: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", "", fmt.Errorf("file not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to get blob properties: %s", resp.Status)
+	}
+
+	contentMD5 := resp.Header.Get("Content-MD5")
+	if contentMD5 == "" {
+		return "", "", ErrChecksumUnavailable
+	}
+	sum, err := base64.StdEncoding.DecodeString(contentMD5)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Content-MD5 %q: %w", contentMD5, err)
+	}
+	return "md5", hex.EncodeToString(sum), nil
+}
+
+// LockedUntil returns when a blob's immutability policy expires
+func (a *AzureBlob) LockedUntil(filename string) (time.Time, error) {
+	resp, err := a.do(http.MethodHead, a.blobURL(filename), nil, nil, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get blob properties: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, fmt.Errorf("file not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("failed to get blob properties: %s", resp.Status)
+	}
+
+	until, _ := http.ParseTime(resp.Header.Get("x-ms-immutability-policy-until-date"))
+	return until, nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (a *AzureBlob) CleanOldBackups(keepLast int) error {
+	backups, err := a.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, a.Delete)
+	return err
+}
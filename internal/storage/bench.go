@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// BenchFilePrefix starts the names of benchmark test objects. Their leading dot
+// keeps them out of backup listings if a benchmark is interrupted.
+const BenchFilePrefix = ".stashr-bench-"
+
+// BenchStep is the outcome of one operation of a benchmark
+type BenchStep struct {
+	Operation string
+	Duration  time.Duration
+	// Bytes is the amount of data transferred, 0 for operations without a payload
+	Bytes int64
+	Err   error
+}
+
+// Throughput returns the bytes transferred per second, or 0 if nothing was
+func (s BenchStep) Throughput() float64 {
+	if s.Bytes == 0 || s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// BenchResult is the outcome of benchmarking one backend
+type BenchResult struct {
+	Steps []BenchStep
+}
+
+// OK reports whether every operation succeeded
+func (r BenchResult) OK() bool {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Step returns the outcome of an operation, and false if it didn't run
+func (r BenchResult) Step(operation string) (BenchStep, bool) {
+	for _, step := range r.Steps {
+		if step.Operation == operation {
+			return step, true
+		}
+	}
+	return BenchStep{}, false
+}
+
+// Bench uploads a test object of size random bytes to a backend, downloads and
+// compares it, lists the backups and deletes the object again, timing each
+// operation. It stops at the first failure, after deleting the object if it
+// was uploaded, so the steps that ran show which permission is missing.
+func Bench(backend Storage, size int) BenchResult {
+	var result BenchResult
+	run := func(operation string, bytes int64, fn func() error) bool {
+		start := time.Now()
+		err := fn()
+		result.Steps = append(result.Steps, BenchStep{
+			Operation: operation,
+			Duration:  time.Since(start),
+			Bytes:     bytes,
+			Err:       err,
+		})
+		return err == nil
+	}
+
+	data := make([]byte, size)
+	token := make([]byte, 8)
+	if _, err := rand.Read(data); err != nil {
+		result.Steps = append(result.Steps, BenchStep{Operation: "Upload", Err: fmt.Errorf("failed to generate test data: %w", err)})
+		return result
+	}
+	if _, err := rand.Read(token); err != nil {
+		result.Steps = append(result.Steps, BenchStep{Operation: "Upload", Err: fmt.Errorf("failed to generate test name: %w", err)})
+		return result
+	}
+	filename := BenchFilePrefix + hex.EncodeToString(token)
+
+	if !run("Upload", int64(size), func() error { return backend.Upload(filename, data) }) {
+		return result
+	}
+
+	ok := run("Download", int64(size), func() error {
+		downloaded, err := backend.Download(filename)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(downloaded, data) {
+			return fmt.Errorf("downloaded %d bytes that don't match the %d uploaded", len(downloaded), size)
+		}
+		return nil
+	})
+	if ok {
+		run("List", 0, func() error {
+			_, err := backend.List()
+			return err
+		})
+	}
+
+	// The test object is removed even if another step failed
+	run("Delete", 0, func() error { return backend.Delete(filename) })
+	return result
+}
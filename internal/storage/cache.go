@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache is a size-bounded local directory of downloaded backups. Files are grouped
+// by storage backend and the least recently used ones are evicted first.
+type Cache struct {
+	Dir      string
+	MaxBytes int64
+}
+
+// CacheEntry describes a cached backup
+type CacheEntry struct {
+	// Storage is the backend's cache directory name, e.g. "google-drive"
+	Storage  string
+	Name     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// NewCache creates a cache rooted at dir holding at most maxBytes
+func NewCache(dir string, maxBytes int64) *Cache {
+	return &Cache{
+		Dir:      dir,
+		MaxBytes: maxBytes,
+	}
+}
+
+// path returns the cache file for a backup, keeping names from escaping the cache directory
+func (c *Cache) path(storage, filename string) string {
+	return filepath.Join(c.Dir, cacheDirName(storage), filepath.Base(filename))
+}
+
+// cacheDirName turns a storage name such as "Google Drive" into a directory name
+func cacheDirName(storage string) string {
+	return strings.ReplaceAll(strings.ToLower(storage), " ", "-")
+}
+
+// Get returns a cached backup and marks it as recently used
+func (c *Cache) Get(storage, filename string) ([]byte, bool) {
+	path := c.path(storage, filename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores a backup, then evicts the least recently used backups over the size limit.
+// Backups larger than the whole cache are not stored.
+func (c *Cache) Put(storage, filename string, data []byte) error {
+	if int64(len(data)) > c.MaxBytes {
+		return nil
+	}
+
+	path := c.path(storage, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so a partial write is never served
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return c.evict()
+}
+
+// Remove drops a backup from the cache
+func (c *Cache) Remove(storage, filename string) {
+	os.Remove(c.path(storage, filename))
+}
+
+// Entries lists cached backups, most recently used first
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	dirs, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var entries []CacheEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(c.Dir, dir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache directory: %w", err)
+		}
+		for _, file := range files {
+			if file.IsDir() || strings.HasSuffix(file.Name(), ".tmp") {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			entries = append(entries, CacheEntry{
+				Storage:  dir.Name(),
+				Name:     file.Name(),
+				Size:     info.Size(),
+				LastUsed: info.ModTime(),
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Size returns the total size of cached backups
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return total, nil
+}
+
+// Clear deletes every cached backup
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.Dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// evict deletes the least recently used backups until the cache fits its size limit
+func (c *Cache) evict() error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		if total > c.MaxBytes {
+			if err := os.Remove(filepath.Join(c.Dir, entry.Storage, entry.Name)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to evict %s: %w", entry.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CachedStorage reads downloads through a Cache, so repeated operations on the same
+// remote backup only download it once
+type CachedStorage struct {
+	Storage
+	cache *Cache
+}
+
+// NewCachedStorage wraps a storage backend with a read-through cache
+func NewCachedStorage(backend Storage, cache *Cache) *CachedStorage {
+	return &CachedStorage{
+		Storage: backend,
+		cache:   cache,
+	}
+}
+
+// Download returns the cached backup, downloading and caching it on a miss
+func (s *CachedStorage) Download(filename string) ([]byte, error) {
+	// Only backups are cached; control files like the lease change between reads
+	if shouldIgnoreFile(filename) {
+		return s.Storage.Download(filename)
+	}
+
+	if data, ok := s.cache.Get(s.Name(), filename); ok {
+		return data, nil
+	}
+
+	data, err := s.Storage.Download(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cache failure shouldn't fail the download
+	_ = s.cache.Put(s.Name(), filename, data)
+	return data, nil
+}
+
+// Upload uploads a file and drops any stale cached copy
+func (s *CachedStorage) Upload(filename string, data []byte) error {
+	s.cache.Remove(s.Name(), filename)
+	return s.Storage.Upload(filename, data)
+}
+
+// UploadWithProgress uploads a file through the wrapped backend, reporting its
+// progress, and drops any stale cached copy
+func (s *CachedStorage) UploadWithProgress(filename string, data []byte, progress ProgressFunc) error {
+	s.cache.Remove(s.Name(), filename)
+	return UploadWithProgress(s.Storage, filename, data, progress)
+}
+
+// StoredChecksum forwards to the wrapped backend, if its provider records checksums
+func (s *CachedStorage) StoredChecksum(filename string) (string, string, error) {
+	if provider, ok := s.Storage.(ChecksumProvider); ok {
+		return provider.StoredChecksum(filename)
+	}
+	return "", "", ErrChecksumUnavailable
+}
+
+// GetFreeSpace forwards to the wrapped backend, if it can report its free space
+func (s *CachedStorage) GetFreeSpace() (int64, error) {
+	return FreeSpace(s.Storage)
+}
+
+// LockedUntil forwards to the wrapped backend, if it can lock backups
+func (s *CachedStorage) LockedUntil(filename string) (time.Time, error) {
+	return LockedUntil(s.Storage, filename)
+}
+
+// WaitForSync forwards to the wrapped backend, if it is a synced folder
+func (s *CachedStorage) WaitForSync(filename string) (SyncState, error) {
+	return WaitForSync(s.Storage, filename)
+}
+
+// Delete deletes a file and its cached copy
+func (s *CachedStorage) Delete(filename string) error {
+	s.cache.Remove(s.Name(), filename)
+	return s.Storage.Delete(filename)
+}
+
+// Trash moves a file to the wrapped backend's trash, if it has one, and drops its cached copy
+func (s *CachedStorage) Trash(filename string) error {
+	s.cache.Remove(s.Name(), filename)
+	return Trash(s.Storage, filename)
+}
+
+// ListTrash forwards to the wrapped backend, if it has a trash
+func (s *CachedStorage) ListTrash() ([]TrashedFile, error) {
+	return ListTrash(s.Storage)
+}
+
+// DeleteTrashed forwards to the wrapped backend, if it has a trash
+func (s *CachedStorage) DeleteTrashed(filename string) error {
+	return DeleteTrashed(s.Storage, filename)
+}
+
+// unwrap returns the wrapped backend
+func (s *CachedStorage) unwrap() Storage {
+	return s.Storage
+}
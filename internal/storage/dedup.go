@@ -0,0 +1,401 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+const (
+	// DedupDirName is the hidden folder deduplicated destinations keep chunks in.
+	// Like other hidden files it is skipped when listing backups.
+	DedupDirName = ".stashr-chunks"
+
+	// dedupSaltFile holds the salt backups are encrypted with for deduplication
+	dedupSaltFile = DedupDirName + "/salt"
+	// dedupSaltLength matches the salt length of the encryption format
+	dedupSaltLength = 32
+
+	// Chunk sizes of stored backups: small enough that a changed vault item
+	// costs little, large enough to keep the number of objects down
+	dedupMinChunk = 8 << 10
+	dedupAvgChunk = 32 << 10
+	dedupMaxChunk = 128 << 10
+
+	// Piece sizes exports are split into before they are compressed and encrypted
+	pieceMinSize = 2 << 10
+	pieceAvgSize = 8 << 10
+	pieceMaxSize = 32 << 10
+
+	// maxManifestSize bounds the files read to find manifests, enough for
+	// backups of several hundred MB
+	maxManifestSize = 1 << 20
+)
+
+// dedupManifestMagic starts every manifest, as encoded from dedupManifest
+var dedupManifestMagic = []byte(`{"stashr_dedup":`)
+
+// dedupManifest is stored in place of a deduplicated backup and lists its chunks
+type dedupManifest struct {
+	Version int          `json:"stashr_dedup"`
+	Size    int64        `json:"size"`
+	SHA256  string       `json:"sha256"`
+	Chunks  []dedupChunk `json:"chunks"`
+}
+
+// dedupChunk is a piece of a backup, stored under its SHA-256 checksum
+type dedupChunk struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// DedupStorage stores backups on a backend as content-defined chunks named by
+// their checksum, plus a small manifest under the backup's name. Chunks shared
+// with earlier backups are stored once, so nightly backups of a mostly
+// unchanged vault take little extra space, provided the backups themselves
+// share their bytes (see DedupPieces). Deleting a backup deletes the chunks no
+// other backup uses.
+//
+// Backups stored before deduplication was enabled are read as they are, and
+// hidden files like leases bypass the chunk store. Deduplicated destinations
+// have no trash, and another host must not apply retention to them while
+// this one backs up, since it could delete a chunk just being reused.
+type DedupStorage struct {
+	Storage
+
+	mu sync.Mutex
+	// manifests are the manifests found so far by backup name
+	manifests map[string]dedupManifest
+	// plain are the backups found not to be manifests
+	plain map[string]bool
+}
+
+// NewDedupStorage wraps a storage backend so backups are stored deduplicated
+func NewDedupStorage(backend Storage) *DedupStorage {
+	return &DedupStorage{
+		Storage:   backend,
+		manifests: make(map[string]dedupManifest),
+		plain:     make(map[string]bool),
+	}
+}
+
+// DedupPieces splits an export into the content-defined pieces it should be
+// compressed and encrypted in for deduplication. Since an edit only changes
+// the pieces around it, backups built piece by piece share most of their bytes.
+func DedupPieces(data []byte) [][]byte {
+	return SplitChunks(data, pieceMinSize, pieceAvgSize, pieceMaxSize)
+}
+
+// ErrNotDeduplicated is returned when a destination doesn't deduplicate backups
+var ErrNotDeduplicated = errors.New("destination does not deduplicate backups")
+
+// dedupStorage returns the DedupStorage a backend is or wraps
+func dedupStorage(backend Storage) (*DedupStorage, bool) {
+	for {
+		if d, ok := backend.(*DedupStorage); ok {
+			return d, true
+		}
+		w, ok := backend.(wrapper)
+		if !ok {
+			return nil, false
+		}
+		backend = w.unwrap()
+	}
+}
+
+// IsDeduplicated reports whether a backend stores backups deduplicated
+func IsDeduplicated(backend Storage) bool {
+	_, ok := dedupStorage(backend)
+	return ok
+}
+
+// DedupSalt returns the salt backups for a deduplicated backend are encrypted
+// with, creating it on first use. Encryption is only deterministic, and the
+// backups share chunks, under a fixed salt. It returns ErrNotDeduplicated if
+// backend doesn't deduplicate.
+func DedupSalt(backend Storage) ([]byte, error) {
+	d, ok := dedupStorage(backend)
+	if !ok {
+		return nil, ErrNotDeduplicated
+	}
+	return d.salt()
+}
+
+// salt downloads the salt, or creates one if there is none yet. A salt that
+// can't be read is replaced, which only costs the space of one full backup.
+func (d *DedupStorage) salt() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if salt, err := d.Storage.Download(dedupSaltFile); err == nil && len(salt) == dedupSaltLength {
+		return salt, nil
+	}
+	salt := make([]byte, dedupSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if err := d.Storage.Upload(dedupSaltFile, salt); err != nil {
+		return nil, fmt.Errorf("failed to store salt: %w", err)
+	}
+	return salt, nil
+}
+
+// chunkPath returns the name a chunk is stored under
+func chunkPath(hash string) string {
+	return path.Join(DedupDirName, hash)
+}
+
+// load reads the manifests among backups that haven't been read yet and
+// forgets the ones that are gone
+func (d *DedupStorage) load(backups []BackupFile) error {
+	present := make(map[string]bool, len(backups))
+	for _, backup := range backups {
+		present[backup.Name] = true
+		if _, ok := d.manifests[backup.Name]; ok || d.plain[backup.Name] {
+			continue
+		}
+		if backup.Size > maxManifestSize {
+			d.plain[backup.Name] = true
+			continue
+		}
+
+		data, err := d.Storage.Download(backup.Name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", backup.Name, err)
+		}
+		if manifest, ok := parseManifest(data); ok {
+			d.manifests[backup.Name] = manifest
+		} else {
+			d.plain[backup.Name] = true
+		}
+	}
+	for name := range d.manifests {
+		if !present[name] {
+			delete(d.manifests, name)
+		}
+	}
+	return nil
+}
+
+// refresh lists the backups and loads their manifests
+func (d *DedupStorage) refresh() ([]BackupFile, error) {
+	backups, err := d.Storage.List()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.load(backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// parseManifest decodes data if it is a manifest
+func parseManifest(data []byte) (dedupManifest, bool) {
+	var manifest dedupManifest
+	if !bytes.HasPrefix(data, dedupManifestMagic) || json.Unmarshal(data, &manifest) != nil || manifest.Version != 1 {
+		return dedupManifest{}, false
+	}
+	return manifest, true
+}
+
+// referencedChunks returns the chunks the known manifests use
+func (d *DedupStorage) referencedChunks() map[string]bool {
+	chunks := make(map[string]bool)
+	for _, manifest := range d.manifests {
+		for _, chunk := range manifest.Chunks {
+			chunks[chunk.Hash] = true
+		}
+	}
+	return chunks
+}
+
+// Upload stores the chunks of a backup that aren't stored yet, then its manifest
+func (d *DedupStorage) Upload(filename string, data []byte) error {
+	return d.UploadWithProgress(filename, data, nil)
+}
+
+// UploadWithProgress stores a backup like Upload, reporting the bytes covered
+// by the chunks processed so far
+func (d *DedupStorage) UploadWithProgress(filename string, data []byte, progress ProgressFunc) error {
+	if hasIgnoredElement(filename) {
+		return UploadWithProgress(d.Storage, filename, data, progress)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, err := d.refresh(); err != nil {
+		return err
+	}
+	stored := d.referencedChunks()
+
+	manifest := dedupManifest{
+		Version: 1,
+		Size:    int64(len(data)),
+		SHA256:  utils.SHA256Hex(data),
+	}
+	var sent int64
+	for _, chunk := range SplitChunks(data, dedupMinChunk, dedupAvgChunk, dedupMaxChunk) {
+		hash := utils.SHA256Hex(chunk)
+		if !stored[hash] {
+			if err := d.Storage.Upload(chunkPath(hash), chunk); err != nil {
+				return fmt.Errorf("failed to upload chunk: %w", err)
+			}
+			stored[hash] = true
+		}
+		manifest.Chunks = append(manifest.Chunks, dedupChunk{Hash: hash, Size: int64(len(chunk))})
+
+		sent += int64(len(chunk))
+		if progress != nil {
+			progress(sent, int64(len(data)))
+		}
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if len(encoded) > maxManifestSize {
+		return fmt.Errorf("backup is too large to deduplicate (%s)", utils.FormatBytes(int64(len(data))))
+	}
+	if err := d.Storage.Upload(filename, encoded); err != nil {
+		return err
+	}
+	d.manifests[filename] = manifest
+	delete(d.plain, filename)
+	return nil
+}
+
+// Download reassembles a backup from its chunks, checking each of them
+func (d *DedupStorage) Download(filename string) ([]byte, error) {
+	data, err := d.Storage.Download(filename)
+	if err != nil || hasIgnoredElement(filename) {
+		return data, err
+	}
+	manifest, ok := parseManifest(data)
+	if !ok {
+		return data, nil // Stored before deduplication was enabled
+	}
+
+	backup := make([]byte, 0, manifest.Size)
+	for _, chunk := range manifest.Chunks {
+		chunkData, err := d.Storage.Download(chunkPath(chunk.Hash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to download chunk %s: %w", chunk.Hash, err)
+		}
+		if utils.SHA256Hex(chunkData) != chunk.Hash {
+			return nil, fmt.Errorf("chunk %s is corrupted", chunk.Hash)
+		}
+		backup = append(backup, chunkData...)
+	}
+	if int64(len(backup)) != manifest.Size || utils.SHA256Hex(backup) != manifest.SHA256 {
+		return nil, fmt.Errorf("reassembled backup doesn't match its manifest")
+	}
+	return backup, nil
+}
+
+// List lists the backups with the size they have when reassembled
+func (d *DedupStorage) List() ([]BackupFile, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	backups, err := d.refresh()
+	if err != nil {
+		return nil, err
+	}
+	for i, backup := range backups {
+		if manifest, ok := d.manifests[backup.Name]; ok {
+			backups[i].Size = manifest.Size
+		}
+	}
+	return backups, nil
+}
+
+// Delete deletes a backup and the chunks no other backup uses. Chunks that
+// fail to delete are left behind; they only take up space.
+func (d *DedupStorage) Delete(filename string) error {
+	if hasIgnoredElement(filename) {
+		return d.Storage.Delete(filename)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Another host may have added backups using the same chunks
+	if _, err := d.refresh(); err != nil {
+		return err
+	}
+	manifest, deduplicated := d.manifests[filename]
+	if err := d.Storage.Delete(filename); err != nil {
+		return err
+	}
+	delete(d.manifests, filename)
+	delete(d.plain, filename)
+	if !deduplicated {
+		return nil
+	}
+
+	referenced := d.referencedChunks()
+	for _, chunk := range manifest.Chunks {
+		if referenced[chunk.Hash] {
+			continue
+		}
+		referenced[chunk.Hash] = true // Chunks repeated within the backup are deleted once
+		_ = d.Storage.Delete(chunkPath(chunk.Hash))
+	}
+	return nil
+}
+
+// GetFreeSpace forwards to the wrapped backend, if it can report its free space
+func (d *DedupStorage) GetFreeSpace() (int64, error) {
+	return FreeSpace(d.Storage)
+}
+
+// SplitChunks splits data into content-defined chunks of minSize to maxSize
+// bytes, averaging about avgSize, which must be a power of two. A boundary is
+// placed where a rolling hash of the last bytes matches, so inserting or
+// removing bytes only moves the boundaries close to the change.
+func SplitChunks(data []byte, minSize, avgSize, maxSize int) [][]byte {
+	mask := uint64(avgSize - 1)
+	var chunks [][]byte
+	for len(data) > 0 {
+		end := len(data)
+		if end > minSize {
+			if end > maxSize {
+				end = maxSize
+			}
+			var hash uint64
+			for i := minSize; i < end; i++ {
+				hash = hash<<1 + gearTable[data[i]]
+				if hash&mask == 0 {
+					end = i + 1
+					break
+				}
+			}
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	return chunks
+}
+
+// gearTable maps bytes to the random values of the rolling hash. It must never
+// change: the chunks of new backups would no longer match the stored ones.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x5354415348520001) // splitmix64, seeded with "STASHR" and a version
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
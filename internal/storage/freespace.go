@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrFreeSpaceUnknown is returned when a destination can't report its free space
+var ErrFreeSpaceUnknown = errors.New("free space unknown")
+
+// FreeSpaceReporter is implemented by backends that can report how much more
+// they can store
+type FreeSpaceReporter interface {
+	// GetFreeSpace returns the free space in bytes
+	GetFreeSpace() (int64, error)
+}
+
+// FreeSpace returns the free space of a backend, or ErrFreeSpaceUnknown if it
+// can't report it
+func FreeSpace(backend Storage) (int64, error) {
+	if reporter, ok := backend.(FreeSpaceReporter); ok {
+		return reporter.GetFreeSpace()
+	}
+	return 0, ErrFreeSpaceUnknown
+}
+
+// PathFreeSpace returns the space available to the current user on the file
+// system holding path. Folders are created on the first upload, so the nearest
+// existing parent is measured.
+func PathFreeSpace(path string) (int64, error) {
+	dir := filepath.Clean(path)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return diskFreeSpace(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, ErrFreeSpaceUnknown
+		}
+		dir = parent
+	}
+}
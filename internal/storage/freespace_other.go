@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package storage
+
+// diskFreeSpace isn't supported on this platform
+func diskFreeSpace(dir string) (int64, error) {
+	return 0, ErrFreeSpaceUnknown
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package storage
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskFreeSpace returns the bytes available to the current user, after quotas, on the volume holding dir
+func diskFreeSpace(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, fmt.Errorf("failed to read free space of %s: %w", dir, err)
+	}
+	return int64(available), nil
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	gcs "google.golang.org/api/storage/v1"
+
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// GCS represents a Google Cloud Storage bucket backend
+type GCS struct {
+	Bucket string
+	Prefix string
+	// CredentialsPath is a service account key file; empty uses Application Default Credentials
+	CredentialsPath string
+
+	// ContentType is the MIME type uploads are stored with; empty uses DefaultContentType
+	ContentType string
+	// LockDays locks each uploaded backup for this many days, so it can't be
+	// deleted or overwritten until then; 0 uploads unlocked backups. The bucket
+	// needs object retention enabled.
+	LockDays int
+
+	service *gcs.Service
+}
+
+// NewGCS creates a new Google Cloud Storage backend
+func NewGCS(bucket, prefix, credentialsPath string) *GCS {
+	return &GCS{
+		Bucket:          bucket,
+		Prefix:          strings.Trim(prefix, "/"),
+		CredentialsPath: credentialsPath,
+	}
+}
+
+// Name returns the name of the storage backend
+func (g *GCS) Name() string {
+	return "Google Cloud Storage"
+}
+
+// IsAvailable checks if the bucket is reachable with the configured credentials
+func (g *GCS) IsAvailable() (bool, error) {
+	if g.Bucket == "" {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  "bucket not configured",
+		}
+	}
+	if g.CredentialsPath != "" && !utils.FileExists(g.CredentialsPath) {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  fmt.Sprintf("service account key not found at %s", g.CredentialsPath),
+		}
+	}
+
+	if err := g.initService(); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  fmt.Sprintf("failed to initialize service: %v", err),
+		}
+	}
+
+	// Listing needs only object permissions, unlike reading the bucket metadata
+	if _, err := g.service.Objects.List(g.Bucket).Prefix(g.objectPrefix()).MaxResults(1).Do(); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  fmt.Sprintf("failed to access bucket %s: %v", g.Bucket, err),
+		}
+	}
+
+	return true, nil
+}
+
+// initService initializes the Cloud Storage service
+func (g *GCS) initService() error {
+	if g.service != nil {
+		return nil // Already initialized
+	}
+
+	ctx := oauthContext(context.Background())
+	var creds *google.Credentials
+	var err error
+	if g.CredentialsPath != "" {
+		data, readErr := os.ReadFile(g.CredentialsPath)
+		if readErr != nil {
+			return fmt.Errorf("unable to read credentials file: %w", readErr)
+		}
+		creds, err = google.CredentialsFromJSON(ctx, data, gcs.DevstorageReadWriteScope)
+	} else {
+		creds, err = google.FindDefaultCredentials(ctx, gcs.DevstorageReadWriteScope)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	// The client connects, and refreshes its token, through the configured proxy
+	service, err := gcs.NewService(ctx, option.WithHTTPClient(oauth2.NewClient(ctx, creds.TokenSource)))
+	if err != nil {
+		return fmt.Errorf("failed to create Cloud Storage service: %w", err)
+	}
+
+	g.service = service
+	return nil
+}
+
+// objectPrefix returns the prefix shared by all backup objects, with a trailing slash
+func (g *GCS) objectPrefix() string {
+	if g.Prefix == "" {
+		return ""
+	}
+	return g.Prefix + "/"
+}
+
+// objectName returns the object name of a backup file
+func (g *GCS) objectName(filename string) string {
+	return path.Join(g.Prefix, filename)
+}
+
+// isNotFound reports whether a Google API error is a 404
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// Upload uploads a file to the bucket
+func (g *GCS) Upload(filename string, data []byte) error {
+	if err := g.initService(); err != nil {
+		return &UploadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	object := &gcs.Object{
+		Name:        g.objectName(filename),
+		ContentType: contentTypeOrDefault(g.ContentType),
+	}
+	if g.LockDays > 0 {
+		object.Retention = &gcs.ObjectRetention{
+			Mode:            "Locked",
+			RetainUntilTime: lockUntil(g.LockDays).Format(time.RFC3339),
+		}
+	}
+	if _, err := g.service.Objects.Insert(g.Bucket, object).Media(bytes.NewReader(data)).Do(); err != nil {
+		return &UploadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
+	}
+
+	return nil
+}
+
+// Download downloads a file from the bucket
+func (g *GCS) Download(filename string) ([]byte, error) {
+	if err := g.initService(); err != nil {
+		return nil, &DownloadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	response, err := g.service.Objects.Get(g.Bucket, g.objectName(filename)).Download()
+	if isNotFound(err) {
+		return nil, &DownloadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("file not found"),
+		}
+	}
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file content: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// List lists all backup files under the prefix, including those in subfolders
+func (g *GCS) List() ([]BackupFile, error) {
+	if err := g.initService(); err != nil {
+		return nil, err
+	}
+
+	var backups []BackupFile
+	call := g.service.Objects.List(g.Bucket).Prefix(g.objectPrefix()).
+		Fields("nextPageToken", "items(name,size,updated)")
+	err := call.Pages(context.Background(), func(objects *gcs.Objects) error {
+		for _, object := range objects.Items {
+			// Names are relative to the prefix, keeping any subfolder
+			name := strings.TrimPrefix(object.Name, g.objectPrefix())
+			if name == "" || strings.HasSuffix(name, "/") || hasIgnoredElement(name) {
+				continue
+			}
+
+			modTime, _ := time.Parse(time.RFC3339, object.Updated)
+			backups = append(backups, BackupFile{
+				Name:         name,
+				Size:         int64(object.Size),
+				ModifiedTime: modTime,
+				Location:     fmt.Sprintf("gs://%s/%s", g.Bucket, object.Name),
+				StorageType:  g.Name(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file from the bucket
+func (g *GCS) Delete(filename string) error {
+	if err := g.initService(); err != nil {
+		return err
+	}
+
+	err := g.service.Objects.Delete(g.Bucket, g.objectName(filename)).Do()
+	if isNotFound(err) {
+		return fmt.Errorf("file not found")
+	}
+	if err != nil {
+		// Deleting an object under retention is forbidden
+		if lockErr := checkUnlocked(g, filename); lockErr != nil {
+			return lockErr
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// StoredChecksum returns the MD5 checksum Cloud Storage recorded for an object.
+// Composite objects have none.
+func (g *GCS) StoredChecksum(filename string) (string, string, error) {
+	if err := g.initService(); err != nil {
+		return "", "", err
+	}
+
+	object, err := g.service.Objects.Get(g.Bucket, g.objectName(filename)).Fields("md5Hash").Do()
+	if isNotFound(err) {
+		return "", "", fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if object.Md5Hash == "" {
+		return "", "", ErrChecksumUnavailable
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(object.Md5Hash)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid MD5 hash %q: %w", object.Md5Hash, err)
+	}
+	return "md5", hex.EncodeToString(sum), nil
+}
+
+// LockedUntil returns when an object's retention expires, whether it was set
+// on upload or by the bucket's retention policy
+func (g *GCS) LockedUntil(filename string) (time.Time, error) {
+	if err := g.initService(); err != nil {
+		return time.Time{}, err
+	}
+
+	object, err := g.service.Objects.Get(g.Bucket, g.objectName(filename)).
+		Fields("retention", "retentionExpirationTime").Do()
+	if isNotFound(err) {
+		return time.Time{}, fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	var until time.Time
+	if object.Retention != nil {
+		until, _ = time.Parse(time.RFC3339, object.Retention.RetainUntilTime)
+	}
+	if expiration, err := time.Parse(time.RFC3339, object.RetentionExpirationTime); err == nil && expiration.After(until) {
+		until = expiration
+	}
+	return until, nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (g *GCS) CleanOldBackups(keepLast int) error {
+	backups, err := g.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, g.Delete)
+	return err
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// annexKeySize extracts the size field from a git-annex key (e.g. SHA256E-s1234--<hash>.enc)
+var annexKeySize = regexp.MustCompile(`-s(\d+)--`)
+
+// GitAnnex represents a git-annex repository storage backend.
+// Backups are added to the annex and committed so git-annex tracks their
+// location, then copied to any configured special remotes.
+type GitAnnex struct {
+	RepoPath  string
+	BackupDir string
+	Remotes   []string
+}
+
+// NewGitAnnex creates a new git-annex storage backend
+func NewGitAnnex(repoPath, backupDir string, remotes []string) *GitAnnex {
+	return &GitAnnex{
+		RepoPath:  repoPath,
+		BackupDir: backupDir,
+		Remotes:   remotes,
+	}
+}
+
+// Name returns the name of the storage backend
+func (g *GitAnnex) Name() string {
+	return "git-annex"
+}
+
+// IsAvailable checks if git-annex is installed and the repository is initialized
+func (g *GitAnnex) IsAvailable() (bool, error) {
+	if !utils.CommandExists("git-annex") {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  "git-annex is not installed",
+		}
+	}
+
+	if !utils.DirExists(g.RepoPath) {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  fmt.Sprintf("repository %s does not exist", g.RepoPath),
+		}
+	}
+
+	// An initialized annex has a UUID in its git config
+	if _, err := g.git("config", "annex.uuid"); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: g.Name(),
+			Reason:  fmt.Sprintf("%s is not a git-annex repository (run: git annex init)", g.RepoPath),
+		}
+	}
+
+	return true, nil
+}
+
+// relPath returns the path of a backup relative to the repository root
+func (g *GitAnnex) relPath(filename string) string {
+	return filepath.ToSlash(filepath.Join(g.BackupDir, filename))
+}
+
+// Upload adds a file to the annex, commits it and copies it to the configured remotes
+func (g *GitAnnex) Upload(filename string, data []byte) error {
+	filePath := filepath.Join(g.RepoPath, filepath.FromSlash(g.relPath(filename)))
+	if err := utils.CreateDirIfNotExists(filepath.Dir(filePath), 0700); err != nil {
+		return &UploadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to create backup directory: %w", err),
+		}
+	}
+
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return &UploadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to write file: %w", err),
+		}
+	}
+
+	relPath := g.relPath(filename)
+	if _, err := g.git("annex", "add", "--quiet", relPath); err != nil {
+		return &UploadError{Storage: g.Name(), File: filename, Err: err}
+	}
+	if _, err := g.git("commit", "--quiet", "-m", "stashr: add "+filename, "--", relPath); err != nil {
+		return &UploadError{Storage: g.Name(), File: filename, Err: err}
+	}
+
+	for _, remote := range g.Remotes {
+		if _, err := g.git("annex", "copy", "--quiet", "--to", remote, relPath); err != nil {
+			return &UploadError{
+				Storage: g.Name(),
+				File:    filename,
+				Err:     fmt.Errorf("failed to copy to remote %s: %w", remote, err),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Download retrieves a file's content, fetching it from a remote if it is not present locally
+func (g *GitAnnex) Download(filename string) ([]byte, error) {
+	relPath := g.relPath(filename)
+	if _, err := g.git("annex", "get", "--quiet", relPath); err != nil {
+		return nil, &DownloadError{Storage: g.Name(), File: filename, Err: err}
+	}
+
+	data, err := os.ReadFile(filepath.Join(g.RepoPath, relPath))
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// List lists all backups tracked in the annex, including those in subfolders and
+// those whose content is only on remotes
+func (g *GitAnnex) List() ([]BackupFile, error) {
+	output, err := g.git("ls-files", "-z", "--", g.BackupDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list annex files: %w", err)
+	}
+
+	var backups []BackupFile
+	for _, relPath := range strings.Split(string(output), "\x00") {
+		if relPath == "" {
+			continue
+		}
+
+		// Names are relative to the backup directory, keeping any subfolder
+		name := relPath
+		if g.BackupDir != "" {
+			name = strings.TrimPrefix(relPath, strings.Trim(g.BackupDir, "/")+"/")
+		}
+		if hasIgnoredElement(name) {
+			continue
+		}
+
+		fullPath := filepath.Join(g.RepoPath, relPath)
+		info, err := os.Lstat(fullPath)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, BackupFile{
+			Name:         name,
+			Size:         g.annexedSize(fullPath, info),
+			ModifiedTime: info.ModTime(),
+			Location:     fullPath,
+			StorageType:  g.Name(),
+		})
+	}
+
+	return backups, nil
+}
+
+// annexedSize returns the content size of an annexed file, which is encoded in
+// the key its symlink points to, so it is known even when content is not present
+func (g *GitAnnex) annexedSize(path string, info os.FileInfo) int64 {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(path); err == nil {
+			if match := annexKeySize.FindStringSubmatch(filepath.Base(target)); match != nil {
+				if size, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+					return size
+				}
+			}
+		}
+	}
+	return info.Size()
+}
+
+// Delete drops the local content of a file and removes it from the repository
+func (g *GitAnnex) Delete(filename string) error {
+	relPath := g.relPath(filename)
+
+	// Dropping local content is best effort; other copies are left to git-annex
+	_, _ = g.git("annex", "drop", "--quiet", "--force", relPath)
+
+	if _, err := g.git("rm", "--quiet", "--", relPath); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if _, err := g.git("commit", "--quiet", "-m", "stashr: remove "+filename, "--", relPath); err != nil {
+		return fmt.Errorf("failed to commit deletion: %w", err)
+	}
+
+	return nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (g *GitAnnex) CleanOldBackups(keepLast int) error {
+	backups, err := g.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, g.Delete)
+	return err
+}
+
+// git runs a git command in the repository and returns its output
+func (g *GitAnnex) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.RepoPath}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, fmt.Errorf("git %s failed: %w (output: %s)", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
@@ -1,36 +1,86 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
+const (
+	// googleDriveChunkSize is the resumable upload chunk size; Drive requires a multiple of 256 KiB
+	googleDriveChunkSize = 32 * 256 * 1024
+
+	// googleDriveMaxRetries is how many transient failures in a row an upload survives
+	googleDriveMaxRetries = 5
+
+	// googleDriveChunkTimeout bounds each upload request so a stalled connection is retried
+	googleDriveChunkTimeout = 5 * time.Minute
+
+	// googleDriveSignInTimeout is how long the browser sign-in may take
+	googleDriveSignInTimeout = 5 * time.Minute
+
+	// googleDriveMaxPageSize is the most files Drive returns per listing request
+	googleDriveMaxPageSize = 1000
+
+	// googleDriveFolderMimeType is the MIME type of Drive folders
+	googleDriveFolderMimeType = "application/vnd.google-apps.folder"
+
+	// googleDriveTrashedProperty is the app property recording when stashr
+	// trashed a backup, so backups users trashed themselves are left alone
+	googleDriveTrashedProperty = "stashrTrashedAt"
+)
+
+// googleDriveUploadURL starts a resumable upload session
+var googleDriveUploadURL = "https://www.googleapis.com/upload/drive/v3/files?uploadType=resumable&supportsAllDrives=true&fields=id"
+
+// googleDriveUpdateURL starts a resumable upload session replacing the content of a file
+var googleDriveUpdateURL = "https://www.googleapis.com/upload/drive/v3/files/%s?uploadType=resumable&supportsAllDrives=true&fields=id"
+
 // GoogleDrive represents a Google Drive storage backend
 type GoogleDrive struct {
+	// CredentialsPath is an OAuth client or a service account key
 	CredentialsPath string
 	FolderID        string
-	service         *drive.Service
+	// DriveID selects a Shared Drive; empty uses My Drive
+	DriveID string
+
+	// ContentType is the MIME type uploads are stored with; empty uses DefaultContentType
+	ContentType string
+	// PageSize is how many files each listing request returns; 0 uses the maximum
+	PageSize int64
+
+	service *drive.Service
+	client  *http.Client
 }
 
 // NewGoogleDrive creates a new Google Drive storage backend
-func NewGoogleDrive(credentialsPath, folderID string) *GoogleDrive {
+func NewGoogleDrive(credentialsPath, folderID, driveID string) *GoogleDrive {
 	return &GoogleDrive{
 		CredentialsPath: credentialsPath,
 		FolderID:        folderID,
+		DriveID:         driveID,
 	}
 }
 
@@ -66,7 +116,7 @@ func (g *GoogleDrive) initService() error {
 		return nil // Already initialized
 	}
 
-	ctx := context.Background()
+	ctx := oauthContext(context.Background())
 
 	// Read credentials file
 	credData, err := os.ReadFile(g.CredentialsPath)
@@ -74,19 +124,27 @@ func (g *GoogleDrive) initService() error {
 		return fmt.Errorf("failed to read credentials file: %w", err)
 	}
 
-	// Parse credentials
-	config, err := google.ConfigFromJSON(credData, drive.DriveFileScope)
-	if err != nil {
-		return fmt.Errorf("failed to parse credentials: %w", err)
-	}
-
-	// Get token file path
-	tokenPath := g.getTokenPath()
+	var client *http.Client
+	if isServiceAccountKey(credData) {
+		// Service accounts only see files shared with them, so they get the full Drive
+		// scope to reach the shared folder or Shared Drive
+		jwtConfig, err := google.JWTConfigFromJSON(credData, drive.DriveScope)
+		if err != nil {
+			return fmt.Errorf("failed to parse service account key: %w", err)
+		}
+		client = jwtConfig.Client(ctx)
+	} else {
+		// Parse credentials
+		config, err := google.ConfigFromJSON(credData, drive.DriveFileScope)
+		if err != nil {
+			return fmt.Errorf("failed to parse credentials: %w", err)
+		}
 
-	// Get client
-	client, err := g.getClient(ctx, config, tokenPath)
-	if err != nil {
-		return fmt.Errorf("failed to get client: %w", err)
+		// Get client
+		client, err = g.getClient(ctx, config, g.TokenPath())
+		if err != nil {
+			return fmt.Errorf("failed to get client: %w", err)
+		}
 	}
 
 	// Create Drive service
@@ -96,11 +154,126 @@ func (g *GoogleDrive) initService() error {
 	}
 
 	g.service = service
+	g.client = client
 	return nil
 }
 
-// getTokenPath returns the path to the token file
-func (g *GoogleDrive) getTokenPath() string {
+// IsServiceAccount reports whether the credentials file is a service account key,
+// which signs in without a browser and has no token file
+func (g *GoogleDrive) IsServiceAccount() bool {
+	credData, err := os.ReadFile(g.CredentialsPath)
+	return err == nil && isServiceAccountKey(credData)
+}
+
+// isServiceAccountKey reports whether credentials JSON is a service account key
+func isServiceAccountKey(credData []byte) bool {
+	var credentials struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(credData, &credentials) == nil && credentials.Type == "service_account"
+}
+
+// parentID returns the folder new backups are created in, if any
+func (g *GoogleDrive) parentID() string {
+	if g.FolderID != "" {
+		return g.FolderID
+	}
+	// The root folder of a Shared Drive has the drive's ID
+	return g.DriveID
+}
+
+// listFiles returns a file listing call for a query, covering the Shared Drive if set
+func (g *GoogleDrive) listFiles(query string) *drive.FilesListCall {
+	call := g.service.Files.List().Q(query).SupportsAllDrives(true)
+	if g.DriveID != "" {
+		call = call.Corpora("drive").DriveId(g.DriveID).IncludeItemsFromAllDrives(true)
+	}
+	return call
+}
+
+// listAllFiles returns every file matching a query, following the pages of the
+// listing. fields selects the fields of each file, e.g. "id, name".
+func (g *GoogleDrive) listAllFiles(query, fields string) ([]*drive.File, error) {
+	pageSize := g.PageSize
+	if pageSize <= 0 || pageSize > googleDriveMaxPageSize {
+		pageSize = googleDriveMaxPageSize
+	}
+
+	var files []*drive.File
+	call := g.listFiles(query).
+		Fields(googleapi.Field("nextPageToken, files(" + fields + ")")).
+		PageSize(pageSize)
+	err := call.Pages(context.Background(), func(page *drive.FileList) error {
+		files = append(files, page.Files...)
+		return nil
+	})
+	return files, err
+}
+
+// subfolderID returns the ID of a slash-separated subfolder of the backup folder,
+// creating missing folders if create is set. It returns "" if a folder doesn't
+// exist and create is not set.
+func (g *GoogleDrive) subfolderID(dir string, create bool) (string, error) {
+	parent := g.parentID()
+	if dir == "" || dir == "." {
+		return parent, nil
+	}
+	if parent == "" {
+		parent = "root"
+	}
+
+	for _, name := range strings.Split(dir, "/") {
+		query := fmt.Sprintf("name='%s' and mimeType='%s' and '%s' in parents and trashed=false", name, googleDriveFolderMimeType, parent)
+		fileList, err := g.listFiles(query).Fields("files(id)").Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to find folder %s: %w", name, err)
+		}
+		if len(fileList.Files) > 0 {
+			parent = fileList.Files[0].Id
+			continue
+		}
+		if !create {
+			return "", nil
+		}
+
+		folder := &drive.File{Name: name, MimeType: googleDriveFolderMimeType, Parents: []string{parent}}
+		created, err := g.service.Files.Create(folder).SupportsAllDrives(true).Fields("id").Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to create folder %s: %w", name, err)
+		}
+		parent = created.Id
+	}
+
+	return parent, nil
+}
+
+// findFileID returns the ID of a backup, which may be in a subfolder, or "" if it doesn't exist
+func (g *GoogleDrive) findFileID(filename string) (string, error) {
+	dir, name := path.Split(filename)
+	parent, err := g.subfolderID(strings.TrimSuffix(dir, "/"), false)
+	if err != nil {
+		return "", err
+	}
+	if dir != "" && parent == "" {
+		return "", nil
+	}
+
+	query := fmt.Sprintf("name='%s' and trashed=false", name)
+	if parent != "" {
+		query += fmt.Sprintf(" and '%s' in parents", parent)
+	}
+	fileList, err := g.listFiles(query).Fields("files(id)").Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to list files: %w", err)
+	}
+	if len(fileList.Files) == 0 {
+		return "", nil
+	}
+	return fileList.Files[0].Id, nil
+}
+
+// TokenPath returns the path to the OAuth token file, stored next to the credentials
+func (g *GoogleDrive) TokenPath() string {
 	dir := filepath.Dir(g.CredentialsPath)
 	return filepath.Join(dir, "gdrive-token.json")
 }
@@ -111,7 +284,7 @@ func (g *GoogleDrive) getClient(ctx context.Context, config *oauth2.Config, toke
 	token, err := g.loadToken(tokenPath)
 	if err != nil {
 		// If token doesn't exist or is invalid, get a new one
-		token, err = g.getTokenFromWeb(ctx, config)
+		token, err = g.getTokenFromBrowser(ctx, config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get token: %w", err)
 		}
@@ -121,7 +294,15 @@ func (g *GoogleDrive) getClient(ctx context.Context, config *oauth2.Config, toke
 		}
 	}
 
-	return config.Client(ctx, token), nil
+	// Persist refreshed tokens, so a rotated refresh token isn't lost
+	source := &savingTokenSource{
+		source: config.TokenSource(ctx, token),
+		last:   token,
+		save: func(token *oauth2.Token) error {
+			return g.saveToken(tokenPath, token)
+		},
+	}
+	return oauth2.NewClient(ctx, source), nil
 }
 
 // loadToken loads a token from a file
@@ -151,18 +332,76 @@ func (g *GoogleDrive) saveToken(path string, token *oauth2.Token) error {
 	return json.NewEncoder(file).Encode(token)
 }
 
-// getTokenFromWeb requests a token from the web
-func (g *GoogleDrive) getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser:\n%v\n\n", authURL)
-	fmt.Print("Enter authorization code: ")
-
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		return nil, fmt.Errorf("failed to read authorization code: %w", err)
-	}
-
-	token, err := config.Exchange(ctx, authCode)
+// getTokenFromBrowser signs in through the browser. Google redirects back to a
+// temporary server on the loopback interface, which captures the authorization code.
+func (g *GoogleDrive) getTokenFromBrowser(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start sign-in callback server: %w", err)
+	}
+	defer listener.Close()
+
+	// Desktop app clients accept any loopback port
+	redirectConfig := *config
+	redirectConfig.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr())
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate sign-in state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+	verifier := oauth2.GenerateVerifier()
+	authURL := redirectConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Ignore requests that aren't the redirect, such as /favicon.ico
+			query := r.URL.Query()
+			if r.URL.Path != "/" || query.Get("state") != state {
+				http.NotFound(w, r)
+				return
+			}
+
+			var result callbackResult
+			if reason := query.Get("error"); reason != "" {
+				result.err = fmt.Errorf("sign-in was not completed: %s", reason)
+				fmt.Fprintln(w, "Google Drive sign-in failed. You can close this window and check the terminal.")
+			} else {
+				result.code = query.Get("code")
+				fmt.Fprintln(w, "Signed in to Google Drive. You can close this window and return to stashr.")
+			}
+			select {
+			case results <- result:
+			default:
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Opening your browser to sign in to Google Drive. If it doesn't open, visit:\n%v\n\n", authURL)
+	if err := utils.OpenBrowser(authURL); err != nil {
+		fmt.Println("Could not open a browser; open the link above on this machine.")
+	}
+	fmt.Println("Waiting for sign-in to complete...")
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-time.After(googleDriveSignInTimeout):
+		return nil, fmt.Errorf("sign-in timed out after %s", googleDriveSignInTimeout)
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := redirectConfig.Exchange(ctx, result.code, oauth2.VerifierOption(verifier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
 	}
@@ -172,6 +411,13 @@ func (g *GoogleDrive) getTokenFromWeb(ctx context.Context, config *oauth2.Config
 
 // Upload uploads a file to Google Drive
 func (g *GoogleDrive) Upload(filename string, data []byte) error {
+	return g.UploadWithProgress(filename, data, nil)
+}
+
+// UploadWithProgress uploads a file to Google Drive in chunks through a resumable
+// upload session, reporting progress after each chunk. Transient failures resume
+// from the last byte Drive received instead of starting over.
+func (g *GoogleDrive) UploadWithProgress(filename string, data []byte, progress ProgressFunc) error {
 	if err := g.initService(); err != nil {
 		return &UploadError{
 			Storage: g.Name(),
@@ -180,30 +426,203 @@ func (g *GoogleDrive) Upload(filename string, data []byte) error {
 		}
 	}
 
-	// Create file metadata
-	file := &drive.File{
-		Name: filename,
+	if err := g.resumableUpload(filename, data, progress); err != nil {
+		return &UploadError{
+			Storage: g.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
 	}
 
-	// If folder ID is specified, set parent
-	if g.FolderID != "" {
-		file.Parents = []string{g.FolderID}
+	return nil
+}
+
+// resumableUpload runs the Drive resumable upload protocol
+func (g *GoogleDrive) resumableUpload(filename string, data []byte, progress ProgressFunc) error {
+	total := int64(len(data))
+
+	var sessionURL string
+	var offset int64
+	var resuming bool
+	failures := 0
+	for {
+		next := offset
+		var err error
+		var done bool
+		switch {
+		case sessionURL == "":
+			sessionURL, err = g.startUploadSession(filename, total)
+		case resuming:
+			// Ask Drive how much it received before the failure
+			next, done, err = g.putUploadChunk(sessionURL, nil, fmt.Sprintf("bytes */%d", total))
+		default:
+			end := min(offset+googleDriveChunkSize, total)
+			contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, end-1, total)
+			if total == 0 {
+				contentRange = "bytes */0"
+			}
+			next, done, err = g.putUploadChunk(sessionURL, data[offset:end], contentRange)
+			if err == nil && !done && next <= offset {
+				err = &transientUploadError{err: fmt.Errorf("Drive accepted none of bytes %d-%d", offset, end-1)}
+			}
+		}
+
+		if err != nil {
+			var transient *transientUploadError
+			if !errors.As(err, &transient) {
+				return err
+			}
+			failures++
+			if failures > googleDriveMaxRetries {
+				return fmt.Errorf("giving up after %d retries: %w", googleDriveMaxRetries, err)
+			}
+			time.Sleep(time.Duration(1<<(failures-1)) * time.Second)
+			resuming = sessionURL != ""
+			continue
+		}
+
+		failures = 0
+		resuming = false
+		if done {
+			next = total
+		}
+		if progress != nil && next > offset {
+			progress(next, total)
+		}
+		offset = next
+		if done {
+			return nil
+		}
 	}
+}
 
-	// Create file reader
-	reader := strings.NewReader(string(data))
+// startUploadSession creates a resumable upload session and returns its URL. A
+// file that already has the name is replaced, as Drive would otherwise keep both.
+func (g *GoogleDrive) startUploadSession(filename string, size int64) (string, error) {
+	dir, name := path.Split(filename)
+	parent, err := g.subfolderID(strings.TrimSuffix(dir, "/"), true)
+	if err != nil {
+		return "", err
+	}
+	existingID, err := g.findFileID(filename)
+	if err != nil {
+		return "", err
+	}
 
-	// Upload file
-	_, err := g.service.Files.Create(file).Media(reader).Do()
+	method, target := http.MethodPost, googleDriveUploadURL
+	metadata := &drive.File{Name: name, MimeType: contentTypeOrDefault(g.ContentType)}
+	if existingID != "" {
+		method, target = http.MethodPatch, fmt.Sprintf(googleDriveUpdateURL, existingID)
+	} else if parent != "" {
+		metadata.Parents = []string{parent}
+	}
+	body, err := json.Marshal(metadata)
 	if err != nil {
-		return &UploadError{
-			Storage: g.Name(),
-			File:    filename,
-			Err:     fmt.Errorf("failed to upload file: %w", err),
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), googleDriveChunkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", contentTypeOrDefault(g.ContentType))
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", &transientUploadError{err: fmt.Errorf("failed to start upload session: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", uploadResponseError("failed to start upload session", resp)
+	}
+	sessionURL := resp.Header.Get("Location")
+	if sessionURL == "" {
+		return "", fmt.Errorf("failed to start upload session: no session URL returned")
+	}
+
+	return sessionURL, nil
+}
+
+// putUploadChunk sends a chunk, or only queries the session when chunk is nil. It
+// returns how many bytes Drive has received and whether the upload is complete.
+func (g *GoogleDrive) putUploadChunk(sessionURL string, chunk []byte, contentRange string) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), googleDriveChunkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, bytes.NewReader(chunk))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Range", contentRange)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, false, &transientUploadError{err: err}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return 0, true, nil
+	case http.StatusPermanentRedirect:
+		// "Resume Incomplete": Range holds the bytes received so far, e.g. "bytes=0-524287"
+		received := resp.Header.Get("Range")
+		if received == "" {
+			return 0, false, nil
+		}
+		_, last, found := strings.Cut(received, "-")
+		end, err := strconv.ParseInt(last, 10, 64)
+		if !found || err != nil {
+			return 0, false, fmt.Errorf("invalid Range header in upload response: %q", received)
 		}
+		return end + 1, false, nil
+	case http.StatusNotFound, http.StatusGone:
+		return 0, false, fmt.Errorf("upload session expired")
+	default:
+		return 0, false, uploadResponseError("upload failed", resp)
 	}
+}
 
-	return nil
+// transientUploadError is an upload failure worth retrying, such as a dropped
+// connection or a server error
+type transientUploadError struct {
+	err error
+}
+
+func (e *transientUploadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientUploadError) Unwrap() error {
+	return e.err
+}
+
+// Transient marks the error as worth retrying, see IsTransient
+func (e *transientUploadError) Transient() bool {
+	return true
+}
+
+// uploadResponseError describes an unsuccessful upload response, marking server
+// errors and rate limiting as transient
+func uploadResponseError(message string, resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err := fmt.Errorf("%s: %s", message, resp.Status)
+	if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error.Message != "" {
+		err = fmt.Errorf("%s: %s (%s)", message, body.Error.Message, resp.Status)
+	}
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return &transientUploadError{err: err}
+	}
+	return err
 }
 
 // Download downloads a file from Google Drive
@@ -217,21 +636,16 @@ func (g *GoogleDrive) Download(filename string) ([]byte, error) {
 	}
 
 	// Find file by name
-	query := fmt.Sprintf("name='%s' and trashed=false", filename)
-	if g.FolderID != "" {
-		query += fmt.Sprintf(" and '%s' in parents", g.FolderID)
-	}
-
-	fileList, err := g.service.Files.List().Q(query).Fields("files(id)").Do()
+	fileID, err := g.findFileID(filename)
 	if err != nil {
 		return nil, &DownloadError{
 			Storage: g.Name(),
 			File:    filename,
-			Err:     fmt.Errorf("failed to list files: %w", err),
+			Err:     err,
 		}
 	}
 
-	if len(fileList.Files) == 0 {
+	if fileID == "" {
 		return nil, &DownloadError{
 			Storage: g.Name(),
 			File:    filename,
@@ -240,7 +654,7 @@ func (g *GoogleDrive) Download(filename string) ([]byte, error) {
 	}
 
 	// Get file content
-	response, err := g.service.Files.Get(fileList.Files[0].Id).Download()
+	response, err := g.service.Files.Get(fileID).SupportsAllDrives(true).Download()
 	if err != nil {
 		return nil, &DownloadError{
 			Storage: g.Name(),
@@ -262,38 +676,58 @@ func (g *GoogleDrive) Download(filename string) ([]byte, error) {
 	return data, nil
 }
 
-// List lists all backup files in Google Drive
+// List lists all backup files in Google Drive, including those in subfolders
 func (g *GoogleDrive) List() ([]BackupFile, error) {
 	if err := g.initService(); err != nil {
 		return nil, err
 	}
 
-	// Build query
-	query := "trashed=false"
-	if g.FolderID != "" {
-		query += fmt.Sprintf(" and '%s' in parents", g.FolderID)
+	parent := g.parentID()
+	if parent == "" {
+		parent = "root"
 	}
 
-	// List files
-	fileList, err := g.service.Files.List().
-		Q(query).
-		Fields("files(id, name, size, modifiedTime)").
-		OrderBy("modifiedTime desc").
-		Do()
+	backups, err := g.listFolder(parent, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first across subfolders
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModifiedTime.After(backups[j].ModifiedTime)
+	})
+	return backups, nil
+}
+
+// listFolder lists the backups in a folder and its subfolders, naming them by
+// their path below the backup folder
+func (g *GoogleDrive) listFolder(folderID, dir string) ([]BackupFile, error) {
+	query := fmt.Sprintf("'%s' in parents and trashed=false", folderID)
+	files, err := g.listAllFiles(query, "id, name, size, modifiedTime, mimeType")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
 	var backups []BackupFile
-	for _, file := range fileList.Files {
-		// Skip hidden/system files (e.g., ._ files, .DS_Store)
+	for _, file := range files {
+		// Skip hidden/system files and folders (e.g., ._ files, .DS_Store)
 		if shouldIgnoreFile(file.Name) {
 			continue
 		}
+		name := path.Join(dir, file.Name)
+
+		if file.MimeType == googleDriveFolderMimeType {
+			children, err := g.listFolder(file.Id, name)
+			if err != nil {
+				return nil, err
+			}
+			backups = append(backups, children...)
+			continue
+		}
 
 		modTime, _ := time.Parse(time.RFC3339, file.ModifiedTime)
 		backups = append(backups, BackupFile{
-			Name:         file.Name,
+			Name:         name,
 			Size:         file.Size,
 			ModifiedTime: modTime,
 			Location:     file.Id,
@@ -311,28 +745,179 @@ func (g *GoogleDrive) Delete(filename string) error {
 	}
 
 	// Find file by name
-	query := fmt.Sprintf("name='%s' and trashed=false", filename)
-	if g.FolderID != "" {
-		query += fmt.Sprintf(" and '%s' in parents", g.FolderID)
-	}
-
-	fileList, err := g.service.Files.List().Q(query).Fields("files(id)").Do()
+	fileID, err := g.findFileID(filename)
 	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
+		return err
 	}
 
-	if len(fileList.Files) == 0 {
+	if fileID == "" {
 		return fmt.Errorf("file not found")
 	}
 
 	// Delete file
-	if err := g.service.Files.Delete(fileList.Files[0].Id).Do(); err != nil {
+	if err := g.service.Files.Delete(fileID).SupportsAllDrives(true).Do(); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
 
 	return nil
 }
 
+// Trash moves a backup to the Google Drive trash. Drive permanently deletes
+// trashed files after 30 days, whatever the grace period.
+func (g *GoogleDrive) Trash(filename string) error {
+	if err := g.initService(); err != nil {
+		return err
+	}
+
+	fileID, err := g.findFileID(filename)
+	if err != nil {
+		return err
+	}
+	if fileID == "" {
+		return fmt.Errorf("file not found")
+	}
+
+	update := &drive.File{
+		Trashed:       true,
+		AppProperties: map[string]string{googleDriveTrashedProperty: time.Now().UTC().Format(time.RFC3339)},
+	}
+	if _, err := g.service.Files.Update(fileID, update).SupportsAllDrives(true).Fields("id").Do(); err != nil {
+		return fmt.Errorf("failed to move file to trash: %w", err)
+	}
+	return nil
+}
+
+// ListTrash lists the backups stashr moved to the Google Drive trash
+func (g *GoogleDrive) ListTrash() ([]TrashedFile, error) {
+	if err := g.initService(); err != nil {
+		return nil, err
+	}
+
+	parent := g.parentID()
+	if parent == "" {
+		parent = "root"
+	}
+	return g.listTrashFolder(parent, "")
+}
+
+// listTrashFolder lists the trashed backups in a folder and its subfolders
+func (g *GoogleDrive) listTrashFolder(folderID, dir string) ([]TrashedFile, error) {
+	query := fmt.Sprintf("'%s' in parents", folderID)
+	listed, err := g.listAllFiles(query, "id, name, size, mimeType, trashed, appProperties")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	var files []TrashedFile
+	for _, file := range listed {
+		name := path.Join(dir, file.Name)
+		if file.MimeType == googleDriveFolderMimeType {
+			if file.Trashed || shouldIgnoreFile(file.Name) {
+				continue
+			}
+			children, err := g.listTrashFolder(file.Id, name)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, children...)
+			continue
+		}
+
+		trashedAt, ok := file.AppProperties[googleDriveTrashedProperty]
+		if !file.Trashed || !ok {
+			continue
+		}
+		trashedTime, _ := time.Parse(time.RFC3339, trashedAt)
+		files = append(files, TrashedFile{
+			Name:        name,
+			Size:        file.Size,
+			TrashedTime: trashedTime,
+		})
+	}
+	return files, nil
+}
+
+// DeleteTrashed permanently deletes a backup stashr moved to the Google Drive trash
+func (g *GoogleDrive) DeleteTrashed(filename string) error {
+	if err := g.initService(); err != nil {
+		return err
+	}
+
+	dir, name := path.Split(filename)
+	parent, err := g.subfolderID(strings.TrimSuffix(dir, "/"), false)
+	if err != nil {
+		return err
+	}
+	if parent == "" {
+		parent = "root"
+	}
+
+	query := fmt.Sprintf("name='%s' and trashed=true and '%s' in parents", name, parent)
+	files, err := g.listAllFiles(query, "id, appProperties")
+	if err != nil {
+		return fmt.Errorf("failed to list trash: %w", err)
+	}
+	deleted := 0
+	for _, file := range files {
+		if _, ok := file.AppProperties[googleDriveTrashedProperty]; !ok {
+			continue
+		}
+		if err := g.service.Files.Delete(file.Id).SupportsAllDrives(true).Do(); err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+		deleted++
+	}
+	if deleted == 0 {
+		return fmt.Errorf("file not found")
+	}
+	return nil
+}
+
+// StoredChecksum returns the MD5 checksum Google Drive computed for a file
+func (g *GoogleDrive) StoredChecksum(filename string) (string, string, error) {
+	if err := g.initService(); err != nil {
+		return "", "", err
+	}
+
+	fileID, err := g.findFileID(filename)
+	if err != nil {
+		return "", "", err
+	}
+	if fileID == "" {
+		return "", "", fmt.Errorf("file not found")
+	}
+
+	file, err := g.service.Files.Get(fileID).Fields("md5Checksum").SupportsAllDrives(true).Do()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if file.Md5Checksum == "" {
+		return "", "", ErrChecksumUnavailable
+	}
+	return "md5", file.Md5Checksum, nil
+}
+
+// GetFreeSpace returns the storage quota left on the account. Shared Drives,
+// service accounts (whose uploads count against the folder owner) and
+// accounts without a limit report ErrFreeSpaceUnknown.
+func (g *GoogleDrive) GetFreeSpace() (int64, error) {
+	if g.DriveID != "" || g.IsServiceAccount() {
+		return 0, ErrFreeSpaceUnknown
+	}
+	if err := g.initService(); err != nil {
+		return 0, err
+	}
+
+	about, err := g.service.About.Get().Fields("storageQuota").Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read storage quota: %w", err)
+	}
+	if about.StorageQuota == nil || about.StorageQuota.Limit == 0 {
+		return 0, ErrFreeSpaceUnknown
+	}
+	return max(about.StorageQuota.Limit-about.StorageQuota.Usage, 0), nil
+}
+
 // CreateBackupFolder creates a dedicated backup folder in Google Drive
 func (g *GoogleDrive) CreateBackupFolder(folderName string) (string, error) {
 	if err := g.initService(); err != nil {
@@ -342,11 +927,14 @@ func (g *GoogleDrive) CreateBackupFolder(folderName string) (string, error) {
 	// Create folder metadata
 	folder := &drive.File{
 		Name:     folderName,
-		MimeType: "application/vnd.google-apps.folder",
+		MimeType: googleDriveFolderMimeType,
+	}
+	if g.DriveID != "" {
+		folder.Parents = []string{g.DriveID}
 	}
 
 	// Create folder
-	createdFolder, err := g.service.Files.Create(folder).Fields("id").Do()
+	createdFolder, err := g.service.Files.Create(folder).SupportsAllDrives(true).Fields("id").Do()
 	if err != nil {
 		return "", fmt.Errorf("failed to create folder: %w", err)
 	}
@@ -364,7 +952,7 @@ func (g *GoogleDrive) GetFolderInfo() (*drive.File, error) {
 		return nil, fmt.Errorf("folder ID not set")
 	}
 
-	folder, err := g.service.Files.Get(g.FolderID).Fields("id, name, createdTime").Do()
+	folder, err := g.service.Files.Get(g.FolderID).SupportsAllDrives(true).Fields("id, name, createdTime").Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get folder info: %w", err)
 	}
@@ -379,7 +967,8 @@ func (g *GoogleDrive) CleanOldBackups(keepLast int) error {
 		return err
 	}
 
-	return ApplyRetentionPolicy(backups, keepLast, g.Delete)
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, g.Delete)
+	return err
 }
 
 // TestConnection tests the connection to Google Drive
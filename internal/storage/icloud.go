@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// ICloudDriveDir is the iCloud Drive folder of macOS, relative to the home directory
+const ICloudDriveDir = "Library/Mobile Documents/com~apple~CloudDocs"
+
+// SyncState is how far a backup stored in a synced folder has got to the cloud
+type SyncState string
+
+const (
+	// SyncUploaded means the cloud has the backup
+	SyncUploaded SyncState = "uploaded"
+	// SyncUploading means the sync client is uploading the backup
+	SyncUploading SyncState = "uploading"
+	// SyncPending means the sync client hasn't started uploading the backup
+	SyncPending SyncState = "pending"
+)
+
+// ErrSyncStatusUnsupported is returned when a destination isn't a synced folder
+var ErrSyncStatusUnsupported = errors.New("destination does not report sync status")
+
+// SyncReporter is implemented by backends that store backups in a folder a sync
+// client uploads in the background, where a successful write doesn't mean the
+// backup has left the machine
+type SyncReporter interface {
+	// WaitForSync waits until the cloud has a backup, or the backend's sync
+	// timeout passes, and returns the last state seen
+	WaitForSync(filename string) (SyncState, error)
+}
+
+// SupportsSyncStatus reports whether a backend stores backups in a synced folder
+func SupportsSyncStatus(backend Storage) bool {
+	if w, ok := backend.(wrapper); ok {
+		return SupportsSyncStatus(w.unwrap())
+	}
+	_, ok := backend.(SyncReporter)
+	return ok
+}
+
+// WaitForSync waits until the cloud has a backup, or returns ErrSyncStatusUnsupported
+func WaitForSync(backend Storage, filename string) (SyncState, error) {
+	if reporter, ok := backend.(SyncReporter); ok {
+		return reporter.WaitForSync(filename)
+	}
+	return "", ErrSyncStatusUnsupported
+}
+
+// icloudSyncPollInterval is how often WaitForSync checks an upload's state
+const icloudSyncPollInterval = 2 * time.Second
+
+// icloudSyncScript prints the upload state of an iCloud Drive file, read from
+// its ubiquitous item resource values
+const icloudSyncScript = `ObjC.import('Foundation');
+function run(argv) {
+  var url = $.NSURL.fileURLWithPath(argv[0]);
+  function value(key) {
+    var result = Ref();
+    url.getResourceValueForKeyError(result, key, null);
+    return result[0];
+  }
+  var error = value($.NSURLUbiquitousItemUploadingErrorKey);
+  if (error && !error.isNil()) {
+    return 'error ' + ObjC.unwrap(error.localizedDescription);
+  }
+  if (ObjC.unwrap(value($.NSURLUbiquitousItemIsUploadedKey))) {
+    return 'uploaded';
+  }
+  if (ObjC.unwrap(value($.NSURLUbiquitousItemIsUploadingKey))) {
+    return 'uploading';
+  }
+  return 'pending';
+}`
+
+// ICloudDrive stores backups in the iCloud Drive folder of macOS, which the
+// system uploads to iCloud in the background
+type ICloudDrive struct {
+	// DrivePath is the iCloud Drive folder
+	DrivePath string
+	BackupDir string
+	// SyncTimeout is how long WaitForSync waits for iCloud to take an upload;
+	// 0 only checks once
+	SyncTimeout time.Duration
+}
+
+// NewICloudDrive creates a new iCloud Drive storage backend. An empty drivePath
+// uses the iCloud Drive folder of the current user.
+func NewICloudDrive(drivePath, backupDir string, syncTimeout time.Duration) *ICloudDrive {
+	if drivePath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			drivePath = filepath.Join(home, filepath.FromSlash(ICloudDriveDir))
+		}
+	}
+	return &ICloudDrive{
+		DrivePath:   drivePath,
+		BackupDir:   backupDir,
+		SyncTimeout: syncTimeout,
+	}
+}
+
+// Name returns the name of the storage backend
+func (c *ICloudDrive) Name() string {
+	return "iCloud Drive"
+}
+
+// backupPath returns the folder backups are stored in
+func (c *ICloudDrive) backupPath() string {
+	return filepath.Join(c.DrivePath, c.BackupDir)
+}
+
+// IsAvailable checks that this is a Mac signed in to iCloud Drive
+func (c *ICloudDrive) IsAvailable() (bool, error) {
+	if runtime.GOOS != "darwin" {
+		return false, &StorageUnavailableError{
+			Storage: c.Name(),
+			Reason:  "iCloud Drive is only available on macOS",
+		}
+	}
+	if !utils.DirExists(c.DrivePath) {
+		return false, &StorageUnavailableError{
+			Storage: c.Name(),
+			Reason:  fmt.Sprintf("%s does not exist (turn on iCloud Drive in System Settings)", c.DrivePath),
+		}
+	}
+	return true, nil
+}
+
+// Upload writes a file to the iCloud Drive folder
+func (c *ICloudDrive) Upload(filename string, data []byte) error {
+	if available, err := c.IsAvailable(); !available {
+		return err
+	}
+
+	filePath := filepath.Join(c.backupPath(), filepath.FromSlash(filename))
+	if err := utils.CreateDirIfNotExists(filepath.Dir(filePath), 0700); err != nil {
+		return &UploadError{
+			Storage: c.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to create backup directory: %w", err),
+		}
+	}
+
+	// Write file, never leaving a partial file for iCloud to upload
+	if err := writeFileAtomic(filePath, data, 0600); err != nil {
+		return &UploadError{
+			Storage: c.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to write file: %w", err),
+		}
+	}
+
+	return nil
+}
+
+// Download reads a file from the iCloud Drive folder. macOS downloads a backup
+// that was evicted to save space when it is read.
+func (c *ICloudDrive) Download(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(c.backupPath(), filepath.FromSlash(filename)))
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: c.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// List lists all backup files in the iCloud Drive folder, including those in subfolders
+func (c *ICloudDrive) List() ([]BackupFile, error) {
+	if !utils.DirExists(c.backupPath()) {
+		return []BackupFile{}, nil
+	}
+	return listBackupTree(c.backupPath(), c.Name())
+}
+
+// Delete deletes a file from the iCloud Drive folder, and so from iCloud
+func (c *ICloudDrive) Delete(filename string) error {
+	if err := os.Remove(filepath.Join(c.backupPath(), filepath.FromSlash(filename))); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// Trash moves a backup to the .trash folder of the backup directory
+func (c *ICloudDrive) Trash(filename string) error {
+	return trashFile(c.backupPath(), filename)
+}
+
+// ListTrash lists the backups in the .trash folder
+func (c *ICloudDrive) ListTrash() ([]TrashedFile, error) {
+	return listTrashTree(c.backupPath())
+}
+
+// DeleteTrashed permanently deletes a backup from the .trash folder
+func (c *ICloudDrive) DeleteTrashed(filename string) error {
+	return deleteTrashedFile(c.backupPath(), filename)
+}
+
+// GetFreeSpace returns the free space of the disk holding the iCloud Drive
+// folder, which keeps a copy of every backup until iCloud has uploaded it
+func (c *ICloudDrive) GetFreeSpace() (int64, error) {
+	return PathFreeSpace(c.DrivePath)
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (c *ICloudDrive) CleanOldBackups(keepLast int) error {
+	backups, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, c.Delete)
+	return err
+}
+
+// SyncStatus returns how far iCloud has got with uploading a backup
+func (c *ICloudDrive) SyncStatus(filename string) (SyncState, error) {
+	filePath := filepath.Join(c.backupPath(), filepath.FromSlash(filename))
+	output, err := exec.Command("osascript", "-l", "JavaScript", "-e", icloudSyncScript, filePath).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read iCloud status: %w", err)
+	}
+
+	status := strings.TrimSpace(string(output))
+	if reason, ok := strings.CutPrefix(status, "error "); ok {
+		return "", fmt.Errorf("iCloud upload failed: %s", reason)
+	}
+	switch state := SyncState(status); state {
+	case SyncUploaded, SyncUploading, SyncPending:
+		return state, nil
+	}
+	return "", fmt.Errorf("unexpected iCloud status: %q", status)
+}
+
+// WaitForSync polls a backup's upload state until iCloud has it or SyncTimeout passes
+func (c *ICloudDrive) WaitForSync(filename string) (SyncState, error) {
+	deadline := time.Now().Add(c.SyncTimeout)
+	for {
+		state, err := c.SyncStatus(filename)
+		if err != nil || state == SyncUploaded || !time.Now().Before(deadline) {
+			return state, err
+		}
+		time.Sleep(icloudSyncPollInterval)
+	}
+}
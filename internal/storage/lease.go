@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LeaseFile is the lock object hosts write to a destination before applying
+// retention. Its leading dot keeps it out of backup listings.
+const LeaseFile = ".stashr-lock.json"
+
+// DefaultLeaseTTL is how long a lease is honored if its holder never releases it
+const DefaultLeaseTTL = 10 * time.Minute
+
+// Lease is a time-limited claim on a destination, held by one host at a time.
+// Storage backends have no compare-and-swap, so a lease is written and then
+// read back; the host whose token survives holds it. It is a best-effort guard
+// against two hosts applying retention at once, not a strict mutex.
+type Lease struct {
+	Holder     string    `json:"holder"`
+	Token      string    `json:"token"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	backend Storage
+}
+
+// LeaseHeldError is returned when another host holds a destination's lease
+type LeaseHeldError struct {
+	Storage   string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+func (e *LeaseHeldError) Error() string {
+	return fmt.Sprintf("%s is locked by %s until %s", e.Storage, e.Holder, e.ExpiresAt.Local().Format("15:04:05"))
+}
+
+// AcquireLease claims a destination's lease for holder. An expired lease, or
+// one left behind by the same holder, is taken over.
+func AcquireLease(backend Storage, holder string, ttl time.Duration) (*Lease, error) {
+	if data, err := backend.Download(LeaseFile); err == nil {
+		// An unreadable lease is treated as expired
+		var current Lease
+		if json.Unmarshal(data, &current) == nil && current.Holder != holder && time.Now().Before(current.ExpiresAt) {
+			return nil, &LeaseHeldError{Storage: backend.Name(), Holder: current.Holder, ExpiresAt: current.ExpiresAt}
+		}
+		// Some backends keep duplicate names, so remove the old lease before writing
+		if err := backend.Delete(LeaseFile); err != nil {
+			return nil, fmt.Errorf("failed to remove expired lease: %w", err)
+		}
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate lease token: %w", err)
+	}
+	now := time.Now()
+	lease := &Lease{
+		Holder:     holder,
+		Token:      hex.EncodeToString(tokenBytes),
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+		backend:    backend,
+	}
+
+	data, err := json.MarshalIndent(lease, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Upload(LeaseFile, data); err != nil {
+		return nil, fmt.Errorf("failed to write lease: %w", err)
+	}
+
+	// Another host may have written its lease at the same time; the one read back wins
+	written, err := readLease(backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read back lease: %w", err)
+	}
+	if written.Token != lease.Token {
+		return nil, &LeaseHeldError{Storage: backend.Name(), Holder: written.Holder, ExpiresAt: written.ExpiresAt}
+	}
+
+	return lease, nil
+}
+
+// Release deletes the lease if it is still held by this host
+func (l *Lease) Release() error {
+	current, err := readLease(l.backend)
+	if err != nil || current.Token != l.Token {
+		// Expired and taken over by another host; theirs must be left alone
+		return nil
+	}
+	return l.backend.Delete(LeaseFile)
+}
+
+// readLease returns the lease currently stored at a destination
+func readLease(backend Storage) (*Lease, error) {
+	data, err := backend.Download(LeaseFile)
+	if err != nil {
+		return nil, err
+	}
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("invalid lease file: %w", err)
+	}
+	return &lease, nil
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/harshalranjhani/stashr/internal/backupname"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
@@ -34,8 +35,9 @@ func (l *Local) IsAvailable() (bool, error) {
 
 // Upload uploads a file to local storage
 func (l *Local) Upload(filename string, data []byte) error {
-	// Create backup directory if it doesn't exist
-	if err := utils.CreateDirIfNotExists(l.BackupPath, 0700); err != nil {
+	// Create backup directory (and any subfolder of the backup) if it doesn't exist
+	filePath := filepath.Join(l.BackupPath, filepath.FromSlash(filename))
+	if err := utils.CreateDirIfNotExists(filepath.Dir(filePath), 0700); err != nil {
 		return &UploadError{
 			Storage: l.Name(),
 			File:    filename,
@@ -43,9 +45,8 @@ func (l *Local) Upload(filename string, data []byte) error {
 		}
 	}
 
-	// Write file
-	filePath := filepath.Join(l.BackupPath, filename)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	// Write file, never leaving a partial file under the backup's name
+	if err := writeFileAtomic(filePath, data, 0600); err != nil {
 		return &UploadError{
 			Storage: l.Name(),
 			File:    filename,
@@ -67,7 +68,7 @@ func (l *Local) Upload(filename string, data []byte) error {
 
 // Download downloads a file from local storage
 func (l *Local) Download(filename string) ([]byte, error) {
-	filePath := filepath.Join(l.BackupPath, filename)
+	filePath := filepath.Join(l.BackupPath, filepath.FromSlash(filename))
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, &DownloadError{
@@ -80,50 +81,14 @@ func (l *Local) Download(filename string) ([]byte, error) {
 	return data, nil
 }
 
-// List lists all backup files in local storage
+// List lists all backup files in local storage, including those in subfolders
 func (l *Local) List() ([]BackupFile, error) {
-	// Check if backup directory exists
-	if !utils.DirExists(l.BackupPath) {
-		return []BackupFile{}, nil // No backups yet
-	}
-
-	// Read directory
-	entries, err := os.ReadDir(l.BackupPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read backup directory: %w", err)
-	}
-
-	var backups []BackupFile
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		// Skip hidden/system files (e.g., ._ files, .DS_Store)
-		if shouldIgnoreFile(entry.Name()) {
-			continue
-		}
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		backups = append(backups, BackupFile{
-			Name:         entry.Name(),
-			Size:         info.Size(),
-			ModifiedTime: info.ModTime(),
-			Location:     filepath.Join(l.BackupPath, entry.Name()),
-			StorageType:  l.Name(),
-		})
-	}
-
-	return backups, nil
+	return listBackupTree(l.BackupPath, l.Name())
 }
 
 // Delete deletes a file from local storage
 func (l *Local) Delete(filename string) error {
-	filePath := filepath.Join(l.BackupPath, filename)
+	filePath := filepath.Join(l.BackupPath, filepath.FromSlash(filename))
 	if err := os.Remove(filePath); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -131,6 +96,21 @@ func (l *Local) Delete(filename string) error {
 	return nil
 }
 
+// Trash moves a backup to the .trash folder of the backup directory
+func (l *Local) Trash(filename string) error {
+	return trashFile(l.BackupPath, filename)
+}
+
+// ListTrash lists the backups in the .trash folder
+func (l *Local) ListTrash() ([]TrashedFile, error) {
+	return listTrashTree(l.BackupPath)
+}
+
+// DeleteTrashed permanently deletes a backup from the .trash folder
+func (l *Local) DeleteTrashed(filename string) error {
+	return deleteTrashedFile(l.BackupPath, filename)
+}
+
 // GetBackupLocation returns the location where backups are stored
 func (l *Local) GetBackupLocation() string {
 	return l.BackupPath
@@ -138,9 +118,7 @@ func (l *Local) GetBackupLocation() string {
 
 // GetFreeSpace returns the free space in bytes
 func (l *Local) GetFreeSpace() (int64, error) {
-	// This is platform-specific and would require syscalls
-	// For simplicity, we'll return 0 for now
-	return 0, fmt.Errorf("not implemented")
+	return PathFreeSpace(l.BackupPath)
 }
 
 // CleanOldBackups applies retention policy and deletes old backups
@@ -150,12 +128,13 @@ func (l *Local) CleanOldBackups(keepLast int) error {
 		return err
 	}
 
-	return ApplyRetentionPolicy(backups, keepLast, l.Delete)
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, l.Delete)
+	return err
 }
 
 // VerifyBackup verifies that a backup file exists and is readable
 func (l *Local) VerifyBackup(filename string) error {
-	filePath := filepath.Join(l.BackupPath, filename)
+	filePath := filepath.Join(l.BackupPath, filepath.FromSlash(filename))
 
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -184,7 +163,7 @@ func (l *Local) VerifyBackup(filename string) error {
 
 // GetBackupAge returns the age of a backup file
 func (l *Local) GetBackupAge(filename string) (time.Duration, error) {
-	filePath := filepath.Join(l.BackupPath, filename)
+	filePath := filepath.Join(l.BackupPath, filepath.FromSlash(filename))
 
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -203,8 +182,8 @@ func (l *Local) GetBackupsByManager(manager string) ([]BackupFile, error) {
 
 	var filtered []BackupFile
 	for _, backup := range backups {
-		// Check if filename contains the manager name
-		if len(backup.Name) > len(manager) && backup.Name[:len(manager)] == "backup_"+manager {
+		// Check if filename starts with the manager name, ignoring any subfolder
+		if backupname.Parse(backup.Name).Manager == manager {
 			filtered = append(filtered, backup)
 		}
 	}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLockUnsupported is returned when a destination can't lock backups
+var ErrLockUnsupported = errors.New("destination does not support object lock")
+
+// ObjectLocker is implemented by object storage backends that can store backups
+// write-once: until its lock expires, a backup can't be deleted or overwritten,
+// not even with the destination's credentials, so ransomware or a compromised
+// machine can't destroy it
+type ObjectLocker interface {
+	// LockedUntil returns when a backup's lock expires, the zero time if it has none
+	LockedUntil(filename string) (time.Time, error)
+}
+
+// LockedUntil returns when a backup's lock expires, or ErrLockUnsupported
+func LockedUntil(backend Storage, filename string) (time.Time, error) {
+	if locker, ok := backend.(ObjectLocker); ok {
+		return locker.LockedUntil(filename)
+	}
+	return time.Time{}, ErrLockUnsupported
+}
+
+// ObjectLockedError is returned when a backup can't be deleted before its lock expires
+type ObjectLockedError struct {
+	File  string
+	Until time.Time
+}
+
+func (e *ObjectLockedError) Error() string {
+	return fmt.Sprintf("%s is locked until %s", e.File, e.Until.Local().Format("2006-01-02 15:04"))
+}
+
+// checkUnlocked returns an ObjectLockedError if a backup's lock hasn't expired
+func checkUnlocked(locker ObjectLocker, filename string) error {
+	until, err := locker.LockedUntil(filename)
+	if err != nil {
+		return err
+	}
+	if until.After(time.Now()) {
+		return &ObjectLockedError{File: filename, Until: until}
+	}
+	return nil
+}
+
+// lockUntil returns when a backup uploaded now stays locked for days
+func lockUntil(days int) time.Time {
+	return time.Now().Add(time.Duration(days) * 24 * time.Hour).UTC()
+}
@@ -0,0 +1,72 @@
+package storage
+
+import "time"
+
+// NamedStorage gives a backend the name of the destination profile it was
+// configured as, so several destinations of the same type can be told apart
+// in output, the metadata database and the download cache
+type NamedStorage struct {
+	Storage
+	name string
+}
+
+// NewNamedStorage wraps a storage backend under a new name
+func NewNamedStorage(backend Storage, name string) *NamedStorage {
+	return &NamedStorage{
+		Storage: backend,
+		name:    name,
+	}
+}
+
+// Name returns the destination profile's name
+func (s *NamedStorage) Name() string {
+	return s.name
+}
+
+// UploadWithProgress uploads a file through the wrapped backend, reporting its progress
+func (s *NamedStorage) UploadWithProgress(filename string, data []byte, progress ProgressFunc) error {
+	return UploadWithProgress(s.Storage, filename, data, progress)
+}
+
+// StoredChecksum forwards to the wrapped backend, if its provider records checksums
+func (s *NamedStorage) StoredChecksum(filename string) (string, string, error) {
+	if provider, ok := s.Storage.(ChecksumProvider); ok {
+		return provider.StoredChecksum(filename)
+	}
+	return "", "", ErrChecksumUnavailable
+}
+
+// GetFreeSpace forwards to the wrapped backend, if it can report its free space
+func (s *NamedStorage) GetFreeSpace() (int64, error) {
+	return FreeSpace(s.Storage)
+}
+
+// LockedUntil forwards to the wrapped backend, if it can lock backups
+func (s *NamedStorage) LockedUntil(filename string) (time.Time, error) {
+	return LockedUntil(s.Storage, filename)
+}
+
+// WaitForSync forwards to the wrapped backend, if it is a synced folder
+func (s *NamedStorage) WaitForSync(filename string) (SyncState, error) {
+	return WaitForSync(s.Storage, filename)
+}
+
+// Trash forwards to the wrapped backend, if it has a trash
+func (s *NamedStorage) Trash(filename string) error {
+	return Trash(s.Storage, filename)
+}
+
+// ListTrash forwards to the wrapped backend, if it has a trash
+func (s *NamedStorage) ListTrash() ([]TrashedFile, error) {
+	return ListTrash(s.Storage)
+}
+
+// DeleteTrashed forwards to the wrapped backend, if it has a trash
+func (s *NamedStorage) DeleteTrashed(filename string) error {
+	return DeleteTrashed(s.Storage, filename)
+}
+
+// unwrap returns the wrapped backend
+func (s *NamedStorage) unwrap() Storage {
+	return s.Storage
+}
@@ -0,0 +1,510 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+const (
+	// oneDriveGraphURL is the Microsoft Graph endpoint for the signed-in user's drive
+	oneDriveGraphURL = "https://graph.microsoft.com/v1.0/me/drive"
+
+	// oneDriveSimpleUploadLimit is the largest file Graph accepts in a single PUT
+	oneDriveSimpleUploadLimit = 4 * 1024 * 1024
+
+	// oneDriveChunkSize is the upload session chunk size; Graph requires a multiple of 320 KiB
+	oneDriveChunkSize = 10 * 320 * 1024
+)
+
+// oneDriveScopes are the delegated permissions requested during sign-in
+var oneDriveScopes = []string{"Files.ReadWrite", "offline_access"}
+
+// OneDrive represents a OneDrive storage backend using the Microsoft Graph API
+type OneDrive struct {
+	ClientID  string
+	Tenant    string
+	Folder    string
+	TokenPath string
+	client    *http.Client
+}
+
+// NewOneDrive creates a new OneDrive storage backend
+func NewOneDrive(clientID, tenant, folder, tokenPath string) *OneDrive {
+	return &OneDrive{
+		ClientID:  clientID,
+		Tenant:    tenant,
+		Folder:    strings.Trim(folder, "/"),
+		TokenPath: tokenPath,
+	}
+}
+
+// Name returns the name of the storage backend
+func (o *OneDrive) Name() string {
+	return "OneDrive"
+}
+
+// IsAvailable checks if OneDrive is available (signed in and the drive is reachable)
+func (o *OneDrive) IsAvailable() (bool, error) {
+	if o.ClientID == "" {
+		return false, &StorageUnavailableError{
+			Storage: o.Name(),
+			Reason:  "client ID not configured",
+		}
+	}
+
+	if err := o.initClient(); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: o.Name(),
+			Reason:  fmt.Sprintf("failed to initialize client: %v", err),
+		}
+	}
+
+	resp, err := o.client.Get(oneDriveGraphURL + "?$select=id")
+	if err != nil {
+		return false, &StorageUnavailableError{
+			Storage: o.Name(),
+			Reason:  fmt.Sprintf("failed to reach Microsoft Graph: %v", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if err := graphError(resp); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: o.Name(),
+			Reason:  err.Error(),
+		}
+	}
+
+	return true, nil
+}
+
+// oauthConfig returns the OAuth2 configuration for the configured tenant
+func (o *OneDrive) oauthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: o.ClientID,
+		Endpoint: microsoft.AzureADEndpoint(o.Tenant),
+		Scopes:   oneDriveScopes,
+	}
+}
+
+// initClient initializes the authenticated HTTP client, signing in with the
+// device code flow when no token has been saved yet
+func (o *OneDrive) initClient() error {
+	if o.client != nil {
+		return nil // Already initialized
+	}
+
+	ctx := oauthContext(context.Background())
+	config := o.oauthConfig()
+
+	token, err := o.loadToken()
+	if err != nil {
+		// If token doesn't exist or is invalid, sign in again
+		token, err = o.getTokenFromDevice(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to get token: %w", err)
+		}
+		if err := o.saveToken(token); err != nil {
+			return fmt.Errorf("failed to save token: %w", err)
+		}
+	}
+
+	// Microsoft rotates refresh tokens, so persist every refreshed token
+	source := &savingTokenSource{
+		source: config.TokenSource(ctx, token),
+		last:   token,
+		save:   o.saveToken,
+	}
+	o.client = oauth2.NewClient(ctx, source)
+	return nil
+}
+
+// getTokenFromDevice signs in with the OAuth2 device code flow, which works on
+// machines without a browser
+func (o *OneDrive) getTokenFromDevice(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	auth, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device sign-in: %w", err)
+	}
+
+	fmt.Printf("To sign in to OneDrive, open %s and enter the code: %s\n\n", auth.VerificationURI, auth.UserCode)
+	fmt.Println("Waiting for sign-in to complete...")
+
+	token, err := config.DeviceAccessToken(ctx, auth)
+	if err != nil {
+		return nil, fmt.Errorf("device sign-in failed: %w", err)
+	}
+
+	return token, nil
+}
+
+// loadToken loads the saved token
+func (o *OneDrive) loadToken() (*oauth2.Token, error) {
+	file, err := os.Open(o.TokenPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	token := &oauth2.Token{}
+	if err := json.NewDecoder(file).Decode(token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// saveToken saves a token with owner-only permissions
+func (o *OneDrive) saveToken(token *oauth2.Token) error {
+	file, err := os.OpenFile(o.TokenPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(token)
+}
+
+// savingTokenSource saves tokens whenever the underlying source refreshes them
+type savingTokenSource struct {
+	source oauth2.TokenSource
+	save   func(*oauth2.Token) error
+
+	mu   sync.Mutex
+	last *oauth2.Token
+}
+
+// Token returns a valid token, saving it if it was refreshed
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil || token.AccessToken != s.last.AccessToken {
+		// A failed save only costs a sign-in next time, so don't fail the request
+		_ = s.save(token)
+		s.last = token
+	}
+
+	return token, nil
+}
+
+// itemURL returns the Graph URL of a file in the backup folder, with an optional
+// action such as "content"
+func (o *OneDrive) itemURL(filename, action string) string {
+	itemURL := oneDriveGraphURL + "/root:/" + escapePath(path.Join(o.Folder, filename))
+	if action == "" {
+		return itemURL
+	}
+	return itemURL + ":/" + action
+}
+
+// folderURL returns the Graph URL of the children of a subfolder of the backup
+// folder, or of the backup folder itself when dir is empty
+func (o *OneDrive) folderURL(dir string) string {
+	folder := path.Join(o.Folder, dir)
+	if folder == "" {
+		return oneDriveGraphURL + "/root/children"
+	}
+	return oneDriveGraphURL + "/root:/" + escapePath(folder) + ":/children"
+}
+
+// graphError returns an error describing an unsuccessful Graph response
+func graphError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error.Message != "" {
+		return fmt.Errorf("%s: %s (%s)", resp.Status, body.Error.Message, body.Error.Code)
+	}
+	return fmt.Errorf("unexpected response: %s", resp.Status)
+}
+
+// Upload uploads a file to OneDrive, using an upload session for large files
+func (o *OneDrive) Upload(filename string, data []byte) error {
+	if err := o.initClient(); err != nil {
+		return &UploadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	var err error
+	if len(data) <= oneDriveSimpleUploadLimit {
+		err = o.simpleUpload(filename, data)
+	} else {
+		err = o.sessionUpload(filename, data)
+	}
+	if err != nil {
+		return &UploadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
+	}
+
+	return nil
+}
+
+// simpleUpload uploads a small file in a single request, creating the folder if needed
+func (o *OneDrive) simpleUpload(filename string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, o.itemURL(filename, "content"), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return graphError(resp)
+}
+
+// sessionUpload uploads a large file in chunks through an upload session
+func (o *OneDrive) sessionUpload(filename string, data []byte) error {
+	body := strings.NewReader(`{"item":{"@microsoft.graph.conflictBehavior":"replace"}}`)
+	resp, err := o.client.Post(o.itemURL(filename, "createUploadSession"), "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := graphError(resp); err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	var session struct {
+		UploadURL string `json:"uploadUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return fmt.Errorf("failed to parse upload session: %w", err)
+	}
+
+	// The upload URL is pre-authenticated and must not receive the bearer token
+	uploader := newHTTPClient(5 * time.Minute)
+	total := len(data)
+	for start := 0; start < total; start += oneDriveChunkSize {
+		end := min(start+oneDriveChunkSize, total)
+
+		req, err := http.NewRequest(http.MethodPut, session.UploadURL, bytes.NewReader(data[start:end]))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, total))
+
+		chunkResp, err := uploader.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to upload bytes %d-%d: %w", start, end-1, err)
+		}
+		err = graphError(chunkResp)
+		chunkResp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to upload bytes %d-%d: %w", start, end-1, err)
+		}
+	}
+
+	return nil
+}
+
+// Download downloads a file from OneDrive
+func (o *OneDrive) Download(filename string) ([]byte, error) {
+	if err := o.initClient(); err != nil {
+		return nil, &DownloadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	resp, err := o.client.Get(o.itemURL(filename, "content"))
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &DownloadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("file not found"),
+		}
+	}
+	if err := graphError(resp); err != nil {
+		return nil, &DownloadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: o.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file content: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// List lists all backup files in the OneDrive backup folder, including those in subfolders
+func (o *OneDrive) List() ([]BackupFile, error) {
+	if err := o.initClient(); err != nil {
+		return nil, err
+	}
+
+	return o.listFolder("")
+}
+
+// listFolder lists the backups in a subfolder of the backup folder and its subfolders
+func (o *OneDrive) listFolder(dir string) ([]BackupFile, error) {
+	var backups []BackupFile
+	next := o.folderURL(dir) + "?$select=id,name,size,lastModifiedDateTime,file,folder&$top=200"
+	for next != "" {
+		page, err := o.listPage(next)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			// The folder is created by the first upload
+			return nil, nil
+		}
+
+		for _, item := range page.Value {
+			// Skip hidden/system files and folders (e.g., ._ files, .DS_Store)
+			if shouldIgnoreFile(item.Name) {
+				continue
+			}
+			name := path.Join(dir, item.Name)
+
+			if item.Folder != nil {
+				children, err := o.listFolder(name)
+				if err != nil {
+					return nil, err
+				}
+				backups = append(backups, children...)
+				continue
+			}
+			if item.File == nil {
+				continue
+			}
+
+			backups = append(backups, BackupFile{
+				Name:         name,
+				Size:         item.Size,
+				ModifiedTime: item.LastModifiedDateTime,
+				Location:     item.ID,
+				StorageType:  o.Name(),
+			})
+		}
+		next = page.NextLink
+	}
+
+	return backups, nil
+}
+
+// oneDrivePage is one page of a Graph children listing
+type oneDrivePage struct {
+	Value []struct {
+		ID                   string    `json:"id"`
+		Name                 string    `json:"name"`
+		Size                 int64     `json:"size"`
+		LastModifiedDateTime time.Time `json:"lastModifiedDateTime"`
+		File                 *struct{} `json:"file"`
+		Folder               *struct{} `json:"folder"`
+	} `json:"value"`
+	NextLink string `json:"@odata.nextLink"`
+}
+
+// listPage fetches one page of folder children, returning nil if the folder doesn't exist
+func (o *OneDrive) listPage(pageURL string) (*oneDrivePage, error) {
+	resp, err := o.client.Get(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := graphError(resp); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	page := &oneDrivePage{}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	}
+
+	return page, nil
+}
+
+// Delete deletes a file from OneDrive
+func (o *OneDrive) Delete(filename string) error {
+	if err := o.initClient(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, o.itemURL(filename, ""), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("file not found")
+	}
+	if err := graphError(resp); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (o *OneDrive) CleanOldBackups(keepLast int) error {
+	backups, err := o.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, o.Delete)
+	return err
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// PluginExecutablePrefix prefixes the executable name of every storage plugin,
+// e.g. stashr-storage-glacier for a plugin named glacier
+const PluginExecutablePrefix = "stashr-storage-"
+
+// PluginProtocolVersion is the version of the request format sent to plugins
+const PluginProtocolVersion = 1
+
+// Plugin commands. Each runs the executable once, e.g. "stashr-storage-glacier upload".
+const (
+	PluginCommandAvailable = "available"
+	PluginCommandUpload    = "upload"
+	PluginCommandDownload  = "download"
+	PluginCommandList      = "list"
+	PluginCommandDelete    = "delete"
+)
+
+// PluginRequest is the JSON object a plugin reads from stdin
+type PluginRequest struct {
+	Version int    `json:"version"`
+	Command string `json:"command"`
+	// Filename is the backup to upload, download or delete; it may contain
+	// slash-separated subfolders
+	Filename string `json:"filename,omitempty"`
+	// Data is the file content for upload (base64 in JSON)
+	Data []byte `json:"data,omitempty"`
+	// ContentType is the MIME type to store an upload with, if the
+	// destination is configured with one
+	ContentType string `json:"content_type,omitempty"`
+	// Options are the plugin's settings from the config file
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// PluginResponse is the JSON object a plugin writes to stdout
+type PluginResponse struct {
+	// OK is false when the command failed; Error says why
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// NotFound reports that the requested file doesn't exist
+	NotFound bool `json:"not_found,omitempty"`
+	// Available answers the available command; Reason explains a false answer
+	Available bool   `json:"available,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Data answers the download command (base64 in JSON)
+	Data []byte `json:"data,omitempty"`
+	// Files answers the list command
+	Files []PluginFile `json:"files,omitempty"`
+}
+
+// PluginFile is one stored backup in a list response
+type PluginFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Plugin is a storage backend implemented by an external executable, so
+// providers stashr doesn't support can be added without changing stashr.
+// Each operation runs the executable with the command as its only argument,
+// writes a PluginRequest to its stdin and reads a PluginResponse from stdout.
+type Plugin struct {
+	// PluginName identifies the plugin in the config file and --destination
+	PluginName string
+	// Path is the executable; empty looks up stashr-storage-<name> in PATH
+	Path    string
+	Options map[string]string
+	// ContentType is sent with uploads; empty leaves the type to the plugin
+	ContentType string
+}
+
+// NewPlugin creates a storage backend backed by a plugin executable
+func NewPlugin(name, path string, options map[string]string) *Plugin {
+	if path == "" {
+		path = PluginExecutablePrefix + name
+	}
+	return &Plugin{
+		PluginName: name,
+		Path:       path,
+		Options:    options,
+	}
+}
+
+// Name returns the name of the storage backend
+func (p *Plugin) Name() string {
+	return p.PluginName
+}
+
+// IsAvailable checks that the plugin is installed and its provider is reachable
+func (p *Plugin) IsAvailable() (bool, error) {
+	if _, err := exec.LookPath(p.Path); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: p.Name(),
+			Reason:  fmt.Sprintf("plugin executable %s not found", p.Path),
+		}
+	}
+
+	resp, err := p.call(PluginRequest{Command: PluginCommandAvailable})
+	if err != nil {
+		return false, &StorageUnavailableError{
+			Storage: p.Name(),
+			Reason:  err.Error(),
+		}
+	}
+	if !resp.Available {
+		reason := resp.Reason
+		if reason == "" {
+			reason = "plugin reported the destination unavailable"
+		}
+		return false, &StorageUnavailableError{
+			Storage: p.Name(),
+			Reason:  reason,
+		}
+	}
+
+	return true, nil
+}
+
+// Upload stores a file through the plugin
+func (p *Plugin) Upload(filename string, data []byte) error {
+	if _, err := p.call(PluginRequest{Command: PluginCommandUpload, Filename: filename, Data: data, ContentType: p.ContentType}); err != nil {
+		return &UploadError{
+			Storage: p.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// Download reads a file through the plugin
+func (p *Plugin) Download(filename string) ([]byte, error) {
+	resp, err := p.call(PluginRequest{Command: PluginCommandDownload, Filename: filename})
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: p.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+	return resp.Data, nil
+}
+
+// List lists the backups the plugin stores, including those in subfolders
+func (p *Plugin) List() ([]BackupFile, error) {
+	resp, err := p.call(PluginRequest{Command: PluginCommandList})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var backups []BackupFile
+	for _, file := range resp.Files {
+		if file.Name == "" || hasIgnoredElement(file.Name) {
+			continue
+		}
+		backups = append(backups, BackupFile{
+			Name:         file.Name,
+			Size:         file.Size,
+			ModifiedTime: file.Modified,
+			Location:     p.Name() + ":" + file.Name,
+			StorageType:  p.Name(),
+		})
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file through the plugin
+func (p *Plugin) Delete(filename string) error {
+	if _, err := p.call(PluginRequest{Command: PluginCommandDelete, Filename: filename}); err != nil {
+		if errors.Is(err, errPluginNotFound) {
+			return fmt.Errorf("file not found")
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (p *Plugin) CleanOldBackups(keepLast int) error {
+	backups, err := p.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, p.Delete)
+	return err
+}
+
+// errPluginNotFound is returned when a plugin reports a missing file
+var errPluginNotFound = errors.New("file not found")
+
+// call runs one plugin command and returns its successful response
+func (p *Plugin) call(req PluginRequest) (*PluginResponse, error) {
+	req.Version = PluginProtocolVersion
+	req.Options = p.Options
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	cmd := exec.Command(p.Path, req.Command)
+	cmd.Env = proxyEnv()
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %v (output: %s)", req.Command, runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("plugin %s returned an invalid response: %w", req.Command, err)
+	}
+	if resp.NotFound {
+		return nil, errPluginNotFound
+	}
+	if !resp.OK || runErr != nil {
+		message := resp.Error
+		if message == "" && runErr != nil {
+			message = fmt.Sprintf("%v (output: %s)", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("plugin %s failed: %s", req.Command, message)
+	}
+
+	return &resp, nil
+}
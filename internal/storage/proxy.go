@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/oauth2"
+)
+
+// proxyConfig is the proxy set with SetProxy; nil uses the environment
+var proxyConfig *httpproxy.Config
+
+// SetProxy routes the requests of cloud destinations created afterwards through
+// proxyURL (http, https, socks5 or socks5h), reaching the hosts in noProxy
+// directly. An empty proxyURL uses HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func SetProxy(proxyURL, noProxy string) error {
+	if proxyURL == "" {
+		proxyConfig = nil
+		return nil
+	}
+	if _, err := url.Parse(proxyURL); err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	proxyConfig = &httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    noProxy,
+	}
+	return nil
+}
+
+// proxyFunc returns the proxy of a request, if any
+func proxyFunc() func(*http.Request) (*url.URL, error) {
+	if proxyConfig == nil {
+		return http.ProxyFromEnvironment
+	}
+	proxy := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// httpTransport returns a transport that connects through the configured proxy
+func httpTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc()
+	return transport
+}
+
+// newHTTPClient returns an HTTP client that connects through the configured proxy
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: httpTransport()}
+}
+
+// oauthContext returns a context whose OAuth2 clients, including the ones
+// refreshing tokens, connect through the configured proxy
+func oauthContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: httpTransport()})
+}
+
+// proxyEnv returns the environment of external tools like rclone and plugins,
+// with the configured proxy in the variables they read
+func proxyEnv() []string {
+	env := os.Environ()
+	if proxyConfig == nil {
+		return env
+	}
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy"} {
+		env = append(env, name+"="+proxyConfig.HTTPSProxy)
+	}
+	for _, name := range []string{"NO_PROXY", "no_proxy"} {
+		env = append(env, name+"="+proxyConfig.NoProxy)
+	}
+	return env
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Exit codes used by rclone to report missing paths
+const (
+	rcloneExitDirNotFound  = 3
+	rcloneExitFileNotFound = 4
+)
+
+// Rclone represents a storage backend that shells out to rclone, giving access
+// to every provider rclone supports through a configured remote
+type Rclone struct {
+	// Remote is an rclone path such as "b2:my-bucket/stashr"
+	Remote  string
+	CLIPath string
+	// ConfigPath overrides rclone's default config file when set
+	ConfigPath string
+}
+
+// NewRclone creates a new rclone storage backend
+func NewRclone(remote, cliPath, configPath string) *Rclone {
+	if cliPath == "" {
+		cliPath = "rclone"
+	}
+	return &Rclone{
+		Remote:     strings.TrimRight(remote, "/"),
+		CLIPath:    cliPath,
+		ConfigPath: configPath,
+	}
+}
+
+// Name returns the name of the storage backend
+func (r *Rclone) Name() string {
+	return "rclone"
+}
+
+// IsAvailable checks if rclone is installed and the remote is configured and reachable
+func (r *Rclone) IsAvailable() (bool, error) {
+	if _, err := exec.LookPath(r.CLIPath); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: r.Name(),
+			Reason:  fmt.Sprintf("rclone CLI not found at %s", r.CLIPath),
+		}
+	}
+	if r.Remote == "" {
+		return false, &StorageUnavailableError{
+			Storage: r.Name(),
+			Reason:  "remote not configured",
+		}
+	}
+
+	// Remotes are "name:path"; local paths (including Windows drive letters) need no configured remote
+	if name, _, ok := strings.Cut(r.Remote, ":"); ok && len(name) > 1 {
+		output, err := r.rclone("listremotes")
+		if err != nil {
+			return false, &StorageUnavailableError{
+				Storage: r.Name(),
+				Reason:  fmt.Sprintf("failed to list remotes: %v", err),
+			}
+		}
+		if !containsLine(string(output), name+":") {
+			return false, &StorageUnavailableError{
+				Storage: r.Name(),
+				Reason:  fmt.Sprintf("remote %s is not configured (run: rclone config)", name),
+			}
+		}
+	}
+
+	// A missing directory is fine; rclone creates it on the first upload
+	if _, err := r.rclone("lsjson", "--files-only", "--max-depth", "1", r.Remote); err != nil && rcloneExitCode(err) != rcloneExitDirNotFound {
+		return false, &StorageUnavailableError{
+			Storage: r.Name(),
+			Reason:  fmt.Sprintf("failed to reach %s: %v", r.Remote, err),
+		}
+	}
+
+	return true, nil
+}
+
+// remotePath returns the rclone path of a backup file
+func (r *Rclone) remotePath(filename string) string {
+	if strings.HasSuffix(r.Remote, ":") {
+		return r.Remote + filename
+	}
+	return r.Remote + "/" + filename
+}
+
+// Upload copies a file to the remote
+func (r *Rclone) Upload(filename string, data []byte) error {
+	// rclone copies from a file, so stage the backup in a private temporary file
+	tmpFile, err := os.CreateTemp("", "stashr-rclone-*")
+	if err != nil {
+		return &UploadError{
+			Storage: r.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to create temporary file: %w", err),
+		}
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &UploadError{
+			Storage: r.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to write temporary file: %w", err),
+		}
+	}
+
+	if _, err := r.rclone("copyto", tmpFile.Name(), r.remotePath(filename)); err != nil {
+		return &UploadError{
+			Storage: r.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	return nil
+}
+
+// Download reads a file from the remote
+func (r *Rclone) Download(filename string) ([]byte, error) {
+	data, err := r.rclone("cat", r.remotePath(filename))
+	if err != nil {
+		if code := rcloneExitCode(err); code == rcloneExitFileNotFound || code == rcloneExitDirNotFound {
+			err = fmt.Errorf("file not found")
+		}
+		return nil, &DownloadError{
+			Storage: r.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	return data, nil
+}
+
+// rcloneEntry is one entry of "rclone lsjson" output
+type rcloneEntry struct {
+	Path    string    `json:"Path"`
+	Name    string    `json:"Name"`
+	Size    int64     `json:"Size"`
+	ModTime time.Time `json:"ModTime"`
+	IsDir   bool      `json:"IsDir"`
+}
+
+// List lists all backup files in the remote directory, including those in subfolders
+func (r *Rclone) List() ([]BackupFile, error) {
+	output, err := r.rclone("lsjson", "--files-only", "--recursive", r.Remote)
+	if err != nil {
+		// The directory is created by the first upload
+		if rcloneExitCode(err) == rcloneExitDirNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var entries []rcloneEntry
+	if err := json.Unmarshal(output, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	}
+
+	var backups []BackupFile
+	for _, entry := range entries {
+		// Path is relative to the remote directory, keeping any subfolder
+		if entry.IsDir || hasIgnoredElement(entry.Path) {
+			continue
+		}
+		backups = append(backups, BackupFile{
+			Name:         entry.Path,
+			Size:         entry.Size,
+			ModifiedTime: entry.ModTime,
+			Location:     r.remotePath(entry.Path),
+			StorageType:  r.Name(),
+		})
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file from the remote
+func (r *Rclone) Delete(filename string) error {
+	if _, err := r.rclone("deletefile", r.remotePath(filename)); err != nil {
+		if code := rcloneExitCode(err); code == rcloneExitFileNotFound || code == rcloneExitDirNotFound {
+			return fmt.Errorf("file not found")
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (r *Rclone) CleanOldBackups(keepLast int) error {
+	backups, err := r.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, r.Delete)
+	return err
+}
+
+// rclone runs an rclone command and returns its output
+func (r *Rclone) rclone(args ...string) ([]byte, error) {
+	command := args[0]
+	if r.ConfigPath != "" {
+		args = append([]string{"--config", r.ConfigPath}, args...)
+	}
+
+	cmd := exec.Command(r.CLIPath, args...)
+	cmd.Env = proxyEnv()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, &rcloneError{
+			command: command,
+			output:  strings.TrimSpace(stderr.String()),
+			err:     err,
+		}
+	}
+	return output, nil
+}
+
+// rcloneError is a failed rclone command, keeping its exit status
+type rcloneError struct {
+	command string
+	output  string
+	err     error
+}
+
+func (e *rcloneError) Error() string {
+	return fmt.Sprintf("rclone %s failed: %v (output: %s)", e.command, e.err, e.output)
+}
+
+func (e *rcloneError) Unwrap() error {
+	return e.err
+}
+
+// rcloneExitCode returns the exit code of a failed rclone command, or -1
+func rcloneExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// containsLine reports whether output has a line equal to want
+func containsLine(output, want string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == want {
+			return true
+		}
+	}
+	return false
+}
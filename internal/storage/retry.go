@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// rcloneExitRetryable is rclone's exit code for temporary errors worth retrying
+const rcloneExitRetryable = 5
+
+// transientStatusCodes are the HTTP statuses that say a request may succeed if repeated
+var transientStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// IsTransient reports whether an operation that failed with err may succeed if
+// retried: rate limiting, server errors, timeouts and dropped connections.
+// Authentication failures, missing files, full destinations and other errors
+// that retrying can't fix are permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var marked interface{ Transient() bool }
+	if errors.As(err, &marked) {
+		return marked.Transient()
+	}
+	var unavailable *StorageUnavailableError
+	var leaseHeld *LeaseHeldError
+	if errors.As(err, &unavailable) || errors.As(err, &leaseHeld) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if isTransientStatus(apiErr.Code) {
+			return true
+		}
+		for _, item := range apiErr.Errors {
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var rcloneErr *rcloneError
+	if errors.As(err, &rcloneErr) {
+		return rcloneExitCode(rcloneErr.err) == rcloneExitRetryable
+	}
+
+	// Most backends report unsuccessful responses with their status, e.g.
+	// "failed to upload file: 503 Service Unavailable"
+	message := err.Error()
+	for _, code := range transientStatusCodes {
+		if strings.Contains(message, fmt.Sprintf("%d %s", code, http.StatusText(code))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientStatus reports whether an HTTP status is worth retrying
+func isTransientStatus(code int) bool {
+	for _, transient := range transientStatusCodes {
+		if code == transient {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy decides how often and how patiently failed operations are retried
+type RetryPolicy struct {
+	// Attempts is how often an operation is tried in total
+	Attempts int
+	// InitialDelay is the wait before the first retry; each further retry
+	// waits twice as long, up to MaxDelay
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Jitter randomizes each delay by up to this fraction, so hosts rate
+	// limited together don't retry together
+	Jitter float64
+}
+
+// Delay returns the wait before retry number retry, counting from 1
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.InitialDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// RetryFunc is called before an operation is retried, with the failed attempt
+// (counting from 1), its error and the wait before the next one
+type RetryFunc func(operation string, attempt int, err error, delay time.Duration)
+
+// RetryStorage retries the operations of a backend that fail transiently
+type RetryStorage struct {
+	Storage
+	policy  RetryPolicy
+	onRetry RetryFunc
+}
+
+// NewRetryStorage wraps a storage backend so its transient failures are retried.
+// onRetry may be nil.
+func NewRetryStorage(backend Storage, policy RetryPolicy, onRetry RetryFunc) *RetryStorage {
+	return &RetryStorage{
+		Storage: backend,
+		policy:  policy,
+		onRetry: onRetry,
+	}
+}
+
+// retry runs an operation until it succeeds, fails permanently or runs out of attempts
+func (s *RetryStorage) retry(operation string, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !IsTransient(err) || attempt >= s.policy.Attempts {
+			return err
+		}
+		delay := s.policy.Delay(attempt)
+		if s.onRetry != nil {
+			s.onRetry(operation, attempt, err, delay)
+		}
+		time.Sleep(delay)
+	}
+}
+
+// Upload uploads a file, retrying transient failures
+func (s *RetryStorage) Upload(filename string, data []byte) error {
+	return s.retry("Upload of "+filename, func() error {
+		return s.Storage.Upload(filename, data)
+	})
+}
+
+// UploadWithProgress uploads a file, reporting its progress and retrying
+// transient failures from the start
+func (s *RetryStorage) UploadWithProgress(filename string, data []byte, progress ProgressFunc) error {
+	return s.retry("Upload of "+filename, func() error {
+		return UploadWithProgress(s.Storage, filename, data, progress)
+	})
+}
+
+// Download downloads a file, retrying transient failures
+func (s *RetryStorage) Download(filename string) ([]byte, error) {
+	var data []byte
+	err := s.retry("Download of "+filename, func() error {
+		var err error
+		data, err = s.Storage.Download(filename)
+		return err
+	})
+	return data, err
+}
+
+// List lists the backups, retrying transient failures
+func (s *RetryStorage) List() ([]BackupFile, error) {
+	var backups []BackupFile
+	err := s.retry("Listing", func() error {
+		var err error
+		backups, err = s.Storage.List()
+		return err
+	})
+	return backups, err
+}
+
+// Delete deletes a file, retrying transient failures
+func (s *RetryStorage) Delete(filename string) error {
+	return s.retry("Deletion of "+filename, func() error {
+		return s.Storage.Delete(filename)
+	})
+}
+
+// StoredChecksum forwards to the wrapped backend, if its provider records checksums
+func (s *RetryStorage) StoredChecksum(filename string) (string, string, error) {
+	provider, ok := s.Storage.(ChecksumProvider)
+	if !ok {
+		return "", "", ErrChecksumUnavailable
+	}
+	var algorithm, checksum string
+	err := s.retry("Checksum of "+filename, func() error {
+		var err error
+		algorithm, checksum, err = provider.StoredChecksum(filename)
+		return err
+	})
+	return algorithm, checksum, err
+}
+
+// GetFreeSpace forwards to the wrapped backend, if it can report its free space
+func (s *RetryStorage) GetFreeSpace() (int64, error) {
+	return FreeSpace(s.Storage)
+}
+
+// LockedUntil forwards to the wrapped backend, if it can lock backups, retrying transient failures
+func (s *RetryStorage) LockedUntil(filename string) (time.Time, error) {
+	var until time.Time
+	err := s.retry("Lock check of "+filename, func() error {
+		var err error
+		until, err = LockedUntil(s.Storage, filename)
+		return err
+	})
+	return until, err
+}
+
+// WaitForSync forwards to the wrapped backend, if it is a synced folder
+func (s *RetryStorage) WaitForSync(filename string) (SyncState, error) {
+	return WaitForSync(s.Storage, filename)
+}
+
+// Trash moves a file to the wrapped backend's trash, if it has one, retrying transient failures
+func (s *RetryStorage) Trash(filename string) error {
+	return s.retry("Trashing of "+filename, func() error {
+		return Trash(s.Storage, filename)
+	})
+}
+
+// ListTrash forwards to the wrapped backend, if it has a trash
+func (s *RetryStorage) ListTrash() ([]TrashedFile, error) {
+	var files []TrashedFile
+	err := s.retry("Trash listing", func() error {
+		var err error
+		files, err = ListTrash(s.Storage)
+		return err
+	})
+	return files, err
+}
+
+// DeleteTrashed forwards to the wrapped backend, if it has a trash
+func (s *RetryStorage) DeleteTrashed(filename string) error {
+	return s.retry("Deletion of "+filename, func() error {
+		return DeleteTrashed(s.Storage, filename)
+	})
+}
+
+// unwrap returns the wrapped backend
+func (s *RetryStorage) unwrap() Storage {
+	return s.Storage
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// DefaultS3Endpoint is the endpoint of Amazon S3
+const DefaultS3Endpoint = "s3.amazonaws.com"
+
+// S3 represents a bucket on Amazon S3 or an S3-compatible service such as
+// Backblaze B2, Wasabi or MinIO
+type S3 struct {
+	// Endpoint is the host of the S3 API, e.g. "s3.us-west-004.backblazeb2.com";
+	// an http:// prefix connects without TLS, to a local MinIO server for example
+	Endpoint string
+	Region   string
+	Bucket   string
+	Prefix   string
+	// AccessKeyID and SecretAccessKey sign requests. Without them the AWS_*
+	// environment variables and ~/.aws/credentials are used.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// ContentType is the MIME type uploads are stored with; empty uses DefaultContentType
+	ContentType string
+	// LockDays locks each uploaded backup with S3 Object Lock in compliance mode
+	// for this many days, so it can't be deleted or overwritten until then, not
+	// even with the bucket owner's credentials; 0 uploads unlocked backups. The
+	// bucket needs Object Lock enabled (on B2, file lock).
+	LockDays int
+
+	client *minio.Client
+}
+
+// NewS3 creates a new S3 backend. An empty endpoint is Amazon S3.
+func NewS3(endpoint, region, bucket, prefix, accessKeyID, secretAccessKey string) *S3 {
+	if endpoint == "" {
+		endpoint = DefaultS3Endpoint
+	}
+	return &S3{
+		Endpoint:        endpoint,
+		Region:          region,
+		Bucket:          bucket,
+		Prefix:          strings.Trim(prefix, "/"),
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	}
+}
+
+// Name returns the name of the storage backend
+func (s *S3) Name() string {
+	return "S3"
+}
+
+// IsAvailable checks if the bucket is reachable with the configured credentials
+func (s *S3) IsAvailable() (bool, error) {
+	if s.Bucket == "" {
+		return false, &StorageUnavailableError{
+			Storage: s.Name(),
+			Reason:  "bucket not configured",
+		}
+	}
+
+	if err := s.initClient(); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: s.Name(),
+			Reason:  fmt.Sprintf("failed to initialize client: %v", err),
+		}
+	}
+
+	// Listing needs only object permissions, unlike reading the bucket configuration
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for object := range s.client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: s.objectPrefix(), MaxKeys: 1}) {
+		if object.Err != nil {
+			return false, &StorageUnavailableError{
+				Storage: s.Name(),
+				Reason:  fmt.Sprintf("failed to access bucket %s: %v", s.Bucket, object.Err),
+			}
+		}
+		break
+	}
+
+	return true, nil
+}
+
+// initClient initializes the S3 client
+func (s *S3) initClient() error {
+	if s.client != nil {
+		return nil // Already initialized
+	}
+
+	endpoint, secure := s.Endpoint, true
+	if rest, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		endpoint, secure = rest, false
+	}
+	endpoint = strings.TrimSuffix(strings.TrimPrefix(endpoint, "https://"), "/")
+
+	var creds *credentials.Credentials
+	if s.AccessKeyID != "" || s.SecretAccessKey != "" {
+		creds = credentials.NewStaticV4(s.AccessKeyID, s.SecretAccessKey, "")
+	} else {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+		})
+	}
+
+	// The client connects through the configured proxy
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:     creds,
+		Secure:    secure,
+		Region:    s.Region,
+		Transport: httpTransport(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	s.client = client
+	return nil
+}
+
+// objectPrefix returns the prefix shared by all backup objects, with a trailing slash
+func (s *S3) objectPrefix() string {
+	if s.Prefix == "" {
+		return ""
+	}
+	return s.Prefix + "/"
+}
+
+// objectName returns the object key of a backup file
+func (s *S3) objectName(filename string) string {
+	return path.Join(s.Prefix, filename)
+}
+
+// isS3NotFound reports whether an S3 error says the object doesn't exist
+func isS3NotFound(err error) bool {
+	response := minio.ToErrorResponse(err)
+	return response.StatusCode == http.StatusNotFound || response.Code == "NoSuchKey"
+}
+
+// Upload uploads a file to the bucket
+func (s *S3) Upload(filename string, data []byte) error {
+	if err := s.initClient(); err != nil {
+		return &UploadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	options := minio.PutObjectOptions{ContentType: contentTypeOrDefault(s.ContentType)}
+	if s.LockDays > 0 {
+		options.Mode = minio.Compliance
+		options.RetainUntilDate = lockUntil(s.LockDays)
+		// Object Lock uploads must carry a Content-MD5 header
+		options.SendContentMd5 = true
+	}
+	_, err := s.client.PutObject(context.Background(), s.Bucket, s.objectName(filename),
+		bytes.NewReader(data), int64(len(data)), options)
+	if err != nil {
+		return &UploadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
+	}
+
+	return nil
+}
+
+// Download downloads a file from the bucket
+func (s *S3) Download(filename string) ([]byte, error) {
+	if err := s.initClient(); err != nil {
+		return nil, &DownloadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	object, err := s.client.GetObject(context.Background(), s.Bucket, s.objectName(filename), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+	defer object.Close()
+
+	// The request is only sent on the first read
+	data, err := io.ReadAll(object)
+	if isS3NotFound(err) {
+		return nil, &DownloadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("file not found"),
+		}
+	}
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: s.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file content: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// List lists all backup files under the prefix, including those in subfolders
+func (s *S3) List() ([]BackupFile, error) {
+	if err := s.initClient(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var backups []BackupFile
+	for object := range s.client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: s.objectPrefix(), Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", object.Err)
+		}
+
+		// Names are relative to the prefix, keeping any subfolder
+		name := strings.TrimPrefix(object.Key, s.objectPrefix())
+		if name == "" || strings.HasSuffix(name, "/") || hasIgnoredElement(name) {
+			continue
+		}
+
+		backups = append(backups, BackupFile{
+			Name:         name,
+			Size:         object.Size,
+			ModifiedTime: object.LastModified,
+			Location:     fmt.Sprintf("s3://%s/%s", s.Bucket, object.Key),
+			StorageType:  s.Name(),
+		})
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file from the bucket. In a versioned bucket, which Object
+// Lock requires, the current version is deleted rather than hidden behind a
+// delete marker, so the space is freed.
+func (s *S3) Delete(filename string) error {
+	if err := s.initClient(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	info, err := s.client.StatObject(ctx, s.Bucket, s.objectName(filename), minio.StatObjectOptions{})
+	if isS3NotFound(err) {
+		return fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	err = s.client.RemoveObject(ctx, s.Bucket, s.objectName(filename), minio.RemoveObjectOptions{VersionID: info.VersionID})
+	if err != nil {
+		// Deleting a version under retention is denied
+		if lockErr := checkUnlocked(s, filename); lockErr != nil {
+			return lockErr
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// LockedUntil returns when the Object Lock retention of a backup's current
+// version expires, whether it was set on upload or by the bucket's default
+func (s *S3) LockedUntil(filename string) (time.Time, error) {
+	if err := s.initClient(); err != nil {
+		return time.Time{}, err
+	}
+
+	_, until, err := s.client.GetObjectRetention(context.Background(), s.Bucket, s.objectName(filename), "")
+	if err != nil {
+		response := minio.ToErrorResponse(err)
+		switch {
+		case response.Code == "NoSuchObjectLockConfiguration":
+			// The object has no retention
+			return time.Time{}, nil
+		case response.Code == "InvalidRequest" || response.Code == "ObjectLockConfigurationNotFoundError":
+			// The bucket doesn't have Object Lock enabled
+			return time.Time{}, nil
+		case isS3NotFound(err):
+			return time.Time{}, fmt.Errorf("file not found")
+		}
+		return time.Time{}, fmt.Errorf("failed to get object retention: %w", err)
+	}
+	if until == nil {
+		return time.Time{}, nil
+	}
+	return *until, nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (s *S3) CleanOldBackups(keepLast int) error {
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, s.Delete)
+	return err
+}
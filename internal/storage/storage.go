@@ -1,9 +1,16 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/harshalranjhani/stashr/internal/backupname"
 )
 
 // Storage represents a storage backend interface
@@ -27,6 +34,44 @@ type Storage interface {
 	Delete(filename string) error
 }
 
+// DefaultContentType is the MIME type backups are stored with unless a
+// destination is configured with another
+const DefaultContentType = "application/octet-stream"
+
+// contentTypeOrDefault returns contentType, or DefaultContentType if it is empty
+func contentTypeOrDefault(contentType string) string {
+	if contentType == "" {
+		return DefaultContentType
+	}
+	return contentType
+}
+
+// ProgressFunc receives the number of bytes uploaded so far out of the total
+type ProgressFunc func(sent, total int64)
+
+// ProgressUploader is implemented by backends that upload in chunks and can
+// report progress while they do
+type ProgressUploader interface {
+	UploadWithProgress(filename string, data []byte, progress ProgressFunc) error
+}
+
+// UploadWithProgress uploads a file, reporting progress if the backend supports
+// it. Other backends report completion once the upload succeeds.
+func UploadWithProgress(backend Storage, filename string, data []byte, progress ProgressFunc) error {
+	if progress == nil {
+		return backend.Upload(filename, data)
+	}
+	if uploader, ok := backend.(ProgressUploader); ok {
+		return uploader.UploadWithProgress(filename, data, progress)
+	}
+
+	if err := backend.Upload(filename, data); err != nil {
+		return err
+	}
+	progress(int64(len(data)), int64(len(data)))
+	return nil
+}
+
 // BackupFile represents a backup file in storage
 type BackupFile struct {
 	Name         string
@@ -76,30 +121,210 @@ func (e *DownloadError) Unwrap() error {
 	return e.Err
 }
 
-// ApplyRetentionPolicy applies a retention policy to a list of backups
-func ApplyRetentionPolicy(backups []BackupFile, keepLast int, deleteFunc func(string) error) error {
-	if len(backups) <= keepLast {
-		return nil // Nothing to delete
+// RetentionPolicy decides which backups survive pruning. Rules are applied to
+// each manager's backups separately, and a backup is kept if any rule keeps it.
+type RetentionPolicy struct {
+	// KeepLast keeps the newest N backups
+	KeepLast int
+	// KeepDays keeps every backup made in the last N days
+	KeepDays int
+	// KeepDaily, KeepWeekly, KeepMonthly and KeepYearly keep the newest backup of
+	// each of the last N days, weeks, months and years that have a backup
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+	KeepYearly  int
+}
+
+// String describes the rules of a policy, e.g. "last 10, 7 daily, 4 weekly"
+func (p RetentionPolicy) String() string {
+	var rules []string
+	if p.KeepLast > 0 {
+		rules = append(rules, fmt.Sprintf("last %d", p.KeepLast))
+	}
+	if p.KeepDays > 0 {
+		rules = append(rules, fmt.Sprintf("%d days", p.KeepDays))
+	}
+	for _, rule := range []struct {
+		count int
+		name  string
+	}{
+		{p.KeepDaily, "daily"},
+		{p.KeepWeekly, "weekly"},
+		{p.KeepMonthly, "monthly"},
+		{p.KeepYearly, "yearly"},
+	} {
+		if rule.count > 0 {
+			rules = append(rules, fmt.Sprintf("%d %s", rule.count, rule.name))
+		}
+	}
+	if len(rules) == 0 {
+		return "none"
+	}
+	return strings.Join(rules, ", ")
+}
+
+// retentionPeriods are the calendar periods of the grandfather-father-son rules
+var retentionPeriods = []struct {
+	name  string
+	count func(RetentionPolicy) int
+	key   func(time.Time) string
+}{
+	{"daily", func(p RetentionPolicy) int { return p.KeepDaily }, func(t time.Time) string { return t.Format("2006-01-02") }},
+	{"weekly", func(p RetentionPolicy) int { return p.KeepWeekly }, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}},
+	{"monthly", func(p RetentionPolicy) int { return p.KeepMonthly }, func(t time.Time) string { return t.Format("2006-01") }},
+	{"yearly", func(p RetentionPolicy) int { return p.KeepYearly }, func(t time.Time) string { return t.Format("2006") }},
+}
+
+// RetentionDecision is what a retention policy does with one backup, and why
+type RetentionDecision struct {
+	Backup  BackupFile
+	Manager string
+	Keep    bool
+	// Skipped is set for files whose manager isn't known, such as obfuscated
+	// names without their mapping or files that aren't backups. Retention keeps
+	// them rather than count them against any manager's backups.
+	Skipped bool
+	// Reasons lists the rules that keep the backup, or for a deleted backup
+	// why each rule doesn't
+	Reasons []string
+}
+
+// ApplyRetentionPolicy deletes the backups a retention policy doesn't keep and
+// returns how many were deleted. managerOf returns the manager a backup belongs
+// to, so one manager's backups never evict another's, and "" for files retention
+// leaves alone; nil reads it from the built-in filename formats.
+func ApplyRetentionPolicy(backups []BackupFile, policy RetentionPolicy, managerOf func(string) string, deleteFunc func(string) error) (int, error) {
+	deleted := 0
+	for _, backup := range RetentionCandidates(backups, policy, managerOf, time.Now()) {
+		if err := deleteFunc(backup.Name); err != nil {
+			// A locked backup is removed by a later run, once its lock expires
+			var locked *ObjectLockedError
+			if errors.As(err, &locked) {
+				continue
+			}
+			return deleted, fmt.Errorf("failed to remove %s: %w", backup.Name, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// RetentionCandidates returns the backups a retention policy would delete at
+// now, oldest last
+func RetentionCandidates(backups []BackupFile, policy RetentionPolicy, managerOf func(string) string, now time.Time) []BackupFile {
+	var candidates []BackupFile
+	for _, decision := range EvaluateRetention(backups, policy, managerOf, now) {
+		if !decision.Keep {
+			candidates = append(candidates, decision.Backup)
+		}
+	}
+	return candidates
+}
+
+// EvaluateRetention decides for every backup whether a retention policy keeps
+// it at now, newest first
+func EvaluateRetention(backups []BackupFile, policy RetentionPolicy, managerOf func(string) string, now time.Time) []RetentionDecision {
+	if managerOf == nil {
+		managerOf = func(name string) string {
+			return backupname.Parse(name).Manager
+		}
 	}
 
 	// Sort backups by modification time (newest first)
-	// Note: This assumes the list is already sorted, but we should sort it anyway
-	for i := 0; i < len(backups)-1; i++ {
-		for j := i + 1; j < len(backups); j++ {
-			if backups[i].ModifiedTime.Before(backups[j].ModifiedTime) {
-				backups[i], backups[j] = backups[j], backups[i]
+	decisions := make([]RetentionDecision, len(backups))
+	for i, backup := range backups {
+		decisions[i] = RetentionDecision{Backup: backup, Manager: managerOf(backup.Name)}
+	}
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].Backup.ModifiedTime.After(decisions[j].Backup.ModifiedTime)
+	})
+
+	byManager := make(map[string][]int)
+	var order []string
+	for i, decision := range decisions {
+		if decision.Manager == "" {
+			decisions[i].Skipped = true
+			continue
+		}
+		if _, ok := byManager[decision.Manager]; !ok {
+			order = append(order, decision.Manager)
+		}
+		byManager[decision.Manager] = append(byManager[decision.Manager], i)
+	}
+
+	kept := make([][]string, len(decisions))
+	rejected := make([][]string, len(decisions))
+	cutoff := now.AddDate(0, 0, -policy.KeepDays)
+	for _, manager := range order {
+		indexes := byManager[manager]
+		for n, i := range indexes {
+			if policy.KeepLast > 0 {
+				if n < policy.KeepLast {
+					kept[i] = append(kept[i], fmt.Sprintf("one of the newest %d", policy.KeepLast))
+				} else {
+					rejected[i] = append(rejected[i], fmt.Sprintf("not among the newest %d (#%d)", policy.KeepLast, n+1))
+				}
+			}
+			if policy.KeepDays > 0 {
+				if decisions[i].Backup.ModifiedTime.After(cutoff) {
+					kept[i] = append(kept[i], fmt.Sprintf("made in the last %d days", policy.KeepDays))
+				} else {
+					rejected[i] = append(rejected[i], fmt.Sprintf("older than %d days", policy.KeepDays))
+				}
+			}
+		}
+
+		// The newest backup of each period is kept, for as many periods as the rule allows
+		for _, period := range retentionPeriods {
+			count := period.count(policy)
+			if count <= 0 {
+				continue
+			}
+			periods := 0
+			lastKey := ""
+			for _, i := range indexes {
+				key := period.key(decisions[i].Backup.ModifiedTime.Local())
+				switch {
+				case key == lastKey:
+					rejected[i] = append(rejected[i], fmt.Sprintf("a newer backup is kept for %s", key))
+				case periods >= count:
+					rejected[i] = append(rejected[i], fmt.Sprintf("%s is beyond the %d %s periods kept", key, count, period.name))
+				default:
+					kept[i] = append(kept[i], fmt.Sprintf("newest of %s (%s)", key, period.name))
+					periods++
+				}
+				lastKey = key
 			}
 		}
 	}
 
-	// Delete old backups
-	for i := keepLast; i < len(backups); i++ {
-		if err := deleteFunc(backups[i].Name); err != nil {
-			return fmt.Errorf("failed to delete %s: %w", backups[i].Name, err)
+	for i := range decisions {
+		if decisions[i].Skipped {
+			decisions[i].Keep = true
+			decisions[i].Reasons = []string{"manager unknown, not subject to retention"}
+			continue
+		}
+		decisions[i].Keep = len(kept[i]) > 0
+		if decisions[i].Keep {
+			decisions[i].Reasons = kept[i]
+		} else {
+			decisions[i].Reasons = rejected[i]
 		}
 	}
+	return decisions
+}
 
-	return nil
+// SignatureSuffix names the signature file stored next to a signed backup
+const SignatureSuffix = ".sig"
+
+// SignatureName returns the name of a backup's signature file
+func SignatureName(filename string) string {
+	return filename + SignatureSuffix
 }
 
 // shouldIgnoreFile returns true if the file should be ignored when listing backups.
@@ -115,6 +340,16 @@ func shouldIgnoreFile(filename string) bool {
 		return true
 	}
 
+	// Filter out backups still being written, or left unfinished by an interrupted write
+	if isPartialFile(filename) {
+		return true
+	}
+
+	// Filter out the signatures stored next to signed backups
+	if strings.HasSuffix(filename, SignatureSuffix) {
+		return true
+	}
+
 	// Filter out other common hidden/system files
 	// Note: Legitimate backup files never start with a dot based on our naming convention
 	if strings.HasPrefix(filename, ".") {
@@ -123,3 +358,63 @@ func shouldIgnoreFile(filename string) bool {
 
 	return false
 }
+
+// hasIgnoredElement reports whether any element of a slash-separated path is
+// a hidden/system file or folder
+func hasIgnoredElement(name string) bool {
+	for _, element := range strings.Split(name, "/") {
+		if shouldIgnoreFile(element) {
+			return true
+		}
+	}
+	return false
+}
+
+// listBackupTree lists the backups under a directory and its subfolders. Names
+// are slash-separated paths relative to root, e.g. "bitwarden/backup_....enc".
+// Every file is listed; retention leaves those with no known manager alone.
+func listBackupTree(root, storageType string) ([]BackupFile, error) {
+	var backups []BackupFile
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return fs.SkipAll // No backups yet
+			}
+			return err
+		}
+
+		// Skip hidden/system files and folders (e.g., ._ files, .DS_Store, .Trashes)
+		if path != root && shouldIgnoreFile(entry.Name()) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		backups = append(backups, BackupFile{
+			Name:         filepath.ToSlash(rel),
+			Size:         info.Size(),
+			ModifiedTime: info.ModTime(),
+			Location:     path,
+			StorageType:  storageType,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	return backups, nil
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// syncFileSystem flushes every pending write on the file system holding dir
+func syncFileSystem(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := unix.Syncfs(int(d.Fd())); err != nil {
+		return fmt.Errorf("failed to flush %s: %w", dir, err)
+	}
+	return nil
+}
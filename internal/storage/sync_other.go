@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storage
+
+// syncFileSystem has nothing to flush beyond the files themselves, which are
+// synced as they are written
+func syncFileSystem(dir string) error {
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build unix && !linux
+
+package storage
+
+import "syscall"
+
+// syncFileSystem flushes pending writes. Only Linux can flush a single file
+// system, so every file system is flushed.
+func syncFileSystem(dir string) error {
+	syscall.Sync()
+	return nil
+}
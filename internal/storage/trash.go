@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// TrashDirName is the hidden folder file system destinations keep trashed
+// backups in. Like other hidden files it is skipped when listing backups.
+const TrashDirName = ".trash"
+
+// ErrTrashUnsupported is returned when a destination has no trash
+var ErrTrashUnsupported = errors.New("destination has no trash")
+
+// TrashedFile is a backup in a destination's trash
+type TrashedFile struct {
+	// Name is the backup's name before it was trashed
+	Name        string
+	Size        int64
+	TrashedTime time.Time
+}
+
+// Trasher is implemented by backends that can move backups to a trash instead of
+// deleting them, so a retention mistake can be undone during a grace period
+type Trasher interface {
+	// Trash moves a backup to the trash
+	Trash(filename string) error
+	// ListTrash lists the backups stashr moved to the trash
+	ListTrash() ([]TrashedFile, error)
+	// DeleteTrashed permanently deletes a trashed backup
+	DeleteTrashed(filename string) error
+}
+
+// wrapper is implemented by backends that wrap another, like NamedStorage,
+// and forward Trasher to it whether or not it has a trash
+type wrapper interface {
+	unwrap() Storage
+}
+
+// SupportsTrash reports whether a backend can move backups to a trash
+func SupportsTrash(backend Storage) bool {
+	if w, ok := backend.(wrapper); ok {
+		return SupportsTrash(w.unwrap())
+	}
+	_, ok := backend.(Trasher)
+	return ok
+}
+
+// Trash moves a backup to the backend's trash, or returns ErrTrashUnsupported
+func Trash(backend Storage, filename string) error {
+	if trasher, ok := backend.(Trasher); ok {
+		return trasher.Trash(filename)
+	}
+	return ErrTrashUnsupported
+}
+
+// ListTrash lists the backend's trashed backups, or returns ErrTrashUnsupported
+func ListTrash(backend Storage) ([]TrashedFile, error) {
+	if trasher, ok := backend.(Trasher); ok {
+		return trasher.ListTrash()
+	}
+	return nil, ErrTrashUnsupported
+}
+
+// DeleteTrashed permanently deletes a trashed backup, or returns ErrTrashUnsupported
+func DeleteTrashed(backend Storage, filename string) error {
+	if trasher, ok := backend.(Trasher); ok {
+		return trasher.DeleteTrashed(filename)
+	}
+	return ErrTrashUnsupported
+}
+
+// trashFile moves a backup below root into root's trash folder, keeping its
+// subfolders. Its modification time records when it was trashed.
+func trashFile(root, filename string) error {
+	source := filepath.Join(root, filepath.FromSlash(filename))
+	target := filepath.Join(root, TrashDirName, filepath.FromSlash(filename))
+	if err := utils.CreateDirIfNotExists(filepath.Dir(target), 0700); err != nil {
+		return fmt.Errorf("failed to create trash folder: %w", err)
+	}
+	if err := os.Rename(source, target); err != nil {
+		return fmt.Errorf("failed to move file to trash: %w", err)
+	}
+	now := time.Now()
+	if err := os.Chtimes(target, now, now); err != nil {
+		return fmt.Errorf("failed to record trash time: %w", err)
+	}
+	return syncDir(filepath.Dir(source))
+}
+
+// listTrashTree lists the backups in root's trash folder
+func listTrashTree(root string) ([]TrashedFile, error) {
+	trashDir := filepath.Join(root, TrashDirName)
+	var files []TrashedFile
+	err := filepath.WalkDir(trashDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == trashDir && os.IsNotExist(err) {
+				return fs.SkipAll // Nothing trashed yet
+			}
+			return err
+		}
+		if entry.IsDir() || shouldIgnoreFile(entry.Name()) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(trashDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, TrashedFile{
+			Name:        filepath.ToSlash(rel),
+			Size:        info.Size(),
+			TrashedTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	return files, nil
+}
+
+// deleteTrashedFile permanently deletes a backup from root's trash folder
+func deleteTrashedFile(root, filename string) error {
+	path := filepath.Join(root, TrashDirName, filepath.FromSlash(filename))
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/harshalranjhani/stashr/internal/backupname"
 	"github.com/harshalranjhani/stashr/pkg/utils"
 )
 
@@ -13,6 +14,10 @@ import (
 type USB struct {
 	MountPath string
 	BackupDir string
+	// Label and UUID find the drive among the mounted volumes when set,
+	// replacing MountPath with the mount point it was found at
+	Label string
+	UUID  string
 }
 
 // NewUSB creates a new USB storage backend
@@ -30,6 +35,13 @@ func (u *USB) Name() string {
 
 // IsAvailable checks if the USB drive is mounted and accessible
 func (u *USB) IsAvailable() (bool, error) {
+	if err := u.resolveMountPath(); err != nil {
+		return false, &StorageUnavailableError{
+			Storage: u.Name(),
+			Reason:  err.Error(),
+		}
+	}
+
 	// Check if mount path exists and is a directory
 	if !utils.DirExists(u.MountPath) {
 		return false, &StorageUnavailableError{
@@ -55,6 +67,21 @@ func (u *USB) IsAvailable() (bool, error) {
 	return true, nil
 }
 
+// resolveMountPath finds the drive by its label or UUID, if configured with one.
+// Drives can be mounted elsewhere each time they are plugged in, so this is
+// repeated on every availability check.
+func (u *USB) resolveMountPath() error {
+	if u.Label == "" && u.UUID == "" {
+		return nil
+	}
+	mountPath, err := FindVolume(u.Label, u.UUID)
+	if err != nil {
+		return err
+	}
+	u.MountPath = mountPath
+	return nil
+}
+
 // getBackupPath returns the full path to the backup directory
 func (u *USB) getBackupPath() string {
 	return filepath.Join(u.MountPath, u.BackupDir)
@@ -74,9 +101,9 @@ func (u *USB) Upload(filename string, data []byte) error {
 		}
 	}
 
-	// Create backup directory if it doesn't exist
-	backupPath := u.getBackupPath()
-	if err := utils.CreateDirIfNotExists(backupPath, 0755); err != nil {
+	// Create backup directory (and any subfolder of the backup) if it doesn't exist
+	filePath := filepath.Join(u.getBackupPath(), filepath.FromSlash(filename))
+	if err := utils.CreateDirIfNotExists(filepath.Dir(filePath), 0755); err != nil {
 		return &UploadError{
 			Storage: u.Name(),
 			File:    filename,
@@ -84,9 +111,8 @@ func (u *USB) Upload(filename string, data []byte) error {
 		}
 	}
 
-	// Write file
-	filePath := filepath.Join(backupPath, filename)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	// Write file, never leaving a partial file under the backup's name
+	if err := writeFileAtomic(filePath, data, 0600); err != nil {
 		return &UploadError{
 			Storage: u.Name(),
 			File:    filename,
@@ -103,6 +129,14 @@ func (u *USB) Upload(filename string, data []byte) error {
 		}
 	}
 
+	if err := u.Sync(); err != nil {
+		return &UploadError{
+			Storage: u.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
 	return nil
 }
 
@@ -121,7 +155,7 @@ func (u *USB) Download(filename string) ([]byte, error) {
 	}
 
 	// Read file
-	filePath := filepath.Join(u.getBackupPath(), filename)
+	filePath := filepath.Join(u.getBackupPath(), filepath.FromSlash(filename))
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, &DownloadError{
@@ -134,7 +168,7 @@ func (u *USB) Download(filename string) ([]byte, error) {
 	return data, nil
 }
 
-// List lists all backup files on the USB drive
+// List lists all backup files on the USB drive, including those in subfolders
 func (u *USB) List() ([]BackupFile, error) {
 	// Check availability
 	available, err := u.IsAvailable()
@@ -148,50 +182,57 @@ func (u *USB) List() ([]BackupFile, error) {
 		}
 	}
 
-	backupPath := u.getBackupPath()
-
-	// Check if backup directory exists
-	if !utils.DirExists(backupPath) {
-		return []BackupFile{}, nil // No backups yet
-	}
+	return listBackupTree(u.getBackupPath(), u.Name())
+}
 
-	// Read directory
-	entries, err := os.ReadDir(backupPath)
+// Delete deletes a file from the USB drive
+func (u *USB) Delete(filename string) error {
+	// Check availability
+	available, err := u.IsAvailable()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+		return err
 	}
-
-	var backups []BackupFile
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	if !available {
+		return &StorageUnavailableError{
+			Storage: u.Name(),
+			Reason:  "USB drive not available",
 		}
+	}
 
-		// Skip hidden/system files (e.g., ._ files, .DS_Store)
-		if shouldIgnoreFile(entry.Name()) {
-			continue
-		}
+	filePath := filepath.Join(u.getBackupPath(), filepath.FromSlash(filename))
+	if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
 
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
+	return nil
+}
+
+// Trash moves a backup to the .trash folder of the backup directory on the drive
+func (u *USB) Trash(filename string) error {
+	if err := u.checkAvailable(); err != nil {
+		return err
+	}
+	return trashFile(u.getBackupPath(), filename)
+}
 
-		backups = append(backups, BackupFile{
-			Name:         entry.Name(),
-			Size:         info.Size(),
-			ModifiedTime: info.ModTime(),
-			Location:     filepath.Join(backupPath, entry.Name()),
-			StorageType:  u.Name(),
-		})
+// ListTrash lists the backups in the .trash folder on the drive
+func (u *USB) ListTrash() ([]TrashedFile, error) {
+	if err := u.checkAvailable(); err != nil {
+		return nil, err
 	}
+	return listTrashTree(u.getBackupPath())
+}
 
-	return backups, nil
+// DeleteTrashed permanently deletes a backup from the .trash folder on the drive
+func (u *USB) DeleteTrashed(filename string) error {
+	if err := u.checkAvailable(); err != nil {
+		return err
+	}
+	return deleteTrashedFile(u.getBackupPath(), filename)
 }
 
-// Delete deletes a file from the USB drive
-func (u *USB) Delete(filename string) error {
-	// Check availability
+// checkAvailable returns an error if the drive is not connected
+func (u *USB) checkAvailable() error {
 	available, err := u.IsAvailable()
 	if err != nil {
 		return err
@@ -202,12 +243,6 @@ func (u *USB) Delete(filename string) error {
 			Reason:  "USB drive not available",
 		}
 	}
-
-	filePath := filepath.Join(u.getBackupPath(), filename)
-	if err := os.Remove(filePath); err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
-	}
-
 	return nil
 }
 
@@ -218,18 +253,16 @@ func (u *USB) GetBackupLocation() string {
 
 // GetFreeSpace returns the free space on the USB drive in bytes
 func (u *USB) GetFreeSpace() (int64, error) {
-	// This is platform-specific and would require syscalls
-	// For simplicity, we'll return 0 for now
-	// In a production implementation, you would use syscall.Statfs on Unix
-	// or GetDiskFreeSpaceEx on Windows
-	return 0, fmt.Errorf("not implemented")
+	if err := u.resolveMountPath(); err != nil {
+		return 0, err
+	}
+	return PathFreeSpace(u.getBackupPath())
 }
 
-// Sync ensures all writes to the USB drive are flushed
+// Sync ensures all writes to the USB drive are flushed, including the folders
+// created for backups, so the drive can be removed safely
 func (u *USB) Sync() error {
-	// This would require platform-specific sync calls
-	// For now, we'll just return nil
-	return nil
+	return syncFileSystem(u.getBackupPath())
 }
 
 // GetBackupsByManager returns backups for a specific manager
@@ -241,8 +274,8 @@ func (u *USB) GetBackupsByManager(manager string) ([]BackupFile, error) {
 
 	var filtered []BackupFile
 	for _, backup := range backups {
-		// Check if filename contains the manager name
-		if len(backup.Name) > len(manager) && backup.Name[:len(manager)] == "backup_"+manager {
+		// Check if filename starts with the manager name, ignoring any subfolder
+		if backupname.Parse(backup.Name).Manager == manager {
 			filtered = append(filtered, backup)
 		}
 	}
@@ -257,12 +290,13 @@ func (u *USB) CleanOldBackups(keepLast int) error {
 		return err
 	}
 
-	return ApplyRetentionPolicy(backups, keepLast, u.Delete)
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, u.Delete)
+	return err
 }
 
 // VerifyBackup verifies that a backup file exists and is readable
 func (u *USB) VerifyBackup(filename string) error {
-	filePath := filepath.Join(u.getBackupPath(), filename)
+	filePath := filepath.Join(u.getBackupPath(), filepath.FromSlash(filename))
 
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -291,7 +325,7 @@ func (u *USB) VerifyBackup(filename string) error {
 
 // GetBackupAge returns the age of a backup file
 func (u *USB) GetBackupAge(filename string) (time.Duration, error) {
-	filePath := filepath.Join(u.getBackupPath(), filename)
+	filePath := filepath.Join(u.getBackupPath(), filepath.FromSlash(filename))
 
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrChecksumUnavailable is returned when the provider has no checksum for a file
+var ErrChecksumUnavailable = errors.New("stored checksum unavailable")
+
+// ChecksumProvider is implemented by backends whose provider computes a
+// checksum of each stored file, so uploads can be verified without
+// downloading them again
+type ChecksumProvider interface {
+	// StoredChecksum returns the hash algorithm ("md5") and hex digest the
+	// provider recorded for a stored file
+	StoredChecksum(filename string) (algorithm, checksum string, err error)
+}
+
+// VerifyUpload proves that the stored copy of filename matches data. The
+// provider's checksum is compared when the backend reports one; otherwise the
+// file is downloaded again and compared by SHA-256. It returns a description
+// of how the copy was verified.
+func VerifyUpload(backend Storage, filename string, data []byte) (string, error) {
+	if provider, ok := backend.(ChecksumProvider); ok {
+		algorithm, stored, err := provider.StoredChecksum(filename)
+		switch {
+		case err == nil && algorithm == "md5":
+			sum := md5.Sum(data)
+			if !strings.EqualFold(stored, hex.EncodeToString(sum[:])) {
+				return "", fmt.Errorf("stored copy does not match: %s MD5 is %s, expected %s", backend.Name(), stored, hex.EncodeToString(sum[:]))
+			}
+			return "provider MD5", nil
+		case err != nil && !errors.Is(err, ErrChecksumUnavailable):
+			return "", fmt.Errorf("failed to get stored checksum: %w", err)
+		}
+	}
+
+	stored, err := backend.Download(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to download stored copy: %w", err)
+	}
+	expected := sha256.Sum256(data)
+	actual := sha256.Sum256(stored)
+	if !bytes.Equal(expected[:], actual[:]) {
+		return "", fmt.Errorf("stored copy does not match: SHA-256 is %s, expected %s", hex.EncodeToString(actual[:]), hex.EncodeToString(expected[:]))
+	}
+	return "SHA-256 of downloaded copy", nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Volume is a mounted filesystem a USB destination can be found on
+type Volume struct {
+	MountPath string
+	Label     string
+	// UUID is the filesystem UUID, or the volume serial number (e.g. "1A2B-3C4D") on Windows
+	UUID string
+}
+
+// FindVolume returns the mount path of the mounted volume with the given label
+// and/or filesystem UUID. Both are compared case-insensitively, and a volume
+// must match each one that is set.
+func FindVolume(label, uuid string) (string, error) {
+	volumes, err := listVolumes(uuid != "")
+	if err != nil {
+		return "", fmt.Errorf("failed to list mounted volumes: %w", err)
+	}
+
+	for _, volume := range volumes {
+		if label != "" && !strings.EqualFold(volume.Label, label) {
+			continue
+		}
+		if uuid != "" && !strings.EqualFold(volume.UUID, uuid) {
+			continue
+		}
+		return volume.MountPath, nil
+	}
+
+	var wanted []string
+	if label != "" {
+		wanted = append(wanted, fmt.Sprintf("label %q", label))
+	}
+	if uuid != "" {
+		wanted = append(wanted, fmt.Sprintf("UUID %s", uuid))
+	}
+	return "", fmt.Errorf("no mounted volume with %s", strings.Join(wanted, " and "))
+}
@@ -0,0 +1,54 @@
+//go:build darwin
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// listVolumes lists the volumes mounted under /Volumes, which macOS names after
+// their label. UUIDs are read with diskutil, only when needed.
+func listVolumes(withUUID bool) ([]Volume, error) {
+	entries, err := os.ReadDir("/Volumes")
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []Volume
+	for _, entry := range entries {
+		if shouldIgnoreFile(entry.Name()) {
+			continue
+		}
+		mountPath := filepath.Join("/Volumes", entry.Name())
+		volume := Volume{
+			MountPath: mountPath,
+			Label:     entry.Name(),
+		}
+		if withUUID {
+			volume.UUID = diskutilVolumeUUID(mountPath)
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes, nil
+}
+
+// diskutilVolumeUUID returns the volume UUID diskutil reports for a mount point
+func diskutilVolumeUUID(mountPath string) string {
+	output, err := exec.Command("diskutil", "info", mountPath).Output()
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "Volume UUID" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package storage
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listVolumes reads the mounted block devices from /proc and names them using the
+// udev symlinks in /dev/disk/by-label and /dev/disk/by-uuid
+func listVolumes(_ bool) ([]Volume, error) {
+	file, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	labels := diskLinks("/dev/disk/by-label")
+	uuids := diskLinks("/dev/disk/by-uuid")
+
+	var volumes []Volume
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		device := fields[0]
+		if resolved, err := filepath.EvalSymlinks(device); err == nil {
+			device = resolved
+		}
+		mountPath := unescapeMountField(fields[1])
+
+		label := labels[device]
+		if label == "" && isRemovableMountRoot(filepath.Dir(mountPath)) {
+			// Desktop environments mount removable drives under their label
+			label = filepath.Base(mountPath)
+		}
+		volumes = append(volumes, Volume{
+			MountPath: mountPath,
+			Label:     label,
+			UUID:      uuids[device],
+		})
+	}
+	return volumes, scanner.Err()
+}
+
+// diskLinks maps each device in a /dev/disk directory to the name linking to it
+func diskLinks(dir string) map[string]string {
+	links := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return links
+	}
+	for _, entry := range entries {
+		device, err := filepath.EvalSymlinks(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		links[device] = unescapeUdevName(entry.Name())
+	}
+	return links
+}
+
+// isRemovableMountRoot reports whether dir is where removable drives are mounted,
+// e.g. /media, /media/<user> or /run/media/<user>
+func isRemovableMountRoot(dir string) bool {
+	return dir == "/media" || filepath.Dir(dir) == "/media" || filepath.Dir(dir) == "/run/media"
+}
+
+// unescapeMountField decodes the octal escapes (\040 for a space) in /proc/self/mounts
+func unescapeMountField(field string) string {
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if value, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// unescapeUdevName decodes the hex escapes (\x20 for a space) udev uses in link names
+func unescapeUdevName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if strings.HasPrefix(name[i:], `\x`) && i+3 < len(name) {
+			if value, err := strconv.ParseUint(name[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
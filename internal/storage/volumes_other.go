@@ -0,0 +1,30 @@
+//go:build !linux && !darwin && !windows
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// listVolumes lists the folders under /media and /mnt, taking each folder's
+// name as the label. Filesystem UUIDs aren't available on this platform.
+func listVolumes(_ bool) ([]Volume, error) {
+	var volumes []Volume
+	for _, root := range []string{"/media", "/mnt"} {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || shouldIgnoreFile(entry.Name()) {
+				continue
+			}
+			volumes = append(volumes, Volume{
+				MountPath: filepath.Join(root, entry.Name()),
+				Label:     entry.Name(),
+			})
+		}
+	}
+	return volumes, nil
+}
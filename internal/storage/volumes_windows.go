@@ -0,0 +1,43 @@
+//go:build windows
+
+package storage
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// listVolumes lists the drive letters with a mounted volume, reading each
+// volume's label and serial number
+func listVolumes(_ bool) ([]Volume, error) {
+	drives, err := windows.GetLogicalDrives()
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []Volume
+	for i := 0; i < 26; i++ {
+		if drives&(1<<uint(i)) == 0 {
+			continue
+		}
+		root := string(rune('A'+i)) + `:\`
+		rootPtr, err := windows.UTF16PtrFromString(root)
+		if err != nil {
+			continue
+		}
+
+		var serial uint32
+		label := make([]uint16, windows.MAX_PATH+1)
+		// Fails for empty card readers and optical drives
+		if err := windows.GetVolumeInformation(rootPtr, &label[0], uint32(len(label)), &serial, nil, nil, nil, 0); err != nil {
+			continue
+		}
+		volumes = append(volumes, Volume{
+			MountPath: root,
+			Label:     windows.UTF16ToString(label),
+			UUID:      fmt.Sprintf("%04X-%04X", serial>>16, serial&0xFFFF),
+		})
+	}
+	return volumes, nil
+}
@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webDAVPropfind requests the properties needed to list backups
+const webDAVPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:resourcetype/>
+    <d:getcontentlength/>
+    <d:getlastmodified/>
+  </d:prop>
+</d:propfind>`
+
+// WebDAV represents a WebDAV storage backend (Nextcloud, ownCloud, ...)
+type WebDAV struct {
+	URL       string
+	Username  string
+	Password  string
+	BackupDir string
+
+	// ContentType is the MIME type uploads are stored with; empty uses DefaultContentType
+	ContentType string
+
+	client *http.Client
+}
+
+// NewWebDAV creates a new WebDAV storage backend
+func NewWebDAV(baseURL, username, password, backupDir string) *WebDAV {
+	return &WebDAV{
+		URL:       strings.TrimRight(baseURL, "/"),
+		Username:  username,
+		Password:  password,
+		BackupDir: strings.Trim(backupDir, "/"),
+		client:    newHTTPClient(5 * time.Minute),
+	}
+}
+
+// Name returns the name of the storage backend
+func (w *WebDAV) Name() string {
+	return "WebDAV"
+}
+
+// IsAvailable checks if the WebDAV server is reachable and accepts the credentials
+func (w *WebDAV) IsAvailable() (bool, error) {
+	if w.URL == "" {
+		return false, &StorageUnavailableError{
+			Storage: w.Name(),
+			Reason:  "URL not configured",
+		}
+	}
+
+	resp, err := w.do("PROPFIND", w.URL+"/", strings.NewReader(webDAVPropfind), map[string]string{"Depth": "0"})
+	if err != nil {
+		return false, &StorageUnavailableError{
+			Storage: w.Name(),
+			Reason:  fmt.Sprintf("failed to reach server: %v", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return false, &StorageUnavailableError{
+			Storage: w.Name(),
+			Reason:  "authentication failed (check username and app password)",
+		}
+	}
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return false, &StorageUnavailableError{
+			Storage: w.Name(),
+			Reason:  fmt.Sprintf("unexpected response: %s", resp.Status),
+		}
+	}
+
+	return true, nil
+}
+
+// fileURL returns the URL of a file in the backup directory
+func (w *WebDAV) fileURL(filename string) string {
+	return w.URL + "/" + escapePath(path.Join(w.BackupDir, filename))
+}
+
+// dirURL returns the URL of the backup directory, with a trailing slash
+func (w *WebDAV) dirURL() string {
+	if w.BackupDir == "" {
+		return w.URL + "/"
+	}
+	return w.URL + "/" + escapePath(w.BackupDir) + "/"
+}
+
+// escapePath escapes each element of a slash-separated path
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// do sends an authenticated request
+func (w *WebDAV) do(method, target string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if w.Username != "" {
+		req.SetBasicAuth(w.Username, w.Password)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return w.client.Do(req)
+}
+
+// ensureDir creates a directory and its parents if they don't exist
+func (w *WebDAV) ensureDir(dir string) error {
+	if dir == "" || dir == "." {
+		return nil
+	}
+
+	current := w.URL
+	for _, part := range strings.Split(dir, "/") {
+		current += "/" + url.PathEscape(part)
+		resp, err := w.do("MKCOL", current+"/", nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		resp.Body.Close()
+
+		// 405 Method Not Allowed means the collection already exists
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("failed to create directory %s: %s", part, resp.Status)
+		}
+	}
+
+	return nil
+}
+
+// Upload uploads a file to the WebDAV server
+func (w *WebDAV) Upload(filename string, data []byte) error {
+	// Create the backup directory and any subfolder of the backup
+	if err := w.ensureDir(path.Dir(path.Join(w.BackupDir, filename))); err != nil {
+		return &UploadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     err,
+		}
+	}
+
+	resp, err := w.do(http.MethodPut, w.fileURL(filename), bytes.NewReader(data), map[string]string{"Content-Type": contentTypeOrDefault(w.ContentType)})
+	if err != nil {
+		return &UploadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %w", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return &UploadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to upload file: %s", resp.Status),
+		}
+	}
+
+	return nil
+}
+
+// Download downloads a file from the WebDAV server
+func (w *WebDAV) Download(filename string) ([]byte, error) {
+	resp, err := w.do(http.MethodGet, w.fileURL(filename), nil, nil)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %w", err),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &DownloadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("file not found"),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &DownloadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to download file: %s", resp.Status),
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &DownloadError{
+			Storage: w.Name(),
+			File:    filename,
+			Err:     fmt.Errorf("failed to read file content: %w", err),
+		}
+	}
+
+	return data, nil
+}
+
+// webDAVMultistatus is the PROPFIND response body
+type webDAVMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// List lists all backup files in the backup directory, including those in subfolders
+func (w *WebDAV) List() ([]BackupFile, error) {
+	return w.listDir("")
+}
+
+// listDir lists the backups in a subfolder of the backup directory and its
+// subfolders. Depth: infinity is often disabled, so each level is listed separately.
+func (w *WebDAV) listDir(dir string) ([]BackupFile, error) {
+	dirURL := w.dirURL()
+	if dir != "" {
+		dirURL += escapePath(dir) + "/"
+	}
+
+	resp, err := w.do("PROPFIND", dirURL, strings.NewReader(webDAVPropfind), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The directory is created by the first upload
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("failed to list files: %s", resp.Status)
+	}
+
+	var multistatus webDAVMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	}
+
+	// Hrefs are absolute paths or URLs; compare their paths with the listed directory's
+	var dirPath string
+	if parsed, err := url.Parse(dirURL); err == nil {
+		dirPath = strings.TrimRight(parsed.Path, "/")
+	}
+
+	var backups []BackupFile
+	for _, response := range multistatus.Responses {
+		href, err := url.PathUnescape(response.Href)
+		if err != nil {
+			href = response.Href
+		}
+		hrefPath := href
+		if parsed, err := url.Parse(response.Href); err == nil {
+			hrefPath = parsed.Path
+		}
+		hrefPath = strings.TrimRight(hrefPath, "/")
+		name := path.Base(hrefPath)
+
+		// Skip the directory itself and hidden/system files
+		if hrefPath == dirPath || shouldIgnoreFile(name) {
+			continue
+		}
+		if dir != "" {
+			name = dir + "/" + name
+		}
+
+		for _, propstat := range response.Propstat {
+			if !strings.Contains(propstat.Status, " 200 ") {
+				continue
+			}
+			prop := propstat.Prop
+
+			if prop.ResourceType.Collection != nil {
+				children, err := w.listDir(name)
+				if err != nil {
+					return nil, err
+				}
+				backups = append(backups, children...)
+				continue
+			}
+
+			size, _ := strconv.ParseInt(prop.ContentLength, 10, 64)
+			modTime, _ := http.ParseTime(prop.LastModified)
+			backups = append(backups, BackupFile{
+				Name:         name,
+				Size:         size,
+				ModifiedTime: modTime,
+				Location:     href,
+				StorageType:  w.Name(),
+			})
+		}
+	}
+
+	return backups, nil
+}
+
+// Delete deletes a file from the WebDAV server
+func (w *WebDAV) Delete(filename string) error {
+	resp, err := w.do(http.MethodDelete, w.fileURL(filename), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("file not found")
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete file: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// CleanOldBackups applies retention policy and deletes old backups
+func (w *WebDAV) CleanOldBackups(keepLast int) error {
+	backups, err := w.List()
+	if err != nil {
+		return err
+	}
+
+	_, err = ApplyRetentionPolicy(backups, RetentionPolicy{KeepLast: keepLast}, nil, w.Delete)
+	return err
+}
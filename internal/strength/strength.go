@@ -0,0 +1,143 @@
+// Package strength estimates how guessable a password is with zxcvbn
+// (github.com/nbutton23/zxcvbn-go): the password is split into the patterns an
+// attacker would try first (common passwords and words, names, keyboard rows,
+// repeats, sequences and dates, each possibly capitalized or with l33t
+// substitutions), and the cheapest way to cover it with them gives its entropy.
+//
+// Unlike counting character classes, this rates "correct horse battery
+// staple" above "P@ssw0rd1!", which is what matters for an encryption password
+// that can be attacked offline.
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/nbutton23/zxcvbn-go"
+	"github.com/nbutton23/zxcvbn-go/match"
+)
+
+// MaxLength is the number of characters estimated; longer passwords are
+// rated by their first MaxLength characters, which are strong enough already
+const MaxLength = 100
+
+// Result is the estimated strength of a password
+type Result struct {
+	// Guesses is the estimated number of guesses an attacker needs
+	Guesses float64
+	// Entropy is log2(Guesses), in bits
+	Entropy float64
+	// Score is 0 (too guessable) to 4 (very unguessable)
+	Score int
+	// Warning explains the weakest part of the password, if any
+	Warning string
+	// Suggestions say how to make it stronger
+	Suggestions []string
+}
+
+// Estimate rates a password. userInputs are words tied to the context, such as
+// the app and password manager names, which an attacker would try first.
+func Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	if len(runes) > MaxLength {
+		runes = runes[:MaxLength]
+	}
+
+	estimate := zxcvbn.PasswordStrength(string(runes), userWords(userInputs))
+	result := Result{Entropy: estimate.Entropy, Guesses: math.Exp2(estimate.Entropy), Score: estimate.Score}
+	result.Warning, result.Suggestions = feedback(result.Score, estimate.MatchSequence)
+	return result
+}
+
+// userWords splits user inputs such as full names into lowercase words
+func userWords(userInputs []string) []string {
+	var words []string
+	for _, input := range userInputs {
+		words = append(words, strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return words
+}
+
+// feedback explains a weak password's most significant pattern and how to
+// improve it, as zxcvbn's feedback does
+func feedback(score int, sequence []match.Match) (string, []string) {
+	if len(sequence) == 0 {
+		return "", []string{"Use a few words, avoid common phrases", "No need for symbols, digits, or uppercase letters"}
+	}
+	if score > 2 {
+		return "", nil
+	}
+
+	// The longest match says most about the password
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if len([]rune(m.Token)) > len([]rune(longest.Token)) {
+			longest = m
+		}
+	}
+	warning, suggestions := matchFeedback(longest, len(sequence) == 1)
+	suggestions = append([]string{"Add another word or two. Uncommon words are better."}, suggestions...)
+	return warning, suggestions
+}
+
+// matchFeedback explains why a match is easy to guess
+func matchFeedback(m match.Match, whole bool) (string, []string) {
+	switch m.Pattern {
+	case "dictionary":
+		return dictionaryFeedback(m, whole)
+	case "spatial":
+		if len([]rune(m.Token)) <= 5 {
+			return "Short keyboard patterns are easy to guess", []string{"Use a longer keyboard pattern with more turns"}
+		}
+		return "Straight rows of keys are easy to guess", []string{"Use a longer keyboard pattern with more turns"}
+	case "repeat":
+		return `Repeats like "aaa" are easy to guess`, []string{"Avoid repeated words and characters"}
+	case "sequence":
+		return "Sequences like abc or 6543 are easy to guess", []string{"Avoid sequences"}
+	case "date":
+		return "Dates are often easy to guess", []string{"Avoid dates and years that are associated with you"}
+	}
+	return "", nil
+}
+
+// dictionaryFeedback explains why a dictionary word is easy to guess
+func dictionaryFeedback(m match.Match, whole bool) (string, []string) {
+	var warning string
+	dictionary := strings.TrimSuffix(m.DictionaryName, "_3117")
+	switch dictionary {
+	case "Passwords":
+		if whole {
+			warning = "This is a very common password"
+		} else {
+			warning = "This is similar to a commonly used password"
+		}
+	case "English":
+		if whole {
+			warning = "A word by itself is easy to guess"
+		}
+	case "MaleNames", "FemaleNames", "Surname":
+		if whole {
+			warning = "Names and surnames by themselves are easy to guess"
+		} else {
+			warning = "Common names and surnames are easy to guess"
+		}
+	case "user_inputs":
+		warning = "Words tied to this backup, like the app or password manager names, are easy to guess"
+	}
+
+	var suggestions []string
+	word := m.Token
+	switch {
+	case strings.ToUpper(word) == word && strings.ToLower(word) != word:
+		suggestions = append(suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	case strings.ToLower(word) != word:
+		suggestions = append(suggestions, "Capitalization doesn't help very much")
+	}
+	if dictionary != m.DictionaryName {
+		suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return warning, suggestions
+}
@@ -0,0 +1,112 @@
+package stashr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/backupname"
+	"github.com/harshalranjhani/stashr/internal/crypto"
+	"github.com/harshalranjhani/stashr/internal/dictionary"
+	"github.com/harshalranjhani/stashr/pkg/utils"
+)
+
+// DefaultDictionaryDir is where stashr keeps compression dictionaries unless
+// backup.dictionary.dir says otherwise
+const DefaultDictionaryDir = "~/.stashr/dictionaries"
+
+// BackupName is what a backup filename says about the backup
+type BackupName struct {
+	// Manager is the password manager, e.g. "bitwarden", or "" if the name doesn't say
+	Manager string
+	// Timestamp is when the backup was made, or zero if the name doesn't say
+	Timestamp time.Time
+	// Encrypted and Compressed are read from the file extension; names with
+	// the .stashr extension don't say, so both are false
+	Encrypted  bool
+	Compressed bool
+}
+
+// ParseBackupName reads the manager and time of a backup from its filename.
+// It understands every naming format stashr has written.
+func ParseBackupName(filename string) BackupName {
+	name := backupname.Parse(filename)
+	return BackupName{
+		Manager:    name.Manager,
+		Timestamp:  name.Timestamp,
+		Encrypted:  name.Encrypted,
+		Compressed: name.Compressed,
+	}
+}
+
+// IsEncrypted reports whether backup data is encrypted with a password
+func IsEncrypted(data []byte) bool {
+	return crypto.IsEncrypted(data)
+}
+
+// Encrypt encrypts data with a password the way stashr encrypts backups
+func Encrypt(data []byte, password string) ([]byte, error) {
+	return crypto.Encrypt(data, password)
+}
+
+// Decrypt decrypts a password-encrypted backup
+func Decrypt(data []byte, password string) ([]byte, error) {
+	return crypto.Decrypt(data, password)
+}
+
+// IsCompressed reports whether decrypted backup data is compressed
+func IsCompressed(data []byte) bool {
+	if utils.IsCompressed(data) {
+		return true
+	}
+	_, ok := dictionary.HeaderID(data)
+	return ok
+}
+
+// Decompress decompresses decrypted backup data. Backups compressed with a
+// trained dictionary need it from dictionaryDir; empty means DefaultDictionaryDir.
+func Decompress(data []byte, dictionaryDir string) ([]byte, error) {
+	id, ok := dictionary.HeaderID(data)
+	if !ok {
+		return utils.DecompressData(data)
+	}
+
+	if dictionaryDir == "" {
+		dictionaryDir = DefaultDictionaryDir
+	}
+	if strings.HasPrefix(dictionaryDir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the home directory: %w", err)
+		}
+		dictionaryDir = filepath.Join(home, dictionaryDir[2:])
+	}
+
+	dict, err := dictionary.NewStore(dictionaryDir).Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return dictionary.Decompress(data, dict)
+}
+
+// Open returns the plaintext export of a backup: it decrypts the data if it is
+// encrypted, then decompresses it if it is compressed. password is only needed
+// for encrypted backups.
+func Open(data []byte, password, dictionaryDir string) ([]byte, error) {
+	if IsEncrypted(data) {
+		if password == "" {
+			return nil, fmt.Errorf("backup is encrypted; a password is required")
+		}
+		decrypted, err := Decrypt(data, password)
+		if err != nil {
+			return nil, err
+		}
+		data = decrypted
+	}
+	if IsCompressed(data) {
+		return Decompress(data, dictionaryDir)
+	}
+	return data, nil
+}
@@ -0,0 +1,22 @@
+// Package stashr is the stable library API of stashr, for third-party tools
+// built on it: GUIs that open backups, scripts that read backup names, and
+// storage plugins.
+//
+// Import it as
+//
+//	import stashr "github.com/harshalranjhani/stashr/pkg/stashr/v1"
+//
+// # Compatibility
+//
+// Within major version 1 the API only grows: exported identifiers are never
+// removed and their signatures never change, so a tool built against v1.N
+// keeps building against every later v1 release. Minor versions add
+// identifiers; patch versions only fix bugs. Identifiers that are superseded
+// stay as deprecated shims forwarding to their replacement, are listed in
+// Deprecations, and are only removed in a new major version, which gets a new
+// import path (pkg/stashr/v2).
+//
+// Everything under internal/ and the other packages of the module may change
+// in any release. Tools that run stashr instead of importing it can check the
+// API version of the installed binary with `stashr api-version`.
+package stashr
@@ -0,0 +1,138 @@
+package stashr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/harshalranjhani/stashr/internal/storage"
+)
+
+// PluginProtocolVersion is the version of the request format stashr sends to
+// storage plugins
+const PluginProtocolVersion = storage.PluginProtocolVersion
+
+// PluginExecutablePrefix prefixes the executable name of every storage plugin,
+// e.g. stashr-storage-glacier for a plugin named glacier
+const PluginExecutablePrefix = storage.PluginExecutablePrefix
+
+// ErrNotFound is returned by a Storage when the requested backup doesn't exist
+var ErrNotFound = errors.New("file not found")
+
+// BackupFile is one stored backup
+type BackupFile struct {
+	// Name may contain slash-separated subfolders
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// Storage is a destination served as a storage plugin. Filenames may contain
+// slash-separated subfolders. options are the plugin's settings from the
+// stashr config file.
+type Storage interface {
+	// IsAvailable returns nil if the destination is reachable, or why it isn't
+	IsAvailable(options map[string]string) error
+	Upload(options map[string]string, filename string, data []byte) error
+	Download(options map[string]string, filename string) ([]byte, error)
+	List(options map[string]string) ([]BackupFile, error)
+	Delete(options map[string]string, filename string) error
+}
+
+// pluginRequest is the JSON object stashr writes to a plugin's stdin
+type pluginRequest struct {
+	Version  int               `json:"version"`
+	Command  string            `json:"command"`
+	Filename string            `json:"filename,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// pluginResponse is the JSON object a plugin writes to stdout
+type pluginResponse struct {
+	OK        bool         `json:"ok"`
+	Error     string       `json:"error,omitempty"`
+	NotFound  bool         `json:"not_found,omitempty"`
+	Available bool         `json:"available,omitempty"`
+	Reason    string       `json:"reason,omitempty"`
+	Data      []byte       `json:"data,omitempty"`
+	Files     []pluginFile `json:"files,omitempty"`
+}
+
+// pluginFile is one stored backup in a list response
+type pluginFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// ServePlugin runs one command of a storage plugin, reading stashr's request
+// from stdin and writing the response to stdout. Call it from the main function
+// of an executable named PluginExecutablePrefix + the plugin's name.
+// The content_type of uploads isn't passed on; plugins that store it implement
+// the protocol themselves.
+func ServePlugin(s Storage) error {
+	return ServePluginIO(s, os.Stdin, os.Stdout)
+}
+
+// ServePluginIO is ServePlugin with the request read from r and the response
+// written to w
+func ServePluginIO(s Storage, r io.Reader, w io.Writer) error {
+	var req pluginRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return writePluginResponse(w, pluginResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+	}
+	if req.Version > PluginProtocolVersion {
+		return writePluginResponse(w, pluginResponse{Error: fmt.Sprintf("unsupported protocol version %d", req.Version)})
+	}
+	return writePluginResponse(w, handlePluginRequest(s, req))
+}
+
+// handlePluginRequest dispatches a request to the Storage
+func handlePluginRequest(s Storage, req pluginRequest) pluginResponse {
+	var resp pluginResponse
+	var err error
+	switch req.Command {
+	case storage.PluginCommandAvailable:
+		if reason := s.IsAvailable(req.Options); reason != nil {
+			resp.Reason = reason.Error()
+		} else {
+			resp.Available = true
+		}
+	case storage.PluginCommandUpload:
+		err = s.Upload(req.Options, req.Filename, req.Data)
+	case storage.PluginCommandDownload:
+		resp.Data, err = s.Download(req.Options, req.Filename)
+	case storage.PluginCommandList:
+		var files []BackupFile
+		files, err = s.List(req.Options)
+		for _, file := range files {
+			resp.Files = append(resp.Files, pluginFile{Name: file.Name, Size: file.Size, Modified: file.Modified})
+		}
+	case storage.PluginCommandDelete:
+		err = s.Delete(req.Options, req.Filename)
+	default:
+		err = fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		resp.NotFound = true
+	case err != nil:
+		resp.Error = err.Error()
+	default:
+		resp.OK = true
+	}
+	return resp
+}
+
+// writePluginResponse writes a response as JSON
+func writePluginResponse(w io.Writer, resp pluginResponse) error {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return fmt.Errorf("failed to write plugin response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package stashr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// API version of this package, following semantic versioning
+const (
+	APIMajor = 1
+	APIMinor = 0
+	APIPatch = 0
+)
+
+// APIVersion is the API version of this package, e.g. "1.0.0"
+var APIVersion = fmt.Sprintf("%d.%d.%d", APIMajor, APIMinor, APIPatch)
+
+// Deprecation describes a deprecated identifier kept as a shim
+type Deprecation struct {
+	// Name is the deprecated identifier
+	Name string
+	// Replacement is what to use instead
+	Replacement string
+	// Since is the API version that deprecated it
+	Since string
+}
+
+// Deprecations lists the deprecated identifiers of this API version. They keep
+// working until the next major version.
+var Deprecations = []Deprecation{}
+
+// Supports reports whether this API version provides an API a tool was built
+// against, given as "MAJOR.MINOR" or "MAJOR.MINOR.PATCH". The major versions must
+// match, and the tool's minor version must not be newer than this one; patch
+// versions don't affect compatibility.
+func Supports(required string) (bool, error) {
+	parts := strings.Split(strings.TrimPrefix(required, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return false, fmt.Errorf("invalid API version %q: expected MAJOR.MINOR or MAJOR.MINOR.PATCH", required)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return false, fmt.Errorf("invalid API version %q", required)
+		}
+		numbers[i] = n
+	}
+	return numbers[0] == APIMajor && numbers[1] <= APIMinor, nil
+}
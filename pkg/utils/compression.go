@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression algorithms. All are built in: backups can be made and restored
+// without the zstd or xz tools, which read the files too.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+	CompressionXZ   = "xz"
+	CompressionNone = "none"
+)
+
+// CompressionAlgorithms lists the algorithms backups can be compressed with
+var CompressionAlgorithms = []string{CompressionGzip, CompressionZstd, CompressionXZ, CompressionNone}
+
+// Magic bytes each algorithm's output starts with
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// CompressionLevels returns the levels an algorithm accepts. Level 0 always
+// means the algorithm's default.
+func CompressionLevels(algorithm string) (int, int) {
+	switch algorithm {
+	case CompressionZstd:
+		return 1, 19
+	case CompressionGzip, CompressionXZ:
+		return 1, 9
+	default:
+		return 0, 0
+	}
+}
+
+// CompressionExtension returns the file extension of data compressed with an algorithm
+func CompressionExtension(algorithm string) string {
+	switch algorithm {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	case CompressionXZ:
+		return ".xz"
+	default:
+		return ""
+	}
+}
+
+// Compress compresses data with an algorithm at a level, 0 for its default
+func Compress(data []byte, algorithm string, level int) ([]byte, error) {
+	if min, max := CompressionLevels(algorithm); level != 0 && (level < min || level > max) {
+		return nil, fmt.Errorf("invalid %s compression level %d (use %d-%d)", algorithm, level, min, max)
+	}
+
+	switch algorithm {
+	case CompressionGzip:
+		if level == 0 {
+			return CompressData(data)
+		}
+		var compressed bytes.Buffer
+		gzWriter, err := gzip.NewWriterLevel(&compressed, level)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+		}
+		if _, err := gzWriter.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to compress data: %w", err)
+		}
+		if err := gzWriter.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close gzip writer: %w", err)
+		}
+		return compressed.Bytes(), nil
+	case CompressionZstd:
+		return compressZstd(data, level)
+	case CompressionXZ:
+		return compressXZ(data, level)
+	case CompressionNone:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm: %s (use: %s)", algorithm, strings.Join(CompressionAlgorithms, ", "))
+	}
+}
+
+// DetectCompression returns the algorithm data was compressed with, read from
+// its magic bytes, or "" if it isn't compressed
+func DetectCompression(data []byte) string {
+	switch {
+	case len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return CompressionGzip
+	case bytes.HasPrefix(data, zstdMagic):
+		return CompressionZstd
+	case bytes.HasPrefix(data, xzMagic):
+		return CompressionXZ
+	default:
+		return ""
+	}
+}
+
+// compressZstd compresses data with zstd at a level of the zstd tool
+func compressZstd(data []byte, level int) ([]byte, error) {
+	options := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if level != 0 {
+		options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	encoder, err := zstd.NewWriter(nil, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil), nil
+}
+
+// xzDictionarySizes are the dictionary sizes of the xz tool's presets 1-9,
+// which mostly set how far back xz looks for repeats
+var xzDictionarySizes = []int{1 << 20, 2 << 20, 4 << 20, 4 << 20, 8 << 20, 8 << 20, 16 << 20, 32 << 20, 64 << 20}
+
+// compressXZ compresses data with xz at a level of the xz tool
+func compressXZ(data []byte, level int) ([]byte, error) {
+	var config xz.WriterConfig
+	if level != 0 {
+		config.DictCap = xzDictionarySizes[level-1]
+	}
+	var compressed bytes.Buffer
+	xzWriter, err := config.NewWriter(&compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create xz writer: %w", err)
+	}
+	if _, err := xzWriter.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	if err := xzWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close xz writer: %w", err)
+	}
+	return compressed.Bytes(), nil
+}
+
+// decompressZstd decompresses zstd data. Concatenated frames decompress like a single one.
+func decompressZstd(data []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer decoder.Close()
+
+	decompressed, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return decompressed, nil
+}
+
+// decompressXZ decompresses xz data. Concatenated streams decompress like a single one.
+func decompressXZ(data []byte) ([]byte, error) {
+	reader, err := xz.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create xz reader: %w", err)
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return decompressed, nil
+}
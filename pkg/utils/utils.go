@@ -2,14 +2,21 @@ package utils
 
 import (
 	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"golang.org/x/term"
+
+	"github.com/harshalranjhani/stashr/internal/i18n"
+	"github.com/harshalranjhani/stashr/internal/secret"
 )
 
 // CompressData compresses data using gzip
@@ -29,8 +36,16 @@ func CompressData(data []byte) ([]byte, error) {
 	return compressed, nil
 }
 
-// DecompressData decompresses gzip-compressed data
+// DecompressData decompresses data compressed with any of the
+// CompressionAlgorithms, detected from its magic bytes
 func DecompressData(data []byte) ([]byte, error) {
+	switch DetectCompression(data) {
+	case CompressionZstd:
+		return decompressZstd(data)
+	case CompressionXZ:
+		return decompressXZ(data)
+	}
+
 	reader, err := gzip.NewReader(&readableBuffer{buf: data})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
@@ -70,6 +85,12 @@ func (r *readableBuffer) Read(p []byte) (int, error) {
 	return n, nil
 }
 
+// IsCompressed reports whether data starts with the magic bytes of one of the
+// CompressionAlgorithms
+func IsCompressed(data []byte) bool {
+	return DetectCompression(data) != ""
+}
+
 // FormatBytes formats bytes as human-readable size
 func FormatBytes(bytes int64) string {
 	const unit = 1024
@@ -86,7 +107,12 @@ func FormatBytes(bytes int64) string {
 
 // GenerateBackupFilename generates a backup filename based on the format
 func GenerateBackupFilename(format, manager string) string {
-	timestamp := time.Now().Format("20060102_150405")
+	return GenerateBackupFilenameAt(format, manager, time.Now())
+}
+
+// GenerateBackupFilenameAt generates a backup filename for a specific time
+func GenerateBackupFilenameAt(format, manager string, t time.Time) string {
+	timestamp := t.Format("20060102_150405")
 	return fmt.Sprintf(format, manager, timestamp)
 }
 
@@ -117,6 +143,24 @@ func IsCommandAvailable(path string) bool {
 	return err == nil
 }
 
+// OpenBrowser opens a URL in the default browser without waiting for it to close
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
 // RunCommand runs a command and returns its output
 func RunCommand(name string, args ...string) ([]byte, error) {
 	cmd := exec.Command(name, args...)
@@ -138,6 +182,12 @@ func RunCommandWithEnv(name string, env []string, args ...string) ([]byte, error
 	return output, nil
 }
 
+// SHA256Hex returns the hex-encoded SHA-256 checksum of data
+func SHA256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // FileExists checks if a file exists
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -163,9 +213,15 @@ func CreateDirIfNotExists(path string, perm os.FileMode) error {
 	return nil
 }
 
-// GetTempFile creates a temporary file and returns its path
-func GetTempFile(prefix string) (*os.File, error) {
-	tmpFile, err := os.CreateTemp("", prefix)
+// GetTempFile creates a temporary file in dir, or the OS default when dir is empty.
+// A missing dir is created with 0700 permissions.
+func GetTempFile(dir, prefix string) (*os.File, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+	}
+	tmpFile, err := os.CreateTemp(dir, prefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -180,17 +236,58 @@ func CleanupTempFile(path string) error {
 	return nil
 }
 
+// SecureDelete overwrites a file, or every file under a directory, with random
+// data before removing it. On SSDs and copy-on-write or journaling file systems
+// old blocks may survive an overwrite, so this reduces rather than rules out recovery.
+func SecureDelete(path string) error {
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return overwriteFile(file, info.Size())
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to overwrite %s: %w", path, err)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// overwriteFile replaces a file's contents with random data and flushes it to disk
+func overwriteFile(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.CopyN(f, rand.Reader, size); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// IsTerminal reports whether stdin is a terminal the user can answer prompts on
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 // ConfirmPrompt prompts the user for confirmation
 func ConfirmPrompt(message string) bool {
-	fmt.Printf("%s (y/n): ", message)
+	fmt.Printf("%s %s: ", i18n.T(message), i18n.T("(y/n)"))
 	var response string
 	fmt.Scanln(&response)
-	return response == "y" || response == "Y" || response == "yes" || response == "Yes"
+	return i18n.IsYes(response)
 }
 
 // PromptForInput prompts the user for input
 func PromptForInput(message string) string {
-	fmt.Printf("%s: ", message)
+	fmt.Printf("%s: ", i18n.T(message))
 	var input string
 	fmt.Scanln(&input)
 	return input
@@ -199,7 +296,7 @@ func PromptForInput(message string) string {
 // PromptForPassword prompts the user for a password (without echo)
 func PromptForPassword(message string) (string, error) {
 	if message != "" {
-		fmt.Print(message)
+		fmt.Print(i18n.T(message))
 	}
 
 	// Read password without echoing to terminal
@@ -212,3 +309,20 @@ func PromptForPassword(message string) (string, error) {
 
 	return string(bytepw), nil
 }
+
+// PromptForSecret prompts for a password like PromptForPassword, but returns
+// it in locked memory without ever holding it in a string
+func PromptForSecret(message string) (*secret.Buffer, error) {
+	if message != "" {
+		fmt.Print(i18n.T(message))
+	}
+
+	bytepw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return secret.FromBytes(bytepw), nil
+}